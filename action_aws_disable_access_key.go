@@ -0,0 +1,59 @@
+//go:build !sra_action || sra_aws_disable_access_key
+// +build !sra_action sra_aws_disable_access_key
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/disableaccesskey"
+)
+
+var _ = addAction("DisableAccessKey", DisableAccessKey)
+
+// DisableAccessKey disables an AWS IAM access key.
+//
+// This Cloud Function will respond to AWS GuardDuty findings raised for an access key, such as
+// **UnauthorizedAccess:IAMUser/MaliciousIPCaller**. The access key is marked inactive.
+//
+// Permissions required
+//	- iam:UpdateAccessKey granted to the AWS credentials of the function.
+//
+func DisableAccessKey(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "aws_disable_access_key", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "aws_disable_access_key", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values disableaccesskey.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		aws, err := awsServices(ctx, values.Region)
+		if err != nil {
+			return err
+		}
+		return disableaccesskey.Execute(ctx, &values, &disableaccesskey.Services{
+			AWS:    aws,
+			Logger: s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !sra_action || sra_aws_isolate_instance
+// +build !sra_action sra_aws_isolate_instance
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/isolateinstance"
+)
+
+var _ = addAction("IsolateEC2Instance", IsolateEC2Instance)
+
+// IsolateEC2Instance isolates an AWS EC2 instance from the network.
+//
+// This Cloud Function will respond to AWS GuardDuty findings raised for an instance, such as
+// **CryptoCurrency:EC2/BitcoinTool.B!DNS**. The instance is moved into a security group of its
+// VPC that allows no ingress or egress traffic.
+//
+// Permissions required
+//	- ec2:DescribeSecurityGroups, ec2:CreateSecurityGroup and ec2:RevokeSecurityGroupEgress to manage the isolation group.
+//	- ec2:ModifyInstanceAttribute to replace the security groups of the instance.
+//
+func IsolateEC2Instance(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "aws_isolate_instance", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "aws_isolate_instance", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values isolateinstance.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		aws, err := awsServices(ctx, values.Region)
+		if err != nil {
+			return err
+		}
+		return isolateinstance.Execute(ctx, &values, &isolateinstance.Services{
+			AWS:    aws,
+			Logger: s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,54 @@
+//go:build !sra_action || sra_close_bucket
+// +build !sra_action sra_close_bucket
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("CloseBucket", CloseBucket, services.WithResource)
+
+// CloseBucket will remove any public users from buckets found within the provided folders.
+//
+// Permissions required
+//	- roles/viewer to retrieve ancestry.
+//	- roles/storeage.admin to modify buckets.
+//
+func CloseBucket(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "close_bucket", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "close_bucket", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values closebucket.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return closebucket.Execute(ctx, &values, &closebucket.Services{
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
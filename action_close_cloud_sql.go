@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_close_cloud_sql
+// +build !sra_action sra_close_cloud_sql
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/removepublic"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("CloseCloudSQL", CloseCloudSQL, services.WithResource, services.WithCloudSQL)
+
+// CloseCloudSQL removes public IP for a Cloud SQL instance.
+//
+// This Cloud Function will respond to Security Health Analytics **Public SQL Instance** findings
+// from **SQL Scanner**. All public IP addresses of the affected instance will be
+// deleted when this function is activated.
+//
+// Permissions required
+//	- roles/cloudsql.editor to get instance data and delete access config.
+//
+func CloseCloudSQL(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "close_cloud_sql", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "close_cloud_sql", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values removepublic.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return removepublic.Execute(ctx, &values, &removepublic.Services{
+			CloudSQL: s.CloudSQL,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
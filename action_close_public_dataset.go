@@ -0,0 +1,60 @@
+//go:build !sra_action || sra_close_public_dataset
+// +build !sra_action sra_close_public_dataset
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/bigquery/closepublicdataset"
+)
+
+var _ = addAction("ClosePublicDataset", ClosePublicDataset)
+
+// ClosePublicDataset removes public access of a BigQuery dataset.
+//
+// This Cloud Function will respond to Security Health Analytics **Public Dataset** findings
+// from **Dataset Scanner**. All public access of the affected dataset will be
+// removed when this function is activated.
+//
+// Permissions required
+//	- roles/bigquery.dataOwner to get and update dataset metadata.
+//
+func ClosePublicDataset(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "close_public_dataset", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "close_public_dataset", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values closepublicdataset.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		bigquery, err := bigQuery(ctx, values.ProjectID)
+		if err != nil {
+			return err
+		}
+		return closepublicdataset.Execute(ctx, &values, &closepublicdataset.Services{
+			BigQuery: bigquery,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
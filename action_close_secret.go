@@ -0,0 +1,60 @@
+//go:build !sra_action || sra_close_secret
+// +build !sra_action sra_close_secret
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/secretmanager/closesecret"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("CloseSecret", CloseSecret, services.WithSecretManager)
+
+// CloseSecret removes public and external members from a Secret Manager secret.
+//
+// This Cloud Function will respond to custom findings of secrets named
+// "//secretmanager.googleapis.com/projects/<id>/secrets/<secret>". allUsers and
+// allAuthenticatedUsers are removed from the secret's policy along with the users and groups
+// outside the allowed domains. If configured the secret is rotated: a random version is added and
+// the versions enabled before are disabled.
+//
+// Permissions required
+//	- roles/secretmanager.admin to set the IAM policies of secrets and manage their versions.
+//
+func CloseSecret(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "close_secret", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "close_secret", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values closesecret.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return closesecret.Execute(ctx, &values, &closesecret.Services{
+			SecretManager: s.SecretManager,
+			Logger:        s.Logger,
+			Progress:      progress(ctx, r),
+		})
+	default:
+		return err
+	}
+}
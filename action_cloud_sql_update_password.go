@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_cloud_sql_update_password
+// +build !sra_action sra_cloud_sql_update_password
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/updatepassword"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("UpdatePassword", UpdatePassword, services.WithResource, services.WithCloudSQL)
+
+// UpdatePassword updates the root password for a Cloud SQL instance.
+//
+// This Cloud Function will respond to Security Health Analytics **SQL No Root Password** findings
+// from **SQL Scanner**. The root user of the affected instance will be updated with
+// a new password when this function is activated.
+//
+// Permissions required
+//	- roles/cloudsql.admin to update a user password.
+//
+func UpdatePassword(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "cloud_sql_update_password", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "cloud_sql_update_password", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values updatepassword.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return updatepassword.Execute(ctx, &values, &updatepassword.Services{
+			CloudSQL: s.CloudSQL,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
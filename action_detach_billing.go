@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_detach_billing
+// +build !sra_action sra_detach_billing
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/billing/detachbilling"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("DetachBilling", DetachBilling, services.WithBilling)
+
+// DetachBilling detaches the billing account from the project of a finding, such as a project
+// running a cryptomining fleet, stopping its costs while the finding is investigated.
+//
+// This Cloud Function will respond to any Security Command Center, Event Threat Detection or custom
+// finding the action is configured for. Resources that are not free stop and may be deleted while
+// billing is detached.
+//
+// Permissions required
+//	- roles/billing.projectManager to detach the billing accounts of projects.
+//
+func DetachBilling(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "detach_billing", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "detach_billing", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values detachbilling.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return detachbilling.Execute(ctx, &values, &detachbilling.Services{
+			Billing: s.Billing,
+			Logger:  s.Logger,
+		})
+	default:
+		return err
+	}
+}
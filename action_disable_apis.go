@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_disable_apis
+// +build !sra_action sra_disable_apis
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/serviceusage/disableapis"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("DisableAPIs", DisableAPIs, services.WithServiceUsage)
+
+// DisableAPIs disables APIs of the project of a finding, such as the Compute Engine API of a sandbox
+// project abused for cryptomining.
+//
+// This Cloud Function will respond to any Security Command Center, Event Threat Detection or custom
+// finding the action is configured for. Protected APIs, such as those of Resource Manager, IAM and
+// Cloud Logging, are never disabled.
+//
+// Permissions required
+//	- roles/serviceusage.serviceUsageAdmin to list and disable the APIs of projects.
+//
+func DisableAPIs(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "disable_apis", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "disable_apis", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values disableapis.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return disableapis.Execute(ctx, &values, &disableapis.Services{
+			ServiceUsage: s.ServiceUsage,
+			Logger:       s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_disable_dashboard
+// +build !sra_action sra_disable_dashboard
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/disabledashboard"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("DisableDashboard", DisableDashboard, services.WithResource, services.WithContainer)
+
+// DisableDashboard will disable the Kubernetes dashboard addon.
+//
+// This Cloud Function will respond to Security Health Analytics **Web UI Enabled** findings
+// from **Container Scanner**. The Kubernetes dashboard addon will be disabled when this
+// function is activated.
+//
+// Permissions required
+//	- roles/container.clusterAdmin update cluster addon.
+//
+func DisableDashboard(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "disable_dashboard", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "disable_dashboard", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values disabledashboard.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return disabledashboard.Execute(ctx, &values, &disabledashboard.Services{
+			Container: s.Container,
+			Resource:  s.Resource,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_enable_audit_logs
+// +build !sra_action sra_enable_audit_logs
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("EnableAuditLogs", EnableAuditLogs, services.WithResource)
+
+// EnableAuditLogs enables the Audit Logs to specific project
+//
+// This Cloud Function will respond to Security Health Analytics **AUDIT_LOGGING_DISABLED** findings
+// from **LOGGING_SCANNER**, enabling the ADMIN_READ, DATA_READ and DATA_WRITE logs of the configured
+// services, all services if none are.
+//
+// Permissions required
+//	- roles/resourcemanager.folderAdmin to get/update resource policy from projects in folder.
+//	- roles/editor to get/update resource policy to specific project.
+//
+func EnableAuditLogs(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "enable_audit_logs", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "enable_audit_logs", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values enableauditlogs.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return enableauditlogs.Execute(ctx, &values, &enableauditlogs.Services{
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
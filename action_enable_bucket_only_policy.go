@@ -0,0 +1,56 @@
+//go:build !sra_action || sra_enable_bucket_only_policy
+// +build !sra_action sra_enable_bucket_only_policy
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("EnableBucketOnlyPolicy", EnableBucketOnlyPolicy, services.WithResource)
+
+// EnableBucketOnlyPolicy Enable bucket only policy on a GCS bucket.
+//
+// This Cloud Function will respond to Security Health Analytics **BUCKET_POLICY_ONLY_DISABLED** findings
+// from **STORAGE_SCANNER**. Bucket only IAM policy will be enforced on the bucket.
+//
+// Permissions required
+//	- roles/storage.admin to change the Bucket policy mode.
+//
+func EnableBucketOnlyPolicy(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "enable_bucket_only_policy", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "enable_bucket_only_policy", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values enablebucketonlypolicy.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return enablebucketonlypolicy.Execute(ctx, &values, &enablebucketonlypolicy.Services{
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,68 @@
+//go:build !sra_action || sra_export_evidence_logs
+// +build !sra_action sra_export_evidence_logs
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/exportlogs"
+)
+
+var _ = addAction("ExportEvidenceLogs", ExportEvidenceLogs)
+
+// ExportEvidenceLogs exports the logs of the resource of a finding around the finding time.
+//
+// This Cloud Function exports the audit log entries naming the resource of a finding, and the VPC
+// flows of instances and projects, logged in a window around the time of the finding to the
+// bucket named by EVIDENCE_BUCKET for its investigation.
+//
+// Permissions required
+//	- roles/logging.privateLogViewer to read Data Access audit logs along with the other logs.
+//	- roles/storage.objectCreator on the evidence bucket.
+//
+func ExportEvidenceLogs(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "export_evidence_logs", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "export_evidence_logs", r, m, &err)
+	// The evidence bucket is written as the function's own service account, not a folder's.
+	archive, err := archiveOf(ctx, os.Getenv("EVIDENCE_BUCKET"))
+	if err != nil {
+		return err
+	}
+	ctx, s := route(ctx, m)
+	var values exportlogs.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		l, err := logs(ctx)
+		if err != nil {
+			return err
+		}
+		return exportlogs.Execute(ctx, &values, &exportlogs.Services{
+			Logs:    l,
+			Archive: archive,
+			Logger:  s.Logger,
+		})
+	default:
+		return err
+	}
+}
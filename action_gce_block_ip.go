@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_gce_block_ip
+// +build !sra_action sra_gce_block_ip
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("BlockIP", BlockIP, services.WithResource, services.WithFirewall)
+
+// BlockIP blocks traffic from IP addresses.
+//
+// This Cloud Function will respond to network threat detections such as those raised by Cloud IDS.
+// A firewall rule denying all ingress traffic from the attacking IP addresses is added to the network.
+//
+// Permissions required
+//	- roles/viewer to retrieve ancestry.
+//	- roles/compute.securityAdmin to create and update firewall rules.
+//
+func BlockIP(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gce_block_ip", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gce_block_ip", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values blockip.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return blockip.Execute(ctx, &values, &blockip.Services{
+			Firewall: s.Firewall,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !sra_action || sra_gce_create_disk_snapshot
+// +build !sra_action sra_gce_create_disk_snapshot
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("SnapshotDisk", SnapshotDisk, services.WithResource, services.WithHost)
+
+// SnapshotDisk is the entry point for the auto creation of GCE snapshots Cloud Function.
+//
+// Once a supported finding is received this Cloud Function will look for any existing disk snapshots
+// for the affected instance. If there are recent snapshots then no action is taken. This is so we
+// do not overwrite a recent snapshot. If we have not taken a snapshot recently, take a new snapshot
+// for each disk within the instance.
+//
+// Permissions required
+//	- roles/compute.instanceAdmin.v1 to manage disk snapshots.
+//
+func SnapshotDisk(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gce_create_disk_snapshot", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gce_create_disk_snapshot", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values createsnapshot.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		if values.FindingID == "" {
+			values.FindingID = m.Attributes[services.CorrelationAttribute]
+		}
+		output, err := createsnapshot.Execute(ctx, &values, &createsnapshot.Services{
+			Host:     s.Host,
+			Logger:   s.Logger,
+			Resource: s.Resource,
+			Progress: progress(ctx, r),
+		})
+		if err != nil {
+			return err
+		}
+		for _, dest := range values.Output {
+			switch dest {
+			case "turbinia":
+				log.Println("turbinia output is enabled, sending each copied disk to turbinia")
+				turbiniaProjectID := values.Turbinia.ProjectID
+				turbiniaTopicName := values.Turbinia.Topic
+				turbiniaZone := values.Turbinia.Zone
+				diskNames := output.DiskNames
+				if err := services.SendTurbinia(ctx, turbiniaProjectID, turbiniaTopicName, turbiniaZone, diskNames); err != nil {
+					return err
+				}
+				s.Logger.Info("sent %d disks to turbinia", len(diskNames))
+			}
+		}
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !sra_action || sra_gce_quarantine_instance
+// +build !sra_action sra_gce_quarantine_instance
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("QuarantineInstance", QuarantineInstance, services.WithResource, services.WithHost, services.WithFirewall)
+
+// QuarantineInstance isolates a GCE instance from the network.
+//
+// This Cloud Function will respond to network threat detections such as those raised by Cloud IDS.
+// The affected instance is tagged for quarantine and firewall rules denying all ingress and
+// egress traffic of quarantined instances are added to its networks.
+//
+// Permissions required
+//	- roles/viewer to retrieve ancestry.
+//	- roles/compute.instanceAdmin.v1 to find instances and set their network tags.
+//	- roles/compute.securityAdmin to create firewall rules.
+//
+func QuarantineInstance(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gce_quarantine_instance", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gce_quarantine_instance", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values quarantineinstance.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return quarantineinstance.Execute(ctx, &values, &quarantineinstance.Services{
+			Host:     s.Host,
+			Firewall: s.Firewall,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !sra_action || sra_gce_require_os_login
+// +build !sra_action sra_gce_require_os_login
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/requireoslogin"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RequireOSLogin", RequireOSLogin, services.WithOrgPolicy)
+
+// RequireOSLogin enforces OS Login on a project through its organization policy.
+//
+// This Cloud Function will respond to Event Threat Detection **SSH Brute Force** findings. The
+// constraints/compute.requireOsLogin constraint is enforced on the affected project so SSH keys
+// in the metadata of the project or of its instances no longer grant access.
+//
+// Permissions required
+//	- roles/orgpolicy.policyAdmin to set the organization policies of projects.
+//
+func RequireOSLogin(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gce_require_os_login", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gce_require_os_login", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values requireoslogin.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return requireoslogin.Execute(ctx, &values, &requireoslogin.Services{
+			OrgPolicy: s.OrgPolicy,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
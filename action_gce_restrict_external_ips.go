@@ -0,0 +1,62 @@
+//go:build !sra_action || sra_gce_restrict_external_ips
+// +build !sra_action sra_gce_restrict_external_ips
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/restrictexternalips"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RestrictExternalIPs", RestrictExternalIPs, services.WithResource, services.WithOrgPolicy)
+
+// RestrictExternalIPs restricts the instances allowed external IP addresses through the
+// organization policy of a project or its folder.
+//
+// This Cloud Function will respond to Security Health Analytics **Public IP Address** findings
+// from **Compute Instance Scanner**. Once the project had as many findings as the configured
+// threshold, counted in the remediation history, constraints/compute.vmExternalIpAccess denies
+// every instance or all but the allowlisted ones an external IP address.
+//
+// Permissions required
+//	- roles/orgpolicy.policyAdmin to set the organization policies of projects and folders.
+//	- roles/viewer to retrieve the folder of the project.
+//
+func RestrictExternalIPs(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gce_restrict_external_ips", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gce_restrict_external_ips", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values restrictexternalips.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return restrictexternalips.Execute(ctx, &values, &restrictexternalips.Services{
+			OrgPolicy: s.OrgPolicy,
+			Resource:  s.Resource,
+			History:   history,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !sra_action || sra_gcs_prevent_public_access
+// +build !sra_action sra_gcs_prevent_public_access
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/preventpublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("PreventPublicAccess", PreventPublicAccess, services.WithResource, services.WithOrgPolicy)
+
+// PreventPublicAccess enforces public access prevention on the folder of a project through its
+// organization policy.
+//
+// This Cloud Function will respond to Security Health Analytics **Public Bucket ACL** findings
+// from **Storage Scanner**. Once the folder had as many findings as the configured threshold,
+// counted in the remediation history, constraints/storage.publicAccessPrevention is enforced on
+// the folder, preventing every bucket within it from being shared publicly.
+//
+// Permissions required
+//	- roles/orgpolicy.policyAdmin to set the organization policies of folders.
+//	- roles/viewer to retrieve the folders of projects.
+//
+func PreventPublicAccess(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gcs_prevent_public_access", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gcs_prevent_public_access", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values preventpublicaccess.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return preventpublicaccess.Execute(ctx, &values, &preventpublicaccess.Services{
+			OrgPolicy: s.OrgPolicy,
+			Resource:  s.Resource,
+			History:   history,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !sra_action || sra_gke_cordon_node
+// +build !sra_action sra_gke_cordon_node
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/cordonnode"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("CordonNode", CordonNode, services.WithResource, services.WithKubernetes)
+
+// CordonNode cordons a Kubernetes node.
+//
+// This Cloud Function will respond to container runtime alerts such as those raised by Falco.
+// The node running the affected workload is marked unschedulable so no new pods are placed on it.
+//
+// Permissions required
+//	- roles/container.admin to get clusters and update nodes.
+//
+func CordonNode(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gke_cordon_node", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gke_cordon_node", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values cordonnode.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return cordonnode.Execute(ctx, &values, &cordonnode.Services{
+			Kubernetes: s.Kubernetes,
+			Resource:   s.Resource,
+			Logger:     s.Logger,
+		})
+	default:
+		return err
+	}
+}
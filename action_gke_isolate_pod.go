@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_gke_isolate_pod
+// +build !sra_action sra_gke_isolate_pod
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/isolatepod"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("IsolatePod", IsolatePod, services.WithResource, services.WithKubernetes)
+
+// IsolatePod isolates a Kubernetes pod from the network.
+//
+// This Cloud Function will respond to container runtime alerts such as those raised by Falco.
+// The affected pod is labeled for quarantine and a network policy denying all ingress and
+// egress traffic for quarantined pods is created in its namespace.
+//
+// Permissions required
+//	- roles/container.developer to label pods and create network policies.
+//
+func IsolatePod(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "gke_isolate_pod", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "gke_isolate_pod", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values isolatepod.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return isolatepod.Execute(ctx, &values, &isolatepod.Services{
+			Kubernetes: s.Kubernetes,
+			Resource:   s.Resource,
+			Logger:     s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,81 @@
+//go:build !sra_action || sra_iam_contain_service_account
+// +build !sra_action sra_iam_contain_service_account
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/containserviceaccount"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("ContainServiceAccount", ContainServiceAccount, services.WithResource)
+
+// ContainServiceAccount contains a service account that may be compromised.
+//
+// This Cloud Function will respond to Event Threat Detection findings reporting a service account
+// acting suspiciously. In one step, so that a playbook runs it as a single step, the user-managed
+// keys of the service account are disabled, it is removed from the IAM policies of its project and
+// of the finding's project, and the owners of its project are emailed how to restore it. Above
+// fan_out_above projects their removals are published to the threat-findings-fan-out topic instead.
+//
+// Permissions required
+//	- roles/iam.serviceAccountKeyAdmin to disable service account keys.
+//	- roles/resourcemanager.projectIamAdmin to revoke IAM grants.
+//	- roles/viewer to read the owners of projects.
+//	- roles/pubsub.publisher on the threat-findings-fan-out topic to fan out removals.
+//
+func ContainServiceAccount(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "iam_contain_service_account", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "iam_contain_service_account", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values containserviceaccount.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		i, err := services.InitIAM(ctx)
+		if err != nil {
+			return err
+		}
+		key, err := secret(ctx, "SENDGRID_API_KEY")
+		if err != nil {
+			return err
+		}
+		var fo *services.FanOut
+		if values.FanOutAbove > 0 {
+			ps, err := pubSub(ctx)
+			if err != nil {
+				return err
+			}
+			fo = services.NewFanOut(ps, m.Attributes[services.CorrelationAttribute])
+		}
+		return containserviceaccount.Execute(ctx, &values, &containserviceaccount.Services{
+			IAM:      i,
+			Resource: s.Resource,
+			Email:    services.InitEmail(key),
+			FanOut:   fo,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
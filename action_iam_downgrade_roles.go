@@ -0,0 +1,64 @@
+//go:build !sra_action || sra_iam_downgrade_roles
+// +build !sra_action sra_iam_downgrade_roles
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/downgraderoles"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("DowngradeRoles", DowngradeRoles, services.WithResource)
+
+// DowngradeRoles replaces the roles of over-privileged members by the roles IAM Recommender suggests.
+//
+// This Cloud Function will respond to Security Health Analytics findings reporting primitive roles
+// or over-privileged service accounts. The active IAM recommendations of the project for the
+// members of the finding are applied, replacing their roles by the smaller roles they use instead
+// of removing their access.
+//
+// Permissions required
+//	- roles/resourcemanager.projectIamAdmin to update IAM policies.
+//	- roles/recommender.iamAdmin to read and mark IAM recommendations.
+//
+func DowngradeRoles(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "iam_downgrade_roles", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "iam_downgrade_roles", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values downgraderoles.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		rec, err := services.InitRecommender(ctx)
+		if err != nil {
+			return err
+		}
+		return downgraderoles.Execute(ctx, &values, &downgraderoles.Services{
+			Resource:    s.Resource,
+			Recommender: rec,
+			Logger:      s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,72 @@
+//go:build !sra_action || sra_iam_revoke
+// +build !sra_action sra_iam_revoke
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("IAMRevoke", IAMRevoke, services.WithResource)
+
+// IAMRevoke is the entry point for the IAM revoker Cloud Function.
+//
+// This function will attempt to revoke the external members added to the policy if they
+// match the provided list of disallowed domains. Additionally this method will only remove
+// members if the project they were added to is within the specified folders. This
+// configuration allows you to take a remediation action only on specific members and folders.
+// For example, you may have a folder "development" where users can experiment without strict
+// policies. However in your "production" folder you may want to revoke any grants that ETD
+// finds as long as they match the domains you specify. Grants can instead be replaced by grants
+// expiring after a number of hours, the grantor being emailed through SendGrid.
+//
+// Permissions required
+// 	- roles/resourcemanager.folderAdmin to revoke IAM grants.
+//	- roles/viewer to verify the affected project is within the enforced folder.
+//
+func IAMRevoke(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "iam_revoke", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "iam_revoke", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values revoke.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		var email *services.Email
+		if values.ExpireAfterHours > 0 && values.From != "" {
+			key, err := secret(ctx, "SENDGRID_API_KEY")
+			if err != nil {
+				return err
+			}
+			email = services.InitEmail(key)
+		}
+		return revoke.Execute(ctx, &values, &revoke.Services{
+			Resource: s.Resource,
+			Logger:   s.Logger,
+			Email:    email,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_kms_remove_public_access
+// +build !sra_action sra_kms_remove_public_access
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/removepublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RemoveKeyPublicAccess", RemoveKeyPublicAccess, services.WithKMS)
+
+// RemoveKeyPublicAccess removes public and external members from a Cloud KMS crypto key or key ring.
+//
+// This Cloud Function will respond to Security Health Analytics **KMS Public Key** findings from
+// **KMS Scanner**. allUsers and allAuthenticatedUsers are removed from the policies of the key and
+// of its key ring along with the users, groups and domains outside the allowed domains. The
+// removed grants are logged as changes so they can be restored.
+//
+// Permissions required
+//	- roles/cloudkms.admin to get and set the IAM policies of crypto keys and key rings.
+//
+func RemoveKeyPublicAccess(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "kms_remove_public_access", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "kms_remove_public_access", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values removepublicaccess.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return removepublicaccess.Execute(ctx, &values, &removepublicaccess.Services{
+			KMS:    s.KMS,
+			Logger: s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !sra_action || sra_kms_rotate_key
+// +build !sra_action sra_kms_rotate_key
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/rotatekey"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RotateKey", RotateKey, services.WithKMS)
+
+// RotateKey sets the rotation period and next rotation time of a Cloud KMS crypto key.
+//
+// This Cloud Function will respond to Security Health Analytics **KMS Key Not Rotated** findings
+// from **KMS Scanner**. The key is rotated every configured period, 90 days by default, from its
+// next rotation on, a day after by default.
+//
+// Permissions required
+//	- roles/cloudkms.admin to get and update crypto keys.
+//
+func RotateKey(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "kms_rotate_key", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "kms_rotate_key", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values rotatekey.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return rotatekey.Execute(ctx, &values, &rotatekey.Services{
+			KMS:    s.KMS,
+			Logger: s.Logger,
+		})
+	default:
+		return err
+	}
+}
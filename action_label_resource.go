@@ -0,0 +1,60 @@
+//go:build !sra_action || sra_label_resource
+// +build !sra_action sra_label_resource
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/labelresource"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("LabelResource", LabelResource, services.WithResource, services.WithHost)
+
+// LabelResource labels the resource of a finding for investigation.
+//
+// This Cloud Function labels the instance, bucket or project a finding is about with
+// security-incident=<finding ID> and triage=pending without remediating it, as the default for
+// the categories not yet automatically remediated.
+//
+// Permissions required
+//	- roles/compute.instanceAdmin.v1 to set the labels of instances.
+//	- roles/storage.admin to set the labels of buckets.
+//	- roles/resourcemanager.projectMover to set the labels of projects.
+//
+func LabelResource(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "label_resource", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "label_resource", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values labelresource.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return labelresource.Execute(ctx, &values, &labelresource.Services{
+			Host:     s.Host,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+//go:build !sra_action || sra_quarantine_image
+// +build !sra_action sra_quarantine_image
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/binauthz/quarantineimage"
+)
+
+var _ = addAction("QuarantineImage", QuarantineImage)
+
+// QuarantineImage quarantines a container image with critical vulnerabilities.
+//
+// This Cloud Function will respond to Container Analysis vulnerability occurrences. The
+// attestations of the image made by the configured attestor are revoked so Binary Authorization
+// policies requiring the attestor block new deployments of the image.
+//
+// Permissions required
+//	- roles/containeranalysis.occurrences.editor to list and delete attestations.
+//
+func QuarantineImage(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "quarantine_image", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "quarantine_image", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values quarantineimage.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		ca, err := containerAnalysis(ctx)
+		if err != nil {
+			return err
+		}
+		return quarantineimage.Execute(ctx, &values, &quarantineimage.Services{
+			ContainerAnalysis: ca,
+			Logger:            s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+//go:build !sra_action || sra_remediate_firewall
+// +build !sra_action sra_remediate_firewall
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("OpenFirewall", OpenFirewall, services.WithResource, services.WithFirewall)
+
+// OpenFirewall will remediate an open firewall.
+//
+// Permissions required
+//	- roles/viewer to retrieve ancestry.
+//	- roles/compute.securityAdmin to modify firewall rules.
+//
+func OpenFirewall(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "remediate_firewall", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "remediate_firewall", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values openfirewall.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		err := openfirewall.Execute(ctx, &values, &openfirewall.Services{
+			Firewall: s.Firewall,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+		if err != nil {
+			return err
+		}
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+//go:build !sra_action || sra_remove_non_org_members
+// +build !sra_action sra_remove_non_org_members
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/removenonorgmembers"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RemoveNonOrganizationMembers", RemoveNonOrganizationMembers, services.WithResource)
+
+// RemoveNonOrganizationMembers removes all members that do not match the organization domain.
+//
+// This Cloud Function will respond to Security Health Analytics **NON_ORG_IAM_MEMBER** findings from **IAM Scanner**.
+// All user member types (user:) that do not correspond to the organization will be removed from policy binding.
+// When groups are expanded, groups (group:) with direct or nested members that do not correspond to
+// the organization are removed too.
+//
+// Permissions required
+//	- roles/resourcemanager.organizationAdmin to get org info and policies and set policies.
+//	- Groups Reader admin role in Google Workspace or Cloud Identity to expand groups.
+//
+func RemoveNonOrganizationMembers(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "remove_non_org_members", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "remove_non_org_members", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values removenonorgmembers.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		var ci *services.CloudIdentity
+		if values.ExpandGroups {
+			if ci, err = services.InitCloudIdentity(ctx); err != nil {
+				return err
+			}
+		}
+		return removenonorgmembers.Execute(ctx, &values, &removenonorgmembers.Services{
+			Logger:        s.Logger,
+			Resource:      s.Resource,
+			CloudIdentity: ci,
+		})
+	default:
+		return err
+	}
+}
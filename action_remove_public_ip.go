@@ -0,0 +1,58 @@
+//go:build !sra_action || sra_remove_public_ip
+// +build !sra_action sra_remove_public_ip
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RemovePublicIP", RemovePublicIP, services.WithResource, services.WithHost)
+
+// RemovePublicIP removes all the external IP addresses of a GCE instance.
+//
+// This Cloud Function will respond to Security Health Analytics **Public IP Address** findings
+// from **Compute Instance Scanner**. All public IP addresses of the affected instance will be
+// deleted when this function is activated.
+//
+// Permissions required
+//	- roles/compute.instanceAdmin.v1 to get instance data and delete access config.
+//
+func RemovePublicIP(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "remove_public_ip", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "remove_public_ip", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values removepublicip.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return removepublicip.Execute(ctx, &values, &removepublicip.Services{
+			Host:     s.Host,
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !sra_action || sra_send_email
+// +build !sra_action sra_send_email
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/notify/sendemail"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("SendEmail", SendEmail)
+
+// SendEmail sends a notification email about a finding.
+//
+// Emails are sent through SendGrid with the API key held in the SENDGRID_API_KEY environment
+// variable, or in the Secret Manager secret it references.
+//
+// Permissions required
+//	- None, only the SendGrid API key.
+//
+func SendEmail(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "send_email", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "send_email", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values sendemail.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		key, err := secret(ctx, "SENDGRID_API_KEY")
+		if err != nil {
+			return err
+		}
+		values.CorrelationID = m.Attributes[services.CorrelationAttribute]
+		return sendemail.Execute(ctx, &values, &sendemail.Services{
+			Email:    services.InitEmail(key),
+			Logger:   s.Logger,
+			Redactor: redactor,
+		})
+	default:
+		return err
+	}
+}
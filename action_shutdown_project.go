@@ -0,0 +1,59 @@
+//go:build !sra_action || sra_shutdown_project
+// +build !sra_action sra_shutdown_project
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/resourcemanager/shutdownproject"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("ShutdownProject", ShutdownProject, services.WithResource)
+
+// ShutdownProject shuts down the project of a finding, or places a lien on it, such as a project
+// created by an attacker under a compromised organization admin.
+//
+// This Cloud Function will respond to any Security Command Center, Event Threat Detection or custom
+// finding the action is configured for. It is held for approval by default, shut down projects can
+// be restored within 30 days.
+//
+// Permissions required
+//	- roles/resourcemanager.projectDeleter to shut down projects.
+//	- roles/resourcemanager.lienModifier to place liens on projects.
+//
+func ShutdownProject(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "shutdown_project", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "shutdown_project", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values shutdownproject.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		return shutdownproject.Execute(ctx, &values, &shutdownproject.Services{
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
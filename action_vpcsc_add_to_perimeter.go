@@ -0,0 +1,63 @@
+//go:build !sra_action || sra_vpcsc_add_to_perimeter
+// +build !sra_action sra_vpcsc_add_to_perimeter
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/addtoperimeter"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("AddToPerimeter", AddToPerimeter, services.WithResource)
+
+// AddToPerimeter adds a project to a restricted VPC Service Controls perimeter.
+//
+// This Cloud Function will respond to Event Threat Detection findings reporting BigQuery or Cloud
+// SQL data exfiltration. The project is added to the configured perimeter, blocking API-level
+// exfiltration of its data to resources outside of the perimeter.
+//
+// Permissions required
+//	- roles/accesscontextmanager.policyEditor on the organization to update perimeters.
+//	- roles/viewer to read the number of projects.
+//
+func AddToPerimeter(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "vpcsc_add_to_perimeter", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "vpcsc_add_to_perimeter", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values addtoperimeter.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		a, err := services.InitAccessContextManager(ctx)
+		if err != nil {
+			return err
+		}
+		return addtoperimeter.Execute(ctx, &values, &addtoperimeter.Services{
+			AccessContextManager: a,
+			Resource:             s.Resource,
+			Logger:               s.Logger,
+		})
+	default:
+		return err
+	}
+}
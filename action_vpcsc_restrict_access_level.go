@@ -0,0 +1,61 @@
+//go:build !sra_action || sra_vpcsc_restrict_access_level
+// +build !sra_action sra_vpcsc_restrict_access_level
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/restrictaccesslevel"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RestrictAccessLevel", RestrictAccessLevel)
+
+// RestrictAccessLevel excludes a compromised principal or IP range from an access level.
+//
+// This Cloud Function will respond to Event Threat Detection findings reporting a compromised
+// account. The principal is excluded from the configured access level, cutting off its access to
+// the services protected by VPC Service Controls perimeters admitting the level.
+//
+// Permissions required
+//	- roles/accesscontextmanager.policyEditor on the organization to update access levels.
+//
+func RestrictAccessLevel(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "vpcsc_restrict_access_level", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "vpcsc_restrict_access_level", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values restrictaccesslevel.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		a, err := services.InitAccessContextManager(ctx)
+		if err != nil {
+			return err
+		}
+		return restrictaccesslevel.Execute(ctx, &values, &restrictaccesslevel.Services{
+			AccessContextManager: a,
+			Logger:               s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !sra_action || sra_workspace_enforce_2sv
+// +build !sra_action sra_workspace_enforce_2sv
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/enforce2sv"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("Enforce2SV", Enforce2SV)
+
+// Enforce2SV enforces 2-Step Verification for a Google Workspace or Cloud Identity user.
+//
+// This Cloud Function will respond to Event Threat Detection findings reporting suspicious logins
+// of a user. The user is moved into an organizational unit, or added to a group, configured to
+// enforce 2-Step Verification. Users it is already enforced for are left as they are.
+//
+// Permissions required
+//	- The User Management Admin role of the Workspace account to move users between organizational units.
+//	- The Groups Admin role of the Workspace account to add group members.
+//
+func Enforce2SV(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "workspace_enforce_2sv", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "workspace_enforce_2sv", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values enforce2sv.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		d, err := services.InitDirectory(ctx)
+		if err != nil {
+			return err
+		}
+		return enforce2sv.Execute(ctx, &values, &enforce2sv.Services{
+			Directory: d,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !sra_action || sra_workspace_revoke_sessions
+// +build !sra_action sra_workspace_revoke_sessions
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/revokesessions"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("RevokeSessions", RevokeSessions)
+
+// RevokeSessions signs a Google Workspace or Cloud Identity user out and revokes their OAuth tokens.
+//
+// This Cloud Function will respond to Event Threat Detection findings reporting leaked credentials
+// or suspicious logins of a user. Unlike SuspendUser the user can sign in again straight away, the
+// sessions and third-party application grants an attacker may hold are invalidated.
+//
+// Permissions required
+//	- The User Management Admin role of the Workspace account to sign out users and revoke tokens.
+//
+func RevokeSessions(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "workspace_revoke_sessions", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "workspace_revoke_sessions", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values revokesessions.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		d, err := services.InitDirectory(ctx)
+		if err != nil {
+			return err
+		}
+		return revokesessions.Execute(ctx, &values, &revokesessions.Services{
+			Directory: d,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
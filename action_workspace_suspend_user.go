@@ -0,0 +1,61 @@
+//go:build !sra_action || sra_workspace_suspend_user
+// +build !sra_action sra_workspace_suspend_user
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/suspenduser"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+var _ = addAction("SuspendUser", SuspendUser)
+
+// SuspendUser suspends a Google Workspace or Cloud Identity user.
+//
+// This Cloud Function will respond to Event Threat Detection findings reporting leaked credentials
+// or suspicious logins of a user. The user is suspended, signing them out everywhere, until an
+// administrator restores them. Automations are held for approval unless configured otherwise.
+//
+// Permissions required
+//	- The User Management Admin role of the Workspace account to suspend users.
+//
+func SuspendUser(ctx context.Context, m pubsub.Message) (err error) {
+	r, ok := claim(ctx, "workspace_suspend_user", m)
+	if !ok {
+		return nil
+	}
+	defer record(ctx, "workspace_suspend_user", r, m, &err)
+	ctx, s := route(ctx, m)
+	var values suspenduser.Values
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		d, err := services.InitDirectory(ctx)
+		if err != nil {
+			return err
+		}
+		return suspenduser.Execute(ctx, &values, &suspenduser.Services{
+			Directory: d,
+			Logger:    s.Logger,
+		})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,160 @@
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// cacheKey identifies a cached client by what it was created for and the credentials it
+// authenticates with.
+type cacheKey struct {
+	name     string
+	provider clients.CredentialsProvider
+}
+
+var (
+	cacheMu sync.Mutex
+	// cache holds the clients created by earlier invocations of the function instance.
+	cache = make(map[cacheKey]interface{})
+)
+
+// cached returns the client created for the name and the context's credentials by an earlier
+// invocation, creating it with create on first use. Errors are not cached.
+//
+// create is given a context that outlives the invocation, with the same credentials.
+func cached(ctx context.Context, name string, create func(context.Context) (interface{}, error)) (interface{}, error) {
+	k := cacheKey{name: name, provider: clients.ProviderFrom(ctx)}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if v, ok := cache[k]; ok {
+		return v, nil
+	}
+	detached := context.Background()
+	if k.provider != nil {
+		detached = clients.WithProvider(detached, k.provider)
+	}
+	v, err := create(detached)
+	if err != nil {
+		return nil, err
+	}
+	cache[k] = v
+	return v, nil
+}
+
+// memo holds a value computed by an earlier invocation of the function instance until it expires.
+type memo struct {
+	mu       sync.Mutex
+	value    interface{}
+	computed time.Time
+}
+
+// get returns the value computed less than ttl ago, computing it again with compute otherwise.
+// Errors are not cached, a ttl of 0 computes the value every time.
+func (m *memo) get(ttl time.Duration, compute func() (interface{}, error)) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.computed.IsZero() && time.Since(m.computed) < ttl {
+		return m.value, nil
+	}
+	v, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	m.value, m.computed = v, time.Now()
+	return v, nil
+}
+
+// pubSub returns the Pub/Sub service of the automation project.
+func pubSub(ctx context.Context) (*services.PubSub, error) {
+	v, err := cached(ctx, "pubsub", func(ctx context.Context) (interface{}, error) {
+		return services.InitPubSub(ctx, projectID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.PubSub), nil
+}
+
+// bigQuery returns the BigQuery service of the project.
+func bigQuery(ctx context.Context, projectID string) (*services.BigQuery, error) {
+	v, err := cached(ctx, "bigquery/"+projectID, func(ctx context.Context) (interface{}, error) {
+		return services.InitBigQuery(ctx, projectID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.BigQuery), nil
+}
+
+// containerAnalysis returns the Container Analysis service.
+func containerAnalysis(ctx context.Context) (*services.ContainerAnalysis, error) {
+	v, err := cached(ctx, "containeranalysis", func(ctx context.Context) (interface{}, error) {
+		return services.InitContainerAnalysis(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.ContainerAnalysis), nil
+}
+
+// awsServices returns the AWS service of the region.
+func awsServices(ctx context.Context, region string) (*services.AWS, error) {
+	v, err := cached(ctx, "aws/"+region, func(context.Context) (interface{}, error) {
+		return services.InitAWS(region)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.AWS), nil
+}
+
+// policyStore returns the policy service reading the policies of POLICY_BUCKET.
+func policyStore(ctx context.Context) (*services.Policy, error) {
+	v, err := cached(ctx, "policy", func(ctx context.Context) (interface{}, error) {
+		return services.InitPolicy(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.Policy), nil
+}
+
+// logs returns the service reading log entries.
+func logs(ctx context.Context) (*services.Logs, error) {
+	v, err := cached(ctx, "logs", func(ctx context.Context) (interface{}, error) {
+		return services.InitLogs(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.Logs), nil
+}
+
+// archiveOf returns the archive service storing objects in the bucket.
+func archiveOf(ctx context.Context, bucket string) (*services.Archive, error) {
+	v, err := cached(ctx, "archive/"+bucket, func(ctx context.Context) (interface{}, error) {
+		return services.InitArchive(ctx, bucket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*services.Archive), nil
+}
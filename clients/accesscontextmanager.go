@@ -0,0 +1,63 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	acm "google.golang.org/api/accesscontextmanager/v1"
+	"google.golang.org/api/option"
+)
+
+// AccessContextManager client managing the VPC Service Controls perimeters and access levels of an
+// access policy.
+type AccessContextManager struct {
+	service *acm.Service
+}
+
+// NewAccessContextManager returns and initializes an Access Context Manager client.
+func NewAccessContextManager(ctx context.Context) (*AccessContextManager, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := acm.NewService(ctx, append(apiOptions("accesscontextmanager"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init access context manager: %q", err)
+	}
+	return &AccessContextManager{service: s}, nil
+}
+
+// GetServicePerimeter returns the perimeter, named "accessPolicies/<id>/servicePerimeters/<name>".
+func (a *AccessContextManager) GetServicePerimeter(ctx context.Context, name string) (*acm.ServicePerimeter, error) {
+	return a.service.AccessPolicies.ServicePerimeters.Get(name).Context(ctx).Do()
+}
+
+// PatchServicePerimeter updates the fields of the perimeter listed in the mask, such as
+// "status.resources".
+func (a *AccessContextManager) PatchServicePerimeter(ctx context.Context, name string, perimeter *acm.ServicePerimeter, mask string) (*acm.Operation, error) {
+	return a.service.AccessPolicies.ServicePerimeters.Patch(name, perimeter).UpdateMask(mask).Context(ctx).Do()
+}
+
+// GetAccessLevel returns the access level, named "accessPolicies/<id>/accessLevels/<name>".
+func (a *AccessContextManager) GetAccessLevel(ctx context.Context, name string) (*acm.AccessLevel, error) {
+	return a.service.AccessPolicies.AccessLevels.Get(name).Context(ctx).Do()
+}
+
+// PatchAccessLevel updates the fields of the access level listed in the mask, such as "basic".
+func (a *AccessContextManager) PatchAccessLevel(ctx context.Context, name string, level *acm.AccessLevel, mask string) (*acm.Operation, error) {
+	return a.service.AccessPolicies.AccessLevels.Patch(name, level).UpdateMask(mask).Context(ctx).Do()
+}
@@ -0,0 +1,232 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// iamRegion is the region used to sign requests to the global IAM endpoint.
+	iamRegion  = "us-east-1"
+	iamVersion = "2010-05-08"
+	ec2Version = "2016-11-15"
+)
+
+// AWSCredentials holds the access key used to sign requests.
+type AWSCredentials struct {
+	AccessKeyID, SecretAccessKey, SessionToken string
+}
+
+// AWS client calls the IAM and EC2 query APIs of Amazon Web Services.
+type AWS struct {
+	credentials AWSCredentials
+	region      string
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewAWS returns and initializes an AWS client for the region using credentials from the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optional AWS_SESSION_TOKEN environment variables.
+func NewAWS(region string) (*AWS, error) {
+	c := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("aws region must be set")
+	}
+	return &AWS{credentials: c, region: region, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}, nil
+}
+
+// UpdateAccessKey sets the status of an IAM user's access key to Active or Inactive.
+func (a *AWS) UpdateAccessKey(ctx context.Context, userName, accessKeyID, status string) error {
+	params := url.Values{
+		"Action":      {"UpdateAccessKey"},
+		"UserName":    {userName},
+		"AccessKeyId": {accessKeyID},
+		"Status":      {status},
+	}
+	return a.call(ctx, "iam", iamRegion, "iam.amazonaws.com", iamVersion, params, nil)
+}
+
+// SecurityGroupID returns the ID of the named security group in the VPC, or an empty string if it does not exist.
+func (a *AWS) SecurityGroupID(ctx context.Context, vpcID, name string) (string, error) {
+	params := url.Values{
+		"Action":           {"DescribeSecurityGroups"},
+		"Filter.1.Name":    {"vpc-id"},
+		"Filter.1.Value.1": {vpcID},
+		"Filter.2.Name":    {"group-name"},
+		"Filter.2.Value.1": {name},
+	}
+	var out struct {
+		Groups []struct {
+			GroupID string `xml:"groupId"`
+		} `xml:"securityGroupInfo>item"`
+	}
+	if err := a.call(ctx, "ec2", a.region, a.ec2Host(), ec2Version, params, &out); err != nil {
+		return "", err
+	}
+	if len(out.Groups) == 0 {
+		return "", nil
+	}
+	return out.Groups[0].GroupID, nil
+}
+
+// CreateSecurityGroup creates a security group in the VPC and returns its ID.
+func (a *AWS) CreateSecurityGroup(ctx context.Context, vpcID, name, description string) (string, error) {
+	params := url.Values{
+		"Action":           {"CreateSecurityGroup"},
+		"VpcId":            {vpcID},
+		"GroupName":        {name},
+		"GroupDescription": {description},
+	}
+	var out struct {
+		GroupID string `xml:"groupId"`
+	}
+	if err := a.call(ctx, "ec2", a.region, a.ec2Host(), ec2Version, params, &out); err != nil {
+		return "", err
+	}
+	return out.GroupID, nil
+}
+
+// RevokeSecurityGroupEgress removes the rule allowing all egress traffic to the range from the security group.
+func (a *AWS) RevokeSecurityGroupEgress(ctx context.Context, groupID, cidr string) error {
+	params := url.Values{
+		"Action":                            {"RevokeSecurityGroupEgress"},
+		"GroupId":                           {groupID},
+		"IpPermissions.1.IpProtocol":        {"-1"},
+		"IpPermissions.1.IpRanges.1.CidrIp": {cidr},
+	}
+	return a.call(ctx, "ec2", a.region, a.ec2Host(), ec2Version, params, nil)
+}
+
+// SetInstanceSecurityGroups replaces the security groups of the instance's primary network interface.
+func (a *AWS) SetInstanceSecurityGroups(ctx context.Context, instanceID string, groupIDs []string) error {
+	params := url.Values{
+		"Action":     {"ModifyInstanceAttribute"},
+		"InstanceId": {instanceID},
+	}
+	for i, id := range groupIDs {
+		params.Set(fmt.Sprintf("GroupId.%d", i+1), id)
+	}
+	return a.call(ctx, "ec2", a.region, a.ec2Host(), ec2Version, params, nil)
+}
+
+func (a *AWS) ec2Host() string {
+	return fmt.Sprintf("ec2.%s.amazonaws.com", a.region)
+}
+
+// call sends a signed query API request and decodes the XML response into out if set.
+func (a *AWS) call(ctx context.Context, service, region, host, version string, params url.Values, out interface{}) error {
+	params.Set("Version", version)
+	body := params.Encode()
+	req, err := http.NewRequest(http.MethodPost, "https://"+host+"/", strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	SignAWSRequest(req, []byte(body), a.credentials, service, region, a.now())
+	resp, err := a.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s %s failed with status %d: %s", service, params.Get("Action"), resp.StatusCode, b)
+	}
+	if out == nil {
+		return nil
+	}
+	return xml.Unmarshal(b, out)
+}
+
+// SignAWSRequest adds Signature Version 4 authentication headers to the request.
+func SignAWSRequest(req *http.Request, body []byte, c AWSCredentials, service, region string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+
+	var names []string
+	for k := range req.Header {
+		names = append(names, strings.ToLower(k))
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, n := range names {
+		headers.WriteString(n + ":" + strings.TrimSpace(req.Header.Get(n)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payload := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		signedHeaders,
+		hex.EncodeToString(payload[:]),
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonical))
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	toSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	for _, s := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the query sorted by key with spaces encoded as %20.
+func canonicalQuery(v url.Values) string {
+	return strings.Replace(v.Encode(), "+", "%20", -1)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/option"
 )
 
 // BigQuery client.
@@ -28,7 +29,11 @@ type BigQuery struct {
 
 // NewBigQuery returns the BigQuery client.
 func NewBigQuery(ctx context.Context, projectID string) (*BigQuery, error) {
-	client, err := bigquery.NewClient(ctx, projectID)
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	client, err := bigquery.NewClient(ctx, projectID, append(apiOptions("bigquery"), option.WithHTTPClient(hc))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init bigquery: %q", err)
 	}
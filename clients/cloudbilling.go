@@ -0,0 +1,54 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+	"google.golang.org/api/option"
+)
+
+// CloudBilling client.
+type CloudBilling struct {
+	service *cloudbilling.APIService
+}
+
+// NewCloudBilling returns and initializes a Cloud Billing client.
+func NewCloudBilling(ctx context.Context) (*CloudBilling, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := cloudbilling.NewService(ctx, append(apiOptions("cloudbilling"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cloud billing: %q", err)
+	}
+	return &CloudBilling{service: s}, nil
+}
+
+// GetBillingInfo returns the billing information of the project.
+func (c *CloudBilling) GetBillingInfo(ctx context.Context, projectID string) (*cloudbilling.ProjectBillingInfo, error) {
+	return c.service.Projects.GetBillingInfo("projects/" + projectID).Context(ctx).Do()
+}
+
+// UpdateBillingInfo sets the billing account of the project, an empty account name disables its
+// billing.
+func (c *CloudBilling) UpdateBillingInfo(ctx context.Context, projectID string, info *cloudbilling.ProjectBillingInfo) (*cloudbilling.ProjectBillingInfo, error) {
+	// The account name is omitted when empty unless forced, leaving billing unchanged.
+	info.ForceSendFields = append(info.ForceSendFields, "BillingAccountName")
+	return c.service.Projects.UpdateBillingInfo("projects/"+projectID, info).Context(ctx).Do()
+}
@@ -0,0 +1,60 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+// CloudIdentity client managing the groups of a Google Workspace or Cloud Identity account.
+type CloudIdentity struct {
+	service *cloudidentity.Service
+}
+
+// NewCloudIdentity returns and initializes a CloudIdentity client.
+func NewCloudIdentity(ctx context.Context) (*CloudIdentity, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := cloudidentity.NewService(ctx, append(apiOptions("cloudidentity"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cloudidentity: %q", err)
+	}
+	return &CloudIdentity{service: s}, nil
+}
+
+// LookupGroup returns the resource name of the group, "groups/<id>", from its email address.
+func (c *CloudIdentity) LookupGroup(ctx context.Context, email string) (string, error) {
+	r, err := c.service.Groups.Lookup().GroupKeyId(email).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	return r.Name, nil
+}
+
+// ListMemberships returns the direct memberships of the group, named "groups/<id>".
+func (c *CloudIdentity) ListMemberships(ctx context.Context, group string) ([]*cloudidentity.Membership, error) {
+	var memberships []*cloudidentity.Membership
+	err := c.service.Groups.Memberships.List(group).Pages(ctx, func(r *cloudidentity.ListMembershipsResponse) error {
+		memberships = append(memberships, r.Memberships...)
+		return nil
+	})
+	return memberships, err
+}
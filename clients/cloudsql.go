@@ -20,6 +20,7 @@ import (
 	"log"
 	"time"
 
+	"google.golang.org/api/option"
 	sqladmin "google.golang.org/api/sqladmin/v1beta4"
 )
 
@@ -31,7 +32,11 @@ type CloudSQL struct {
 
 // NewCloudSQL returns and initializes a Cloud SQL client.
 func NewCloudSQL(ctx context.Context) (*CloudSQL, error) {
-	sql, err := sqladmin.NewService(ctx)
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	sql, err := sqladmin.NewService(ctx, append(apiOptions("sqladmin"), option.WithHTTPClient(hc))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init scc: %q", err)
 	}
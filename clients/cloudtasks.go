@@ -0,0 +1,52 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	cloudtasks "google.golang.org/api/cloudtasks/v2"
+	"google.golang.org/api/option"
+)
+
+// CloudTasks client.
+type CloudTasks struct {
+	service *cloudtasks.Service
+}
+
+// NewCloudTasks returns and initializes a Cloud Tasks client.
+func NewCloudTasks(ctx context.Context) (*CloudTasks, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	ct, err := cloudtasks.NewService(ctx, append(apiOptions("cloudtasks"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cloud tasks: %q", err)
+	}
+	return &CloudTasks{service: ct}, nil
+}
+
+// CreateTask creates a task in the queue.
+func (c *CloudTasks) CreateTask(ctx context.Context, queue string, task *cloudtasks.Task) (*cloudtasks.Task, error) {
+	return c.service.Projects.Locations.Queues.Tasks.Create(queue, &cloudtasks.CreateTaskRequest{Task: task}).Context(ctx).Do()
+}
+
+// DeleteTask deletes the task, which will not run.
+func (c *CloudTasks) DeleteTask(ctx context.Context, name string) error {
+	_, err := c.service.Projects.Locations.Queues.Tasks.Delete(name).Context(ctx).Do()
+	return err
+}
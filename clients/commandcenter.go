@@ -30,7 +30,11 @@ type SecurityCommandCenter struct {
 
 // NewSecurityCommandCenter returns and initializes a SecurityCommandCenter client.
 func NewSecurityCommandCenter(ctx context.Context) (*SecurityCommandCenter, error) {
-	scc, err := commandcenter.NewClient(ctx)
+	creds, err := withCredentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init credentials: %q", err)
+	}
+	scc, err := commandcenter.NewClient(ctx, append(apiOptions("securitycenter"), grpcRetry(), creds)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init scc: %q", err)
 	}
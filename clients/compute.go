@@ -21,6 +21,7 @@ import (
 	"time"
 
 	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
 )
 
 const (
@@ -41,7 +42,11 @@ type Compute struct {
 
 // NewCompute returns and initializes a Compute client.
 func NewCompute(ctx context.Context) (*Compute, error) {
-	cc, err := compute.NewService(ctx)
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	cc, err := compute.NewService(ctx, append(apiOptions("compute"), option.WithHTTPClient(hc))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init cs: %q", err)
 	}
@@ -99,14 +104,25 @@ func (c *Compute) CreateSnapshot(ctx context.Context, projectID, zone, disk stri
 	return c.compute.Disks.CreateSnapshot(projectID, zone, disk, rb).Context(ctx).Do()
 }
 
-// ListDisks returns a list of disk for a given project.
+// ListDisks returns a list of disk for a given project, with the disks of every page.
 func (c *Compute) ListDisks(ctx context.Context, projectID, zone string) (*compute.DiskList, error) {
-	return c.compute.Disks.List(projectID, zone).Context(ctx).Do()
+	list := &compute.DiskList{}
+	err := c.compute.Disks.List(projectID, zone).Pages(ctx, func(page *compute.DiskList) error {
+		list.Items = append(list.Items, page.Items...)
+		return nil
+	})
+	return list, err
 }
 
-// ListProjectSnapshots returns a list of snapshot reousrces for a given project.
+// ListProjectSnapshots returns a list of snapshot reousrces for a given project, with the
+// snapshots of every page.
 func (c *Compute) ListProjectSnapshots(ctx context.Context, projectID string) (*compute.SnapshotList, error) {
-	return c.compute.Snapshots.List(projectID).Context(ctx).Do()
+	list := &compute.SnapshotList{}
+	err := c.compute.Snapshots.List(projectID).Pages(ctx, func(page *compute.SnapshotList) error {
+		list.Items = append(list.Items, page.Items...)
+		return nil
+	})
+	return list, err
 }
 
 // SetLabels sets labels on a snapshot.
@@ -128,6 +144,21 @@ func (c *Compute) WaitGlobal(project string, op *compute.Operation) []error {
 	})
 }
 
+// AttachDisk attaches a disk to an instance.
+func (c *Compute) AttachDisk(ctx context.Context, projectID, zone, instance string, disk *compute.AttachedDisk) (*compute.Operation, error) {
+	return c.compute.Instances.AttachDisk(projectID, zone, instance, disk).Context(ctx).Do()
+}
+
+// DetachDisk detaches the disk attached to an instance under the given device name.
+func (c *Compute) DetachDisk(ctx context.Context, projectID, zone, instance, deviceName string) (*compute.Operation, error) {
+	return c.compute.Instances.DetachDisk(projectID, zone, instance, deviceName).Context(ctx).Do()
+}
+
+// SetInstanceLabels sets the labels of an instance.
+func (c *Compute) SetInstanceLabels(ctx context.Context, projectID, zone, instance string, rb *compute.InstancesSetLabelsRequest) (*compute.Operation, error) {
+	return c.compute.Instances.SetLabels(projectID, zone, instance, rb).Context(ctx).Do()
+}
+
 // StopInstance instance command to some instance/zone
 func (c *Compute) StopInstance(ctx context.Context, projectID, zone, instance string) (*compute.Operation, error) {
 	return c.compute.Instances.Stop(projectID, zone, instance).Context(ctx).Do()
@@ -143,6 +174,23 @@ func (c *Compute) DeleteInstance(ctx context.Context, projectID, zone, instance
 	return c.compute.Instances.Delete(projectID, zone, instance).Context(ctx).Do()
 }
 
+// SetInstanceTags sets the network tags of an instance.
+func (c *Compute) SetInstanceTags(ctx context.Context, projectID, zone, instance string, tags *compute.Tags) (*compute.Operation, error) {
+	return c.compute.Instances.SetTags(projectID, zone, instance, tags).Context(ctx).Do()
+}
+
+// ListInstances returns all instances of the project across zones.
+func (c *Compute) ListInstances(ctx context.Context, projectID string) ([]*compute.Instance, error) {
+	var instances []*compute.Instance
+	err := c.compute.Instances.AggregatedList(projectID).Pages(ctx, func(page *compute.InstanceAggregatedList) error {
+		for _, scoped := range page.Items {
+			instances = append(instances, scoped.Instances...)
+		}
+		return nil
+	})
+	return instances, err
+}
+
 func wait(op *compute.Operation, fn func() (*compute.Operation, error)) []error {
 	if op.Error != nil {
 		return returnErrorCodes(op.Error.Errors)
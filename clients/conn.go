@@ -0,0 +1,90 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc"
+)
+
+// connKey identifies a shared connection by the API it reaches and the credentials it
+// authenticates with.
+type connKey struct {
+	api      string
+	provider CredentialsProvider
+}
+
+var (
+	connMu sync.Mutex
+	// httpClients are the HTTP clients shared by the REST clients, by provider.
+	httpClients = make(map[CredentialsProvider]*http.Client)
+	// conns are the gRPC connections shared by the gRPC clients of an API, by provider.
+	conns = make(map[connKey]*grpc.ClientConn)
+)
+
+// detached returns a context that outlives the invocation with the credentials of ctx, as the
+// token sources of shared connections keep the context they are created with.
+func detached(ctx context.Context) context.Context {
+	if p := ProviderFrom(ctx); p != nil {
+		return WithProvider(context.Background(), p)
+	}
+	return context.Background()
+}
+
+// httpClient returns the authenticated HTTP client retrying with the default policy, shared by the
+// REST clients created with the same credentials so they reuse its connections and tokens.
+func httpClient(ctx context.Context) (*http.Client, error) {
+	p := ProviderFrom(ctx)
+	connMu.Lock()
+	defer connMu.Unlock()
+	if hc, ok := httpClients[p]; ok {
+		return hc, nil
+	}
+	hc, err := newHTTPClient(detached(ctx))
+	if err != nil {
+		return nil, err
+	}
+	httpClients[p] = hc
+	return hc, nil
+}
+
+// grpcConn returns the connection to the API's gRPC endpoint, shared by the clients created with
+// the same credentials. The endpoint is overridden like that of REST clients, by the API's entry
+// of API_ENDPOINTS. Unary calls are retried with the default policy.
+func grpcConn(ctx context.Context, api, endpoint string) (*grpc.ClientConn, error) {
+	k := connKey{api: api, provider: ProviderFrom(ctx)}
+	connMu.Lock()
+	defer connMu.Unlock()
+	if cc, ok := conns[k]; ok {
+		return cc, nil
+	}
+	ctx = detached(ctx)
+	creds, err := withCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]option.ClientOption{option.WithEndpoint(endpoint)}, apiOptions(api)...)
+	cc, err := gtransport.Dial(ctx, append(opts, grpcRetry(), creds)...)
+	if err != nil {
+		return nil, err
+	}
+	conns[k] = cc
+	return cc, nil
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	container "google.golang.org/api/container/v1"
+	"google.golang.org/api/option"
 )
 
 // Container client.
@@ -28,7 +29,11 @@ type Container struct {
 
 // NewContainer returns and initializes a Container client.
 func NewContainer(ctx context.Context) (*Container, error) {
-	cc, err := container.NewService(ctx)
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	cc, err := container.NewService(ctx, append(apiOptions("container"), option.WithHTTPClient(hc))...)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to init container service: %q", err)
 	}
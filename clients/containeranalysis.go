@@ -0,0 +1,63 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+	"google.golang.org/api/option"
+)
+
+// ContainerAnalysis client.
+type ContainerAnalysis struct {
+	service *containeranalysis.Service
+}
+
+// NewContainerAnalysis returns and initializes a Container Analysis client.
+func NewContainerAnalysis(ctx context.Context) (*ContainerAnalysis, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	ca, err := containeranalysis.NewService(ctx, append(apiOptions("containeranalysis"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init container analysis: %q", err)
+	}
+	return &ContainerAnalysis{service: ca}, nil
+}
+
+// GetOccurrence returns the occurrence with the given name.
+func (c *ContainerAnalysis) GetOccurrence(ctx context.Context, name string) (*containeranalysis.Occurrence, error) {
+	return c.service.Projects.Occurrences.Get(name).Context(ctx).Do()
+}
+
+// ListOccurrences returns the occurrences of the project matching the filter.
+func (c *ContainerAnalysis) ListOccurrences(ctx context.Context, projectID, filter string) ([]*containeranalysis.Occurrence, error) {
+	var occurrences []*containeranalysis.Occurrence
+	call := c.service.Projects.Occurrences.List("projects/" + projectID).Filter(filter)
+	err := call.Pages(ctx, func(resp *containeranalysis.ListOccurrencesResponse) error {
+		occurrences = append(occurrences, resp.Occurrences...)
+		return nil
+	})
+	return occurrences, err
+}
+
+// DeleteOccurrence deletes the occurrence with the given name.
+func (c *ContainerAnalysis) DeleteOccurrence(ctx context.Context, name string) error {
+	_, err := c.service.Projects.Occurrences.Delete(name).Context(ctx).Do()
+	return err
+}
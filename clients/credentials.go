@@ -0,0 +1,219 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	iamcredentials "google.golang.org/api/iamcredentials/v1"
+	"google.golang.org/api/option"
+)
+
+// credentialsFile is the service account key bundled with the function, if any.
+const credentialsFile = "credentials/auth.json"
+
+// CredentialsProvider provides the credentials the clients authenticate with.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (*google.Credentials, error)
+}
+
+// Provider is the credentials provider of the clients. When nil it is chosen by CREDENTIALS:
+//
+//   - "file" uses the service account key at CREDENTIALS_FILE, credentials/auth.json by default.
+//   - "default" uses Application Default Credentials, the function's runtime service account
+//     when deployed.
+//   - "keyless" uses the service account the workload runs as, from the metadata server.
+//   - Left empty the key is used if it is bundled with the function. Otherwise credentials are
+//     keyless on Cloud Run and GKE, Application Default Credentials elsewhere.
+//
+// When IMPERSONATE_SERVICE_ACCOUNT is set these credentials only impersonate the service account
+// it names, which the clients then authenticate as.
+var Provider CredentialsProvider
+
+// KeyFile provides the credentials of the service account key at the path.
+type KeyFile string
+
+// Credentials reads the key.
+func (f KeyFile) Credentials(ctx context.Context) (*google.Credentials, error) {
+	b, err := ioutil.ReadFile(string(f))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %q", err)
+	}
+	return google.CredentialsFromJSON(ctx, b, cloudPlatformScope)
+}
+
+// DefaultCredentials provides Application Default Credentials.
+type DefaultCredentials struct{}
+
+// Credentials finds the default credentials.
+func (DefaultCredentials) Credentials(ctx context.Context) (*google.Credentials, error) {
+	return google.FindDefaultCredentials(ctx, cloudPlatformScope)
+}
+
+// Keyless provides the credentials of the service account the workload runs as, with tokens from
+// the metadata server: the runtime service account on Cloud Functions and Cloud Run, the Google
+// service account bound to the Kubernetes service account with Workload Identity on GKE. No key
+// is involved, GOOGLE_APPLICATION_CREDENTIALS is ignored.
+type Keyless struct{}
+
+// Credentials returns the credentials of the metadata server.
+func (Keyless) Credentials(ctx context.Context) (*google.Credentials, error) {
+	if !metadata.OnGCE() {
+		return nil, errors.New("no metadata server, keyless credentials are only available on Google Cloud")
+	}
+	projectID, err := metadata.ProjectID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project from metadata: %q", err)
+	}
+	return &google.Credentials{ProjectID: projectID, TokenSource: google.ComputeTokenSource("", cloudPlatformScope)}, nil
+}
+
+// Impersonated provides the credentials of the service account, impersonated with the credentials
+// of Base, or of the clients' provider when it is nil.
+type Impersonated struct {
+	Base           CredentialsProvider
+	ServiceAccount string
+}
+
+// Credentials impersonates the service account.
+func (i Impersonated) Credentials(ctx context.Context) (*google.Credentials, error) {
+	base := i.Base
+	if base == nil {
+		var err error
+		if base, err = defaultProvider(); err != nil {
+			return nil, err
+		}
+	}
+	creds, err := base.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return impersonate(ctx, creds, i.ServiceAccount, append(apiOptions("iamcredentials"), option.WithCredentials(creds))...)
+}
+
+// providerFromEnv returns the provider chosen by the environment, as documented by Provider.
+func providerFromEnv() (CredentialsProvider, error) {
+	file := os.Getenv("CREDENTIALS_FILE")
+	if file == "" {
+		file = credentialsFile
+	}
+	var p CredentialsProvider
+	switch mode := os.Getenv("CREDENTIALS"); mode {
+	case "file":
+		p = KeyFile(file)
+	case "default":
+		p = DefaultCredentials{}
+	case "keyless":
+		p = Keyless{}
+	case "":
+		p = DefaultCredentials{}
+		if _, err := os.Stat(file); err == nil {
+			p = KeyFile(file)
+		} else if os.Getenv("K_SERVICE") != "" || os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			p = Keyless{}
+		}
+	default:
+		return nil, fmt.Errorf("unknown CREDENTIALS %q, want file, default or keyless", mode)
+	}
+	if sa := os.Getenv("IMPERSONATE_SERVICE_ACCOUNT"); sa != "" {
+		p = Impersonated{Base: p, ServiceAccount: sa}
+	}
+	return p, nil
+}
+
+// providerKey is the key of the provider in the contexts returned by WithProvider.
+type providerKey struct{}
+
+// WithProvider returns a context the clients created with authenticate with the provider, rather
+// than with Provider.
+func WithProvider(ctx context.Context, p CredentialsProvider) context.Context {
+	return context.WithValue(ctx, providerKey{}, p)
+}
+
+// ProviderFrom returns the provider of a context returned by WithProvider, nil for other contexts.
+func ProviderFrom(ctx context.Context) CredentialsProvider {
+	p, _ := ctx.Value(providerKey{}).(CredentialsProvider)
+	return p
+}
+
+// defaultProvider returns Provider, or the provider chosen by the environment if it is nil.
+func defaultProvider() (CredentialsProvider, error) {
+	if Provider != nil {
+		return Provider, nil
+	}
+	return providerFromEnv()
+}
+
+// credentials returns the credentials of the context's provider, or of Provider.
+func credentials(ctx context.Context) (*google.Credentials, error) {
+	if p := ProviderFrom(ctx); p != nil {
+		return p.Credentials(ctx)
+	}
+	p, err := defaultProvider()
+	if err != nil {
+		return nil, err
+	}
+	return p.Credentials(ctx)
+}
+
+// withCredentials returns the option authenticating a client with the credentials.
+func withCredentials(ctx context.Context) (option.ClientOption, error) {
+	creds, err := credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return option.WithCredentials(creds), nil
+}
+
+// impersonate returns credentials authenticating as the service account, with short-lived tokens
+// generated through the IAM Credentials API by the caller, which needs
+// roles/iam.serviceAccountTokenCreator on it.
+func impersonate(ctx context.Context, creds *google.Credentials, serviceAccount string, opts ...option.ClientOption) (*google.Credentials, error) {
+	s, err := iamcredentials.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init iam credentials: %q", err)
+	}
+	ts := &impersonatedTokenSource{ctx: ctx, service: s, name: "projects/-/serviceAccounts/" + serviceAccount}
+	return &google.Credentials{ProjectID: creds.ProjectID, TokenSource: oauth2.ReuseTokenSource(nil, ts)}, nil
+}
+
+// impersonatedTokenSource generates access tokens of a service account.
+type impersonatedTokenSource struct {
+	ctx     context.Context
+	service *iamcredentials.Service
+	name    string
+}
+
+// Token generates a new access token of the service account.
+func (s *impersonatedTokenSource) Token() (*oauth2.Token, error) {
+	req := &iamcredentials.GenerateAccessTokenRequest{Scope: []string{cloudPlatformScope}}
+	r, err := s.service.Projects.ServiceAccounts.GenerateAccessToken(s.name, req).Context(s.ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to impersonate %q: %q", s.name, err)
+	}
+	expiry, err := time.Parse(time.RFC3339, r.ExpireTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token expiry: %q", err)
+	}
+	return &oauth2.Token{AccessToken: r.AccessToken, TokenType: "Bearer", Expiry: expiry}, nil
+}
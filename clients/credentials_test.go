@@ -0,0 +1,98 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+func TestProviderFromEnv(t *testing.T) {
+	const sa = "close-bucket@automation-project.iam.gserviceaccount.com"
+	vars := []string{"CREDENTIALS", "CREDENTIALS_FILE", "K_SERVICE", "KUBERNETES_SERVICE_HOST", "IMPERSONATE_SERVICE_ACCOUNT"}
+	for _, v := range vars {
+		defer os.Setenv(v, os.Getenv(v))
+	}
+	for _, tt := range []struct {
+		name        string
+		env         map[string]string
+		expected    CredentialsProvider
+		expectError bool
+	}{
+		{name: "key file", env: map[string]string{"CREDENTIALS": "file", "CREDENTIALS_FILE": "key.json"}, expected: KeyFile("key.json")},
+		{name: "default", env: map[string]string{"CREDENTIALS": "default"}, expected: DefaultCredentials{}},
+		{name: "keyless", env: map[string]string{"CREDENTIALS": "keyless"}, expected: Keyless{}},
+		{name: "no key bundled", env: map[string]string{}, expected: DefaultCredentials{}},
+		{name: "no key bundled on cloud run", env: map[string]string{"K_SERVICE": "close-bucket"}, expected: Keyless{}},
+		{name: "no key bundled on gke", env: map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}, expected: Keyless{}},
+		{
+			name:     "impersonated",
+			env:      map[string]string{"CREDENTIALS": "default", "IMPERSONATE_SERVICE_ACCOUNT": sa},
+			expected: Impersonated{Base: DefaultCredentials{}, ServiceAccount: sa},
+		},
+		{name: "unknown mode", env: map[string]string{"CREDENTIALS": "keyfile"}, expectError: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range vars {
+				os.Setenv(v, tt.env[v])
+			}
+			p, err := providerFromEnv()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s got error %q, expectError:%t", tt.name, err, tt.expectError)
+			}
+			if p != tt.expected {
+				t.Errorf("%s got provider %#v, want %#v", tt.name, p, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMissingKeyFile(t *testing.T) {
+	if _, err := KeyFile("testdata/missing.json").Credentials(context.Background()); err == nil {
+		t.Errorf("missing key file did not fail")
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	ctx := context.Background()
+	const sa = "close-bucket@automation-project.iam.gserviceaccount.com"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/projects/-/serviceAccounts/"+sa+":generateAccessToken") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"accessToken": "impersonated-token", "expireTime": "2099-06-01T19:00:00Z"}`)
+	}))
+	defer srv.Close()
+	creds, err := impersonate(ctx, &google.Credentials{}, sa, option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("impersonate failed: %q", err)
+	}
+	tok, err := creds.TokenSource.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %q", err)
+	}
+	if tok.AccessToken != "impersonated-token" {
+		t.Errorf("got token %q, want impersonated-token", tok.AccessToken)
+	}
+}
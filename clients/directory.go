@@ -0,0 +1,91 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/option"
+)
+
+// Directory client of the Admin SDK, managing the users and groups of a Google Workspace or
+// Cloud Identity account.
+type Directory struct {
+	service *admin.Service
+}
+
+// NewDirectory returns and initializes a Directory client.
+func NewDirectory(ctx context.Context) (*Directory, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := admin.NewService(ctx, append(apiOptions("admin"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init directory: %q", err)
+	}
+	return &Directory{service: s}, nil
+}
+
+// GetUser returns the user, its primary email address or ID.
+func (d *Directory) GetUser(ctx context.Context, userKey string) (*admin.User, error) {
+	return d.service.Users.Get(userKey).Context(ctx).Do()
+}
+
+// PatchUser updates the fields of the user set in the patch.
+func (d *Directory) PatchUser(ctx context.Context, userKey string, patch *admin.User) (*admin.User, error) {
+	return d.service.Users.Patch(userKey, patch).Context(ctx).Do()
+}
+
+// SignOut signs the user out of their web and device sessions and resets their sign-in cookies.
+func (d *Directory) SignOut(ctx context.Context, userKey string) error {
+	return d.service.Users.SignOut(userKey).Context(ctx).Do()
+}
+
+// ListTokens returns the OAuth tokens the user granted to third-party applications.
+func (d *Directory) ListTokens(ctx context.Context, userKey string) ([]*admin.Token, error) {
+	r, err := d.service.Tokens.List(userKey).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return r.Items, nil
+}
+
+// DeleteToken revokes the OAuth tokens the user granted to the application of the client ID.
+func (d *Directory) DeleteToken(ctx context.Context, userKey, clientID string) error {
+	return d.service.Tokens.Delete(userKey, clientID).Context(ctx).Do()
+}
+
+// ListGroups returns the groups the user is a direct member of.
+func (d *Directory) ListGroups(ctx context.Context, userKey string) ([]*admin.Group, error) {
+	var groups []*admin.Group
+	err := d.service.Groups.List().UserKey(userKey).Pages(ctx, func(r *admin.Groups) error {
+		groups = append(groups, r.Groups...)
+		return nil
+	})
+	return groups, err
+}
+
+// InsertMember adds the member to the group.
+func (d *Directory) InsertMember(ctx context.Context, groupKey string, member *admin.Member) (*admin.Member, error) {
+	return d.service.Members.Insert(groupKey, member).Context(ctx).Do()
+}
+
+// DeleteMember removes the member from the group.
+func (d *Directory) DeleteMember(ctx context.Context, groupKey, memberKey string) error {
+	return d.service.Members.Delete(groupKey, memberKey).Context(ctx).Do()
+}
@@ -0,0 +1,47 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+	"google.golang.org/api/option"
+)
+
+// ErrorReporting client reporting errors to Cloud Error Reporting.
+type ErrorReporting struct {
+	service *clouderrorreporting.Service
+}
+
+// NewErrorReporting returns and initializes an Error Reporting client.
+func NewErrorReporting(ctx context.Context) (*ErrorReporting, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := clouderrorreporting.NewService(ctx, append(apiOptions("clouderrorreporting"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init error reporting: %q", err)
+	}
+	return &ErrorReporting{service: s}, nil
+}
+
+// ReportErrorEvent reports the error event to the project.
+func (e *ErrorReporting) ReportErrorEvent(ctx context.Context, projectID string, event *clouderrorreporting.ReportedErrorEvent) error {
+	_, err := e.service.Projects.Events.Report("projects/"+projectID, event).Context(ctx).Do()
+	return err
+}
@@ -0,0 +1,112 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	firestore "google.golang.org/api/firestore/v1"
+	"google.golang.org/api/option"
+)
+
+// Firestore client.
+type Firestore struct {
+	service *firestore.Service
+	http    *http.Client
+}
+
+// NewFirestore returns and initializes a Firestore client.
+func NewFirestore(ctx context.Context) (*Firestore, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	fs, err := firestore.NewService(ctx, append(apiOptions("firestore"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init firestore: %q", err)
+	}
+	return &Firestore{service: fs, http: hc}, nil
+}
+
+// GetDocument returns the document by resource name.
+func (f *Firestore) GetDocument(ctx context.Context, name string) (*firestore.Document, error) {
+	return f.service.Projects.Databases.Documents.Get(name).Context(ctx).Do()
+}
+
+// CreateDocument creates the document in the collection, it fails if the document exists.
+func (f *Firestore) CreateDocument(ctx context.Context, parent, collection, id string, doc *firestore.Document) (*firestore.Document, error) {
+	return f.service.Projects.Databases.Documents.CreateDocument(parent, collection, doc).DocumentId(id).Context(ctx).Do()
+}
+
+// PatchDocument creates or replaces the document.
+func (f *Firestore) PatchDocument(ctx context.Context, name string, doc *firestore.Document) (*firestore.Document, error) {
+	return f.service.Projects.Databases.Documents.Patch(name, doc).Context(ctx).Do()
+}
+
+// PatchDocumentIf replaces the document only if it was last updated at updateTime, or only if it
+// does not exist if updateTime is empty.
+func (f *Firestore) PatchDocumentIf(ctx context.Context, name string, doc *firestore.Document, updateTime string) (*firestore.Document, error) {
+	call := f.service.Projects.Databases.Documents.Patch(name, doc)
+	if updateTime == "" {
+		call = call.CurrentDocumentExists(false)
+	} else {
+		call = call.CurrentDocumentUpdateTime(updateTime)
+	}
+	return call.Context(ctx).Do()
+}
+
+// RunQuery returns the documents matching the query.
+//
+// The API streams its results as a JSON array the generated client cannot decode, the request is
+// made directly instead.
+func (f *Firestore) RunQuery(ctx context.Context, parent string, q *firestore.StructuredQuery) ([]*firestore.Document, error) {
+	b, err := json.Marshal(&firestore.RunQueryRequest{StructuredQuery: q})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://firestore.googleapis.com/v1/"+parent+":runQuery", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query failed with %s: %s", resp.Status, body)
+	}
+	var results []firestore.RunQueryResponse
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, err
+	}
+	var docs []*firestore.Document
+	for _, r := range results {
+		if r.Document != nil {
+			docs = append(docs, r.Document)
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,71 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+	iam "google.golang.org/api/iam/v1"
+	"google.golang.org/api/option"
+)
+
+// IAM client managing service accounts and their keys.
+type IAM struct {
+	service *iam.Service
+	hc      *http.Client
+}
+
+// NewIAM returns and initializes an IAM client.
+func NewIAM(ctx context.Context) (*IAM, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := iam.NewService(ctx, append(apiOptions("iam"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init iam: %q", err)
+	}
+	return &IAM{service: s, hc: hc}, nil
+}
+
+// ListServiceAccountKeys returns the user-managed keys of the service account, named
+// "projects/<id>/serviceAccounts/<email>".
+func (i *IAM) ListServiceAccountKeys(ctx context.Context, name string) ([]*iam.ServiceAccountKey, error) {
+	r, err := i.service.Projects.ServiceAccounts.Keys.List(name).KeyTypes("USER_MANAGED").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return r.Keys, nil
+}
+
+// DisableServiceAccountKey disables the key, which can be enabled again. keys.disable is newer
+// than the API client in use so it is called directly.
+func (i *IAM) DisableServiceAccountKey(ctx context.Context, name string) error {
+	req, err := http.NewRequest(http.MethodPost, i.service.BasePath+"v1/"+name+":disable", strings.NewReader("{}"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := i.hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return googleapi.CheckResponse(resp)
+}
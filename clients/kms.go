@@ -0,0 +1,73 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cloudkms "google.golang.org/api/cloudkms/v1"
+	"google.golang.org/api/option"
+)
+
+// KMS client.
+type KMS struct {
+	service *cloudkms.Service
+}
+
+// NewKMS returns and initializes a Cloud KMS client.
+func NewKMS(ctx context.Context) (*KMS, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := cloudkms.NewService(ctx, append(apiOptions("cloudkms"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init kms: %q", err)
+	}
+	return &KMS{service: s}, nil
+}
+
+// GetCryptoKey returns the crypto key, named
+// "projects/<id>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>".
+func (k *KMS) GetCryptoKey(ctx context.Context, name string) (*cloudkms.CryptoKey, error) {
+	return k.service.Projects.Locations.KeyRings.CryptoKeys.Get(name).Context(ctx).Do()
+}
+
+// PatchCryptoKey updates the fields of the crypto key listed by the update mask, a comma
+// separated list such as "rotationPeriod,nextRotationTime".
+func (k *KMS) PatchCryptoKey(ctx context.Context, name, updateMask string, key *cloudkms.CryptoKey) (*cloudkms.CryptoKey, error) {
+	return k.service.Projects.Locations.KeyRings.CryptoKeys.Patch(name, key).UpdateMask(updateMask).Context(ctx).Do()
+}
+
+// GetIamPolicy returns the IAM policy of the crypto key or key ring, named
+// "projects/<id>/locations/<location>/keyRings/<ring>". Version 3 is requested so conditional
+// bindings are returned with their conditions.
+func (k *KMS) GetIamPolicy(ctx context.Context, resource string) (*cloudkms.Policy, error) {
+	if strings.Contains(resource, "/cryptoKeys/") {
+		return k.service.Projects.Locations.KeyRings.CryptoKeys.GetIamPolicy(resource).OptionsRequestedPolicyVersion(3).Context(ctx).Do()
+	}
+	return k.service.Projects.Locations.KeyRings.GetIamPolicy(resource).OptionsRequestedPolicyVersion(3).Context(ctx).Do()
+}
+
+// SetIamPolicy sets the IAM policy of the crypto key or key ring, failing if its ETag is stale.
+func (k *KMS) SetIamPolicy(ctx context.Context, resource string, policy *cloudkms.Policy) (*cloudkms.Policy, error) {
+	req := &cloudkms.SetIamPolicyRequest{Policy: policy}
+	if strings.Contains(resource, "/cryptoKeys/") {
+		return k.service.Projects.Locations.KeyRings.CryptoKeys.SetIamPolicy(resource, req).Context(ctx).Do()
+	}
+	return k.service.Projects.Locations.KeyRings.SetIamPolicy(resource, req).Context(ctx).Do()
+}
@@ -0,0 +1,142 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+	container "google.golang.org/api/container/v1"
+	"google.golang.org/api/option"
+)
+
+// Kubernetes client talks to the Kubernetes API server of GKE clusters.
+type Kubernetes struct {
+	container   *container.Service
+	tokenSource oauth2.TokenSource
+
+	mu       sync.Mutex
+	clusters map[string]*cluster
+}
+
+// cluster holds the endpoint of a cluster's API server and a client trusting its CA.
+type cluster struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKubernetes returns and initializes a Kubernetes client.
+func NewKubernetes(ctx context.Context) (*Kubernetes, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	cc, err := container.NewService(ctx, append(apiOptions("container"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init container service: %q", err)
+	}
+	creds, err := credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init token source: %q", err)
+	}
+	return &Kubernetes{container: cc, tokenSource: creds.TokenSource, clusters: make(map[string]*cluster)}, nil
+}
+
+// Patch applies a strategic merge patch to the object at the given API path.
+func (k *Kubernetes) Patch(ctx context.Context, projectID, location, clusterID, path string, body []byte) error {
+	status, resp, err := k.do(ctx, projectID, location, clusterID, http.MethodPatch, path, "application/strategic-merge-patch+json", body)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusMultipleChoices {
+		return fmt.Errorf("patch %q failed with status %d: %s", path, status, resp)
+	}
+	return nil
+}
+
+// Create creates an object in the collection at the given API path. Existing objects are left untouched.
+func (k *Kubernetes) Create(ctx context.Context, projectID, location, clusterID, path string, body []byte) error {
+	status, resp, err := k.do(ctx, projectID, location, clusterID, http.MethodPost, path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusMultipleChoices && status != http.StatusConflict {
+		return fmt.Errorf("create %q failed with status %d: %s", path, status, resp)
+	}
+	return nil
+}
+
+// cluster returns the cached connection details of the cluster, looking them up on first use.
+func (k *Kubernetes) cluster(ctx context.Context, projectID, location, clusterID string) (*cluster, error) {
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, clusterID)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if c, ok := k.clusters[name]; ok {
+		return c, nil
+	}
+	cl, err := k.container.Projects.Locations.Clusters.Get(name).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster %q: %q", name, err)
+	}
+	ca, err := base64.StdEncoding.DecodeString(cl.MasterAuth.ClusterCaCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cluster ca certificate: %q", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to load cluster ca certificate")
+	}
+	c := &cluster{
+		endpoint: "https://" + cl.Endpoint,
+		client: &http.Client{
+			Transport: &oauth2.Transport{
+				Source: k.tokenSource,
+				Base:   &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+			},
+		},
+	}
+	k.clusters[name] = c
+	return c, nil
+}
+
+func (k *Kubernetes) do(ctx context.Context, projectID, location, clusterID, method, path, contentType string, body []byte) (int, []byte, error) {
+	c, err := k.cluster(ctx, projectID, location, clusterID)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest(method, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, b, nil
+}
@@ -0,0 +1,55 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	cloudlogging "google.golang.org/api/logging/v2"
+	"google.golang.org/api/option"
+)
+
+// LogEntries client reading log entries from Cloud Logging.
+type LogEntries struct {
+	service *cloudlogging.Service
+}
+
+// NewLogEntries returns and initializes a Cloud Logging client reading log entries.
+func NewLogEntries(ctx context.Context) (*LogEntries, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := cloudlogging.NewService(ctx, append(apiOptions("logging"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init logging: %q", err)
+	}
+	return &LogEntries{service: s}, nil
+}
+
+// ListLogEntries calls fn with each page of the entries of the project matching the filter, oldest
+// first. Listing stops at the first error fn returns.
+func (l *LogEntries) ListLogEntries(ctx context.Context, projectID, filter string, fn func([]*cloudlogging.LogEntry) error) error {
+	req := &cloudlogging.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/" + projectID},
+		Filter:        filter,
+		OrderBy:       "timestamp asc",
+		PageSize:      1000,
+	}
+	return l.service.Entries.List(req).Pages(ctx, func(r *cloudlogging.ListLogEntriesResponse) error {
+		return fn(r.Entries)
+	})
+}
@@ -36,7 +36,11 @@ type Logger struct {
 
 // NewLogger initializes and returns a Logger struct.
 func NewLogger(ctx context.Context) (*Logger, error) {
-	c, err := logging.NewClient(ctx, projectID)
+	creds, err := withCredentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init credentials: %q", err)
+	}
+	c, err := logging.NewClient(ctx, projectID, append(apiOptions("logging"), creds)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init logger: %q", err)
 	}
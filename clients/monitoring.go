@@ -0,0 +1,88 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	dashboard "google.golang.org/api/monitoring/v1"
+	monitoring "google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+)
+
+// Monitoring client managing Cloud Monitoring dashboards and alerting policies.
+type Monitoring struct {
+	dashboards *dashboard.Service
+	alerts     *monitoring.Service
+}
+
+// NewMonitoring returns and initializes a Monitoring client.
+func NewMonitoring(ctx context.Context) (*Monitoring, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	opts := append(apiOptions("monitoring"), option.WithHTTPClient(hc))
+	d, err := dashboard.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init monitoring dashboards: %q", err)
+	}
+	a, err := monitoring.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init monitoring: %q", err)
+	}
+	return &Monitoring{dashboards: d, alerts: a}, nil
+}
+
+// ListDashboards returns the dashboards of the project.
+func (m *Monitoring) ListDashboards(ctx context.Context, projectID string) ([]*dashboard.Dashboard, error) {
+	var dashboards []*dashboard.Dashboard
+	err := m.dashboards.Projects.Dashboards.List("projects/"+projectID).Pages(ctx, func(r *dashboard.ListDashboardsResponse) error {
+		dashboards = append(dashboards, r.Dashboards...)
+		return nil
+	})
+	return dashboards, err
+}
+
+// CreateDashboard creates the dashboard in the project.
+func (m *Monitoring) CreateDashboard(ctx context.Context, projectID string, d *dashboard.Dashboard) (*dashboard.Dashboard, error) {
+	return m.dashboards.Projects.Dashboards.Create("projects/"+projectID, d).Context(ctx).Do()
+}
+
+// UpdateDashboard replaces the dashboard named by d, whose etag must be current.
+func (m *Monitoring) UpdateDashboard(ctx context.Context, d *dashboard.Dashboard) (*dashboard.Dashboard, error) {
+	return m.dashboards.Projects.Dashboards.Patch(d.Name, d).Context(ctx).Do()
+}
+
+// ListAlertPolicies returns the alerting policies of the project.
+func (m *Monitoring) ListAlertPolicies(ctx context.Context, projectID string) ([]*monitoring.AlertPolicy, error) {
+	var policies []*monitoring.AlertPolicy
+	err := m.alerts.Projects.AlertPolicies.List("projects/"+projectID).Pages(ctx, func(r *monitoring.ListAlertPoliciesResponse) error {
+		policies = append(policies, r.AlertPolicies...)
+		return nil
+	})
+	return policies, err
+}
+
+// CreateAlertPolicy creates the alerting policy in the project.
+func (m *Monitoring) CreateAlertPolicy(ctx context.Context, projectID string, p *monitoring.AlertPolicy) (*monitoring.AlertPolicy, error) {
+	return m.alerts.Projects.AlertPolicies.Create("projects/"+projectID, p).Context(ctx).Do()
+}
+
+// UpdateAlertPolicy replaces the alerting policy named by p.
+func (m *Monitoring) UpdateAlertPolicy(ctx context.Context, p *monitoring.AlertPolicy) (*monitoring.AlertPolicy, error) {
+	return m.alerts.Projects.AlertPolicies.Patch(p.Name, p).Context(ctx).Do()
+}
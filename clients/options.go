@@ -0,0 +1,76 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"os"
+	"strings"
+
+	"google.golang.org/api/option"
+)
+
+// Version of SRA sent in the user agent of its API calls, set when building with
+// -ldflags "-X github.com/googlecloudplatform/security-response-automation/clients.Version=v1.2.0".
+var Version = "dev"
+
+// Commit and BuildTime identify the build, set with -ldflags like Version, e.g.
+// "-X .../clients.Commit=$(git rev-parse HEAD) -X .../clients.BuildTime=$(date -u +%FT%TZ)".
+var (
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// userAgent returns the user agent identifying SRA in the audit logs of the APIs it calls, the
+// USER_AGENT environment variable if set.
+func userAgent() string {
+	if ua := os.Getenv("USER_AGENT"); ua != "" {
+		return ua
+	}
+	return "security-response-automation/" + Version
+}
+
+// commonOptions returns the options shared by every client: the user agent and the project
+// billed for quota, QUOTA_PROJECT if set, instead of the project of the credentials.
+func commonOptions() []option.ClientOption {
+	opts := []option.ClientOption{option.WithUserAgent(userAgent())}
+	if p := os.Getenv("QUOTA_PROJECT"); p != "" {
+		opts = append(opts, option.WithQuotaProject(p))
+	}
+	return opts
+}
+
+// apiOptions returns the options of the client of the API, such as "compute": the common options
+// and its endpoint if overridden by API_ENDPOINTS, a comma separated list of API names and base
+// URLs used to reach regional or Private Service Connect endpoints, such as
+// "compute=https://compute-sra.p.googleapis.com/compute/v1/,storage=https://storage-sra.p.googleapis.com/storage/v1/".
+func apiOptions(api string) []option.ClientOption {
+	opts := commonOptions()
+	if e := endpoints()[api]; e != "" {
+		opts = append(opts, option.WithEndpoint(e))
+	}
+	return opts
+}
+
+// endpoints parses API_ENDPOINTS into the base URLs by API name, ignoring malformed entries.
+func endpoints() map[string]string {
+	m := make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv("API_ENDPOINTS"), ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) == 2 && kv[0] != "" && kv[1] != "" {
+			m[kv[0]] = kv[1]
+		}
+	}
+	return m
+}
@@ -0,0 +1,61 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEndpoints(t *testing.T) {
+	defer os.Setenv("API_ENDPOINTS", os.Getenv("API_ENDPOINTS"))
+	for _, tt := range []struct {
+		name     string
+		env      string
+		expected map[string]string
+	}{
+		{name: "not set", env: "", expected: map[string]string{}},
+		{
+			name: "private service connect",
+			env:  "compute=https://compute-sra.p.googleapis.com/compute/v1/, storage=https://storage-sra.p.googleapis.com/storage/v1/",
+			expected: map[string]string{
+				"compute": "https://compute-sra.p.googleapis.com/compute/v1/",
+				"storage": "https://storage-sra.p.googleapis.com/storage/v1/",
+			},
+		},
+		{name: "malformed entries", env: "compute,=https://example.com,storage=", expected: map[string]string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("API_ENDPOINTS", tt.env)
+			if diff := cmp.Diff(tt.expected, endpoints()); diff != "" {
+				t.Errorf("%s (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	defer os.Setenv("USER_AGENT", os.Getenv("USER_AGENT"))
+	os.Setenv("USER_AGENT", "")
+	if got, want := userAgent(), "security-response-automation/"+Version; got != want {
+		t.Errorf("got user agent %q, want %q", got, want)
+	}
+	os.Setenv("USER_AGENT", "acme-sra/1.0")
+	if got, want := userAgent(), "acme-sra/1.0"; got != want {
+		t.Errorf("got user agent %q, want %q", got, want)
+	}
+}
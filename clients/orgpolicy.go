@@ -0,0 +1,86 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/option"
+)
+
+// OrgPolicy client.
+type OrgPolicy struct {
+	service *crm.Service
+}
+
+// NewOrgPolicy returns and initializes an OrgPolicy client.
+func NewOrgPolicy(ctx context.Context) (*OrgPolicy, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := crm.NewService(ctx, append(apiOptions("cloudresourcemanager"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init crm: %q", err)
+	}
+	return &OrgPolicy{service: s}, nil
+}
+
+// GetOrgPolicy returns the policy of the constraint set on the resource, one of
+// "projects/<id>", "folders/<id>" or "organizations/<id>".
+func (o *OrgPolicy) GetOrgPolicy(ctx context.Context, resource, constraint string) (*crm.OrgPolicy, error) {
+	req := &crm.GetOrgPolicyRequest{Constraint: constraint}
+	switch {
+	case strings.HasPrefix(resource, "projects/"):
+		return o.service.Projects.GetOrgPolicy(resource, req).Context(ctx).Do()
+	case strings.HasPrefix(resource, "folders/"):
+		return o.service.Folders.GetOrgPolicy(resource, req).Context(ctx).Do()
+	case strings.HasPrefix(resource, "organizations/"):
+		return o.service.Organizations.GetOrgPolicy(resource, req).Context(ctx).Do()
+	}
+	return nil, fmt.Errorf("unsupported resource %q", resource)
+}
+
+// GetEffectiveOrgPolicy returns the policy of the constraint in effect on the resource, merged
+// with the policies of its ancestors.
+func (o *OrgPolicy) GetEffectiveOrgPolicy(ctx context.Context, resource, constraint string) (*crm.OrgPolicy, error) {
+	req := &crm.GetEffectiveOrgPolicyRequest{Constraint: constraint}
+	switch {
+	case strings.HasPrefix(resource, "projects/"):
+		return o.service.Projects.GetEffectiveOrgPolicy(resource, req).Context(ctx).Do()
+	case strings.HasPrefix(resource, "folders/"):
+		return o.service.Folders.GetEffectiveOrgPolicy(resource, req).Context(ctx).Do()
+	case strings.HasPrefix(resource, "organizations/"):
+		return o.service.Organizations.GetEffectiveOrgPolicy(resource, req).Context(ctx).Do()
+	}
+	return nil, fmt.Errorf("unsupported resource %q", resource)
+}
+
+// SetOrgPolicy sets the policy on the resource, failing if its ETag is stale.
+func (o *OrgPolicy) SetOrgPolicy(ctx context.Context, resource string, policy *crm.OrgPolicy) (*crm.OrgPolicy, error) {
+	req := &crm.SetOrgPolicyRequest{Policy: policy}
+	switch {
+	case strings.HasPrefix(resource, "projects/"):
+		return o.service.Projects.SetOrgPolicy(resource, req).Context(ctx).Do()
+	case strings.HasPrefix(resource, "folders/"):
+		return o.service.Folders.SetOrgPolicy(resource, req).Context(ctx).Do()
+	case strings.HasPrefix(resource, "organizations/"):
+		return o.service.Organizations.SetOrgPolicy(resource, req).Context(ctx).Do()
+	}
+	return nil, fmt.Errorf("unsupported resource %q", resource)
+}
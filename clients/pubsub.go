@@ -28,7 +28,11 @@ type PubSub struct {
 
 // NewPubSub returns the PubSub client.
 func NewPubSub(ctx context.Context, projectID string) (*PubSub, error) {
-	client, err := pubsub.NewClient(ctx, projectID)
+	creds, err := withCredentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init credentials: %q", err)
+	}
+	client, err := pubsub.NewClient(ctx, projectID, append(apiOptions("pubsub"), creds)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init pubsub: %q", err)
 	}
@@ -0,0 +1,60 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/option"
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+// Recommender client.
+type Recommender struct {
+	service *recommender.Service
+}
+
+// NewRecommender returns and initializes a Recommender client.
+func NewRecommender(ctx context.Context) (*Recommender, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := recommender.NewService(ctx, append(apiOptions("recommender"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init recommender: %q", err)
+	}
+	return &Recommender{service: s}, nil
+}
+
+// ListRecommendations returns the recommendations of the recommender matching the filter, the
+// parent is named "projects/<id>/locations/<location>/recommenders/<recommender>".
+func (r *Recommender) ListRecommendations(ctx context.Context, parent, filter string) ([]*recommender.GoogleCloudRecommenderV1Recommendation, error) {
+	var recommendations []*recommender.GoogleCloudRecommenderV1Recommendation
+	call := r.service.Projects.Locations.Recommenders.Recommendations.List(parent).Filter(filter)
+	err := call.Pages(ctx, func(resp *recommender.GoogleCloudRecommenderV1ListRecommendationsResponse) error {
+		recommendations = append(recommendations, resp.Recommendations...)
+		return nil
+	})
+	return recommendations, err
+}
+
+// MarkRecommendationSucceeded marks the recommendation as applied.
+func (r *Recommender) MarkRecommendationSucceeded(ctx context.Context, name, etag string) error {
+	req := &recommender.GoogleCloudRecommenderV1MarkRecommendationSucceededRequest{Etag: etag}
+	_, err := r.service.Projects.Locations.Recommenders.Recommendations.MarkSucceeded(name, req).Context(ctx).Do()
+	return err
+}
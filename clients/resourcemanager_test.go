@@ -0,0 +1,70 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	crmv2 "google.golang.org/api/cloudresourcemanager/v2"
+)
+
+func TestPolicyConversion(t *testing.T) {
+	policy := &crm.Policy{
+		Version: 3,
+		Etag:    "BwWWja0YfJA=",
+		Bindings: []*crm.Binding{
+			{Role: "roles/owner", Members: []string{"user:ddgo@cloudorg.com"}},
+			{
+				Role:      "roles/viewer",
+				Members:   []string{"group:sec@cloudorg.com"},
+				Condition: &crm.Expr{Title: "expires", Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`},
+			},
+		},
+		AuditConfigs: []*crm.AuditConfig{{
+			Service:         "allServices",
+			AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "DATA_READ", ExemptedMembers: []string{"user:ddgo@cloudorg.com"}}},
+		}},
+	}
+	pb, err := toProtoPolicy(policy)
+	if err != nil {
+		t.Fatalf("toProtoPolicy got error %q", err)
+	}
+	if string(pb.GetEtag()) != "\x07\x05\x96\x8d\xad\x18\x7c\x90" {
+		t.Errorf("etag got %q, want it decoded", pb.GetEtag())
+	}
+	got := &crm.Policy{}
+	if err := fromProtoPolicy(pb, got); err != nil {
+		t.Fatalf("fromProtoPolicy got error %q", err)
+	}
+	if diff := cmp.Diff(policy, got); diff != "" {
+		t.Errorf("project policy difference: %+v", diff)
+	}
+	folder := &crmv2.Policy{}
+	if err := fromProtoPolicy(pb, folder); err != nil {
+		t.Fatalf("fromProtoPolicy got error %q", err)
+	}
+	if folder.Etag != policy.Etag || len(folder.Bindings) != 2 || folder.Bindings[1].Condition.Title != "expires" {
+		t.Errorf("folder policy got %+v", folder)
+	}
+}
+
+func TestCreateMask(t *testing.T) {
+	got := createMask([]string{"auditConfigs", "bindings"}).GetPaths()
+	if diff := cmp.Diff([]string{"audit_configs", "bindings", "etag"}, got); diff != "" {
+		t.Errorf("mask difference: %+v", diff)
+	}
+}
@@ -0,0 +1,200 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// Retry is the policy Google API calls are retried with when they fail with a transient error:
+// 429 and 503 responses over HTTP, RESOURCE_EXHAUSTED and UNAVAILABLE over gRPC. Reads are also
+// retried on the other 5xx responses but 501, writes are not as they may have been applied.
+// Other errors are permanent and returned immediately.
+//
+// Calls are retried after an exponential backoff with full jitter, or after the delay the
+// Retry-After header asks for if the response has one, capped by Max. The Pub/Sub and Logging
+// clients retry publishing and writing entries themselves and are left as they are.
+type Retry struct {
+	// Attempts is the maximum number of calls made, including the first.
+	Attempts int
+	// Initial is the backoff before the first retry, doubled for each retry after it.
+	Initial time.Duration
+	// Max caps the backoff.
+	Max time.Duration
+}
+
+// DefaultRetry is the policy of the clients, overridden by the RETRY_ATTEMPTS,
+// RETRY_INITIAL_BACKOFF and RETRY_MAX_BACKOFF environment variables, e.g. "5", "500ms", "30s".
+var DefaultRetry = retryFromEnv(Retry{Attempts: 5, Initial: 500 * time.Millisecond, Max: 30 * time.Second})
+
+func retryFromEnv(r Retry) Retry {
+	if n, err := strconv.Atoi(os.Getenv("RETRY_ATTEMPTS")); err == nil && n > 0 {
+		r.Attempts = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("RETRY_INITIAL_BACKOFF")); err == nil {
+		r.Initial = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("RETRY_MAX_BACKOFF")); err == nil {
+		r.Max = d
+	}
+	return r
+}
+
+// backoff returns how long to wait before the retry following the attempt, counted from zero.
+// The delay asked for by Retry-After is capped by Max, so a server cannot hold the function until
+// it times out.
+func (r Retry) backoff(attempt int, retryAfter string) time.Duration {
+	if s, err := strconv.Atoi(retryAfter); err == nil && s >= 0 {
+		return r.capped(time.Duration(s) * time.Second)
+	}
+	if t, err := http.ParseTime(retryAfter); err == nil {
+		return r.capped(time.Until(t))
+	}
+	d := r.Initial << uint(attempt)
+	if d <= 0 || d > r.Max {
+		d = r.Max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// capped returns the delay between zero and Max.
+func (r Retry) capped(d time.Duration) time.Duration {
+	switch {
+	case d < 0:
+		return 0
+	case d > r.Max:
+		return r.Max
+	}
+	return d
+}
+
+// wait sleeps for the backoff, returning false if the context is done first.
+func (r Retry) wait(ctx context.Context, attempt int, retryAfter string) bool {
+	t := time.NewTimer(r.backoff(attempt, retryAfter))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// retryableStatus reports whether an HTTP response with the status code to a request with the
+// method is worth retrying. Only throttled and unavailable responses are known not to have applied
+// a write, other server errors are only retried for reads.
+func retryableStatus(method string, code int) bool {
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusServiceUnavailable:
+		return true
+	case method != http.MethodGet:
+		return false
+	}
+	return code >= http.StatusInternalServerError && code != http.StatusNotImplemented
+}
+
+// retryTransport retries the requests that fail with a transient error.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy Retry
+}
+
+// RoundTrip sends the request, retrying it while the response is transient. Requests whose body
+// cannot be sent again, such as streamed uploads, are sent once.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		last := attempt+1 >= t.policy.Attempts || (req.Body != nil && req.GetBody == nil)
+		switch {
+		case last:
+			return resp, err
+		case err != nil:
+			// Connection errors are only retried for reads, a write may have been applied.
+			if req.Method != http.MethodGet {
+				return resp, err
+			}
+			if !t.policy.wait(req.Context(), attempt, "") {
+				return resp, err
+			}
+		case !retryableStatus(req.Method, resp.StatusCode):
+			return resp, nil
+		default:
+			resp.Body.Close()
+			if !t.policy.wait(req.Context(), attempt, resp.Header.Get("Retry-After")) {
+				return nil, req.Context().Err()
+			}
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// newHTTPClient returns an authenticated HTTP client retrying with the default policy, sending the
+// user agent and quota project of commonOptions.
+func newHTTPClient(ctx context.Context) (*http.Client, error) {
+	creds, err := withCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := htransport.NewTransport(ctx, &retryTransport{base: http.DefaultTransport, policy: DefaultRetry}, append(commonOptions(), creds)...)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t}, nil
+}
+
+// retryableCode reports whether a gRPC call failing with the code is worth retrying. INTERNAL is
+// not, the interceptor cannot tell reads from writes and a write may have been applied.
+func retryableCode(c codes.Code) bool {
+	return c == codes.ResourceExhausted || c == codes.Unavailable
+}
+
+// retryInterceptor retries the unary gRPC calls that fail with a transient error.
+func retryInterceptor(policy Retry) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt+1 >= policy.Attempts || !retryableCode(status.Code(err)) {
+				return err
+			}
+			if !policy.wait(ctx, attempt, "") {
+				return err
+			}
+		}
+	}
+}
+
+// grpcRetry returns the option retrying the unary calls of a gRPC client with the default policy.
+func grpcRetry() option.ClientOption {
+	return option.WithGRPCDialOption(grpc.WithUnaryInterceptor(retryInterceptor(DefaultRetry)))
+}
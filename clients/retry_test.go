@@ -0,0 +1,143 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryTransport(t *testing.T) {
+	policy := Retry{Attempts: 3, Initial: time.Millisecond, Max: 5 * time.Millisecond}
+	tests := []struct {
+		name             string
+		method           string
+		statuses         []int
+		retryAfter       string
+		expectedStatus   int
+		expectedRequests int
+	}{
+		{name: "succeeds first time", statuses: []int{200}, expectedStatus: 200, expectedRequests: 1},
+		{name: "retries unavailable", statuses: []int{503, 503, 200}, expectedStatus: 200, expectedRequests: 3},
+		{name: "retries rate limited honoring retry-after", statuses: []int{429, 200}, retryAfter: "0", expectedStatus: 200, expectedRequests: 2},
+		{name: "gives up after the attempts", statuses: []int{503, 503, 503, 200}, expectedStatus: 503, expectedRequests: 3},
+		{name: "does not retry permanent errors", statuses: []int{403, 200}, expectedStatus: 403, expectedRequests: 1},
+		{name: "does not retry writes failing internally", statuses: []int{500, 200}, expectedStatus: 500, expectedRequests: 1},
+		{name: "retries reads failing internally", method: http.MethodGet, statuses: []int{500, 200}, expectedStatus: 200, expectedRequests: 2},
+		{name: "does not retry not implemented", method: http.MethodGet, statuses: []int{501, 200}, expectedStatus: 501, expectedRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[len(bodies)-1])
+			}))
+			defer srv.Close()
+			c := &http.Client{Transport: &retryTransport{base: http.DefaultTransport, policy: policy}}
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, srv.URL, strings.NewReader(`{"a":1}`))
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("%s status got:%d want:%d", tt.name, resp.StatusCode, tt.expectedStatus)
+			}
+			if len(bodies) != tt.expectedRequests {
+				t.Errorf("%s requests got:%d want:%d", tt.name, len(bodies), tt.expectedRequests)
+			}
+			for _, b := range bodies {
+				if b != `{"a":1}` {
+					t.Errorf("%s sent body %q", tt.name, b)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	policy := Retry{Attempts: 3, Initial: time.Millisecond, Max: 5 * time.Millisecond}
+	tests := []struct {
+		name          string
+		codes         []codes.Code
+		expectedCode  codes.Code
+		expectedCalls int
+	}{
+		{name: "retries unavailable", codes: []codes.Code{codes.Unavailable, codes.OK}, expectedCode: codes.OK, expectedCalls: 2},
+		{name: "does not retry not found", codes: []codes.Code{codes.NotFound, codes.OK}, expectedCode: codes.NotFound, expectedCalls: 1},
+		{name: "gives up after the attempts", codes: []codes.Code{codes.ResourceExhausted, codes.ResourceExhausted, codes.ResourceExhausted, codes.OK}, expectedCode: codes.ResourceExhausted, expectedCalls: 3},
+		{name: "does not retry internal errors", codes: []codes.Code{codes.Internal, codes.OK}, expectedCode: codes.Internal, expectedCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				calls++
+				return status.Error(tt.codes[calls-1], "")
+			}
+			err := retryInterceptor(policy)(context.Background(), "/m", nil, nil, nil, invoker)
+			if status.Code(err) != tt.expectedCode {
+				t.Errorf("%s code got:%v want:%v", tt.name, status.Code(err), tt.expectedCode)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("%s calls got:%d want:%d", tt.name, calls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := Retry{Attempts: 5, Initial: time.Second, Max: 4 * time.Second}
+	for attempt := 0; attempt < 5; attempt++ {
+		if d := policy.backoff(attempt, ""); d < 0 || d > 4*time.Second {
+			t.Errorf("backoff of attempt %d got:%v want at most 4s", attempt, d)
+		}
+	}
+	for _, tt := range []struct {
+		name       string
+		retryAfter string
+		expected   time.Duration
+	}{
+		{name: "honors retry-after", retryAfter: "3", expected: 3 * time.Second},
+		{name: "caps retry-after", retryAfter: "3600", expected: 4 * time.Second},
+		{name: "caps retry-after dates", retryAfter: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), expected: 4 * time.Second},
+		{name: "retry-after dates past", retryAfter: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), expected: 0},
+	} {
+		if d := policy.backoff(0, tt.retryAfter); d != tt.expected {
+			t.Errorf("%s got:%v want:%v", tt.name, d, tt.expected)
+		}
+	}
+}
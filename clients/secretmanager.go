@@ -0,0 +1,86 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"google.golang.org/api/option"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// SecretManager client.
+type SecretManager struct {
+	service *secretmanager.Service
+}
+
+// NewSecretManager returns and initializes a Secret Manager client.
+func NewSecretManager(ctx context.Context) (*SecretManager, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	sm, err := secretmanager.NewService(ctx, append(apiOptions("secretmanager"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init secret manager: %q", err)
+	}
+	return &SecretManager{service: sm}, nil
+}
+
+// AccessSecretVersion returns the payload of the secret version.
+func (s *SecretManager) AccessSecretVersion(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.service.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Payload.Data)
+}
+
+// GetIamPolicy returns the IAM policy of the secret, named "projects/<id>/secrets/<secret>".
+// Version 3 is requested so conditional bindings are returned with their conditions.
+func (s *SecretManager) GetIamPolicy(ctx context.Context, name string) (*secretmanager.Policy, error) {
+	return s.service.Projects.Secrets.GetIamPolicy(name).OptionsRequestedPolicyVersion(3).Context(ctx).Do()
+}
+
+// SetIamPolicy sets the IAM policy of the secret, failing if its ETag is stale.
+func (s *SecretManager) SetIamPolicy(ctx context.Context, name string, policy *secretmanager.Policy) (*secretmanager.Policy, error) {
+	return s.service.Projects.Secrets.SetIamPolicy(name, &secretmanager.SetIamPolicyRequest{Policy: policy}).Context(ctx).Do()
+}
+
+// ListSecretVersions returns the versions of the secret.
+func (s *SecretManager) ListSecretVersions(ctx context.Context, name string) ([]*secretmanager.SecretVersion, error) {
+	var versions []*secretmanager.SecretVersion
+	err := s.service.Projects.Secrets.Versions.List(name).Pages(ctx, func(page *secretmanager.ListSecretVersionsResponse) error {
+		versions = append(versions, page.Versions...)
+		return nil
+	})
+	return versions, err
+}
+
+// AddSecretVersion adds a version holding the payload to the secret.
+func (s *SecretManager) AddSecretVersion(ctx context.Context, name string, payload []byte) (*secretmanager.SecretVersion, error) {
+	req := &secretmanager.AddSecretVersionRequest{
+		Payload: &secretmanager.SecretPayload{Data: base64.StdEncoding.EncodeToString(payload)},
+	}
+	return s.service.Projects.Secrets.AddVersion(name, req).Context(ctx).Do()
+}
+
+// DisableSecretVersion disables the secret version, named
+// "projects/<id>/secrets/<secret>/versions/<version>", it can no longer be accessed.
+func (s *SecretManager) DisableSecretVersion(ctx context.Context, name string) (*secretmanager.SecretVersion, error) {
+	return s.service.Projects.Secrets.Versions.Disable(name, &secretmanager.DisableSecretVersionRequest{}).Context(ctx).Do()
+}
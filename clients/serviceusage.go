@@ -0,0 +1,85 @@
+package clients
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/option"
+	serviceusage "google.golang.org/api/serviceusage/v1"
+)
+
+// ServiceUsage client.
+type ServiceUsage struct {
+	service *serviceusage.Service
+}
+
+// NewServiceUsage returns and initializes a Service Usage client.
+func NewServiceUsage(ctx context.Context) (*ServiceUsage, error) {
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	s, err := serviceusage.NewService(ctx, append(apiOptions("serviceusage"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init service usage: %q", err)
+	}
+	return &ServiceUsage{service: s}, nil
+}
+
+// EnabledServices returns the names of the APIs enabled in the project, such as
+// "compute.googleapis.com".
+func (s *ServiceUsage) EnabledServices(ctx context.Context, projectID string) ([]string, error) {
+	var names []string
+	call := s.service.Services.List("projects/" + projectID).Filter("state:ENABLED")
+	err := call.Pages(ctx, func(resp *serviceusage.ListServicesResponse) error {
+		for _, svc := range resp.Services {
+			// Names are of the form "projects/123/services/compute.googleapis.com".
+			names = append(names, svc.Name[strings.LastIndex(svc.Name, "/")+1:])
+		}
+		return nil
+	})
+	return names, err
+}
+
+// DisableService disables the API, such as "compute.googleapis.com", in the project. The APIs
+// depending on it are disabled too if dependents is set, the call fails if any is enabled otherwise.
+func (s *ServiceUsage) DisableService(ctx context.Context, projectID, api string, dependents bool) (*serviceusage.Operation, error) {
+	name := fmt.Sprintf("projects/%s/services/%s", projectID, api)
+	req := &serviceusage.DisableServiceRequest{DisableDependentServices: dependents}
+	return s.service.Services.Disable(name, req).Context(ctx).Do()
+}
+
+// WaitServiceUsage waits for the operation to complete.
+func (s *ServiceUsage) WaitServiceUsage(ctx context.Context, op *serviceusage.Operation) error {
+	for i := 0; i < maxLoops; i++ {
+		if op.Error != nil {
+			return fmt.Errorf("operation %q failed: %s", op.Name, op.Error.Message)
+		}
+		if op.Done {
+			return nil
+		}
+		time.Sleep(loopSleep)
+		o, err := s.service.Operations.Get(op.Name).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		op = o
+	}
+	return fmt.Errorf("operation timed out: %q", op.Name)
+}
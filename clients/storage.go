@@ -18,22 +18,41 @@ import (
 	"context"
 	"fmt"
 
+	"io/ioutil"
+
 	"cloud.google.com/go/iam"
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	storagev1 "google.golang.org/api/storage/v1"
 )
 
+// objectPageSize is the number of objects listed per page when walking the ACLs of a bucket's
+// objects.
+const objectPageSize = 500
+
 // Storage client.
 type Storage struct {
 	service *storage.Client
+	// raw is the JSON API, which returns the ACLs of objects along with them a page at a time.
+	raw *storagev1.Service
 }
 
 // NewStorage returns and initializes the Storage client.
 func NewStorage(ctx context.Context) (*Storage, error) {
-	c, err := storage.NewClient(ctx)
+	hc, err := httpClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %q", err)
+	}
+	c, err := storage.NewClient(ctx, append(apiOptions("storage"), option.WithHTTPClient(hc))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init storage: %q", err)
 	}
-	return &Storage{service: c}, nil
+	raw, err := storagev1.NewService(ctx, append(apiOptions("storage"), option.WithHTTPClient(hc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init storage: %q", err)
+	}
+	return &Storage{service: c, raw: raw}, nil
 }
 
 // SetBucketPolicy sets the policy for the given bucket.
@@ -46,6 +65,16 @@ func (s *Storage) BucketPolicy(ctx context.Context, bucketName string) (*iam.Pol
 	return s.service.Bucket(bucketName).IAM().Policy(ctx)
 }
 
+// BucketPolicyOnly reports whether the bucket only policy, now uniform bucket-level access, is
+// enabled on the given bucket.
+func (s *Storage) BucketPolicyOnly(ctx context.Context, bucketName string) (bool, error) {
+	attrs, err := s.service.Bucket(bucketName).Attrs(ctx)
+	if err != nil {
+		return false, err
+	}
+	return attrs.UniformBucketLevelAccess.Enabled || attrs.BucketPolicyOnly.Enabled, nil
+}
+
 // EnableBucketOnlyPolicy enables the bucket only policy for the given bucket.
 func (s *Storage) EnableBucketOnlyPolicy(ctx context.Context, bucketName string) error {
 	enableBucketPolicyOnly := storage.BucketAttrsToUpdate{
@@ -58,3 +87,81 @@ func (s *Storage) EnableBucketOnlyPolicy(ctx context.Context, bucketName string)
 	}
 	return nil
 }
+
+// BucketLabels returns the labels of the given bucket.
+func (s *Storage) BucketLabels(ctx context.Context, bucketName string) (map[string]string, error) {
+	attrs, err := s.service.Bucket(bucketName).Attrs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return attrs.Labels, nil
+}
+
+// SetBucketLabels sets the given labels on the bucket, leaving its other labels as they are.
+func (s *Storage) SetBucketLabels(ctx context.Context, bucketName string, labels map[string]string) error {
+	var update storage.BucketAttrsToUpdate
+	for k, v := range labels {
+		update.SetLabel(k, v)
+	}
+	_, err := s.service.Bucket(bucketName).Update(ctx, update)
+	return err
+}
+
+// Objects lists the names of the objects in the bucket starting with the prefix.
+func (s *Storage) Objects(ctx context.Context, bucketName, prefix string) ([]string, error) {
+	var names []string
+	it := s.service.Bucket(bucketName).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
+// Object reads the content of an object.
+func (s *Storage) Object(ctx context.Context, bucketName, name string) ([]byte, error) {
+	r, err := s.service.Bucket(bucketName).Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// WriteObject creates or replaces an object with the content.
+func (s *Storage) WriteObject(ctx context.Context, bucketName, name string, b []byte) error {
+	w := s.service.Bucket(bucketName).Object(name).NewWriter(ctx)
+	if _, err := w.Write(b); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// EachObjectACLPage calls fn with the ACL of each object of the bucket, the role of each entity
+// keyed by object name, a page of objects at a time so that buckets holding millions of objects are never held in
+// memory at once. Stops at the first error fn returns.
+func (s *Storage) EachObjectACLPage(ctx context.Context, bucketName string, fn func(map[string]map[string]string) error) error {
+	call := s.raw.Objects.List(bucketName).Projection("full").MaxResults(objectPageSize).Fields("nextPageToken", "items(name,acl(entity,role))")
+	return call.Pages(ctx, func(resp *storagev1.Objects) error {
+		page := make(map[string]map[string]string, len(resp.Items))
+		for _, o := range resp.Items {
+			acl := make(map[string]string, len(o.Acl))
+			for _, a := range o.Acl {
+				acl[a.Entity] = a.Role
+			}
+			page[o.Name] = acl
+		}
+		return fn(page)
+	})
+}
+
+// DeleteObjectACL removes the entity's entry from the ACL of the object.
+func (s *Storage) DeleteObjectACL(ctx context.Context, bucketName, object, entity string) error {
+	return s.service.Bucket(bucketName).Object(object).ACL().Delete(ctx, storage.ACLEntity(entity))
+}
@@ -0,0 +1,66 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	acm "google.golang.org/api/accesscontextmanager/v1"
+)
+
+// AccessContextManagerStub provides a stub for the Access Context Manager client.
+type AccessContextManagerStub struct {
+	// Perimeters maps names to the perimeters returned by GetServicePerimeter and updated by
+	// PatchServicePerimeter.
+	Perimeters map[string]*acm.ServicePerimeter
+	// AccessLevels maps names to the access levels returned by GetAccessLevel and updated by
+	// PatchAccessLevel.
+	AccessLevels map[string]*acm.AccessLevel
+	// SavedMasks holds the update masks of the patches.
+	SavedMasks []string
+}
+
+// GetServicePerimeter is a stub of Access Context Manager's servicePerimeters.get.
+func (s *AccessContextManagerStub) GetServicePerimeter(ctx context.Context, name string) (*acm.ServicePerimeter, error) {
+	p, ok := s.Perimeters[name]
+	if !ok {
+		return nil, fmt.Errorf("perimeter %q not found", name)
+	}
+	return p, nil
+}
+
+// PatchServicePerimeter is a stub of Access Context Manager's servicePerimeters.patch.
+func (s *AccessContextManagerStub) PatchServicePerimeter(ctx context.Context, name string, perimeter *acm.ServicePerimeter, mask string) (*acm.Operation, error) {
+	s.Perimeters[name] = perimeter
+	s.SavedMasks = append(s.SavedMasks, mask)
+	return &acm.Operation{Done: true}, nil
+}
+
+// GetAccessLevel is a stub of Access Context Manager's accessLevels.get.
+func (s *AccessContextManagerStub) GetAccessLevel(ctx context.Context, name string) (*acm.AccessLevel, error) {
+	l, ok := s.AccessLevels[name]
+	if !ok {
+		return nil, fmt.Errorf("access level %q not found", name)
+	}
+	return l, nil
+}
+
+// PatchAccessLevel is a stub of Access Context Manager's accessLevels.patch.
+func (s *AccessContextManagerStub) PatchAccessLevel(ctx context.Context, name string, level *acm.AccessLevel, mask string) (*acm.Operation, error) {
+	s.AccessLevels[name] = level
+	s.SavedMasks = append(s.SavedMasks, mask)
+	return &acm.Operation{Done: true}, nil
+}
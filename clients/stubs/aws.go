@@ -0,0 +1,66 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "context"
+
+// AWSStub provides a stub for the AWS client.
+type AWSStub struct {
+	// AccessKeyStatus holds the status set for each access key.
+	AccessKeyStatus map[string]string
+	// StubbedSecurityGroupID is returned when looking up a security group.
+	StubbedSecurityGroupID string
+	// CreatedSecurityGroups holds the names of all created security groups.
+	CreatedSecurityGroups []string
+	// RevokedEgress holds the IDs of security groups with egress revoked.
+	RevokedEgress []string
+	// InstanceSecurityGroups holds the security groups set on each instance.
+	InstanceSecurityGroups map[string][]string
+}
+
+// UpdateAccessKey sets the status of an access key.
+func (a *AWSStub) UpdateAccessKey(ctx context.Context, userName, accessKeyID, status string) error {
+	if a.AccessKeyStatus == nil {
+		a.AccessKeyStatus = make(map[string]string)
+	}
+	a.AccessKeyStatus[accessKeyID] = status
+	return nil
+}
+
+// SecurityGroupID returns the stubbed security group ID.
+func (a *AWSStub) SecurityGroupID(ctx context.Context, vpcID, name string) (string, error) {
+	return a.StubbedSecurityGroupID, nil
+}
+
+// CreateSecurityGroup records the security group and returns its name as ID.
+func (a *AWSStub) CreateSecurityGroup(ctx context.Context, vpcID, name, description string) (string, error) {
+	a.CreatedSecurityGroups = append(a.CreatedSecurityGroups, name)
+	return "sg-" + name, nil
+}
+
+// RevokeSecurityGroupEgress records the security group.
+func (a *AWSStub) RevokeSecurityGroupEgress(ctx context.Context, groupID, cidr string) error {
+	a.RevokedEgress = append(a.RevokedEgress, groupID)
+	return nil
+}
+
+// SetInstanceSecurityGroups records the security groups of the instance.
+func (a *AWSStub) SetInstanceSecurityGroups(ctx context.Context, instanceID string, groupIDs []string) error {
+	if a.InstanceSecurityGroups == nil {
+		a.InstanceSecurityGroups = make(map[string][]string)
+	}
+	a.InstanceSecurityGroups[instanceID] = groupIDs
+	return nil
+}
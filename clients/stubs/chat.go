@@ -0,0 +1,29 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "context"
+
+// ChatStub provides a stub for the Chat client.
+type ChatStub struct {
+	// Posted holds the texts posted.
+	Posted []string
+}
+
+// Post is a stub of posting to a Google Chat incoming webhook.
+func (s *ChatStub) Post(ctx context.Context, webhook, text string) error {
+	s.Posted = append(s.Posted, text)
+	return nil
+}
@@ -0,0 +1,47 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+)
+
+// CloudBillingStub provides a stub for the Cloud Billing client.
+type CloudBillingStub struct {
+	// StubbedBillingInfo maps project IDs to their billing information.
+	StubbedBillingInfo map[string]*cloudbilling.ProjectBillingInfo
+	// UpdatedBillingInfo maps project IDs to the billing information they were updated with.
+	UpdatedBillingInfo map[string]*cloudbilling.ProjectBillingInfo
+}
+
+// GetBillingInfo returns the stubbed billing information of the project.
+func (s *CloudBillingStub) GetBillingInfo(ctx context.Context, projectID string) (*cloudbilling.ProjectBillingInfo, error) {
+	if info, ok := s.StubbedBillingInfo[projectID]; ok {
+		return info, nil
+	}
+	return &cloudbilling.ProjectBillingInfo{Name: "projects/" + projectID + "/billingInfo", ProjectId: projectID}, nil
+}
+
+// UpdateBillingInfo is a stub of Cloud Billing's UpdateBillingInfo.
+func (s *CloudBillingStub) UpdateBillingInfo(ctx context.Context, projectID string, info *cloudbilling.ProjectBillingInfo) (*cloudbilling.ProjectBillingInfo, error) {
+	if s.UpdatedBillingInfo == nil {
+		s.UpdatedBillingInfo = make(map[string]*cloudbilling.ProjectBillingInfo)
+	}
+	s.UpdatedBillingInfo[projectID] = info
+	info.BillingEnabled = info.BillingAccountName != ""
+	return info, nil
+}
@@ -0,0 +1,45 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"net/http"
+
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// CloudIdentityStub provides a stub for the CloudIdentity client.
+type CloudIdentityStub struct {
+	// Groups maps the email addresses of groups to their resource names, other groups are not found.
+	Groups map[string]string
+	// Memberships maps the resource names of groups to their direct memberships.
+	Memberships map[string][]*cloudidentity.Membership
+}
+
+// LookupGroup is a stub of the Cloud Identity API's groups.lookup.
+func (s *CloudIdentityStub) LookupGroup(ctx context.Context, email string) (string, error) {
+	name, ok := s.Groups[email]
+	if !ok {
+		return "", &googleapi.Error{Code: http.StatusNotFound}
+	}
+	return name, nil
+}
+
+// ListMemberships is a stub of the Cloud Identity API's groups.memberships.list.
+func (s *CloudIdentityStub) ListMemberships(ctx context.Context, group string) ([]*cloudidentity.Membership, error) {
+	return s.Memberships[group], nil
+}
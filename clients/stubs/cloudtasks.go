@@ -0,0 +1,45 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	cloudtasks "google.golang.org/api/cloudtasks/v2"
+)
+
+// CloudTasksStub provides a stub for the Cloud Tasks client.
+type CloudTasksStub struct {
+	// CreatedTasks holds all created tasks.
+	CreatedTasks []*cloudtasks.Task
+	// DeletedTasks holds the names of all deleted tasks.
+	DeletedTasks []string
+}
+
+// CreateTask records the created task.
+func (c *CloudTasksStub) CreateTask(ctx context.Context, queue string, task *cloudtasks.Task) (*cloudtasks.Task, error) {
+	if task.Name == "" {
+		task.Name = fmt.Sprintf("%s/tasks/%d", queue, len(c.CreatedTasks)+1)
+	}
+	c.CreatedTasks = append(c.CreatedTasks, task)
+	return task, nil
+}
+
+// DeleteTask records the deleted task.
+func (c *CloudTasksStub) DeleteTask(ctx context.Context, name string) error {
+	c.DeletedTasks = append(c.DeletedTasks, name)
+	return nil
+}
@@ -0,0 +1,101 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	sccpb "google.golang.org/genproto/googleapis/cloud/securitycenter/v1beta1"
+	"google.golang.org/protobuf/proto"
+)
+
+// SecurityCommandCenterFake is an in-memory Security Command Center holding findings, whose
+// security marks and state are changed like the API does.
+type SecurityCommandCenterFake struct {
+	mu       sync.Mutex
+	findings map[string]*sccpb.Finding
+}
+
+// NewSecurityCommandCenterFake returns an empty fake.
+func NewSecurityCommandCenterFake() *SecurityCommandCenterFake {
+	return &SecurityCommandCenterFake{findings: make(map[string]*sccpb.Finding)}
+}
+
+// AddFinding adds a finding by its name.
+func (s *SecurityCommandCenterFake) AddFinding(f *sccpb.Finding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.findings[f.GetName()] = proto.Clone(f).(*sccpb.Finding)
+}
+
+// Finding returns a copy of the finding, nil if it does not exist.
+func (s *SecurityCommandCenterFake) Finding(name string) *sccpb.Finding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.findings[name]
+	if !ok {
+		return nil
+	}
+	return proto.Clone(f).(*sccpb.Finding)
+}
+
+// AddSecurityMarks sets the marks named by the request's update mask on the finding, replacing all
+// of its marks if there is no mask.
+func (s *SecurityCommandCenterFake) AddSecurityMarks(ctx context.Context, request *sccpb.UpdateSecurityMarksRequest) (*sccpb.SecurityMarks, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := request.GetSecurityMarks().GetName()
+	f, ok := s.findings[strings.TrimSuffix(name, "/securityMarks")]
+	if !ok {
+		return nil, ErrEntityNonExistent
+	}
+	if f.SecurityMarks == nil {
+		f.SecurityMarks = &sccpb.SecurityMarks{Name: name}
+	}
+	marks := request.GetSecurityMarks().GetMarks()
+	paths := request.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		f.SecurityMarks.Marks = make(map[string]string)
+		for k, v := range marks {
+			f.SecurityMarks.Marks[k] = v
+		}
+	}
+	for _, p := range paths {
+		key := strings.TrimPrefix(p, "marks.")
+		if f.SecurityMarks.Marks == nil {
+			f.SecurityMarks.Marks = make(map[string]string)
+		}
+		if v, ok := marks[key]; ok {
+			f.SecurityMarks.Marks[key] = v
+		} else {
+			delete(f.SecurityMarks.Marks, key)
+		}
+	}
+	return proto.Clone(f.SecurityMarks).(*sccpb.SecurityMarks), nil
+}
+
+// SetFindingState sets the state of the finding.
+func (s *SecurityCommandCenterFake) SetFindingState(ctx context.Context, request *sccpb.SetFindingStateRequest) (*sccpb.Finding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.findings[request.GetName()]
+	if !ok {
+		return nil, ErrEntityNonExistent
+	}
+	f.State = request.GetState()
+	return proto.Clone(f).(*sccpb.Finding), nil
+}
@@ -17,9 +17,12 @@ package stubs
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/pkg/errors"
 	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
 )
 
 // ErrNonexistentVM is a stub error returned simulating an error in case of VM not found.
@@ -28,7 +31,12 @@ var ErrNonexistentVM = fmt.Errorf("googleapi: Error 404: The resource 'projects/
 // ComputeStub provides a stub for the compute client.
 type ComputeStub struct {
 	SavedFirewallRule            *compute.Firewall
+	InsertedFirewallRules        []*compute.Firewall
+	FirewallRuleNotFound         bool
+	SavedInstanceTags            *compute.Tags
+	StubbedInstances             []*compute.Instance
 	SavedCreateSnapshots         map[string]compute.Snapshot
+	CreateSnapshotShouldFail     map[string]bool
 	DeletedAccessConfigs         []NetworkAccessConfigStub
 	DeleteAccessConfigShouldFail bool
 	GetInstanceShouldFail        bool
@@ -40,12 +48,23 @@ type ComputeStub struct {
 	StubbedInstance              *compute.Instance
 	SavedDiskInsertDst           string
 	DiskInsertCalled             bool
+	InsertedDisks                []*compute.Disk
+	AttachedDisks                []*compute.AttachedDisk
+	DetachedDevices              []string
+	SavedInstanceLabels          *compute.InstancesSetLabelsRequest
+	// SavedSnapshotLabels are the labels last set on a snapshot.
+	SavedSnapshotLabels map[string]string
+	// mu guards the fields written by the snapshot calls, made concurrently for each disk.
+	mu sync.Mutex
 }
 
 // DiskInsert creates a new disk in the project.
 func (c *ComputeStub) DiskInsert(ctx context.Context, projectID, zone string, disk *compute.Disk) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.SavedDiskInsertDst = projectID
 	c.DiskInsertCalled = true
+	c.InsertedDisks = append(c.InsertedDisks, disk)
 	return nil, nil
 }
 
@@ -58,6 +77,7 @@ type NetworkAccessConfigStub struct {
 // InsertFirewallRule inserts a new firewall rule.
 func (c *ComputeStub) InsertFirewallRule(ctx context.Context, projectID string, fw *compute.Firewall) (*compute.Operation, error) {
 	c.SavedFirewallRule = fw
+	c.InsertedFirewallRules = append(c.InsertedFirewallRules, fw)
 	return nil, nil
 }
 
@@ -74,6 +94,9 @@ func (c *ComputeStub) DeleteFirewallRule(ctx context.Context, projectID string,
 
 // FirewallRule get the details of a firewall rule
 func (c *ComputeStub) FirewallRule(ctx context.Context, projectID string, ruleID string) (*compute.Firewall, error) {
+	if c.FirewallRuleNotFound {
+		return nil, &googleapi.Error{Code: http.StatusNotFound}
+	}
 	return c.StubbedFirewall, nil
 }
 
@@ -99,6 +122,11 @@ func (c *ComputeStub) DeleteAccessConfig(ctx context.Context, project, zone, ins
 
 // CreateSnapshot creates a snapshot of a specified persistent disk.
 func (c *ComputeStub) CreateSnapshot(ctx context.Context, _, _, disk string, snapshot *compute.Snapshot) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.CreateSnapshotShouldFail[disk] {
+		return nil, errors.New("failed to create snapshot")
+	}
 	c.SavedCreateSnapshots[disk] = *snapshot
 	return nil, nil
 }
@@ -110,6 +138,8 @@ func (c *ComputeStub) DeleteDiskSnapshot(_ context.Context, _, _ string) (*compu
 
 // ListProjectSnapshots returns a list of snapshot resources.
 func (c *ComputeStub) ListProjectSnapshots(context.Context, string) (*compute.SnapshotList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if len(c.StubbedListProjectSnapshots) == 0 {
 		return nil, nil
 	}
@@ -127,7 +157,10 @@ func (c *ComputeStub) ListDisks(ctx context.Context, _, _ string) (*compute.Disk
 }
 
 // SetLabels sets the labels on a snapshot.
-func (c *ComputeStub) SetLabels(context.Context, string, string, *compute.GlobalSetLabelsRequest) (*compute.Operation, error) {
+func (c *ComputeStub) SetLabels(_ context.Context, _, _ string, req *compute.GlobalSetLabelsRequest) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SavedSnapshotLabels = req.Labels
 	return nil, nil
 }
 
@@ -141,6 +174,24 @@ func (c *ComputeStub) WaitZone(_, _ string, _ *compute.Operation) []error {
 	return []error{}
 }
 
+// AttachDisk attaches a disk to an instance.
+func (c *ComputeStub) AttachDisk(ctx context.Context, projectID, zone, instance string, disk *compute.AttachedDisk) (*compute.Operation, error) {
+	c.AttachedDisks = append(c.AttachedDisks, disk)
+	return nil, nil
+}
+
+// DetachDisk detaches a disk from an instance.
+func (c *ComputeStub) DetachDisk(ctx context.Context, projectID, zone, instance, deviceName string) (*compute.Operation, error) {
+	c.DetachedDevices = append(c.DetachedDevices, deviceName)
+	return nil, nil
+}
+
+// SetInstanceLabels sets the labels of an instance.
+func (c *ComputeStub) SetInstanceLabels(ctx context.Context, projectID, zone, instance string, rb *compute.InstancesSetLabelsRequest) (*compute.Operation, error) {
+	c.SavedInstanceLabels = rb
+	return nil, nil
+}
+
 // StopInstance stops an instance.
 func (c *ComputeStub) StopInstance(ctx context.Context, projectID, zone, instance string) (*compute.Operation, error) {
 	return c.StubbedStopInstance, nil
@@ -155,3 +206,14 @@ func (c *ComputeStub) StartInstance(ctx context.Context, projectID, zone, instan
 func (c *ComputeStub) DeleteInstance(ctx context.Context, projectID, zone, instance string) (*compute.Operation, error) {
 	return nil, nil
 }
+
+// SetInstanceTags sets the network tags of an instance.
+func (c *ComputeStub) SetInstanceTags(ctx context.Context, projectID, zone, instance string, tags *compute.Tags) (*compute.Operation, error) {
+	c.SavedInstanceTags = tags
+	return nil, nil
+}
+
+// ListInstances returns all instances of the project.
+func (c *ComputeStub) ListInstances(ctx context.Context, projectID string) ([]*compute.Instance, error) {
+	return c.StubbedInstances, nil
+}
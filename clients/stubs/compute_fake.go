@@ -0,0 +1,526 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// ComputeFake is an in-memory Compute Engine holding instances, disks, snapshots and firewall
+// rules. Operations complete immediately, label writes with a stale fingerprint are rejected.
+type ComputeFake struct {
+	mu        sync.Mutex
+	instances map[string]*compute.Instance
+	disks     map[string]*compute.Disk
+	snapshots map[string]*compute.Snapshot
+	firewalls map[string]*compute.Firewall
+	ids       uint64
+}
+
+// NewComputeFake returns an empty fake.
+func NewComputeFake() *ComputeFake {
+	return &ComputeFake{
+		instances: make(map[string]*compute.Instance),
+		disks:     make(map[string]*compute.Disk),
+		snapshots: make(map[string]*compute.Snapshot),
+		firewalls: make(map[string]*compute.Firewall),
+	}
+}
+
+// zonal returns the key of a zonal resource.
+func zonal(project, zone, name string) string {
+	return path.Join(project, zone, name)
+}
+
+// global returns the key of a global resource.
+func global(project, name string) string {
+	return path.Join(project, name)
+}
+
+// id returns the next resource ID.
+func (c *ComputeFake) id() uint64 {
+	c.ids++
+	return c.ids
+}
+
+// done returns a completed operation on the target.
+func (c *ComputeFake) done(kind, target string) *compute.Operation {
+	return &compute.Operation{Name: fmt.Sprintf("operation-%d", c.id()), OperationType: kind, TargetLink: target, Status: "DONE"}
+}
+
+// AddInstance adds an instance to the zone, running unless its status is set.
+func (c *ComputeFake) AddInstance(project, zone string, i *compute.Instance) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var in compute.Instance
+	clone(i, &in)
+	if in.Id == 0 {
+		in.Id = c.id()
+	}
+	if in.Status == "" {
+		in.Status = "RUNNING"
+	}
+	in.Zone = fmt.Sprintf("projects/%s/zones/%s", project, zone)
+	c.instances[zonal(project, zone, in.Name)] = &in
+}
+
+// Instance returns a copy of the instance, nil if it does not exist.
+func (c *ComputeFake) Instance(project, zone, name string) *compute.Instance {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, ok := c.instances[zonal(project, zone, name)]
+	if !ok {
+		return nil
+	}
+	var in compute.Instance
+	clone(i, &in)
+	return &in
+}
+
+// AddDisk adds a disk to the zone.
+func (c *ComputeFake) AddDisk(project, zone string, d *compute.Disk) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addDisk(project, zone, d)
+}
+
+// addDisk adds a copy of the disk, the lock held.
+func (c *ComputeFake) addDisk(project, zone string, d *compute.Disk) {
+	var disk compute.Disk
+	clone(d, &disk)
+	if disk.Id == 0 {
+		disk.Id = c.id()
+	}
+	disk.Zone = fmt.Sprintf("projects/%s/zones/%s", project, zone)
+	disk.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/disks/%s", project, zone, disk.Name)
+	c.disks[zonal(project, zone, disk.Name)] = &disk
+}
+
+// Disk returns a copy of the disk, nil if it does not exist.
+func (c *ComputeFake) Disk(project, zone, name string) *compute.Disk {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.disks[zonal(project, zone, name)]
+	if !ok {
+		return nil
+	}
+	var disk compute.Disk
+	clone(d, &disk)
+	return &disk
+}
+
+// Snapshots returns copies of the project's snapshots ordered by name.
+func (c *ComputeFake) Snapshots(project string) []*compute.Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.projectSnapshots(project)
+}
+
+// projectSnapshots returns copies of the project's snapshots ordered by name, the lock held.
+func (c *ComputeFake) projectSnapshots(project string) []*compute.Snapshot {
+	var l []*compute.Snapshot
+	for k, s := range c.snapshots {
+		if path.Dir(k) != project {
+			continue
+		}
+		var snapshot compute.Snapshot
+		clone(s, &snapshot)
+		l = append(l, &snapshot)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	return l
+}
+
+// AddFirewall adds a firewall rule to the project.
+func (c *ComputeFake) AddFirewall(project string, fw *compute.Firewall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addFirewall(project, fw)
+}
+
+// addFirewall adds a copy of the rule, the lock held.
+func (c *ComputeFake) addFirewall(project string, fw *compute.Firewall) {
+	var rule compute.Firewall
+	clone(fw, &rule)
+	if rule.Id == 0 {
+		rule.Id = c.id()
+	}
+	c.firewalls[global(project, rule.Name)] = &rule
+}
+
+// Firewall returns a copy of the firewall rule named or numbered, nil if it does not exist.
+func (c *ComputeFake) Firewall(project, rule string) *compute.Firewall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fw, ok := c.firewall(project, rule)
+	if !ok {
+		return nil
+	}
+	var f compute.Firewall
+	clone(fw, &f)
+	return &f
+}
+
+// firewall looks up a rule by name or ID like the API does.
+func (c *ComputeFake) firewall(project, rule string) (*compute.Firewall, bool) {
+	if fw, ok := c.firewalls[global(project, rule)]; ok {
+		return fw, true
+	}
+	for k, fw := range c.firewalls {
+		if path.Dir(k) == project && strconv.FormatUint(fw.Id, 10) == rule {
+			return fw, true
+		}
+	}
+	return nil, false
+}
+
+// instance looks up an instance by name or ID like the API does.
+func (c *ComputeFake) instance(project, zone, instance string) (*compute.Instance, error) {
+	if i, ok := c.instances[zonal(project, zone, instance)]; ok {
+		return i, nil
+	}
+	for _, i := range c.instances {
+		if i.Zone == fmt.Sprintf("projects/%s/zones/%s", project, zone) && strconv.FormatUint(i.Id, 10) == instance {
+			return i, nil
+		}
+	}
+	return nil, notFound("instance %q not found in %s/%s", instance, project, zone)
+}
+
+// AttachDisk attaches the disk to the instance.
+func (c *ComputeFake) AttachDisk(ctx context.Context, project, zone, instance string, disk *compute.AttachedDisk) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	var d compute.AttachedDisk
+	clone(disk, &d)
+	i.Disks = append(i.Disks, &d)
+	return c.done("attachDisk", i.Name), nil
+}
+
+// DetachDisk detaches the disk attached under the device name from the instance.
+func (c *ComputeFake) DetachDisk(ctx context.Context, project, zone, instance, deviceName string) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	for n, d := range i.Disks {
+		if d.DeviceName == deviceName {
+			i.Disks = append(i.Disks[:n], i.Disks[n+1:]...)
+			return c.done("detachDisk", i.Name), nil
+		}
+	}
+	return nil, notFound("disk %q not attached to %q", deviceName, instance)
+}
+
+// DiskInsert creates a disk in the zone.
+func (c *ComputeFake) DiskInsert(ctx context.Context, project, zone string, disk *compute.Disk) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.disks[zonal(project, zone, disk.Name)]; ok {
+		return nil, &googleapi.Error{Code: http.StatusConflict, Message: fmt.Sprintf("disk %q already exists", disk.Name)}
+	}
+	c.addDisk(project, zone, disk)
+	return c.done("insert", disk.Name), nil
+}
+
+// CreateSnapshot snapshots the disk.
+func (c *ComputeFake) CreateSnapshot(ctx context.Context, project, zone, disk string, snapshot *compute.Snapshot) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.disks[zonal(project, zone, disk)]
+	if !ok {
+		return nil, notFound("disk %q not found in %s/%s", disk, project, zone)
+	}
+	if _, ok := c.snapshots[global(project, snapshot.Name)]; ok {
+		return nil, &googleapi.Error{Code: http.StatusConflict, Message: fmt.Sprintf("snapshot %q already exists", snapshot.Name)}
+	}
+	var s compute.Snapshot
+	clone(snapshot, &s)
+	s.Id = c.id()
+	s.SourceDisk = d.SelfLink
+	s.Status = "READY"
+	s.LabelFingerprint = fmt.Sprintf("fingerprint-%d", c.id())
+	c.snapshots[global(project, s.Name)] = &s
+	return c.done("createSnapshot", disk), nil
+}
+
+// DeleteAccessConfig removes the access config from the instance's network interface.
+func (c *ComputeFake) DeleteAccessConfig(ctx context.Context, project, zone, instance, accessConfig, networkInterface string) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	for _, ni := range i.NetworkInterfaces {
+		if ni.Name != networkInterface {
+			continue
+		}
+		for n, ac := range ni.AccessConfigs {
+			if ac.Name == accessConfig {
+				ni.AccessConfigs = append(ni.AccessConfigs[:n], ni.AccessConfigs[n+1:]...)
+				return c.done("deleteAccessConfig", i.Name), nil
+			}
+		}
+	}
+	return nil, notFound("access config %q not found on %q of %q", accessConfig, networkInterface, instance)
+}
+
+// DeleteDiskSnapshot deletes the snapshot.
+func (c *ComputeFake) DeleteDiskSnapshot(ctx context.Context, project, snapshot string) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.snapshots[global(project, snapshot)]; !ok {
+		return nil, notFound("snapshot %q not found in %s", snapshot, project)
+	}
+	delete(c.snapshots, global(project, snapshot))
+	return c.done("delete", snapshot), nil
+}
+
+// DeleteInstance deletes the instance.
+func (c *ComputeFake) DeleteInstance(ctx context.Context, project, zone, instance string) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	delete(c.instances, zonal(project, zone, i.Name))
+	return c.done("delete", i.Name), nil
+}
+
+// GetInstance returns the instance.
+func (c *ComputeFake) GetInstance(ctx context.Context, project, zone, instance string) (*compute.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	var in compute.Instance
+	clone(i, &in)
+	return &in, nil
+}
+
+// ListDisks returns the disks of the zone ordered by name.
+func (c *ComputeFake) ListDisks(ctx context.Context, project, zone string) (*compute.DiskList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := &compute.DiskList{}
+	for k, d := range c.disks {
+		if path.Dir(k) != path.Join(project, zone) {
+			continue
+		}
+		var disk compute.Disk
+		clone(d, &disk)
+		list.Items = append(list.Items, &disk)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+	return list, nil
+}
+
+// ListInstances returns the instances of every zone of the project ordered by name.
+func (c *ComputeFake) ListInstances(ctx context.Context, project string) ([]*compute.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var l []*compute.Instance
+	for k, i := range c.instances {
+		if path.Dir(path.Dir(k)) != project {
+			continue
+		}
+		var in compute.Instance
+		clone(i, &in)
+		l = append(l, &in)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	return l, nil
+}
+
+// ListProjectSnapshots returns the snapshots of the project ordered by name.
+func (c *ComputeFake) ListProjectSnapshots(ctx context.Context, project string) (*compute.SnapshotList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &compute.SnapshotList{Items: c.projectSnapshots(project)}, nil
+}
+
+// SetInstanceLabels replaces the instance's labels.
+func (c *ComputeFake) SetInstanceLabels(ctx context.Context, project, zone, instance string, req *compute.InstancesSetLabelsRequest) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	if req.LabelFingerprint != i.LabelFingerprint {
+		return nil, &googleapi.Error{Code: http.StatusPreconditionFailed, Message: "stale label fingerprint"}
+	}
+	i.Labels = make(map[string]string)
+	for k, v := range req.Labels {
+		i.Labels[k] = v
+	}
+	i.LabelFingerprint = fmt.Sprintf("fingerprint-%d", c.id())
+	return c.done("setLabels", i.Name), nil
+}
+
+// SetInstanceTags replaces the instance's network tags.
+func (c *ComputeFake) SetInstanceTags(ctx context.Context, project, zone, instance string, tags *compute.Tags) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	if i.Tags != nil && tags.Fingerprint != i.Tags.Fingerprint {
+		return nil, &googleapi.Error{Code: http.StatusPreconditionFailed, Message: "stale tags fingerprint"}
+	}
+	i.Tags = &compute.Tags{Items: append([]string(nil), tags.Items...), Fingerprint: fmt.Sprintf("fingerprint-%d", c.id())}
+	return c.done("setTags", i.Name), nil
+}
+
+// SetLabels replaces the labels of the snapshot given by ID.
+func (c *ComputeFake) SetLabels(ctx context.Context, project, resource string, req *compute.GlobalSetLabelsRequest) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, s := range c.snapshots {
+		if path.Dir(k) != project || (strconv.FormatUint(s.Id, 10) != resource && s.Name != resource) {
+			continue
+		}
+		if req.LabelFingerprint != s.LabelFingerprint {
+			return nil, &googleapi.Error{Code: http.StatusPreconditionFailed, Message: "stale label fingerprint"}
+		}
+		s.Labels = make(map[string]string)
+		for k, v := range req.Labels {
+			s.Labels[k] = v
+		}
+		s.LabelFingerprint = fmt.Sprintf("fingerprint-%d", c.id())
+		return c.done("setLabels", s.Name), nil
+	}
+	return nil, notFound("snapshot %q not found in %s", resource, project)
+}
+
+// StartInstance sets the instance running.
+func (c *ComputeFake) StartInstance(ctx context.Context, project, zone, instance string) (*compute.Operation, error) {
+	return c.setStatus(project, zone, instance, "RUNNING", "start")
+}
+
+// StopInstance sets the instance terminated.
+func (c *ComputeFake) StopInstance(ctx context.Context, project, zone, instance string) (*compute.Operation, error) {
+	return c.setStatus(project, zone, instance, "TERMINATED", "stop")
+}
+
+// setStatus sets the status of the instance.
+func (c *ComputeFake) setStatus(project, zone, instance, status, kind string) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.instance(project, zone, instance)
+	if err != nil {
+		return nil, err
+	}
+	i.Status = status
+	return c.done(kind, i.Name), nil
+}
+
+// InsertFirewallRule creates the firewall rule.
+func (c *ComputeFake) InsertFirewallRule(ctx context.Context, project string, fw *compute.Firewall) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.firewalls[global(project, fw.Name)]; ok {
+		return nil, &googleapi.Error{Code: http.StatusConflict, Message: fmt.Sprintf("firewall rule %q already exists", fw.Name)}
+	}
+	c.addFirewall(project, fw)
+	return c.done("insert", fw.Name), nil
+}
+
+// PatchFirewallRule updates the fields of the rule set in the patch, including those forced to be
+// sent.
+func (c *ComputeFake) PatchFirewallRule(ctx context.Context, project, rule string, patch *compute.Firewall) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fw, ok := c.firewall(project, rule)
+	if !ok {
+		return nil, notFound("firewall rule %q not found in %s", rule, project)
+	}
+	forced := make(map[string]bool)
+	for _, f := range patch.ForceSendFields {
+		forced[f] = true
+	}
+	if patch.Disabled || forced["Disabled"] {
+		fw.Disabled = patch.Disabled
+	}
+	if len(patch.SourceRanges) > 0 || forced["SourceRanges"] {
+		fw.SourceRanges = append([]string(nil), patch.SourceRanges...)
+	}
+	if len(patch.TargetTags) > 0 || forced["TargetTags"] {
+		fw.TargetTags = append([]string(nil), patch.TargetTags...)
+	}
+	if len(patch.Allowed) > 0 || forced["Allowed"] {
+		fw.Allowed = patch.Allowed
+	}
+	if len(patch.Denied) > 0 || forced["Denied"] {
+		fw.Denied = patch.Denied
+	}
+	if patch.Priority != 0 {
+		fw.Priority = patch.Priority
+	}
+	if patch.Description != "" {
+		fw.Description = patch.Description
+	}
+	return c.done("patch", fw.Name), nil
+}
+
+// FirewallRule returns the firewall rule named or numbered.
+func (c *ComputeFake) FirewallRule(ctx context.Context, project, rule string) (*compute.Firewall, error) {
+	if fw := c.Firewall(project, rule); fw != nil {
+		return fw, nil
+	}
+	return nil, notFound("firewall rule %q not found in %s", rule, project)
+}
+
+// DeleteFirewallRule deletes the firewall rule named or numbered.
+func (c *ComputeFake) DeleteFirewallRule(ctx context.Context, project, rule string) (*compute.Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fw, ok := c.firewall(project, rule)
+	if !ok {
+		return nil, notFound("firewall rule %q not found in %s", rule, project)
+	}
+	delete(c.firewalls, global(project, fw.Name))
+	return c.done("delete", fw.Name), nil
+}
+
+// WaitGlobal returns immediately as the fake's operations are done when returned.
+func (c *ComputeFake) WaitGlobal(project string, op *compute.Operation) []error {
+	return nil
+}
+
+// WaitZone returns immediately as the fake's operations are done when returned.
+func (c *ComputeFake) WaitZone(project, zone string, op *compute.Operation) []error {
+	return nil
+}
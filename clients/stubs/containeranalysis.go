@@ -0,0 +1,57 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+)
+
+// ContainerAnalysisStub provides a stub for the Container Analysis client.
+type ContainerAnalysisStub struct {
+	// StubbedOccurrences holds the occurrences keyed by name.
+	StubbedOccurrences map[string]*containeranalysis.Occurrence
+	// ListFilter holds the filter of the last list call.
+	ListFilter string
+	// DeletedOccurrences holds the names of all deleted occurrences.
+	DeletedOccurrences []string
+}
+
+// GetOccurrence returns the stubbed occurrence.
+func (c *ContainerAnalysisStub) GetOccurrence(ctx context.Context, name string) (*containeranalysis.Occurrence, error) {
+	o, ok := c.StubbedOccurrences[name]
+	if !ok {
+		return nil, fmt.Errorf("occurrence %q not found", name)
+	}
+	return o, nil
+}
+
+// ListOccurrences returns all stubbed occurrences and records the filter.
+func (c *ContainerAnalysisStub) ListOccurrences(ctx context.Context, projectID, filter string) ([]*containeranalysis.Occurrence, error) {
+	c.ListFilter = filter
+	var occurrences []*containeranalysis.Occurrence
+	for _, o := range c.StubbedOccurrences {
+		occurrences = append(occurrences, o)
+	}
+	return occurrences, nil
+}
+
+// DeleteOccurrence records the deleted occurrence.
+func (c *ContainerAnalysisStub) DeleteOccurrence(ctx context.Context, name string) error {
+	c.DeletedOccurrences = append(c.DeletedOccurrences, name)
+	return nil
+}
@@ -0,0 +1,114 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// DirectoryStub provides a stub for the Directory client.
+type DirectoryStub struct {
+	// Users maps user keys to the users returned by GetUser and updated by PatchUser.
+	Users map[string]*admin.User
+	// Groups maps user keys to the groups returned by ListGroups.
+	Groups map[string][]*admin.Group
+	// SignedOut holds the users signed out.
+	SignedOut []string
+	// Tokens maps user keys to the OAuth tokens returned by ListTokens and revoked by DeleteToken.
+	Tokens map[string][]*admin.Token
+	// InsertedMembers maps groups to the members added to them.
+	InsertedMembers map[string][]string
+	// DeletedMembers maps groups to the members removed from them.
+	DeletedMembers map[string][]string
+}
+
+// GetUser is a stub of the Directory API's users.get.
+func (s *DirectoryStub) GetUser(ctx context.Context, userKey string) (*admin.User, error) {
+	u, ok := s.Users[userKey]
+	if !ok {
+		return nil, fmt.Errorf("user %q not found", userKey)
+	}
+	return u, nil
+}
+
+// PatchUser is a stub of the Directory API's users.patch, only suspension and the organizational
+// unit are patched.
+func (s *DirectoryStub) PatchUser(ctx context.Context, userKey string, patch *admin.User) (*admin.User, error) {
+	u, err := s.GetUser(ctx, userKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range patch.ForceSendFields {
+		if f == "Suspended" {
+			u.Suspended = patch.Suspended
+		}
+	}
+	if patch.Suspended {
+		u.Suspended = true
+	}
+	if patch.OrgUnitPath != "" {
+		u.OrgUnitPath = patch.OrgUnitPath
+	}
+	return u, nil
+}
+
+// SignOut is a stub of the Directory API's users.signOut.
+func (s *DirectoryStub) SignOut(ctx context.Context, userKey string) error {
+	s.SignedOut = append(s.SignedOut, userKey)
+	return nil
+}
+
+// ListTokens is a stub of the Directory API's tokens.list.
+func (s *DirectoryStub) ListTokens(ctx context.Context, userKey string) ([]*admin.Token, error) {
+	return s.Tokens[userKey], nil
+}
+
+// DeleteToken is a stub of the Directory API's tokens.delete.
+func (s *DirectoryStub) DeleteToken(ctx context.Context, userKey, clientID string) error {
+	var tokens []*admin.Token
+	for _, t := range s.Tokens[userKey] {
+		if t.ClientId != clientID {
+			tokens = append(tokens, t)
+		}
+	}
+	s.Tokens[userKey] = tokens
+	return nil
+}
+
+// ListGroups is a stub of the Directory API's groups.list.
+func (s *DirectoryStub) ListGroups(ctx context.Context, userKey string) ([]*admin.Group, error) {
+	return s.Groups[userKey], nil
+}
+
+// InsertMember is a stub of the Directory API's members.insert.
+func (s *DirectoryStub) InsertMember(ctx context.Context, groupKey string, member *admin.Member) (*admin.Member, error) {
+	if s.InsertedMembers == nil {
+		s.InsertedMembers = make(map[string][]string)
+	}
+	s.InsertedMembers[groupKey] = append(s.InsertedMembers[groupKey], member.Email)
+	return member, nil
+}
+
+// DeleteMember is a stub of the Directory API's members.delete.
+func (s *DirectoryStub) DeleteMember(ctx context.Context, groupKey, memberKey string) error {
+	if s.DeletedMembers == nil {
+		s.DeletedMembers = make(map[string][]string)
+	}
+	s.DeletedMembers[groupKey] = append(s.DeletedMembers[groupKey], memberKey)
+	return nil
+}
@@ -0,0 +1,33 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+)
+
+// ErrorReportingStub provides a stub for the Error Reporting client.
+type ErrorReportingStub struct {
+	// Events holds the error events reported.
+	Events []*clouderrorreporting.ReportedErrorEvent
+}
+
+// ReportErrorEvent is a stub of reporting an error event.
+func (s *ErrorReportingStub) ReportErrorEvent(ctx context.Context, projectID string, event *clouderrorreporting.ReportedErrorEvent) error {
+	s.Events = append(s.Events, event)
+	return nil
+}
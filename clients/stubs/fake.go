@@ -0,0 +1,44 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// The fakes in this package keep the resources they are given in memory and change them as the
+// APIs would, unlike the stubs returning canned responses. Tests of custom actions can seed a fake,
+// run the action through the services and assert on the state left behind.
+
+// notFound returns the error the APIs return for a missing resource.
+func notFound(format string, a ...interface{}) error {
+	return &googleapi.Error{Code: http.StatusNotFound, Message: fmt.Sprintf(format, a...)}
+}
+
+// clone deep copies src into dst, both pointers to the same API type, so callers of a fake cannot
+// change its state without calling it.
+func clone(src, dst interface{}) {
+	b, err := json.Marshal(src)
+	if err != nil {
+		panic(fmt.Sprintf("failed to copy %T: %q", src, err))
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		panic(fmt.Sprintf("failed to copy %T: %q", src, err))
+	}
+}
@@ -0,0 +1,133 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	firestore "google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// FirestoreStub provides an in-memory stub for the Firestore client.
+type FirestoreStub struct {
+	// Documents maps document names to documents.
+	Documents map[string]*firestore.Document
+	// ReadDocuments counts the documents returned by queries.
+	ReadDocuments int
+	updates       int
+	// mu guards the documents, written concurrently by claims racing for the same remediation.
+	mu sync.Mutex
+}
+
+// GetDocument returns the stored document or a not found error.
+func (s *FirestoreStub) GetDocument(ctx context.Context, name string) (*firestore.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.Documents[name]
+	if !ok {
+		return nil, &googleapi.Error{Code: http.StatusNotFound}
+	}
+	return d, nil
+}
+
+// CreateDocument stores the document or returns a conflict error if it exists.
+func (s *FirestoreStub) CreateDocument(ctx context.Context, parent, collection, id string, doc *firestore.Document) (*firestore.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := parent + "/" + collection + "/" + id
+	if _, ok := s.Documents[name]; ok {
+		return nil, &googleapi.Error{Code: http.StatusConflict}
+	}
+	return s.patch(name, doc), nil
+}
+
+// PatchDocument stores the document.
+func (s *FirestoreStub) PatchDocument(ctx context.Context, name string, doc *firestore.Document) (*firestore.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.patch(name, doc), nil
+}
+
+func (s *FirestoreStub) patch(name string, doc *firestore.Document) *firestore.Document {
+	if s.Documents == nil {
+		s.Documents = make(map[string]*firestore.Document)
+	}
+	s.updates++
+	d := *doc
+	d.Name = name
+	d.UpdateTime = fmt.Sprintf("2020-06-01T18:00:%02d.000000Z", s.updates)
+	s.Documents[name] = &d
+	return &d
+}
+
+// PatchDocumentIf stores the document if it was last updated at updateTime, or if it does not
+// exist if updateTime is empty, and returns the error Firestore does otherwise.
+func (s *FirestoreStub) PatchDocumentIf(ctx context.Context, name string, doc *firestore.Document, updateTime string) (*firestore.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.Documents[name]
+	switch {
+	case updateTime == "" && ok:
+		return nil, &googleapi.Error{Code: http.StatusConflict}
+	case updateTime != "" && (!ok || d.UpdateTime != updateTime):
+		return nil, &googleapi.Error{Code: http.StatusBadRequest, Body: "FAILED_PRECONDITION"}
+	}
+	return s.patch(name, doc), nil
+}
+
+// RunQuery returns the documents of the queried collection, only equality filters on string
+// fields and GREATER_THAN_OR_EQUAL filters on timestamp fields are supported.
+func (s *FirestoreStub) RunQuery(ctx context.Context, parent string, q *firestore.StructuredQuery) ([]*firestore.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range s.Documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var docs []*firestore.Document
+	for _, name := range names {
+		if !strings.HasPrefix(name, parent+"/"+q.From[0].CollectionId+"/") {
+			continue
+		}
+		d := s.Documents[name]
+		if f := q.Where; f != nil && f.FieldFilter != nil {
+			v, ok := d.Fields[f.FieldFilter.Field.FieldPath]
+			if !ok || !matches(f.FieldFilter.Op, v, f.FieldFilter.Value) {
+				continue
+			}
+		}
+		docs = append(docs, d)
+	}
+	s.ReadDocuments += len(docs)
+	return docs, nil
+}
+
+// matches reports whether the value of a document field passes the filter.
+func matches(op string, v firestore.Value, filter *firestore.Value) bool {
+	if op == "GREATER_THAN_OR_EQUAL" {
+		t, err := time.Parse(time.RFC3339Nano, v.TimestampValue)
+		from, ferr := time.Parse(time.RFC3339Nano, filter.TimestampValue)
+		return err == nil && ferr == nil && !t.Before(from)
+	}
+	return v.StringValue == filter.StringValue
+}
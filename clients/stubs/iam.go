@@ -0,0 +1,45 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	iam "google.golang.org/api/iam/v1"
+)
+
+// IAMStub provides a stub for the IAM client.
+type IAMStub struct {
+	// Keys maps service accounts, named "projects/<id>/serviceAccounts/<email>", to their keys.
+	Keys map[string][]*iam.ServiceAccountKey
+	// DisabledKeys holds the names of the keys disabled.
+	DisabledKeys []string
+}
+
+// ListServiceAccountKeys is a stub of the IAM API's serviceAccounts.keys.list.
+func (s *IAMStub) ListServiceAccountKeys(ctx context.Context, name string) ([]*iam.ServiceAccountKey, error) {
+	keys, ok := s.Keys[name]
+	if !ok {
+		return nil, fmt.Errorf("service account %q not found", name)
+	}
+	return keys, nil
+}
+
+// DisableServiceAccountKey is a stub of the IAM API's serviceAccounts.keys.disable.
+func (s *IAMStub) DisableServiceAccountKey(ctx context.Context, name string) error {
+	s.DisabledKeys = append(s.DisabledKeys, name)
+	return nil
+}
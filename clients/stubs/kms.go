@@ -0,0 +1,65 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+// KMSStub provides a stub for the KMS client.
+type KMSStub struct {
+	// StubbedKeys maps crypto key names to the keys returned.
+	StubbedKeys     map[string]*cloudkms.CryptoKey
+	SavedKey        *cloudkms.CryptoKey
+	SavedKeyName    string
+	SavedUpdateMask string
+	// StubbedPolicies maps crypto key and key ring names to their IAM policies.
+	StubbedPolicies map[string]*cloudkms.Policy
+	SavedPolicy     *cloudkms.Policy
+	SavedResource   string
+}
+
+// GetCryptoKey is a stub of Cloud KMS's GetCryptoKey.
+func (s *KMSStub) GetCryptoKey(ctx context.Context, name string) (*cloudkms.CryptoKey, error) {
+	if k, ok := s.StubbedKeys[name]; ok {
+		return k, nil
+	}
+	return &cloudkms.CryptoKey{Name: name, Purpose: "ENCRYPT_DECRYPT"}, nil
+}
+
+// PatchCryptoKey is a stub of Cloud KMS's PatchCryptoKey.
+func (s *KMSStub) PatchCryptoKey(ctx context.Context, name, updateMask string, key *cloudkms.CryptoKey) (*cloudkms.CryptoKey, error) {
+	s.SavedKeyName = name
+	s.SavedUpdateMask = updateMask
+	s.SavedKey = key
+	return key, nil
+}
+
+// GetIamPolicy is a stub of Cloud KMS's GetIamPolicy.
+func (s *KMSStub) GetIamPolicy(ctx context.Context, resource string) (*cloudkms.Policy, error) {
+	if p, ok := s.StubbedPolicies[resource]; ok {
+		return p, nil
+	}
+	return &cloudkms.Policy{}, nil
+}
+
+// SetIamPolicy is a stub of Cloud KMS's SetIamPolicy.
+func (s *KMSStub) SetIamPolicy(ctx context.Context, resource string, policy *cloudkms.Policy) (*cloudkms.Policy, error) {
+	s.SavedResource = resource
+	s.SavedPolicy = policy
+	return policy, nil
+}
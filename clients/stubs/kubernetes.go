@@ -0,0 +1,43 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "context"
+
+// KubernetesStub provides a stub for the Kubernetes client.
+type KubernetesStub struct {
+	// Patched holds the bodies of all patches keyed by API path.
+	Patched map[string][]byte
+	// Created holds the bodies of all created objects keyed by API path.
+	Created map[string][]byte
+}
+
+// Patch applies a strategic merge patch to the object at the given API path.
+func (k *KubernetesStub) Patch(ctx context.Context, projectID, location, clusterID, path string, body []byte) error {
+	if k.Patched == nil {
+		k.Patched = make(map[string][]byte)
+	}
+	k.Patched[path] = body
+	return nil
+}
+
+// Create creates an object in the collection at the given API path.
+func (k *KubernetesStub) Create(ctx context.Context, projectID, location, clusterID, path string, body []byte) error {
+	if k.Created == nil {
+		k.Created = make(map[string][]byte)
+	}
+	k.Created[path] = body
+	return nil
+}
@@ -0,0 +1,40 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	cloudlogging "google.golang.org/api/logging/v2"
+)
+
+// LogEntriesStub provides a stub for the Cloud Logging client reading log entries.
+type LogEntriesStub struct {
+	// StubbedEntries maps filters to the pages of entries returned for them.
+	StubbedEntries map[string][][]*cloudlogging.LogEntry
+	// Filters holds the filters entries were listed with.
+	Filters []string
+}
+
+// ListLogEntries is a stub of listing the log entries matching the filter.
+func (s *LogEntriesStub) ListLogEntries(ctx context.Context, projectID, filter string, fn func([]*cloudlogging.LogEntry) error) error {
+	s.Filters = append(s.Filters, filter)
+	for _, page := range s.StubbedEntries[filter] {
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
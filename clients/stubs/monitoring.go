@@ -0,0 +1,75 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	dashboard "google.golang.org/api/monitoring/v1"
+	monitoring "google.golang.org/api/monitoring/v3"
+)
+
+// MonitoringStub provides a stub for the Monitoring client.
+type MonitoringStub struct {
+	Dashboards    []*dashboard.Dashboard
+	AlertPolicies []*monitoring.AlertPolicy
+}
+
+// ListDashboards is a stub of the Monitoring API's dashboards.list.
+func (s *MonitoringStub) ListDashboards(ctx context.Context, projectID string) ([]*dashboard.Dashboard, error) {
+	return s.Dashboards, nil
+}
+
+// CreateDashboard is a stub of the Monitoring API's dashboards.create, naming the dashboard.
+func (s *MonitoringStub) CreateDashboard(ctx context.Context, projectID string, d *dashboard.Dashboard) (*dashboard.Dashboard, error) {
+	d.Name = fmt.Sprintf("projects/%s/dashboards/%d", projectID, len(s.Dashboards))
+	s.Dashboards = append(s.Dashboards, d)
+	return d, nil
+}
+
+// UpdateDashboard is a stub of the Monitoring API's dashboards.patch.
+func (s *MonitoringStub) UpdateDashboard(ctx context.Context, d *dashboard.Dashboard) (*dashboard.Dashboard, error) {
+	for i, existing := range s.Dashboards {
+		if existing.Name == d.Name {
+			s.Dashboards[i] = d
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("dashboard %q not found", d.Name)
+}
+
+// ListAlertPolicies is a stub of the Monitoring API's alertPolicies.list.
+func (s *MonitoringStub) ListAlertPolicies(ctx context.Context, projectID string) ([]*monitoring.AlertPolicy, error) {
+	return s.AlertPolicies, nil
+}
+
+// CreateAlertPolicy is a stub of the Monitoring API's alertPolicies.create, naming the policy.
+func (s *MonitoringStub) CreateAlertPolicy(ctx context.Context, projectID string, p *monitoring.AlertPolicy) (*monitoring.AlertPolicy, error) {
+	p.Name = fmt.Sprintf("projects/%s/alertPolicies/%d", projectID, len(s.AlertPolicies))
+	s.AlertPolicies = append(s.AlertPolicies, p)
+	return p, nil
+}
+
+// UpdateAlertPolicy is a stub of the Monitoring API's alertPolicies.patch.
+func (s *MonitoringStub) UpdateAlertPolicy(ctx context.Context, p *monitoring.AlertPolicy) (*monitoring.AlertPolicy, error) {
+	for i, existing := range s.AlertPolicies {
+		if existing.Name == p.Name {
+			s.AlertPolicies[i] = p
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("alert policy %q not found", p.Name)
+}
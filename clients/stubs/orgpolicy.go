@@ -0,0 +1,54 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// OrgPolicyStub provides a stub for the OrgPolicy client.
+type OrgPolicyStub struct {
+	// Policies maps constraints to the policies set on the resource.
+	Policies map[string]*crm.OrgPolicy
+	// EffectivePolicies maps constraints to the policies in effect, Policies if not set.
+	EffectivePolicies map[string]*crm.OrgPolicy
+	SavedResource     string
+	SavedPolicy       *crm.OrgPolicy
+}
+
+// GetOrgPolicy is a stub of Cloud Resource Manager's GetOrgPolicy.
+func (s *OrgPolicyStub) GetOrgPolicy(ctx context.Context, resource, constraint string) (*crm.OrgPolicy, error) {
+	if p, ok := s.Policies[constraint]; ok {
+		return p, nil
+	}
+	return &crm.OrgPolicy{Constraint: constraint}, nil
+}
+
+// GetEffectiveOrgPolicy is a stub of Cloud Resource Manager's GetEffectiveOrgPolicy.
+func (s *OrgPolicyStub) GetEffectiveOrgPolicy(ctx context.Context, resource, constraint string) (*crm.OrgPolicy, error) {
+	if p, ok := s.EffectivePolicies[constraint]; ok {
+		return p, nil
+	}
+	return s.GetOrgPolicy(ctx, resource, constraint)
+}
+
+// SetOrgPolicy is a stub of Cloud Resource Manager's SetOrgPolicy.
+func (s *OrgPolicyStub) SetOrgPolicy(ctx context.Context, resource string, policy *crm.OrgPolicy) (*crm.OrgPolicy, error) {
+	s.SavedResource = resource
+	s.SavedPolicy = policy
+	return policy, nil
+}
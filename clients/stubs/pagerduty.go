@@ -0,0 +1,34 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "github.com/PagerDuty/go-pagerduty"
+
+// Incident is an incident created through the PagerDuty stub.
+type Incident struct {
+	From, ServiceID, Title, Body string
+}
+
+// PagerDutyStub provides a stub for the PagerDuty client.
+type PagerDutyStub struct {
+	// Incidents holds the incidents created in order.
+	Incidents []Incident
+}
+
+// CreateIncident records the incident.
+func (p *PagerDutyStub) CreateIncident(from, serviceID, title, body string) (*pagerduty.Incident, error) {
+	p.Incidents = append(p.Incidents, Incident{From: from, ServiceID: serviceID, Title: title, Body: body})
+	return &pagerduty.Incident{}, nil
+}
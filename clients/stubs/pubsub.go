@@ -16,6 +16,7 @@ package stubs
 
 import (
 	"context"
+	"sync"
 
 	"cloud.google.com/go/pubsub"
 )
@@ -24,6 +25,9 @@ import (
 type PubSubStub struct {
 	StubbedTopic     *pubsub.Topic
 	PublishedMessage *pubsub.Message
+	// Published are all the messages published, in order.
+	Published []*pubsub.Message
+	mu        sync.Mutex
 }
 
 // Topic returns a reference to a topic.
@@ -33,6 +37,9 @@ func (p *PubSubStub) Topic(id string) *pubsub.Topic {
 
 // Publish will publish a message to a PubSub topic.
 func (p *PubSubStub) Publish(ctx context.Context, topic *pubsub.Topic, message *pubsub.Message) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.PublishedMessage = message
+	p.Published = append(p.Published, message)
 	return "", nil
 }
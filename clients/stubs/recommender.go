@@ -0,0 +1,40 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+// RecommenderStub provides a stub for the Recommender client.
+type RecommenderStub struct {
+	// Recommendations maps recommenders to the recommendations returned by ListRecommendations.
+	Recommendations map[string][]*recommender.GoogleCloudRecommenderV1Recommendation
+	// Succeeded holds the names of the recommendations marked as succeeded.
+	Succeeded []string
+}
+
+// ListRecommendations is a stub of the Recommender API's recommendations.list, ignoring the filter.
+func (s *RecommenderStub) ListRecommendations(ctx context.Context, parent, filter string) ([]*recommender.GoogleCloudRecommenderV1Recommendation, error) {
+	return s.Recommendations[parent], nil
+}
+
+// MarkRecommendationSucceeded is a stub of the Recommender API's recommendations.markSucceeded.
+func (s *RecommenderStub) MarkRecommendationSucceeded(ctx context.Context, name, etag string) error {
+	s.Succeeded = append(s.Succeeded, name)
+	return nil
+}
@@ -17,8 +17,15 @@ package stubs
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sync"
 
 	crm "google.golang.org/api/cloudresourcemanager/v1"
+	crmv2 "google.golang.org/api/cloudresourcemanager/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // ResourceManagerStub provides a stub for the CRM client.
@@ -27,27 +34,99 @@ type ResourceManagerStub struct {
 	GetAncestryResponse     *crm.GetAncestryResponse
 	SavedSetPolicy          *crm.Policy
 	GetOrganizationResponse *crm.Organization
+	// AncestryCalls counts the calls to GetAncestry.
+	AncestryCalls int
+	// OrganizationCalls counts the calls to GetOrganization.
+	OrganizationCalls int
+	// StubbedFolders maps folder names to the folders returned by GetFolder.
+	StubbedFolders map[string]*crmv2.Folder
+	// StubbedFolderPolicies maps folder names to the policies returned by GetPolicyFolder.
+	StubbedFolderPolicies map[string]*crmv2.Policy
+	// PolicyConflicts is the number of policy writes rejected as having a stale ETag.
+	PolicyConflicts int
+	// StubbedProjects maps project IDs to the projects returned by GetProject.
+	StubbedProjects map[string]*crm.Project
+	// ProjectPages holds the pages of projects returned by ListProjects, in order.
+	ProjectPages [][]*crm.Project
+	// FolderPages maps parents to the pages of folders returned by ListFolders, in order.
+	FolderPages map[string][][]*crmv2.Folder
+	// ProjectPolicies maps project IDs to their policies, read and written by the project policy
+	// calls instead of GetPolicyResponse when set. Projects missing from it are not found.
+	ProjectPolicies map[string]*crm.Policy
+	// GrantedPermissions maps resource names to the permissions TestPermissions finds held on them.
+	GrantedPermissions map[string][]string
+	// StubbedLiens maps parents, such as "projects/123", to the liens returned by ListLiens.
+	StubbedLiens map[string][]*crm.Lien
+	// CreatedLiens are the liens created, in order.
+	CreatedLiens []*crm.Lien
+	// DeletedLiens are the names of the liens removed, in order.
+	DeletedLiens []string
+	// DeletedProjects are the IDs of the projects deleted, in order.
+	DeletedProjects []string
+	mu              sync.Mutex
+}
+
+// page returns the index of the page of the token and the token of the next page of n pages.
+func page(token string, n int) (int, string) {
+	i := 0
+	if token != "" {
+		fmt.Sscanf(token, "page-%d", &i)
+	}
+	if i+1 < n {
+		return i, fmt.Sprintf("page-%d", i+1)
+	}
+	return i, ""
+}
+
+// conflict rejects the policy write while PolicyConflicts remain.
+func (s *ResourceManagerStub) conflict() error {
+	if s.PolicyConflicts == 0 {
+		return nil
+	}
+	s.PolicyConflicts--
+	return status.Error(codes.Aborted, "policy changed since it was read")
 }
 
 // GetPolicyProject is a stub of Cloud Resource Manager's GetIamPolicy.
 func (s *ResourceManagerStub) GetPolicyProject(ctx context.Context, projectID string) (*crm.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ProjectPolicies != nil {
+		p, ok := s.ProjectPolicies[projectID]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "project %q not found", projectID)
+		}
+		return p, nil
+	}
 	return s.GetPolicyResponse, nil
 }
 
 // SetPolicyProject is a stub of Cloud Resource Manager's SetIamPolicy.
 func (s *ResourceManagerStub) SetPolicyProject(ctx context.Context, projectID string, p *crm.Policy) (*crm.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.conflict(); err != nil {
+		return nil, err
+	}
+	if s.ProjectPolicies != nil {
+		s.ProjectPolicies[projectID] = p
+	}
 	s.SavedSetPolicy = p
 	return s.SavedSetPolicy, nil
 }
 
 // SetPolicyProjectWithMask is a stub of Cloud Resource Manager's SetIamPolicy.
 func (s *ResourceManagerStub) SetPolicyProjectWithMask(ctx context.Context, projectID string, p *crm.Policy, fields ...string) (*crm.Policy, error) {
+	if err := s.conflict(); err != nil {
+		return nil, err
+	}
 	s.SavedSetPolicy = p
 	return s.SavedSetPolicy, nil
 }
 
 // GetAncestry is a stub of Cloud Resource Manager's GetAncestry.
 func (s *ResourceManagerStub) GetAncestry(context.Context, string) (*crm.GetAncestryResponse, error) {
+	s.AncestryCalls++
 	return s.GetAncestryResponse, nil
 }
 
@@ -58,11 +137,108 @@ func (s *ResourceManagerStub) GetPolicyOrganization(ctx context.Context, organiz
 
 // SetPolicyOrganization is a stub of Cloud Resource Manager's SetIamPolicy.
 func (s *ResourceManagerStub) SetPolicyOrganization(ctx context.Context, organizationID string, p *crm.Policy) (*crm.Policy, error) {
+	if err := s.conflict(); err != nil {
+		return nil, err
+	}
 	s.SavedSetPolicy = p
 	return s.SavedSetPolicy, nil
 }
 
 // GetOrganization is a stub of Cloud Resource Manager's GetOrganization.
 func (s *ResourceManagerStub) GetOrganization(ctx context.Context, organizationID string) (*crm.Organization, error) {
+	s.OrganizationCalls++
 	return s.GetOrganizationResponse, nil
 }
+
+// GetFolder is a stub of Cloud Resource Manager's folders.get.
+func (s *ResourceManagerStub) GetFolder(ctx context.Context, name string) (*crmv2.Folder, error) {
+	f, ok := s.StubbedFolders[name]
+	if !ok {
+		return nil, fmt.Errorf("folder %q not found", name)
+	}
+	return f, nil
+}
+
+// GetPolicyFolder is a stub of Cloud Resource Manager's folders.getIamPolicy.
+func (s *ResourceManagerStub) GetPolicyFolder(ctx context.Context, name string) (*crmv2.Policy, error) {
+	if p, ok := s.StubbedFolderPolicies[name]; ok {
+		return p, nil
+	}
+	return &crmv2.Policy{}, nil
+}
+
+// GetProject is a stub of Cloud Resource Manager's projects.get.
+func (s *ResourceManagerStub) GetProject(ctx context.Context, projectID string) (*crm.Project, error) {
+	p, ok := s.StubbedProjects[projectID]
+	if !ok {
+		return nil, &googleapi.Error{Code: http.StatusNotFound}
+	}
+	return p, nil
+}
+
+// UpdateProject is a stub of Cloud Resource Manager's projects.update, replacing the project in
+// StubbedProjects.
+func (s *ResourceManagerStub) UpdateProject(ctx context.Context, projectID string, p *crm.Project) (*crm.Project, error) {
+	if _, ok := s.StubbedProjects[projectID]; !ok {
+		return nil, &googleapi.Error{Code: http.StatusNotFound}
+	}
+	s.StubbedProjects[projectID] = p
+	return p, nil
+}
+
+// DeleteProject is a stub of Cloud Resource Manager's projects.delete.
+func (s *ResourceManagerStub) DeleteProject(ctx context.Context, projectID string) error {
+	s.DeletedProjects = append(s.DeletedProjects, projectID)
+	return nil
+}
+
+// ListLiens is a stub of Cloud Resource Manager's liens.list, returning StubbedLiens.
+func (s *ResourceManagerStub) ListLiens(ctx context.Context, parent string) ([]*crm.Lien, error) {
+	return s.StubbedLiens[parent], nil
+}
+
+// CreateLien is a stub of Cloud Resource Manager's liens.create.
+func (s *ResourceManagerStub) CreateLien(ctx context.Context, lien *crm.Lien) (*crm.Lien, error) {
+	s.CreatedLiens = append(s.CreatedLiens, lien)
+	return lien, nil
+}
+
+// DeleteLien is a stub of Cloud Resource Manager's liens.delete.
+func (s *ResourceManagerStub) DeleteLien(ctx context.Context, name string) error {
+	s.DeletedLiens = append(s.DeletedLiens, name)
+	return nil
+}
+
+// ListProjects is a stub of Cloud Resource Manager's projects.list, returning ProjectPages.
+func (s *ResourceManagerStub) ListProjects(ctx context.Context, filter, pageToken string) (*crm.ListProjectsResponse, error) {
+	if len(s.ProjectPages) == 0 {
+		return &crm.ListProjectsResponse{}, nil
+	}
+	i, next := page(pageToken, len(s.ProjectPages))
+	return &crm.ListProjectsResponse{Projects: s.ProjectPages[i], NextPageToken: next}, nil
+}
+
+// ListFolders is a stub of Cloud Resource Manager's folders.list, returning FolderPages.
+func (s *ResourceManagerStub) ListFolders(ctx context.Context, parent, pageToken string) (*crmv2.ListFoldersResponse, error) {
+	pages := s.FolderPages[parent]
+	if len(pages) == 0 {
+		return &crmv2.ListFoldersResponse{}, nil
+	}
+	i, next := page(pageToken, len(pages))
+	return &crmv2.ListFoldersResponse{Folders: pages[i], NextPageToken: next}, nil
+}
+
+// TestPermissions returns the permissions among those given granted on the resource.
+func (s *ResourceManagerStub) TestPermissions(ctx context.Context, resource string, permissions []string) ([]string, error) {
+	granted := make(map[string]bool)
+	for _, p := range s.GrantedPermissions[resource] {
+		granted[p] = true
+	}
+	var held []string
+	for _, p := range permissions {
+		if granted[p] {
+			held = append(held, p)
+		}
+	}
+	return held, nil
+}
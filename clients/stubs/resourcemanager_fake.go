@@ -0,0 +1,428 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	crmv2 "google.golang.org/api/cloudresourcemanager/v2"
+	"google.golang.org/api/googleapi"
+)
+
+// ResourceManagerFake is an in-memory Cloud Resource Manager holding organizations, folders and
+// projects along with their IAM policies and liens. Policies are versioned by ETag: writes with a
+// stale ETag are rejected with a conflict like the API does.
+type ResourceManagerFake struct {
+	mu             sync.Mutex
+	organizations  map[string]*crm.Organization
+	folders        map[string]*crmv2.Folder
+	projects       map[string]*crm.Project
+	policies       map[string]*crm.Policy
+	folderPolicies map[string]*crmv2.Policy
+	liens          map[string][]*crm.Lien
+	etags          int
+	lienIDs        int
+}
+
+// NewResourceManagerFake returns an empty fake.
+func NewResourceManagerFake() *ResourceManagerFake {
+	return &ResourceManagerFake{
+		organizations:  make(map[string]*crm.Organization),
+		folders:        make(map[string]*crmv2.Folder),
+		projects:       make(map[string]*crm.Project),
+		policies:       make(map[string]*crm.Policy),
+		folderPolicies: make(map[string]*crmv2.Policy),
+		liens:          make(map[string][]*crm.Lien),
+	}
+}
+
+// AddOrganization adds an organization by its numeric ID.
+func (f *ResourceManagerFake) AddOrganization(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := "organizations/" + id
+	f.organizations[name] = &crm.Organization{Name: name, LifecycleState: "ACTIVE"}
+}
+
+// AddFolder adds a folder by its numeric ID under its parent, "organizations/<id>" or
+// "folders/<id>".
+func (f *ResourceManagerFake) AddFolder(id, parent string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := "folders/" + id
+	f.folders[name] = &crmv2.Folder{Name: name, Parent: parent, DisplayName: id, LifecycleState: "ACTIVE"}
+}
+
+// AddProject adds a project under its parent, "organizations/<id>" or "folders/<id>".
+func (f *ResourceManagerFake) AddProject(id string, number int64, parent string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kind, parentID := split(parent)
+	f.projects[id] = &crm.Project{
+		ProjectId:      id,
+		ProjectNumber:  number,
+		Name:           id,
+		LifecycleState: "ACTIVE",
+		Parent:         &crm.ResourceId{Type: kind, Id: parentID},
+	}
+}
+
+// ProjectPolicy returns a copy of the project's policy, nil if it has none.
+func (f *ResourceManagerFake) ProjectPolicy(projectID string) *crm.Policy {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.policies["projects/"+projectID]
+	if !ok {
+		return nil
+	}
+	var c crm.Policy
+	clone(p, &c)
+	return &c
+}
+
+// OrganizationPolicy returns a copy of the organization's policy, nil if it has none.
+func (f *ResourceManagerFake) OrganizationPolicy(organizationID string) *crm.Policy {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.policies[organizationName(organizationID)]
+	if !ok {
+		return nil
+	}
+	var c crm.Policy
+	clone(p, &c)
+	return &c
+}
+
+// SetFolderPolicy sets the policy of a folder, "folders/<id>".
+func (f *ResourceManagerFake) SetFolderPolicy(name string, p *crmv2.Policy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var c crmv2.Policy
+	clone(p, &c)
+	f.folderPolicies[name] = &c
+}
+
+// split splits a resource name such as "folders/123" into its singular type and ID.
+func split(name string) (string, string) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) != 2 {
+		return "", name
+	}
+	return strings.TrimSuffix(parts[0], "s"), parts[1]
+}
+
+// organizationName returns the resource name of the organization given by ID or by name.
+func organizationName(organization string) string {
+	if strings.HasPrefix(organization, "organizations/") {
+		return organization
+	}
+	return "organizations/" + organization
+}
+
+// getPolicy returns a copy of the policy of the resource, an empty one if it has none yet.
+func (f *ResourceManagerFake) getPolicy(name string) *crm.Policy {
+	p, ok := f.policies[name]
+	if !ok {
+		return &crm.Policy{}
+	}
+	var c crm.Policy
+	clone(p, &c)
+	return &c
+}
+
+// setPolicy replaces the fields of the resource's policy, rejecting writes with a stale ETag.
+func (f *ResourceManagerFake) setPolicy(name string, p *crm.Policy, fields ...string) (*crm.Policy, error) {
+	current := f.getPolicy(name)
+	if p.Etag != "" && p.Etag != current.Etag {
+		return nil, &googleapi.Error{Code: http.StatusConflict, Message: fmt.Sprintf("stale etag %q for %q", p.Etag, name)}
+	}
+	if len(fields) == 0 {
+		fields = []string{"bindings", "etag"}
+	}
+	for _, field := range fields {
+		switch field {
+		case "bindings":
+			current.Bindings = p.Bindings
+		case "auditConfigs":
+			current.AuditConfigs = p.AuditConfigs
+		case "version":
+			current.Version = p.Version
+		}
+	}
+	f.etags++
+	current.Etag = fmt.Sprintf("etag-%d", f.etags)
+	var c crm.Policy
+	clone(current, &c)
+	f.policies[name] = &c
+	return current, nil
+}
+
+// GetAncestry returns the project followed by its folders and organization.
+func (f *ResourceManagerFake) GetAncestry(ctx context.Context, projectID string) (*crm.GetAncestryResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.projects[projectID]
+	if !ok {
+		return nil, notFound("project %q not found", projectID)
+	}
+	resp := &crm.GetAncestryResponse{Ancestor: []*crm.Ancestor{
+		{ResourceId: &crm.ResourceId{Type: "project", Id: projectID}},
+	}}
+	parent := p.Parent
+	for parent != nil {
+		resp.Ancestor = append(resp.Ancestor, &crm.Ancestor{ResourceId: &crm.ResourceId{Type: parent.Type, Id: parent.Id}})
+		if parent.Type != "folder" {
+			break
+		}
+		folder, ok := f.folders["folders/"+parent.Id]
+		if !ok {
+			break
+		}
+		kind, id := split(folder.Parent)
+		parent = &crm.ResourceId{Type: kind, Id: id}
+	}
+	return resp, nil
+}
+
+// GetPolicyProject returns the project's policy.
+func (f *ResourceManagerFake) GetPolicyProject(ctx context.Context, projectID string) (*crm.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.projects[projectID]; !ok {
+		return nil, notFound("project %q not found", projectID)
+	}
+	return f.getPolicy("projects/" + projectID), nil
+}
+
+// SetPolicyProject replaces the project's bindings.
+func (f *ResourceManagerFake) SetPolicyProject(ctx context.Context, projectID string, p *crm.Policy) (*crm.Policy, error) {
+	return f.SetPolicyProjectWithMask(ctx, projectID, p)
+}
+
+// SetPolicyProjectWithMask replaces the fields of the project's policy, its bindings and ETag if no
+// fields are given.
+func (f *ResourceManagerFake) SetPolicyProjectWithMask(ctx context.Context, projectID string, p *crm.Policy, fields ...string) (*crm.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.projects[projectID]; !ok {
+		return nil, notFound("project %q not found", projectID)
+	}
+	return f.setPolicy("projects/"+projectID, p, fields...)
+}
+
+// GetPolicyOrganization returns the organization's policy.
+func (f *ResourceManagerFake) GetPolicyOrganization(ctx context.Context, organization string) (*crm.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := organizationName(organization)
+	if _, ok := f.organizations[name]; !ok {
+		return nil, notFound("organization %q not found", name)
+	}
+	return f.getPolicy(name), nil
+}
+
+// SetPolicyOrganization replaces the organization's bindings.
+func (f *ResourceManagerFake) SetPolicyOrganization(ctx context.Context, organization string, p *crm.Policy) (*crm.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := organizationName(organization)
+	if _, ok := f.organizations[name]; !ok {
+		return nil, notFound("organization %q not found", name)
+	}
+	return f.setPolicy(name, p)
+}
+
+// GetOrganization returns the organization by name.
+func (f *ResourceManagerFake) GetOrganization(ctx context.Context, name string) (*crm.Organization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, ok := f.organizations[organizationName(name)]
+	if !ok {
+		return nil, notFound("organization %q not found", name)
+	}
+	var c crm.Organization
+	clone(o, &c)
+	return &c, nil
+}
+
+// GetFolder returns the folder by name.
+func (f *ResourceManagerFake) GetFolder(ctx context.Context, name string) (*crmv2.Folder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	folder, ok := f.folders[name]
+	if !ok {
+		return nil, notFound("folder %q not found", name)
+	}
+	var c crmv2.Folder
+	clone(folder, &c)
+	return &c, nil
+}
+
+// GetPolicyFolder returns the folder's policy.
+func (f *ResourceManagerFake) GetPolicyFolder(ctx context.Context, name string) (*crmv2.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.folders[name]; !ok {
+		return nil, notFound("folder %q not found", name)
+	}
+	var c crmv2.Policy
+	if p, ok := f.folderPolicies[name]; ok {
+		clone(p, &c)
+	}
+	return &c, nil
+}
+
+// ListProjects returns the projects matching the filter in a single page. Only the "parent.type",
+// "parent.id" and "lifecycleState" terms of the filter are supported.
+func (f *ResourceManagerFake) ListProjects(ctx context.Context, filter, pageToken string) (*crm.ListProjectsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	terms := make(map[string]string)
+	for _, t := range strings.Fields(filter) {
+		kv := strings.SplitN(t, ":", 2)
+		if len(kv) != 2 {
+			return nil, &googleapi.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("unsupported filter %q", filter)}
+		}
+		terms[kv[0]] = kv[1]
+	}
+	resp := &crm.ListProjectsResponse{}
+	for _, p := range f.projects {
+		if t, ok := terms["parent.type"]; ok && p.Parent.Type != t {
+			continue
+		}
+		if id, ok := terms["parent.id"]; ok && p.Parent.Id != id {
+			continue
+		}
+		if s, ok := terms["lifecycleState"]; ok && p.LifecycleState != s {
+			continue
+		}
+		var c crm.Project
+		clone(p, &c)
+		resp.Projects = append(resp.Projects, &c)
+	}
+	sort.Slice(resp.Projects, func(i, j int) bool { return resp.Projects[i].ProjectId < resp.Projects[j].ProjectId })
+	return resp, nil
+}
+
+// ListFolders returns the folders directly under the parent in a single page.
+func (f *ResourceManagerFake) ListFolders(ctx context.Context, parent, pageToken string) (*crmv2.ListFoldersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &crmv2.ListFoldersResponse{}
+	for _, folder := range f.folders {
+		if folder.Parent != parent {
+			continue
+		}
+		var c crmv2.Folder
+		clone(folder, &c)
+		resp.Folders = append(resp.Folders, &c)
+	}
+	sort.Slice(resp.Folders, func(i, j int) bool { return resp.Folders[i].Name < resp.Folders[j].Name })
+	return resp, nil
+}
+
+// GetProject returns the project by ID.
+func (f *ResourceManagerFake) GetProject(ctx context.Context, projectID string) (*crm.Project, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.projects[projectID]
+	if !ok {
+		return nil, notFound("project %q not found", projectID)
+	}
+	var c crm.Project
+	clone(p, &c)
+	return &c, nil
+}
+
+// UpdateProject replaces the project's name and labels.
+func (f *ResourceManagerFake) UpdateProject(ctx context.Context, projectID string, p *crm.Project) (*crm.Project, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.projects[projectID]
+	if !ok {
+		return nil, notFound("project %q not found", projectID)
+	}
+	var update crm.Project
+	clone(p, &update)
+	current.Name = update.Name
+	current.Labels = update.Labels
+	var c crm.Project
+	clone(current, &c)
+	return &c, nil
+}
+
+// DeleteProject marks the project as pending deletion, failing like the API does if it holds any
+// lien.
+func (f *ResourceManagerFake) DeleteProject(ctx context.Context, projectID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.projects[projectID]
+	if !ok {
+		return notFound("project %q not found", projectID)
+	}
+	if n := len(f.liens[fmt.Sprintf("projects/%d", p.ProjectNumber)]); n > 0 {
+		return &googleapi.Error{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("project %q holds %d liens", projectID, n)}
+	}
+	p.LifecycleState = "DELETE_REQUESTED"
+	return nil
+}
+
+// ListLiens returns the liens of the parent.
+func (f *ResourceManagerFake) ListLiens(ctx context.Context, parent string) ([]*crm.Lien, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var liens []*crm.Lien
+	for _, l := range f.liens[parent] {
+		var c crm.Lien
+		clone(l, &c)
+		liens = append(liens, &c)
+	}
+	return liens, nil
+}
+
+// CreateLien places the lien on its parent, naming it.
+func (f *ResourceManagerFake) CreateLien(ctx context.Context, lien *crm.Lien) (*crm.Lien, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var c crm.Lien
+	clone(lien, &c)
+	f.lienIDs++
+	c.Name = fmt.Sprintf("liens/%d", f.lienIDs)
+	f.liens[c.Parent] = append(f.liens[c.Parent], &c)
+	var r crm.Lien
+	clone(&c, &r)
+	return &r, nil
+}
+
+// DeleteLien removes the lien by name.
+func (f *ResourceManagerFake) DeleteLien(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for parent, liens := range f.liens {
+		for i, l := range liens {
+			if l.Name == name {
+				f.liens[parent] = append(liens[:i:i], liens[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return notFound("lien %q not found", name)
+}
@@ -0,0 +1,87 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// SecretManagerStub provides a stub for the Secret Manager client.
+type SecretManagerStub struct {
+	// StubbedSecrets maps secret version names to their payloads.
+	StubbedSecrets map[string][]byte
+	// Accessed counts the calls to AccessSecretVersion.
+	Accessed int
+	// StubbedPolicies maps secret names to their IAM policies.
+	StubbedPolicies map[string]*secretmanager.Policy
+	SavedPolicy     *secretmanager.Policy
+	SavedSecretName string
+	// StubbedVersions maps secret names to their versions.
+	StubbedVersions map[string][]*secretmanager.SecretVersion
+	// AddedPayload is the payload of the last version added.
+	AddedPayload []byte
+	// StubbedAddErr is returned by AddSecretVersion if set.
+	StubbedAddErr error
+	// DisabledVersions are the names of the versions disabled.
+	DisabledVersions []string
+}
+
+// AccessSecretVersion returns the stubbed payload of the secret version.
+func (s *SecretManagerStub) AccessSecretVersion(ctx context.Context, name string) ([]byte, error) {
+	s.Accessed++
+	b, ok := s.StubbedSecrets[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %q not found", name)
+	}
+	return b, nil
+}
+
+// GetIamPolicy is a stub of Secret Manager's GetIamPolicy.
+func (s *SecretManagerStub) GetIamPolicy(ctx context.Context, name string) (*secretmanager.Policy, error) {
+	if p, ok := s.StubbedPolicies[name]; ok {
+		return p, nil
+	}
+	return &secretmanager.Policy{}, nil
+}
+
+// SetIamPolicy is a stub of Secret Manager's SetIamPolicy.
+func (s *SecretManagerStub) SetIamPolicy(ctx context.Context, name string, policy *secretmanager.Policy) (*secretmanager.Policy, error) {
+	s.SavedSecretName = name
+	s.SavedPolicy = policy
+	return policy, nil
+}
+
+// ListSecretVersions is a stub of Secret Manager's ListSecretVersions.
+func (s *SecretManagerStub) ListSecretVersions(ctx context.Context, name string) ([]*secretmanager.SecretVersion, error) {
+	return s.StubbedVersions[name], nil
+}
+
+// AddSecretVersion is a stub of Secret Manager's AddSecretVersion.
+func (s *SecretManagerStub) AddSecretVersion(ctx context.Context, name string, payload []byte) (*secretmanager.SecretVersion, error) {
+	if s.StubbedAddErr != nil {
+		return nil, s.StubbedAddErr
+	}
+	s.AddedPayload = payload
+	return &secretmanager.SecretVersion{Name: name + "/versions/new", State: "ENABLED"}, nil
+}
+
+// DisableSecretVersion is a stub of Secret Manager's DisableSecretVersion.
+func (s *SecretManagerStub) DisableSecretVersion(ctx context.Context, name string) (*secretmanager.SecretVersion, error) {
+	s.DisabledVersions = append(s.DisabledVersions, name)
+	return &secretmanager.SecretVersion{Name: name, State: "DISABLED"}, nil
+}
@@ -23,9 +23,12 @@ import (
 type SendGridStub struct {
 	StubbedSend    *rest.Response
 	StubbedSendErr error
+	// Sent holds the emails sent.
+	Sent []*mail.SGMailV3
 }
 
 // Send to send email
 func (e *SendGridStub) Send(mail *mail.SGMailV3) (*rest.Response, error) {
+	e.Sent = append(e.Sent, mail)
 	return e.StubbedSend, e.StubbedSendErr
 }
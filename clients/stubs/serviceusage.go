@@ -0,0 +1,82 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+	serviceusage "google.golang.org/api/serviceusage/v1"
+)
+
+// ServiceUsageStub provides a stub for the Service Usage client.
+type ServiceUsageStub struct {
+	// StubbedEnabledServices maps project IDs to the APIs enabled in them.
+	StubbedEnabledServices map[string][]string
+	// StubbedDependents maps APIs to the APIs depending on them, which DisableService fails to
+	// disable them without while they are enabled.
+	StubbedDependents map[string][]string
+	// DisabledServices are the APIs disabled, as "<project>/<api>".
+	DisabledServices []string
+	// DisableDependents is set if any API was disabled along with its dependents.
+	DisableDependents bool
+}
+
+// EnabledServices returns the stubbed APIs enabled in the project, those disabled since excluded.
+func (s *ServiceUsageStub) EnabledServices(ctx context.Context, projectID string) ([]string, error) {
+	var l []string
+	for _, api := range s.StubbedEnabledServices[projectID] {
+		if !s.disabled(projectID, api) {
+			l = append(l, api)
+		}
+	}
+	return l, nil
+}
+
+// DisableService is a stub of Service Usage's DisableService.
+func (s *ServiceUsageStub) DisableService(ctx context.Context, projectID, api string, dependents bool) (*serviceusage.Operation, error) {
+	var active []string
+	for _, d := range s.StubbedDependents[api] {
+		if !s.disabled(projectID, d) {
+			active = append(active, d)
+		}
+	}
+	if len(active) > 0 && !dependents {
+		return nil, &googleapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("The service %s is depended on by the following active service(s): %s; Please specify disable_dependency_services=true if you want to proceed with disabling all services.", api, strings.Join(active, ",")),
+		}
+	}
+	s.DisabledServices = append(s.DisabledServices, projectID+"/"+api)
+	s.DisableDependents = s.DisableDependents || dependents
+	return &serviceusage.Operation{Name: "operations/disable", Done: true}, nil
+}
+
+// WaitServiceUsage is a stub of waiting for a Service Usage operation.
+func (s *ServiceUsageStub) WaitServiceUsage(ctx context.Context, op *serviceusage.Operation) error {
+	return nil
+}
+
+func (s *ServiceUsageStub) disabled(projectID, api string) bool {
+	for _, d := range s.DisabledServices {
+		if d == projectID+"/"+api {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+)
+
+// SNSStub provides a stub for the SNS client.
+type SNSStub struct {
+	// Responses holds the bodies returned keyed by URL.
+	Responses map[string][]byte
+	// Requested holds all requested URLs.
+	Requested []string
+}
+
+// Get returns the stubbed body of the given URL.
+func (s *SNSStub) Get(ctx context.Context, url string) ([]byte, error) {
+	s.Requested = append(s.Requested, url)
+	b, ok := s.Responses[url]
+	if !ok {
+		return nil, fmt.Errorf("no response stubbed for %q", url)
+	}
+	return b, nil
+}
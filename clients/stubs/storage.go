@@ -16,8 +16,14 @@ package stubs
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
 
 	"cloud.google.com/go/iam"
+	"google.golang.org/api/googleapi"
 )
 
 // StorageStub provides a stub for the Storage client.
@@ -25,10 +31,43 @@ type StorageStub struct {
 	BucketPolicyResponse  *iam.Policy
 	RemoveBucketPolicy    *iam.Policy
 	EnabledPolicyOnBucket string
+	// BucketPolicyOnlyEnabled is whether the bucket only policy is already enabled.
+	BucketPolicyOnlyEnabled bool
+	// StubbedObjects holds the content of objects keyed by name.
+	StubbedObjects map[string][]byte
+	// PolicyConflicts is the number of bucket policy writes rejected as having a stale ETag.
+	PolicyConflicts int
+	// StubbedBucketLabels holds the labels of the bucket.
+	StubbedBucketLabels map[string]string
+	// SavedBucketLabels holds the labels set on the bucket.
+	SavedBucketLabels map[string]string
+	// ObjectACLPages holds the pages of object ACLs listed by EachObjectACLPage, the role of each
+	// entity by object name.
+	ObjectACLPages []map[string]map[string]string
+	// DeletedObjectACLs holds the ACL entries deleted, as "<object>/<entity>".
+	DeletedObjectACLs []string
+
+	// mu guards DeletedObjectACLs, written concurrently for the objects of a page.
+	mu sync.Mutex
+}
+
+// BucketLabels returns the labels of the bucket.
+func (s *StorageStub) BucketLabels(ctx context.Context, bucketName string) (map[string]string, error) {
+	return s.StubbedBucketLabels, nil
+}
+
+// SetBucketLabels sets labels on the bucket.
+func (s *StorageStub) SetBucketLabels(ctx context.Context, bucketName string, labels map[string]string) error {
+	s.SavedBucketLabels = labels
+	return nil
 }
 
 // SetBucketPolicy set a policy for the given bucket.
 func (s *StorageStub) SetBucketPolicy(ctx context.Context, bucketName string, p *iam.Policy) error {
+	if s.PolicyConflicts > 0 {
+		s.PolicyConflicts--
+		return &googleapi.Error{Code: http.StatusPreconditionFailed}
+	}
 	s.RemoveBucketPolicy = p
 	return nil
 }
@@ -38,8 +77,61 @@ func (s *StorageStub) BucketPolicy(ctx context.Context, bucketName string) (*iam
 	return s.BucketPolicyResponse, nil
 }
 
+// BucketPolicyOnly returns whether the bucket only policy is already enabled.
+func (s *StorageStub) BucketPolicyOnly(ctx context.Context, bucketName string) (bool, error) {
+	return s.BucketPolicyOnlyEnabled, nil
+}
+
 // EnableBucketOnlyPolicy saves the bucket that receives the request for enabling bucket only policy.
 func (s *StorageStub) EnableBucketOnlyPolicy(ctx context.Context, bucketName string) error {
 	s.EnabledPolicyOnBucket = bucketName
 	return nil
 }
+
+// Objects lists the names of the stubbed objects starting with the prefix.
+func (s *StorageStub) Objects(ctx context.Context, bucketName, prefix string) ([]string, error) {
+	var names []string
+	for name := range s.StubbedObjects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Object returns the content of a stubbed object.
+func (s *StorageStub) Object(ctx context.Context, bucketName, name string) ([]byte, error) {
+	b, ok := s.StubbedObjects[name]
+	if !ok {
+		return nil, fmt.Errorf("object %q not found", name)
+	}
+	return b, nil
+}
+
+// WriteObject stores the content of a stubbed object.
+func (s *StorageStub) WriteObject(ctx context.Context, bucketName, name string, b []byte) error {
+	if s.StubbedObjects == nil {
+		s.StubbedObjects = make(map[string][]byte)
+	}
+	s.StubbedObjects[name] = b
+	return nil
+}
+
+// EachObjectACLPage calls fn with each of the stubbed pages of object ACLs.
+func (s *StorageStub) EachObjectACLPage(ctx context.Context, bucketName string, fn func(map[string]map[string]string) error) error {
+	for _, page := range s.ObjectACLPages {
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteObjectACL records the deleted ACL entry.
+func (s *StorageStub) DeleteObjectACL(ctx context.Context, bucketName, object, entity string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.DeletedObjectACLs = append(s.DeletedObjectACLs, object+"/"+entity)
+	return nil
+}
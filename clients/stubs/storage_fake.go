@@ -0,0 +1,295 @@
+package stubs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/iam"
+	"google.golang.org/api/googleapi"
+	iampb "google.golang.org/genproto/googleapis/iam/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// StorageFake is an in-memory Cloud Storage holding buckets with their IAM policy, labels and
+// objects. Policy writes with a stale ETag are rejected like the API does.
+type StorageFake struct {
+	mu      sync.Mutex
+	buckets map[string]*fakeBucket
+	etags   int
+}
+
+// fakeBucket is the state of a bucket of the fake.
+type fakeBucket struct {
+	policy           *iampb.Policy
+	bucketPolicyOnly bool
+	labels           map[string]string
+	objects          map[string][]byte
+	objectACLs       map[string]map[string]string
+}
+
+// NewStorageFake returns an empty fake.
+func NewStorageFake() *StorageFake {
+	return &StorageFake{buckets: make(map[string]*fakeBucket)}
+}
+
+// AddBucket adds a bucket granting each role in bindings to its members.
+func (s *StorageFake) AddBucket(name string, bindings map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := &iampb.Policy{Etag: s.etag()}
+	roles := make([]string, 0, len(bindings))
+	for role := range bindings {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		p.Bindings = append(p.Bindings, &iampb.Binding{Role: role, Members: append([]string(nil), bindings[role]...)})
+	}
+	s.buckets[name] = &fakeBucket{policy: p, labels: make(map[string]string), objects: make(map[string][]byte), objectACLs: make(map[string]map[string]string)}
+}
+
+// Members returns the members granted the role on the bucket.
+func (s *StorageFake) Members(bucketName, role string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	return (&iam.Policy{InternalProto: b.policy}).Members(iam.RoleName(role))
+}
+
+// SetObjectACL replaces the ACL of the bucket's object, the role of each entity.
+func (s *StorageFake) SetObjectACL(bucketName, object string, acl map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.buckets[bucketName]; ok {
+		b.objectACLs[object] = copyACL(acl)
+	}
+}
+
+// ObjectACL returns the ACL of the bucket's object.
+func (s *StorageFake) ObjectACL(bucketName, object string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	return copyACL(b.objectACLs[object])
+}
+
+// copyACL returns a copy of the ACL.
+func copyACL(acl map[string]string) map[string]string {
+	c := make(map[string]string, len(acl))
+	for k, v := range acl {
+		c[k] = v
+	}
+	return c
+}
+
+// BucketPolicyOnlyEnabled returns whether the bucket only policy is enabled on the bucket.
+func (s *StorageFake) BucketPolicyOnlyEnabled(bucketName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	return ok && b.bucketPolicyOnly
+}
+
+// etag returns a new ETag.
+func (s *StorageFake) etag() []byte {
+	s.etags++
+	return []byte(fmt.Sprintf("etag-%d", s.etags))
+}
+
+// bucket returns the named bucket.
+func (s *StorageFake) bucket(name string) (*fakeBucket, error) {
+	b, ok := s.buckets[name]
+	if !ok {
+		return nil, notFound("bucket %q not found", name)
+	}
+	return b, nil
+}
+
+// SetBucketPolicy replaces the bucket's policy.
+func (s *StorageFake) SetBucketPolicy(ctx context.Context, bucketName string, p *iam.Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if etag := p.InternalProto.GetEtag(); len(etag) > 0 && string(etag) != string(b.policy.Etag) {
+		return &googleapi.Error{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("stale etag for %q", bucketName)}
+	}
+	policy := proto.Clone(p.InternalProto).(*iampb.Policy)
+	policy.Etag = s.etag()
+	b.policy = policy
+	return nil
+}
+
+// BucketPolicy returns the bucket's policy.
+func (s *StorageFake) BucketPolicy(ctx context.Context, bucketName string) (*iam.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return &iam.Policy{InternalProto: proto.Clone(b.policy).(*iampb.Policy)}, nil
+}
+
+// EnableBucketOnlyPolicy enables the bucket only policy on the bucket.
+func (s *StorageFake) EnableBucketOnlyPolicy(ctx context.Context, bucketName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	b.bucketPolicyOnly = true
+	return nil
+}
+
+// BucketPolicyOnly returns whether the bucket only policy is enabled on the bucket.
+func (s *StorageFake) BucketPolicyOnly(ctx context.Context, bucketName string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return false, err
+	}
+	return b.bucketPolicyOnly, nil
+}
+
+// BucketLabels returns the bucket's labels.
+func (s *StorageFake) BucketLabels(ctx context.Context, bucketName string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string)
+	for k, v := range b.labels {
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// SetBucketLabels replaces the bucket's labels.
+func (s *StorageFake) SetBucketLabels(ctx context.Context, bucketName string, labels map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	b.labels = make(map[string]string)
+	for k, v := range labels {
+		b.labels[k] = v
+	}
+	return nil
+}
+
+// Objects lists the names of the bucket's objects starting with the prefix.
+func (s *StorageFake) Objects(ctx context.Context, bucketName, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range b.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Object returns the content of an object.
+func (s *StorageFake) Object(ctx context.Context, bucketName, name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := b.objects[name]
+	if !ok {
+		return nil, notFound("object %q not found in %q", name, bucketName)
+	}
+	return append([]byte(nil), o...), nil
+}
+
+// WriteObject replaces the content of an object.
+func (s *StorageFake) WriteObject(ctx context.Context, bucketName, name string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	b.objects[name] = append([]byte(nil), content...)
+	return nil
+}
+
+// EachObjectACLPage calls fn with the ACLs of the bucket's objects in a single page.
+func (s *StorageFake) EachObjectACLPage(ctx context.Context, bucketName string, fn func(map[string]map[string]string) error) error {
+	s.mu.Lock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if b.bucketPolicyOnly {
+		s.mu.Unlock()
+		return &googleapi.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("uniform bucket-level access is enabled on %q", bucketName)}
+	}
+	page := make(map[string]map[string]string, len(b.objectACLs))
+	for name, acl := range b.objectACLs {
+		page[name] = copyACL(acl)
+	}
+	s.mu.Unlock()
+	return fn(page)
+}
+
+// DeleteObjectACL removes the entity from the ACL of the bucket's object.
+func (s *StorageFake) DeleteObjectACL(ctx context.Context, bucketName, object, entity string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	acl, ok := b.objectACLs[object]
+	if !ok {
+		return notFound("object %q not found", object)
+	}
+	if _, ok := acl[entity]; !ok {
+		return notFound("entity %q not in the ACL of %q", entity, object)
+	}
+	delete(acl, entity)
+	return nil
+}
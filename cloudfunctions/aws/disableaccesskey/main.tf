@@ -0,0 +1,47 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "disable-access-key" {
+  name                  = "DisableAccessKey"
+  description           = "Disables AWS IAM access keys."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "DisableAccessKey"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-disable-access-key"
+  }
+  environment_variables = {
+    GCP_PROJECT                 = var.setup.automation-project
+    AWS_ACCESS_KEY_ID           = var.aws-access-key-id
+    AWS_SECRET_ACCESS_KEY       = var.aws-secret-access-key
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "aws_disable_access_key", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_aws_disable_access_key"
+  }
+}
+
+# PubSub topic to trigger this automation.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-disable-access-key"
+  project = var.setup.automation-project
+}
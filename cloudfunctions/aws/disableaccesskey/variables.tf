@@ -0,0 +1,11 @@
+variable "setup" {}
+
+variable "aws-access-key-id" {
+  type        = string
+  description = "Access key ID of the AWS IAM user the automation acts as."
+}
+
+variable "aws-secret-access-key" {
+  type        = string
+  description = "Secret access key of the AWS IAM user the automation acts as."
+}
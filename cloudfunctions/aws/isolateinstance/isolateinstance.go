@@ -0,0 +1,60 @@
+package isolateinstance
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "aws_isolate_instance",
+		EntryPoint: "IsolateEC2Instance",
+		Topic:      "threat-findings-isolate-ec2-instance",
+		Services:   []string{"AWS"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	AccountID, Region, VpcID, InstanceID string
+	DryRun                               bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	AWS    *services.AWS
+	Logger *services.Logger
+}
+
+// Execute isolates the EC2 instance by moving it into a security group allowing no traffic.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.VpcID == "" || values.InstanceID == "" {
+		return fmt.Errorf("vpc id and instance id are required")
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have isolated instance %q in account %q", values.InstanceID, values.AccountID)
+		return nil
+	}
+	if err := services.AWS.IsolateInstance(ctx, values.VpcID, values.InstanceID); err != nil {
+		return err
+	}
+	services.Logger.Info("isolated instance %q in account %q", values.InstanceID, values.AccountID)
+	return nil
+}
@@ -0,0 +1,68 @@
+package isolateinstance
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestIsolateInstance(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name           string
+		existingGroup  string
+		expectedGroups map[string][]string
+		expectedNew    []string
+	}{
+		{
+			name:           "create isolation group",
+			expectedGroups: map[string][]string{"i-0123456789abcdef0": {"sg-sra-isolation"}},
+			expectedNew:    []string{"sra-isolation"},
+		},
+		{
+			name:           "existing isolation group",
+			existingGroup:  "sg-0abc",
+			expectedGroups: map[string][]string{"i-0123456789abcdef0": {"sg-0abc"}},
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			awsStub := &stubs.AWSStub{StubbedSecurityGroupID: tt.existingGroup}
+			values := &Values{
+				AccountID:  "123456789012",
+				Region:     "us-east-1",
+				VpcID:      "vpc-6d1f5b05",
+				InstanceID: "i-0123456789abcdef0",
+			}
+			if err := Execute(ctx, values, &Services{
+				AWS:    services.NewAWS(awsStub),
+				Logger: services.NewLogger(&stubs.LoggerStub{}),
+			}); err != nil {
+				t.Errorf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expectedGroups, awsStub.InstanceSecurityGroups); diff != "" {
+				t.Errorf("%s failed, security groups difference: %+v", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.expectedNew, awsStub.CreatedSecurityGroups); diff != "" {
+				t.Errorf("%s failed, created groups difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "guardduty-bridge" {
+  name                  = "GuardDutyBridge"
+  description           = "Forwards AWS GuardDuty findings delivered by Amazon SNS to the findings topic."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "GuardDutyBridge"
+  service_account_email = var.setup.automation-service-account
+  trigger_http          = true
+
+  environment_variables = {
+    GCP_PROJECT    = var.setup.automation-project
+    FINDINGS_TOPIC = var.setup.findings-topic-name
+    SNS_TOPIC_ARNS = join(",", var.sns-topic-arns)
+  }
+}
+
+# Amazon SNS does not authenticate to Google, messages are verified by their SNS signature instead.
+resource "google_cloudfunctions_function_iam_member" "invoker" {
+  project        = var.setup.automation-project
+  region         = var.setup.region
+  cloud_function = google_cloudfunctions_function.guardduty-bridge.name
+  role           = "roles/cloudfunctions.invoker"
+  member         = "allUsers"
+}
+
+# Required to publish findings to the findings topic.
+resource "google_project_iam_member" "roles-pubsub-publisher" {
+  project = var.setup.automation-project
+  role    = "roles/pubsub.publisher"
+  member  = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+output "url" {
+  value = google_cloudfunctions_function.guardduty-bridge.https_trigger_url
+}
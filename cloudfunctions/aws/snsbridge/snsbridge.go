@@ -0,0 +1,75 @@
+package snsbridge
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// Topic is the Pub/Sub topic notifications are forwarded to.
+	Topic string
+	// TopicARNs lists the SNS topics messages are accepted from.
+	TopicARNs []string
+	Message   *services.SNSMessage
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	SNS    *services.SNS
+	PubSub *services.PubSub
+	Logger *services.Logger
+}
+
+// Execute verifies the SNS message and forwards notifications to the findings topic.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	m := values.Message
+	if !allowed(m.TopicArn, values.TopicARNs) {
+		return fmt.Errorf("sns topic %q is not allowed", m.TopicArn)
+	}
+	if err := services.SNS.Verify(ctx, m); err != nil {
+		return errors.Wrapf(err, "failed to verify message %q", m.MessageID)
+	}
+	switch m.Type {
+	case "SubscriptionConfirmation":
+		if err := services.SNS.ConfirmSubscription(ctx, m); err != nil {
+			return err
+		}
+		services.Logger.Info("confirmed subscription to %q", m.TopicArn)
+	case "Notification":
+		if _, err := services.PubSub.Publish(ctx, values.Topic, &pubsub.Message{Data: []byte(m.Message)}); err != nil {
+			return errors.Wrapf(err, "failed to publish message %q", m.MessageID)
+		}
+		services.Logger.Info("forwarded message %q from %q", m.MessageID, m.TopicArn)
+	default:
+		services.Logger.Info("ignored %q message from %q", m.Type, m.TopicArn)
+	}
+	return nil
+}
+
+func allowed(arn string, arns []string) bool {
+	for _, a := range arns {
+		if a == arn {
+			return true
+		}
+	}
+	return false
+}
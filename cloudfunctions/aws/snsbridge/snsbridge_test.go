@@ -0,0 +1,140 @@
+package snsbridge
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+const (
+	certURL      = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-0123456789.pem"
+	subscribeURL = "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&Token=2336412f37"
+	topicARN     = "arn:aws:sns:us-east-1:123456789012:guardduty"
+)
+
+// sign signs the notification with a new key and returns the PEM encoded certificate of the key.
+func sign(t *testing.T, m *services.SNSMessage) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %q", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %q", err)
+	}
+	s := "Message\n" + m.Message + "\nMessageId\n" + m.MessageID + "\n"
+	if m.Type == "SubscriptionConfirmation" {
+		s += "SubscribeURL\n" + m.SubscribeURL + "\nTimestamp\n" + m.Timestamp + "\nToken\n" + m.Token + "\n"
+	} else {
+		s += "Timestamp\n" + m.Timestamp + "\n"
+	}
+	s += "TopicArn\n" + m.TopicArn + "\nType\n" + m.Type + "\n"
+	digest := sha256.Sum256([]byte(s))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign message: %q", err)
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(sig)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestSNSBridge(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name              string
+		messageType       string
+		topicARNs         []string
+		expectError       bool
+		expectedPublished []byte
+		expectedRequested []string
+	}{
+		{
+			name:              "forward notification",
+			messageType:       "Notification",
+			topicARNs:         []string{topicARN},
+			expectedPublished: []byte(`{"source": "aws.guardduty"}`),
+			expectedRequested: []string{certURL},
+		},
+		{
+			name:              "confirm subscription",
+			messageType:       "SubscriptionConfirmation",
+			topicARNs:         []string{topicARN},
+			expectedRequested: []string{certURL, subscribeURL},
+		},
+		{
+			name:        "topic not allowed",
+			messageType: "Notification",
+			topicARNs:   []string{"arn:aws:sns:us-east-1:123456789012:other"},
+			expectError: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &services.SNSMessage{
+				Type:             tt.messageType,
+				MessageID:        "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+				TopicArn:         topicARN,
+				Message:          `{"source": "aws.guardduty"}`,
+				Timestamp:        "2021-06-01T15:04:05.000Z",
+				SignatureVersion: "2",
+				SigningCertURL:   certURL,
+			}
+			if tt.messageType == "SubscriptionConfirmation" {
+				m.Token, m.SubscribeURL = "2336412f37", subscribeURL
+			}
+			snsStub := &stubs.SNSStub{Responses: map[string][]byte{certURL: sign(t, m), subscribeURL: {}}}
+			psStub := &stubs.PubSubStub{}
+			err := Execute(ctx, &Values{Topic: "threat-findings", TopicARNs: tt.topicARNs, Message: m}, &Services{
+				SNS:    services.NewSNS(snsStub),
+				PubSub: services.NewPubSub(psStub),
+				Logger: services.NewLogger(&stubs.LoggerStub{}),
+			})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s failed, got error: %v want error: %t", tt.name, err, tt.expectError)
+			}
+			var published []byte
+			if psStub.PublishedMessage != nil {
+				published = psStub.PublishedMessage.Data
+			}
+			if diff := cmp.Diff(tt.expectedPublished, published); diff != "" {
+				t.Errorf("%s failed, published difference: %+v", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.expectedRequested, snsStub.Requested); diff != "" {
+				t.Errorf("%s failed, requested difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
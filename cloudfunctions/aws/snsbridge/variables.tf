@@ -0,0 +1,6 @@
+variable "setup" {}
+
+variable "sns-topic-arns" {
+  type        = list(string)
+  description = "ARNs of the Amazon SNS topics GuardDuty findings are published to."
+}
@@ -17,10 +17,22 @@ package closepublicdataset
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	"github.com/pkg/errors"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "close_public_dataset",
+		EntryPoint:  "ClosePublicDataset",
+		Topic:       "threat-findings-close-public-dataset",
+		Services:    []string{"BigQuery"},
+		Roles:       []string{"roles/viewer", "roles/bigquery.dataOwner"},
+		Permissions: []string{"bigquery.datasets.get", "bigquery.datasets.update"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID string
@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "close-public-dataset" {
     resource   = "threat-findings-close-public-dataset"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "close_public_dataset", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_close_public_dataset"
   }
 }
 
@@ -0,0 +1,67 @@
+package detachbilling
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "detach_billing",
+		EntryPoint:  "DetachBilling",
+		Topic:       "threat-findings-detach-billing",
+		Services:    []string{"Billing"},
+		Roles:       []string{"roles/billing.projectManager"},
+		Permissions: []string{"resourcemanager.projects.get", "resourcemanager.projects.deleteBillingAssignment"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	DryRun    bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Billing *services.Billing
+	Logger  *services.Logger
+}
+
+// Execute detaches the billing account from the project, stopping its billable resources. The
+// account detached is logged as a change, so it can be attached again.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	account, err := services.Billing.BillingAccount(ctx, values.ProjectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the billing account of %q", values.ProjectID)
+	}
+	if account == "" {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have detached billing account %q from %q", account, values.ProjectID)
+		return nil
+	}
+	if _, err := services.Billing.DetachBilling(ctx, values.ProjectID); err != nil {
+		return errors.Wrapf(err, "failed to detach billing account %q from %q", account, values.ProjectID)
+	}
+	services.Logger.Info("detached billing account %q from %q", account, values.ProjectID)
+	return nil
+}
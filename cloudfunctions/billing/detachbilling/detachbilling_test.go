@@ -0,0 +1,72 @@
+package detachbilling
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+)
+
+func TestDetachBilling(t *testing.T) {
+	ctx := context.Background()
+	const account = "billingAccounts/012345-567890-ABCDEF"
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		info          *cloudbilling.ProjectBillingInfo
+		detached      bool
+		expectedError error
+	}{
+		{
+			name:     "detach",
+			values:   &Values{ProjectID: "test-project"},
+			info:     &cloudbilling.ProjectBillingInfo{BillingAccountName: account, BillingEnabled: true},
+			detached: true,
+		},
+		{
+			name:          "already detached",
+			values:        &Values{ProjectID: "test-project"},
+			info:          &cloudbilling.ProjectBillingInfo{},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "test-project", DryRun: true},
+			info:   &cloudbilling.ProjectBillingInfo{BillingAccountName: account, BillingEnabled: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.CloudBillingStub{StubbedBillingInfo: map[string]*cloudbilling.ProjectBillingInfo{"test-project": tt.info}}
+			if err := Execute(ctx, tt.values, &Services{
+				Billing: services.NewBilling(stub),
+				Logger:  services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			updated, ok := stub.UpdatedBillingInfo["test-project"]
+			if ok != tt.detached {
+				t.Fatalf("%s detached:%t want:%t", tt.name, ok, tt.detached)
+			}
+			if ok && updated.BillingAccountName != "" {
+				t.Errorf("%s set billing account %q", tt.name, updated.BillingAccountName)
+			}
+		})
+	}
+}
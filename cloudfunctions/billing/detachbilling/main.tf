@@ -0,0 +1,61 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "detach-billing" {
+  name                  = "DetachBilling"
+  description           = "Detaches billing accounts from projects in response to findings."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "DetachBilling"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-detach-billing"
+  }
+  environment_variables = {
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "detach_billing", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_detach_billing"
+  }
+}
+
+# PubSub topic to trigger this automation.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-detach-billing"
+  project = var.setup.automation-project
+}
+
+resource "google_project_service" "cloudbilling_api" {
+  project                    = var.setup.automation-project
+  service                    = "cloudbilling.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+# Required to detach the billing accounts of projects within this folder.
+resource "google_folder_iam_member" "roles-billing-project-manager" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/billing.projectManager"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
@@ -0,0 +1,6 @@
+variable "setup" {}
+
+variable "folder-ids" {
+  type        = list(string)
+  description = "Detach the billing accounts of projects if they are within the given folder IDs."
+}
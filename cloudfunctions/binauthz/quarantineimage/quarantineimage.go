@@ -0,0 +1,67 @@
+package quarantineimage
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "quarantine_image",
+		EntryPoint:  "QuarantineImage",
+		Topic:       "threat-findings-quarantine-image",
+		Services:    []string{"ContainerAnalysis"},
+		Roles:       []string{"roles/viewer", "roles/containeranalysis.occurrences.editor"},
+		Permissions: []string{"containeranalysis.occurrences.list", "containeranalysis.occurrences.delete"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	ImageURI  string
+	// AttestorNote is the Container Analysis note of the attestor whose attestations are revoked,
+	// such as "projects/attestor-project/notes/vulnerability-free".
+	AttestorNote string
+	DryRun       bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	ContainerAnalysis *services.ContainerAnalysis
+	Logger            *services.Logger
+}
+
+// Execute quarantines the image by revoking its attestations so Binary Authorization blocks new deployments.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.AttestorNote == "" {
+		return fmt.Errorf("attestor note is required to quarantine %q", values.ImageURI)
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have revoked attestations of %q by %q", values.ImageURI, values.AttestorNote)
+		return nil
+	}
+	revoked, err := services.ContainerAnalysis.RevokeAttestations(ctx, values.ProjectID, values.AttestorNote, values.ImageURI)
+	if err != nil {
+		return err
+	}
+	services.Logger.Info("quarantined image %q, revoked attestations: %q", values.ImageURI, revoked)
+	return nil
+}
@@ -0,0 +1,61 @@
+package quarantineimage
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+)
+
+func TestQuarantineImage(t *testing.T) {
+	ctx := context.Background()
+	const (
+		image = "https://gcr.io/test-project/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+		note  = "projects/test-project/notes/vulnerability-free"
+	)
+	occurrences := map[string]*containeranalysis.Occurrence{
+		"projects/test-project/occurrences/1": {Name: "projects/test-project/occurrences/1", NoteName: note, Resource: &containeranalysis.Resource{Uri: image}},
+		"projects/test-project/occurrences/2": {Name: "projects/test-project/occurrences/2", NoteName: "projects/test-project/notes/built-by-ci", Resource: &containeranalysis.Resource{Uri: image}},
+		"projects/test-project/occurrences/3": {Name: "projects/test-project/occurrences/3", NoteName: note, Resource: &containeranalysis.Resource{Uri: "https://gcr.io/test-project/other@sha256:1"}},
+	}
+	test := []struct {
+		name     string
+		dryRun   bool
+		expected []string
+	}{
+		{name: "revoke attestations", expected: []string{"projects/test-project/occurrences/1"}},
+		{name: "dry run", dryRun: true},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			caStub := &stubs.ContainerAnalysisStub{StubbedOccurrences: occurrences}
+			values := &Values{ProjectID: "test-project", ImageURI: image, AttestorNote: note, DryRun: tt.dryRun}
+			if err := Execute(ctx, values, &Services{
+				ContainerAnalysis: services.NewContainerAnalysis(caStub),
+				Logger:            services.NewLogger(&stubs.LoggerStub{}),
+			}); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expected, caStub.DeletedOccurrences); diff != "" {
+				t.Errorf("%s failed, difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "close-cloud-sql" {
     resource   = "threat-findings-remove-public-sql"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "close_cloud_sql", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_close_cloud_sql"
   }
 }
 
@@ -18,9 +18,21 @@ import (
 	"context"
 	"log"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "close_cloud_sql",
+		EntryPoint:  "CloseCloudSQL",
+		Topic:       "threat-findings-remove-public-sql",
+		Services:    []string{"CloudSQL", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/cloudsql.editor"},
+		Permissions: []string{"cloudsql.instances.get", "cloudsql.instances.update"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID, InstanceName string
@@ -45,7 +57,7 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 	acls := instance.Settings.IpConfiguration.AuthorizedNetworks
 	if !services.CloudSQL.IsPublic(acls) {
 		services.Logger.Info("instance %q does not have public access enabled", values.InstanceName)
-		return nil
+		return registry.ErrAlreadyRemediated
 	}
 	if values.DryRun {
 		services.Logger.Info("dry_run on, would have removed public access from Cloud SQL instance %q in project %q.", values.InstanceName, values.ProjectID)
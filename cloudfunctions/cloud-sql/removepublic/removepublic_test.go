@@ -22,6 +22,7 @@ import (
 	sqladmin "google.golang.org/api/sqladmin/v1beta4"
 
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
@@ -31,6 +32,7 @@ func TestCloseCloudSQL(t *testing.T) {
 		name                    string
 		instanceDetailsResponse *sqladmin.DatabaseInstance
 		expectedRequest         *sqladmin.DatabaseInstance
+		expectedError           error
 	}{
 		{
 			name: "close public ip on sql instance",
@@ -80,6 +82,7 @@ func TestCloseCloudSQL(t *testing.T) {
 				},
 			},
 			expectedRequest: nil,
+			expectedError:   registry.ErrAlreadyRemediated,
 		},
 	}
 	for _, tt := range test {
@@ -94,7 +97,7 @@ func TestCloseCloudSQL(t *testing.T) {
 				CloudSQL: svcs.CloudSQL,
 				Resource: svcs.Resource,
 				Logger:   svcs.Logger,
-			}); err != nil {
+			}); err != tt.expectedError {
 				t.Errorf("%s failed to remove public ip from instance :%q", tt.name, err)
 			}
 
@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "enforce-ssl-cloud-sql" {
     resource   = "threat-findings-require-ssl"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "cloud_sql_require_ssl", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_cloud_sql_require_ssl"
   }
 }
 
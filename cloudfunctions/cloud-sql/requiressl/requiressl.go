@@ -17,9 +17,21 @@ package requiressl
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "cloud_sql_require_ssl",
+		EntryPoint:  "CloudSQLRequireSSL",
+		Topic:       "threat-findings-require-ssl",
+		Services:    []string{"CloudSQL", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/cloudsql.editor"},
+		Permissions: []string{"cloudsql.instances.get", "cloudsql.instances.update"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID, InstanceName string
@@ -39,6 +51,13 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 		services.Logger.Info("dry_run on, enforced ssl on sql instance %q in project %q.", values.InstanceName, values.ProjectID)
 		return nil
 	}
+	instance, err := services.CloudSQL.InstanceDetails(ctx, values.ProjectID, values.InstanceName)
+	if err != nil {
+		return err
+	}
+	if instance.Settings.IpConfiguration.RequireSsl {
+		return registry.ErrAlreadyRemediated
+	}
 	if err := services.CloudSQL.RequireSSL(ctx, values.ProjectID, values.InstanceName); err != nil {
 		return err
 	}
@@ -22,6 +22,7 @@ import (
 	sqladmin "google.golang.org/api/sqladmin/v1beta4"
 
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
@@ -29,7 +30,9 @@ func TestCloudSQLRequireSSL(t *testing.T) {
 	ctx := context.Background()
 	test := []struct {
 		name            string
+		requireSSL      bool
 		expectedRequest *sqladmin.DatabaseInstance
+		expectedError   error
 	}{
 		{
 			name: "enforce ssl on sql instance",
@@ -43,10 +46,25 @@ func TestCloudSQLRequireSSL(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:            "ssl already enforced",
+			requireSSL:      true,
+			expectedRequest: nil,
+			expectedError:   registry.ErrAlreadyRemediated,
+		},
 	}
 	for _, tt := range test {
 		t.Run(tt.name, func(t *testing.T) {
 			svcs, sqlStub := cloudSQLRequireSSL()
+			sqlStub.InstanceDetailsResponse = &sqladmin.DatabaseInstance{
+				Name:    "public-sql-instance",
+				Project: "sha-resources-20191002",
+				Settings: &sqladmin.Settings{
+					IpConfiguration: &sqladmin.IpConfiguration{
+						RequireSsl: tt.requireSSL,
+					},
+				},
+			}
 			values := &Values{
 				ProjectID:    "sha-resources-20191002",
 				InstanceName: "public-sql-instance",
@@ -55,7 +73,7 @@ func TestCloudSQLRequireSSL(t *testing.T) {
 				CloudSQL: svcs.CloudSQL,
 				Resource: svcs.Resource,
 				Logger:   svcs.Logger,
-			}); err != nil {
+			}); err != tt.expectedError {
 				t.Errorf("%s failed to enforce ssl in the instance :%q", tt.name, err)
 			}
 
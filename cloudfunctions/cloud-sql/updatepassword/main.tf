@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "update-password" {
     resource   = "threat-findings-update-password"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "cloud_sql_update_password", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_cloud_sql_update_password"
   }
 }
 
@@ -18,9 +18,21 @@ import (
 	"context"
 	"log"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "cloud_sql_update_password",
+		EntryPoint:  "UpdatePassword",
+		Topic:       "threat-findings-update-password",
+		Services:    []string{"CloudSQL", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/cloudsql.admin"},
+		Permissions: []string{"cloudsql.users.update"},
+	})
+}
+
 // Values contains the values values needed for this function.
 type Values struct {
 	ProjectID, InstanceName, Host, UserName, Password string
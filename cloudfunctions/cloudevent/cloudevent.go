@@ -0,0 +1,121 @@
+// Package cloudevent runs the Pub/Sub entry points on 2nd gen Cloud Functions and Cloud Run.
+//
+// Both deliver Pub/Sub messages through Eventarc as CloudEvents of type
+// google.cloud.pubsub.topic.v1.messagePublished, POSTed over HTTP in binary content mode: the
+// attributes of the event in ce- headers and the published message as the JSON body. Events in
+// structured content mode, with the attributes and the body in one JSON document, are accepted
+// too. The entry points are served as HTTP functions, which the functions framework the Go
+// buildpacks bundle runs without the CloudEvents SDK.
+package cloudevent
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+const (
+	// MessagePublished is the type of the events of messages published to a Pub/Sub topic.
+	MessagePublished = "google.cloud.pubsub.topic.v1.messagePublished"
+	// structuredContentType is the content type of events in structured content mode.
+	structuredContentType = "application/cloudevents+json"
+	// maxBodySize is the largest accepted request body, Pub/Sub messages are at most 10 MB.
+	maxBodySize = 16 << 20
+)
+
+// Handler is a Pub/Sub entry point.
+type Handler func(context.Context, pubsub.Message) error
+
+// structured is an event in structured content mode.
+type structured struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// messagePublished is the data of a MessagePublished event.
+type messagePublished struct {
+	Message struct {
+		ID          string            `json:"messageId"`
+		Data        []byte            `json:"data"`
+		Attributes  map[string]string `json:"attributes"`
+		PublishTime time.Time         `json:"publishTime"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// Message returns the Pub/Sub message delivered by the event in the body, of the given content
+// type and with the ce-type header of binary content mode if set.
+func Message(contentType, eventType string, body []byte) (pubsub.Message, error) {
+	data := body
+	if mt, _, _ := mime.ParseMediaType(contentType); mt == structuredContentType {
+		var e structured
+		if err := json.Unmarshal(body, &e); err != nil {
+			return pubsub.Message{}, fmt.Errorf("failed to read event: %q", err)
+		}
+		eventType, data = e.Type, e.Data
+	}
+	if eventType != MessagePublished {
+		return pubsub.Message{}, fmt.Errorf("unsupported event type %q, want %q", eventType, MessagePublished)
+	}
+	var d messagePublished
+	if err := json.Unmarshal(data, &d); err != nil {
+		return pubsub.Message{}, fmt.Errorf("failed to read message: %q", err)
+	}
+	return pubsub.Message{
+		ID:          d.Message.ID,
+		Data:        d.Message.Data,
+		Attributes:  d.Message.Attributes,
+		PublishTime: d.Message.PublishTime,
+	}, nil
+}
+
+// Serve returns an HTTP function passing the messages of the events it receives to the handler.
+// A failing handler is answered with a 500 so that the event is delivered again, as background
+// functions are retried, while events that cannot be read are answered with a 400 and dropped.
+func Serve(name string, handler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		m, err := Message(r.Header.Get("Content-Type"), r.Header.Get("Ce-Type"), body)
+		if err != nil {
+			log.Printf("rejected event %q for %q: %q", r.Header.Get("Ce-Id"), name, err)
+			http.Error(w, "invalid event", http.StatusBadRequest)
+			return
+		}
+		if err := handler(r.Context(), m); err != nil {
+			log.Printf("entry point %q failed: %q", name, err)
+			http.Error(w, "entry point failed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
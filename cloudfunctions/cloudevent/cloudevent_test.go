@@ -0,0 +1,79 @@
+package cloudevent
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServe(t *testing.T) {
+	// The message data is base64 encoded, {"finding": {"category": "PUBLIC_BUCKET_ACL"}}.
+	data := `{"message": {"messageId": "123", "data": "eyJmaW5kaW5nIjogeyJjYXRlZ29yeSI6ICJQVUJMSUNfQlVDS0VUX0FDTCJ9fQ==", "attributes": {"correlationId": "abc"}}, "subscription": "projects/p/subscriptions/s"}`
+	finding := []byte(`{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`)
+	test := []struct {
+		name               string
+		method             string
+		contentType        string
+		eventType          string
+		body               string
+		fail               bool
+		expectedStatus     int
+		expectedData       []byte
+		expectedAttributes map[string]string
+	}{
+		{name: "binary", method: http.MethodPost, contentType: "application/json", eventType: MessagePublished, body: data, expectedStatus: http.StatusNoContent, expectedData: finding, expectedAttributes: map[string]string{"correlationId": "abc"}},
+		{name: "structured", method: http.MethodPost, contentType: "application/cloudevents+json; charset=utf-8", body: `{"id": "1", "type": "` + MessagePublished + `", "data": ` + data + `}`, expectedStatus: http.StatusNoContent, expectedData: finding, expectedAttributes: map[string]string{"correlationId": "abc"}},
+		{name: "unsupported type", method: http.MethodPost, contentType: "application/json", eventType: "google.cloud.storage.object.v1.finalized", body: data, expectedStatus: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPost, contentType: "application/json", eventType: MessagePublished, body: "{", expectedStatus: http.StatusBadRequest},
+		{name: "failing handler", method: http.MethodPost, contentType: "application/json", eventType: MessagePublished, body: data, fail: true, expectedStatus: http.StatusInternalServerError, expectedData: finding, expectedAttributes: map[string]string{"correlationId": "abc"}},
+		{name: "wrong method", method: http.MethodGet, expectedStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			var got pubsub.Message
+			h := Serve("Router", func(ctx context.Context, m pubsub.Message) error {
+				got = m
+				if tt.fail {
+					return errors.New("failed")
+				}
+				return nil
+			})
+			req := httptest.NewRequest(tt.method, "/", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.eventType != "" {
+				req.Header.Set("Ce-Type", tt.eventType)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("%s failed, got status: %d want: %d", tt.name, rec.Code, tt.expectedStatus)
+			}
+			if diff := cmp.Diff(tt.expectedData, got.Data); diff != "" {
+				t.Errorf("%s failed, data difference: %+v", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.expectedAttributes, got.Attributes); diff != "" {
+				t.Errorf("%s failed, attributes difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "container-analysis-bridge" {
+  name                  = "ContainerAnalysisBridge"
+  description           = "Forwards Container Analysis vulnerability occurrences to the findings topic."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "ContainerAnalysisBridge"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "container-analysis-occurrences-v1beta1"
+  }
+  environment_variables = {
+    GCP_PROJECT    = var.setup.automation-project
+    FINDINGS_TOPIC = var.setup.findings-topic-name
+  }
+
+  # The notification topic is created by Container Analysis once its API is enabled.
+  depends_on = [google_project_service.containeranalysis_api]
+}
+
+# Required to get occurrences of images in projects within this folder.
+resource "google_folder_iam_member" "roles-containeranalysis-occurrences-viewer" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/containeranalysis.occurrences.viewer"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Required to publish findings to the findings topic.
+resource "google_project_iam_member" "roles-pubsub-publisher" {
+  project = var.setup.automation-project
+  role    = "roles/pubsub.publisher"
+  member  = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+resource "google_project_service" "containeranalysis_api" {
+  project                    = var.setup.automation-project
+  service                    = "containeranalysis.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
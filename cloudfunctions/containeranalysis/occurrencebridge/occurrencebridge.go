@@ -0,0 +1,61 @@
+package occurrencebridge
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// Values contains the required values needed for this function.
+//
+// Container Analysis notifications only name the occurrence, the full occurrence is looked up.
+type Values struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Topic is the Pub/Sub topic vulnerability occurrences are forwarded to.
+	Topic string `json:"-"`
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	ContainerAnalysis *services.ContainerAnalysis
+	PubSub            *services.PubSub
+	Logger            *services.Logger
+}
+
+// Execute forwards vulnerability occurrences to the findings topic.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.Kind != "VULNERABILITY" {
+		return nil
+	}
+	o, err := services.ContainerAnalysis.Occurrence(ctx, values.Name)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal occurrence %q", values.Name)
+	}
+	if _, err := services.PubSub.Publish(ctx, values.Topic, &pubsub.Message{Data: b}); err != nil {
+		return errors.Wrapf(err, "failed to publish occurrence %q", values.Name)
+	}
+	services.Logger.Info("forwarded occurrence %q", values.Name)
+	return nil
+}
@@ -0,0 +1,73 @@
+package occurrencebridge
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+)
+
+func TestOccurrenceBridge(t *testing.T) {
+	ctx := context.Background()
+	const name = "projects/test-project/occurrences/7a0e3cd5-97a2-4c4e-8b4d-3f0a1f8f2d11"
+	occurrence := &containeranalysis.Occurrence{
+		Name:     name,
+		Kind:     "VULNERABILITY",
+		NoteName: "projects/goog-vulnz/notes/CVE-2021-3711",
+		Resource: &containeranalysis.Resource{Uri: "https://gcr.io/test-project/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"},
+		Vulnerability: &containeranalysis.GrafeasV1beta1VulnerabilityDetails{
+			Severity:          "CRITICAL",
+			EffectiveSeverity: "CRITICAL",
+		},
+	}
+	want, err := json.Marshal(occurrence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := []struct {
+		name     string
+		kind     string
+		expected []byte
+	}{
+		{name: "forward vulnerability", kind: "VULNERABILITY", expected: want},
+		{name: "ignore other kinds", kind: "DISCOVERY"},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			caStub := &stubs.ContainerAnalysisStub{StubbedOccurrences: map[string]*containeranalysis.Occurrence{name: occurrence}}
+			psStub := &stubs.PubSubStub{}
+			if err := Execute(ctx, &Values{Name: name, Kind: tt.kind, Topic: "threat-findings"}, &Services{
+				ContainerAnalysis: services.NewContainerAnalysis(caStub),
+				PubSub:            services.NewPubSub(psStub),
+				Logger:            services.NewLogger(&stubs.LoggerStub{}),
+			}); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			var got []byte
+			if psStub.PublishedMessage != nil {
+				got = psStub.PublishedMessage.Data
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("%s failed, difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
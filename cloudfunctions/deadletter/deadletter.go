@@ -0,0 +1,107 @@
+// Package deadletter handles the messages automations failed to process after every retry.
+package deadletter
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// Attributes Pub/Sub adds to the messages it forwards to a dead-letter topic.
+const (
+	subscriptionAttribute     = "CloudPubSubDeadLetterSourceSubscription"
+	deliveryAttemptsAttribute = "CloudPubSubDeadLetterSourceDeliveryCount"
+)
+
+// correlationAttribute is the attribute of the messages sent for a finding holding its correlation ID.
+const correlationAttribute = services.CorrelationAttribute
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// PagerDutyServiceID is the PagerDuty service alerted of every message, none is if empty.
+	PagerDutyServiceID string
+	// PagerDutyFrom is the email address of the PagerDuty user incidents are created as.
+	PagerDutyFrom string
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Archive *services.Archive
+	// PagerDuty creates the incidents, nil if PagerDuty is not configured.
+	PagerDuty *services.PagerDuty
+	Logger    *services.Logger
+	// Redactor scrubs sensitive material from the incident, none is if nil.
+	Redactor *services.Redactor
+}
+
+// record is the archived form of a dead-lettered message.
+type record struct {
+	Subscription     string
+	DeliveryAttempts string
+	Attributes       map[string]string
+	// Data is the payload of the message if it is JSON, otherwise Raw is.
+	Data     json.RawMessage `json:",omitempty"`
+	Raw      []byte          `json:",omitempty"`
+	Received time.Time
+}
+
+// Execute archives the dead-lettered message, logs it for the dead-letter metric and raises a
+// PagerDuty incident if configured.
+//
+// The archived object is named after the subscription, the day and a hash of the payload so a
+// message delivered again overwrites its archive.
+func Execute(ctx context.Context, m pubsub.Message, values *Values, services *Services) error {
+	sub := m.Attributes[subscriptionAttribute]
+	if sub == "" {
+		sub = "unknown"
+	}
+	r := record{
+		Subscription:     sub,
+		DeliveryAttempts: m.Attributes[deliveryAttemptsAttribute],
+		Attributes:       m.Attributes,
+		Received:         time.Now().UTC(),
+	}
+	if json.Valid(m.Data) {
+		r.Data = m.Data
+	} else {
+		r.Raw = m.Data
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(m.Data)
+	name := fmt.Sprintf("%s/%s/%s.json", sub, r.Received.Format("2006/01/02"), hex.EncodeToString(sum[:]))
+	uri, err := services.Archive.Store(ctx, name, b)
+	if err != nil {
+		return err
+	}
+	// The dead-letter metric counts these entries, keep the message in sync with its filter.
+	services.Logger.Error("dead-lettered message from subscription %q after %s delivery attempts archived to %s", sub, r.DeliveryAttempts, uri)
+	if services.PagerDuty == nil || values.PagerDutyServiceID == "" {
+		return nil
+	}
+	title := fmt.Sprintf("Security response automation dropped a message from %s", sub)
+	body := fmt.Sprintf("A message from subscription %s failed after %s delivery attempts and was archived to %s.\n\nFinding: %s\nCorrelation ID: %s", sub, r.DeliveryAttempts, uri, m.Attributes["finding"], m.Attributes[correlationAttribute])
+	return services.PagerDuty.CreateIncident(ctx, values.PagerDutyFrom, values.PagerDutyServiceID, title, services.Redactor.Redact(body))
+}
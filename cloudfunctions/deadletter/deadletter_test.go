@@ -0,0 +1,77 @@
+package deadletter
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name              string
+		data              []byte
+		serviceID         string
+		expectedIncidents int
+		expectedData      bool
+	}{
+		{name: "archives json payloads", data: []byte(`{"ProjectID":"p"}`), expectedData: true},
+		{name: "archives other payloads", data: []byte("not json")},
+		{name: "alerts pagerduty", data: []byte(`{"ProjectID":"p"}`), serviceID: "PSERVICE", expectedIncidents: 1, expectedData: true},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &stubs.StorageStub{}
+			pd := &stubs.PagerDutyStub{}
+			m := pubsub.Message{Data: tt.data, Attributes: map[string]string{
+				"finding":                 "organizations/1/sources/2/findings/3",
+				subscriptionAttribute:     "projects/p/subscriptions/threat-findings-close-bucket",
+				deliveryAttemptsAttribute: "5",
+			}}
+			if err := Execute(ctx, m, &Values{PagerDutyServiceID: tt.serviceID, PagerDutyFrom: "sra@example.com"}, &Services{
+				Archive:   services.NewArchive(storage, "dead-letters"),
+				PagerDuty: services.NewPagerDuty(pd),
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if len(storage.StubbedObjects) != 1 {
+				t.Fatalf("%s archived %d objects want 1", tt.name, len(storage.StubbedObjects))
+			}
+			for name, b := range storage.StubbedObjects {
+				if !strings.HasPrefix(name, "projects/p/subscriptions/threat-findings-close-bucket/") {
+					t.Errorf("%s archived to %q", tt.name, name)
+				}
+				var r record
+				if err := json.Unmarshal(b, &r); err != nil {
+					t.Fatalf("%s archived invalid record: %q", tt.name, err)
+				}
+				if r.DeliveryAttempts != "5" || (r.Data != nil) != tt.expectedData || (r.Raw != nil) == tt.expectedData {
+					t.Errorf("%s archived %+v", tt.name, r)
+				}
+			}
+			if len(pd.Incidents) != tt.expectedIncidents {
+				t.Errorf("%s created %d incidents want %d", tt.name, len(pd.Incidents), tt.expectedIncidents)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "dead-letter" {
+  name                  = "DeadLetter"
+  description           = "Archives and alerts on messages automations failed to process."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "DeadLetter"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-dead-letter"
+  }
+  environment_variables = {
+    GCP_PROJECT          = var.setup.automation-project
+    DEAD_LETTER_BUCKET   = google_storage_bucket.dead-letters.name
+    PAGERDUTY_API_KEY    = var.pagerduty-api-key
+    PAGERDUTY_SERVICE_ID = var.pagerduty-service-id
+    PAGERDUTY_FROM       = var.pagerduty-from
+  }
+}
+
+# PubSub topic subscriptions forward the messages they failed to deliver to.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-dead-letter"
+  project = var.setup.automation-project
+}
+
+# Pub/Sub publishes dead-lettered messages as its service agent.
+resource "google_pubsub_topic_iam_member" "publisher" {
+  project = var.setup.automation-project
+  topic   = google_pubsub_topic.topic.name
+  role    = "roles/pubsub.publisher"
+  member  = "serviceAccount:service-${data.google_project.project.number}@gcp-sa-pubsub.iam.gserviceaccount.com"
+}
+
+data "google_project" "project" {
+  project_id = var.setup.automation-project
+}
+
+# Bucket the dead-lettered messages are archived to.
+resource "google_storage_bucket" "dead-letters" {
+  name     = "${var.setup.automation-project}-dead-letters"
+  project  = var.setup.automation-project
+  location = var.setup.region
+
+  lifecycle_rule {
+    condition {
+      age = 90
+    }
+    action {
+      type = "Delete"
+    }
+  }
+}
+
+# Required to archive dead-lettered messages.
+resource "google_storage_bucket_iam_member" "object-creator" {
+  bucket = google_storage_bucket.dead-letters.name
+  role   = "roles/storage.objectCreator"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Counts the dead-lettered messages by subscription.
+resource "google_logging_metric" "dead-letters" {
+  project = var.setup.automation-project
+  name    = "security-response-automation/dead-letters"
+  filter  = "logName=\"projects/${var.setup.automation-project}/logs/security-response-automation\" AND textPayload:\"dead-lettered message from subscription\""
+
+  metric_descriptor {
+    metric_kind = "DELTA"
+    value_type  = "INT64"
+    labels {
+      key        = "subscription"
+      value_type = "STRING"
+    }
+  }
+  label_extractors = {
+    "subscription" = "REGEXP_EXTRACT(textPayload, \"subscription \\\"([^\\\"]+)\\\"\")"
+  }
+}
@@ -0,0 +1,58 @@
+package fanout
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// removeMember is the operation removing a member from the policy of the project.
+const removeMember = services.OperationRemoveMember
+
+// Services contains the services needed for this function.
+type Services struct {
+	Resource *services.Resource
+	Logger   *services.Logger
+}
+
+// Execute applies the work item to its project. Errors are returned so Pub/Sub delivers the work
+// item again, the operations are safe to apply more than once.
+func Execute(ctx context.Context, item *services.WorkItem, services *Services) error {
+	if item.ProjectID == "" || item.Member == "" {
+		return fmt.Errorf("work item of %q missing project or member", item.Action)
+	}
+	switch item.Operation {
+	case removeMember:
+		if item.DryRun {
+			services.Logger.Info("dry_run on, %s would have removed %q from %q", item.Action, item.Member, item.ProjectID)
+			return nil
+		}
+		removed, err := services.Resource.RemoveMemberProjects(ctx, []string{item.ProjectID}, item.Member)
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			services.Logger.Info("%s found %q not in the policy of %q", item.Action, item.Member, item.ProjectID)
+			return nil
+		}
+		services.Logger.Info("%s removed %q from %q", item.Action, item.Member, item.ProjectID)
+		return nil
+	default:
+		return fmt.Errorf("unknown operation %q of %q", item.Operation, item.Action)
+	}
+}
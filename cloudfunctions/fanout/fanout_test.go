@@ -0,0 +1,79 @@
+package fanout
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+const member = "serviceAccount:deployer@sa-project.iam.gserviceaccount.com"
+
+func TestFanOut(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name         string
+		item         services.WorkItem
+		wantBindings int
+		wantErr      bool
+	}{
+		{
+			name:         "remove member",
+			item:         services.WorkItem{Action: "iam_contain_service_account", Operation: services.OperationRemoveMember, ProjectID: "app-project", Member: member},
+			wantBindings: 1,
+		},
+		{
+			name:         "dry run",
+			item:         services.WorkItem{Action: "iam_contain_service_account", Operation: services.OperationRemoveMember, ProjectID: "app-project", Member: member, DryRun: true},
+			wantBindings: 2,
+		},
+		{
+			name:         "unknown operation",
+			item:         services.WorkItem{Action: "iam_contain_service_account", Operation: "delete_project", ProjectID: "app-project", Member: member},
+			wantBindings: 2,
+			wantErr:      true,
+		},
+		{
+			name:         "missing project",
+			item:         services.WorkItem{Action: "iam_contain_service_account", Operation: services.OperationRemoveMember, Member: member},
+			wantBindings: 2,
+			wantErr:      true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crmStub := &stubs.ResourceManagerStub{ProjectPolicies: map[string]*crm.Policy{
+				"app-project": {Bindings: []*crm.Binding{
+					{Role: "roles/viewer", Members: []string{"user:dev@example.com"}},
+					{Role: "roles/editor", Members: []string{member}},
+				}},
+			}}
+			s := &Services{
+				Resource: services.NewResource(crmStub, &stubs.StorageStub{}),
+				Logger:   services.NewLogger(&stubs.LoggerStub{}),
+			}
+			if err := Execute(ctx, &tt.item, s); (err != nil) != tt.wantErr {
+				t.Fatalf("%s got %q, want error %t", tt.name, err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantBindings, len(crmStub.ProjectPolicies["app-project"].Bindings)); diff != "" {
+				t.Errorf("%s bindings (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
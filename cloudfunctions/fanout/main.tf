@@ -0,0 +1,54 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "fan-out" {
+  name                  = "FanOut"
+  description           = "Applies the per-project work items of actions touching many projects."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "FanOut"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-fan-out"
+    # Work items failing are delivered again on their own.
+    failure_policy {
+      retry = true
+    }
+  }
+  environment_variables = {
+    GCP_PROJECT             = var.setup.automation-project
+    FOLDER_SERVICE_ACCOUNTS = var.setup.folder-service-accounts
+  }
+}
+
+# Required by FanOut to remove members from the IAM policies of projects within this folder.
+resource "google_folder_iam_member" "fan-out-iam-admin-folder-bind" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/resourcemanager.projectIamAdmin"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# PubSub topic receiving the work items of actions.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-fan-out"
+  project = var.setup.automation-project
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/filter/internal/storage"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
@@ -51,7 +52,10 @@ type notification struct {
 // then if it should be filtered, update the finding, otherwise pass it along to the
 // router cloud function.
 func Execute(ctx context.Context, m pubsub.Message, svcs *Services) (err error) {
-	raw := m.Data
+	raw, err := scc.Decode(m.Data)
+	if err != nil {
+		return err
+	}
 	var msg notification
 	if err = json.Unmarshal(raw, &msg); err != nil {
 		svcs.Logger.Info("Only SCC Notification format is supported. This message will not be filtered.")
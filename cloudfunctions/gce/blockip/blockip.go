@@ -0,0 +1,72 @@
+package blockip
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "gce_block_ip",
+		EntryPoint:  "BlockIP",
+		Topic:       "threat-findings-block-ip",
+		Services:    []string{"Firewall", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/compute.securityAdmin"},
+		Permissions: []string{"compute.firewalls.get", "compute.firewalls.create", "compute.networks.updatePolicy", "compute.globalOperations.get"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID   string
+	Network     string
+	IPAddresses []string
+	DryRun      bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Firewall *services.Firewall
+	Resource *services.Resource
+	Logger   *services.Logger
+}
+
+// Execute adds a firewall rule denying all ingress traffic from the IP addresses.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if len(values.IPAddresses) == 0 {
+		return fmt.Errorf("no ip addresses to block")
+	}
+	for _, ip := range values.IPAddresses {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid ip address %q", ip)
+		}
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have blocked %q in project %q", values.IPAddresses, values.ProjectID)
+		return nil
+	}
+	if err := services.Firewall.BlockIPs(ctx, values.ProjectID, values.Network, values.IPAddresses); err != nil {
+		return errors.Wrapf(err, "failed to block %q", values.IPAddresses)
+	}
+	services.Logger.Info("blocked %q in project %q", values.IPAddresses, values.ProjectID)
+	return nil
+}
@@ -0,0 +1,98 @@
+package blockip
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestBlockIP(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name         string
+		ips          []string
+		existingRule *compute.Firewall
+		expectedRule *compute.Firewall
+		expectError  bool
+	}{
+		{
+			name: "new rule",
+			ips:  []string{"198.51.100.7"},
+			expectedRule: &compute.Firewall{
+				Denied:          []*compute.FirewallDenied{{IPProtocol: "all"}},
+				Description:     "Block IP addresses by Security Response Automation",
+				Direction:       "INGRESS",
+				Name:            "automatic-ip-block",
+				Network:         "projects/project-id/global/networks/default",
+				SourceRanges:    []string{"198.51.100.7/32"},
+				ForceSendFields: []string{"Priority"},
+			},
+		},
+		{
+			name:         "existing rule",
+			ips:          []string{"198.51.100.7"},
+			existingRule: &compute.Firewall{Id: 123, Name: "automatic-ip-block", SourceRanges: []string{"203.0.113.1/32"}},
+			expectedRule: &compute.Firewall{
+				Name:         "automatic-ip-block",
+				SourceRanges: []string{"198.51.100.7/32", "203.0.113.1/32"},
+			},
+		},
+		{
+			name:        "invalid ip",
+			ips:         []string{"not-an-ip"},
+			expectError: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			svcs, computeStub := setupBlockIP()
+			computeStub.StubbedFirewall = tt.existingRule
+			computeStub.FirewallRuleNotFound = tt.existingRule == nil
+			values := &Values{
+				ProjectID:   "project-id",
+				Network:     "projects/project-id/global/networks/default",
+				IPAddresses: tt.ips,
+			}
+			err := Execute(ctx, values, &Services{
+				Firewall: svcs.Firewall,
+				Resource: svcs.Resource,
+				Logger:   svcs.Logger,
+			})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s failed got:%q expectError:%t", tt.name, err, tt.expectError)
+			}
+			if diff := cmp.Diff(tt.expectedRule, computeStub.SavedFirewallRule); diff != "" {
+				t.Errorf("%s failed, difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func setupBlockIP() (*services.Global, *stubs.ComputeStub) {
+	loggerStub := &stubs.LoggerStub{}
+	log := services.NewLogger(loggerStub)
+	computeStub := &stubs.ComputeStub{}
+	storageStub := &stubs.StorageStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	res := services.NewResource(crmStub, storageStub)
+	fw := services.NewFirewall(computeStub)
+	return &services.Global{Logger: log, Firewall: fw, Resource: res}, computeStub
+}
@@ -15,20 +15,60 @@ package createsnapshot
 // limitations under the License.
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"log"
+	"regexp"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	"github.com/pkg/errors"
 	compute "google.golang.org/api/compute/v1"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "gce_create_disk_snapshot",
+		EntryPoint:  "SnapshotDisk",
+		Topic:       "threat-findings-create-disk-snapshot",
+		Services:    []string{"Host", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/compute.admin"},
+		Permissions: []string{"compute.instances.get", "compute.disks.get", "compute.disks.createSnapshot", "compute.disks.create", "compute.instances.attachDisk", "compute.snapshots.get", "compute.snapshots.list", "compute.snapshots.create", "compute.snapshots.delete", "compute.snapshots.setLabels", "compute.snapshots.useReadOnly", "compute.globalOperations.get", "compute.zoneOperations.get", "resourcemanager.projects.get"},
+		// Snapshots of a disk can only be taken every 10 minutes, several disks a minute per project.
+		Quota:          "compute.snapshots.insert",
+		QuotaPerMinute: 20,
+	})
+}
+
 const (
 	snapshotPrefix = "forensic-snapshots-"
-	// allowSnapshotOlderThanDuration defines how old a snapshot must be before we overwrite.
+	// allowSnapshotOlderThanDuration defines how old a snapshot must be before we overwrite, unless
+	// the settings of the project set another recency window.
 	allowSnapshotOlderThanDuration = 5 * time.Minute
+	// defaultNameTemplate names the snapshots unless the settings of the project set a template.
+	defaultNameTemplate = snapshotPrefix + "{{.Category}}-{{.Disk}}"
+	// maxNameLength is the longest name or label value Compute Engine accepts.
+	maxNameLength = 63
+	// categoryLabel and findingLabel are set on each snapshot to the category and ID of the finding
+	// it was taken for, snapshots being deduplicated by category.
+	categoryLabel = "sra-category"
+	findingLabel  = "sra-finding"
+	// maxConcurrentSnapshots bounds how many disks are snapshotted at once, instances with many
+	// disks outlasting the function's timeout when snapshotted one at a time.
+	maxConcurrentSnapshots = 4
+)
+
+// Statuses of the disks reported in Output, disks that failed report their error instead.
+const (
+	StatusCreated = "created"
+	StatusEarlier = "created by an earlier attempt"
+	StatusSkipped = "skipped"
+	StatusDryRun  = "dry run"
 )
 
 // labels to be saved with each disk snapshot created.
@@ -36,6 +76,39 @@ var labels = map[string]string{
 	"info": "created-by-security-response-automation",
 }
 
+var (
+	// invalidNameChars are the runs of characters snapshot names cannot hold.
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+	// invalidLabelChars are the runs of characters label values cannot hold.
+	invalidLabelChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+// Settings are how the snapshots of a project are deduplicated, named and labeled.
+type Settings struct {
+	// RecencyMinutes skips the disks snapshotted for the same category less than this many
+	// minutes ago, 5 if zero.
+	RecencyMinutes int
+	// NameTemplate is the text/template naming the snapshots, executed with the fields Category,
+	// Disk, Instance, FindingID, ProjectID and Timestamp, such as
+	// "{{.Category}}-{{.Disk}}-{{.Timestamp}}". The name is lower cased, characters that are not
+	// letters, digits or hyphens are replaced by hyphens and it is cut to 63 characters. Names are
+	// those of the default template, "forensic-snapshots-{{.Category}}-{{.Disk}}", if empty.
+	NameTemplate string
+	// Labels are set on the snapshots along with those marking them as created by SRA for the
+	// finding, which they cannot replace.
+	Labels map[string]string
+}
+
+// Override replaces the settings it sets for the projects within its folders, at any depth, and
+// whose labels match all its selectors.
+type Override struct {
+	// FolderIDs are the folders the override applies to, any folder if empty.
+	FolderIDs []string
+	// ProjectLabels are the label selectors of the projects it applies to, such as "env=prod".
+	ProjectLabels []string
+	Settings
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	DryRun    bool
@@ -55,6 +128,13 @@ type Values struct {
 	DestProjectID string
 	// DestZone is the optional zone where the newly created snapshot should be copied to.
 	DestZone string
+
+	// FindingID identifies the finding in the names and labels of the snapshots.
+	FindingID string
+	// Settings are those of the projects no override applies to.
+	Settings Settings
+	// Overrides replace the settings of some projects, the first applying to the project is used.
+	Overrides []Override
 }
 
 // Services contains the services needed for this function.
@@ -62,28 +142,45 @@ type Services struct {
 	Host     *services.Host
 	Logger   *services.Logger
 	Resource *services.Resource
+	// Progress records the disks snapshotted, letting a retry snapshot only the disks that failed.
+	Progress *services.Progress
 }
 
 // Output contains the output of this function.
 type Output struct {
 	// DiskNames optionally contains the names of the disks copied to a target project.
 	DiskNames []string
+	// Statuses maps the disks of the instance to one of the statuses above or their error.
+	Statuses map[string]string
 }
 
 // Execute creates a snapshot of an instance's disk.
 //
 // For a given supported finding pull each disk associated with the affected instance.
-// 	- Check to make sure we haven't created a snapshot for this finding recently.
-// 	- Create a new snapshot for each disk labeled with the finding and current time.
+// 	- Check to make sure we haven't created a snapshot for this finding recently, within the
+// 	  recency window of the project's settings.
+// 	- Create a new snapshot for each disk named by the settings' template and labeled with the
+// 	  finding, several disks at once.
+// 	- Disks failing do not stop the others, the returned PartialError names them and a retry
+// 	  of the remediation snapshots only these.
 //
 // In order for the snapshot to be create the service account must be granted the correct
 // role on the affected project. At this time this grant is defined per project but should
 // be changed to support folder and organization level grants.
 func Execute(ctx context.Context, values *Values, services *Services) (*Output, error) {
 	var output Output
+	settings, err := settingsOf(ctx, values, services)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get snapshot settings")
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(settings.NameTemplate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid snapshot name template %q", settings.NameTemplate)
+	}
 	log.Printf("listing disk names within instance %q, in zone %q and project %q", values.Instance, values.Zone, values.ProjectID)
 	disksCopied := []string{}
 	rule := strings.Replace(values.RuleName, "_", "-", -1)
+	taken := now().UTC()
 	disks, err := services.Host.ListInstanceDisks(ctx, values.ProjectID, values.Zone, values.Instance)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list disks")
@@ -95,65 +192,127 @@ func Execute(ctx context.Context, values *Values, services *Services) (*Output,
 	}
 	log.Printf("got %d existing snapshots for project %q", len(snapshots.Items), values.ProjectID)
 
-	for _, disk := range disks {
-		snapshotName := createSnapshotName(rule, disk.Name)
-		create, removeExisting, err := canCreateSnapshot(snapshots, disk, rule)
+	progress := progressOf(services)
+	copied := make([]string, len(disks))
+	statuses := make([]string, len(disks))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentSnapshots)
+	for i, disk := range disks {
+		snapshotName, err := nameSnapshot(tmpl, values, rule, disk.Name, taken)
+		if err != nil {
+			wg.Wait()
+			return nil, err
+		}
+		if !progress.Pending(disk.Name) {
+			if earlier := latestSnapshot(snapshots, disk, rule); earlier != "" {
+				snapshotName = earlier
+			}
+			log.Printf("snapshot %q for disk %q was created by an earlier attempt", snapshotName, disk.Name)
+			statuses[i] = StatusEarlier
+			if values.DestProjectID != "" {
+				copied[i] = snapshotName
+			}
+			continue
+		}
+		create, removeExisting, err := canCreateSnapshot(snapshots, disk, rule, snapshotName, settings.window())
 		if err != nil {
+			wg.Wait()
 			return nil, errors.Wrapf(err, "failed checking if can create snapshot for %q", disk.Name)
 		}
 
 		if !create {
 			log.Printf("snapshot %q for disk %q will be skipped (not old enough or from another finding)", snapshotName, disk.Name)
+			statuses[i] = StatusSkipped
 			continue
 		}
 
 		if values.DryRun {
 			services.Logger.Info("dry_run on, would created a snapshot of %q from %q", disk.Name, values.ProjectID)
+			statuses[i] = StatusDryRun
 			continue
 		}
 
-		for k := range removeExisting {
-			if err := services.Host.DeleteDiskSnapshot(ctx, values.ProjectID, k); err != nil {
-				return nil, errors.Wrapf(err, "failed deleting snapshot: %q", k)
+		wg.Add(1)
+		go func(i int, disk *compute.Disk, snapshotName string, removeExisting map[string]bool) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := snapshotDisk(ctx, values, services, disk, snapshotName, removeExisting, snapshotLabels(values, settings, rule)); err != nil {
+				services.Logger.Error("failed to snapshot disk %q: %q", disk.Name, err)
+				statuses[i] = err.Error()
+				if err := progress.Fail(ctx, disk.Name, err); err != nil {
+					log.Printf("failed to record progress: %q", err)
+				}
+				return
 			}
-			services.Logger.Info("removed existing snapshot %q from disk %q", k, disk.Name)
+			statuses[i] = StatusCreated
+			if err := progress.Complete(ctx, disk.Name); err != nil {
+				log.Printf("failed to record progress: %q", err)
+			}
+			if values.DestProjectID != "" {
+				copied[i] = snapshotName
+			}
+		}(i, disk, snapshotName, removeExisting)
+	}
+	wg.Wait()
+	output.Statuses = make(map[string]string)
+	for i, disk := range disks {
+		output.Statuses[disk.Name] = statuses[i]
+		if copied[i] != "" {
+			disksCopied = append(disksCopied, copied[i])
 		}
+	}
+	log.Printf("completed")
+	output.DiskNames = disksCopied
+	return &output, progress.Err()
+}
 
-		log.Printf("creating a snapshot %q for %q", snapshotName, disk.Name)
-		if err := services.Host.CreateDiskSnapshot(ctx, values.ProjectID, values.Zone, disk.Name, snapshotName); err != nil {
-			return nil, errors.Wrapf(err, "failed creating snapshot: %q", snapshotName)
+// snapshotDisk replaces the existing snapshots of the disk with a new one, copied to the
+// destination project if one is configured.
+func snapshotDisk(ctx context.Context, values *Values, services *Services, disk *compute.Disk, snapshotName string, removeExisting map[string]bool, labels map[string]string) error {
+	for k := range removeExisting {
+		if err := services.Host.DeleteDiskSnapshot(ctx, values.ProjectID, k); err != nil {
+			return errors.Wrapf(err, "failed deleting snapshot: %q", k)
 		}
-		services.Logger.Info("created snapshot for disk %q", disk.Name)
+		services.Logger.Info("removed existing snapshot %q from disk %q", k, disk.Name)
+	}
 
-		if err := services.Host.SetSnapshotLabels(ctx, values.ProjectID, snapshotName, disk, labels); err != nil {
-			return nil, errors.Wrapf(err, "failed setting labels: %q", snapshotName)
-		}
-		log.Printf("set labels for snapshot %q for disk %q", snapshotName, disk.Name)
+	log.Printf("creating a snapshot %q for %q", snapshotName, disk.Name)
+	if err := services.Host.CreateDiskSnapshot(ctx, values.ProjectID, values.Zone, disk.Name, snapshotName); err != nil {
+		return errors.Wrapf(err, "failed creating snapshot: %q", snapshotName)
+	}
+	services.Logger.Info("created snapshot for disk %q", disk.Name)
 
-		if values.DestProjectID != "" {
-			log.Printf("copying snapshot %q for %q to %q in %q", snapshotName, disk.Name, values.DestProjectID, values.DestZone)
-			if err := services.Host.CopyDiskSnapshot(ctx, values.ProjectID, values.DestProjectID, values.DestZone, snapshotName); err != nil {
-				return nil, errors.Wrapf(err, "failed to copy disk to %q", values.DestProjectID)
-			}
-			disksCopied = append(disksCopied, snapshotName)
-			services.Logger.Info("copied snapshot %q to %q in %q", snapshotName, values.DestProjectID, values.DestZone)
+	if err := services.Host.SetSnapshotLabels(ctx, values.ProjectID, snapshotName, disk, labels); err != nil {
+		return errors.Wrapf(err, "failed setting labels: %q", snapshotName)
+	}
+	log.Printf("set labels for snapshot %q for disk %q", snapshotName, disk.Name)
+
+	if values.DestProjectID != "" {
+		log.Printf("copying snapshot %q for %q to %q in %q", snapshotName, disk.Name, values.DestProjectID, values.DestZone)
+		if err := services.Host.CopyDiskSnapshot(ctx, values.ProjectID, values.DestProjectID, values.DestZone, snapshotName); err != nil {
+			return errors.Wrapf(err, "failed to copy disk to %q", values.DestProjectID)
 		}
+		services.Logger.Info("copied snapshot %q to %q in %q", snapshotName, values.DestProjectID, values.DestZone)
 	}
-	log.Printf("completed")
-	output.DiskNames = disksCopied
-	return &output, nil
+	return nil
 }
 
-// canCreateSnapshot checks if we should create a snapshot along with a map of existing snapshots to be removed.
-func canCreateSnapshot(snapshots *compute.SnapshotList, disk *compute.Disk, rule string) (bool, map[string]bool, error) {
+// progressOf returns the progress of the remediation, kept for this attempt only if there is none.
+func progressOf(s *Services) *services.Progress {
+	if s.Progress == nil {
+		return &services.Progress{}
+	}
+	return s.Progress
+}
+
+// canCreateSnapshot checks if we should create a snapshot along with a map of existing snapshots
+// to be removed, those of the disk and category named as the new one would be.
+func canCreateSnapshot(snapshots *compute.SnapshotList, disk *compute.Disk, rule, name string, window time.Duration) (bool, map[string]bool, error) {
 	create := true
-	prefix := createSnapshotName(rule, disk.Name)
 	removeExisting := map[string]bool{}
-	for _, s := range snapshots.Items {
-		if s.SourceDisk != disk.SelfLink || !strings.HasPrefix(s.Name, prefix) {
-			continue
-		}
-		isNew, err := isSnapshotCreatedWithin(s.CreationTimestamp, allowSnapshotOlderThanDuration)
+	for _, s := range snapshotsOf(snapshots, disk, rule) {
+		isNew, err := isSnapshotCreatedWithin(s.CreationTimestamp, window)
 		if err != nil {
 			return false, nil, err
 		}
@@ -161,11 +320,46 @@ func canCreateSnapshot(snapshots *compute.SnapshotList, disk *compute.Disk, rule
 			create = !isNew
 			break
 		}
-		removeExisting[s.Name] = true
+		if s.Name == name {
+			removeExisting[s.Name] = true
+		}
 	}
 	return create, removeExisting, nil
 }
 
+// snapshotsOf returns the snapshots of the disk taken for the category, labeled with it or named
+// as snapshots were before names could be configured.
+func snapshotsOf(snapshots *compute.SnapshotList, disk *compute.Disk, rule string) []*compute.Snapshot {
+	if snapshots == nil {
+		return nil
+	}
+	legacy := createSnapshotName(rule, disk.Name)
+	var of []*compute.Snapshot
+	for _, s := range snapshots.Items {
+		if s.SourceDisk != disk.SelfLink {
+			continue
+		}
+		if s.Labels[categoryLabel] == labelValue(rule) || strings.HasPrefix(s.Name, legacy) {
+			of = append(of, s)
+		}
+	}
+	return of
+}
+
+// latestSnapshot returns the name of the newest snapshot of the disk taken for the category, empty
+// if there is none.
+func latestSnapshot(snapshots *compute.SnapshotList, disk *compute.Disk, rule string) string {
+	var name string
+	var latest time.Time
+	for _, s := range snapshotsOf(snapshots, disk, rule) {
+		t, err := time.Parse(time.RFC3339, s.CreationTimestamp)
+		if err == nil && t.After(latest) {
+			name, latest = s.Name, t
+		}
+	}
+	return name
+}
+
 // isSnapshotCreatedWithin checks if the previous snapshots created recently.
 func isSnapshotCreatedWithin(snapshotTime string, window time.Duration) (bool, error) {
 	t, err := time.Parse(time.RFC3339, snapshotTime)
@@ -178,3 +372,123 @@ func isSnapshotCreatedWithin(snapshotTime string, window time.Duration) (bool, e
 func createSnapshotName(rule, disk string) string {
 	return snapshotPrefix + rule + "-" + disk
 }
+
+// now returns the time snapshots are named after, replaced by tests.
+var now = time.Now
+
+// nameSnapshot returns the name of the disk's snapshot, executing the template.
+func nameSnapshot(tmpl *template.Template, values *Values, rule, disk string, taken time.Time) (string, error) {
+	var b bytes.Buffer
+	err := tmpl.Execute(&b, map[string]string{
+		"Category":  rule,
+		"Disk":      disk,
+		"Instance":  values.Instance,
+		"FindingID": values.FindingID,
+		"ProjectID": values.ProjectID,
+		"Timestamp": taken.Format("20060102-150405"),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to name the snapshot of %q", disk)
+	}
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(b.String()), "-"), "-")
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		name = "sra-" + name
+	}
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-")
+	}
+	return name, nil
+}
+
+// labelValue returns the value as a valid label value.
+func labelValue(v string) string {
+	v = invalidLabelChars.ReplaceAllString(strings.ToLower(v), "-")
+	if len(v) > maxNameLength {
+		v = v[:maxNameLength]
+	}
+	return v
+}
+
+// snapshotLabels returns the labels of the snapshots: those of the settings, then those marking
+// them as created by SRA for the finding.
+func snapshotLabels(values *Values, settings Settings, rule string) map[string]string {
+	l := make(map[string]string)
+	for k, v := range settings.Labels {
+		l[k] = v
+	}
+	for k, v := range labels {
+		l[k] = v
+	}
+	l[categoryLabel] = labelValue(rule)
+	if values.FindingID != "" {
+		l[findingLabel] = labelValue(values.FindingID)
+	}
+	return l
+}
+
+// window returns the recency window of the settings.
+func (s Settings) window() time.Duration {
+	if s.RecencyMinutes <= 0 {
+		return allowSnapshotOlderThanDuration
+	}
+	return time.Duration(s.RecencyMinutes) * time.Minute
+}
+
+// settingsOf returns the settings of the project: those set by the first override applying to it
+// over the default settings, with the default template if none is set.
+func settingsOf(ctx context.Context, values *Values, services *Services) (Settings, error) {
+	settings := values.Settings
+	for _, o := range values.Overrides {
+		ok, err := applies(ctx, services.Resource, o, values.ProjectID)
+		if err != nil {
+			return Settings{}, err
+		}
+		if !ok {
+			continue
+		}
+		if o.RecencyMinutes != 0 {
+			settings.RecencyMinutes = o.RecencyMinutes
+		}
+		if o.NameTemplate != "" {
+			settings.NameTemplate = o.NameTemplate
+		}
+		if o.Labels != nil {
+			settings.Labels = o.Labels
+		}
+		break
+	}
+	if settings.NameTemplate == "" {
+		settings.NameTemplate = defaultNameTemplate
+	}
+	return settings, nil
+}
+
+// applies reports whether the override applies to the project.
+func applies(ctx context.Context, r *services.Resource, o Override, projectID string) (bool, error) {
+	if len(o.FolderIDs) > 0 {
+		folders, err := r.Folders(ctx, projectID)
+		if err != nil {
+			return false, err
+		}
+		if !overlaps(folders, o.FolderIDs) {
+			return false, nil
+		}
+	}
+	ok, err := r.MatchesLabels(ctx, projectID, o.ProjectLabels)
+	if err != nil {
+		return false, fmt.Errorf("failed to match the labels of %q: %q", projectID, err)
+	}
+	return ok, nil
+}
+
+// overlaps reports whether the lists have a value in common.
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
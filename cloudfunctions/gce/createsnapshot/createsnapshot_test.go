@@ -16,12 +16,16 @@ package createsnapshot
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
 	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
 	compute "google.golang.org/api/compute/v1"
 )
 
@@ -119,8 +123,9 @@ func TestCreateSnapshot(t *testing.T) {
 				createDisk("sample-disk-name2", "instance1"),
 			},
 			existingDiskSnapshots: []*compute.SnapshotList{
-				{Items: []*compute.Snapshot{createSs(snapshotName2, now, "sample-disk-name2")}},
-				{Items: []*compute.Snapshot{createSs(snapshotName, now, diskName)}},
+				// Disks are snapshotted concurrently, either may list the snapshots first.
+				{Items: []*compute.Snapshot{createSs(snapshotName, now, diskName), createSs(snapshotName2, now, "sample-disk-name2")}},
+				{Items: []*compute.Snapshot{createSs(snapshotName, now, diskName), createSs(snapshotName2, now, "sample-disk-name2")}},
 				{Items: []*compute.Snapshot{createSs(snapshotName, fiveMinAgo, diskName)}},
 			},
 			expectedSnapshots:        expectedSnapshot2,
@@ -203,6 +208,43 @@ func TestCreateSnapshot(t *testing.T) {
 	}
 }
 
+func TestResumeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	svcs, computeStub := createSnapshotSetup()
+	computeStub.StubbedListDisks = &compute.DiskList{Items: []*compute.Disk{
+		createDisk("disk-1", "instance1"),
+		createDisk("disk-2", "instance1"),
+	}}
+	computeStub.StubbedListProjectSnapshots = []*compute.SnapshotList{nil, nil}
+	computeStub.CreateSnapshotShouldFail = map[string]bool{"disk-2": true}
+	values := &Values{ProjectID: "project-id-123", RuleName: "bad_ip", Instance: "instance1", Zone: "test-zone"}
+	s := &Services{Host: svcs.Host, Logger: svcs.Logger, Progress: &services.Progress{}}
+
+	_, err := Execute(ctx, values, s)
+	partial, ok := err.(*services.PartialError)
+	if !ok {
+		t.Fatalf("first attempt got %q, want a partial failure", err)
+	}
+	if diff := cmp.Diff([]string{"disk-1"}, partial.Completed); diff != "" {
+		t.Errorf("completed difference: %+v", diff)
+	}
+	if _, ok := partial.Failed["disk-2"]; !ok {
+		t.Errorf("disk-2 not reported as failed: %q", partial)
+	}
+
+	computeStub.CreateSnapshotShouldFail = nil
+	computeStub.SavedCreateSnapshots = make(map[string]compute.Snapshot)
+	if _, err := Execute(ctx, values, s); err != nil {
+		t.Fatalf("retry failed: %q", err)
+	}
+	if _, ok := computeStub.SavedCreateSnapshots["disk-1"]; ok {
+		t.Errorf("retry snapshotted disk-1 again")
+	}
+	if _, ok := computeStub.SavedCreateSnapshots["disk-2"]; !ok {
+		t.Errorf("retry did not snapshot disk-2")
+	}
+}
+
 func createDisk(name, instance string) *compute.Disk {
 	return &compute.Disk{
 		Name:     name,
@@ -232,3 +274,145 @@ func createSnapshotSetup() (*services.Global, *stubs.ComputeStub) {
 	r := services.NewResource(resourceManagerStub, storageStub)
 	return &services.Global{Host: h, Resource: r, Logger: log}, computeStub
 }
+
+func TestConcurrentSnapshots(t *testing.T) {
+	ctx := context.Background()
+	svcs, computeStub := createSnapshotSetup()
+	var disks []*compute.Disk
+	created := &compute.SnapshotList{}
+	now := time.Now().Format(time.RFC3339)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("disk-%d", i)
+		disks = append(disks, createDisk(name, "instance1"))
+		created.Items = append(created.Items, createSs(createSnapshotName("bad-ip", name), now, name))
+	}
+	computeStub.StubbedListDisks = &compute.DiskList{Items: disks}
+	// The first list is read by Execute, the rest by each disk setting its labels.
+	computeStub.StubbedListProjectSnapshots = []*compute.SnapshotList{nil}
+	for range disks {
+		computeStub.StubbedListProjectSnapshots = append([]*compute.SnapshotList{created}, computeStub.StubbedListProjectSnapshots...)
+	}
+	computeStub.CreateSnapshotShouldFail = map[string]bool{"disk-3": true}
+	values := &Values{ProjectID: "project-id-123", RuleName: "bad_ip", Instance: "instance1", Zone: "test-zone"}
+
+	output, err := Execute(ctx, values, &Services{Host: svcs.Host, Logger: svcs.Logger})
+	if _, ok := err.(*services.PartialError); !ok {
+		t.Fatalf("got %q, want a partial failure", err)
+	}
+	if len(computeStub.SavedCreateSnapshots) != 9 {
+		t.Errorf("created %d snapshots, want 9", len(computeStub.SavedCreateSnapshots))
+	}
+	for _, d := range disks {
+		got := output.Statuses[d.Name]
+		if d.Name == "disk-3" {
+			if !strings.HasPrefix(got, "failed creating snapshot") {
+				t.Errorf("status of %q got %q, want its error", d.Name, got)
+			}
+			continue
+		}
+		if got != StatusCreated {
+			t.Errorf("status of %q got %q, want %q", d.Name, got, StatusCreated)
+		}
+	}
+}
+
+func TestSnapshotSettings(t *testing.T) {
+	ctx := context.Background()
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) }
+	const name = "bad-ip-disk-1-finding-123-20261016-093000"
+	earlier := createSs("bad-ip-disk-1-finding-100-20261016-091000", time.Now().Add(-20*time.Minute).Format(time.RFC3339), "disk-1")
+	earlier.Labels = map[string]string{categoryLabel: "bad-ip"}
+	test := []struct {
+		name           string
+		folder         string
+		projectLabels  map[string]string
+		expectedName   string
+		expectedLabels map[string]string
+	}{
+		{
+			name:           "default settings",
+			folder:         "999",
+			expectedName:   name,
+			expectedLabels: map[string]string{"team": "secops", "info": "created-by-security-response-automation", categoryLabel: "bad-ip", findingLabel: "finding_123"},
+		},
+		{
+			name:           "override skips recent snapshots",
+			folder:         "123",
+			projectLabels:  map[string]string{"env": "prod"},
+			expectedName:   "",
+			expectedLabels: nil,
+		},
+		{
+			name:           "override needs the project labels",
+			folder:         "123",
+			projectLabels:  map[string]string{"env": "dev"},
+			expectedName:   name,
+			expectedLabels: map[string]string{"team": "secops", "info": "created-by-security-response-automation", categoryLabel: "bad-ip", findingLabel: "finding_123"},
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			_, computeStub := createSnapshotSetup()
+			crmStub := &stubs.ResourceManagerStub{
+				GetAncestryResponse: services.CreateAncestors([]string{"project/project-id-123", "folder/" + tt.folder, "organization/456"}),
+				StubbedProjects:     map[string]*crm.Project{"project-id-123": {ProjectId: "project-id-123", Labels: tt.projectLabels}},
+			}
+			computeStub.StubbedListDisks = &compute.DiskList{Items: []*compute.Disk{createDisk("disk-1", "instance1")}}
+			created := createSs(name, time.Now().Format(time.RFC3339), "disk-1")
+			computeStub.StubbedListProjectSnapshots = []*compute.SnapshotList{
+				{Items: []*compute.Snapshot{earlier, created}},
+				{Items: []*compute.Snapshot{earlier}},
+			}
+			values := &Values{
+				ProjectID: "project-id-123",
+				RuleName:  "bad_ip",
+				Instance:  "instance1",
+				Zone:      "test-zone",
+				FindingID: "Finding_123",
+				Settings: Settings{
+					RecencyMinutes: 10,
+					NameTemplate:   "{{.Category}}-{{.Disk}}-{{.FindingID}}-{{.Timestamp}}",
+					Labels:         map[string]string{"team": "secops"},
+				},
+				Overrides: []Override{{FolderIDs: []string{"123"}, ProjectLabels: []string{"env=prod"}, Settings: Settings{RecencyMinutes: 60}}},
+			}
+			r := services.NewResource(crmStub, &stubs.StorageStub{})
+			if _, err := Execute(ctx, values, &Services{Host: services.NewHost(computeStub), Logger: services.NewLogger(&stubs.LoggerStub{}), Resource: r}); err != nil {
+				t.Fatalf("%s failed to create snapshot: %q", tt.name, err)
+			}
+			if got := computeStub.SavedCreateSnapshots["disk-1"].Name; got != tt.expectedName {
+				t.Errorf("%s snapshot name got %q want %q", tt.name, got, tt.expectedName)
+			}
+			if diff := cmp.Diff(tt.expectedLabels, computeStub.SavedSnapshotLabels); diff != "" {
+				t.Errorf("%s labels difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestNameSnapshot(t *testing.T) {
+	taken := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	values := &Values{ProjectID: "prod-project", Instance: "web-1", FindingID: "F00"}
+	for _, tt := range []struct {
+		template string
+		expected string
+	}{
+		{template: defaultNameTemplate, expected: "forensic-snapshots-bad-ip-disk-1"},
+		{template: "{{.ProjectID}}/{{.Instance}}/{{.FindingID}}_{{.Timestamp}}", expected: "prod-project-web-1-f00-20261016-093000"},
+		{template: "{{.Timestamp}}-{{.Disk}}", expected: "sra-20261016-093000-disk-1"},
+		{template: strings.Repeat("{{.Disk}}-", 20), expected: strings.TrimSuffix(strings.Repeat("disk-1-", 9), "-")},
+	} {
+		tmpl, err := template.New("name").Option("missingkey=error").Parse(tt.template)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %q", tt.template, err)
+		}
+		got, err := nameSnapshot(tmpl, values, "bad-ip", "disk-1", taken)
+		if err != nil {
+			t.Fatalf("%q got error %q", tt.template, err)
+		}
+		if got != tt.expected {
+			t.Errorf("%q got %q want %q", tt.template, got, tt.expected)
+		}
+	}
+}
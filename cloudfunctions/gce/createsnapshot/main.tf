@@ -28,8 +28,16 @@ resource "google_cloudfunctions_function" "create-disk-snapshot" {
     event_type = "google.pubsub.topic.publish"
     resource   = "threat-findings-create-disk-snapshot"
   }
+  # Snapshots over the compute.snapshots.insert quota are deferred on the router's queue.
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    TASKS_QUEUE                 = "projects/${var.setup.automation-project}/locations/${var.setup.region}/queues/sra-deferred-automations"
+    TASKS_SERVICE_ACCOUNT       = var.setup.automation-service-account
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "gce_create_disk_snapshot", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_gce_create_disk_snapshot"
   }
 }
 
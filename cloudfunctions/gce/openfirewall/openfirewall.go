@@ -18,10 +18,22 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	"github.com/pkg/errors"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "remediate_firewall",
+		EntryPoint:  "OpenFirewall",
+		Topic:       "threat-findings-open-firewall",
+		Services:    []string{"Firewall", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/compute.securityAdmin"},
+		Permissions: []string{"compute.firewalls.get", "compute.firewalls.create", "compute.firewalls.update", "compute.firewalls.delete", "compute.networks.updatePolicy", "compute.globalOperations.get"},
+	})
+}
+
 // Values contains the required and optional values needed for this function.
 type Values struct {
 	Action       string
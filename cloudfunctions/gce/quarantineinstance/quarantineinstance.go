@@ -0,0 +1,104 @@
+package quarantineinstance
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "gce_quarantine_instance",
+		EntryPoint:  "QuarantineInstance",
+		Topic:       "threat-findings-quarantine-instance",
+		Services:    []string{"Host", "Firewall", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/compute.instanceAdmin.v1", "roles/compute.securityAdmin"},
+		Permissions: []string{"compute.instances.get", "compute.instances.list", "compute.instances.setTags", "compute.firewalls.get", "compute.firewalls.create", "compute.networks.updatePolicy", "compute.globalOperations.get", "compute.zoneOperations.get"},
+	})
+}
+
+// quarantineTag is the network tag added to quarantined instances.
+const quarantineTag = "sra-quarantine"
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// ProjectID is the project of the instance.
+	ProjectID string
+	// IPAddress is the internal IP address used to find the instance if Zone and Instance are not set.
+	IPAddress string
+	// Zone and Instance identify the instance directly.
+	Zone, Instance string
+	DryRun         bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Host     *services.Host
+	Firewall *services.Firewall
+	Resource *services.Resource
+	Logger   *services.Logger
+}
+
+// Execute quarantines the instance by tagging it and denying all traffic to and from the tag.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	zone, instance := values.Zone, values.Instance
+	if instance == "" {
+		if values.IPAddress == "" {
+			return fmt.Errorf("no instance or ip address to quarantine")
+		}
+		i, err := services.Host.InstanceByInternalIP(ctx, values.ProjectID, values.IPAddress)
+		if err != nil {
+			return err
+		}
+		zone, instance = lastSegment(i.Zone), i.Name
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have quarantined instance %q in zone %q in project %q", instance, zone, values.ProjectID)
+		return nil
+	}
+	i, err := services.Host.Instance(ctx, values.ProjectID, zone, instance)
+	if err != nil {
+		return err
+	}
+	// The tag is added once the quarantine rules are in place.
+	if i.Tags != nil {
+		for _, tag := range i.Tags.Items {
+			if tag == quarantineTag {
+				return registry.ErrAlreadyRemediated
+			}
+		}
+	}
+	for _, nic := range i.NetworkInterfaces {
+		if err := services.Firewall.QuarantineRules(ctx, values.ProjectID, nic.Network, quarantineTag); err != nil {
+			return errors.Wrapf(err, "failed to add quarantine rules to %q", nic.Network)
+		}
+	}
+	if err := services.Host.AddInstanceTag(ctx, values.ProjectID, zone, instance, quarantineTag); err != nil {
+		return err
+	}
+	services.Logger.Info("quarantined instance %q in zone %q in project %q", instance, zone, values.ProjectID)
+	return nil
+}
+
+// lastSegment returns the name at the end of a resource URL.
+func lastSegment(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
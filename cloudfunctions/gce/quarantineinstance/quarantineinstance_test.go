@@ -0,0 +1,147 @@
+package quarantineinstance
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestQuarantineInstance(t *testing.T) {
+	ctx := context.Background()
+	const network = "https://www.googleapis.com/compute/v1/projects/project-id/global/networks/default"
+	instance := &compute.Instance{
+		Name: "miner",
+		Zone: "https://www.googleapis.com/compute/v1/projects/project-id/zones/us-central1-a",
+		NetworkInterfaces: []*compute.NetworkInterface{
+			{Network: network, NetworkIP: "10.128.0.2"},
+		},
+		Tags: &compute.Tags{Items: []string{"http-server"}, Fingerprint: "abc"},
+	}
+	test := []struct {
+		name          string
+		values        *Values
+		expectedTags  *compute.Tags
+		expectedRules []*compute.Firewall
+		expectError   bool
+	}{
+		{
+			name:         "quarantine by ip",
+			values:       &Values{ProjectID: "project-id", IPAddress: "10.128.0.2"},
+			expectedTags: &compute.Tags{Items: []string{"http-server", "sra-quarantine"}, Fingerprint: "abc"},
+			expectedRules: []*compute.Firewall{
+				{
+					Denied:          []*compute.FirewallDenied{{IPProtocol: "all"}},
+					Description:     "Isolate quarantined instances by Security Response Automation",
+					Direction:       "INGRESS",
+					Name:            "automatic-quarantine-ingress",
+					Network:         network,
+					SourceRanges:    []string{"0.0.0.0/0"},
+					TargetTags:      []string{"sra-quarantine"},
+					ForceSendFields: []string{"Priority"},
+				},
+				{
+					Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+					Description:       "Isolate quarantined instances by Security Response Automation",
+					Direction:         "EGRESS",
+					DestinationRanges: []string{"0.0.0.0/0"},
+					Name:              "automatic-quarantine-egress",
+					Network:           network,
+					TargetTags:        []string{"sra-quarantine"},
+					ForceSendFields:   []string{"Priority"},
+				},
+			},
+		},
+		{
+			name:        "unknown ip",
+			values:      &Values{ProjectID: "project-id", IPAddress: "10.128.0.99"},
+			expectError: true,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "project-id", IPAddress: "10.128.0.2", DryRun: true},
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			svcs, computeStub := setupQuarantineInstance()
+			computeStub.StubbedInstances = []*compute.Instance{instance}
+			computeStub.StubbedInstance = &compute.Instance{
+				Name:              instance.Name,
+				Zone:              instance.Zone,
+				NetworkInterfaces: instance.NetworkInterfaces,
+				Tags:              &compute.Tags{Items: []string{"http-server"}, Fingerprint: "abc"},
+			}
+			computeStub.FirewallRuleNotFound = true
+			err := Execute(ctx, tt.values, &Services{
+				Host:     svcs.Host,
+				Firewall: svcs.Firewall,
+				Resource: svcs.Resource,
+				Logger:   svcs.Logger,
+			})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s failed got:%q expectError:%t", tt.name, err, tt.expectError)
+			}
+			if diff := cmp.Diff(tt.expectedTags, computeStub.SavedInstanceTags); diff != "" {
+				t.Errorf("%s failed, tags difference: %+v", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.expectedRules, computeStub.InsertedFirewallRules); diff != "" {
+				t.Errorf("%s failed, rules difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestAlreadyQuarantined(t *testing.T) {
+	svcs, computeStub := setupQuarantineInstance()
+	computeStub.StubbedInstance = &compute.Instance{
+		Name: "miner",
+		Tags: &compute.Tags{Items: []string{"http-server", "sra-quarantine"}, Fingerprint: "abc"},
+	}
+	values := &Values{ProjectID: "project-id", Zone: "us-central1-a", Instance: "miner"}
+	err := Execute(context.Background(), values, &Services{
+		Host:     svcs.Host,
+		Firewall: svcs.Firewall,
+		Resource: svcs.Resource,
+		Logger:   svcs.Logger,
+	})
+	if err != registry.ErrAlreadyRemediated {
+		t.Errorf("got %q, want %q", err, registry.ErrAlreadyRemediated)
+	}
+	if computeStub.SavedInstanceTags != nil || computeStub.InsertedFirewallRules != nil {
+		t.Errorf("quarantined instance quarantined again")
+	}
+}
+
+func setupQuarantineInstance() (*services.Global, *stubs.ComputeStub) {
+	loggerStub := &stubs.LoggerStub{}
+	log := services.NewLogger(loggerStub)
+	computeStub := &stubs.ComputeStub{}
+	storageStub := &stubs.StorageStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	res := services.NewResource(crmStub, storageStub)
+	return &services.Global{
+		Logger:   log,
+		Host:     services.NewHost(computeStub),
+		Firewall: services.NewFirewall(computeStub),
+		Resource: res,
+	}, computeStub
+}
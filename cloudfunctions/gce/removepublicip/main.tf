@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "remove-public-ip" {
     resource   = "threat-findings-remove-public-ip"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "remove_public_ip", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_remove_public_ip"
   }
 }
 
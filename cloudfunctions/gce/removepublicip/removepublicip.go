@@ -17,10 +17,22 @@ package removepublicip
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	"github.com/pkg/errors"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "remove_public_ip",
+		EntryPoint:  "RemovePublicIP",
+		Topic:       "threat-findings-remove-public-ip",
+		Services:    []string{"Host", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/compute.instanceAdmin.v1"},
+		Permissions: []string{"compute.instances.get", "compute.instances.deleteAccessConfig", "compute.zoneOperations.get"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID, InstanceZone, InstanceID string
@@ -40,6 +52,13 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 		services.Logger.Info("dry_run on, would have removed public IP address for instance %q, in zone %q in project %q.", values.InstanceID, values.ProjectID)
 		return nil
 	}
+	found, err := services.Host.HasExternalIPs(ctx, values.ProjectID, values.InstanceZone, values.InstanceID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return registry.ErrAlreadyRemediated
+	}
 	if err := services.Host.RemoveExternalIPs(ctx, values.ProjectID, values.InstanceZone, values.InstanceID); err != nil {
 		return errors.Wrap(err, "failed to remove public ip")
 	}
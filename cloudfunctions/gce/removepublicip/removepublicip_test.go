@@ -22,6 +22,7 @@ import (
 	compute "google.golang.org/api/compute/v1"
 
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
@@ -43,6 +44,7 @@ func TestRemovePublicIP(t *testing.T) {
 		name                         string
 		instance                     *compute.Instance
 		expectedDeletedAccessConfigs []stubs.NetworkAccessConfigStub
+		expectedError                error
 	}{
 		{
 			name: "remove public ip",
@@ -58,6 +60,15 @@ func TestRemovePublicIP(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "no public ip",
+			instance: &compute.Instance{
+				NetworkInterfaces: []*compute.NetworkInterface{
+					{Name: "nic0"},
+				},
+			},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
 	}
 	for _, tt := range test {
 		t.Run(tt.name, func(t *testing.T) {
@@ -73,7 +84,7 @@ func TestRemovePublicIP(t *testing.T) {
 				Host:     svcs.Host,
 				Resource: svcs.Resource,
 				Logger:   svcs.Logger,
-			}); err != nil {
+			}); err != tt.expectedError {
 				t.Errorf("%s failed to remove public ip :%q", tt.name, err)
 			}
 
@@ -0,0 +1,71 @@
+package requireoslogin
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "gce_require_os_login",
+		EntryPoint:  "RequireOSLogin",
+		Topic:       "threat-findings-require-os-login",
+		Services:    []string{"OrgPolicy"},
+		Roles:       []string{"roles/orgpolicy.policyAdmin"},
+		Permissions: []string{"orgpolicy.policy.get", "orgpolicy.policy.set"},
+	})
+}
+
+// constraint requires OS Login on every instance of the project, ignoring SSH keys in metadata.
+const constraint = "constraints/compute.requireOsLogin"
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	DryRun    bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	OrgPolicy *services.OrgPolicy
+	Logger    *services.Logger
+}
+
+// Execute enforces OS Login on the project through its organization policy, so SSH keys added to
+// the metadata of the project or of its instances no longer grant access.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	resource := "projects/" + values.ProjectID
+	enforced, err := services.OrgPolicy.Enforced(ctx, resource, constraint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %q of %q", constraint, resource)
+	}
+	if enforced {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have enforced %q on %q", constraint, resource)
+		return nil
+	}
+	if err := services.OrgPolicy.EnforceConstraint(ctx, resource, constraint); err != nil {
+		return errors.Wrapf(err, "failed to enforce %q on %q", constraint, resource)
+	}
+	services.Logger.Info("enforced %q on %q", constraint, resource)
+	return nil
+}
@@ -0,0 +1,73 @@
+package requireoslogin
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestRequireOSLogin(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		effective     *crm.OrgPolicy
+		dryRun        bool
+		expected      *crm.OrgPolicy
+		expectedError error
+	}{
+		{
+			name:     "enforce",
+			expected: &crm.OrgPolicy{Constraint: constraint, Etag: "etag-1", BooleanPolicy: &crm.BooleanPolicy{Enforced: true}},
+		},
+		{
+			name:          "enforced by an ancestor",
+			effective:     &crm.OrgPolicy{Constraint: constraint, BooleanPolicy: &crm.BooleanPolicy{Enforced: true}},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.OrgPolicyStub{
+				Policies: map[string]*crm.OrgPolicy{constraint: {Constraint: constraint, Etag: "etag-1"}},
+			}
+			if tt.effective != nil {
+				stub.EffectivePolicies = map[string]*crm.OrgPolicy{constraint: tt.effective}
+			}
+			values := &Values{ProjectID: "test-project", DryRun: tt.dryRun}
+			if err := Execute(ctx, values, &Services{
+				OrgPolicy: services.NewOrgPolicy(stub),
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.expected, stub.SavedPolicy); diff != "" {
+				t.Errorf("%s policy (-want +got):\n%s", tt.name, diff)
+			}
+			if tt.expected != nil && stub.SavedResource != "projects/test-project" {
+				t.Errorf("%s set the policy of %q, want %q", tt.name, stub.SavedResource, "projects/test-project")
+			}
+		})
+	}
+}
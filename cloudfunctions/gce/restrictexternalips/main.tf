@@ -0,0 +1,62 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "restrict-external-ips" {
+  name                  = "RestrictExternalIPs"
+  description           = "Restricts the instances allowed external IP addresses after repeated public IP findings."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "RestrictExternalIPs"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-restrict-external-ips"
+  }
+  environment_variables = {
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "gce_restrict_external_ips", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_gce_restrict_external_ips"
+  }
+}
+
+# Required by RestrictExternalIPs to set the organization policies of projects and folders, only
+# granted on the organization.
+resource "google_organization_iam_member" "restrict-external-ips-policy-admin-bind" {
+  org_id = var.setup.organization-id
+  role   = "roles/orgpolicy.policyAdmin"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Required to retrieve the folder of projects within this folder.
+resource "google_folder_iam_member" "restrict-external-ips-viewer-folder-bind" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/viewer"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# PubSub topic to trigger this automation.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-restrict-external-ips"
+  project = var.setup.automation-project
+}
@@ -0,0 +1,169 @@
+package restrictexternalips
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// action is the name of this action, whose earlier remediations of a project are counted.
+const action = "gce_restrict_external_ips"
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        action,
+		EntryPoint:  "RestrictExternalIPs",
+		Topic:       "threat-findings-restrict-external-ips",
+		Services:    []string{"OrgPolicy", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/orgpolicy.policyAdmin"},
+		Permissions: []string{"resourcemanager.projects.get", "orgpolicy.policy.get", "orgpolicy.policy.set"},
+	})
+}
+
+// constraint lists the instances allowed external IP addresses.
+const constraint = "constraints/compute.vmExternalIpAccess"
+
+const (
+	// ScopeProject sets the constraint on the project of the finding, the default.
+	ScopeProject = "project"
+	// ScopeFolder sets the constraint on the folder the project is directly in.
+	ScopeFolder = "folder"
+)
+
+// defaultWindowHours is how far back findings are counted if the window is not set, a week.
+const defaultWindowHours = 7 * 24
+
+// now returns the current time, replaced by tests.
+var now = time.Now
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// Scope is where the constraint is set, ScopeProject if empty.
+	Scope string
+	// AllowedInstances keep their external IP addresses, named
+	// "projects/<id>/zones/<zone>/instances/<name>". Every instance is denied one if empty.
+	AllowedInstances []string
+	// Threshold is the number of findings of the project within the window the constraint is set
+	// after, from the first finding if zero.
+	Threshold int
+	// WindowHours is how far back findings are counted, a week if zero.
+	WindowHours int
+	DryRun      bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	OrgPolicy *services.OrgPolicy
+	Resource  *services.Resource
+	// History holds the earlier findings of the project, required by a threshold above one.
+	History *services.State
+	Logger  *services.Logger
+}
+
+// Execute restricts the instances allowed external IP addresses on the project or its folder once
+// the project had as many findings as the threshold within the window. Instances already holding
+// an external IP address keep it, the constraint only applies to those created or updated after.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.Threshold > 1 {
+		n, err := findings(ctx, values, services.History)
+		if err != nil {
+			return err
+		}
+		if n < values.Threshold {
+			services.Logger.Info("%d of %d findings in project %q, not restricting external IPs yet", n, values.Threshold, values.ProjectID)
+			return registry.ErrPending
+		}
+	}
+	resource, err := target(ctx, values, services.Resource)
+	if err != nil {
+		return err
+	}
+	restricted, err := services.OrgPolicy.Restricted(ctx, resource, constraint, values.AllowedInstances)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %q of %q", constraint, resource)
+	}
+	if restricted {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have restricted %q on %q to %q", constraint, resource, values.AllowedInstances)
+		return nil
+	}
+	if len(values.AllowedInstances) == 0 {
+		err = services.OrgPolicy.DenyAll(ctx, resource, constraint)
+	} else {
+		err = services.OrgPolicy.AllowValues(ctx, resource, constraint, values.AllowedInstances)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to restrict %q on %q", constraint, resource)
+	}
+	services.Logger.Info("restricted %q on %q to %q", constraint, resource, values.AllowedInstances)
+	return nil
+}
+
+// findings returns the number of findings of the project remediated by this action within the
+// window, this one included. Remediations run without a finding are counted each.
+func findings(ctx context.Context, values *Values, history *services.State) (int, error) {
+	if history == nil {
+		return 0, fmt.Errorf("threshold of %d requires the remediation history", values.Threshold)
+	}
+	hours := values.WindowHours
+	if hours <= 0 {
+		hours = defaultWindowHours
+	}
+	l, err := history.Query(ctx, services.HistoryQuery{
+		Action:    action,
+		ProjectID: values.ProjectID,
+		Since:     now().Add(-time.Duration(hours) * time.Hour),
+	})
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool)
+	n := 0
+	for _, r := range l {
+		if r.Finding != "" && seen[r.Finding] {
+			continue
+		}
+		seen[r.Finding] = true
+		n++
+	}
+	return n, nil
+}
+
+// target returns the project or the folder the constraint is set on.
+func target(ctx context.Context, values *Values, resource *services.Resource) (string, error) {
+	switch values.Scope {
+	case "", ScopeProject:
+		return "projects/" + values.ProjectID, nil
+	case ScopeFolder:
+		folders, err := resource.Folders(ctx, values.ProjectID)
+		if err != nil {
+			return "", err
+		}
+		if len(folders) == 0 {
+			return "", fmt.Errorf("project %q is not in a folder", values.ProjectID)
+		}
+		return "folders/" + folders[0], nil
+	}
+	return "", fmt.Errorf("unknown scope %q", values.Scope)
+}
@@ -0,0 +1,159 @@
+package restrictexternalips
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestRestrictExternalIPs(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+	const bastion = "projects/test-project/zones/us-central1-a/instances/bastion"
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		earlier       []time.Time
+		effective     *crm.OrgPolicy
+		wantResource  string
+		wantPolicy    *crm.OrgPolicy
+		expectedError error
+	}{
+		{
+			name:         "deny all",
+			values:       &Values{ProjectID: "test-project"},
+			wantResource: "projects/test-project",
+			wantPolicy:   &crm.OrgPolicy{Constraint: constraint, Etag: "etag-1", ListPolicy: &crm.ListPolicy{AllValues: "DENY"}},
+		},
+		{
+			name:         "allowlist on folder",
+			values:       &Values{ProjectID: "test-project", Scope: ScopeFolder, AllowedInstances: []string{bastion}},
+			wantResource: "folders/123",
+			wantPolicy:   &crm.OrgPolicy{Constraint: constraint, Etag: "etag-1", ListPolicy: &crm.ListPolicy{AllowedValues: []string{bastion}}},
+		},
+		{
+			name:          "below threshold",
+			values:        &Values{ProjectID: "test-project", Threshold: 3},
+			earlier:       []time.Time{at.Add(-time.Hour), at.Add(-8 * 24 * time.Hour)},
+			expectedError: registry.ErrPending,
+		},
+		{
+			name:         "threshold reached",
+			values:       &Values{ProjectID: "test-project", Threshold: 3},
+			earlier:      []time.Time{at.Add(-time.Hour), at.Add(-2 * time.Hour)},
+			wantResource: "projects/test-project",
+			wantPolicy:   &crm.OrgPolicy{Constraint: constraint, Etag: "etag-1", ListPolicy: &crm.ListPolicy{AllValues: "DENY"}},
+		},
+		{
+			name:          "already restricted",
+			values:        &Values{ProjectID: "test-project"},
+			effective:     &crm.OrgPolicy{Constraint: constraint, ListPolicy: &crm.ListPolicy{AllValues: "DENY"}},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "test-project", DryRun: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			history := services.NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+			// The remediation of this finding is claimed before the action runs.
+			started := append(tt.earlier, at)
+			for i, s := range started {
+				r := &services.Remediation{
+					ID:       fmt.Sprintf("remediation-%d", i),
+					Finding:  fmt.Sprintf("organizations/456/sources/789/findings/%d", i),
+					Action:   action,
+					Resource: "projects/test-project/zones/us-central1-a/instances/web",
+					Outcome:  services.RemediationPending,
+					Started:  s,
+				}
+				if err := history.Save(ctx, r); err != nil {
+					t.Fatalf("failed to save remediation: %q", err)
+				}
+			}
+			policyStub := &stubs.OrgPolicyStub{
+				Policies: map[string]*crm.OrgPolicy{constraint: {Constraint: constraint, Etag: "etag-1"}},
+			}
+			if tt.effective != nil {
+				policyStub.EffectivePolicies = map[string]*crm.OrgPolicy{constraint: tt.effective}
+			}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			if err := Execute(ctx, tt.values, &Services{
+				OrgPolicy: services.NewOrgPolicy(policyStub),
+				Resource:  services.NewResource(crmStub, &stubs.StorageStub{}),
+				History:   history,
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantPolicy, policyStub.SavedPolicy); diff != "" {
+				t.Errorf("%s policy (-want +got):\n%s", tt.name, diff)
+			}
+			if policyStub.SavedResource != tt.wantResource {
+				t.Errorf("%s set the policy of %q, want %q", tt.name, policyStub.SavedResource, tt.wantResource)
+			}
+		})
+	}
+}
+
+func TestThresholdReadsWindow(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+	fs := &stubs.FirestoreStub{}
+	history := services.NewState(fs, "automation-project", "remediations")
+	// A month of findings a day, only this one and the one an hour earlier are in the window.
+	for i := 0; i < 30; i++ {
+		started := at.Add(-time.Duration(i) * 24 * time.Hour)
+		if i == 1 {
+			started = at.Add(-time.Hour)
+		}
+		r := &services.Remediation{
+			ID:       fmt.Sprintf("remediation-%d", i),
+			Finding:  fmt.Sprintf("organizations/456/sources/789/findings/%d", i),
+			Action:   action,
+			Resource: "projects/test-project/zones/us-central1-a/instances/web",
+			Outcome:  services.RemediationPending,
+			Started:  started,
+		}
+		if err := history.Save(ctx, r); err != nil {
+			t.Fatalf("failed to save remediation: %q", err)
+		}
+	}
+	n, err := findings(ctx, &Values{ProjectID: "test-project", Threshold: 3, WindowHours: 2}, history)
+	if err != nil {
+		t.Fatalf("findings failed: %q", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d findings, want 2", n)
+	}
+	if fs.ReadDocuments != 2 {
+		t.Errorf("read %d remediations, want only the 2 of the window", fs.ReadDocuments)
+	}
+}
@@ -17,9 +17,21 @@ package closebucket
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "close_bucket",
+		EntryPoint:  "CloseBucket",
+		Topic:       "threat-findings-close-bucket",
+		Services:    []string{"Resource"},
+		Roles:       []string{"roles/viewer", "roles/storage.admin"},
+		Permissions: []string{"storage.buckets.get", "storage.buckets.getIamPolicy", "storage.buckets.setIamPolicy", "storage.objects.list", "storage.objects.getIamPolicy", "storage.objects.setIamPolicy"},
+	})
+}
+
 // publicUsers contains a slice of public users we want to remove.
 var publicUsers = []string{"allUsers", "allAuthenticatedUsers"}
 
@@ -42,9 +54,25 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 		services.Logger.Info("dry_run on, would have removed public members from bucket %q in project %q", values.BucketName, values.ProjectID)
 		return nil
 	}
-	if err := services.Resource.RemoveMembersFromBucket(ctx, values.BucketName, publicUsers); err != nil {
+	found, err := services.Resource.BucketHasMembers(ctx, values.BucketName, publicUsers)
+	if err != nil {
 		return err
 	}
-	services.Logger.Info("removed public members from bucket %q in project %q", values.BucketName, values.ProjectID)
+	if found {
+		if err := services.Resource.RemoveMembersFromBucket(ctx, values.BucketName, publicUsers); err != nil {
+			return err
+		}
+		services.Logger.Info("removed public members from bucket %q in project %q", values.BucketName, values.ProjectID)
+	}
+	removed, err := services.Resource.RemoveMembersFromObjectACLs(ctx, values.BucketName, publicUsers)
+	if removed > 0 {
+		services.Logger.Info("removed %d public entries from the object ACLs of bucket %q in project %q", removed, values.BucketName, values.ProjectID)
+	}
+	if err != nil {
+		return err
+	}
+	if !found && removed == 0 {
+		return registry.ErrAlreadyRemediated
+	}
 	return nil
 }
@@ -16,11 +16,13 @@ package closebucket
 
 import (
 	"context"
+	"sort"
 	"testing"
 
 	"cloud.google.com/go/iam"
 	"github.com/google/go-cmp/cmp"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
@@ -30,13 +32,30 @@ func TestCloseBucket(t *testing.T) {
 	test := []struct {
 		name           string
 		initialMembers []string
+		objectACLs     []map[string]map[string]string
 		expected       []string
+		expectedACLs   []string
+		expectedError  error
 	}{
 		{
 			name:           "remove allUsers",
 			initialMembers: []string{"allUsers", "member:tom@tom.com"},
 			expected:       []string{"member:tom@tom.com"},
 		},
+		{
+			name:           "public objects",
+			initialMembers: []string{"member:tom@tom.com"},
+			objectACLs: []map[string]map[string]string{
+				{"a.txt": {"allUsers": "READER", "user-tom@tom.com": "OWNER"}, "b.txt": {"user-tom@tom.com": "OWNER"}},
+				{"c.txt": {"allAuthenticatedUsers": "READER", "allUsers": "READER"}},
+			},
+			expectedACLs: []string{"a.txt/allUsers", "c.txt/allAuthenticatedUsers", "c.txt/allUsers"},
+		},
+		{
+			name:           "already private",
+			initialMembers: []string{"member:tom@tom.com"},
+			expectedError:  registry.ErrAlreadyRemediated,
+		},
 	}
 	for _, tt := range test {
 		t.Run(tt.name, func(t *testing.T) {
@@ -44,6 +63,7 @@ func TestCloseBucket(t *testing.T) {
 			for _, v := range tt.initialMembers {
 				storageStub.BucketPolicyResponse.Add(v, "project/viewer")
 			}
+			storageStub.ObjectACLPages = tt.objectACLs
 
 			required := &Values{
 				ProjectID:  "project-name",
@@ -53,10 +73,17 @@ func TestCloseBucket(t *testing.T) {
 			if err := Execute(ctx, required, &Services{
 				Resource: svcs.Resource,
 				Logger:   svcs.Logger,
-			}); err != nil {
-				t.Errorf("%s test failed want:%q", tt.name, err)
+			}); err != tt.expectedError {
+				t.Errorf("%s test failed want:%q got:%q", tt.name, tt.expectedError, err)
 			}
 
+			if tt.expected == nil && storageStub.RemoveBucketPolicy != nil {
+				t.Errorf("%v failed, policy of private bucket set", tt.name)
+			}
+			sort.Strings(storageStub.DeletedObjectACLs)
+			if diff := cmp.Diff(tt.expectedACLs, storageStub.DeletedObjectACLs); diff != "" {
+				t.Errorf("%v failed, deleted object ACLs (-want +got):\n%s", tt.name, diff)
+			}
 			if tt.expected != nil {
 				s := storageStub.RemoveBucketPolicy.Members("project/viewer")
 				if diff := cmp.Diff(s, tt.expected); diff != "" {
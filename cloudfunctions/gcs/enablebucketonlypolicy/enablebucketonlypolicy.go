@@ -17,9 +17,21 @@ package enablebucketonlypolicy
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "enable_bucket_only_policy",
+		EntryPoint:  "EnableBucketOnlyPolicy",
+		Topic:       "threat-findings-enable-bucket-only-policy",
+		Services:    []string{"Resource"},
+		Roles:       []string{"roles/viewer", "roles/storage.admin"},
+		Permissions: []string{"storage.buckets.get", "storage.buckets.update"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	BucketName string
@@ -39,6 +51,13 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 		services.Logger.Info("dry_run on, would have enabled Bucket only policy on bucket %q in project %q.", values.BucketName, values.ProjectID)
 		return nil
 	}
+	enabled, err := services.Resource.BucketPolicyOnlyEnabled(ctx, values.BucketName)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		return registry.ErrAlreadyRemediated
+	}
 	if err := services.Resource.EnableBucketOnlyPolicy(ctx, values.BucketName); err != nil {
 		return err
 	}
@@ -20,6 +20,7 @@ import (
 
 	"cloud.google.com/go/iam"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
@@ -27,17 +28,25 @@ func TestEnableBucketOnlyPolicy(t *testing.T) {
 	ctx := context.Background()
 
 	test := []struct {
-		name     string
-		expected string
+		name          string
+		enabled       bool
+		expected      string
+		expectedError error
 	}{
 		{
 			name:     "enable bucket only policy",
 			expected: "bucket-to-enable-policy",
 		},
+		{
+			name:          "already enabled",
+			enabled:       true,
+			expectedError: registry.ErrAlreadyRemediated,
+		},
 	}
 	for _, tt := range test {
 		t.Run(tt.name, func(t *testing.T) {
 			svcs, storageStub := enableBucketOnlyPolicySetup()
+			storageStub.BucketPolicyOnlyEnabled = tt.enabled
 			values := &Values{
 				ProjectID:  "project-name",
 				BucketName: "bucket-to-enable-policy",
@@ -46,14 +55,12 @@ func TestEnableBucketOnlyPolicy(t *testing.T) {
 			if err := Execute(ctx, values, &Services{
 				Resource: svcs.Resource,
 				Logger:   svcs.Logger,
-			}); err != nil {
-				t.Errorf("%s test failed want:%q", tt.name, err)
+			}); err != tt.expectedError {
+				t.Errorf("%s test failed want:%q got:%q", tt.name, tt.expectedError, err)
 			}
 
-			if tt.expected != "" {
-				if s := storageStub.EnabledPolicyOnBucket; s != tt.expected {
-					t.Errorf("%v failed exp:%v got:%v", tt.name, tt.expected, s)
-				}
+			if s := storageStub.EnabledPolicyOnBucket; s != tt.expected {
+				t.Errorf("%v failed exp:%v got:%v", tt.name, tt.expected, s)
 			}
 		})
 	}
@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "enable-bucket-only-policy" {
     resource   = "threat-findings-enable-bucket-only-policy"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "enable_bucket_only_policy", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_enable_bucket_only_policy"
   }
 }
 
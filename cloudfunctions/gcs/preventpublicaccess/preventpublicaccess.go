@@ -0,0 +1,162 @@
+package preventpublicaccess
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// action is the name of this action, whose earlier remediations within a folder are counted.
+const action = "gcs_prevent_public_access"
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        action,
+		EntryPoint:  "PreventPublicAccess",
+		Topic:       "threat-findings-prevent-public-access",
+		Services:    []string{"OrgPolicy", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/orgpolicy.policyAdmin"},
+		Permissions: []string{"resourcemanager.projects.get", "orgpolicy.policy.get", "orgpolicy.policy.set"},
+	})
+}
+
+// constraint prevents buckets from being shared publicly, whatever their ACLs and IAM policies.
+const constraint = "constraints/storage.publicAccessPrevention"
+
+// defaultWindowHours is how far back findings are counted if the window is not set, a day.
+const defaultWindowHours = 24
+
+// now returns the current time, replaced by tests.
+var now = time.Now
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID  string
+	BucketName string
+	// Threshold is the number of public bucket findings within the folder in the window the
+	// constraint is set after, from the first finding if zero.
+	Threshold int
+	// WindowHours is how far back findings are counted, a day if zero.
+	WindowHours int
+	DryRun      bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	OrgPolicy *services.OrgPolicy
+	Resource  *services.Resource
+	// History holds the earlier findings of the folder, required by a threshold above one.
+	History *services.State
+	Logger  *services.Logger
+}
+
+// Execute enforces public access prevention on the folder the project is directly in once the
+// folder had as many public bucket findings as the threshold within the window. Buckets already
+// public are closed as well, as the constraint applies to every bucket of the folder.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	folders, err := services.Resource.Folders(ctx, values.ProjectID)
+	if err != nil {
+		return err
+	}
+	if len(folders) == 0 {
+		return fmt.Errorf("project %q is not in a folder", values.ProjectID)
+	}
+	folder := folders[0]
+	if values.Threshold > 1 {
+		n, err := findings(ctx, values, folder, services)
+		if err != nil {
+			return err
+		}
+		if n < values.Threshold {
+			services.Logger.Info("%d of %d findings in folder %q, not preventing public access yet", n, values.Threshold, folder)
+			return registry.ErrPending
+		}
+	}
+	resource := "folders/" + folder
+	enforced, err := services.OrgPolicy.Enforced(ctx, resource, constraint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %q of %q", constraint, resource)
+	}
+	if enforced {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have enforced %q on %q after bucket %q", constraint, resource, values.BucketName)
+		return nil
+	}
+	if err := services.OrgPolicy.EnforceConstraint(ctx, resource, constraint); err != nil {
+		return errors.Wrapf(err, "failed to enforce %q on %q", constraint, resource)
+	}
+	services.Logger.Info("enforced %q on %q after bucket %q", constraint, resource, values.BucketName)
+	return nil
+}
+
+// findings returns the number of findings of projects within the folder, at any depth, remediated
+// by this action within the window, this one included. Remediations run without a finding are
+// counted each.
+func findings(ctx context.Context, values *Values, folder string, svcs *Services) (int, error) {
+	if svcs.History == nil {
+		return 0, fmt.Errorf("threshold of %d requires the remediation history", values.Threshold)
+	}
+	hours := values.WindowHours
+	if hours <= 0 {
+		hours = defaultWindowHours
+	}
+	l, err := svcs.History.Query(ctx, services.HistoryQuery{
+		Action: action,
+		Since:  now().Add(-time.Duration(hours) * time.Hour),
+	})
+	if err != nil {
+		return 0, err
+	}
+	// within caches whether each project is in the folder, as most findings share a few projects.
+	within := map[string]bool{values.ProjectID: true}
+	seen := make(map[string]bool)
+	n := 0
+	for _, r := range l {
+		projectID := r.ProjectID()
+		in, ok := within[projectID]
+		if !ok && projectID != "" {
+			folders, err := svcs.Resource.Folders(ctx, projectID)
+			if err != nil {
+				return 0, err
+			}
+			in = contains(folders, folder)
+			within[projectID] = in
+		}
+		if !in || r.Finding != "" && seen[r.Finding] {
+			continue
+		}
+		seen[r.Finding] = true
+		n++
+	}
+	return n, nil
+}
+
+// contains returns whether the list holds the value.
+func contains(list []string, value string) bool {
+	for _, s := range list {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
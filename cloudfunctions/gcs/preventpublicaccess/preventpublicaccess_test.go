@@ -0,0 +1,123 @@
+package preventpublicaccess
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestPreventPublicAccess(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+	enforced := &crm.OrgPolicy{Constraint: constraint, Etag: "etag-1", BooleanPolicy: &crm.BooleanPolicy{Enforced: true}}
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		ancestry      []string
+		earlier       []time.Time
+		effective     *crm.OrgPolicy
+		wantResource  string
+		wantPolicy    *crm.OrgPolicy
+		expectedError error
+	}{
+		{
+			name:         "enforce on folder",
+			values:       &Values{ProjectID: "test-project", BucketName: "public-bucket"},
+			ancestry:     []string{"project/test-project", "folder/123", "folder/789", "organization/456"},
+			wantResource: "folders/123",
+			wantPolicy:   enforced,
+		},
+		{
+			name:          "below threshold",
+			values:        &Values{ProjectID: "test-project", BucketName: "public-bucket", Threshold: 3},
+			ancestry:      []string{"project/test-project", "folder/123", "organization/456"},
+			earlier:       []time.Time{at.Add(-time.Hour), at.Add(-25 * time.Hour)},
+			expectedError: registry.ErrPending,
+		},
+		{
+			name:         "threshold reached",
+			values:       &Values{ProjectID: "test-project", BucketName: "public-bucket", Threshold: 3},
+			ancestry:     []string{"project/test-project", "folder/123", "organization/456"},
+			earlier:      []time.Time{at.Add(-time.Hour), at.Add(-2 * time.Hour)},
+			wantResource: "folders/123",
+			wantPolicy:   enforced,
+		},
+		{
+			name:          "already enforced",
+			values:        &Values{ProjectID: "test-project", BucketName: "public-bucket"},
+			ancestry:      []string{"project/test-project", "folder/123", "organization/456"},
+			effective:     enforced,
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:     "dry run",
+			values:   &Values{ProjectID: "test-project", BucketName: "public-bucket", DryRun: true},
+			ancestry: []string{"project/test-project", "folder/123", "organization/456"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			history := services.NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+			// The remediation of this finding is claimed before the action runs, the earlier ones are
+			// of other projects in the same folder.
+			started := append(tt.earlier, at)
+			for i, s := range started {
+				r := &services.Remediation{
+					ID:       fmt.Sprintf("remediation-%d", i),
+					Finding:  fmt.Sprintf("organizations/456/sources/789/findings/%d", i),
+					Action:   action,
+					Resource: fmt.Sprintf("projects/project-%d/buckets/public-bucket", i),
+					Outcome:  services.RemediationPending,
+					Started:  s,
+				}
+				if err := history.Save(ctx, r); err != nil {
+					t.Fatalf("failed to save remediation: %q", err)
+				}
+			}
+			policyStub := &stubs.OrgPolicyStub{
+				Policies: map[string]*crm.OrgPolicy{constraint: {Constraint: constraint, Etag: "etag-1"}},
+			}
+			if tt.effective != nil {
+				policyStub.EffectivePolicies = map[string]*crm.OrgPolicy{constraint: tt.effective}
+			}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors(tt.ancestry)
+			if err := Execute(ctx, tt.values, &Services{
+				OrgPolicy: services.NewOrgPolicy(policyStub),
+				Resource:  services.NewResource(crmStub, &stubs.StorageStub{}),
+				History:   history,
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantPolicy, policyStub.SavedPolicy); diff != "" {
+				t.Errorf("%s policy (-want +got):\n%s", tt.name, diff)
+			}
+			if policyStub.SavedResource != tt.wantResource {
+				t.Errorf("%s set the policy of %q, want %q", tt.name, policyStub.SavedResource, tt.wantResource)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package cordonnode
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "gke_cordon_node",
+		EntryPoint:  "CordonNode",
+		Topic:       "threat-findings-cordon-node",
+		Services:    []string{"Kubernetes", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/container.admin"},
+		Permissions: []string{"container.clusters.get", "container.nodes.get", "container.nodes.update"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID, Location, ClusterID, Node string
+	DryRun                               bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Kubernetes *services.Kubernetes
+	Resource   *services.Resource
+	Logger     *services.Logger
+}
+
+// Execute cordons the node so no new workloads are scheduled on it.
+func Execute(ctx context.Context, values *Values, service *Services) error {
+	if values.DryRun {
+		service.Logger.Info("dry_run on, would have cordoned node %q of cluster %q in project %q", values.Node, values.ClusterID, values.ProjectID)
+		return nil
+	}
+	if err := service.Kubernetes.CordonNode(ctx, values.ProjectID, values.Location, values.ClusterID, values.Node); err != nil {
+		return err
+	}
+	service.Logger.Info("successfully cordoned node %q of cluster %q in project %q", values.Node, values.ClusterID, values.ProjectID)
+	return nil
+}
@@ -0,0 +1,86 @@
+package cordonnode
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestCordonNode(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name            string
+		node            string
+		dryRun          bool
+		expectError     bool
+		expectedPatched map[string][]byte
+	}{
+		{
+			name: "cordon node",
+			node: "gke-test-cluster-default-pool-1234",
+			expectedPatched: map[string][]byte{
+				"/api/v1/nodes/gke-test-cluster-default-pool-1234": []byte(`{"spec":{"unschedulable":true}}`),
+			},
+		},
+		{
+			name:   "dry run",
+			node:   "gke-test-cluster-default-pool-1234",
+			dryRun: true,
+		},
+		{
+			name:        "invalid node name",
+			node:        "../namespaces/kube-system",
+			expectError: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			svcs, kubeStub := cordonNodeSetup()
+			values := &Values{
+				ProjectID: "project-test",
+				Location:  "us-central1-a",
+				ClusterID: "test-cluster",
+				Node:      tt.node,
+				DryRun:    tt.dryRun,
+			}
+			if err := Execute(ctx, values, &Services{
+				Kubernetes: svcs.Kubernetes,
+				Resource:   svcs.Resource,
+				Logger:     svcs.Logger,
+			}); (err != nil) != tt.expectError {
+				t.Errorf("%s test failed got:%q expectError:%t", tt.name, err, tt.expectError)
+			}
+			if diff := cmp.Diff(tt.expectedPatched, kubeStub.Patched); diff != "" {
+				t.Errorf("%v failed, patched difference: %v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func cordonNodeSetup() (*services.Global, *stubs.KubernetesStub) {
+	loggerStub := &stubs.LoggerStub{}
+	log := services.NewLogger(loggerStub)
+	kubeStub := &stubs.KubernetesStub{}
+	kube := services.NewKubernetes(kubeStub)
+	crmStub := &stubs.ResourceManagerStub{}
+	storageStub := &stubs.StorageStub{}
+	resource := services.NewResource(crmStub, storageStub)
+	return &services.Global{Logger: log, Resource: resource, Kubernetes: kube}, kubeStub
+}
@@ -17,9 +17,21 @@ package disabledashboard
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "disable_dashboard",
+		EntryPoint:  "DisableDashboard",
+		Topic:       "threat-findings-disable-dashboard",
+		Services:    []string{"Container", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/container.clusterAdmin"},
+		Permissions: []string{"container.clusters.get", "container.clusters.update", "container.operations.get"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID, Zone, ClusterID string
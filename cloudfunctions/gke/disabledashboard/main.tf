@@ -30,7 +30,12 @@ resource "google_cloudfunctions_function" "disable-dashboard" {
     resource   = "threat-findings-disable-dashboard"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "disable_dashboard", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_disable_dashboard"
   }
 }
 
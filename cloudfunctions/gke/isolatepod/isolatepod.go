@@ -0,0 +1,59 @@
+package isolatepod
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "gke_isolate_pod",
+		EntryPoint:  "IsolatePod",
+		Topic:       "threat-findings-isolate-pod",
+		Services:    []string{"Kubernetes", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/container.developer"},
+		Permissions: []string{"container.clusters.get", "container.pods.get", "container.pods.update", "container.networkPolicies.get", "container.networkPolicies.create"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID, Location, ClusterID, Namespace, Pod string
+	DryRun                                         bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Kubernetes *services.Kubernetes
+	Resource   *services.Resource
+	Logger     *services.Logger
+}
+
+// Execute isolates the pod with a deny all network policy.
+func Execute(ctx context.Context, values *Values, service *Services) error {
+	if values.DryRun {
+		service.Logger.Info("dry_run on, would have isolated pod %q in namespace %q of cluster %q in project %q", values.Pod, values.Namespace, values.ClusterID, values.ProjectID)
+		return nil
+	}
+	if err := service.Kubernetes.IsolatePod(ctx, values.ProjectID, values.Location, values.ClusterID, values.Namespace, values.Pod); err != nil {
+		return err
+	}
+	service.Logger.Info("successfully isolated pod %q in namespace %q of cluster %q in project %q", values.Pod, values.Namespace, values.ClusterID, values.ProjectID)
+	return nil
+}
@@ -0,0 +1,89 @@
+package isolatepod
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestIsolatePod(t *testing.T) {
+	ctx := context.Background()
+	const (
+		policyPath = "/apis/networking.k8s.io/v1/namespaces/default/networkpolicies"
+		podPath    = "/api/v1/namespaces/default/pods/nginx"
+	)
+	test := []struct {
+		name            string
+		dryRun          bool
+		expectedPatched map[string][]byte
+		expectedCreated map[string][]byte
+	}{
+		{
+			name: "isolate pod",
+			expectedCreated: map[string][]byte{
+				policyPath: []byte(`{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","metadata":{"name":"sra-quarantine"},"spec":{"podSelector":{"matchLabels":{"sra-quarantine":"true"}},"policyTypes":["Ingress","Egress"]}}`),
+			},
+			expectedPatched: map[string][]byte{
+				podPath: []byte(`{"metadata":{"labels":{"sra-quarantine":"true"}}}`),
+			},
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			svcs, kubeStub := isolatePodSetup()
+			values := &Values{
+				ProjectID: "project-test",
+				Location:  "us-central1-a",
+				ClusterID: "test-cluster",
+				Namespace: "default",
+				Pod:       "nginx",
+				DryRun:    tt.dryRun,
+			}
+			if err := Execute(ctx, values, &Services{
+				Kubernetes: svcs.Kubernetes,
+				Resource:   svcs.Resource,
+				Logger:     svcs.Logger,
+			}); err != nil {
+				t.Errorf("%s test failed want:%q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expectedCreated, kubeStub.Created); diff != "" {
+				t.Errorf("%v failed, created difference: %v", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.expectedPatched, kubeStub.Patched); diff != "" {
+				t.Errorf("%v failed, patched difference: %v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func isolatePodSetup() (*services.Global, *stubs.KubernetesStub) {
+	loggerStub := &stubs.LoggerStub{}
+	log := services.NewLogger(loggerStub)
+	kubeStub := &stubs.KubernetesStub{}
+	kube := services.NewKubernetes(kubeStub)
+	crmStub := &stubs.ResourceManagerStub{}
+	storageStub := &stubs.StorageStub{}
+	resource := services.NewResource(crmStub, storageStub)
+	return &services.Global{Logger: log, Resource: resource, Kubernetes: kube}, kubeStub
+}
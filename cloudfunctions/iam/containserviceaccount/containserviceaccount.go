@@ -0,0 +1,180 @@
+package containserviceaccount
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// action is the name of this action, attributed the work items it publishes.
+const action = "iam_contain_service_account"
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        action,
+		EntryPoint:  "ContainServiceAccount",
+		Topic:       "threat-findings-contain-service-account",
+		Services:    []string{"Resource", "IAM", "Email"},
+		Roles:       []string{"roles/viewer", "roles/iam.serviceAccountKeyAdmin", "roles/resourcemanager.projectIamAdmin"},
+		Permissions: []string{"iam.serviceAccountKeys.list", "iam.serviceAccountKeys.disable", "resourcemanager.projects.getIamPolicy", "resourcemanager.projects.setIamPolicy", "resourcemanager.projects.list", "resourcemanager.folders.list"},
+	})
+}
+
+// notification is the body of the email sent to the owners of the service account's project.
+var notification = template.Must(template.New("notification").Parse(`The service account {{.ServiceAccount}} may be compromised and was contained by Security Response Automation.
+{{if .Keys}}
+Disabled keys:
+{{range .Keys}}  - {{.}}
+{{end}}{{end}}{{if .Projects}}
+Removed from the IAM policies of:
+{{range .Projects}}  - {{.}}
+{{end}}{{end}}{{if .Queued}}
+Being removed from the IAM policies of:
+{{range .Queued}}  - {{.}}
+{{end}}{{end}}
+Once the service account is investigated and its credentials rotated, enable the keys still in use with:
+
+  gcloud iam service-accounts keys enable KEY_ID --iam-account={{.ServiceAccount}}
+
+and grant the service account its roles again with:
+
+  gcloud projects add-iam-policy-binding PROJECT_ID --member=serviceAccount:{{.ServiceAccount}} --role=ROLE
+`))
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// ProjectID is the project of the service account, whose owners are notified.
+	ProjectID string
+	// ServiceAccount is the email address of the service account.
+	ServiceAccount string
+	// ProjectIDs are the projects whose IAM policies the service account is removed from.
+	ProjectIDs []string
+	// FolderIDs are folders whose projects, at any depth, the service account is removed from too.
+	FolderIDs []string
+	// FanOutAbove is the number of projects above which the service account is removed from them
+	// by the FanOut function, one project at a time, rather than by this function. Zero never fans
+	// out.
+	FanOutAbove int
+	// From and To are the sender and additional recipients of the notification, sent to the
+	// owners of the service account's project too.
+	From   string
+	To     []string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	IAM      *services.IAM
+	Resource *services.Resource
+	Email    *services.Email
+	// FanOut publishes the removal from each project as a work item, nil removes them all here.
+	FanOut *services.FanOut
+	Logger *services.Logger
+}
+
+// Execute contains a service account that may be compromised in one step: its user-managed keys
+// are disabled, it is removed from the IAM policies of the projects and the owners of its project
+// are notified how to restore it.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have disabled the keys of %q and removed it from %q and the projects of folders %q", values.ServiceAccount, values.ProjectIDs, values.FolderIDs)
+		return nil
+	}
+	keys, err := services.IAM.DisableKeys(ctx, values.ServiceAccount)
+	if err != nil {
+		return err
+	}
+	projectIDs := values.ProjectIDs
+	if len(values.FolderIDs) > 0 {
+		ids, err := services.Resource.ProjectsUnder(ctx, values.FolderIDs)
+		if err != nil {
+			return err
+		}
+		projectIDs = unique(append(ids, projectIDs...))
+	}
+	member := "serviceAccount:" + values.ServiceAccount
+	if services.FanOut != nil && values.FanOutAbove > 0 && len(projectIDs) > values.FanOutAbove {
+		queued, err := services.FanOut.Publish(ctx, removal(member), projectIDs)
+		if err != nil {
+			return err
+		}
+		services.Logger.Info("disabled keys %q of %q and queued its removal from %d projects", keys, values.ServiceAccount, len(queued))
+		return notify(ctx, values, keys, nil, queued, services)
+	}
+	projects, err := services.Resource.RemoveMemberProjects(ctx, projectIDs, member)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 && len(projects) == 0 {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("disabled keys %q of %q and removed it from %q", keys, values.ServiceAccount, projects)
+	return notify(ctx, values, keys, projects, nil, services)
+}
+
+// notify emails the owners of the service account's project and the configured recipients, listing
+// the projects it was removed from and those it is being removed from by the FanOut function.
+func notify(ctx context.Context, values *Values, keys, projects, queued []string, services *Services) error {
+	owners, err := services.Resource.ProjectOwners(ctx, values.ProjectID)
+	if err != nil {
+		return err
+	}
+	to := unique(append(owners, values.To...))
+	if len(to) == 0 || values.From == "" {
+		services.Logger.Info("no sender or recipients, not notifying about %q", values.ServiceAccount)
+		return nil
+	}
+	var body bytes.Buffer
+	if err := notification.Execute(&body, struct {
+		ServiceAccount         string
+		Keys, Projects, Queued []string
+	}{values.ServiceAccount, keys, projects, queued}); err != nil {
+		return errors.Wrap(err, "failed to render notification")
+	}
+	subject := fmt.Sprintf("Service account %s contained", values.ServiceAccount)
+	if _, err := services.Email.Send(subject, values.From, body.String(), to); err != nil {
+		return errors.Wrapf(err, "failed to send %q", subject)
+	}
+	services.Logger.Info("sent %q to %q", subject, to)
+	return nil
+}
+
+// removal returns the work item removing the member from a project.
+func removal(member string) services.WorkItem {
+	return services.WorkItem{Action: action, Operation: services.OperationRemoveMember, Member: member}
+}
+
+// unique returns the email addresses sorted and without duplicates, compared regardless of case.
+func unique(emails []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, e := range emails {
+		if k := strings.ToLower(e); !seen[k] {
+			seen[k] = true
+			out = append(out, e)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,177 @@
+package containserviceaccount
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/sendgrid/rest"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+)
+
+const (
+	serviceAccount = "deployer@sa-project.iam.gserviceaccount.com"
+	accountName    = "projects/-/serviceAccounts/" + serviceAccount
+)
+
+func TestContainServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		keys          []*iam.ServiceAccountKey
+		granted       bool
+		folders       bool
+		fanOut        bool
+		dryRun        bool
+		wantDisabled  []string
+		wantProjects  map[string]int
+		wantTo        []string
+		wantQueued    int
+		expectedError error
+	}{
+		{
+			name:         "contain",
+			keys:         []*iam.ServiceAccountKey{{Name: accountName + "/keys/1"}},
+			granted:      true,
+			wantDisabled: []string{accountName + "/keys/1"},
+			wantProjects: map[string]int{"sa-project": 1, "app-project": 1},
+			wantTo:       []string{"owner@example.com", "secops@example.com"},
+		},
+		{
+			name:         "keys only",
+			keys:         []*iam.ServiceAccountKey{{Name: accountName + "/keys/1"}},
+			wantDisabled: []string{accountName + "/keys/1"},
+			wantProjects: map[string]int{"sa-project": 1, "app-project": 1},
+			wantTo:       []string{"owner@example.com", "secops@example.com"},
+		},
+		{
+			name:         "contain in folders",
+			keys:         []*iam.ServiceAccountKey{{Name: accountName + "/keys/1"}},
+			granted:      true,
+			folders:      true,
+			wantDisabled: []string{accountName + "/keys/1"},
+			wantProjects: map[string]int{"sa-project": 1, "app-project": 1, "folder-project": 0},
+			wantTo:       []string{"owner@example.com", "secops@example.com"},
+		},
+		{
+			name:         "fan out",
+			keys:         []*iam.ServiceAccountKey{{Name: accountName + "/keys/1"}},
+			granted:      true,
+			fanOut:       true,
+			wantDisabled: []string{accountName + "/keys/1"},
+			wantProjects: map[string]int{"sa-project": 2, "app-project": 1},
+			wantTo:       []string{"owner@example.com", "secops@example.com"},
+			wantQueued:   2,
+		},
+		{
+			name:         "dry run",
+			keys:         []*iam.ServiceAccountKey{{Name: accountName + "/keys/1"}},
+			granted:      true,
+			dryRun:       true,
+			wantProjects: map[string]int{"sa-project": 2, "app-project": 1},
+		},
+		{
+			name:          "already contained",
+			wantProjects:  map[string]int{"sa-project": 1, "app-project": 1},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crmStub := &stubs.ResourceManagerStub{ProjectPolicies: map[string]*crm.Policy{
+				"sa-project":  {Bindings: []*crm.Binding{{Role: "roles/owner", Members: []string{"user:owner@example.com"}}}},
+				"app-project": {Bindings: []*crm.Binding{{Role: "roles/viewer", Members: []string{"user:dev@example.com"}}}},
+			}}
+			if tt.granted {
+				b := &crm.Binding{Role: "roles/editor", Members: []string{"serviceAccount:" + serviceAccount}}
+				crmStub.ProjectPolicies["sa-project"].Bindings = append(crmStub.ProjectPolicies["sa-project"].Bindings, b)
+			}
+			var folderIDs []string
+			if tt.folders {
+				folderIDs = []string{"7"}
+				crmStub.ProjectPages = [][]*crm.Project{{{ProjectId: "folder-project", LifecycleState: "ACTIVE"}}}
+				crmStub.ProjectPolicies["folder-project"] = &crm.Policy{Bindings: []*crm.Binding{{Role: "roles/editor", Members: []string{"serviceAccount:" + serviceAccount}}}}
+			}
+			iamStub := &stubs.IAMStub{Keys: map[string][]*iam.ServiceAccountKey{accountName: tt.keys}}
+			sendGridStub := &stubs.SendGridStub{StubbedSend: &rest.Response{StatusCode: http.StatusAccepted}}
+			pubSubStub := &stubs.PubSubStub{StubbedTopic: &pubsub.Topic{}}
+			s := &Services{
+				IAM:      services.NewIAM(iamStub),
+				Resource: services.NewResource(crmStub, &stubs.StorageStub{}),
+				Email:    services.NewEmail(&clients.SendGrid{Service: sendGridStub}),
+				FanOut:   services.NewFanOut(services.NewPubSub(pubSubStub), "correlation-1"),
+				Logger:   services.NewLogger(&stubs.LoggerStub{}),
+			}
+			var fanOutAbove int
+			if tt.fanOut {
+				fanOutAbove = 1
+			}
+			values := &Values{
+				ProjectID:      "sa-project",
+				ServiceAccount: serviceAccount,
+				ProjectIDs:     []string{"sa-project", "app-project"},
+				FolderIDs:      folderIDs,
+				FanOutAbove:    fanOutAbove,
+				From:           "sra@example.com",
+				To:             []string{"secops@example.com"},
+				DryRun:         tt.dryRun,
+			}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantDisabled, iamStub.DisabledKeys); diff != "" {
+				t.Errorf("%s disabled keys (-want +got):\n%s", tt.name, diff)
+			}
+			if len(pubSubStub.Published) != tt.wantQueued {
+				t.Errorf("%s published %d work items, want %d", tt.name, len(pubSubStub.Published), tt.wantQueued)
+			}
+			got := make(map[string]int)
+			for project, policy := range crmStub.ProjectPolicies {
+				got[project] = len(policy.Bindings)
+			}
+			if diff := cmp.Diff(tt.wantProjects, got); diff != "" {
+				t.Errorf("%s bindings per project (-want +got):\n%s", tt.name, diff)
+			}
+			if tt.wantTo == nil {
+				if len(sendGridStub.Sent) != 0 {
+					t.Errorf("%s sent %d emails, want none", tt.name, len(sendGridStub.Sent))
+				}
+				return
+			}
+			if len(sendGridStub.Sent) != 1 {
+				t.Fatalf("%s sent %d emails, want 1", tt.name, len(sendGridStub.Sent))
+			}
+			var to []string
+			for _, e := range sendGridStub.Sent[0].Personalizations[0].To {
+				to = append(to, e.Address)
+			}
+			if diff := cmp.Diff(tt.wantTo, to); diff != "" {
+				t.Errorf("%s recipients (-want +got):\n%s", tt.name, diff)
+			}
+			if body := sendGridStub.Sent[0].Content[0].Value; !strings.Contains(body, "gcloud iam service-accounts keys enable") {
+				t.Errorf("%s notification lacks re-enable instructions:\n%s", tt.name, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "contain-service-account" {
+  name                  = "ContainServiceAccount"
+  description           = "Disables the keys and revokes the grants of a compromised service account and notifies its owners."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 120
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "ContainServiceAccount"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-contain-service-account"
+  }
+  environment_variables = {
+    GCP_PROJECT                 = var.setup.automation-project
+    SENDGRID_API_KEY            = var.sendgrid-api-key
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "iam_contain_service_account", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_iam_contain_service_account"
+  }
+}
+
+# Required by ContainServiceAccount to disable the keys of service accounts within this folder.
+resource "google_folder_iam_member" "contain-service-account-key-admin-folder-bind" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/iam.serviceAccountKeyAdmin"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Required by ContainServiceAccount to revoke IAM grants on projects within this folder.
+resource "google_folder_iam_member" "contain-service-account-iam-admin-folder-bind" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/resourcemanager.projectIamAdmin"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Required to retrieve ancestry and owners for projects within this folder.
+resource "google_folder_iam_member" "contain-service-account-viewer-folder-bind" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/viewer"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# PubSub topic to trigger this automation.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-contain-service-account"
+  project = var.setup.automation-project
+}
@@ -0,0 +1,11 @@
+variable "setup" {}
+
+variable "folder-ids" {
+  type        = list(string)
+  description = "Contain service accounts of projects within the given folder IDs."
+}
+
+variable "sendgrid-api-key" {
+  type        = string
+  description = "SendGrid API key used to notify the owners of service accounts."
+}
@@ -0,0 +1,86 @@
+package downgraderoles
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "iam_downgrade_roles",
+		EntryPoint:  "DowngradeRoles",
+		Topic:       "threat-findings-downgrade-roles",
+		Services:    []string{"Resource", "Recommender"},
+		Roles:       []string{"roles/viewer", "roles/resourcemanager.projectIamAdmin", "roles/recommender.iamAdmin"},
+		Permissions: []string{"resourcemanager.projects.getIamPolicy", "resourcemanager.projects.setIamPolicy", "recommender.iamPolicyRecommendations.list", "recommender.iamPolicyRecommendations.update"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// Members are the over-privileged members, such as "user:dev@example.com".
+	Members []string
+	DryRun  bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Resource    *services.Resource
+	Recommender *services.Recommender
+	Logger      *services.Logger
+}
+
+// Execute applies the IAM recommendations of the project for the over-privileged members,
+// replacing their roles by the smaller roles they use rather than removing their access.
+// Members without a recommendation are left as they are.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	applied := 0
+	for _, member := range values.Members {
+		recommendations, err := services.Recommender.RoleRecommendations(ctx, values.ProjectID, member)
+		if err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			services.Logger.Info("no role recommendation for %q in %q", member, values.ProjectID)
+			continue
+		}
+		for _, rec := range recommendations {
+			if values.DryRun {
+				services.Logger.Info("dry_run on, would have replaced %q of %q in %q by %q", rec.Role, member, values.ProjectID, rec.Replacements)
+				continue
+			}
+			changed, err := services.Resource.ReplaceRoleProject(ctx, values.ProjectID, member, rec.Role, rec.Replacements)
+			if err != nil {
+				return err
+			}
+			if err := services.Recommender.MarkSucceeded(ctx, rec); err != nil {
+				return err
+			}
+			if changed {
+				applied++
+				services.Logger.Info("replaced %q of %q in %q by %q", rec.Role, member, values.ProjectID, rec.Replacements)
+			}
+		}
+	}
+	if applied == 0 && !values.DryRun {
+		return registry.ErrAlreadyRemediated
+	}
+	return nil
+}
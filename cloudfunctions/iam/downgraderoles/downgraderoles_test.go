@@ -0,0 +1,108 @@
+package downgraderoles
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+const (
+	member = "user:dev@example.com"
+	parent = "projects/foo/locations/global/recommenders/google.iam.policy.Recommender"
+)
+
+func TestDowngradeRoles(t *testing.T) {
+	ctx := context.Background()
+	recommendation := &recommender.GoogleCloudRecommenderV1Recommendation{
+		Name: parent + "/recommendations/1",
+		Etag: "\"a\"",
+		Content: &recommender.GoogleCloudRecommenderV1RecommendationContent{
+			OperationGroups: []*recommender.GoogleCloudRecommenderV1OperationGroup{{Operations: []*recommender.GoogleCloudRecommenderV1Operation{
+				{
+					Action:      "add",
+					Path:        "/iamPolicy/bindings/*/members/-",
+					PathFilters: map[string]interface{}{"/iamPolicy/bindings/*/role": "roles/storage.objectViewer"},
+					Value:       member,
+				},
+				{
+					Action: "remove",
+					Path:   "/iamPolicy/bindings/*/members/*",
+					PathFilters: map[string]interface{}{
+						"/iamPolicy/bindings/*/role":      "roles/editor",
+						"/iamPolicy/bindings/*/members/*": member,
+					},
+				},
+			}}},
+		},
+	}
+	for _, tt := range []struct {
+		name            string
+		recommendations []*recommender.GoogleCloudRecommenderV1Recommendation
+		dryRun          bool
+		wantBindings    []*crm.Binding
+		wantSucceeded   []string
+		expectedError   error
+	}{
+		{
+			name:            "downgrade",
+			recommendations: []*recommender.GoogleCloudRecommenderV1Recommendation{recommendation},
+			wantBindings:    []*crm.Binding{{Role: "roles/storage.objectViewer", Members: []string{member}}},
+			wantSucceeded:   []string{recommendation.Name},
+		},
+		{
+			name:            "dry run",
+			recommendations: []*recommender.GoogleCloudRecommenderV1Recommendation{recommendation},
+			dryRun:          true,
+			wantBindings:    []*crm.Binding{{Role: "roles/editor", Members: []string{member}}},
+		},
+		{
+			name:          "no recommendation",
+			wantBindings:  []*crm.Binding{{Role: "roles/editor", Members: []string{member}}},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crmStub := &stubs.ResourceManagerStub{ProjectPolicies: map[string]*crm.Policy{
+				"foo": {Bindings: []*crm.Binding{{Role: "roles/editor", Members: []string{member}}}},
+			}}
+			recommenderStub := &stubs.RecommenderStub{Recommendations: map[string][]*recommender.GoogleCloudRecommenderV1Recommendation{
+				parent: tt.recommendations,
+			}}
+			s := &Services{
+				Resource:    services.NewResource(crmStub, &stubs.StorageStub{}),
+				Recommender: services.NewRecommender(recommenderStub),
+				Logger:      services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{ProjectID: "foo", Members: []string{member}, DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantBindings, crmStub.ProjectPolicies["foo"].Bindings); diff != "" {
+				t.Errorf("%s bindings (-want +got):\n%s", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.wantSucceeded, recommenderStub.Succeeded); diff != "" {
+				t.Errorf("%s succeeded (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
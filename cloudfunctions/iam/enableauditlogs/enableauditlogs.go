@@ -17,9 +17,21 @@ package enableauditlogs
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "enable_audit_logs",
+		EntryPoint:  "EnableAuditLogs",
+		Topic:       "threat-findings-enable-audit-logs",
+		Services:    []string{"Resource"},
+		Roles:       []string{"roles/editor", "roles/resourcemanager.folderAdmin"},
+		Permissions: []string{"resourcemanager.projects.getIamPolicy", "resourcemanager.projects.setIamPolicy"},
+	})
+}
+
 // Required contains the required values needed for this function.
 type Required struct {
 	ProjectID string
@@ -34,18 +46,29 @@ type Services struct {
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID string
-	DryRun    bool
+	// Services are the services whose Data Access audit logs are enabled, such as
+	// "storage.googleapis.com", all services if empty.
+	Services []string
+	DryRun   bool
 }
 
 // Execute is the entry point for the Cloud Function to enable audit logs for a specific project.
+// The ADMIN_READ, DATA_READ and DATA_WRITE logs of the configured services are enabled.
 func Execute(ctx context.Context, values *Values, services *Services) error {
 	if values.DryRun {
-		services.Logger.Info("dry_run on, would have enabled data access audit logs in project %q", values.ProjectID)
+		services.Logger.Info("dry_run on, would have enabled data access audit logs of %q in project %q", values.Services, values.ProjectID)
 		return nil
 	}
-	if _, err := services.Resource.EnableAuditLogs(ctx, values.ProjectID); err != nil {
+	enabled, err := services.Resource.AuditLogsEnabled(ctx, values.ProjectID, values.Services)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		return registry.ErrAlreadyRemediated
+	}
+	if _, err := services.Resource.EnableAuditLogs(ctx, values.ProjectID, values.Services); err != nil {
 		return err
 	}
-	services.Logger.Info("audit logs was enabled on %q", values.ProjectID)
+	services.Logger.Info("audit logs of %q was enabled on %q", values.Services, values.ProjectID)
 	return nil
 }
@@ -20,6 +20,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	crm "google.golang.org/api/cloudresourcemanager/v1"
 )
@@ -28,10 +29,14 @@ func TestExecuteEnableDataAccessAuditLogs(t *testing.T) {
 	ctx := context.Background()
 	tests := []struct {
 		name           string
+		initial        []*crm.AuditConfig
+		services       []string
 		expectedResult []*crm.AuditConfig
+		expectedError  error
 	}{
 		{
-			name: "test enable audit logs",
+			name:    "test enable audit logs",
+			initial: []*crm.AuditConfig{},
 			expectedResult: []*crm.AuditConfig{
 				{AuditLogConfigs: []*crm.AuditLogConfig{
 					{LogType: "ADMIN_READ"},
@@ -42,16 +47,77 @@ func TestExecuteEnableDataAccessAuditLogs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "already enabled",
+			initial: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{
+					{LogType: "DATA_WRITE"},
+					{LogType: "DATA_READ"},
+					{LogType: "ADMIN_READ"},
+				},
+					Service: "allServices",
+				},
+			},
+			expectedResult: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{
+					{LogType: "DATA_WRITE"},
+					{LogType: "DATA_READ"},
+					{LogType: "ADMIN_READ"},
+				},
+					Service: "allServices",
+				},
+			},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name: "enable audit logs of services",
+			initial: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}}, Service: "storage.googleapis.com"},
+			},
+			services: []string{"storage.googleapis.com"},
+			expectedResult: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{
+					{LogType: "ADMIN_READ"},
+					{LogType: "DATA_READ"},
+					{LogType: "DATA_WRITE"},
+				},
+					Service: "storage.googleapis.com",
+				},
+			},
+		},
+		{
+			name: "services enabled by all services",
+			initial: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{
+					{LogType: "ADMIN_READ"},
+					{LogType: "DATA_READ"},
+					{LogType: "DATA_WRITE"},
+				},
+					Service: "allServices",
+				},
+			},
+			services: []string{"storage.googleapis.com"},
+			expectedResult: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{
+					{LogType: "ADMIN_READ"},
+					{LogType: "DATA_READ"},
+					{LogType: "DATA_WRITE"},
+				},
+					Service: "allServices",
+				},
+			},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			required := &Values{ProjectID: "fake-project"}
-			policy := &crm.Policy{AuditConfigs: []*crm.AuditConfig{}}
+			required := &Values{ProjectID: "fake-project", Services: tt.services}
+			policy := &crm.Policy{AuditConfigs: tt.initial}
 			entity := setupAuditLogs(policy)
 			if err := Execute(ctx, required, &Services{
 				Resource: entity.Resource,
 				Logger:   entity.Logger,
-			}); err != nil {
+			}); err != tt.expectedError {
 				t.Errorf("%s failed to enable audi logs :%q", tt.name, err)
 			}
 			if diff := cmp.Diff(policy.AuditConfigs, tt.expectedResult); diff != "" {
@@ -29,7 +29,12 @@ resource "google_cloudfunctions_function" "enable-audit-logs" {
     resource   = "threat-findings-enable-audit-logs"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "enable_audit_logs", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_enable_audit_logs"
   }
 }
 
@@ -28,6 +28,14 @@ resource "google_cloudfunctions_function" "remove-non-org-members" {
     event_type = "google.pubsub.topic.publish"
     resource   = "threat-findings-remove-non-org-members"
   }
+  environment_variables = {
+    GCP_PROJECT                 = var.setup.automation-project
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "remove_non_org_members", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_remove_non_org_members"
+  }
 }
 
 #  Required to get and set organization policies.
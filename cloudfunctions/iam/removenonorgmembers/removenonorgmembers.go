@@ -17,13 +17,28 @@ package removenonorgmembers
 import (
 	"context"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "remove_non_org_members",
+		EntryPoint:  "RemoveNonOrganizationMembers",
+		Topic:       "threat-findings-remove-non-org-members",
+		Services:    []string{"Resource"},
+		Roles:       []string{"roles/resourcemanager.folderAdmin"},
+		Permissions: []string{"resourcemanager.projects.get", "resourcemanager.projects.getIamPolicy", "resourcemanager.projects.setIamPolicy"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID    string
 	AllowDomains []string
+	// ExpandGroups removes the groups with members, direct or nested, not from the allowed
+	// domains too, groups are kept otherwise.
+	ExpandGroups bool
 	DryRun       bool
 }
 
@@ -31,9 +46,12 @@ type Values struct {
 type Services struct {
 	Logger   *services.Logger
 	Resource *services.Resource
+	// CloudIdentity expands groups, required when ExpandGroups is set.
+	CloudIdentity *services.CloudIdentity
 }
 
-// Execute removes all users from a specific project not in allowed domain list.
+// Execute removes all users from a specific project not in allowed domain list, and the groups
+// containing such identities when groups are expanded.
 func Execute(ctx context.Context, values *Values, services *Services) error {
 	if values.DryRun {
 		services.Logger.Info("dry run, would have removed users not from %q in %q", values.AllowDomains, values.ProjectID)
@@ -43,6 +61,43 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 	if err != nil {
 		return err
 	}
+	if values.ExpandGroups {
+		groups, err := externalGroups(ctx, values, services)
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			if _, err := services.Resource.RemoveMemberProjects(ctx, []string{values.ProjectID}, g); err != nil {
+				return err
+			}
+			removed = append(removed, g)
+		}
+	}
+	if len(removed) == 0 {
+		return registry.ErrAlreadyRemediated
+	}
 	services.Logger.Info("successfully removed %q from %s", removed, values.ProjectID)
 	return nil
 }
+
+// externalGroups returns the groups of the project, as "group:" members, containing identities
+// not from the allowed domains.
+func externalGroups(ctx context.Context, values *Values, services *Services) ([]string, error) {
+	groups, err := services.Resource.ProjectGroups(ctx, values.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	var flagged []string
+	for _, g := range groups {
+		external, err := services.CloudIdentity.ExternalMembers(ctx, g, values.AllowDomains)
+		if err != nil {
+			return nil, err
+		}
+		if len(external) == 0 {
+			continue
+		}
+		services.Logger.Info("group %q in %q contains external members %q", g, values.ProjectID, external)
+		flagged = append(flagged, "group:"+g)
+	}
+	return flagged, nil
+}
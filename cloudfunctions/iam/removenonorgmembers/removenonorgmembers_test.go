@@ -20,7 +20,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
 	crm "google.golang.org/api/cloudresourcemanager/v1"
 )
 
@@ -142,6 +144,51 @@ func TestRemoveNonOrgMembers(t *testing.T) {
 	}
 }
 
+func TestNothingToRemove(t *testing.T) {
+	policy := &crm.Policy{Bindings: createBindings([]string{"user:ddgo@cloudorg.com", "group:admins@example.com"})}
+	entity, crmStub := setupNonOrgTest(policy)
+	values := &Values{ProjectID: "project-id", AllowDomains: []string{"cloudorg.com"}}
+	err := Execute(context.Background(), values, &Services{
+		Resource: entity.Resource,
+		Logger:   entity.Logger,
+	})
+	if err != registry.ErrAlreadyRemediated {
+		t.Errorf("got %q, want %q", err, registry.ErrAlreadyRemediated)
+	}
+	if crmStub.SavedSetPolicy != nil {
+		t.Errorf("policy set with nothing to remove")
+	}
+}
+
+func TestExpandGroups(t *testing.T) {
+	policy := &crm.Policy{Bindings: createBindings([]string{
+		"user:ddgo@cloudorg.com",
+		"group:devs@cloudorg.com",
+		"group:admins@cloudorg.com",
+	})}
+	entity, crmStub := setupNonOrgTest(policy)
+	ciStub := &stubs.CloudIdentityStub{
+		Groups: map[string]string{"devs@cloudorg.com": "groups/devs", "admins@cloudorg.com": "groups/admins"},
+		Memberships: map[string][]*cloudidentity.Membership{
+			"groups/devs":   {{PreferredMemberKey: &cloudidentity.EntityKey{Id: "bob@gmail.com"}, Type: "USER"}},
+			"groups/admins": {{PreferredMemberKey: &cloudidentity.EntityKey{Id: "ddgo@cloudorg.com"}, Type: "USER"}},
+		},
+	}
+	values := &Values{ProjectID: "project-id", AllowDomains: []string{"cloudorg.com"}, ExpandGroups: true}
+	err := Execute(context.Background(), values, &Services{
+		Resource:      entity.Resource,
+		Logger:        entity.Logger,
+		CloudIdentity: services.NewCloudIdentity(ciStub),
+	})
+	if err != nil {
+		t.Fatalf("got error %q", err)
+	}
+	want := createBindings([]string{"user:ddgo@cloudorg.com", "group:admins@cloudorg.com"})
+	if diff := cmp.Diff(want, crmStub.SavedSetPolicy.Bindings); diff != "" {
+		t.Errorf("bindings (-want +got):\n%s", diff)
+	}
+}
+
 func setupNonOrgTest(policy *crm.Policy) (*services.Global, *stubs.ResourceManagerStub) {
 	crmStub := &stubs.ResourceManagerStub{}
 	crmStub.GetPolicyResponse = policy
@@ -31,7 +31,13 @@ resource "google_cloudfunctions_function" "revoke_member_function" {
     resource   = "threat-findings-iam-revoke"
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT                 = var.setup.automation-project
+    SENDGRID_API_KEY            = var.sendgrid-api-key
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "iam_revoke", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_iam_revoke"
   }
 }
 
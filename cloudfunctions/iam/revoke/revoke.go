@@ -19,23 +19,46 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
+func init() {
+	registry.Register(registry.Action{
+		Name:        "iam_revoke",
+		EntryPoint:  "IAMRevoke",
+		Topic:       "threat-findings-iam-revoke",
+		Services:    []string{"Resource"},
+		Roles:       []string{"roles/viewer", "roles/resourcemanager.folderAdmin"},
+		Permissions: []string{"resourcemanager.projects.get", "resourcemanager.projects.getIamPolicy", "resourcemanager.projects.setIamPolicy"},
+	})
+}
+
 // Values contains the required values needed for this function.
 type Values struct {
 	ProjectID       string
 	ExternalMembers []string
 	AllowDomains    []string
-	DryRun          bool
+	// ExpireAfterHours replaces the grants by grants expiring after this many hours instead of
+	// removing them when set.
+	ExpireAfterHours int
+	// Grantor is the email address of the principal who made the grants, notified from From when
+	// the grants are replaced by expiring ones.
+	Grantor string
+	From    string
+	DryRun  bool
 }
 
 // Services contains the services needed for this function.
 type Services struct {
 	Resource *services.Resource
 	Logger   *services.Logger
+	// Email notifies the grantor, required to notify it of expiring grants.
+	Email *services.Email
 }
 
 // Execute is the entry point for the IAM revoker Cloud Function.
@@ -45,6 +68,9 @@ type Services struct {
 // - The project where the external users were found are within the set configured resources.
 // - The users do not match the list of allowed domains.
 //
+// When ExpireAfterHours is set the grants are replaced by grants expiring after that many hours
+// instead, limiting the impact of false positives, and the grantor is notified.
+//
 func Execute(ctx context.Context, values *Values, services *Services) error {
 	members, err := toRemove(values.ExternalMembers, values.AllowDomains)
 	if err != nil {
@@ -54,6 +80,16 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 		services.Logger.Info("dry_run on, would have removed %q from %q", members, values.ProjectID)
 		return nil
 	}
+	found, err := services.Resource.ProjectHasUsers(ctx, values.ProjectID, members)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.ExpireAfterHours > 0 {
+		return expire(ctx, values, members, services)
+	}
 	if err := services.Resource.RemoveUsersProject(ctx, values.ProjectID, members); err != nil {
 		return err
 	}
@@ -61,6 +97,47 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 	return nil
 }
 
+// expire replaces the grants of the users by grants expiring after the configured hours, and
+// notifies the grantor. Like removal, non-users are not affected.
+func expire(ctx context.Context, values *Values, members []string, services *Services) error {
+	var users []string
+	for _, m := range members {
+		if strings.HasPrefix(m, "user:") {
+			users = append(users, m)
+		}
+	}
+	expiry := time.Now().Add(time.Duration(values.ExpireAfterHours) * time.Hour)
+	expired, err := services.Resource.ExpireMembersProject(ctx, values.ProjectID, users, expiry)
+	if err != nil {
+		return err
+	}
+	if len(expired) == 0 {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("grants of %q in %s expire at %s", expired, values.ProjectID, expiry.UTC().Format(time.RFC3339))
+	if values.Grantor == "" || values.From == "" || services.Email == nil {
+		services.Logger.Info("no grantor or sender, not notifying about expiring grants in %s", values.ProjectID)
+		return nil
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "The following IAM grants you made on project %s were flagged as anomalous by Security Response Automation and expire at %s:\n\n", values.ProjectID, expiry.UTC().Format(time.RFC3339))
+	var expiredMembers []string
+	for m := range expired {
+		expiredMembers = append(expiredMembers, m)
+	}
+	sort.Strings(expiredMembers)
+	for _, m := range expiredMembers {
+		fmt.Fprintf(&body, "  - %s: %s\n", m, strings.Join(expired[m], ", "))
+	}
+	body.WriteString("\nIf the grants are legitimate, grant the roles again without a condition before they expire.\n")
+	subject := fmt.Sprintf("IAM grants on %s set to expire", values.ProjectID)
+	if _, err := services.Email.Send(subject, values.From, body.String(), []string{values.Grantor}); err != nil {
+		return fmt.Errorf("failed to send %q: %q", subject, err)
+	}
+	services.Logger.Info("sent %q to %q", subject, values.Grantor)
+	return nil
+}
+
 // toRemove returns a slice containing only external members that are disallowed.
 // This check is done to ensure we only consider removing members that came from the finding and not
 // just any members that aren't part of the configured allow list.
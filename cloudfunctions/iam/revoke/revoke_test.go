@@ -16,12 +16,17 @@ package revoke
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
 	"github.com/googlecloudplatform/security-response-automation/services"
 	"github.com/pkg/errors"
+	"github.com/sendgrid/rest"
 	"golang.org/x/xerrors"
 	crm "google.golang.org/api/cloudresourcemanager/v1"
 )
@@ -86,24 +91,24 @@ func TestIAMRevoke(t *testing.T) {
 		},
 		{
 			name:            "domains in allowed list",
-			expectedError:   nil,
+			expectedError:   registry.ErrAlreadyRemediated,
 			folderIDs:       []string{"folderID"},
 			projectIDs:      []string{},
 			externalMembers: []string{"user:tom@foo.com"},
 			initialMembers:  []string{"user:test@test.com", "user:tom@foo.com"},
 			allowed:         []string{"test.com", "foo.com"},
-			expectedMembers: []string{"user:test@test.com", "user:tom@foo.com"},
+			expectedMembers: nil,
 			ancestry:        services.CreateAncestors([]string{"project/projectID", "folder/folderID", "organization/organizationID"}),
 		},
 		{
 			name:            "ignore non-users",
-			expectedError:   nil,
+			expectedError:   registry.ErrAlreadyRemediated,
 			folderIDs:       []string{"folderID"},
 			projectIDs:      []string{},
 			externalMembers: []string{"user:tom@foo.com", "serviceAccount:bob@foo.com"},
 			initialMembers:  []string{"user:test@test.com", "user:tom@foo.com", "serviceAccount:bob@foo.com"},
 			allowed:         []string{"test.com", "foo.com"},
-			expectedMembers: []string{"user:test@test.com", "user:tom@foo.com", "serviceAccount:bob@foo.com"},
+			expectedMembers: nil,
 			ancestry:        services.CreateAncestors([]string{"project/projectID", "folder/folderID", "organization/organizationID"}),
 		},
 		{
@@ -117,6 +122,17 @@ func TestIAMRevoke(t *testing.T) {
 			expectedMembers: []string{"user:test@test.com", "serviceAccount:bob@foo.com"},
 			ancestry:        services.CreateAncestors([]string{"project/projectID", "folder/folderID", "organization/organizationID"}),
 		},
+		{
+			name:            "user already removed",
+			expectedError:   registry.ErrAlreadyRemediated,
+			folderIDs:       []string{"folderID"},
+			projectIDs:      []string{},
+			externalMembers: []string{"user:tom@gmail.com"},
+			initialMembers:  []string{"user:test@test.com"},
+			allowed:         []string{},
+			expectedMembers: nil,
+			ancestry:        services.CreateAncestors([]string{"project/projectID", "folder/folderID", "organization/organizationID"}),
+		},
 		{
 			name:            "provide multiple folders and remove gmail users",
 			expectedError:   nil,
@@ -158,6 +174,41 @@ func TestIAMRevoke(t *testing.T) {
 	}
 }
 
+func TestIAMRevokeExpire(t *testing.T) {
+	ctx := context.Background()
+	svcs, crmStub := revokeGrantsSetup(nil, nil, nil)
+	crmStub.GetPolicyResponse = &crm.Policy{Bindings: createPolicy([]string{"user:test@test.com", "user:tom@gmail.com"})}
+	sendGridStub := &stubs.SendGridStub{StubbedSend: &rest.Response{StatusCode: http.StatusAccepted}}
+	values := &Values{
+		ProjectID:        "test-project-id",
+		ExternalMembers:  []string{"user:tom@gmail.com"},
+		ExpireAfterHours: 24,
+		Grantor:          "admin@test.com",
+		From:             "sra@test.com",
+	}
+	s := &Services{
+		Resource: svcs.Resource,
+		Logger:   svcs.Logger,
+		Email:    services.NewEmail(&clients.SendGrid{Service: sendGridStub}),
+	}
+	if err := Execute(ctx, values, s); err != nil {
+		t.Fatalf("got error %q", err)
+	}
+	bindings := crmStub.SavedSetPolicy.Bindings
+	if len(bindings) != 2 || bindings[1].Condition == nil || !strings.HasPrefix(bindings[1].Condition.Expression, "request.time < timestamp(") {
+		t.Fatalf("got bindings %+v, want an expiring grant", bindings)
+	}
+	if diff := cmp.Diff([]string{"user:tom@gmail.com"}, bindings[1].Members); diff != "" {
+		t.Errorf("expiring grant members (-want +got):\n%s", diff)
+	}
+	if len(sendGridStub.Sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(sendGridStub.Sent))
+	}
+	if err := Execute(ctx, values, s); err != registry.ErrAlreadyRemediated {
+		t.Errorf("got %q expiring again, want %q", err, registry.ErrAlreadyRemediated)
+	}
+}
+
 func createPolicy(members []string) []*crm.Binding {
 	return []*crm.Binding{
 		{
@@ -4,3 +4,9 @@ variable "folder-ids" {
   type        = list(string)
   description = "Remove public users from buckets if they are within the given folder IDs."
 }
+
+variable "sendgrid-api-key" {
+  type        = string
+  default     = ""
+  description = "SendGrid API key used to notify grantors of expiring grants."
+}
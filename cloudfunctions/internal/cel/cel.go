@@ -0,0 +1,649 @@
+// Package cel evaluates a subset of the Common Expression Language against decoded JSON.
+//
+// Supported are literals, field selection, indexing, the logical, comparison, `in` and `+`/`-`
+// operators, the `has` macro, `size` and the string methods `startsWith`, `endsWith`,
+// `contains` and `matches`. Numbers are compared as doubles.
+package cel
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Program is a compiled expression.
+type Program struct {
+	expr string
+	root node
+}
+
+// Compile parses the expression.
+func Compile(expr string) (*Program, error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %q", expr, err)
+	}
+	p := &parser{toks: toks}
+	root, err := p.expr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %q", expr, err)
+	}
+	return &Program{expr: expr, root: root}, nil
+}
+
+// Eval evaluates the expression with the given variables, it must evaluate to a bool.
+func (p *Program) Eval(vars map[string]interface{}) (bool, error) {
+	v, err := p.root.eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate %q: %q", p.expr, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q evaluated to %T, not bool", p.expr, v)
+	}
+	return b, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	// value holds the decoded literal of string and number tokens.
+	value interface{}
+}
+
+// ops lists the operators and punctuation, longest first.
+var ops = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "(", ")", "[", "]", ".", ","}
+
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != s[i] {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			body := s[i+1 : j]
+			if c == '\'' {
+				body = strings.Replace(strings.Replace(body, `\'`, `'`, -1), `"`, `\"`, -1)
+			}
+			v, err := strconv.Unquote(`"` + body + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %q", i, err)
+			}
+			toks = append(toks, token{kind: tokString, text: s[i : j+1], value: v})
+			i = j + 1
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.' || s[j] == 'e' || s[j] == 'E') {
+				j++
+			}
+			v, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[i:j])
+			}
+			toks = append(toks, token{kind: tokNumber, text: s[i:j], value: v})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range ops {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			toks = append(toks, token{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given operator or keyword.
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokOp || t.kind == tokIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected %q but got %q", text, p.peek().text)
+	}
+	return nil
+}
+
+func (p *parser) expr() (node, error) {
+	return p.binary(0)
+}
+
+// levels lists binary operators by increasing precedence.
+var levels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+}
+
+func (p *parser) binary(level int) (node, error) {
+	if level == len(levels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range levels[level] {
+			if p.accept(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			n, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: op, operand: n}, nil
+		}
+	}
+	return p.member()
+}
+
+func (p *parser) member() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected field name but got %q", t.text)
+			}
+			if p.accept("(") {
+				args, err := p.list(")")
+				if err != nil {
+					return nil, err
+				}
+				n = &callNode{fn: t.text, target: n, args: args}
+				continue
+			}
+			n = &selectNode{operand: n, field: t.text}
+		case p.accept("["):
+			key, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{operand: n, key: key}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString, tokNumber:
+		return &literalNode{value: t.value}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.accept("(") {
+			args, err := p.list(")")
+			if err != nil {
+				return nil, err
+			}
+			if t.text == "has" {
+				if len(args) != 1 {
+					return nil, fmt.Errorf("has takes a single field selection")
+				}
+				sel, ok := args[0].(*selectNode)
+				if !ok {
+					return nil, fmt.Errorf("has takes a single field selection")
+				}
+				return &hasNode{sel: sel}, nil
+			}
+			return &callNode{fn: t.text, args: args}, nil
+		}
+		return &identNode{name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			items, err := p.list("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// list parses comma separated expressions up to the closing token.
+func (p *parser) list(end string) ([]node, error) {
+	var items []node
+	if p.accept(end) {
+		return items, nil
+	}
+	for {
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, n)
+		if p.accept(end) {
+			return items, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type node interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) { return n.value, nil }
+
+type identNode struct{ name string }
+
+func (n *identNode) eval(vars map[string]interface{}) (interface{}, error) {
+	v, ok := vars[n.name]
+	if !ok {
+		return nil, fmt.Errorf("undeclared reference to %q", n.name)
+	}
+	return normalize(v), nil
+}
+
+type listNode struct{ items []node }
+
+func (n *listNode) eval(vars map[string]interface{}) (interface{}, error) {
+	l := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, v)
+	}
+	return l, nil
+}
+
+type selectNode struct {
+	operand node
+	field   string
+}
+
+func (n *selectNode) eval(vars map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot select field %q from %T", n.field, v)
+	}
+	f, ok := m[n.field]
+	if !ok {
+		return nil, fmt.Errorf("no such key %q", n.field)
+	}
+	return normalize(f), nil
+}
+
+type hasNode struct{ sel *selectNode }
+
+func (n *hasNode) eval(vars map[string]interface{}) (interface{}, error) {
+	v, err := n.sel.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot test field %q of %T", n.sel.field, v)
+	}
+	_, ok = m[n.sel.field]
+	return ok, nil
+}
+
+type indexNode struct{ operand, key node }
+
+func (n *indexNode) eval(vars map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	k, err := n.key.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch c := v.(type) {
+	case map[string]interface{}:
+		s, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("map keys must be strings, got %T", k)
+		}
+		f, ok := c[s]
+		if !ok {
+			return nil, fmt.Errorf("no such key %q", s)
+		}
+		return normalize(f), nil
+	case []interface{}:
+		i, ok := k.(float64)
+		if !ok || i != float64(int(i)) {
+			return nil, fmt.Errorf("list index must be an integer, got %v", k)
+		}
+		if i < 0 || int(i) >= len(c) {
+			return nil, fmt.Errorf("index %d out of range", int(i))
+		}
+		return normalize(c[int(i)]), nil
+	}
+	return nil, fmt.Errorf("cannot index %T", v)
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case bool:
+		if n.op == "!" {
+			return !x, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -x, nil
+		}
+	}
+	return nil, fmt.Errorf("no such overload %s%T", n.op, v)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "&&", "||":
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, fmt.Errorf("no such overload %T %s", l, n.op)
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return lb, nil
+		}
+		r, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("no such overload %s %T", n.op, r)
+		}
+		return rb, nil
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return reflect.DeepEqual(l, r), nil
+	case "!=":
+		return !reflect.DeepEqual(l, r), nil
+	case "in":
+		switch c := r.(type) {
+		case []interface{}:
+			for _, item := range c {
+				if reflect.DeepEqual(normalize(item), l) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			s, ok := l.(string)
+			if !ok {
+				return false, nil
+			}
+			_, ok = c[s]
+			return ok, nil
+		}
+		return nil, fmt.Errorf("no such overload %T in %T", l, r)
+	}
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "<":
+			return lv < rv, nil
+		case "<=":
+			return lv <= rv, nil
+		case ">":
+			return lv > rv, nil
+		case ">=":
+			return lv >= rv, nil
+		case "+":
+			return lv + rv, nil
+		case "-":
+			return lv - rv, nil
+		}
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "<":
+			return lv < rv, nil
+		case "<=":
+			return lv <= rv, nil
+		case ">":
+			return lv > rv, nil
+		case ">=":
+			return lv >= rv, nil
+		case "+":
+			return lv + rv, nil
+		}
+	}
+	return nil, fmt.Errorf("no such overload %T %s %T", l, n.op, r)
+}
+
+type callNode struct {
+	fn     string
+	target node
+	args   []node
+}
+
+func (n *callNode) eval(vars map[string]interface{}) (interface{}, error) {
+	var args []interface{}
+	if n.target != nil {
+		v, err := n.target.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	for _, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	if n.fn == "size" && len(args) == 1 {
+		switch v := args[0].(type) {
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("no such overload size(%T)", args[0])
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("unknown function %q with %d arguments", n.fn, len(args))
+	}
+	s, ok1 := args[0].(string)
+	a, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("no such overload %T.%s(%T)", args[0], n.fn, args[1])
+	}
+	switch n.fn {
+	case "startsWith":
+		return strings.HasPrefix(s, a), nil
+	case "endsWith":
+		return strings.HasSuffix(s, a), nil
+	case "contains":
+		return strings.Contains(s, a), nil
+	case "matches":
+		re, err := regexp.Compile(a)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(s), nil
+	}
+	return nil, fmt.Errorf("unknown function %q", n.fn)
+}
+
+// normalize converts Go values into the types produced by decoding JSON.
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case []string:
+		l := make([]interface{}, 0, len(x))
+		for _, s := range x {
+			l = append(l, s)
+		}
+		return l
+	case map[string]string:
+		m := make(map[string]interface{}, len(x))
+		for k, s := range x {
+			m[k] = s
+		}
+		return m
+	}
+	return v
+}
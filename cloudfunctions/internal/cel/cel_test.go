@@ -0,0 +1,102 @@
+package cel
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	var finding map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"severity": "HIGH",
+		"category": "PUBLIC_BUCKET_ACL",
+		"sourceProperties": {"ReactivationCount": 2, "ExceptionInstructions": "Add a security mark"},
+		"tags": ["pci", "prod"]
+	}`), &finding); err != nil {
+		t.Fatalf("failed to decode finding: %q", err)
+	}
+	vars := map[string]interface{}{
+		"finding":  finding,
+		"resource": map[string]interface{}{"project": "prod-web"},
+	}
+	for _, tt := range []struct {
+		name, expr string
+		want       bool
+	}{
+		{name: "severity and project prefix", expr: `finding.severity == "HIGH" && resource.project.startsWith("prod-")`, want: true},
+		{name: "project prefix mismatch", expr: `resource.project.startsWith("dev-")`, want: false},
+		{name: "or", expr: `finding.severity == 'LOW' || finding.severity == 'HIGH'`, want: true},
+		{name: "negation", expr: `!(finding.category in ["OPEN_FIREWALL", "PUBLIC_IP_ADDRESS"])`, want: true},
+		{name: "list membership", expr: `"pci" in finding.tags`, want: true},
+		{name: "number comparison", expr: `finding.sourceProperties.ReactivationCount >= 2`, want: true},
+		{name: "index", expr: `finding["tags"][1] == "prod"`, want: true},
+		{name: "size", expr: `size(finding.tags) == 2 && finding.category.size() > 3`, want: true},
+		{name: "matches", expr: `resource.project.matches("^prod-[a-z]+$")`, want: true},
+		{name: "contains and ends with", expr: `finding.category.contains("BUCKET") && finding.category.endsWith("_ACL")`, want: true},
+		{name: "has", expr: `has(finding.resourceName)`, want: false},
+		{name: "short circuit", expr: `has(finding.resourceName) && finding.resourceName == "x"`, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			got, err := p.Eval(vars)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s failed got:%v want:%v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := map[string]interface{}{"finding": map[string]interface{}{"severity": "HIGH"}}
+	for _, tt := range []struct{ name, expr string }{
+		{name: "missing key", expr: `finding.state == "ACTIVE"`},
+		{name: "undeclared variable", expr: `resource.project == "p"`},
+		{name: "not a bool", expr: `finding.severity`},
+		{name: "bad overload", expr: `finding.severity > 1`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if _, err := p.Eval(vars); err == nil {
+				t.Errorf("%s expected an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		`finding.severity ==`,
+		`finding.severity == "HIGH`,
+		`(true`,
+		`has(true)`,
+		`true true`,
+		`finding.severity # 1`,
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q) expected an error", expr)
+		}
+	}
+}
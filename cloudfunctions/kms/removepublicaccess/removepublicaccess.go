@@ -0,0 +1,89 @@
+package removepublicaccess
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "kms_remove_public_access",
+		EntryPoint: "RemoveKeyPublicAccess",
+		Topic:      "threat-findings-remove-key-public-access",
+		Services:   []string{"KMS"},
+		Roles:      []string{"roles/cloudkms.admin"},
+		Permissions: []string{
+			"cloudkms.cryptoKeys.getIamPolicy", "cloudkms.cryptoKeys.setIamPolicy",
+			"cloudkms.keyRings.getIamPolicy", "cloudkms.keyRings.setIamPolicy",
+		},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// KeyName is the crypto key, named
+	// "projects/<id>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>", or the key ring.
+	KeyName string
+	// AllowDomains are the domains of the users, groups and domains kept, only allUsers and
+	// allAuthenticatedUsers are removed if empty.
+	AllowDomains []string
+	DryRun       bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	KMS    *services.KMS
+	Logger *services.Logger
+}
+
+// Execute removes allUsers, allAuthenticatedUsers and the members outside the allowed domains from
+// the IAM policies of the crypto key and of its key ring, which grant access to the key as well.
+// The grants removed are logged as changes, so they can be granted again.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	resources := []string{values.KeyName}
+	if i := strings.Index(values.KeyName, "/cryptoKeys/"); i >= 0 {
+		resources = append(resources, values.KeyName[:i])
+	}
+	remediated := true
+	for _, resource := range resources {
+		members, err := services.KMS.ExternalMembers(ctx, resource, values.AllowDomains)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get policy of %q", resource)
+		}
+		if len(members) == 0 {
+			continue
+		}
+		remediated = false
+		if values.DryRun {
+			services.Logger.Info("dry_run on, would have removed %q from %q", members, resource)
+			continue
+		}
+		if err := services.KMS.RemoveMembers(ctx, resource, members); err != nil {
+			return errors.Wrapf(err, "failed to remove members from %q", resource)
+		}
+		services.Logger.Info("removed %q from %q", members, resource)
+	}
+	if remediated {
+		return registry.ErrAlreadyRemediated
+	}
+	return nil
+}
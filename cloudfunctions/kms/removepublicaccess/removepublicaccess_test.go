@@ -0,0 +1,93 @@
+package removepublicaccess
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+func TestRemovePublicAccess(t *testing.T) {
+	ctx := context.Background()
+	const (
+		ring = "projects/test-project/locations/global/keyRings/ring"
+		key  = ring + "/cryptoKeys/key"
+	)
+	encrypter := "roles/cloudkms.cryptoKeyEncrypterDecrypter"
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		policies      map[string]*cloudkms.Policy
+		wantResource  string
+		wantPolicy    *cloudkms.Policy
+		expectedError error
+	}{
+		{
+			name:   "public key",
+			values: &Values{ProjectID: "test-project", KeyName: key},
+			policies: map[string]*cloudkms.Policy{
+				key: {Bindings: []*cloudkms.Binding{{Role: encrypter, Members: []string{"allUsers", "user:alice@example.com"}}}},
+			},
+			wantResource: key,
+			wantPolicy:   &cloudkms.Policy{Bindings: []*cloudkms.Binding{{Role: encrypter, Members: []string{"user:alice@example.com"}}}},
+		},
+		{
+			name:   "external member on key ring",
+			values: &Values{ProjectID: "test-project", KeyName: key, AllowDomains: []string{"example.com"}},
+			policies: map[string]*cloudkms.Policy{
+				ring: {Bindings: []*cloudkms.Binding{{Role: encrypter, Members: []string{"user:alice@example.com", "user:mallory@attacker.com"}}}},
+			},
+			wantResource: ring,
+			wantPolicy:   &cloudkms.Policy{Bindings: []*cloudkms.Binding{{Role: encrypter, Members: []string{"user:alice@example.com"}}}},
+		},
+		{
+			name:   "not public",
+			values: &Values{ProjectID: "test-project", KeyName: key},
+			policies: map[string]*cloudkms.Policy{
+				key: {Bindings: []*cloudkms.Binding{{Role: encrypter, Members: []string{"user:alice@example.com"}}}},
+			},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "test-project", KeyName: key, DryRun: true},
+			policies: map[string]*cloudkms.Policy{
+				key: {Bindings: []*cloudkms.Binding{{Role: encrypter, Members: []string{"allUsers"}}}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.KMSStub{StubbedPolicies: tt.policies}
+			if err := Execute(ctx, tt.values, &Services{
+				KMS:    services.NewKMS(stub),
+				Logger: services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantPolicy, stub.SavedPolicy); diff != "" {
+				t.Errorf("%s policy (-want +got):\n%s", tt.name, diff)
+			}
+			if stub.SavedResource != tt.wantResource {
+				t.Errorf("%s set the policy of %q, want %q", tt.name, stub.SavedResource, tt.wantResource)
+			}
+		})
+	}
+}
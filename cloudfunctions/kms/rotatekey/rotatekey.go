@@ -0,0 +1,96 @@
+package rotatekey
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "kms_rotate_key",
+		EntryPoint:  "RotateKey",
+		Topic:       "threat-findings-rotate-key",
+		Services:    []string{"KMS"},
+		Roles:       []string{"roles/cloudkms.admin"},
+		Permissions: []string{"cloudkms.cryptoKeys.get", "cloudkms.cryptoKeys.update"},
+	})
+}
+
+const (
+	// defaultRotationPeriodDays is how often keys are rotated if the period is not set, the
+	// longest period Security Health Analytics accepts.
+	defaultRotationPeriodDays = 90
+	// defaultNextRotationHours is how soon keys are first rotated if it is not set.
+	defaultNextRotationHours = 24
+)
+
+// now returns the current time, replaced by tests.
+var now = time.Now
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// KeyName is the crypto key, named
+	// "projects/<id>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>".
+	KeyName string
+	// RotationPeriodDays is how often the key is rotated, 90 days if zero.
+	RotationPeriodDays int
+	// NextRotationHours is how soon the key is first rotated, a day if zero.
+	NextRotationHours int
+	DryRun            bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	KMS    *services.KMS
+	Logger *services.Logger
+}
+
+// Execute sets the rotation period and the next rotation time of the crypto key. Keys already
+// rotated at least as often are left as they are.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	days := values.RotationPeriodDays
+	if days <= 0 {
+		days = defaultRotationPeriodDays
+	}
+	hours := values.NextRotationHours
+	if hours <= 0 {
+		hours = defaultNextRotationHours
+	}
+	period := time.Duration(days) * 24 * time.Hour
+	current, err := services.KMS.RotationPeriod(ctx, values.KeyName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get crypto key %q", values.KeyName)
+	}
+	if current > 0 && current <= period {
+		return registry.ErrAlreadyRemediated
+	}
+	next := now().Add(time.Duration(hours) * time.Hour)
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have rotated %q every %d days from %s", values.KeyName, days, next.Format(time.RFC3339))
+		return nil
+	}
+	if err := services.KMS.SetRotation(ctx, values.KeyName, period, next); err != nil {
+		return errors.Wrapf(err, "failed to set rotation of %q", values.KeyName)
+	}
+	services.Logger.Info("rotating %q every %d days from %s", values.KeyName, days, next.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,79 @@
+package rotatekey
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+func TestRotateKey(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+	const key = "projects/test-project/locations/global/keyRings/ring/cryptoKeys/key"
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		period        string
+		expected      *cloudkms.CryptoKey
+		expectedError error
+	}{
+		{
+			name:     "not rotated",
+			values:   &Values{ProjectID: "test-project", KeyName: key},
+			expected: &cloudkms.CryptoKey{RotationPeriod: "7776000s", NextRotationTime: "2020-06-02T18:00:00Z"},
+		},
+		{
+			name:     "rotated too rarely",
+			values:   &Values{ProjectID: "test-project", KeyName: key, RotationPeriodDays: 30, NextRotationHours: 1},
+			period:   "7776000s",
+			expected: &cloudkms.CryptoKey{RotationPeriod: "2592000s", NextRotationTime: "2020-06-01T19:00:00Z"},
+		},
+		{
+			name:          "rotated often enough",
+			values:        &Values{ProjectID: "test-project", KeyName: key},
+			period:        "2592000s",
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "test-project", KeyName: key, DryRun: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.KMSStub{StubbedKeys: map[string]*cloudkms.CryptoKey{
+				key: {Name: key, Purpose: "ENCRYPT_DECRYPT", RotationPeriod: tt.period},
+			}}
+			if err := Execute(ctx, tt.values, &Services{
+				KMS:    services.NewKMS(stub),
+				Logger: services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.expected, stub.SavedKey); diff != "" {
+				t.Errorf("%s key (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
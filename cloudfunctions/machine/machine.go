@@ -0,0 +1,166 @@
+// Package machine runs remediations that take minutes, such as stopping, modifying and starting an
+// instance, as a sequence of steps.
+//
+// Each step runs in its own invocation: it returns the step to run next and how long to wait for
+// it, the run's state is saved and Cloud Tasks publishes the run back to the action's topic when
+// the next step is due. No invocation blocks waiting for a long operation to finish.
+package machine
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/uuid"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+const (
+	// RunAttribute carries the ID of the run a message continues.
+	RunAttribute = "run"
+	// stepAttribute carries the step a message continues the run with.
+	stepAttribute = "step"
+)
+
+// Done is returned as the next step by the last step of a run.
+const Done = ""
+
+const (
+	// Running runs have steps left.
+	Running = "RUNNING"
+	// Succeeded runs completed their last step.
+	Succeeded = "SUCCEEDED"
+	// Failed runs stopped at a step returning an error.
+	Failed = "FAILED"
+)
+
+// Step runs one step of a run, returning the name of the step to run next, or Done, and how long
+// to wait before running it. Steps record what later steps need in the run's Data.
+type Step func(ctx context.Context, run *Run) (next string, after time.Duration, err error)
+
+// Machine describes a multi-step remediation.
+type Machine struct {
+	// Name identifies the machine in its runs, such as the name of its action.
+	Name string
+	// Topic is the topic triggering the action, runs are continued by publishing to it.
+	Topic string
+	// Start is the name of the first step.
+	Start string
+	// Steps maps step names to steps.
+	Steps map[string]Step
+}
+
+// Run is the persisted state of one run of a machine.
+type Run struct {
+	ID      string
+	Machine string
+	// Step is the step to run next, Done once the run finished.
+	Step string
+	// Values are the values of the message that started the run.
+	Values json.RawMessage
+	// Data holds what steps pass on to later steps.
+	Data    map[string]string
+	Status  string
+	Error   string `json:",omitempty"`
+	Started time.Time
+	Updated time.Time
+}
+
+// Services contains the services needed to run a machine.
+type Services struct {
+	Checkpoints *services.Checkpoints
+	Tasks       *services.Tasks
+	Logger      *services.Logger
+}
+
+// Execute runs the next step of the run the message continues, or starts a new run with the
+// message's values as the first step.
+//
+// Messages for a step the run already moved past are redeliveries and ignored.
+func Execute(ctx context.Context, machine *Machine, m pubsub.Message, services *Services) error {
+	run, err := load(ctx, machine, m, services)
+	if err != nil || run == nil {
+		return err
+	}
+	step, ok := machine.Steps[run.Step]
+	if !ok {
+		return fmt.Errorf("%s has no step %q", machine.Name, run.Step)
+	}
+	next, after, err := step(ctx, run)
+	run.Updated = time.Now()
+	if err != nil {
+		run.Status = Failed
+		run.Error = err.Error()
+		if serr := services.Checkpoints.Save(ctx, run.ID, run); serr != nil {
+			services.Logger.Error("failed to save run %s: %q", run.ID, serr)
+		}
+		return fmt.Errorf("%s run %s failed at step %q: %q", machine.Name, run.ID, run.Step, err)
+	}
+	run.Step = next
+	if next == Done {
+		run.Status = Succeeded
+		services.Logger.Info("%s run %s succeeded", machine.Name, run.ID)
+		return services.Checkpoints.Save(ctx, run.ID, run)
+	}
+	if _, ok := machine.Steps[next]; !ok {
+		return fmt.Errorf("%s has no step %q", machine.Name, next)
+	}
+	if err := services.Checkpoints.Save(ctx, run.ID, run); err != nil {
+		return err
+	}
+	attributes := map[string]string{RunAttribute: run.ID, stepAttribute: next}
+	for k, v := range m.Attributes {
+		if _, ok := attributes[k]; !ok {
+			attributes[k] = v
+		}
+	}
+	return services.Tasks.PublishAt(ctx, machine.Topic, run.Values, attributes, run.Updated.Add(after))
+}
+
+// load returns the run the message continues, a new run if it starts one or nil if the message
+// is a redelivery of a step already run.
+func load(ctx context.Context, machine *Machine, m pubsub.Message, services *Services) (*Run, error) {
+	id := m.Attributes[RunAttribute]
+	if id == "" {
+		now := time.Now()
+		return &Run{
+			ID:      uuid.New().String(),
+			Machine: machine.Name,
+			Step:    machine.Start,
+			Values:  m.Data,
+			Data:    make(map[string]string),
+			Status:  Running,
+			Started: now,
+			Updated: now,
+		}, nil
+	}
+	var run Run
+	ok, err := services.Checkpoints.Load(ctx, id, &run)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s run %s not found", machine.Name, id)
+	}
+	if run.Status != Running || run.Step != m.Attributes[stepAttribute] {
+		services.Logger.Info("%s run %s already ran step %q, ignoring", machine.Name, id, m.Attributes[stepAttribute])
+		return nil, nil
+	}
+	return &run, nil
+}
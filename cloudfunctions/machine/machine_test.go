@@ -0,0 +1,126 @@
+package machine
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// published returns the message the task publishes.
+func published(t *testing.T, tasks *stubs.CloudTasksStub) pubsub.Message {
+	t.Helper()
+	task := tasks.CreatedTasks[len(tasks.CreatedTasks)-1]
+	b, err := base64.StdEncoding.DecodeString(task.HttpRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to decode task: %q", err)
+	}
+	var body struct {
+		Messages []struct {
+			Attributes map[string]string
+			Data       []byte
+		}
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatalf("failed to decode task: %q", err)
+	}
+	return pubsub.Message{Data: body.Messages[0].Data, Attributes: body.Messages[0].Attributes}
+}
+
+func TestMachine(t *testing.T) {
+	ctx := context.Background()
+	var ran []string
+	step := func(name, next string, fail bool) Step {
+		return func(ctx context.Context, run *Run) (string, time.Duration, error) {
+			ran = append(ran, name)
+			if fail {
+				return "", 0, errors.New("failed")
+			}
+			run.Data[name] = "done"
+			return next, time.Minute, nil
+		}
+	}
+	test := []struct {
+		name           string
+		failing        string
+		expectedRan    []string
+		expectedStatus string
+	}{
+		{name: "runs every step", expectedRan: []string{"stop", "modify", "start"}, expectedStatus: Succeeded},
+		{name: "stops at a failing step", failing: "modify", expectedRan: []string{"stop", "modify"}, expectedStatus: Failed},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			machine := &Machine{
+				Name:  "gce_rotate_instance",
+				Topic: "threat-findings-rotate-instance",
+				Start: "stop",
+				Steps: map[string]Step{
+					"stop":   step("stop", "modify", tt.failing == "stop"),
+					"modify": step("modify", "start", tt.failing == "modify"),
+					"start":  step("start", Done, tt.failing == "start"),
+				},
+			}
+			tasks := &stubs.CloudTasksStub{}
+			checkpoints := services.NewCheckpoints(&stubs.FirestoreStub{}, "automation-project", "runs")
+			svcs := &Services{
+				Checkpoints: checkpoints,
+				Tasks:       services.NewTasks(tasks, "queue", "automation-project", "sa@automation-project.iam.gserviceaccount.com"),
+				Logger:      services.NewLogger(&stubs.LoggerStub{}),
+			}
+			m := pubsub.Message{Data: []byte(`{"ProjectID":"p"}`), Attributes: map[string]string{"finding": "f"}}
+			var first pubsub.Message
+			for i := 0; i < 5; i++ {
+				if err := Execute(ctx, machine, m, svcs); err != nil {
+					break
+				}
+				if len(tasks.CreatedTasks) != i+1 {
+					break
+				}
+				m = published(t, tasks)
+				if i == 0 {
+					first = m
+				}
+			}
+			if diff := cmp.Diff(tt.expectedRan, ran); diff != "" {
+				t.Errorf("%s ran difference:%+v", tt.name, diff)
+			}
+			if first.Attributes["finding"] != "f" || string(first.Data) != `{"ProjectID":"p"}` {
+				t.Errorf("%s continued with %+v", tt.name, first)
+			}
+			var run Run
+			if ok, err := checkpoints.Load(ctx, first.Attributes[RunAttribute], &run); err != nil || !ok {
+				t.Fatalf("%s run not saved: %v", tt.name, err)
+			}
+			if run.Status != tt.expectedStatus {
+				t.Errorf("%s status got:%q want:%q", tt.name, run.Status, tt.expectedStatus)
+			}
+			ran = nil
+			if err := Execute(ctx, machine, first, svcs); err != nil || len(ran) != 0 {
+				t.Errorf("%s redelivery ran %v, %v", tt.name, ran, err)
+			}
+		})
+	}
+}
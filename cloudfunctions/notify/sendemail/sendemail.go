@@ -0,0 +1,72 @@
+package sendemail
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "send_email",
+		EntryPoint: "SendEmail",
+		Topic:      "threat-findings-send-email",
+		Services:   []string{"Email"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID     string
+	Subject, Body string
+	From          string
+	To            []string
+	DryRun        bool
+	// CorrelationID of the finding the email is about, appended to the body if set.
+	CorrelationID string
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Email  *services.Email
+	Logger *services.Logger
+	// Redactor scrubs sensitive material from the subject and body, none is if nil.
+	Redactor *services.Redactor
+}
+
+// Execute sends a notification email about the finding.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if len(values.To) == 0 || values.From == "" {
+		return fmt.Errorf("sender and recipients are required")
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have sent %q to %q", values.Subject, values.To)
+		return nil
+	}
+	subject, body := services.Redactor.Redact(values.Subject), services.Redactor.Redact(values.Body)
+	if values.CorrelationID != "" {
+		body += "\n\nCorrelation ID: " + values.CorrelationID
+	}
+	if _, err := services.Email.Send(subject, values.From, body, values.To); err != nil {
+		return errors.Wrapf(err, "failed to send %q", subject)
+	}
+	services.Logger.Info("sent %q to %q", subject, values.To)
+	return nil
+}
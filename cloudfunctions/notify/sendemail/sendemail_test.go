@@ -0,0 +1,59 @@
+package sendemail
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/sendgrid/rest"
+)
+
+func TestSendEmail(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name        string
+		to          []string
+		status      int
+		expectError bool
+	}{
+		{name: "send", to: []string{"secops@example.com"}, status: http.StatusAccepted},
+		{name: "no recipients", expectError: true},
+		{name: "rejected", to: []string{"secops@example.com"}, status: http.StatusUnauthorized, expectError: true},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := &clients.SendGrid{Service: &stubs.SendGridStub{StubbedSend: &rest.Response{StatusCode: tt.status}}}
+			values := &Values{
+				ProjectID: "test-project",
+				Subject:   "Critical vulnerability CVE-2021-3711",
+				Body:      "nginx is affected by CVE-2021-3711.",
+				From:      "sra@example.com",
+				To:        tt.to,
+			}
+			err := Execute(ctx, values, &Services{
+				Email:  services.NewEmail(sg),
+				Logger: services.NewLogger(&stubs.LoggerStub{}),
+			})
+			if (err != nil) != tt.expectError {
+				t.Errorf("%s failed, got error: %v want error: %t", tt.name, err, tt.expectError)
+			}
+		})
+	}
+}
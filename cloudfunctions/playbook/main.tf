@@ -0,0 +1,48 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "send-email" {
+resource "google_cloudfunctions_function" "playbook" {
+  name                  = "Playbook"
+  description           = "Runs the automations of a playbook in order for one finding."
+  runtime               = "go113"
+  available_memory_mb   = 256
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 540
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "Playbook"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-playbook"
+  }
+  environment_variables = {
+    GCP_PROJECT      = var.setup.automation-project
+    SENDGRID_API_KEY = var.sendgrid-api-key
+  }
+}
+
+# PubSub topic to trigger this automation.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-playbook"
+  project = var.setup.automation-project
+}
+
+# PubSub topic receiving the outcome of each step of every playbook.
+resource "google_pubsub_topic" "results" {
+  name    = "threat-findings-playbook-results"
+  project = var.setup.automation-project
+}
@@ -0,0 +1,263 @@
+// Package playbook runs the automations of a playbook in order for one finding.
+//
+// The router collects the automations configured with the same playbook name into a single
+// message. Each step runs in this function, in order, and its outcome is recorded in the results
+// published once the playbook completes.
+//
+// Steps can branch on a condition evaluated when the step runs, against the finding and the live
+// attributes of the instance the step acts on. Steps whose condition is not met are skipped or
+// held for approval.
+package playbook
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/internal/cel"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// ResultsTopic receives the outcome of every playbook.
+const ResultsTopic = "threat-findings-playbook-results"
+
+// approvalTopic receives the steps held for approval, shared with the router.
+const approvalTopic = "threat-findings-approval-required"
+
+// correlationAttribute is the attribute of the messages sent for a finding holding its correlation ID.
+const correlationAttribute = services.CorrelationAttribute
+
+const (
+	// Pending steps have not run yet.
+	Pending = "PENDING"
+	// Succeeded steps ran without error.
+	Succeeded = "SUCCEEDED"
+	// Failed steps returned an error.
+	Failed = "FAILED"
+	// Skipped steps did not run because an earlier step failed or their condition was not met.
+	Skipped = "SKIPPED"
+	// Held steps were sent for approval instead of running.
+	Held = "HELD"
+)
+
+const (
+	// Skip skips a step whose condition is not met, the default.
+	Skip = "skip"
+	// Approval holds a step whose condition is not met for approval.
+	Approval = "approval"
+)
+
+// Step is an automation run by a playbook.
+type Step struct {
+	Action string
+	// Topic is the automation's topic, steps held for approval are approved by publishing to it.
+	Topic string
+	// Values are the values the automation runs with.
+	Values json.RawMessage
+	// Resource is the project or account the step acts on.
+	Resource map[string]interface{}
+	// Condition is an optional CEL expression evaluated when the step runs.
+	Condition string `json:",omitempty"`
+	// Otherwise is what happens to the step if its condition is not met, Skip or Approval.
+	Otherwise string `json:",omitempty"`
+	Status    string
+	Error     string `json:",omitempty"`
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// Name is the name of the playbook.
+	Name string
+	// Finding is the finding the playbook runs for.
+	Finding string
+	// CorrelationID identifies everything done for the finding.
+	CorrelationID string
+	// Details are the contents of the finding, conditions reference them as `finding`.
+	Details map[string]interface{}
+	// Steps run in order, the steps after a failed step are skipped.
+	Steps []*Step
+}
+
+// Handler is the entry point of an automation.
+type Handler func(context.Context, pubsub.Message) error
+
+// Services contains the services needed for this function.
+type Services struct {
+	// Handlers maps action names to the entry points of their automations.
+	Handlers map[string]Handler
+	// Host looks up the instances steps act on for their conditions, conditions cannot reference
+	// `instance` if nil.
+	Host   *services.Host
+	PubSub *services.PubSub
+	Logger *services.Logger
+}
+
+// Execute runs the steps of the playbook in order and publishes the outcome of each.
+//
+// Steps receive the playbook's name, the finding, its correlation ID and their position as message
+// attributes.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	correlated := *services
+	correlated.Logger = services.Logger.WithCorrelation(values.CorrelationID)
+	services = &correlated
+	var failed *Step
+	for i, step := range values.Steps {
+		if failed != nil {
+			step.Status = Skipped
+			continue
+		}
+		ok, err := conditionMet(ctx, values, step, services)
+		if err == nil && !ok {
+			err = otherwise(ctx, values, i, services)
+			if err == nil {
+				services.Logger.Info("playbook %q step %d %q condition not met: %s", values.Name, i+1, step.Action, step.Status)
+				continue
+			}
+		}
+		if err == nil {
+			err = run(ctx, values, i, services)
+		}
+		if err != nil {
+			step.Status = Failed
+			step.Error = err.Error()
+			services.Logger.Error("playbook %q step %d %q failed: %q", values.Name, i+1, step.Action, err)
+			failed = step
+			continue
+		}
+		step.Status = Succeeded
+		services.Logger.Info("playbook %q step %d %q succeeded", values.Name, i+1, step.Action)
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	results := &pubsub.Message{Data: b}
+	if values.CorrelationID != "" {
+		results.Attributes = map[string]string{correlationAttribute: values.CorrelationID}
+	}
+	if _, err := services.PubSub.Publish(ctx, ResultsTopic, results); err != nil {
+		return errors.Wrapf(err, "failed to publish results of playbook %q", values.Name)
+	}
+	if failed != nil {
+		return fmt.Errorf("playbook %q failed at %q: %s", values.Name, failed.Action, failed.Error)
+	}
+	return nil
+}
+
+func run(ctx context.Context, values *Values, i int, services *Services) error {
+	step := values.Steps[i]
+	h, ok := services.Handlers[step.Action]
+	if !ok {
+		return fmt.Errorf("action %q cannot run in a playbook", step.Action)
+	}
+	return h(ctx, pubsub.Message{
+		Data:       step.Values,
+		Attributes: attributes(values, i),
+	})
+}
+
+func attributes(values *Values, i int) map[string]string {
+	a := map[string]string{
+		"playbook": values.Name,
+		"finding":  values.Finding,
+		"step":     strconv.Itoa(i + 1),
+	}
+	if values.CorrelationID != "" {
+		a[correlationAttribute] = values.CorrelationID
+	}
+	return a
+}
+
+// conditionMet evaluates the step's condition, steps without one always run.
+//
+// Conditions can reference `finding`, `resource` and, for steps acting on an instance,
+// `instance`: its name, zone, status, labels and network tags as they are when the step runs.
+func conditionMet(ctx context.Context, values *Values, step *Step, services *Services) (bool, error) {
+	if step.Condition == "" {
+		return true, nil
+	}
+	program, err := cel.Compile(step.Condition)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid condition")
+	}
+	vars := map[string]interface{}{"finding": values.Details, "resource": step.Resource}
+	instance, err := liveInstance(ctx, step, services)
+	if err != nil {
+		return false, err
+	}
+	if instance != nil {
+		vars["instance"] = instance
+	}
+	ok, err := program.Eval(vars)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to evaluate condition")
+	}
+	return ok, nil
+}
+
+// liveInstance returns the attributes of the instance the step acts on, nil if it does not act on
+// an instance.
+func liveInstance(ctx context.Context, step *Step, services *Services) (map[string]interface{}, error) {
+	var v struct {
+		ProjectID, Zone, Instance string
+	}
+	if err := json.Unmarshal(step.Values, &v); err != nil {
+		return nil, err
+	}
+	if services.Host == nil || v.ProjectID == "" || v.Zone == "" || v.Instance == "" {
+		return nil, nil
+	}
+	i, err := services.Host.Instance(ctx, v.ProjectID, v.Zone, v.Instance)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	if i.Tags != nil {
+		tags = i.Tags.Items
+	}
+	return map[string]interface{}{
+		"name":   i.Name,
+		"zone":   v.Zone,
+		"status": i.Status,
+		"labels": i.Labels,
+		"tags":   tags,
+	}, nil
+}
+
+// otherwise skips the step or holds it for approval when its condition is not met.
+func otherwise(ctx context.Context, values *Values, i int, services *Services) error {
+	step := values.Steps[i]
+	switch step.Otherwise {
+	case "", Skip:
+		step.Status = Skipped
+		return nil
+	case Approval:
+		a := attributes(values, i)
+		a["action"] = step.Action
+		a["topic"] = step.Topic
+		if _, err := services.PubSub.Publish(ctx, approvalTopic, &pubsub.Message{Data: step.Values, Attributes: a}); err != nil {
+			return errors.Wrapf(err, "failed to publish to %q", approvalTopic)
+		}
+		step.Status = Held
+		return nil
+	default:
+		return fmt.Errorf("invalid otherwise %q", step.Otherwise)
+	}
+}
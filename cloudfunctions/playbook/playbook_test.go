@@ -0,0 +1,187 @@
+package playbook
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestPlaybook(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name           string
+		actions        []string
+		failing        string
+		expectedStatus []string
+		expectedRan    []string
+		expectError    bool
+	}{
+		{
+			name:           "runs every step in order",
+			actions:        []string{"gce_create_disk_snapshot", "gce_quarantine_instance", "send_email"},
+			expectedStatus: []string{Succeeded, Succeeded, Succeeded},
+			expectedRan:    []string{"gce_create_disk_snapshot", "gce_quarantine_instance", "send_email"},
+		},
+		{
+			name:           "skips the steps after a failure",
+			actions:        []string{"gce_create_disk_snapshot", "gce_quarantine_instance", "send_email"},
+			failing:        "gce_quarantine_instance",
+			expectedStatus: []string{Succeeded, Failed, Skipped},
+			expectedRan:    []string{"gce_create_disk_snapshot", "gce_quarantine_instance"},
+			expectError:    true,
+		},
+		{
+			name:           "unknown action fails",
+			actions:        []string{"unknown", "send_email"},
+			expectedStatus: []string{Failed, Skipped},
+			expectError:    true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			handlers := make(map[string]Handler)
+			for _, a := range []string{"gce_create_disk_snapshot", "gce_quarantine_instance", "send_email"} {
+				action := a
+				handlers[action] = func(ctx context.Context, m pubsub.Message) error {
+					ran = append(ran, action)
+					if m.Attributes["playbook"] != "bad_ip" || m.Attributes["finding"] != "finding/1" {
+						t.Errorf("%v unexpected attributes: %v", action, m.Attributes)
+					}
+					if action == tt.failing {
+						return errors.New("failed")
+					}
+					return nil
+				}
+			}
+			values := &Values{Name: "bad_ip", Finding: "finding/1"}
+			for _, a := range tt.actions {
+				values.Steps = append(values.Steps, &Step{Action: a, Values: json.RawMessage(`{}`), Status: Pending})
+			}
+			psStub := &stubs.PubSubStub{}
+			err := Execute(ctx, values, &Services{
+				Handlers: handlers,
+				PubSub:   services.NewPubSub(psStub),
+				Logger:   services.NewLogger(&stubs.LoggerStub{}),
+			})
+			if (err != nil) != tt.expectError {
+				t.Errorf("%v unexpected error: %v", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expectedRan, ran); diff != "" {
+				t.Errorf("%v failed, difference in steps run: %+v", tt.name, diff)
+			}
+			var results Values
+			if err := json.Unmarshal(psStub.PublishedMessage.Data, &results); err != nil {
+				t.Fatalf("%v failed to decode results: %q", tt.name, err)
+			}
+			var status []string
+			for _, s := range results.Steps {
+				status = append(status, s.Status)
+			}
+			if diff := cmp.Diff(tt.expectedStatus, status); diff != "" {
+				t.Errorf("%v failed, difference in status: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestConditionalSteps(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name           string
+		env            string
+		otherwise      string
+		expectedStatus []string
+		expectedRan    []string
+	}{
+		{
+			name:           "condition met",
+			env:            "dev",
+			otherwise:      Approval,
+			expectedStatus: []string{Succeeded, Succeeded},
+			expectedRan:    []string{"gce_quarantine_instance", "send_email"},
+		},
+		{
+			name:           "held for approval",
+			env:            "prod",
+			otherwise:      Approval,
+			expectedStatus: []string{Held, Succeeded},
+			expectedRan:    []string{"send_email"},
+		},
+		{
+			name:           "skipped",
+			env:            "prod",
+			expectedStatus: []string{Skipped, Succeeded},
+			expectedRan:    []string{"send_email"},
+		},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			handlers := make(map[string]Handler)
+			for _, a := range []string{"gce_quarantine_instance", "send_email"} {
+				action := a
+				handlers[action] = func(ctx context.Context, m pubsub.Message) error {
+					ran = append(ran, action)
+					return nil
+				}
+			}
+			computeStub := &stubs.ComputeStub{}
+			computeStub.StubbedInstance = &compute.Instance{Name: "instance-1", Labels: map[string]string{"env": tt.env}}
+			psStub := &stubs.PubSubStub{}
+			values := &Values{
+				Name:    "contain",
+				Details: map[string]interface{}{"category": "bad_ip"},
+				Steps: []*Step{
+					{
+						Action:    "gce_quarantine_instance",
+						Values:    json.RawMessage(`{"ProjectID": "test-project", "Zone": "us-central1-a", "Instance": "instance-1"}`),
+						Condition: `finding.category == "bad_ip" && instance.labels.env != "prod"`,
+						Otherwise: tt.otherwise,
+						Status:    Pending,
+					},
+					{Action: "send_email", Values: json.RawMessage(`{}`), Status: Pending},
+				},
+			}
+			if err := Execute(ctx, values, &Services{
+				Handlers: handlers,
+				Host:     services.NewHost(computeStub),
+				PubSub:   services.NewPubSub(psStub),
+				Logger:   services.NewLogger(&stubs.LoggerStub{}),
+			}); err != nil {
+				t.Errorf("%v unexpected error: %v", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expectedRan, ran); diff != "" {
+				t.Errorf("%v failed, difference in steps run: %+v", tt.name, diff)
+			}
+			var status []string
+			for _, s := range values.Steps {
+				status = append(status, s.Status)
+			}
+			if diff := cmp.Diff(tt.expectedStatus, status); diff != "" {
+				t.Errorf("%v failed, difference in status: %+v", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package registry holds the remediation actions the router can dispatch findings to.
+//
+// Each action registers itself from its package's init function with the name automations are
+// configured with, the Cloud Function and topic running it and the services it requires.
+package registry
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrAlreadyRemediated is returned by actions finding their resource already in the state they
+// would put it in. The remediation is recorded as a no-op rather than a failure.
+var ErrAlreadyRemediated = errors.New("resource already remediated")
+
+// ErrPending is returned by actions deferring their change until more findings are seen, such as
+// until a threshold of findings is reached. The remediation is recorded as pending, counted
+// towards the threshold, rather than as a success.
+var ErrPending = errors.New("remediation pending")
+
+// Action describes a remediation action.
+type Action struct {
+	// Name is the name automations are configured with, such as "close_bucket".
+	Name string
+	// EntryPoint is the entry point of the Cloud Function running the action.
+	EntryPoint string
+	// Topic is the PubSub topic triggering the action.
+	Topic string
+	// Services lists the services the action requires, such as "Resource" or "Host".
+	Services []string
+	// Roles lists the roles the automation service account needs on the targeted folders.
+	Roles []string
+	// Permissions lists the permissions the action uses on the targeted folders, the least
+	// privileged alternative to Roles that custom roles are generated from.
+	Permissions []string
+	// Quota is the rate limited API the action is throttled by, such as "compute.snapshots.insert",
+	// shared by every action calling it.
+	Quota string
+	// QuotaPerMinute is how many times a minute the action may run, by default, when it has a quota.
+	QuotaPerMinute int
+	// Approval holds the action for approval by default, for actions whose mistakes are costly such
+	// as suspending users. Automations can opt out with `approval: false`.
+	Approval bool
+}
+
+var actions = make(map[string]Action)
+
+// Register adds the action to the registry, it panics if the action is already registered.
+func Register(a Action) {
+	if a.Name == "" || a.EntryPoint == "" || a.Topic == "" {
+		panic(fmt.Sprintf("registry: action %+v is missing its name, entry point or topic", a))
+	}
+	if _, ok := actions[a.Name]; ok {
+		panic(fmt.Sprintf("registry: action %q registered twice", a.Name))
+	}
+	actions[a.Name] = a
+}
+
+// Lookup returns the action registered with the given name.
+func Lookup(name string) (Action, bool) {
+	a, ok := actions[name]
+	return a, ok
+}
+
+// Actions returns the registered actions sorted by name.
+func Actions() []Action {
+	l := make([]Action, 0, len(actions))
+	for _, a := range actions {
+		l = append(l, a)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	return l
+}
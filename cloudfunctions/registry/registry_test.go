@@ -0,0 +1,47 @@
+package registry
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	a := Action{Name: "test_action", EntryPoint: "TestAction", Topic: "threat-findings-test-action", Services: []string{"Resource"}}
+	Register(a)
+	got, ok := Lookup("test_action")
+	if !ok || got.Topic != a.Topic {
+		t.Errorf("lookup failed, got %+v", got)
+	}
+	if _, ok := Lookup("unknown_action"); ok {
+		t.Errorf("unknown action found")
+	}
+	for _, tt := range []struct {
+		name   string
+		action Action
+	}{
+		{name: "registered twice", action: a},
+		{name: "missing topic", action: Action{Name: "other_action", EntryPoint: "OtherAction"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q did not panic", tt.name)
+				}
+			}()
+			Register(tt.action)
+		})
+	}
+}
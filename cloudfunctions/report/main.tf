@@ -0,0 +1,58 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "report" {
+  name                  = "Report"
+  description           = "Sends a weekly summary of the remediations taken."
+  runtime               = "go113"
+  available_memory_mb   = 128
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 60
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "Report"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-report"
+  }
+  environment_variables = {
+    GCP_PROJECT         = var.setup.automation-project
+    SENDGRID_API_KEY    = var.sendgrid-api-key
+    REPORT_FROM         = var.report-from
+    REPORT_TO           = join(",", var.report-to)
+    REPORT_CHAT_WEBHOOK = var.report-chat-webhook
+  }
+}
+
+# PubSub topic Cloud Scheduler publishes to when the report is due.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-report"
+  project = var.setup.automation-project
+}
+
+resource "google_cloud_scheduler_job" "weekly" {
+  name        = "threat-findings-report"
+  description = "Triggers the weekly remediation report."
+  project     = var.setup.automation-project
+  region      = var.setup.region
+  schedule    = var.report-schedule
+  time_zone   = "Etc/UTC"
+
+  pubsub_target {
+    topic_name = google_pubsub_topic.topic.id
+    data       = base64encode("{}")
+  }
+}
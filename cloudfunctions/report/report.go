@@ -0,0 +1,168 @@
+// Package report summarizes the remediations of the past days.
+package report
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// topProjects is how many of the noisiest projects the report lists.
+const topProjects = 5
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// From is the sender of the email report.
+	From string
+	// To lists the recipients of the email report, none is sent if empty.
+	To []string
+	// Days is the period the report covers, 7 if zero.
+	Days int
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	History *services.State
+	// Email sends the report, nil if email is not configured.
+	Email *services.Email
+	// Chat posts the report, nil if Google Chat is not configured.
+	Chat   *services.Chat
+	Logger *services.Logger
+}
+
+// Execute summarizes the remediations recorded in the history over the period and sends the
+// summary by email and to Google Chat if configured, or logs it otherwise.
+func Execute(ctx context.Context, m pubsub.Message, values *Values, services *Services) error {
+	days := values.Days
+	if days == 0 {
+		days = 7
+	}
+	until := time.Now().UTC()
+	since := until.AddDate(0, 0, -days)
+	remediations, err := services.History.Since(ctx, since)
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Security response automation report %s to %s", since.Format("2006-01-02"), until.Format("2006-01-02"))
+	body := summarize(remediations)
+	sent := false
+	if services.Email != nil && len(values.To) > 0 {
+		if _, err := services.Email.Send(subject, values.From, body, values.To); err != nil {
+			return err
+		}
+		services.Logger.Info("sent report to %q", values.To)
+		sent = true
+	}
+	if services.Chat != nil {
+		if err := services.Chat.Send(ctx, "*"+subject+"*\n"+body); err != nil {
+			return err
+		}
+		services.Logger.Info("posted report to chat")
+		sent = true
+	}
+	if !sent {
+		services.Logger.Info("%s\n%s", subject, body)
+	}
+	return nil
+}
+
+// summarize renders the findings handled, the outcomes and dry-run share of every action, the mean
+// time to remediate by category and the projects with the most remediations.
+func summarize(remediations []*services.Remediation) string {
+	findings := map[string]bool{}
+	// actions counts the remediations of every action by outcome.
+	actions := map[string]map[string]int{}
+	projects := map[string]int{}
+	// remediated sums the time to remediate of the findings of every category.
+	remediated := map[string]time.Duration{}
+	counts := map[string]int{}
+	for _, r := range remediations {
+		findings[r.Finding] = true
+		if actions[r.Action] == nil {
+			actions[r.Action] = map[string]int{}
+		}
+		actions[r.Action][r.Outcome]++
+		if p := r.ProjectID(); p != "" {
+			projects[p]++
+		}
+		if d, ok := r.TimeToRemediate(); ok {
+			remediated[r.Category] += d
+			counts[r.Category]++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Findings handled: %d\nActions taken: %d\n", len(findings), len(remediations))
+	if len(actions) > 0 {
+		b.WriteString("\nActions:\n")
+	}
+	names := make([]string, 0, len(actions))
+	for n := range actions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		o := actions[n]
+		fmt.Fprintf(&b, "- %s: %d succeeded, %d failed, %d already remediated, %d pending, %d dry run\n",
+			n, o[services.RemediationSucceeded], o[services.RemediationFailed], o[services.RemediationNoOp], o[services.RemediationPending], o[services.RemediationDryRun])
+	}
+	var deltas []string
+	for _, n := range names {
+		if c := actions[n][services.RemediationDryRun]; c > 0 {
+			deltas = append(deltas, fmt.Sprintf("- %s: %d\n", n, c))
+		}
+	}
+	if len(deltas) > 0 {
+		b.WriteString("\nDry run only, would have changed:\n" + strings.Join(deltas, ""))
+	}
+	categories := make([]string, 0, len(remediated))
+	for c := range remediated {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	if len(categories) > 0 {
+		b.WriteString("\nMean time to remediate:\n")
+	}
+	for _, c := range categories {
+		mean := remediated[c] / time.Duration(counts[c])
+		fmt.Fprintf(&b, "- %s: %s\n", c, mean.Round(time.Second))
+	}
+	top := make([]string, 0, len(projects))
+	for p := range projects {
+		top = append(top, p)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if projects[top[i]] != projects[top[j]] {
+			return projects[top[i]] > projects[top[j]]
+		}
+		return top[i] < top[j]
+	})
+	if len(top) > topProjects {
+		top = top[:topProjects]
+	}
+	if len(top) > 0 {
+		b.WriteString("\nTop projects:\n")
+	}
+	for _, p := range top {
+		fmt.Fprintf(&b, "- %s: %d\n", p, projects[p])
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package report
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	remediations := []*services.Remediation{
+		{ID: "1", Finding: "finding/1", Category: "public_bucket_acl", FindingCreated: now.Add(-90 * time.Minute), Action: "close_bucket", Resource: "projects/p1/buckets/b", Outcome: services.RemediationSucceeded, Started: now.Add(-time.Hour), Finished: now.Add(-time.Hour)},
+		{ID: "2", Finding: "finding/1", Action: "enable_bucket_only_policy", Resource: "projects/p1/buckets/b", Outcome: services.RemediationFailed, Started: now.Add(-time.Hour)},
+		{ID: "3", Finding: "finding/2", Action: "close_bucket", Resource: "projects/p2/buckets/c", Outcome: services.RemediationDryRun, Started: now.Add(-2 * time.Hour)},
+		{ID: "4", Finding: "finding/3", Action: "close_bucket", Resource: "projects/p3/buckets/d", Outcome: services.RemediationSucceeded, Started: now.AddDate(0, 0, -8)},
+	}
+	expected := []string{
+		"Findings handled: 2\nActions taken: 3\n",
+		"- close_bucket: 1 succeeded, 0 failed, 0 already remediated, 0 pending, 1 dry run\n",
+		"- enable_bucket_only_policy: 0 succeeded, 1 failed, 0 already remediated, 0 pending, 0 dry run\n",
+		"Dry run only, would have changed:\n- close_bucket: 1\n",
+		"Mean time to remediate:\n- public_bucket_acl: 30m0s\n",
+		"Top projects:\n- p1: 2\n- p2: 1\n",
+	}
+	test := []struct {
+		name         string
+		to           []string
+		chat         bool
+		expectedSent int
+		expectedChat int
+	}{
+		{name: "logs without recipients"},
+		{name: "emails recipients", to: []string{"secops@example.com"}, expectedSent: 1},
+		{name: "posts to chat", chat: true, expectedChat: 1},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			history := services.NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+			for _, r := range remediations {
+				if err := history.Save(ctx, r); err != nil {
+					t.Fatalf("Save failed: %q", err)
+				}
+			}
+			sendgrid := &stubs.SendGridStub{}
+			chat := &stubs.ChatStub{}
+			svcs := &Services{
+				History: history,
+				Email:   services.NewEmail(sendgrid),
+				Logger:  services.NewLogger(&stubs.LoggerStub{}),
+			}
+			if tt.chat {
+				svcs.Chat = services.NewChat(chat, "https://chat.googleapis.com/v1/spaces/s/messages")
+			}
+			values := &Values{From: "automation@example.com", To: tt.to}
+			if err := Execute(ctx, pubsub.Message{}, values, svcs); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if len(sendgrid.Sent) != tt.expectedSent {
+				t.Errorf("%s sent %d emails, want %d", tt.name, len(sendgrid.Sent), tt.expectedSent)
+			}
+			if len(chat.Posted) != tt.expectedChat {
+				t.Errorf("%s posted %d messages, want %d", tt.name, len(chat.Posted), tt.expectedChat)
+			}
+			for _, p := range chat.Posted {
+				for _, e := range expected {
+					if !strings.Contains(p, e) {
+						t.Errorf("%s posted %q, missing %q", tt.name, p, e)
+					}
+				}
+			}
+		})
+	}
+}
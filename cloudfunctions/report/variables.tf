@@ -0,0 +1,40 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+variable "setup" {}
+
+variable "sendgrid-api-key" {
+  type        = string
+  description = "SendGrid API key used to email the report."
+}
+
+variable "report-from" {
+  type        = string
+  description = "Email address the report is sent from."
+}
+
+variable "report-to" {
+  type        = list(string)
+  description = "Email addresses the report is sent to, none is emailed if empty."
+}
+
+variable "report-chat-webhook" {
+  type        = string
+  description = "Google Chat incoming webhook URL the report is posted to, none is posted if empty."
+}
+
+variable "report-schedule" {
+  type        = string
+  default     = "0 9 * * 1"
+  description = "Cron schedule of the report, Mondays at 09:00 UTC by default."
+}
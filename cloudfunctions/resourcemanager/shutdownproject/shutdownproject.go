@@ -0,0 +1,126 @@
+package shutdownproject
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "shutdown_project",
+		EntryPoint:  "ShutdownProject",
+		Topic:       "threat-findings-shutdown-project",
+		Services:    []string{"Resource"},
+		Roles:       []string{"roles/resourcemanager.projectDeleter", "roles/resourcemanager.lienModifier"},
+		Permissions: []string{"resourcemanager.projects.get", "resourcemanager.projects.delete", "resourcemanager.projects.updateLiens"},
+		Approval:    true,
+	})
+}
+
+const (
+	// Shutdown requests the deletion of the project, the default.
+	Shutdown = "shutdown"
+	// Lien places a lien on the project instead, preventing its deletion by anyone else while it is
+	// investigated. A later shutdown removes the lien first.
+	Lien = "lien"
+)
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// Mode is Shutdown or Lien.
+	Mode string
+	// Reason is recorded on the lien placed.
+	Reason string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Resource *services.Resource
+	Logger   *services.Logger
+}
+
+// Execute shuts down the project, or places a lien on it in Lien mode. Liens prevent deletion, so
+// the two cannot be applied at once: a project held in Lien mode is shut down by running the action
+// again in Shutdown mode, which removes the lien placed by the automation first. Remediations are
+// claimed per mode, so the same finding can be routed to both. Projects holding liens placed by
+// others cannot be shut down until those are removed.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	switch values.Mode {
+	case "", Shutdown:
+		return shutdown(ctx, values, services)
+	case Lien:
+		return hold(ctx, values, services)
+	default:
+		return fmt.Errorf("unknown mode %q", values.Mode)
+	}
+}
+
+func shutdown(ctx context.Context, values *Values, services *Services) error {
+	if values.DryRun {
+		p, err := services.Resource.Project(ctx, values.ProjectID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get project %q", values.ProjectID)
+		}
+		if p.LifecycleState != "ACTIVE" {
+			return registry.ErrAlreadyRemediated
+		}
+		services.Logger.Info("dry_run on, would have shut down project %q", values.ProjectID)
+		return nil
+	}
+	shut, err := services.Resource.ShutDownProject(ctx, values.ProjectID)
+	if err != nil {
+		return err
+	}
+	if !shut {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("shut down project %q, it can be restored within 30 days", values.ProjectID)
+	return nil
+}
+
+func hold(ctx context.Context, values *Values, services *Services) error {
+	reason := values.Reason
+	if reason == "" {
+		reason = "Held for investigation by security response automation"
+	}
+	if values.DryRun {
+		held, err := services.Resource.ProjectHeld(ctx, values.ProjectID)
+		if err != nil {
+			return err
+		}
+		if held {
+			return registry.ErrAlreadyRemediated
+		}
+		services.Logger.Info("dry_run on, would have placed a lien on project %q", values.ProjectID)
+		return nil
+	}
+	held, err := services.Resource.HoldProject(ctx, values.ProjectID, reason)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("placed a lien on project %q", values.ProjectID)
+	return nil
+}
@@ -0,0 +1,96 @@
+package shutdownproject
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestShutdownProject(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name   string
+		values *Values
+		state  string
+		liens  int
+	}{
+		{name: "shutdown", values: &Values{ProjectID: "rogue"}, state: "DELETE_REQUESTED"},
+		{name: "shutdown dry run", values: &Values{ProjectID: "rogue", DryRun: true}, state: "ACTIVE"},
+		{name: "lien", values: &Values{ProjectID: "rogue", Mode: Lien}, state: "ACTIVE", liens: 1},
+		{name: "lien dry run", values: &Values{ProjectID: "rogue", Mode: Lien, DryRun: true}, state: "ACTIVE"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crmFake := stubs.NewResourceManagerFake()
+			crmFake.AddOrganization("456")
+			crmFake.AddProject("rogue", 123, "organizations/456")
+			svcs := &Services{
+				Resource: services.NewResource(crmFake, &stubs.StorageStub{}),
+				Logger:   services.NewLogger(&stubs.LoggerStub{}),
+			}
+			if err := Execute(ctx, tt.values, svcs); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			p, err := crmFake.GetProject(ctx, "rogue")
+			if err != nil {
+				t.Fatalf("failed to get project: %q", err)
+			}
+			if p.LifecycleState != tt.state {
+				t.Errorf("%s got state %q, want %q", tt.name, p.LifecycleState, tt.state)
+			}
+			liens, err := crmFake.ListLiens(ctx, "projects/123")
+			if err != nil {
+				t.Fatalf("failed to list liens: %q", err)
+			}
+			if len(liens) != tt.liens {
+				t.Errorf("%s got %d liens, want %d", tt.name, len(liens), tt.liens)
+			}
+			if err := Execute(ctx, tt.values, svcs); !tt.values.DryRun && err != registry.ErrAlreadyRemediated {
+				t.Errorf("%s again got %q, want %q", tt.name, err, registry.ErrAlreadyRemediated)
+			}
+		})
+	}
+}
+
+func TestShutdownHeldProject(t *testing.T) {
+	ctx := context.Background()
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddOrganization("456")
+	crmFake.AddProject("rogue", 123, "organizations/456")
+	svcs := &Services{
+		Resource: services.NewResource(crmFake, &stubs.StorageStub{}),
+		Logger:   services.NewLogger(&stubs.LoggerStub{}),
+	}
+	if err := Execute(ctx, &Values{ProjectID: "rogue", Mode: Lien}, svcs); err != nil {
+		t.Fatalf("lien failed: %q", err)
+	}
+	if err := Execute(ctx, &Values{ProjectID: "rogue", Mode: Lien, DryRun: true}, svcs); err != registry.ErrAlreadyRemediated {
+		t.Errorf("lien dry run of a held project got %q, want %q", err, registry.ErrAlreadyRemediated)
+	}
+	if err := Execute(ctx, &Values{ProjectID: "rogue"}, svcs); err != nil {
+		t.Fatalf("shutting down a held project failed: %q", err)
+	}
+	p, err := crmFake.GetProject(ctx, "rogue")
+	if err != nil {
+		t.Fatalf("failed to get project: %q", err)
+	}
+	if p.LifecycleState != "DELETE_REQUESTED" {
+		t.Errorf("got state %q, want DELETE_REQUESTED", p.LifecycleState)
+	}
+}
@@ -0,0 +1,6 @@
+variable "setup" {}
+
+variable "folder-ids" {
+  type        = list(string)
+  description = "Shut down or place liens on projects if they are within the given folder IDs."
+}
@@ -0,0 +1,59 @@
+package router
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Importing the actions registers them, automations can only run registered actions.
+import (
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/disableaccesskey"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/isolateinstance"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/bigquery/closepublicdataset"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/billing/detachbilling"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/binauthz/quarantineimage"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/removepublic"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/requiressl"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/updatepassword"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/requireoslogin"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/restrictexternalips"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/preventpublicaccess"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/cordonnode"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/disabledashboard"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/isolatepod"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/containserviceaccount"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/downgraderoles"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/removenonorgmembers"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/removepublicaccess"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/rotatekey"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/notify/sendemail"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/resourcemanager/shutdownproject"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/secretmanager/closesecret"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/serviceusage/disableapis"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/exportlogs"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/labelresource"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/addtoperimeter"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/restrictaccesslevel"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/enforce2sv"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/removefromgroups"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/revokesessions"
+	_ "github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/suspenduser"
+)
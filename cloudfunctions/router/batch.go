@@ -0,0 +1,109 @@
+package router
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// routerTopic is the topic the router receives findings from.
+const routerTopic = "threat-findings-router"
+
+// simulatedAttribute marks the messages of findings published by the simulator.
+const simulatedAttribute = services.SimulatedAttribute
+
+// batched returns the findings of a message batching several of them, as some export pipelines
+// do: either a JSON array of findings or an object holding them in a "findings" array. Reports
+// false for messages holding a single finding.
+func batched(b []byte) ([][]byte, bool) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(b, &list); err != nil {
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(b, &envelope); err != nil {
+			return nil, false
+		}
+		findings, ok := envelope["findings"]
+		if _, single := envelope["finding"]; !ok || single {
+			return nil, false
+		}
+		if err := json.Unmarshal(findings, &list); err != nil {
+			return nil, false
+		}
+	}
+	out := make([][]byte, len(list))
+	for i, f := range list {
+		out[i] = f
+	}
+	return out, true
+}
+
+// executeBatch routes each finding of a batch on its own, with the correlation ID of the batch
+// suffixed by the finding's index. A finding failing does not stop the others: it is dead lettered
+// if no parser recognizes it, otherwise it is published back to the router on its own to be
+// retried, so the findings routed successfully are not routed again. The batch fails only if a
+// failed finding could not be published back, the whole batch is then redelivered.
+func executeBatch(ctx context.Context, findings [][]byte, values *Values, services *Services) error {
+	var completed []string
+	failed := make(map[string]string)
+	for i, f := range findings {
+		v := &Values{Finding: f, CorrelationID: fmt.Sprintf("%s-%d", values.CorrelationID, i), Simulated: values.Simulated}
+		r, err := execute(ctx, v, services)
+		if err == nil {
+			completed = append(completed, v.CorrelationID)
+			continue
+		}
+		failed[v.CorrelationID] = err.Error()
+		if r != nil && r.deadLettered {
+			continue
+		}
+		if err := requeue(ctx, services, v); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		services.Logger.WithCorrelation(values.CorrelationID).Warning("batch of %d findings: %s", len(findings), partialError(completed, failed))
+		return nil
+	}
+	services.Logger.WithCorrelation(values.CorrelationID).Info("routed batch of %d findings", len(findings))
+	return nil
+}
+
+// requeue publishes the finding back to the router on its own.
+func requeue(ctx context.Context, services *Services, values *Values) error {
+	attributes := map[string]string{correlationAttribute: values.CorrelationID}
+	if values.Simulated {
+		attributes[simulatedAttribute] = "true"
+	}
+	if _, err := services.PubSub.Publish(ctx, routerTopic, &pubsub.Message{
+		Data:       values.Finding,
+		Attributes: attributes,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to publish finding %s to %q", values.CorrelationID, routerTopic)
+	}
+	return nil
+}
+
+// partialError records which findings of a batch were routed and which failed.
+func partialError(completed []string, failed map[string]string) *services.PartialError {
+	sort.Strings(completed)
+	return &services.PartialError{Completed: completed, Failed: failed}
+}
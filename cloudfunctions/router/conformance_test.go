@@ -0,0 +1,412 @@
+package router
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/bigquery/closepublicdataset"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/removepublic"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/requiressl"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/updatepassword"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/disabledashboard"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/downgraderoles"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/removenonorgmembers"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/removepublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/rotatekey"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/addtoperimeter"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/suspenduser"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/accountcompromise"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/anomalousiam"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/badip"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/exfiltration"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/sshbruteforce"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/computeinstancescanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/containerscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/datasetscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/firewallscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/iamscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/kmsscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/loggingscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/sqlscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/storagescanner"
+)
+
+// conformanceDir holds sanitized notifications of Event Threat Detection and Security Health
+// Analytics under testdata, one directory per source. Add a payload when Google changes a finding's
+// schema so the parsers are checked against both.
+const conformanceDir = "conformance"
+
+// conformance is what the router reads from a payload of the corpus.
+type conformance struct {
+	// file is the path of the payload under testdata/conformance.
+	file     string
+	category string
+	version  string
+	// values returns the values the router reads from the payload for one of the category's actions.
+	values func(b []byte) (interface{}, error)
+	want   interface{}
+}
+
+var conformanceCases = []conformance{
+	{
+		file:     "etd/account_compromise-logging.json",
+		category: "account_compromise",
+		version:  "logging",
+		values:   suspendUser,
+		want:     &suspenduser.Values{Email: "bob@example.com"},
+	},
+	{
+		file:     "etd/account_compromise-scc.json",
+		category: "account_compromise",
+		version:  "scc/v1",
+		values:   suspendUser,
+		want:     &suspenduser.Values{Email: "alice@example.com"},
+	},
+	{
+		file:     "etd/bad_ip-logging.json",
+		category: "bad_ip",
+		version:  "logging",
+		values:   createSnapshot,
+		want:     &createsnapshot.Values{ProjectID: "test-project-15511551515", RuleName: "bad_ip", Instance: "bad-ip-caller", Zone: "us-central1-a"},
+	},
+	{
+		file:     "etd/bad_ip-scc.json",
+		category: "bad_ip",
+		version:  "scc/v1",
+		values:   createSnapshot,
+		want:     &createsnapshot.Values{ProjectID: "test-project-15511551515", RuleName: "bad_ip", Instance: "bad-ip-caller", Zone: "us-central1-a"},
+	},
+	{
+		file:     "etd/exfiltration-logging.json",
+		category: "exfiltration",
+		version:  "logging",
+		values:   addToPerimeter,
+		want:     &addtoperimeter.Values{ProjectID: "billing-db"},
+	},
+	{
+		file:     "etd/exfiltration-scc.json",
+		category: "exfiltration",
+		version:  "scc/v1",
+		values:   addToPerimeter,
+		want:     &addtoperimeter.Values{ProjectID: "sales-data"},
+	},
+	{
+		file:     "etd/iam_anomalous_grant-logging.json",
+		category: "iam_anomalous_grant",
+		version:  "logging",
+		values:   iamRevoke,
+		want:     &revoke.Values{ProjectID: "onboarding-project", ExternalMembers: []string{"user:john.doe@example.com", "user:jane.doe@example.com"}, Grantor: "admin@example.com"},
+	},
+	{
+		file:     "etd/iam_anomalous_grant-scc.json",
+		category: "iam_anomalous_grant",
+		version:  "scc/v1",
+		values:   iamRevoke,
+		want:     &revoke.Values{ProjectID: "onboarding-project", ExternalMembers: []string{"user:john.doe@example.com", "user:jane.doe@example.com"}, Grantor: "admin@example.com"},
+	},
+	{
+		file:     "etd/ssh_brute_force-logging.json",
+		category: "ssh_brute_force",
+		version:  "logging",
+		values:   blockSSH,
+		want:     &openfirewall.Values{ProjectID: "onboarding-project", SourceRanges: []string{"10.200.0.2/32", "10.200.0.3/32"}},
+	},
+	{
+		file:     "etd/ssh_brute_force-scc.json",
+		category: "ssh_brute_force",
+		version:  "scc/v1",
+		values:   blockSSH,
+		want:     &openfirewall.Values{ProjectID: "onboarding-project", SourceRanges: []string{"10.200.0.2/32", "10.200.0.3/32"}},
+	},
+	{
+		file:     "sha/audit_logging_disabled.json",
+		category: "audit_logging_disabled",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := loggingscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.EnableAuditLogs(), nil
+		},
+		want: &enableauditlogs.Values{ProjectID: "test-project"},
+	},
+	{
+		file:     "sha/bucket_policy_only_disabled.json",
+		category: "bucket_policy_only_disabled",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := storagescanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.EnableBucketOnlyPolicy(), nil
+		},
+		want: &enablebucketonlypolicy.Values{ProjectID: "aerial-jigsaw-235219", BucketName: "this-is-public-on-purpose"},
+	},
+	{
+		file:     "sha/kms_key_not_rotated.json",
+		category: "kms_key_not_rotated",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := kmsscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.RotateKey(), nil
+		},
+		want: &rotatekey.Values{ProjectID: "test-project", KeyName: "projects/test-project/locations/global/keyRings/app-ring/cryptoKeys/app-key"},
+	},
+	{
+		file:     "sha/kms_public_key.json",
+		category: "kms_public_key",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := kmsscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.RemovePublicAccess(), nil
+		},
+		want: &removepublicaccess.Values{ProjectID: "test-project", KeyName: "projects/test-project/locations/global/keyRings/app-ring/cryptoKeys/app-key"},
+	},
+	{
+		file:     "sha/non_org_iam_member.json",
+		category: "non_org_iam_member",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := iamscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.RemoveNonOrgMembers(), nil
+		},
+		want: &removenonorgmembers.Values{ProjectID: "test-project"},
+	},
+	{
+		file:     "sha/open_firewall.json",
+		category: "open_firewall",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := firewallscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.OpenFirewall(), nil
+		},
+		want: &openfirewall.Values{ProjectID: "onboarding-project", FirewallID: "6190685430815455733"},
+	},
+	{
+		file:     "sha/primitive_roles_used.json",
+		category: "primitive_roles_used",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := iamscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.DowngradeRoles()
+		},
+		want: &downgraderoles.Values{ProjectID: "test-project", Members: []string{"serviceAccount:app@test-project.iam.gserviceaccount.com", "user:dev@example.com"}},
+	},
+	{
+		file:     "sha/public_bucket_acl.json",
+		category: "public_bucket_acl",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := storagescanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.CloseBucket(), nil
+		},
+		want: &closebucket.Values{ProjectID: "aerial-jigsaw-235219", BucketName: "this-is-public-on-purpose"},
+	},
+	{
+		file:     "sha/public_dataset.json",
+		category: "public_dataset",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := datasetscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.ClosePublicDataset(), nil
+		},
+		want: &closepublicdataset.Values{ProjectID: "sha-resources-20191002", DatasetID: "public_dataset"},
+	},
+	{
+		file:     "sha/public_ip_address.json",
+		category: "public_ip_address",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := computeinstancescanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.RemovePublicIP(), nil
+		},
+		want: &removepublicip.Values{ProjectID: "sec-automation-dev", InstanceZone: "us-central1-a", InstanceID: "4312755253150365851"},
+	},
+	{
+		file:     "sha/public_sql_instance.json",
+		category: "public_sql_instance",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := sqlscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.RemovePublic(), nil
+		},
+		want: &removepublic.Values{ProjectID: "sha-resources-20191002", InstanceName: "public-sql-instance"},
+	},
+	{
+		file:     "sha/sql_no_root_password.json",
+		category: "sql_no_root_password",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := sqlscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.UpdatePassword()
+		},
+		want: &updatepassword.Values{ProjectID: "threat-auto-tests-07102019", InstanceName: "test-no-password", Host: "%", UserName: "root"},
+	},
+	{
+		file:     "sha/ssl_not_enforced.json",
+		category: "ssl_not_enforced",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := sqlscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.RequireSSL(), nil
+		},
+		want: &requiressl.Values{ProjectID: "sha-resources-20191002", InstanceName: "public-sql-instance"},
+	},
+	{
+		file:     "sha/web_ui_enabled.json",
+		category: "web_ui_enabled",
+		version:  "scc/v1",
+		values: func(b []byte) (interface{}, error) {
+			f, err := containerscanner.New(b)
+			if err != nil {
+				return nil, err
+			}
+			return f.DisableDashboard(), nil
+		},
+		want: &disabledashboard.Values{ProjectID: "test-cat-findings-clseclab", Zone: "us-central1-a", ClusterID: "ex-abuse-cluster-3"},
+	},
+}
+
+func suspendUser(b []byte) (interface{}, error) {
+	f, err := accountcompromise.New(b)
+	if err != nil {
+		return nil, err
+	}
+	return f.SuspendUser()
+}
+
+func createSnapshot(b []byte) (interface{}, error) {
+	f, err := badip.New(b)
+	if err != nil {
+		return nil, err
+	}
+	return f.CreateSnapshot(), nil
+}
+
+func addToPerimeter(b []byte) (interface{}, error) {
+	f, err := exfiltration.New(b)
+	if err != nil {
+		return nil, err
+	}
+	return f.AddToPerimeter()
+}
+
+func iamRevoke(b []byte) (interface{}, error) {
+	f, err := anomalousiam.New(b)
+	if err != nil {
+		return nil, err
+	}
+	return f.IAMRevoke(), nil
+}
+
+func blockSSH(b []byte) (interface{}, error) {
+	f, err := sshbruteforce.New(b)
+	if err != nil {
+		return nil, err
+	}
+	return f.OpenFirewall(), nil
+}
+
+func TestConformance(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, tt := range conformanceCases {
+		covered[tt.file] = true
+		t.Run(tt.file, func(t *testing.T) {
+			b, err := scc.Decode(testData(t, filepath.Join(conformanceDir, tt.file)))
+			if err != nil {
+				t.Fatalf("failed to decode: %q", err)
+			}
+			d, err := detect(b)
+			if err != nil {
+				t.Fatalf("failed to detect: %q", err)
+			}
+			want := &Detection{Source: filepath.Dir(tt.file), Category: tt.category, Version: tt.version}
+			if diff := cmp.Diff(want, d, cmpopts.IgnoreFields(Detection{}, "Severity")); diff != "" {
+				t.Errorf("detection difference:%+v", diff)
+			}
+			if err := validate(d, b); err != nil {
+				t.Errorf("failed strict validation: %q", err)
+			}
+			values, err := tt.values(b)
+			if err != nil {
+				t.Fatalf("failed to read values: %q", err)
+			}
+			// Passwords are generated, not read from the finding.
+			if diff := cmp.Diff(tt.want, values, cmpopts.IgnoreFields(updatepassword.Values{}, "Password")); diff != "" {
+				t.Errorf("values difference:%+v", diff)
+			}
+		})
+	}
+	paths, err := filepath.Glob(filepath.Join("testdata", conformanceDir, "*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		rel, err := filepath.Rel(filepath.Join("testdata", conformanceDir), p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !covered[filepath.ToSlash(rel)] {
+			t.Errorf("%s has no expectations in conformanceCases", p)
+		}
+	}
+}
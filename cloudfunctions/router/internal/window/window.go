@@ -0,0 +1,101 @@
+// Package window decides whether automations may run at a given time.
+package window
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a daily period during which an automation may run.
+//
+// Windows ending before they start wrap past midnight, "18:00" to "08:00" runs overnight. Days
+// restricts the days the window opens on, every day if empty.
+type Window struct {
+	// Days lists the days the window opens on, such as "mon" or "sat".
+	Days []string
+	// Start is the "HH:MM" time the window opens.
+	Start string
+	// End is the "HH:MM" time the window closes.
+	End string
+	// Timezone is the IANA time zone of Start and End, UTC if empty.
+	Timezone string
+}
+
+// IsZero returns true if no window is configured.
+func (w Window) IsZero() bool {
+	return w.Start == "" && w.End == ""
+}
+
+// Next returns true if t is within the window. Otherwise it returns the time the window next opens.
+func (w Window) Next(t time.Time) (bool, time.Time, error) {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid timezone %q: %q", w.Timezone, err)
+	}
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid start %q, want HH:MM", w.Start)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid end %q, want HH:MM", w.End)
+	}
+	days := make(map[time.Weekday]bool)
+	for _, d := range w.Days {
+		key := strings.ToLower(d)
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		wd, ok := weekdays[key]
+		if !ok {
+			return false, time.Time{}, fmt.Errorf("invalid day %q", d)
+		}
+		days[wd] = true
+	}
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	// A window opening yesterday may still be open, search from there.
+	for i := -1; i <= 7; i++ {
+		day := midnight.AddDate(0, 0, i)
+		if len(days) > 0 && !days[day.Weekday()] {
+			continue
+		}
+		opens := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+		closes := time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+		if !closes.After(opens) {
+			closes = closes.AddDate(0, 0, 1)
+		}
+		if !t.Before(opens) && t.Before(closes) {
+			return true, time.Time{}, nil
+		}
+		if opens.After(t) {
+			return false, opens, nil
+		}
+	}
+	return false, time.Time{}, fmt.Errorf("window never opens")
+}
@@ -0,0 +1,99 @@
+package window
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// Monday 1 June 2020.
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2020, 6, 1, hour, minute, 0, 0, time.UTC)
+	}
+	for _, tt := range []struct {
+		name   string
+		window Window
+		at     time.Time
+		open   bool
+		next   time.Time
+	}{
+		{
+			name:   "within business hours",
+			window: Window{Start: "09:00", End: "17:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}},
+			at:     monday(10, 30),
+			open:   true,
+		},
+		{
+			name:   "before business hours",
+			window: Window{Start: "09:00", End: "17:00"},
+			at:     monday(8, 0),
+			next:   monday(9, 0),
+		},
+		{
+			name:   "after business hours on friday",
+			window: Window{Start: "09:00", End: "17:00", Days: []string{"Monday", "friday"}},
+			at:     time.Date(2020, 6, 5, 18, 0, 0, 0, time.UTC),
+			next:   time.Date(2020, 6, 8, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "overnight window before midnight",
+			window: Window{Start: "18:00", End: "08:00"},
+			at:     monday(23, 0),
+			open:   true,
+		},
+		{
+			name:   "overnight window after midnight",
+			window: Window{Start: "18:00", End: "08:00", Days: []string{"sun"}},
+			at:     monday(7, 59),
+			open:   true,
+		},
+		{
+			name:   "overnight window during the day",
+			window: Window{Start: "18:00", End: "08:00"},
+			at:     monday(12, 0),
+			next:   monday(18, 0),
+		},
+		{
+			name:   "timezone",
+			window: Window{Start: "09:00", End: "17:00", Timezone: "America/New_York"},
+			at:     monday(12, 0),
+			next:   monday(13, 0),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			open, next, err := tt.window.Next(tt.at)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if open != tt.open || !next.Equal(tt.next) {
+				t.Errorf("%s failed got:%v %v want:%v %v", tt.name, open, next, tt.open, tt.next)
+			}
+		})
+	}
+}
+
+func TestInvalidWindow(t *testing.T) {
+	for _, w := range []Window{
+		{Start: "9am", End: "17:00"},
+		{Start: "09:00", End: "17:00", Days: []string{"someday"}},
+		{Start: "09:00", End: "17:00", Timezone: "Mars/Olympus_Mons"},
+	} {
+		if _, _, err := w.Next(time.Now()); err == nil {
+			t.Errorf("Next() of %+v expected an error", w)
+		}
+	}
+}
@@ -29,10 +29,61 @@ resource "google_cloudfunctions_function" "router" {
     resource   = var.setup.router-topic-id
   }
   environment_variables = {
-    GCP_PROJECT = var.setup.automation-project
+    GCP_PROJECT           = var.setup.automation-project
+    POLICY_BUCKET         = var.policy-bucket
+    TASKS_QUEUE           = google_cloud_tasks_queue.deferred.id
+    TASKS_SERVICE_ACCOUNT = var.setup.automation-service-account
   }
 }
 
+# Dead letter topic for findings the router does not support.
+resource "google_pubsub_topic" "unsupported" {
+  name    = "threat-findings-unsupported"
+  project = var.setup.automation-project
+}
+
+# Automations the policies require approval for are held on this topic.
+resource "google_pubsub_topic" "approval_required" {
+  name    = "threat-findings-approval-required"
+  project = var.setup.automation-project
+}
+
+# Required to read the policies stored in the policy bucket.
+resource "google_storage_bucket_iam_member" "policy-reader" {
+  count  = var.policy-bucket == "" ? 0 : 1
+  bucket = var.policy-bucket
+  role   = "roles/storage.objectViewer"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Automations outside of their execution window are deferred on this queue.
+resource "google_cloud_tasks_queue" "deferred" {
+  name       = "sra-deferred-automations"
+  project    = var.setup.automation-project
+  location   = var.setup.region
+  depends_on = [google_project_service.cloudtasks_api]
+}
+
+resource "google_project_service" "cloudtasks_api" {
+  project                    = var.setup.automation-project
+  service                    = "cloudtasks.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+# Required to defer automations and to publish them as the automation service account when they run.
+resource "google_project_iam_member" "router-tasks-enqueuer" {
+  role    = "roles/cloudtasks.enqueuer"
+  project = var.setup.automation-project
+  member  = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+resource "google_service_account_iam_member" "router-tasks-actor" {
+  service_account_id = "projects/${var.setup.automation-project}/serviceAccounts/${var.setup.automation-service-account}"
+  role               = "roles/iam.serviceAccountUser"
+  member             = "serviceAccount:${var.setup.automation-service-account}"
+}
+
 resource "google_project_iam_member" "router-pubsub-writer" {
   role    = "roles/pubsub.editor"
   project = var.setup.automation-project
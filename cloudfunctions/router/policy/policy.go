@@ -0,0 +1,99 @@
+// Package policy gates automations on Rego policies.
+//
+// Policies are written in the `sra.policy` package and add "allow", "deny" or "approval_required"
+// to the `decision` set. The strictest decision wins and automations no policy decides on are allowed.
+package policy
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+const query = "data.sra.policy.decision"
+
+// Decision is the outcome of evaluating an automation against the policies.
+type Decision string
+
+const (
+	// Allow runs the automation.
+	Allow Decision = "allow"
+	// ApprovalRequired holds the automation until someone approves it.
+	ApprovalRequired Decision = "approval_required"
+	// Deny skips the automation.
+	Deny Decision = "deny"
+)
+
+// strictness orders decisions so the strictest of several can be picked.
+var strictness = map[Decision]int{Allow: 0, ApprovalRequired: 1, Deny: 2}
+
+// Engine evaluates automations against compiled policies.
+type Engine struct {
+	compiler *ast.Compiler
+}
+
+// New compiles the policy modules keyed by file name.
+func New(modules map[string][]byte) (*Engine, error) {
+	m := make(map[string]string, len(modules))
+	for name, b := range modules {
+		m[name] = string(b)
+	}
+	compiler, err := ast.CompileModules(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile policies: %q", err)
+	}
+	return &Engine{compiler: compiler}, nil
+}
+
+// Decide evaluates the input against the policies and returns the strictest decision.
+func (e *Engine) Decide(ctx context.Context, input map[string]interface{}) (Decision, error) {
+	rs, err := rego.New(
+		rego.Query(query),
+		rego.Compiler(e.compiler),
+		rego.Input(input)).Eval(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate policies: %q", err)
+	}
+	decision := Allow
+	for _, r := range rs {
+		for _, expr := range r.Expressions {
+			var values []interface{}
+			switch v := expr.Value.(type) {
+			case string:
+				values = append(values, v)
+			case []interface{}:
+				values = v
+			default:
+				return "", fmt.Errorf("policy decision %v is not a string or set", expr.Value)
+			}
+			for _, v := range values {
+				s, _ := v.(string)
+				d := Decision(s)
+				rank, ok := strictness[d]
+				if !ok {
+					return "", fmt.Errorf("unknown policy decision %v", v)
+				}
+				if rank > strictness[decision] {
+					decision = d
+				}
+			}
+		}
+	}
+	return decision, nil
+}
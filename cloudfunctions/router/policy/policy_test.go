@@ -0,0 +1,78 @@
+package policy
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+)
+
+const testPolicy = `package sra.policy
+
+decision["deny"] {
+	input.action == "close_bucket"
+	input.resource.project == "public-website"
+}
+
+decision["approval_required"] {
+	startswith(input.resource.project, "prod-")
+}
+
+decision["allow"] {
+	input.finding.category == "PUBLIC_BUCKET_ACL"
+}
+`
+
+func TestDecide(t *testing.T) {
+	engine, err := New(map[string][]byte{"test.rego": []byte(testPolicy)})
+	if err != nil {
+		t.Fatalf("failed to compile policies: %q", err)
+	}
+	for _, tt := range []struct {
+		name    string
+		action  string
+		project string
+		want    Decision
+	}{
+		{name: "undecided", action: "iam_revoke", project: "dev-project", want: Allow},
+		{name: "allow", action: "close_bucket", project: "dev-project", want: Allow},
+		{name: "approval required", action: "close_bucket", project: "prod-web", want: ApprovalRequired},
+		{name: "deny wins", action: "close_bucket", project: "public-website", want: Deny},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Decide(context.Background(), map[string]interface{}{
+				"action":   tt.action,
+				"resource": map[string]interface{}{"project": tt.project},
+				"finding":  map[string]interface{}{"category": "PUBLIC_BUCKET_ACL"},
+			})
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s failed got:%q want:%q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownDecision(t *testing.T) {
+	engine, err := New(map[string][]byte{"test.rego": []byte("package sra.policy\n\ndecision[\"maybe\"] { true }\n")})
+	if err != nil {
+		t.Fatalf("failed to compile policies: %q", err)
+	}
+	if _, err := engine.Decide(context.Background(), map[string]interface{}{}); err == nil {
+		t.Errorf("unknown decision should fail")
+	}
+}
@@ -0,0 +1,6 @@
+//go:generate go run generator.go
+
+package policy
+
+// FileStore holds the policies bundled into the deployment keyed by file name.
+var FileStore = make(map[string][]byte)
@@ -0,0 +1,173 @@
+package router
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// resourcePattern matches the organizations, folders and projects named in a target.
+var resourcePattern = regexp.MustCompile(`(organizations|folders)/(\d+)|projects/([a-z][-a-z0-9]{4,28}[a-z0-9])`)
+
+// baseAPIs are the APIs every deployment calls from the automation project.
+var baseAPIs = []string{
+	"cloudfunctions.googleapis.com", "cloudresourcemanager.googleapis.com", "firestore.googleapis.com",
+	"logging.googleapis.com", "pubsub.googleapis.com",
+}
+
+// serviceAPIs maps the services actions register to the APIs their clients call.
+var serviceAPIs = map[string][]string{
+	"AccessContextManager":  {"accesscontextmanager.googleapis.com"},
+	"BigQuery":              {"bigquery.googleapis.com"},
+	"Billing":               {"cloudbilling.googleapis.com"},
+	"CloudIdentity":         {"cloudidentity.googleapis.com"},
+	"CloudSQL":              {"sqladmin.googleapis.com"},
+	"Container":             {"container.googleapis.com"},
+	"ContainerAnalysis":     {"containeranalysis.googleapis.com"},
+	"Directory":             {"admin.googleapis.com"},
+	"Firewall":              {"compute.googleapis.com"},
+	"Host":                  {"compute.googleapis.com"},
+	"IAM":                   {"iam.googleapis.com"},
+	"KMS":                   {"cloudkms.googleapis.com"},
+	"Kubernetes":            {"container.googleapis.com"},
+	"Logs":                  {"logging.googleapis.com"},
+	"OrgPolicy":             {"cloudresourcemanager.googleapis.com"},
+	"Recommender":           {"recommender.googleapis.com"},
+	"Resource":              {"cloudresourcemanager.googleapis.com", "storage-api.googleapis.com"},
+	"SecretManager":         {"secretmanager.googleapis.com"},
+	"SecurityCommandCenter": {"securitycenter.googleapis.com"},
+	"ServiceUsage":          {"serviceusage.googleapis.com"},
+}
+
+// Resources returns the organizations, folders and projects the automation targets, the deepest
+// one named by each target, such as "folders/123" for "organizations/456/folders/123/*".
+func (a Automation) Resources() []string {
+	var r []string
+	for _, pattern := range a.Target {
+		if m := resourcePattern.FindAllString(pattern, -1); len(m) > 0 {
+			r = append(r, m[len(m)-1])
+		}
+	}
+	if a.OrganizationID != "" {
+		r = append(r, "organizations/"+a.OrganizationID)
+	}
+	for _, id := range a.FolderIDs {
+		r = append(r, "folders/"+id)
+	}
+	for _, id := range a.ProjectIDs {
+		r = append(r, "projects/"+id)
+	}
+	return r
+}
+
+// Gap is what the automation service account lacks to run an action.
+type Gap struct {
+	// Action is the action lacking it, empty for what every automation needs.
+	Action string
+	// Resource is the resource permissions are missing on or the project APIs are disabled in.
+	Resource string
+	// Permissions are the permissions missing on Resource.
+	Permissions []string
+	// APIs are the APIs disabled in Resource.
+	APIs []string
+}
+
+// String describes the gap.
+func (g Gap) String() string {
+	action := g.Action
+	if action == "" {
+		action = "all actions"
+	}
+	if len(g.APIs) > 0 {
+		return fmt.Sprintf("%s: %s not enabled in %s", action, strings.Join(g.APIs, ", "), g.Resource)
+	}
+	return fmt.Sprintf("%s: missing %s on %s", action, strings.Join(g.Permissions, ", "), g.Resource)
+}
+
+// Preflight returns what keeps the enabled automations of the configuration from running: the
+// APIs they call that are disabled in the automation project and the permissions of their actions
+// the caller lacks on the resources they target. Run it as the automation service account.
+func Preflight(ctx context.Context, conf *Configuration, projectID string, p *services.Preflight) ([]Gap, error) {
+	resources := make(map[string][]string)
+	for _, as := range conf.Automations() {
+		for _, a := range as {
+			if !enabled(conf, a.Action) {
+				continue
+			}
+			resources[a.Action] = append(resources[a.Action], a.Resources()...)
+		}
+	}
+	var gaps []Gap
+	disabled, err := p.DisabledAPIs(ctx, projectID, baseAPIs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the APIs enabled in %q: %q", projectID, err)
+	}
+	if len(disabled) > 0 {
+		gaps = append(gaps, Gap{Resource: "projects/" + projectID, APIs: disabled})
+	}
+	actions := make([]string, 0, len(resources))
+	for a := range resources {
+		actions = append(actions, a)
+	}
+	sort.Strings(actions)
+	for _, name := range actions {
+		action, _ := registry.Lookup(name)
+		var apis []string
+		for _, s := range action.Services {
+			apis = append(apis, serviceAPIs[s]...)
+		}
+		disabled, err := p.DisabledAPIs(ctx, projectID, unique(apis))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the APIs enabled in %q: %q", projectID, err)
+		}
+		if len(disabled) > 0 {
+			gaps = append(gaps, Gap{Action: name, Resource: "projects/" + projectID, APIs: disabled})
+		}
+		if len(action.Permissions) == 0 {
+			continue
+		}
+		for _, r := range unique(resources[name]) {
+			missing, err := p.MissingPermissions(ctx, r, action.Permissions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to test the permissions of %s on %s: %q", name, r, err)
+			}
+			if len(missing) > 0 {
+				gaps = append(gaps, Gap{Action: name, Resource: r, Permissions: missing})
+			}
+		}
+	}
+	return gaps, nil
+}
+
+// unique returns the values sorted without duplicates.
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	var l []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			l = append(l, v)
+		}
+	}
+	sort.Strings(l)
+	return l
+}
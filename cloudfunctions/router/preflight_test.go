@@ -0,0 +1,91 @@
+package router
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestPreflight(t *testing.T) {
+	base := []string{
+		"cloudfunctions.googleapis.com", "cloudresourcemanager.googleapis.com", "firestore.googleapis.com",
+		"logging.googleapis.com", "pubsub.googleapis.com",
+	}
+	for _, tt := range []struct {
+		name     string
+		enabled  []string
+		granted  []string
+		expected []Gap
+	}{
+		{
+			name:    "ready",
+			enabled: append([]string{"storage-api.googleapis.com"}, base...),
+			granted: []string{"storage.buckets.get", "storage.buckets.getIamPolicy", "storage.buckets.setIamPolicy"},
+		},
+		{
+			name:    "missing permission",
+			enabled: append([]string{"storage-api.googleapis.com"}, base...),
+			granted: []string{"storage.buckets.get", "storage.buckets.getIamPolicy"},
+			expected: []Gap{
+				{Action: "close_bucket", Resource: "folders/123", Permissions: []string{"storage.buckets.setIamPolicy"}},
+			},
+		},
+		{
+			name:    "disabled APIs",
+			enabled: []string{"cloudfunctions.googleapis.com", "pubsub.googleapis.com", "firestore.googleapis.com", "logging.googleapis.com"},
+			granted: []string{"storage.buckets.get", "storage.buckets.getIamPolicy", "storage.buckets.setIamPolicy"},
+			expected: []Gap{
+				{Resource: "projects/automation-project", APIs: []string{"cloudresourcemanager.googleapis.com"}},
+				{Action: "close_bucket", Resource: "projects/automation-project", APIs: []string{"cloudresourcemanager.googleapis.com", "storage-api.googleapis.com"}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Configuration{}
+			conf.Spec.Enabled = []string{"close_bucket"}
+			conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{
+				{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}},
+				{Action: "enable_bucket_only_policy", Target: []string{"organizations/456/*"}},
+			}
+			crm := &stubs.ResourceManagerStub{GrantedPermissions: map[string][]string{"folders/123": tt.granted}}
+			su := &stubs.ServiceUsageStub{StubbedEnabledServices: map[string][]string{"automation-project": tt.enabled}}
+			gaps, err := Preflight(context.Background(), conf, "automation-project", services.NewPreflight(crm, su))
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expected, gaps); diff != "" {
+				t.Errorf("%s gaps difference:%+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestAutomationResources(t *testing.T) {
+	a := Automation{
+		Target:         []string{"organizations/456/folders/123/*", "organizations/456/folders/789/projects/my-project", "organizations/456/*"},
+		OrganizationID: "456",
+		FolderIDs:      []string{"321"},
+		ProjectIDs:     []string{"other-project"},
+	}
+	expected := []string{"folders/123", "projects/my-project", "organizations/456", "organizations/456", "folders/321", "projects/other-project"}
+	if diff := cmp.Diff(expected, a.Resources()); diff != "" {
+		t.Errorf("resources difference:%+v", diff)
+	}
+}
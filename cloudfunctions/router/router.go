@@ -16,20 +16,49 @@ package router
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"log"
+	"reflect"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/billing/detachbilling"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/internal/cel"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/playbook"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/resourcemanager/shutdownproject"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router/internal/window"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router/policy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/serviceusage/disableapis"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/exportlogs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/labelresource"
+	"github.com/googlecloudplatform/security-response-automation/providers/chronicle"
+	"github.com/googlecloudplatform/security-response-automation/providers/cloudids"
+	"github.com/googlecloudplatform/security-response-automation/providers/containeranalysis"
+	"github.com/googlecloudplatform/security-response-automation/providers/custom"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/accountcompromise"
 	"github.com/googlecloudplatform/security-response-automation/providers/etd/anomalousiam"
 	"github.com/googlecloudplatform/security-response-automation/providers/etd/badip"
+	"github.com/googlecloudplatform/security-response-automation/providers/etd/exfiltration"
 	"github.com/googlecloudplatform/security-response-automation/providers/etd/sshbruteforce"
+	"github.com/googlecloudplatform/security-response-automation/providers/falco"
+	"github.com/googlecloudplatform/security-response-automation/providers/forseti"
+	"github.com/googlecloudplatform/security-response-automation/providers/guardduty"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/computeinstancescanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/containerscanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/datasetscanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/firewallscanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/iamscanner"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha/kmsscanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/loggingscanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/sqlscanner"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha/storagescanner"
@@ -38,22 +67,52 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-var findings = []Namer{
-	&anomalousiam.Finding{},
-	&badip.Finding{},
-	&sshbruteforce.Finding{},
-	&storagescanner.Finding{},
-	&sqlscanner.Finding{},
-	&containerscanner.Finding{},
-	&computeinstancescanner.Finding{},
-	&firewallscanner.Finding{},
-	&datasetscanner.Finding{},
-	&loggingscanner.Finding{},
-	&iamscanner.Finding{},
+// parsers is the registry of finding parsers, tried in order until one recognizes the finding.
+var parsers = []Parser{
+	{Source: "etd", Namer: &anomalousiam.Finding{}},
+	{Source: "etd", Namer: &badip.Finding{}},
+	{Source: "etd", Namer: &sshbruteforce.Finding{}},
+	{Source: "etd", Namer: &accountcompromise.Finding{}},
+	{Source: "etd", Namer: &exfiltration.Finding{}},
+	{Source: "sha", Namer: &storagescanner.Finding{}},
+	{Source: "sha", Namer: &sqlscanner.Finding{}},
+	{Source: "sha", Namer: &containerscanner.Finding{}},
+	{Source: "sha", Namer: &computeinstancescanner.Finding{}},
+	{Source: "sha", Namer: &firewallscanner.Finding{}},
+	{Source: "sha", Namer: &datasetscanner.Finding{}},
+	{Source: "sha", Namer: &loggingscanner.Finding{}},
+	{Source: "sha", Namer: &iamscanner.Finding{}},
+	{Source: "sha", Namer: &kmsscanner.Finding{}},
+	{Source: "custom", Namer: &custom.Finding{}},
+	{Source: "forseti", Namer: &forseti.Finding{}},
+	{Source: "falco", Namer: &falco.Finding{}},
+	{Source: "cloud_ids", Namer: &cloudids.Finding{}},
+	{Source: "chronicle", Namer: &chronicle.Finding{}},
+	{Source: "guardduty", Namer: &guardduty.Finding{}},
+	{Source: "container_analysis", Namer: &containeranalysis.Finding{}},
 }
 
+// deadLetterTopic receives findings no parser recognizes.
+const deadLetterTopic = "threat-findings-unsupported"
+
+// approvalTopic receives automations the policies require approval for.
+const approvalTopic = "threat-findings-approval-required"
+
+// playbookTopic receives the playbooks of routed findings.
+const playbookTopic = "threat-findings-playbook"
+
+// now returns the current time, replaced in tests.
+var now = time.Now
+
 // originalEventTime is the security mark key name used to hold the finding's event time.
 const originalEventTime = "sra-remediated-event-time"
+
+// correlationMark is the security mark key name used to hold the correlation ID of the finding's
+// remediation.
+const correlationMark = "sra-correlation-id"
+
+// correlationAttribute is the attribute of the messages sent for a finding holding its correlation ID.
+const correlationAttribute = services.CorrelationAttribute
 const configPath = "./serverless_function_source_code/config/sra.yaml"
 
 // Namer represents findings that export their name.
@@ -61,6 +120,34 @@ type Namer interface {
 	Name([]byte) string
 }
 
+// Leveler represents findings that export their severity.
+type Leveler interface {
+	Level([]byte) severity.Level
+}
+
+// Parser registers a provider's findings with the router.
+type Parser struct {
+	// Source is the provider of the findings, such as "sha" or "falco".
+	Source string
+	Namer  Namer
+}
+
+// Detection identifies a finding by its source, category and schema version.
+type Detection struct {
+	Source   string
+	Category string
+	// Version is the schema of the payload: "scc/v1" for Security Command Center notifications,
+	// "logging" for Cloud Logging entries or the schema version the finding declares.
+	Version string
+	// Severity is the normalized severity of the finding, if its parser can tell.
+	Severity severity.Level
+}
+
+// String returns the detection as "source/category@version".
+func (d *Detection) String() string {
+	return fmt.Sprintf("%s/%s@%s", d.Source, d.Category, d.Version)
+}
+
 // Services contains the services needed for this function.
 type Services struct {
 	PubSub                *services.PubSub
@@ -68,39 +155,71 @@ type Services struct {
 	Logger                *services.Logger
 	Resource              *services.Resource
 	SecurityCommandCenter *services.CommandCenter
+	// Policy gates automations on Rego policies, automations are not gated if nil.
+	Policy *policy.Engine
+	// Tasks defers automations outside of their execution window.
+	Tasks *services.Tasks
 }
 
 // Values contains the required values for this function.
 type Values struct {
 	Finding []byte
+	// CorrelationID identifies everything done for the finding, derived from the finding if empty.
+	CorrelationID string
+	// Simulated is whether the finding was published by the simulator, whose findings are not
+	// marked as they do not exist in Security Command Center.
+	Simulated bool
 }
 
-// topics maps automation targets to PubSub topics.
-var topics = map[string]struct{ Topic string }{
-	"gce_create_disk_snapshot":  {Topic: "threat-findings-create-disk-snapshot"},
-	"iam_revoke":                {Topic: "threat-findings-iam-revoke"},
-	"close_bucket":              {Topic: "threat-findings-close-bucket"},
-	"enable_bucket_only_policy": {Topic: "threat-findings-enable-bucket-only-policy"},
-	"close_cloud_sql":           {Topic: "threat-findings-remove-public-sql"},
-	"cloud_sql_require_ssl":     {Topic: "threat-findings-require-ssl"},
-	"cloud_sql_update_password": {Topic: "threat-findings-update-password"},
-	"disable_dashboard":         {Topic: "threat-findings-disable-dashboard"},
-	"remove_public_ip":          {Topic: "threat-findings-remove-public-ip"},
-	"remediate_firewall":        {Topic: "threat-findings-open-firewall"},
-	"close_public_dataset":      {Topic: "threat-findings-close-public-dataset"},
-	"enable_audit_logs":         {Topic: "threat-findings-enable-audit-logs"},
-	"remove_non_org_members":    {Topic: "threat-findings-remove-non-org-members"},
+// topicOf returns the PubSub topic of the registered action, empty if it is not registered.
+func topicOf(action string) string {
+	a, _ := registry.Lookup(action)
+	return a.Topic
 }
 
 // Automation represents configuration for an automation.
 type Automation struct {
-	Action     string
-	Target     []string
-	Exclude    []string
+	Action  string
+	Target  []string
+	Exclude []string
+	// OrganizationID targets every project of the organization in addition to those of Target.
+	OrganizationID string `yaml:"organization_id"`
+	// FolderIDs targets the projects of these folders and of their sub-folders at any depth, in
+	// addition to those of Target.
+	FolderIDs []string `yaml:"folder_ids"`
+	// ProjectIDs targets these projects in addition to those of Target.
+	ProjectIDs []string `yaml:"project_ids"`
+	// ExcludeProjectIDs excludes these projects whatever targets them.
+	ExcludeProjectIDs []string `yaml:"exclude_project_ids"`
+	// Labels limits the automation to the projects whose labels match every selector, such as
+	// "sra-enforce=true", "env!=dev", "team" or "!legacy".
+	Labels []string
+	// Condition is an optional CEL expression, the automation is skipped unless it evaluates to true.
+	Condition string
+	// MinSeverity skips the automation for findings rated below it or not rated at all.
+	MinSeverity string `yaml:"min_severity"`
+	// Window limits when the automation runs, automations outside of it are deferred until it opens.
+	Window window.Window
+	// Playbook chains the automation with the others of the same playbook, they run in the order
+	// they are configured in. The condition of a playbook's automation is evaluated when it runs.
+	Playbook string
+	// Otherwise is what happens to a playbook's automation if its condition is not met when it
+	// runs: "skip", the default, or "approval".
+	Otherwise string
+	// Approval holds the automation for approval if true or runs it directly if false, by default
+	// only the actions registered as requiring approval are held.
+	Approval   *bool
 	Properties struct {
-		DryRun    bool `yaml:"dry_run"`
-		RevokeIAM struct {
+		DryRun bool `yaml:"dry_run"`
+		// CanaryPercent runs the automation live for this percentage of projects or accounts and as a
+		// dry run for the rest, all are live if zero.
+		CanaryPercent int `yaml:"canary_percent"`
+		RevokeIAM     struct {
 			AllowDomains []string `yaml:"allow_domains"`
+			// ExpireAfterHours replaces anomalous grants by grants expiring after this many hours
+			// instead of removing them, notifying the grantor from From.
+			ExpireAfterHours int `yaml:"expire_after_hours"`
+			From             string
 		} `yaml:"revoke_iam"`
 		CreateSnapshot struct {
 			TargetSnapshotProjectID string `yaml:"target_snapshot_project_id"`
@@ -111,6 +230,10 @@ type Automation struct {
 				Topic     string
 				Zone      string
 			}
+			SnapshotSettings `yaml:",inline"`
+			// Overrides replace the settings above for the projects of some folders or with some
+			// labels, the first applying to the project being used.
+			Overrides []SnapshotOverride
 		} `yaml:"gce_create_snapshot"`
 		OpenFirewall struct {
 			SourceRanges      []string `yaml:"source_ranges"`
@@ -118,7 +241,128 @@ type Automation struct {
 		} `yaml:"open_firewall"`
 		NonOrgMembers struct {
 			AllowDomains []string `yaml:"allow_domains"`
+			// ExpandGroups removes groups containing identities not from the allowed domains.
+			ExpandGroups bool `yaml:"expand_groups"`
 		} `yaml:"non_org_members"`
+		Falco struct {
+			// Rules runs the automation for alerts raised by these Falco rules.
+			Rules []string `yaml:"rules"`
+			// Priorities runs the automation for alerts with these priorities.
+			Priorities []string `yaml:"priorities"`
+		} `yaml:"falco"`
+		ContainerAnalysis struct {
+			// Severities runs the automation for vulnerabilities with these severities, critical if empty.
+			Severities []string `yaml:"severities"`
+		} `yaml:"container_analysis"`
+		QuarantineImage struct {
+			// AttestorNote is the note of the attestor whose attestations of the image are revoked.
+			AttestorNote string `yaml:"attestor_note"`
+		} `yaml:"quarantine_image"`
+		SendEmail struct {
+			From string
+			To   []string
+		} `yaml:"send_email"`
+		LabelResource struct {
+			// Labels are added to the labels naming the finding and marking its triage as pending.
+			Labels map[string]string
+		} `yaml:"label_resource"`
+		ExportEvidenceLogs struct {
+			// WindowMinutes is how long before and after the finding entries are exported for.
+			WindowMinutes int `yaml:"window_minutes"`
+			// MaxEntries bounds the entries exported of each log.
+			MaxEntries int `yaml:"max_entries"`
+		} `yaml:"export_evidence_logs"`
+		RemoveFromGroups struct {
+			// Groups are the email addresses of the privileged groups to remove users from.
+			Groups []string
+		} `yaml:"workspace_remove_from_groups"`
+		Enforce2SV struct {
+			// OrgUnit is the path of an organizational unit enforcing 2-Step Verification.
+			OrgUnit string `yaml:"org_unit"`
+			// Group is the email address of a group enforcing 2-Step Verification.
+			Group string
+		} `yaml:"workspace_enforce_2sv"`
+		ContainServiceAccount struct {
+			// From and To are the sender and additional recipients of the notification sent to
+			// the owners of the service account's project.
+			From string
+			To   []string
+			// FolderIDs are folders whose projects, at any depth, the service account is removed
+			// from in addition to its own project and the finding's project.
+			FolderIDs []string `yaml:"folder_ids"`
+			// FanOutAbove is the number of projects above which their removals are published as
+			// work items to the FanOut function, zero never fans out.
+			FanOutAbove int `yaml:"fan_out_above"`
+		} `yaml:"iam_contain_service_account"`
+		RestrictExternalIPs struct {
+			// Scope sets the constraint on the "project" of the finding, the default, or on the
+			// "folder" the project is directly in.
+			Scope string
+			// AllowedInstances keep their external IP addresses, named
+			// "projects/<id>/zones/<zone>/instances/<name>". Every instance is denied one if empty.
+			AllowedInstances []string `yaml:"allowed_instances"`
+			// Threshold is the number of findings of the project within the window the constraint
+			// is set after, from the first finding if zero.
+			Threshold int
+			// WindowHours is how far back findings are counted, a week if zero.
+			WindowHours int `yaml:"window_hours"`
+		} `yaml:"gce_restrict_external_ips"`
+		PreventPublicAccess struct {
+			// Threshold is the number of public bucket findings within the folder in the window
+			// the constraint is set after, from the first finding if zero.
+			Threshold int
+			// WindowHours is how far back findings are counted, a day if zero.
+			WindowHours int `yaml:"window_hours"`
+		} `yaml:"gcs_prevent_public_access"`
+		RotateKey struct {
+			// RotationPeriodDays is how often the key is rotated, 90 days if zero.
+			RotationPeriodDays int `yaml:"rotation_period_days"`
+			// NextRotationHours is how soon the key is first rotated, a day if zero.
+			NextRotationHours int `yaml:"next_rotation_hours"`
+		} `yaml:"kms_rotate_key"`
+		RemoveKeyPublicAccess struct {
+			// AllowDomains are the domains of the users, groups and domains kept, only allUsers and
+			// allAuthenticatedUsers are removed if empty.
+			AllowDomains []string `yaml:"allow_domains"`
+		} `yaml:"kms_remove_public_access"`
+		CloseSecret struct {
+			// AllowDomains are the domains of the users and groups kept, only allUsers and
+			// allAuthenticatedUsers are removed if empty.
+			AllowDomains []string `yaml:"allow_domains"`
+			// Rotate adds a random version to the secret and disables the others.
+			Rotate bool
+			// PayloadBytes is the number of random bytes of the version added, 32 if zero.
+			PayloadBytes int `yaml:"payload_bytes"`
+		} `yaml:"close_secret"`
+		DisableAPIs struct {
+			// APIs are the APIs disabled, such as "compute.googleapis.com".
+			APIs []string
+			// Protected are APIs never disabled in addition to the built in ones.
+			Protected []string
+			// DisableDependents disables the APIs depending on those disabled too.
+			DisableDependents bool `yaml:"disable_dependents"`
+		} `yaml:"disable_apis"`
+		ShutdownProject struct {
+			// Mode is "shutdown", the default, to shut the project down or "lien" to place a lien
+			// preventing its deletion instead.
+			Mode string
+			// Reason is recorded on the lien, the finding's if empty.
+			Reason string
+		} `yaml:"shutdown_project"`
+		EnableAuditLogs struct {
+			// Services are the services whose Data Access audit logs are enabled, all services if
+			// empty.
+			Services []string
+		} `yaml:"enable_audit_logs"`
+		AddToPerimeter struct {
+			// Perimeter is the restricted perimeter, named
+			// "accessPolicies/<id>/servicePerimeters/<name>".
+			Perimeter string
+		} `yaml:"vpcsc_add_to_perimeter"`
+		RestrictAccessLevel struct {
+			// AccessLevel is the access level, named "accessPolicies/<id>/accessLevels/<name>".
+			AccessLevel string `yaml:"access_level"`
+		} `yaml:"vpcsc_restrict_access_level"`
 	}
 }
 
@@ -126,12 +370,22 @@ type Automation struct {
 type Configuration struct {
 	APIVersion string
 	Spec       struct {
-		Name       string
-		Parameters struct {
+		Name string
+		// Enabled lists the actions automations can run, all registered actions are enabled if empty.
+		Enabled []string `yaml:"enabled_actions"`
+		// StrictSchema dead letters findings missing a field their parser reads or holding top
+		// level fields their schema does not define, instead of routing them with empty values.
+		StrictSchema bool `yaml:"strict_schema"`
+		Parameters   struct {
 			ETD struct {
 				BadIP         []Automation `yaml:"bad_ip"`
 				AnomalousIAM  []Automation `yaml:"anomalous_iam"`
 				SSHBruteForce []Automation `yaml:"ssh_brute_force"`
+				// AccountCompromise runs on leaked credentials and suspicious logins of Google
+				// Workspace or Cloud Identity users.
+				AccountCompromise []Automation `yaml:"account_compromise"`
+				// Exfiltration runs on BigQuery and Cloud SQL data exfiltration.
+				Exfiltration []Automation `yaml:"exfiltration"`
 			}
 			SHA struct {
 				PublicBucketACL         []Automation `yaml:"public_bucket_acl"`
@@ -145,15 +399,47 @@ type Configuration struct {
 				AuditLoggingDisabled    []Automation `yaml:"audit_logging_disabled"`
 				WebUIEnabled            []Automation `yaml:"web_ui_enabled"`
 				NonOrgMembers           []Automation `yaml:"non_org_members"`
+				KMSKeyNotRotated        []Automation `yaml:"kms_key_not_rotated"`
+				KMSPublicKey            []Automation `yaml:"kms_public_key"`
+				// OverPrivileged runs on primitive roles and over-privileged service account findings.
+				OverPrivileged []Automation `yaml:"over_privileged"`
 			}
+			Forseti struct {
+				BucketViolation   []Automation `yaml:"bucket_violation"`
+				FirewallViolation []Automation `yaml:"firewall_violation"`
+			}
+			Falco struct {
+				RuntimeAlert []Automation `yaml:"runtime_alert"`
+			}
+			CloudIDS struct {
+				Threat []Automation `yaml:"threat"`
+			} `yaml:"cloud_ids"`
+			Chronicle struct {
+				// Rules maps Chronicle rule IDs or rule names to automations.
+				Rules map[string][]Automation `yaml:"rules"`
+			}
+			ContainerAnalysis struct {
+				Vulnerability []Automation `yaml:"vulnerability"`
+			} `yaml:"container_analysis"`
+			GuardDuty struct {
+				AccessKey []Automation `yaml:"access_key"`
+				Instance  []Automation `yaml:"instance"`
+			} `yaml:"guardduty"`
+			// Custom maps categories of custom findings to automations.
+			Custom map[string][]Automation `yaml:"custom"`
 		}
 	}
 }
 
 // Config will return the router's configuration.
 func Config() (*Configuration, error) {
+	return ReadConfig(configPath)
+}
+
+// ReadConfig reads the router's configuration from the given file.
+func ReadConfig(path string) (*Configuration, error) {
 	var c Configuration
-	b, err := ioutil.ReadFile(configPath)
+	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -163,18 +449,112 @@ func Config() (*Configuration, error) {
 	return &c, nil
 }
 
-// ruleName will attempt to deserialize all findings until a name is extracted.
-func ruleName(b []byte) string {
-	for _, finding := range findings {
-		if n := finding.Name(b); n != "" {
-			return n
+// Automations returns the configured automations keyed by the path of their finding, such as
+// "sha/public_bucket_acl" or "custom/my_category".
+func (c *Configuration) Automations() map[string][]Automation {
+	m := make(map[string][]Automation)
+	collect(reflect.ValueOf(c.Spec.Parameters), "", m)
+	return m
+}
+
+func collect(v reflect.Value, path string, m map[string][]Automation) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			collect(v.Field(i), strings.TrimPrefix(path+"/"+name, "/"), m)
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			collect(v.MapIndex(k), path+"/"+k.String(), m)
+		}
+	case reflect.Slice:
+		if a, ok := v.Interface().([]Automation); ok && len(a) > 0 {
+			m[path] = a
+		}
+	}
+}
+
+// detect will attempt to deserialize the finding with all parsers until one recognizes it.
+func detect(b []byte) (*Detection, error) {
+	for _, p := range parsers {
+		if n := p.Namer.Name(b); n != "" {
+			d := &Detection{Source: p.Source, Category: n, Version: schemaVersion(b)}
+			if l, ok := p.Namer.(Leveler); ok {
+				d.Severity = l.Level(b)
+			}
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported finding: no parser recognizes the payload")
+}
+
+// schemaVersion returns the schema of the finding's payload.
+func schemaVersion(b []byte) string {
+	var v struct {
+		NotificationConfigName string `json:"notificationConfigName"`
+		LogName                string `json:"logName"`
+		SchemaVersion          string `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return ""
+	}
+	switch {
+	case v.NotificationConfigName != "":
+		return "scc/v1"
+	case v.LogName != "":
+		return "logging"
+	}
+	return v.SchemaVersion
+}
+
+// correlationID returns the correlation ID given with the finding, or one derived from its payload
+// so redeliveries of a notification share it.
+func correlationID(values *Values) string {
+	if values.CorrelationID != "" {
+		return values.CorrelationID
+	}
+	sum := sha256.Sum256(values.Finding)
+	return hex.EncodeToString(sum[:8])
+}
+
+// deadLetter publishes the unsupported finding to the dead letter topic so it is not lost.
+func deadLetter(ctx context.Context, services *Services, finding []byte, reason error) error {
+	attributes := map[string]string{"error": reason.Error()}
+	if e, ok := reason.(*SchemaError); ok {
+		for k, v := range e.attributes() {
+			attributes[k] = v
 		}
 	}
-	return ""
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	if ok {
+		attributes[correlationAttribute] = r.correlationID
+	}
+	if _, err := services.PubSub.Publish(ctx, deadLetterTopic, &pubsub.Message{
+		Data:       finding,
+		Attributes: attributes,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to publish to %q", deadLetterTopic)
+	}
+	if ok {
+		r.deadLettered = true
+	}
+	return nil
 }
 
 func markAsRemediated(ctx context.Context, name, eventTime string, services *Services) error {
 	m := map[string]string{"sra-remediated-event-time": eventTime}
+	if r, ok := ctx.Value(routingKey{}).(*routing); ok {
+		if r.simulated {
+			services.Logger.Info("not marking simulated finding %q", name)
+			return nil
+		}
+		m[correlationMark] = r.correlationID
+	}
 	if _, err := services.SecurityCommandCenter.AddSecurityMarks(ctx, name, m); err != nil {
 		return err
 	}
@@ -182,14 +562,68 @@ func markAsRemediated(ctx context.Context, name, eventTime string, services *Ser
 }
 
 // Execute will route the incoming finding to the appropriate remediations.
+//
+// The log entries, security marks and messages of the finding carry its correlation ID. Messages
+// batching several findings are routed finding by finding, see executeBatch.
 func Execute(ctx context.Context, values *Values, services *Services) error {
-	switch name := ruleName(values.Finding); name {
+	values.CorrelationID = correlationID(values)
+	finding, err := scc.Decode(values.Finding)
+	if err != nil {
+		return err
+	}
+	values.Finding = finding
+	if findings, ok := batched(values.Finding); ok {
+		return executeBatch(ctx, findings, values, services)
+	}
+	_, err = execute(ctx, values, services)
+	return err
+}
+
+// execute routes a single finding, returning how it was routed.
+func execute(ctx context.Context, values *Values, services *Services) (*routing, error) {
+	id := correlationID(values)
+	correlated := *services
+	correlated.Logger = services.Logger.WithCorrelation(id)
+	services = &correlated
+	r := &routing{finding: values.Finding, correlationID: id, simulated: values.Simulated}
+	ctx = context.WithValue(ctx, routingKey{}, r)
+	d, err := detect(values.Finding)
+	if err != nil {
+		if err := deadLetter(ctx, services, values.Finding, err); err != nil {
+			services.Logger.Error("failed to dead letter finding: %q", err)
+		}
+		return r, err
+	}
+	r.detection = d
+	log.Printf("detected finding %s with correlation ID %s", d, id)
+	if services.Configuration.Spec.StrictSchema {
+		if err := validate(d, values.Finding); err != nil {
+			if err := deadLetter(ctx, services, values.Finding, err); err != nil {
+				services.Logger.Error("failed to dead letter finding: %q", err)
+			}
+			return r, err
+		}
+	}
+	err = route(ctx, d.Category, values, services)
+	if perr := publishPlaybooks(ctx, services, r); perr != nil {
+		return r, perr
+	}
+	return r, err
+}
+
+// route runs the automations configured for the finding's category.
+func route(ctx context.Context, name string, values *Values, services *Services) error {
+	switch name {
 	case "bad_ip":
 		return executeBadIP(ctx, name, values, services)
 	case "iam_anomalous_grant":
 		return executeIamAnomalousGrant(ctx, name, values, services)
 	case "ssh_brute_force":
 		return executeSSHBruteForce(ctx, name, values, services)
+	case "account_compromise":
+		return executeAccountCompromise(ctx, name, values, services)
+	case "exfiltration":
+		return executeExfiltration(ctx, name, values, services)
 	case "public_bucket_acl":
 		return executePublicBucketACL(ctx, name, values, services)
 	case "bucket_policy_only_disabled":
@@ -216,6 +650,30 @@ func Execute(ctx context.Context, values *Values, services *Services) error {
 		return executeWebUIEnabled(ctx, name, values, services)
 	case "non_org_iam_member":
 		return executeNonOrgIamMember(ctx, name, values, services)
+	case "primitive_roles_used", "over_privileged_service_account_user", "admin_service_account":
+		return executeOverPrivileged(ctx, name, values, services)
+	case "kms_key_not_rotated":
+		return executeKMSKeyNotRotated(ctx, name, values, services)
+	case "kms_public_key":
+		return executeKMSPublicKey(ctx, name, values, services)
+	case "custom":
+		return executeCustom(ctx, name, values, services)
+	case "falco_alert":
+		return executeFalcoAlert(ctx, name, values, services)
+	case "ids_threat":
+		return executeCloudIDSThreat(ctx, name, values, services)
+	case "chronicle_detection":
+		return executeChronicleDetection(ctx, name, values, services)
+	case "container_vulnerability":
+		return executeContainerVulnerability(ctx, name, values, services)
+	case "guardduty_access_key":
+		return executeGuardDutyAccessKey(ctx, name, values, services)
+	case "guardduty_instance":
+		return executeGuardDutyInstance(ctx, name, values, services)
+	case "bucket_violation":
+		return executeForsetiBucketViolation(ctx, name, values, services)
+	case "firewall_blacklist_violation", "firewall_whitelist_violation":
+		return executeForsetiFirewallViolation(ctx, name, values, services)
 	default:
 		return fmt.Errorf("rule %q not found", name)
 	}
@@ -239,21 +697,21 @@ func executeBadIP(ctx context.Context, name string, values *Values, services *Se
 	for _, automation := range automations {
 		switch automation.Action {
 		case "gce_create_disk_snapshot":
-			values := badIP.CreateSnapshot()
-			values.DryRun = automation.Properties.DryRun
-			values.Output = automation.Properties.CreateSnapshot.Output
-			values.DestProjectID = automation.Properties.CreateSnapshot.TargetSnapshotProjectID
-			values.DestZone = automation.Properties.CreateSnapshot.TargetSnapshotZone
-			values.Turbinia.ProjectID = automation.Properties.CreateSnapshot.Turbinia.ProjectID
-			values.Turbinia.Topic = automation.Properties.CreateSnapshot.Turbinia.Topic
-			values.Turbinia.Zone = automation.Properties.CreateSnapshot.Turbinia.Zone
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			snapshot := badIP.CreateSnapshot()
+			snapshot.DryRun = automation.Properties.DryRun
+			configureSnapshot(snapshot, automation)
+			if resource, err := scc.ResourceOf(values.Finding); err == nil {
+				snapshot.FindingID = resource.FindingID
+			}
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, snapshot.ProjectID, snapshot); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if badIP.UseCSCC {
@@ -277,13 +735,17 @@ func executeIamAnomalousGrant(ctx context.Context, name string, values *Values,
 			values := anomalousIAM.IAMRevoke()
 			values.DryRun = automation.Properties.DryRun
 			values.AllowDomains = automation.Properties.RevokeIAM.AllowDomains
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			values.ExpireAfterHours = automation.Properties.RevokeIAM.ExpireAfterHours
+			values.From = automation.Properties.RevokeIAM.From
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -302,13 +764,168 @@ func executeSSHBruteForce(ctx context.Context, name string, values *Values, serv
 			values := sshBruteForce.OpenFirewall()
 			values.DryRun = automation.Properties.DryRun
 			values.Action = "block_ssh"
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "gce_require_os_login":
+			values := sshBruteForce.RequireOSLogin()
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeAccountCompromise(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.ETD.AccountCompromise
+	finding, err := accountcompromise.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q for %q with %d automations", name, finding.RuleName(), len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "workspace_suspend_user":
+			values, err := finding.SuspendUser()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishUser(ctx, services, automation, topic, values.Email, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "workspace_remove_from_groups":
+			values, err := finding.RemoveFromGroups()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.Groups = automation.Properties.RemoveFromGroups.Groups
+			if len(values.Groups) == 0 {
+				services.Logger.Error("no groups configured for %q", automation.Action)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishUser(ctx, services, automation, topic, values.Email, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "workspace_enforce_2sv":
+			values, err := finding.Enforce2SV()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.OrgUnit = automation.Properties.Enforce2SV.OrgUnit
+			values.Group = automation.Properties.Enforce2SV.Group
+			if values.OrgUnit == "" && values.Group == "" {
+				services.Logger.Error("no org_unit or group configured for %q", automation.Action)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishUser(ctx, services, automation, topic, values.Email, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "iam_contain_service_account":
+			values, err := finding.ContainServiceAccount()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.From = automation.Properties.ContainServiceAccount.From
+			values.To = automation.Properties.ContainServiceAccount.To
+			values.FolderIDs = automation.Properties.ContainServiceAccount.FolderIDs
+			values.FanOutAbove = automation.Properties.ContainServiceAccount.FanOutAbove
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "vpcsc_restrict_access_level":
+			values, err := finding.RestrictAccessLevel()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.AccessLevel = automation.Properties.RestrictAccessLevel.AccessLevel
+			if values.AccessLevel == "" {
+				services.Logger.Error("no access level configured for %q", automation.Action)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishUser(ctx, services, automation, topic, finding.User(), values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "workspace_revoke_sessions":
+			values, err := finding.RevokeSessions()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishUser(ctx, services, automation, topic, values.Email, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeExfiltration(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.ETD.Exfiltration
+	finding, err := exfiltration.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q for %q with %d automations", name, finding.RuleName(), len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "vpcsc_add_to_perimeter":
+			values, err := finding.AddToPerimeter()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.Perimeter = automation.Properties.AddToPerimeter.Perimeter
+			if values.Perimeter == "" {
+				services.Logger.Error("no perimeter configured for %q", automation.Action)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -332,13 +949,25 @@ func executePublicBucketACL(ctx context.Context, name string, values *Values, se
 		case "close_bucket":
 			values := storageScanner.CloseBucket()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "gcs_prevent_public_access":
+			values := storageScanner.PreventPublicAccess()
+			values.Threshold = automation.Properties.PreventPublicAccess.Threshold
+			values.WindowHours = automation.Properties.PreventPublicAccess.WindowHours
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, storageScanner.StorageScanner.GetFinding().GetName(), storageScanner.StorageScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -365,13 +994,15 @@ func executeBucketPolicyOnlyDisabled(ctx context.Context, name string, values *V
 		case "enable_bucket_only_policy":
 			values := storageScanner.EnableBucketOnlyPolicy()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, storageScanner.StorageScanner.GetFinding().GetName(), storageScanner.StorageScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -398,13 +1029,15 @@ func executePublicSQLInstance(ctx context.Context, name string, values *Values,
 		case "close_cloud_sql":
 			values := sqlScanner.RemovePublic()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, sqlScanner.SQLScanner.GetFinding().GetName(), sqlScanner.SQLScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -431,13 +1064,15 @@ func executeSSLNotEnforced(ctx context.Context, name string, values *Values, ser
 		case "cloud_sql_require_ssl":
 			values := sqlScanner.RequireSSL()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, sqlScanner.SQLScanner.GetFinding().GetName(), sqlScanner.SQLScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -468,13 +1103,15 @@ func executeSQLNoRootPassword(ctx context.Context, name string, values *Values,
 				continue
 			}
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, sqlScanner.SQLScanner.GetFinding().GetName(), sqlScanner.SQLScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -501,13 +1138,27 @@ func executePublicIPAddress(ctx context.Context, name string, values *Values, se
 		case "remove_public_ip":
 			values := computeInstanceScanner.RemovePublicIP()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "gce_restrict_external_ips":
+			values := computeInstanceScanner.RestrictExternalIPs()
+			values.Scope = automation.Properties.RestrictExternalIPs.Scope
+			values.AllowedInstances = automation.Properties.RestrictExternalIPs.AllowedInstances
+			values.Threshold = automation.Properties.RestrictExternalIPs.Threshold
+			values.WindowHours = automation.Properties.RestrictExternalIPs.WindowHours
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, computeInstanceScanner.ComputeInstanceScanner.GetFinding().GetName(), computeInstanceScanner.ComputeInstanceScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -536,13 +1187,15 @@ func executeOpenFirewall(ctx context.Context, name string, values *Values, servi
 			values.DryRun = automation.Properties.DryRun
 			values.SourceRanges = automation.Properties.OpenFirewall.SourceRanges
 			values.Action = automation.Properties.OpenFirewall.RemediationAction
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, firewallScanner.FirewallScanner.GetFinding().GetName(), firewallScanner.FirewallScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -571,13 +1224,15 @@ func executeOpenSSHPort(ctx context.Context, name string, values *Values, servic
 			values.DryRun = automation.Properties.DryRun
 			values.SourceRanges = automation.Properties.OpenFirewall.SourceRanges
 			values.Action = automation.Properties.OpenFirewall.RemediationAction
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, firewallScanner.FirewallScanner.GetFinding().GetName(), firewallScanner.FirewallScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -606,13 +1261,15 @@ func executeOpenRDPPort(ctx context.Context, name string, values *Values, servic
 			values.DryRun = automation.Properties.DryRun
 			values.SourceRanges = automation.Properties.OpenFirewall.SourceRanges
 			values.Action = automation.Properties.OpenFirewall.RemediationAction
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, firewallScanner.FirewallScanner.GetFinding().GetName(), firewallScanner.FirewallScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -639,13 +1296,15 @@ func executePublicDataset(ctx context.Context, name string, values *Values, serv
 		case "close_public_dataset":
 			values := publicDataset.ClosePublicDataset()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, publicDataset.DatasetScanner.GetFinding().GetName(), publicDataset.DatasetScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -671,14 +1330,17 @@ func executeAuditLoggingDisabled(ctx context.Context, name string, values *Value
 		switch automation.Action {
 		case "enable_audit_logs":
 			values := loggingScanner.EnableAuditLogs()
+			values.Services = automation.Properties.EnableAuditLogs.Services
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, loggingScanner.Loggingscanner.GetFinding().GetName(), loggingScanner.Loggingscanner.GetFinding().GetEventTime(), services); err != nil {
@@ -687,6 +1349,79 @@ func executeAuditLoggingDisabled(ctx context.Context, name string, values *Value
 	return nil
 }
 
+func executeKMSKeyNotRotated(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.SHA.KMSKeyNotRotated
+	kmsScanner, err := kmsscanner.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	securityMarks := kmsScanner.KmsScanner.GetFinding().GetSecurityMarks().GetMarks()
+	remediated := securityMarks[originalEventTime] == kmsScanner.KmsScanner.GetFinding().GetEventTime()
+	if remediated {
+		log.Printf("finding already remediated")
+		return nil
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "kms_rotate_key":
+			values := kmsScanner.RotateKey()
+			values.RotationPeriodDays = automation.Properties.RotateKey.RotationPeriodDays
+			values.NextRotationHours = automation.Properties.RotateKey.NextRotationHours
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	if err := markAsRemediated(ctx, kmsScanner.KmsScanner.GetFinding().GetName(), kmsScanner.KmsScanner.GetFinding().GetEventTime(), services); err != nil {
+		return err
+	}
+	return nil
+}
+
+func executeKMSPublicKey(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.SHA.KMSPublicKey
+	kmsScanner, err := kmsscanner.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	securityMarks := kmsScanner.KmsScanner.GetFinding().GetSecurityMarks().GetMarks()
+	remediated := securityMarks[originalEventTime] == kmsScanner.KmsScanner.GetFinding().GetEventTime()
+	if remediated {
+		log.Printf("finding already remediated")
+		return nil
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "kms_remove_public_access":
+			values := kmsScanner.RemovePublicAccess()
+			values.AllowDomains = automation.Properties.RemoveKeyPublicAccess.AllowDomains
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	if err := markAsRemediated(ctx, kmsScanner.KmsScanner.GetFinding().GetName(), kmsScanner.KmsScanner.GetFinding().GetEventTime(), services); err != nil {
+		return err
+	}
+	return nil
+}
+
 func executeWebUIEnabled(ctx context.Context, name string, values *Values, services *Services) error {
 	automations := services.Configuration.Spec.Parameters.SHA.WebUIEnabled
 	containerScanner, err := containerscanner.New(values.Finding)
@@ -705,13 +1440,15 @@ func executeWebUIEnabled(ctx context.Context, name string, values *Values, servi
 		case "disable_dashboard":
 			values := containerScanner.DisableDashboard()
 			values.DryRun = automation.Properties.DryRun
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, containerScanner.Containerscanner.GetFinding().GetName(), containerScanner.Containerscanner.GetFinding().GetEventTime(), services); err != nil {
@@ -739,13 +1476,16 @@ func executeNonOrgIamMember(ctx context.Context, name string, values *Values, se
 			values := iamScanner.RemoveNonOrgMembers()
 			values.DryRun = automation.Properties.DryRun
 			values.AllowDomains = automation.Properties.NonOrgMembers.AllowDomains
-			topic := topics[automation.Action].Topic
-			if err := publish(ctx, services, automation.Action, topic, values.ProjectID, automation.Target, automation.Exclude, values); err != nil {
+			values.ExpandGroups = automation.Properties.NonOrgMembers.ExpandGroups
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
 				services.Logger.Error("failed to publish: %q", err)
 				continue
 			}
 		default:
-			return fmt.Errorf("action %q not found", automation.Action)
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
 		}
 	}
 	if err := markAsRemediated(ctx, iamScanner.IAMScanner.GetFinding().GetName(), iamScanner.IAMScanner.GetFinding().GetEventTime(), services); err != nil {
@@ -754,20 +1494,1153 @@ func executeNonOrgIamMember(ctx context.Context, name string, values *Values, se
 	return nil
 }
 
-func publish(ctx context.Context, services *Services, action, topic, projectID string, target, exclude []string, values interface{}) error {
-	ok, err := services.Resource.CheckMatches(ctx, projectID, target, exclude)
+func executeOverPrivileged(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.SHA.OverPrivileged
+	iamScanner, err := iamscanner.New(values.Finding)
 	if err != nil {
-		return errors.Wrapf(err, "failed to check if project %q is within the target or is excluded", projectID)
+		return err
 	}
-	if !ok {
-		return fmt.Errorf("project %q is not within the target or is excluded", projectID)
+	securityMarks := iamScanner.IAMScanner.GetFinding().GetSecurityMarks().GetMarks()
+	remediated := securityMarks[originalEventTime] == iamScanner.IAMScanner.GetFinding().GetEventTime()
+	if remediated {
+		log.Printf("finding already remediated")
+		return nil
 	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "iam_downgrade_roles":
+			values, err := iamScanner.DowngradeRoles()
+			if err != nil {
+				services.Logger.Error("failed to read finding: %q", err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	if err := markAsRemediated(ctx, iamScanner.IAMScanner.GetFinding().GetName(), iamScanner.IAMScanner.GetFinding().GetEventTime(), services); err != nil {
+		return err
+	}
+	return nil
+}
+
+func executeCustom(ctx context.Context, name string, values *Values, services *Services) error {
+	customFinding, err := custom.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	category := customFinding.Category()
+	automations := services.Configuration.Spec.Parameters.Custom[category]
+	log.Printf("got rule %q for category %q with %d automations", name, category, len(automations))
+	for _, automation := range automations {
+		if r := customFinding.RecommendedAction(); r != "" && r != automation.Action {
+			continue
+		}
+		if _, ok := registry.Lookup(automation.Action); !ok {
+			return fmt.Errorf("action %q not found", automation.Action)
+		}
+		projectID, values, err := customValues(customFinding, automation)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			continue
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, projectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+			continue
+		}
+	}
+	return nil
+}
+
+func executeForsetiBucketViolation(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.Forseti.BucketViolation
+	violation, err := forseti.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "close_bucket":
+			values, err := violation.CloseBucket()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "enable_bucket_only_policy":
+			values, err := violation.EnableBucketOnlyPolicy()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeForsetiFirewallViolation(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.Forseti.FirewallViolation
+	violation, err := forseti.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "remediate_firewall":
+			rules, err := violation.OpenFirewall()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			topic := topicOf(automation.Action)
+			for _, values := range rules {
+				values.DryRun = automation.Properties.DryRun
+				values.SourceRanges = automation.Properties.OpenFirewall.SourceRanges
+				values.Action = automation.Properties.OpenFirewall.RemediationAction
+				if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+					services.Logger.Error("failed to publish: %q", err)
+					continue
+				}
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeFalcoAlert(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.Falco.RuntimeAlert
+	alert, err := falco.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		if !matchesAny(automation.Properties.Falco.Rules, alert.Rule()) && !matchesAny(automation.Properties.Falco.Priorities, alert.Priority()) {
+			log.Printf("alert %q with priority %q does not match %q", alert.Rule(), alert.Priority(), automation.Action)
+			continue
+		}
+		switch automation.Action {
+		case "gke_isolate_pod":
+			values, err := alert.IsolatePod()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "gke_cordon_node":
+			values, err := alert.CordonNode()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeCloudIDSThreat(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.CloudIDS.Threat
+	threat, err := cloudids.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "gce_block_ip":
+			values, err := threat.BlockIP()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "gce_quarantine_instance":
+			values, err := threat.QuarantineInstance()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeContainerVulnerability(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.ContainerAnalysis.Vulnerability
+	finding, err := containeranalysis.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		severities := automation.Properties.ContainerAnalysis.Severities
+		if len(severities) == 0 {
+			severities = []string{"critical"}
+		}
+		if !matchesAny(severities, finding.Severity()) {
+			log.Printf("vulnerability %q with severity %q does not match %q", finding.CVE(), finding.Severity(), automation.Action)
+			continue
+		}
+		switch automation.Action {
+		case "quarantine_image":
+			values := finding.QuarantineImage()
+			values.AttestorNote = automation.Properties.QuarantineImage.AttestorNote
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		case "send_email":
+			values := finding.SendEmail()
+			values.From = automation.Properties.SendEmail.From
+			values.To = automation.Properties.SendEmail.To
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeGuardDutyAccessKey(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.GuardDuty.AccessKey
+	finding, err := guardduty.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "aws_disable_access_key":
+			values, err := finding.DisableAccessKey()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishAWS(ctx, services, automation, topic, values.AccountID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeGuardDutyInstance(ctx context.Context, name string, values *Values, services *Services) error {
+	automations := services.Configuration.Spec.Parameters.GuardDuty.Instance
+	finding, err := guardduty.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	log.Printf("got rule %q with %d automations", name, len(automations))
+	for _, automation := range automations {
+		switch automation.Action {
+		case "aws_isolate_instance":
+			values, err := finding.IsolateInstance()
+			if err != nil {
+				services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+				continue
+			}
+			values.DryRun = automation.Properties.DryRun
+			topic := topicOf(automation.Action)
+			if err := publishAWS(ctx, services, automation, topic, values.AccountID, values); err != nil {
+				services.Logger.Error("failed to publish: %q", err)
+				continue
+			}
+		default:
+			if err := executeGeneric(ctx, services, automation, values.Finding); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func executeChronicleDetection(ctx context.Context, name string, values *Values, services *Services) error {
+	detection, err := chronicle.New(values.Finding)
+	if err != nil {
+		return err
+	}
+	rules := services.Configuration.Spec.Parameters.Chronicle.Rules
+	automations, ok := rules[detection.RuleID()]
+	if !ok {
+		automations = rules[detection.RuleName()]
+	}
+	log.Printf("got rule %q for chronicle rule %q with %d automations", name, detection.RuleName(), len(automations))
+	for _, automation := range automations {
+		if _, ok := registry.Lookup(automation.Action); !ok {
+			return fmt.Errorf("action %q not found", automation.Action)
+		}
+		projectID, values, err := chronicleValues(detection, automation)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			continue
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, projectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+			continue
+		}
+	}
+	return nil
+}
+
+// chronicleValues returns the project and values of the automation for the detection.
+func chronicleValues(f *chronicle.Finding, automation Automation) (string, interface{}, error) {
+	switch automation.Action {
+	case "gce_block_ip":
+		values, err := f.BlockIP()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "gce_quarantine_instance":
+		values, err := f.QuarantineInstance()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "close_bucket":
+		values, err := f.CloseBucket()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "enable_bucket_only_policy":
+		values, err := f.EnableBucketOnlyPolicy()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "iam_revoke":
+		values, err := f.IAMRevoke()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		values.AllowDomains = automation.Properties.RevokeIAM.AllowDomains
+		values.ExpireAfterHours = automation.Properties.RevokeIAM.ExpireAfterHours
+		values.From = automation.Properties.RevokeIAM.From
+		return values.ProjectID, values, nil
+	default:
+		return "", nil, fmt.Errorf("action %q not supported for chronicle detections", automation.Action)
+	}
+}
+
+// executeGeneric runs the automations any finding can trigger, whatever its category, returning an
+// error if the action is not one of them.
+func executeGeneric(ctx context.Context, services *Services, automation Automation, finding []byte) error {
+	switch automation.Action {
+	case "label_resource":
+		resource, err := scc.ResourceOf(finding)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			return nil
+		}
+		values := &labelresource.Values{
+			ProjectID:    resource.ProjectID,
+			ResourceName: resource.Name,
+			FindingID:    resource.FindingID,
+			Labels:       automation.Properties.LabelResource.Labels,
+			DryRun:       automation.Properties.DryRun,
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+		}
+		return nil
+	case "export_evidence_logs":
+		resource, err := scc.ResourceOf(finding)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			return nil
+		}
+		values := &exportlogs.Values{
+			ProjectID:     resource.ProjectID,
+			ResourceName:  resource.Name,
+			FindingID:     resource.FindingID,
+			FindingTime:   routedTime(ctx),
+			WindowMinutes: automation.Properties.ExportEvidenceLogs.WindowMinutes,
+			MaxEntries:    automation.Properties.ExportEvidenceLogs.MaxEntries,
+			DryRun:        automation.Properties.DryRun,
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+		}
+		return nil
+	case "disable_apis":
+		resource, err := scc.ResourceOf(finding)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			return nil
+		}
+		values := &disableapis.Values{
+			ProjectID:         resource.ProjectID,
+			APIs:              automation.Properties.DisableAPIs.APIs,
+			Protected:         automation.Properties.DisableAPIs.Protected,
+			DisableDependents: automation.Properties.DisableAPIs.DisableDependents,
+			DryRun:            automation.Properties.DryRun,
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+		}
+		return nil
+	case "shutdown_project":
+		resource, err := scc.ResourceOf(finding)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			return nil
+		}
+		values := &shutdownproject.Values{
+			ProjectID: resource.ProjectID,
+			Mode:      automation.Properties.ShutdownProject.Mode,
+			Reason:    automation.Properties.ShutdownProject.Reason,
+			DryRun:    automation.Properties.DryRun,
+		}
+		if values.Reason == "" {
+			values.Reason = fmt.Sprintf("Held for investigation of finding %s", resource.FindingID)
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+		}
+		return nil
+	case "detach_billing":
+		resource, err := scc.ResourceOf(finding)
+		if err != nil {
+			services.Logger.Error("failed to get values for %q: %q", automation.Action, err)
+			return nil
+		}
+		values := &detachbilling.Values{
+			ProjectID: resource.ProjectID,
+			DryRun:    automation.Properties.DryRun,
+		}
+		topic := topicOf(automation.Action)
+		if err := publish(ctx, services, automation, topic, values.ProjectID, values); err != nil {
+			services.Logger.Error("failed to publish: %q", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("action %q not found", automation.Action)
+	}
+}
+
+// SnapshotSettings are how the snapshots of a project are deduplicated, named and labeled, as
+// described by createsnapshot.Settings.
+type SnapshotSettings struct {
+	RecencyMinutes int    `yaml:"recency_minutes"`
+	NameTemplate   string `yaml:"name_template"`
+	Labels         map[string]string
+}
+
+// SnapshotOverride replaces the snapshot settings it sets for the projects within its folders, at
+// any depth, and whose labels match all its selectors.
+type SnapshotOverride struct {
+	FolderIDs        []string `yaml:"folder_ids"`
+	ProjectLabels    []string `yaml:"project_labels"`
+	SnapshotSettings `yaml:",inline"`
+}
+
+// settings returns the snapshot settings.
+func (s SnapshotSettings) settings() createsnapshot.Settings {
+	return createsnapshot.Settings{RecencyMinutes: s.RecencyMinutes, NameTemplate: s.NameTemplate, Labels: s.Labels}
+}
+
+// configureSnapshot sets the destination, outputs and settings of the automation on the values.
+func configureSnapshot(values *createsnapshot.Values, automation Automation) {
+	c := automation.Properties.CreateSnapshot
+	values.Output = c.Output
+	values.DestProjectID = c.TargetSnapshotProjectID
+	values.DestZone = c.TargetSnapshotZone
+	values.Turbinia.ProjectID = c.Turbinia.ProjectID
+	values.Turbinia.Topic = c.Turbinia.Topic
+	values.Turbinia.Zone = c.Turbinia.Zone
+	values.Settings = c.settings()
+	for _, o := range c.Overrides {
+		values.Overrides = append(values.Overrides, createsnapshot.Override{
+			FolderIDs:     o.FolderIDs,
+			ProjectLabels: o.ProjectLabels,
+			Settings:      o.settings(),
+		})
+	}
+}
+
+// matchesAny returns true if the list contains the value, ignoring case. An empty list matches nothing.
+func matchesAny(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// customValues returns the project ID and values for an automation triggered by a custom finding.
+func customValues(f *custom.Finding, automation Automation) (string, interface{}, error) {
+	switch automation.Action {
+	case "close_bucket":
+		values, err := f.CloseBucket()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "enable_bucket_only_policy":
+		values, err := f.EnableBucketOnlyPolicy()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "gce_create_disk_snapshot":
+		values, err := f.CreateSnapshot()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		configureSnapshot(values, automation)
+		return values.ProjectID, values, nil
+	case "remove_public_ip":
+		values, err := f.RemovePublicIP()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	case "remediate_firewall":
+		values, err := f.OpenFirewall()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		values.SourceRanges = automation.Properties.OpenFirewall.SourceRanges
+		values.Action = automation.Properties.OpenFirewall.RemediationAction
+		return values.ProjectID, values, nil
+	case "iam_revoke":
+		values, err := f.IAMRevoke()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		values.AllowDomains = automation.Properties.RevokeIAM.AllowDomains
+		values.ExpireAfterHours = automation.Properties.RevokeIAM.ExpireAfterHours
+		values.From = automation.Properties.RevokeIAM.From
+		return values.ProjectID, values, nil
+	case "close_secret":
+		values, err := f.CloseSecret()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		values.AllowDomains = automation.Properties.CloseSecret.AllowDomains
+		values.Rotate = automation.Properties.CloseSecret.Rotate
+		values.PayloadBytes = automation.Properties.CloseSecret.PayloadBytes
+		if values.PayloadBytes < 0 {
+			return "", nil, fmt.Errorf("invalid payload_bytes %d for %q", values.PayloadBytes, automation.Action)
+		}
+		return values.ProjectID, values, nil
+	case "disable_apis":
+		values, err := f.DisableAPIs()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		values.APIs = automation.Properties.DisableAPIs.APIs
+		values.Protected = automation.Properties.DisableAPIs.Protected
+		values.DisableDependents = automation.Properties.DisableAPIs.DisableDependents
+		return values.ProjectID, values, nil
+	case "shutdown_project":
+		values, err := f.ShutdownProject()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		values.Mode = automation.Properties.ShutdownProject.Mode
+		if r := automation.Properties.ShutdownProject.Reason; r != "" {
+			values.Reason = r
+		}
+		return values.ProjectID, values, nil
+	case "detach_billing":
+		values, err := f.DetachBilling()
+		if err != nil {
+			return "", nil, err
+		}
+		values.DryRun = automation.Properties.DryRun
+		return values.ProjectID, values, nil
+	default:
+		return "", nil, fmt.Errorf("action %q not supported for custom findings", automation.Action)
+	}
+}
+
+func publish(ctx context.Context, services *Services, automation Automation, topic, projectID string, values interface{}) error {
+	ok, err := inScope(ctx, services, automation, projectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check if project %q is within the target or is excluded", projectID)
+	}
+	if !ok {
+		return fmt.Errorf("project %q is not within the target or is excluded", projectID)
+	}
+	return dispatch(ctx, services, automation, topic, map[string]interface{}{"project": projectID}, values)
+}
+
+// inScope reports whether the project is within the automation's target, organization, folder or
+// project IDs, not excluded, and labeled as its selectors require.
+func inScope(ctx context.Context, services *Services, automation Automation, projectID string) (bool, error) {
+	if matchesAny(automation.ExcludeProjectIDs, projectID) {
+		return false, nil
+	}
+	target := automation.Target
+	if automation.OrganizationID != "" {
+		target = append([]string{"organizations/" + automation.OrganizationID + "/*"}, target...)
+	}
+	in, err := inFolders(ctx, services, automation.FolderIDs, projectID)
+	if err != nil {
+		return false, err
+	}
+	if in || matchesAny(automation.ProjectIDs, projectID) {
+		target = []string{"*"}
+	}
+	if len(target) == 0 {
+		return false, nil
+	}
+	ok, err := services.Resource.CheckMatches(ctx, projectID, target, automation.Exclude)
+	if err != nil || !ok {
+		return false, err
+	}
+	return services.Resource.MatchesLabels(ctx, projectID, automation.Labels)
+}
+
+// inFolders reports whether the project is in one of the folders, directly or through any of
+// their sub-folders, so moving folders around within them keeps their projects in scope.
+func inFolders(ctx context.Context, services *Services, folderIDs []string, projectID string) (bool, error) {
+	if len(folderIDs) == 0 {
+		return false, nil
+	}
+	folders, err := services.Resource.Folders(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range folders {
+		if matchesAny(folderIDs, f) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// publishAWS publishes the values if the AWS account is targeted and not excluded.
+//
+// Targets and exclusions of AWS automations list account IDs, "*" matches every account.
+func publishAWS(ctx context.Context, services *Services, automation Automation, topic, accountID string, values interface{}) error {
+	if !matchesAccount(accountID, automation.Target) || matchesAccount(accountID, automation.Exclude) {
+		return fmt.Errorf("account %q is not within the target or is excluded", accountID)
+	}
+	return dispatch(ctx, services, automation, topic, map[string]interface{}{"account": accountID}, values)
+}
+
+// publishUser publishes the values of an automation acting on a Google Workspace or Cloud Identity
+// user, who is targeted by "*", their domain or their email address.
+func publishUser(ctx context.Context, services *Services, automation Automation, topic, email string, values interface{}) error {
+	if !matchesUser(email, automation.Target) || matchesUser(email, automation.Exclude) {
+		return fmt.Errorf("user %q is not within the target or is excluded", email)
+	}
+	return dispatch(ctx, services, automation, topic, map[string]interface{}{"user": email}, values)
+}
+
+func matchesUser(email string, targets []string) bool {
+	domain := email[strings.LastIndex(email, "@")+1:]
+	for _, t := range targets {
+		if t == "*" || strings.EqualFold(t, email) || strings.EqualFold(t, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAccount(accountID string, accounts []string) bool {
+	for _, a := range accounts {
+		if a == "*" || a == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch sends the values to the automation if its condition is met and the policies allow it.
+func dispatch(ctx context.Context, services *Services, automation Automation, topic string, resource map[string]interface{}, values interface{}) error {
+	if !enabled(services.Configuration, automation.Action) {
+		log.Printf("%q is not enabled, skipping", automation.Action)
+		return nil
+	}
+	ok, err := severe(ctx, automation)
+	if err != nil || !ok {
+		return err
+	}
+	if automation.Playbook == "" {
+		ok, err = conditionMet(ctx, automation, resource)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	if err := canary(ctx, automation, resource, values); err != nil {
+		return err
+	}
+	decision, err := decide(ctx, services, automation, resource, values)
+	if err != nil {
+		return err
+	}
+	if decision == policy.Allow && approvalRequired(automation) {
+		decision = policy.ApprovalRequired
+	}
+	switch decision {
+	case policy.Deny:
+		return fmt.Errorf("policy denied %q", automation.Action)
+	case policy.ApprovalRequired:
+		return requestApproval(ctx, services, automation.Action, topic, values)
+	}
+	if automation.Playbook != "" {
+		return addStep(ctx, automation, topic, resource, values)
+	}
+	if automation.Window.IsZero() {
+		return send(ctx, services, automation.Action, topic, values)
+	}
+	open, next, err := automation.Window.Next(now())
+	if err != nil {
+		return errors.Wrapf(err, "invalid window for %q", automation.Action)
+	}
+	if open {
+		return send(ctx, services, automation.Action, topic, values)
+	}
+	return deferUntil(ctx, services, automation.Action, topic, values, next)
+}
+
+// enabled returns true if the action is registered and enabled by the configuration.
+func enabled(conf *Configuration, action string) bool {
+	if _, ok := registry.Lookup(action); !ok {
+		return false
+	}
+	if conf == nil || len(conf.Spec.Enabled) == 0 {
+		return true
+	}
+	return matchesAny(conf.Spec.Enabled, action)
+}
+
+// EnabledActions returns the names of the registered actions the configuration enables.
+func (c *Configuration) EnabledActions() []string {
+	var names []string
+	for _, a := range registry.Actions() {
+		if enabled(c, a.Name) {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+// deferUntil schedules the values to be published to the topic when the automation's window opens.
+func deferUntil(ctx context.Context, services *Services, action, topic string, values interface{}, at time.Time) error {
+	if services.Tasks == nil {
+		return fmt.Errorf("%q is outside of its window and no task queue is configured to defer it", action)
+	}
+	b, err := json.Marshal(&values)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal when running %q", action)
+	}
+	if err := services.Tasks.PublishAt(ctx, topic, b, routedAttributes(ctx), at); err != nil {
+		return err
+	}
+	log.Printf("%q is outside of its window, deferred to %s", action, at.Format(time.RFC3339))
+	return nil
+}
+
+// canary switches the values to a dry run if the resource is outside of the automation's canary.
+//
+// Resources are assigned to the canary by the hash of their project or account ID so the same
+// resources stay live as the percentage is raised. Findings naming none are assigned by the hash of
+// the finding's name instead, so they do not all fall on the same side.
+func canary(ctx context.Context, automation Automation, resource map[string]interface{}, values interface{}) error {
+	percent := automation.Properties.CanaryPercent
+	if percent == 0 || percent == 100 {
+		return nil
+	}
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("invalid canary_percent %d for %q", percent, automation.Action)
+	}
+	var key string
+	for _, k := range []string{"project", "account", "user"} {
+		if id, ok := resource[k].(string); ok {
+			key += id
+		}
+	}
+	if key == "" {
+		key = routedName(ctx)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if int(h.Sum32()%100) < percent {
+		return nil
+	}
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("values of %q do not support dry runs", automation.Action)
+	}
+	dryRun := v.Elem().FieldByName("DryRun")
+	if !dryRun.IsValid() || dryRun.Kind() != reflect.Bool || !dryRun.CanSet() {
+		return fmt.Errorf("values of %q do not support dry runs", automation.Action)
+	}
+	log.Printf("%v is outside of the %d%% canary of %q, running as a dry run", resource, percent, automation.Action)
+	dryRun.SetBool(true)
+	return nil
+}
+
+// routingKey is the context key of the finding being routed.
+type routingKey struct{}
+
+// routing holds the finding being routed, its detection and the playbooks it runs.
+type routing struct {
+	finding       []byte
+	correlationID string
+	simulated     bool
+	detection     *Detection
+	playbooks     []*playbook.Values
+	// deadLettered is whether the finding was published to the dead letter topic.
+	deadLettered bool
+}
+
+// addStep appends the automation to its playbook for the finding being routed.
+func addStep(ctx context.Context, automation Automation, topic string, resource map[string]interface{}, values interface{}) error {
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	if !ok {
+		return fmt.Errorf("%q is part of playbook %q but no finding is being routed", automation.Action, automation.Playbook)
+	}
+	switch automation.Otherwise {
+	case "", playbook.Skip, playbook.Approval:
+	default:
+		return fmt.Errorf("invalid otherwise %q for %q", automation.Otherwise, automation.Action)
+	}
+	b, err := json.Marshal(&values)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal when running %q", automation.Action)
+	}
+	var p *playbook.Values
+	for _, v := range r.playbooks {
+		if v.Name == automation.Playbook {
+			p = v
+		}
+	}
+	if p == nil {
+		p = &playbook.Values{Name: automation.Playbook}
+		r.playbooks = append(r.playbooks, p)
+	}
+	p.Steps = append(p.Steps, &playbook.Step{
+		Action:    automation.Action,
+		Topic:     topic,
+		Values:    b,
+		Resource:  resource,
+		Condition: automation.Condition,
+		Otherwise: automation.Otherwise,
+		Status:    playbook.Pending,
+	})
+	log.Printf("%q added to playbook %q", automation.Action, automation.Playbook)
+	return nil
+}
+
+// publishPlaybooks sends the playbooks collected while routing the finding to the playbook function.
+func publishPlaybooks(ctx context.Context, services *Services, r *routing) error {
+	if len(r.playbooks) == 0 {
+		return nil
+	}
+	finding, err := routedFinding(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range r.playbooks {
+		p.Finding = routedName(ctx)
+		p.CorrelationID = r.correlationID
+		p.Details = finding
+		if err := send(ctx, services, "playbook "+p.Name, playbookTopic, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routedName returns the name of the finding being routed, or its detection if it has none.
+func routedName(ctx context.Context) string {
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	if !ok {
+		return ""
+	}
+	if finding, err := routedFinding(ctx); err == nil {
+		if name, ok := finding["name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return r.detection.String()
+}
+
+// routedAttributes returns the attributes of the messages sent for the finding being routed: its
+// name, correlation ID, category and, if the finding tells, when it was created so remediations
+// are timed from it.
+func routedAttributes(ctx context.Context) map[string]string {
+	attributes := map[string]string{"finding": routedName(ctx)}
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	if !ok {
+		return attributes
+	}
+	attributes[correlationAttribute] = r.correlationID
+	attributes["category"] = r.detection.Category
+	finding, err := routedFinding(ctx)
+	if err != nil {
+		return attributes
+	}
+	// SCC findings tell when they were created, Cloud Logging entries when they were logged.
+	for _, k := range []string{"createTime", "eventTime", "timestamp"} {
+		if t, ok := finding[k].(string); ok && t != "" {
+			attributes["created"] = t
+			break
+		}
+	}
+	return attributes
+}
+
+// routedTime returns when the event reported by the finding being routed occurred, or when the
+// finding was created or logged if it does not tell, now if it tells neither.
+func routedTime(ctx context.Context) time.Time {
+	finding, err := routedFinding(ctx)
+	if err != nil {
+		return time.Now()
+	}
+	for _, k := range []string{"eventTime", "createTime", "timestamp"} {
+		if s, ok := finding[k].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Now()
+}
+
+// routedSeverity returns the severity of the finding being routed.
+func routedSeverity(ctx context.Context) severity.Level {
+	r, ok := ctx.Value(routingKey{}).(*routing)
+	if !ok {
+		return severity.Unknown
+	}
+	return r.detection.Severity
+}
+
+// severe returns true if the finding being routed is at least as severe as the automation requires.
+func severe(ctx context.Context, automation Automation) (bool, error) {
+	if automation.MinSeverity == "" {
+		return true, nil
+	}
+	min := severity.Parse(automation.MinSeverity)
+	if min == severity.Unknown {
+		return false, fmt.Errorf("invalid min_severity %q for %q", automation.MinSeverity, automation.Action)
+	}
+	if l := routedSeverity(ctx); !l.AtLeast(min) {
+		log.Printf("severity %s is below %s, skipping %q", l, min, automation.Action)
+		return false, nil
+	}
+	return true, nil
+}
+
+// routedFinding returns the decoded finding being routed.
+//
+// The finding of SCC notifications is the notification's finding.
+func routedFinding(ctx context.Context) (map[string]interface{}, error) {
+	var finding map[string]interface{}
+	var b []byte
+	if r, ok := ctx.Value(routingKey{}).(*routing); ok {
+		b = r.finding
+	}
+	if err := json.Unmarshal(b, &finding); err != nil {
+		return nil, errors.Wrap(err, "failed to decode finding")
+	}
+	if f, ok := finding["finding"].(map[string]interface{}); ok {
+		finding = f
+	}
+	return finding, nil
+}
+
+// conditionMet evaluates the automation's condition, automations without one always run.
+//
+// Conditions can reference `finding`, the finding being routed, and `resource`, the project or
+// account the automation acts on.
+func conditionMet(ctx context.Context, automation Automation, resource map[string]interface{}) (bool, error) {
+	if automation.Condition == "" {
+		return true, nil
+	}
+	program, err := cel.Compile(automation.Condition)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid condition for %q", automation.Action)
+	}
+	finding, err := routedFinding(ctx)
+	if err != nil {
+		return false, err
+	}
+	ok, err := program.Eval(map[string]interface{}{"finding": finding, "resource": resource})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to evaluate condition for %q", automation.Action)
+	}
+	if !ok {
+		log.Printf("condition of %q not met, skipping", automation.Action)
+	}
+	return ok, nil
+}
+
+// approvalRequired reports whether the automation is held for approval whatever the policies
+// decide, as configured or by default for its action.
+func approvalRequired(automation Automation) bool {
+	if automation.Approval != nil {
+		return *automation.Approval
+	}
+	a, _ := registry.Lookup(automation.Action)
+	return a.Approval
+}
+
+// decide evaluates the automation against the policies.
+func decide(ctx context.Context, services *Services, automation Automation, resource map[string]interface{}, values interface{}) (policy.Decision, error) {
+	if services.Policy == nil {
+		return policy.Allow, nil
+	}
+	finding, err := routedFinding(ctx)
+	if err != nil {
+		return "", err
+	}
+	// Round trip the values so policies see the same fields the automation receives.
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return "", err
+	}
+	decision, err := services.Policy.Decide(ctx, map[string]interface{}{
+		"finding":  finding,
+		"action":   automation.Action,
+		"severity": routedSeverity(ctx).String(),
+		"resource": resource,
+		"values":   v,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decide on %q", automation.Action)
+	}
+	log.Printf("policy decision for %q: %s", automation.Action, decision)
+	return decision, nil
+}
+
+// requestApproval holds the automation by publishing its values to the approval topic.
+//
+// Approving an automation is done by publishing the message's data to the topic named in its
+// `topic` attribute.
+func requestApproval(ctx context.Context, services *Services, action, topic string, values interface{}) error {
+	b, err := json.Marshal(&values)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal when running %q", action)
+	}
+	attributes := routedAttributes(ctx)
+	attributes["action"], attributes["topic"] = action, topic
+	if _, err := services.PubSub.Publish(ctx, approvalTopic, &pubsub.Message{
+		Data:       b,
+		Attributes: attributes,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to publish to %q", approvalTopic)
+	}
+	log.Printf("%q requires approval, sent to pubsub topic: %q", action, approvalTopic)
+	return nil
+}
+
+func send(ctx context.Context, services *Services, action, topic string, values interface{}) error {
 	b, err := json.Marshal(&values)
 	if err != nil {
 		return errors.Wrapf(err, "failed to marshal when running %q", action)
 	}
 	if _, err := services.PubSub.Publish(ctx, topic, &pubsub.Message{
-		Data: b,
+		Data:       b,
+		Attributes: routedAttributes(ctx),
 	}); err != nil {
 		services.Logger.Error("failed to publish to %q for action %q", topic, action)
 		return err
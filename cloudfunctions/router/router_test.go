@@ -18,23 +18,48 @@ import (
 	"context"
 	"encoding/json"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/isolateinstance"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/bigquery/closepublicdataset"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/billing/detachbilling"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/binauthz/quarantineimage"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/requireoslogin"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/restrictexternalips"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/preventpublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/isolatepod"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/removenonorgmembers"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/removepublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/rotatekey"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/playbook"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/resourcemanager/shutdownproject"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router/internal/window"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router/policy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/secretmanager/closesecret"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/serviceusage/disableapis"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/exportlogs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/labelresource"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	sccv1pb "google.golang.org/genproto/googleapis/cloud/securitycenter/v1"
 	sccpb "google.golang.org/genproto/googleapis/cloud/securitycenter/v1beta1"
+	"gopkg.in/yaml.v2"
 )
 
 // testData reads a file from the testdata directory and returns its bytes. If an error is
@@ -49,6 +74,19 @@ func testData(t *testing.T, filename string) []byte {
 	return b
 }
 
+// binaryData reads a JSON notification from the testdata directory and returns it binary encoded.
+func binaryData(t *testing.T, filename string) []byte {
+	var nm sccv1pb.NotificationMessage
+	if err := protojson.Unmarshal(testData(t, filename), &nm); err != nil {
+		t.Fatalf("Could not unmarshal %s: %v", filename, err)
+	}
+	b, err := proto.Marshal(&nm)
+	if err != nil {
+		t.Fatalf("Could not marshal %s: %v", filename, err)
+	}
+	return b
+}
+
 func TestRouter(t *testing.T) {
 	conf := &Configuration{}
 	// BadIP findings should map to "gce_create_disk_snapshot".
@@ -69,6 +107,7 @@ func TestRouter(t *testing.T) {
 		Instance:  "bad-ip-caller",
 		Zone:      "us-central1-a",
 		DryRun:    false,
+		FindingID: "6a30ce604c11417995b1fa260753f3b5",
 	}
 	sccCreateSnapshot, _ := json.Marshal(sccCreateSnapshotValues)
 
@@ -82,6 +121,83 @@ func TestRouter(t *testing.T) {
 	}
 	closeBucket, _ := json.Marshal(closeBucketValues)
 
+	isolatePodAutomation := Automation{Action: "gke_isolate_pod", Target: []string{"organizations/456/folders/123/projects/test-project"}}
+	isolatePodAutomation.Properties.Falco.Rules = []string{"terminal shell in container"}
+	cordonNodeAutomation := Automation{Action: "gke_cordon_node", Target: []string{"organizations/456/folders/123/projects/test-project"}}
+	cordonNodeAutomation.Properties.Falco.Priorities = []string{"critical"}
+	conf.Spec.Parameters.Falco.RuntimeAlert = []Automation{isolatePodAutomation, cordonNodeAutomation}
+	isolatePodValues := &isolatepod.Values{
+		ProjectID: "test-project",
+		Location:  "us-central1-a",
+		ClusterID: "test-cluster",
+		Namespace: "default",
+		Pod:       "nginx",
+	}
+	isolatePod, _ := json.Marshal(isolatePodValues)
+
+	conf.Spec.Parameters.Forseti.BucketViolation = []Automation{
+		{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+	}
+
+	conf.Spec.Parameters.Forseti.FirewallViolation = []Automation{
+		{Action: "remediate_firewall", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+	}
+	conf.Spec.Parameters.Forseti.FirewallViolation[0].Properties.OpenFirewall.RemediationAction = "disable"
+	openFirewallValues := &openfirewall.Values{
+		Action:     "disable",
+		ProjectID:  "test-project",
+		FirewallID: "default-allow-ssh",
+	}
+	openFirewall, _ := json.Marshal(openFirewallValues)
+
+	conf.Spec.Parameters.CloudIDS.Threat = []Automation{
+		{Action: "gce_quarantine_instance", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+	}
+	quarantineInstanceValues := &quarantineinstance.Values{
+		ProjectID: "test-project",
+		IPAddress: "10.128.0.2",
+	}
+	quarantineInstance, _ := json.Marshal(quarantineInstanceValues)
+
+	conf.Spec.Parameters.Chronicle.Rules = map[string][]Automation{
+		"ru_e6abfcb5-1b85-41b0-b64c-695b3250436f": {
+			{Action: "gce_block_ip", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+		},
+	}
+	blockIPValues := &blockip.Values{
+		ProjectID:   "test-project",
+		IPAddresses: []string{"198.51.100.7"},
+	}
+	blockIP, _ := json.Marshal(blockIPValues)
+
+	quarantineImageAutomation := Automation{Action: "quarantine_image", Target: []string{"organizations/456/folders/123/projects/test-project"}}
+	quarantineImageAutomation.Properties.QuarantineImage.AttestorNote = "projects/test-project/notes/vulnerability-free"
+	conf.Spec.Parameters.ContainerAnalysis.Vulnerability = []Automation{quarantineImageAutomation}
+	quarantineImageValues := &quarantineimage.Values{
+		ProjectID:    "test-project",
+		ImageURI:     "https://gcr.io/test-project/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31",
+		AttestorNote: "projects/test-project/notes/vulnerability-free",
+	}
+	quarantineImage, _ := json.Marshal(quarantineImageValues)
+
+	conf.Spec.Parameters.GuardDuty.Instance = []Automation{
+		{Action: "aws_isolate_instance", Target: []string{"123456789012"}},
+	}
+	isolateInstanceValues := &isolateinstance.Values{
+		AccountID:  "123456789012",
+		Region:     "us-east-1",
+		VpcID:      "vpc-6d1f5b05",
+		InstanceID: "i-0123456789abcdef0",
+	}
+	isolateInstance, _ := json.Marshal(isolateInstanceValues)
+
+	conf.Spec.Parameters.Custom = map[string][]Automation{
+		"public_bucket": {
+			{Action: "enable_bucket_only_policy", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+			{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+		},
+	}
+
 	crmStub := &stubs.ResourceManagerStub{}
 	storageStub := &stubs.StorageStub{}
 	ancestryResponse := services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
@@ -99,11 +215,12 @@ func TestRouter(t *testing.T) {
 	}
 	closePublicDataset, _ := json.Marshal(closePublicDatasetValues)
 
-	conf.Spec.Parameters.SHA.AuditLoggingDisabled = []Automation{
-		{Action: "enable_audit_logs", Target: []string{"organizations/456/folders/123/projects/test-project"}},
-	}
+	auditLogs := Automation{Action: "enable_audit_logs", Target: []string{"organizations/456/folders/123/projects/test-project"}}
+	auditLogs.Properties.EnableAuditLogs.Services = []string{"storage.googleapis.com"}
+	conf.Spec.Parameters.SHA.AuditLoggingDisabled = []Automation{auditLogs}
 	enableAuditLogsValues := &enableauditlogs.Values{
 		ProjectID: "test-project",
+		Services:  []string{"storage.googleapis.com"},
 		DryRun:    false,
 	}
 	enableAuditLog, _ := json.Marshal(enableAuditLogsValues)
@@ -139,6 +256,54 @@ func TestRouter(t *testing.T) {
 			finding: testData(t, "bad_ip_scc.json"),
 			mapTo:   sccCreateSnapshot,
 		},
+		{
+			name:    "chronicle_detection",
+			finding: testData(t, "chronicle_detection.json"),
+			nonSCC:  true,
+			mapTo:   blockIP,
+		},
+		{
+			name:    "container_vulnerability",
+			finding: testData(t, "container_vulnerability.json"),
+			nonSCC:  true,
+			mapTo:   quarantineImage,
+		},
+		{
+			name:    "custom",
+			finding: testData(t, "custom.json"),
+			nonSCC:  true,
+			mapTo:   closeBucket,
+		},
+		{
+			name:    "falco_alert",
+			finding: testData(t, "falco_alert.json"),
+			nonSCC:  true,
+			mapTo:   isolatePod,
+		},
+		{
+			name:    "forseti_bucket_violation",
+			finding: testData(t, "forseti_bucket_violation.json"),
+			nonSCC:  true,
+			mapTo:   closeBucket,
+		},
+		{
+			name:    "forseti_firewall_violation",
+			finding: testData(t, "forseti_firewall_violation.json"),
+			nonSCC:  true,
+			mapTo:   openFirewall,
+		},
+		{
+			name:    "guardduty_instance",
+			finding: testData(t, "guardduty_instance.json"),
+			nonSCC:  true,
+			mapTo:   isolateInstance,
+		},
+		{
+			name:    "ids_threat",
+			finding: testData(t, "ids_threat.json"),
+			nonSCC:  true,
+			mapTo:   quarantineInstance,
+		},
 		{
 			name:    "non_org_members",
 			finding: testData(t, "non_org_iam_member.json"),
@@ -149,6 +314,12 @@ func TestRouter(t *testing.T) {
 			finding: testData(t, "public_bucket_acl.json"),
 			mapTo:   closeBucket,
 		},
+		{
+			name:    "public_bucket_acl_binary",
+			finding: binaryData(t, "public_bucket_acl.json"),
+			nonSCC:  true,
+			mapTo:   closeBucket,
+		},
 		{
 			name:    "public_dataset",
 			finding: testData(t, "public_dataset.json"),
@@ -192,10 +363,11 @@ func TestRouter(t *testing.T) {
 						Name: f.GetName() + "/securityMarks",
 						Marks: map[string]string{
 							originalEventTime: f.GetEventTime().AsTime().UTC().Format(time.RFC3339Nano),
+							correlationMark:   correlationID(&Values{Finding: tt.finding}),
 						},
 					},
 					UpdateMask: &fieldmaskpb.FieldMask{
-						Paths: []string{"marks." + originalEventTime},
+						Paths: []string{"marks." + correlationMark, "marks." + originalEventTime},
 					},
 				}
 				if diff := cmp.Diff(want, sccStub.GetUpdateSecurityMarksRequest, protocmp.Transform()); diff != "" {
@@ -206,6 +378,1162 @@ func TestRouter(t *testing.T) {
 	}
 }
 
+func TestDetect(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		finding  []byte
+		expected *Detection
+	}{
+		{
+			name:     "scc notification",
+			finding:  testData(t, "public_bucket_acl.json"),
+			expected: &Detection{Source: "sha", Category: "public_bucket_acl", Version: "scc/v1"},
+		},
+		{
+			name:     "log entry",
+			finding:  testData(t, "ids_threat.json"),
+			expected: &Detection{Source: "cloud_ids", Category: "ids_threat", Version: "logging", Severity: severity.Critical},
+		},
+		{
+			name:     "falco alert",
+			finding:  testData(t, "falco_alert.json"),
+			expected: &Detection{Source: "falco", Category: "falco_alert", Severity: severity.Low},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := detect(tt.finding)
+			if err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expected, d); diff != "" {
+				t.Errorf("%q failed, difference:%+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestUnsupportedFinding(t *testing.T) {
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	finding := []byte(`{"finding": {"category": "UNKNOWN_CATEGORY"}}`)
+	err := Execute(ctx, &Values{Finding: finding}, &Services{
+		PubSub:        services.NewPubSub(psStub),
+		Logger:        services.NewLogger(&stubs.LoggerStub{}),
+		Configuration: &Configuration{},
+	})
+	if err == nil {
+		t.Fatalf("unsupported finding should fail")
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("unsupported finding was not dead lettered")
+	}
+	if diff := cmp.Diff(finding, psStub.PublishedMessage.Data); diff != "" {
+		t.Errorf("dead lettered finding difference:%+v", diff)
+	}
+	if psStub.PublishedMessage.Attributes["error"] == "" {
+		t.Errorf("dead lettered finding is missing the error attribute")
+	}
+}
+
+func TestBatched(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{name: "array", message: `[{"finding": {"name": "a"}}, {"finding": {"name": "b"}}]`, want: []string{`{"finding": {"name": "a"}}`, `{"finding": {"name": "b"}}`}},
+		{name: "envelope", message: `{"findings": [{"finding": {"name": "a"}}]}`, want: []string{`{"finding": {"name": "a"}}`}},
+		{name: "empty", message: `[]`, want: []string{}},
+		{name: "single", message: `{"finding": {"name": "a"}}`},
+		{name: "finding with findings field", message: `{"finding": {"name": "a"}, "findings": [{}]}`},
+		{name: "findings not an array", message: `{"findings": {}}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, ok := batched([]byte(tt.message))
+			if ok != (tt.want != nil) {
+				t.Fatalf("got batched %t, want %t", ok, tt.want != nil)
+			}
+			var got []string
+			if ok {
+				got = []string{}
+			}
+			for _, f := range findings {
+				got = append(got, string(f))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("findings (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	batch := []byte(`{"findings": [{"finding": {"category": "UNKNOWN_CATEGORY"}}, {"finding": {"category": "OTHER_CATEGORY"}}]}`)
+	err := Execute(ctx, &Values{Finding: batch, CorrelationID: "batch"}, &Services{
+		PubSub:        services.NewPubSub(psStub),
+		Logger:        services.NewLogger(&stubs.LoggerStub{}),
+		Configuration: &Configuration{},
+	})
+	if err != nil {
+		t.Fatalf("batch of dead lettered findings failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("findings were not dead lettered")
+	}
+	if diff := cmp.Diff(`{"finding": {"category": "OTHER_CATEGORY"}}`, string(psStub.PublishedMessage.Data)); diff != "" {
+		t.Errorf("dead lettered finding (-want +got):\n%s", diff)
+	}
+	if got := psStub.PublishedMessage.Attributes[correlationAttribute]; got != "batch-1" {
+		t.Errorf("got correlation ID %q, want batch-1", got)
+	}
+}
+
+func TestStrictSchema(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name       string
+		finding    []byte
+		attributes map[string]string
+	}{
+		{
+			name:    "missing field",
+			finding: []byte(`{"notificationConfigName": "organizations/1/notificationConfigs/c", "finding": {"name": "organizations/1/sources/2/findings/3", "resourceName": "//storage.googleapis.com/bucket", "category": "PUBLIC_BUCKET_ACL", "sourceProperties": {"ScannerName": "STORAGE_SCANNER"}}}`),
+			attributes: map[string]string{
+				"reason":    "schema",
+				"detection": "sha/public_bucket_acl@scc/v1",
+				"missing":   "finding.sourceProperties.ProjectId",
+			},
+		},
+		{
+			name:    "unknown field",
+			finding: []byte(`{"notificationConfigName": "organizations/1/notificationConfigs/c", "findings": {}, "finding": {"name": "organizations/1/sources/2/findings/3", "resourceName": "//storage.googleapis.com/bucket", "category": "PUBLIC_BUCKET_ACL", "sourceProperties": {"ScannerName": "STORAGE_SCANNER", "ProjectId": "p"}}}`),
+			attributes: map[string]string{
+				"reason":    "schema",
+				"detection": "sha/public_bucket_acl@scc/v1",
+				"unknown":   "findings",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			psStub := &stubs.PubSubStub{}
+			conf := &Configuration{}
+			conf.Spec.StrictSchema = true
+			err := Execute(ctx, &Values{Finding: tt.finding}, &Services{
+				PubSub:        services.NewPubSub(psStub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+				Configuration: conf,
+			})
+			if _, ok := err.(*SchemaError); !ok {
+				t.Fatalf("got error %v, want a schema error", err)
+			}
+			if psStub.PublishedMessage == nil {
+				t.Fatalf("finding was not dead lettered")
+			}
+			attributes := psStub.PublishedMessage.Attributes
+			for k, v := range tt.attributes {
+				if attributes[k] != v {
+					t.Errorf("got attribute %q %q, want %q", k, attributes[k], v)
+				}
+			}
+			if attributes["error"] == "" {
+				t.Errorf("dead lettered finding is missing the error attribute")
+			}
+		})
+	}
+}
+
+func TestCondition(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		condition string
+		published bool
+	}{
+		{name: "no condition", published: true},
+		{name: "condition met", condition: `finding.category == "PUBLIC_BUCKET_ACL" && resource.project.startsWith("test-")`, published: true},
+		{name: "condition not met", condition: `resource.project.startsWith("prod-")`, published: false},
+		{name: "invalid condition", condition: `finding.category ==`, published: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			conf := &Configuration{}
+			conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{
+				{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}, Condition: tt.condition},
+			}
+			if err := Execute(ctx, &Values{Finding: testData(t, "public_bucket_acl.json")}, &Services{
+				PubSub:                services.NewPubSub(psStub),
+				Logger:                services.NewLogger(&stubs.LoggerStub{}),
+				Configuration:         conf,
+				Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+				SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Errorf("%q failed, published:%v want:%v", tt.name, got, tt.published)
+			}
+		})
+	}
+}
+
+func TestScope(t *testing.T) {
+	const target = "organizations/456/folders/789/folders/123/projects/test-project"
+	for _, tt := range []struct {
+		name       string
+		automation Automation
+		published  bool
+	}{
+		{name: "targeted", automation: Automation{Target: []string{target}}, published: true},
+		{name: "excluded", automation: Automation{Target: []string{target}, Exclude: []string{"organizations/456/folders/789/*"}}, published: false},
+		{name: "labels match", automation: Automation{Target: []string{target}, Labels: []string{"sra-enforce=true"}}, published: true},
+		{name: "labels do not match", automation: Automation{Target: []string{target}, Labels: []string{"sra-enforce=true", "!legacy"}}, published: false},
+		{name: "project listed", automation: Automation{ProjectIDs: []string{"other-project", "test-project"}}, published: true},
+		{name: "project not listed", automation: Automation{ProjectIDs: []string{"other-project"}}, published: false},
+		{name: "project listed but excluded", automation: Automation{ProjectIDs: []string{"test-project"}, Exclude: []string{"organizations/456/*"}}, published: false},
+		{name: "project excluded", automation: Automation{Target: []string{target}, ExcludeProjectIDs: []string{"test-project"}}, published: false},
+		{name: "organization", automation: Automation{OrganizationID: "456"}, published: true},
+		{name: "other organization", automation: Automation{OrganizationID: "4567"}, published: false},
+		{name: "organization with exclusions", automation: Automation{OrganizationID: "456", Exclude: []string{"organizations/456/folders/789/folders/123/*"}}, published: false},
+		{name: "parent folder", automation: Automation{FolderIDs: []string{"789"}}, published: true},
+		{name: "nested folder", automation: Automation{FolderIDs: []string{"123"}}, published: true},
+		{name: "other folder", automation: Automation{FolderIDs: []string{"12"}}, published: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{
+				StubbedProjects: map[string]*crm.Project{
+					"test-project": {ProjectId: "test-project", Labels: map[string]string{"sra-enforce": "true", "legacy": ""}},
+				},
+			}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "folder/789", "organization/456"})
+			conf := &Configuration{}
+			tt.automation.Action = "close_bucket"
+			conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{tt.automation}
+			if err := Execute(ctx, &Values{Finding: testData(t, "public_bucket_acl.json")}, &Services{
+				PubSub:                services.NewPubSub(psStub),
+				Logger:                services.NewLogger(&stubs.LoggerStub{}),
+				Configuration:         conf,
+				Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+				SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Errorf("%q failed, published:%v want:%v", tt.name, got, tt.published)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	engine, err := policy.New(map[string][]byte{"gate.rego": []byte(`package sra.policy
+
+decision["deny"] {
+	input.resource.project == "test-project"
+	input.values.BucketName == "this-is-public-on-purpose"
+	input.finding.category == "PUBLIC_BUCKET_ACL"
+}
+`)})
+	if err != nil {
+		t.Fatalf("failed to compile policy: %q", err)
+	}
+	approval, err := policy.New(map[string][]byte{"gate.rego": []byte(`package sra.policy
+
+decision["approval_required"] {
+	input.action == "close_bucket"
+}
+`)})
+	if err != nil {
+		t.Fatalf("failed to compile policy: %q", err)
+	}
+	const finding = "organizations/154584661726/sources/2673592633662526977/findings/782e52631d61da6117a3772137c270d8"
+	id := correlationID(&Values{Finding: testData(t, "public_bucket_acl.json")})
+	for _, tt := range []struct {
+		name       string
+		engine     *policy.Engine
+		published  bool
+		attributes map[string]string
+	}{
+		{name: "no policies", published: true, attributes: map[string]string{"finding": finding, correlationAttribute: id, "category": "public_bucket_acl", "created": "2019-09-23T17:20:27.934Z"}},
+		{name: "deny", engine: engine, published: false},
+		{name: "approval required", engine: approval, published: true, attributes: map[string]string{"action": "close_bucket", "topic": "threat-findings-close-bucket", "finding": finding, correlationAttribute: id, "category": "public_bucket_acl", "created": "2019-09-23T17:20:27.934Z"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			conf := &Configuration{}
+			conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{
+				{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+			}
+			if err := Execute(ctx, &Values{Finding: testData(t, "public_bucket_acl.json")}, &Services{
+				PubSub:                services.NewPubSub(psStub),
+				Logger:                services.NewLogger(&stubs.LoggerStub{}),
+				Configuration:         conf,
+				Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+				SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+				Policy:                tt.engine,
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Fatalf("%q failed, published:%v want:%v", tt.name, got, tt.published)
+			}
+			if !tt.published {
+				return
+			}
+			if diff := cmp.Diff(tt.attributes, psStub.PublishedMessage.Attributes); diff != "" {
+				t.Errorf("%q failed, attributes difference:%+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestMinSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		minSeverity string
+		published   bool
+	}{
+		{name: "no minimum", published: true},
+		{name: "below minimum", minSeverity: "CRITICAL", published: false},
+		{name: "at minimum", minSeverity: "high", published: true},
+		{name: "invalid minimum", minSeverity: "urgent", published: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			conf := &Configuration{}
+			conf.Spec.Parameters.Custom = map[string][]Automation{
+				"public_bucket": {{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}, MinSeverity: tt.minSeverity}},
+			}
+			if err := Execute(ctx, &Values{Finding: testData(t, "custom.json")}, &Services{
+				PubSub:        services.NewPubSub(psStub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+				Configuration: conf,
+				Resource:      services.NewResource(crmStub, &stubs.StorageStub{}),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Errorf("%q failed, published:%v want:%v", tt.name, got, tt.published)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	defer func(n func() time.Time) { now = n }(now)
+	// Monday 1 June 2020, 12:00 UTC.
+	now = func() time.Time { return time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC) }
+	for _, tt := range []struct {
+		name      string
+		window    window.Window
+		published bool
+		deferred  string
+	}{
+		{name: "no window", published: true},
+		{name: "open", window: window.Window{Start: "09:00", End: "17:00"}, published: true},
+		{name: "closed", window: window.Window{Start: "18:00", End: "08:00"}, deferred: "2020-06-01T18:00:00Z"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			tasksStub := &stubs.CloudTasksStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			conf := &Configuration{}
+			conf.Spec.Parameters.Custom = map[string][]Automation{
+				"public_bucket": {{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}, Window: tt.window}},
+			}
+			if err := Execute(ctx, &Values{Finding: testData(t, "custom.json")}, &Services{
+				PubSub:        services.NewPubSub(psStub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+				Configuration: conf,
+				Resource:      services.NewResource(crmStub, &stubs.StorageStub{}),
+				Tasks:         services.NewTasks(tasksStub, "projects/p/locations/l/queues/q", "automation-project", "sa@example.com"),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Errorf("%q failed, published:%v want:%v", tt.name, got, tt.published)
+			}
+			var deferred string
+			if len(tasksStub.CreatedTasks) > 0 {
+				deferred = tasksStub.CreatedTasks[0].ScheduleTime
+			}
+			if deferred != tt.deferred {
+				t.Errorf("%q failed, deferred to:%q want:%q", tt.name, deferred, tt.deferred)
+			}
+		})
+	}
+}
+
+func TestCanary(t *testing.T) {
+	// "test-project" hashes to 41.
+	for _, tt := range []struct {
+		name    string
+		percent int
+		dryRun  bool
+	}{
+		{name: "no canary", percent: 0, dryRun: false},
+		{name: "within canary", percent: 42, dryRun: false},
+		{name: "outside of canary", percent: 41, dryRun: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			automation := Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}}
+			automation.Properties.CanaryPercent = tt.percent
+			conf := &Configuration{}
+			conf.Spec.Parameters.Custom = map[string][]Automation{"public_bucket": {automation}}
+			if err := Execute(ctx, &Values{Finding: testData(t, "custom.json")}, &Services{
+				PubSub:        services.NewPubSub(psStub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+				Configuration: conf,
+				Resource:      services.NewResource(crmStub, &stubs.StorageStub{}),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if psStub.PublishedMessage == nil {
+				t.Fatalf("%q failed, nothing published", tt.name)
+			}
+			var got closebucket.Values
+			if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got.DryRun != tt.dryRun {
+				t.Errorf("%q failed, dry run:%v want:%v", tt.name, got.DryRun, tt.dryRun)
+			}
+		})
+	}
+}
+
+func TestCanaryWithoutID(t *testing.T) {
+	// Findings without a project, account or user are assigned by their name: "f0" hashes to 13
+	// and "f1" to 94.
+	for _, tt := range []struct {
+		name    string
+		finding string
+		dryRun  bool
+	}{
+		{name: "within canary", finding: "organizations/456/sources/789/findings/f0", dryRun: false},
+		{name: "outside of canary", finding: "organizations/456/sources/789/findings/f1", dryRun: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := []byte(`{"finding": {"name": "` + tt.finding + `"}}`)
+			ctx := context.WithValue(context.Background(), routingKey{}, &routing{finding: finding})
+			automation := Automation{Action: "close_bucket"}
+			automation.Properties.CanaryPercent = 50
+			var values closebucket.Values
+			if err := canary(ctx, automation, map[string]interface{}{}, &values); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if values.DryRun != tt.dryRun {
+				t.Errorf("%q failed, dry run:%v want:%v", tt.name, values.DryRun, tt.dryRun)
+			}
+		})
+	}
+}
+
+func TestPlaybook(t *testing.T) {
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	target := []string{"organizations/456/folders/123/projects/test-project"}
+	conf := &Configuration{}
+	conf.Spec.Parameters.Custom = map[string][]Automation{"public_bucket": {
+		{Action: "enable_bucket_only_policy", Target: target, Playbook: "lockdown"},
+		{Action: "close_bucket", Target: target, Playbook: "lockdown", Condition: `instance.labels.env != "prod"`, Otherwise: "approval"},
+	}}
+	finding := []byte(`{"schemaVersion": "v1", "customFinding": {"source": "my-detector", "category": "public_bucket",
+		"resource": {"name": "//storage.googleapis.com/this-is-public-on-purpose", "projectId": "test-project"}}}`)
+	if err := Execute(ctx, &Values{Finding: finding}, &Services{
+		PubSub:        services.NewPubSub(psStub),
+		Logger:        services.NewLogger(&stubs.LoggerStub{}),
+		Configuration: conf,
+		Resource:      services.NewResource(crmStub, &stubs.StorageStub{}),
+	}); err != nil {
+		t.Fatalf("playbook failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("playbook failed, nothing published")
+	}
+	var got playbook.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("playbook failed: %q", err)
+	}
+	var actions []string
+	for _, s := range got.Steps {
+		actions = append(actions, s.Action)
+	}
+	if diff := cmp.Diff([]string{"enable_bucket_only_policy", "close_bucket"}, actions); diff != "" {
+		t.Errorf("playbook failed, difference in steps: %+v", diff)
+	}
+	if got.Name != "lockdown" || got.Finding == "" {
+		t.Errorf("playbook failed, got name %q and finding %q", got.Name, got.Finding)
+	}
+	if s := got.Steps[1]; s.Condition == "" || s.Otherwise != "approval" || s.Topic != "threat-findings-close-bucket" {
+		t.Errorf("playbook failed, condition not deferred to the step: %+v", s)
+	}
+}
+
+func TestEnabledActions(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		enabled   []string
+		published bool
+	}{
+		{name: "all enabled", published: true},
+		{name: "enabled", enabled: []string{"close_bucket"}, published: true},
+		{name: "not enabled", enabled: []string{"iam_revoke"}, published: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			conf := &Configuration{}
+			conf.Spec.Enabled = tt.enabled
+			conf.Spec.Parameters.Custom = map[string][]Automation{"public_bucket": {
+				{Action: "close_bucket", Target: []string{"organizations/456/folders/123/projects/test-project"}},
+			}}
+			if err := Execute(ctx, &Values{Finding: testData(t, "custom.json")}, &Services{
+				PubSub:        services.NewPubSub(psStub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+				Configuration: conf,
+				Resource:      services.NewResource(crmStub, &stubs.StorageStub{}),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Errorf("%q failed, published:%v want:%v", tt.name, got, tt.published)
+			}
+		})
+	}
+}
+
+func TestLabelResource(t *testing.T) {
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	automation := Automation{Action: "label_resource", Target: []string{"organizations/456/*"}}
+	automation.Properties.LabelResource.Labels = map[string]string{"team": "secops"}
+	conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "public_bucket_acl.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("label_resource failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("label_resource was not published")
+	}
+	var got labelresource.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := labelresource.Values{
+		ProjectID:    "test-project",
+		ResourceName: "//storage.googleapis.com/this-is-public-on-purpose",
+		FindingID:    "782e52631d61da6117a3772137c270d8",
+		Labels:       map[string]string{"team": "secops"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("label_resource values difference: %+v", diff)
+	}
+}
+
+func TestDisableAPIs(t *testing.T) {
+	const config = `
+action: disable_apis
+target: ["organizations/456/*"]
+properties:
+  disable_apis:
+    apis: ["compute.googleapis.com"]
+    protected: ["bigquery.googleapis.com"]
+    disable_dependents: true
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.ETD.BadIP = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "bad_ip.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("disable_apis failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("disable_apis was not published")
+	}
+	var got disableapis.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := disableapis.Values{
+		ProjectID:         "test-project",
+		APIs:              []string{"compute.googleapis.com"},
+		Protected:         []string{"bigquery.googleapis.com"},
+		DisableDependents: true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("disable_apis values difference: %+v", diff)
+	}
+}
+
+func TestShutdownProject(t *testing.T) {
+	no := false
+	for _, tt := range []struct {
+		name         string
+		approval     *bool
+		reason       string
+		wantApproval bool
+		want         shutdownproject.Values
+	}{
+		{
+			name:         "held for approval by default",
+			wantApproval: true,
+			want:         shutdownproject.Values{ProjectID: "test-project", Mode: "lien"},
+		},
+		{
+			name:     "approval opted out",
+			approval: &no,
+			reason:   "attacker created project",
+			want:     shutdownproject.Values{ProjectID: "test-project", Mode: "lien", Reason: "attacker created project"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			automation := Automation{Action: "shutdown_project", Target: []string{"organizations/456/*"}, Approval: tt.approval}
+			automation.Properties.ShutdownProject.Mode = "lien"
+			automation.Properties.ShutdownProject.Reason = tt.reason
+			psStub := &stubs.PubSubStub{}
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			conf := &Configuration{}
+			conf.Spec.Parameters.ETD.BadIP = []Automation{automation}
+			if err := Execute(context.Background(), &Values{Finding: testData(t, "bad_ip.json")}, &Services{
+				PubSub:                services.NewPubSub(psStub),
+				Logger:                services.NewLogger(&stubs.LoggerStub{}),
+				Configuration:         conf,
+				Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+				SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if psStub.PublishedMessage == nil {
+				t.Fatalf("%q was not published", tt.name)
+			}
+			if got := psStub.PublishedMessage.Attributes["action"] != ""; got != tt.wantApproval {
+				t.Errorf("%q held for approval:%t want:%t", tt.name, got, tt.wantApproval)
+			}
+			var got shutdownproject.Values
+			if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+				t.Fatalf("failed to unmarshal published values: %q", err)
+			}
+			if tt.want.Reason == "" {
+				if !strings.HasPrefix(got.Reason, "Held for investigation of finding ") {
+					t.Errorf("%q reason %q does not name the finding", tt.name, got.Reason)
+				}
+				tt.want.Reason = got.Reason
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%q values difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestDetachBilling(t *testing.T) {
+	const config = `
+action: detach_billing
+target: ["organizations/456/*"]
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.ETD.BadIP = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "bad_ip.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("detach_billing failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("detach_billing was not published")
+	}
+	var got detachbilling.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	if diff := cmp.Diff(detachbilling.Values{ProjectID: "test-project"}, got); diff != "" {
+		t.Errorf("detach_billing values difference: %+v", diff)
+	}
+}
+
+func TestSnapshotSettings(t *testing.T) {
+	const config = `
+action: gce_create_disk_snapshot
+properties:
+  gce_create_snapshot:
+    recency_minutes: 30
+    name_template: "{{.Category}}-{{.Disk}}-{{.Timestamp}}"
+    labels:
+      team: secops
+    overrides:
+      - folder_ids: ["123"]
+        project_labels: ["env=prod"]
+        recency_minutes: 120
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	values := &createsnapshot.Values{ProjectID: "test-project"}
+	configureSnapshot(values, automation)
+	want := &createsnapshot.Values{
+		ProjectID: "test-project",
+		Settings: createsnapshot.Settings{
+			RecencyMinutes: 30,
+			NameTemplate:   "{{.Category}}-{{.Disk}}-{{.Timestamp}}",
+			Labels:         map[string]string{"team": "secops"},
+		},
+		Overrides: []createsnapshot.Override{{
+			FolderIDs:     []string{"123"},
+			ProjectLabels: []string{"env=prod"},
+			Settings:      createsnapshot.Settings{RecencyMinutes: 120},
+		}},
+	}
+	if diff := cmp.Diff(want, values); diff != "" {
+		t.Errorf("snapshot values difference: %+v", diff)
+	}
+}
+
+func TestExportEvidenceLogs(t *testing.T) {
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	automation := Automation{Action: "export_evidence_logs", Target: []string{"organizations/456/*"}}
+	automation.Properties.ExportEvidenceLogs.WindowMinutes = 30
+	conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "public_bucket_acl.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("export_evidence_logs failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("export_evidence_logs was not published")
+	}
+	var got exportlogs.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := exportlogs.Values{
+		ProjectID:     "test-project",
+		ResourceName:  "//storage.googleapis.com/this-is-public-on-purpose",
+		FindingID:     "782e52631d61da6117a3772137c270d8",
+		FindingTime:   time.Date(2019, 9, 23, 17, 20, 27, 204000000, time.UTC),
+		WindowMinutes: 30,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("export_evidence_logs values difference: %+v", diff)
+	}
+}
+
+func TestRequireOSLogin(t *testing.T) {
+	const finding = `{
+		"notificationConfigName": "organizations/456/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/456/sources/789/findings/abc123",
+			"resourceName": "//cloudresourcemanager.googleapis.com/projects/000000000000",
+			"category": "Brute_force: SSH Brute Force",
+			"sourceProperties": {
+				"detectionCategory": {"ruleName": "ssh_brute_force"},
+				"properties": {
+					"project_id": "test-project",
+					"loginAttempts": [{"authResult": "SUCCESS", "sourceIp": "10.200.0.3", "userName": "root", "vmName": "instance-1"}]
+				}
+			}
+		}
+	}`
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.ETD.SSHBruteForce = []Automation{{Action: "gce_require_os_login", Target: []string{"organizations/456/*"}}}
+	if err := Execute(ctx, &Values{Finding: []byte(finding)}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("gce_require_os_login failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("gce_require_os_login was not published")
+	}
+	var got requireoslogin.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	if diff := cmp.Diff(requireoslogin.Values{ProjectID: "test-project"}, got); diff != "" {
+		t.Errorf("gce_require_os_login values difference: %+v", diff)
+	}
+}
+
+func TestRestrictExternalIPs(t *testing.T) {
+	const config = `
+action: gce_restrict_external_ips
+target: ["organizations/456/*"]
+properties:
+  gce_restrict_external_ips:
+    scope: folder
+    allowed_instances: ["projects/sec-automation-dev/zones/us-central1-a/instances/bastion"]
+    threshold: 3
+    window_hours: 48
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/sec-automation-dev", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.SHA.PublicIPAddress = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "conformance/sha/public_ip_address.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("gce_restrict_external_ips failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("gce_restrict_external_ips was not published")
+	}
+	var got restrictexternalips.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := restrictexternalips.Values{
+		ProjectID:        "sec-automation-dev",
+		Scope:            restrictexternalips.ScopeFolder,
+		AllowedInstances: []string{"projects/sec-automation-dev/zones/us-central1-a/instances/bastion"},
+		Threshold:        3,
+		WindowHours:      48,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("gce_restrict_external_ips values difference: %+v", diff)
+	}
+}
+
+func TestPreventPublicAccess(t *testing.T) {
+	const config = `
+action: gcs_prevent_public_access
+target: ["organizations/456/*"]
+properties:
+  gcs_prevent_public_access:
+    threshold: 5
+    window_hours: 12
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/aerial-jigsaw-235219", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "conformance/sha/public_bucket_acl.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("gcs_prevent_public_access failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("gcs_prevent_public_access was not published")
+	}
+	var got preventpublicaccess.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := preventpublicaccess.Values{
+		ProjectID:   "aerial-jigsaw-235219",
+		BucketName:  "this-is-public-on-purpose",
+		Threshold:   5,
+		WindowHours: 12,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("gcs_prevent_public_access values difference: %+v", diff)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	const config = `
+action: kms_rotate_key
+target: ["organizations/154584661726/*"]
+properties:
+  kms_rotate_key:
+    rotation_period_days: 30
+    next_rotation_hours: 6
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/154584661726"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.SHA.KMSKeyNotRotated = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "conformance/sha/kms_key_not_rotated.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("kms_rotate_key failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("kms_rotate_key was not published")
+	}
+	var got rotatekey.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := rotatekey.Values{
+		ProjectID:          "test-project",
+		KeyName:            "projects/test-project/locations/global/keyRings/app-ring/cryptoKeys/app-key",
+		RotationPeriodDays: 30,
+		NextRotationHours:  6,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("kms_rotate_key values difference: %+v", diff)
+	}
+}
+
+func TestRemoveKeyPublicAccess(t *testing.T) {
+	const config = `
+action: kms_remove_public_access
+target: ["organizations/154584661726/*"]
+properties:
+  kms_remove_public_access:
+    allow_domains: ["example.com"]
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/154584661726"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.SHA.KMSPublicKey = []Automation{automation}
+	if err := Execute(ctx, &Values{Finding: testData(t, "conformance/sha/kms_public_key.json")}, &Services{
+		PubSub:                services.NewPubSub(psStub),
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Configuration:         conf,
+		Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+	}); err != nil {
+		t.Fatalf("kms_remove_public_access failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("kms_remove_public_access was not published")
+	}
+	var got removepublicaccess.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := removepublicaccess.Values{
+		ProjectID:    "test-project",
+		KeyName:      "projects/test-project/locations/global/keyRings/app-ring/cryptoKeys/app-key",
+		AllowDomains: []string{"example.com"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("kms_remove_public_access values difference: %+v", diff)
+	}
+}
+
+func TestCloseSecret(t *testing.T) {
+	const config = `
+action: close_secret
+target: ["organizations/456/*"]
+properties:
+  close_secret:
+    allow_domains: ["example.com"]
+    rotate: true
+    payload_bytes: 64
+`
+	var automation Automation
+	if err := yaml.Unmarshal([]byte(config), &automation); err != nil {
+		t.Fatalf("failed to unmarshal automation: %q", err)
+	}
+	ctx := context.Background()
+	psStub := &stubs.PubSubStub{}
+	crmStub := &stubs.ResourceManagerStub{}
+	crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+	conf := &Configuration{}
+	conf.Spec.Parameters.Custom = map[string][]Automation{"public_secret": {automation}}
+	finding := []byte(`{"schemaVersion": "v1", "customFinding": {"source": "my-detector", "category": "public_secret",
+		"resource": {"name": "//secretmanager.googleapis.com/projects/test-project/secrets/api-key"}}}`)
+	if err := Execute(ctx, &Values{Finding: finding}, &Services{
+		PubSub:        services.NewPubSub(psStub),
+		Logger:        services.NewLogger(&stubs.LoggerStub{}),
+		Configuration: conf,
+		Resource:      services.NewResource(crmStub, &stubs.StorageStub{}),
+	}); err != nil {
+		t.Fatalf("close_secret failed: %q", err)
+	}
+	if psStub.PublishedMessage == nil {
+		t.Fatalf("close_secret was not published")
+	}
+	var got closesecret.Values
+	if err := json.Unmarshal(psStub.PublishedMessage.Data, &got); err != nil {
+		t.Fatalf("failed to unmarshal published values: %q", err)
+	}
+	want := closesecret.Values{
+		ProjectID:    "test-project",
+		SecretName:   "projects/test-project/secrets/api-key",
+		AllowDomains: []string{"example.com"},
+		Rotate:       true,
+		PayloadBytes: 64,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("close_secret values difference: %+v", diff)
+	}
+}
+
+func TestAccountCompromise(t *testing.T) {
+	const finding = `{
+		"notificationConfigName": "organizations/456/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/456/sources/789/findings/abc123",
+			"resourceName": "//cloudresourcemanager.googleapis.com/organizations/456",
+			"category": "Account_Has_Leaked_Credentials",
+			"sourceProperties": {
+				"detectionCategory": {"ruleName": "account_has_leaked_credentials"},
+				"properties": {"principalEmail": "alice@example.com"}
+			}
+		}
+	}`
+	no := false
+	for _, tt := range []struct {
+		name         string
+		action       string
+		target       []string
+		approval     *bool
+		published    bool
+		wantApproval bool
+	}{
+		{name: "held for approval by default", action: "workspace_suspend_user", target: []string{"example.com"}, published: true, wantApproval: true},
+		{name: "approval opted out", action: "workspace_suspend_user", target: []string{"alice@example.com"}, approval: &no, published: true},
+		{name: "other domain", action: "workspace_suspend_user", target: []string{"example.org"}},
+		{name: "revoke sessions", action: "workspace_revoke_sessions", target: []string{"*"}, published: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			psStub := &stubs.PubSubStub{}
+			conf := &Configuration{}
+			conf.Spec.Parameters.ETD.AccountCompromise = []Automation{
+				{Action: tt.action, Target: tt.target, Approval: tt.approval},
+			}
+			if err := Execute(context.Background(), &Values{Finding: []byte(finding)}, &Services{
+				PubSub:        services.NewPubSub(psStub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+				Configuration: conf,
+			}); err != nil {
+				t.Fatalf("%q failed: %q", tt.name, err)
+			}
+			if got := psStub.PublishedMessage != nil; got != tt.published {
+				t.Fatalf("%q published:%t want:%t", tt.name, got, tt.published)
+			}
+			if !tt.published {
+				return
+			}
+			if got := psStub.PublishedMessage.Attributes["action"] != ""; got != tt.wantApproval {
+				t.Errorf("%q held for approval:%t want:%t", tt.name, got, tt.wantApproval)
+			}
+			if diff := cmp.Diff(`{"Email":"alice@example.com","DryRun":false}`, string(psStub.PublishedMessage.Data)); diff != "" {
+				t.Errorf("%q values difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestAutomations(t *testing.T) {
+	conf := &Configuration{}
+	conf.Spec.Parameters.SHA.PublicBucketACL = []Automation{{Action: "close_bucket"}}
+	conf.Spec.Parameters.CloudIDS.Threat = []Automation{{Action: "gce_quarantine_instance"}}
+	conf.Spec.Parameters.Chronicle.Rules = map[string][]Automation{"ru_1": {{Action: "send_email"}}}
+	conf.Spec.Parameters.Custom = map[string][]Automation{"public_bucket": {{Action: "close_bucket"}}}
+	got := make(map[string]string)
+	for finding, automations := range conf.Automations() {
+		got[finding] = automations[0].Action
+	}
+	want := map[string]string{
+		"sha/public_bucket_acl": "close_bucket",
+		"cloud_ids/threat":      "gce_quarantine_instance",
+		"chronicle/rules/ru_1":  "send_email",
+		"custom/public_bucket":  "close_bucket",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("automations failed, difference: %+v", diff)
+	}
+}
+
 func TestRemediated(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
@@ -263,3 +1591,36 @@ func TestRemediated(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulated(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		simulated bool
+		marked    bool
+	}{
+		{name: "notified", marked: true},
+		{name: "simulated", simulated: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			crmStub := &stubs.ResourceManagerStub{}
+			crmStub.GetAncestryResponse = services.CreateAncestors([]string{"project/test-project", "folder/123", "organization/456"})
+			sccStub := &stubs.SecurityCommandCenterStub{}
+			if err := Execute(ctx, &Values{
+				Finding:   testData(t, "public_bucket_acl.json"),
+				Simulated: tt.simulated,
+			}, &Services{
+				PubSub:                services.NewPubSub(&stubs.PubSubStub{}),
+				Logger:                services.NewLogger(&stubs.LoggerStub{}),
+				Configuration:         &Configuration{},
+				Resource:              services.NewResource(crmStub, &stubs.StorageStub{}),
+				SecurityCommandCenter: services.NewCommandCenter(sccStub),
+			}); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if marked := sccStub.GetUpdateSecurityMarksRequest != nil; marked != tt.marked {
+				t.Errorf("%s got marked %t want %t", tt.name, marked, tt.marked)
+			}
+		})
+	}
+}
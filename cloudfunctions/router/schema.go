@@ -0,0 +1,189 @@
+package router
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envelopeFields are the top level fields of each schema version, others are unknown.
+var envelopeFields = map[string][]string{
+	"scc/v1": {"notificationConfigName", "finding", "resource"},
+	"logging": {
+		"logName", "resource", "timestamp", "receiveTimestamp", "severity", "insertId", "httpRequest",
+		"labels", "operation", "trace", "spanId", "traceSampled", "sourceLocation", "jsonPayload",
+		"textPayload", "protoPayload", "split",
+	},
+}
+
+// requiredFields are the fields the parser of each finding reads, as dot separated paths with
+// array elements by index. The paths are relative to the finding's source properties for Event
+// Threat Detection and to the finding for Security Health Analytics.
+var requiredFields = map[string][]string{
+	"etd/bad_ip":                               {"detectionCategory.ruleName", "properties.instanceDetails", "properties.network.project"},
+	"etd/iam_anomalous_grant":                  {"detectionCategory.ruleName", "evidence.0.sourceLogId.projectId", "properties.sensitiveRoleGrant.members"},
+	"etd/ssh_brute_force":                      {"detectionCategory.ruleName", "properties.project_id", "properties.loginAttempts"},
+	"etd/account_compromise":                   {"detectionCategory.ruleName", "properties.principalEmail"},
+	"etd/exfiltration":                         {"detectionCategory.ruleName"},
+	"sha/public_bucket_acl":                    {"resourceName", "sourceProperties.ProjectId"},
+	"sha/bucket_policy_only_disabled":          {"resourceName", "sourceProperties.ProjectId"},
+	"sha/public_sql_instance":                  {"resourceName", "sourceProperties.ProjectId"},
+	"sha/ssl_not_enforced":                     {"resourceName", "sourceProperties.ProjectId"},
+	"sha/sql_no_root_password":                 {"resourceName", "sourceProperties.ProjectId"},
+	"sha/public_ip_address":                    {"resourceName", "sourceProperties.ProjectId"},
+	"sha/open_firewall":                        {"resourceName", "sourceProperties.ProjectId"},
+	"sha/open_ssh_port":                        {"resourceName", "sourceProperties.ProjectId"},
+	"sha/open_rdp_port":                        {"resourceName", "sourceProperties.ProjectId"},
+	"sha/public_dataset":                       {"resourceName", "sourceProperties.ProjectId"},
+	"sha/web_ui_enabled":                       {"resourceName", "sourceProperties.ProjectId"},
+	"sha/audit_logging_disabled":               {"sourceProperties.ProjectId"},
+	"sha/non_org_iam_member":                   {"sourceProperties.ProjectId"},
+	"sha/primitive_roles_used":                 {"sourceProperties.ProjectId", "sourceProperties.OffendingIamRoles"},
+	"sha/over_privileged_service_account_user": {"sourceProperties.ProjectId", "sourceProperties.OffendingIamRoles"},
+	"sha/admin_service_account":                {"sourceProperties.ProjectId", "sourceProperties.OffendingIamRoles"},
+	"sha/kms_key_not_rotated":                  {"resourceName", "sourceProperties.ProjectId"},
+	"sha/kms_public_key":                       {"resourceName", "sourceProperties.ProjectId"},
+}
+
+// SchemaError is returned in strict mode for findings that do not hold the fields their parser
+// reads or that hold fields their schema does not define.
+type SchemaError struct {
+	Detection *Detection
+	// Missing are the paths of the required fields that are absent or empty.
+	Missing []string
+	// Unknown are the top level fields the schema does not define.
+	Unknown []string
+}
+
+// Error returns the reason the finding was rejected.
+func (e *SchemaError) Error() string {
+	var reasons []string
+	if len(e.Missing) > 0 {
+		reasons = append(reasons, fmt.Sprintf("missing %s", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Unknown) > 0 {
+		reasons = append(reasons, fmt.Sprintf("unknown %s", strings.Join(e.Unknown, ", ")))
+	}
+	return fmt.Sprintf("finding %s does not match its schema: %s", e.Detection, strings.Join(reasons, "; "))
+}
+
+// attributes returns the reason as message attributes of the dead lettered finding.
+func (e *SchemaError) attributes() map[string]string {
+	m := map[string]string{"reason": "schema", "detection": e.Detection.String()}
+	if len(e.Missing) > 0 {
+		m["missing"] = strings.Join(e.Missing, ",")
+	}
+	if len(e.Unknown) > 0 {
+		m["unknown"] = strings.Join(e.Unknown, ",")
+	}
+	return m
+}
+
+// validate checks the finding holds the fields its parser reads and no top level field its schema
+// does not define. Findings of sources without known required fields are only checked for
+// unknown fields.
+func validate(d *Detection, b []byte) error {
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("failed to read finding %s: %q", d, err)
+	}
+	e := &SchemaError{Detection: d}
+	if known, ok := envelopeFields[d.Version]; ok {
+		for k := range v {
+			if !containsFold(known, k) {
+				e.Unknown = append(e.Unknown, k)
+			}
+		}
+		sort.Strings(e.Unknown)
+	}
+	var prefix string
+	switch {
+	case d.Version == "scc/v1" && d.Source == "etd":
+		prefix = "finding.sourceProperties."
+	case d.Version == "scc/v1":
+		prefix = "finding."
+	case d.Version == "logging":
+		prefix = "jsonPayload."
+	}
+	if d.Version == "scc/v1" && !present(v, "finding.name") {
+		e.Missing = append(e.Missing, "finding.name")
+	}
+	for _, p := range requiredFields[d.Source+"/"+d.Category] {
+		if !present(v, prefix+p) {
+			e.Missing = append(e.Missing, prefix+p)
+		}
+	}
+	if len(e.Missing) == 0 && len(e.Unknown) == 0 {
+		return nil
+	}
+	return e
+}
+
+// present returns whether the field at the path is set and not empty. Keys are matched without
+// regard to case, as the parsers unmarshal them.
+func present(v interface{}, path string) bool {
+	for _, k := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v = lookupFold(t, k)
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i >= len(t) {
+				return false
+			}
+			v = t[i]
+		default:
+			return false
+		}
+	}
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		return t != ""
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+// lookupFold returns the value of the key, preferring an exact match over one ignoring case.
+func lookupFold(m map[string]interface{}, key string) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// containsFold returns whether the list holds the value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, value) {
+			return true
+		}
+	}
+	return false
+}
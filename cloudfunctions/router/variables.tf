@@ -4,3 +4,9 @@ variable "folder-ids" {
   type        = list(string)
   description = "Folder IDs to grant the necessary permissions for this Cloud Function execution."
 }
+
+variable "policy-bucket" {
+  type        = string
+  default     = ""
+  description = "Cloud Storage bucket holding Rego policies that gate automations."
+}
@@ -0,0 +1,149 @@
+package closesecret
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "close_secret",
+		EntryPoint: "CloseSecret",
+		Topic:      "threat-findings-close-secret",
+		Services:   []string{"SecretManager"},
+		Roles:      []string{"roles/secretmanager.admin"},
+		Permissions: []string{
+			"secretmanager.secrets.getIamPolicy", "secretmanager.secrets.setIamPolicy",
+			"secretmanager.versions.list", "secretmanager.versions.add", "secretmanager.versions.disable",
+		},
+	})
+}
+
+// defaultPayloadBytes is the number of random bytes of the versions added when rotating.
+const defaultPayloadBytes = 32
+
+// generate returns the payload of the version added when rotating, set in tests.
+var generate = func(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// SecretName is the secret, named "projects/<id>/secrets/<secret>".
+	SecretName string
+	// AllowDomains are the domains of the users and groups kept, only allUsers and
+	// allAuthenticatedUsers are removed if empty.
+	AllowDomains []string
+	// Rotate adds a random version to the secret and disables the others, as their values may have
+	// been read while the secret was public.
+	Rotate bool
+	// PayloadBytes is the number of random bytes of the version added, encoded as URL safe base64.
+	PayloadBytes int
+	DryRun       bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	SecretManager *services.SecretManager
+	Logger        *services.Logger
+	// Progress records the secret as closed and as rotated, letting a retry rotate a secret closed
+	// by an attempt whose rotation failed.
+	Progress *services.Progress
+}
+
+// Execute removes allUsers, allAuthenticatedUsers and the members outside the allowed domains from
+// the IAM policy of the secret, then rotates it if configured. The grants removed and the versions
+// disabled are logged as changes, so they can be restored.
+//
+// A rotation failing after the members were removed is returned as a PartialError and left pending,
+// the retry rotates the secret even though it is no longer public.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	n := values.PayloadBytes
+	if n < 0 {
+		return fmt.Errorf("invalid payload_bytes %d", n)
+	}
+	if n == 0 {
+		n = defaultPayloadBytes
+	}
+	members, err := services.SecretManager.ExternalMembers(ctx, values.SecretName, values.AllowDomains)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get policy of %q", values.SecretName)
+	}
+	progress := progressOf(services)
+	rotation := values.SecretName + "/versions"
+	pending := values.Rotate && !progress.Pending(values.SecretName) && progress.Pending(rotation)
+	if len(members) == 0 && !pending {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		if len(members) > 0 {
+			services.Logger.Info("dry_run on, would have removed %q from %q", members, values.SecretName)
+		}
+		if values.Rotate {
+			services.Logger.Info("dry_run on, would have rotated %q", values.SecretName)
+		}
+		return nil
+	}
+	if len(members) > 0 {
+		if err := services.SecretManager.RemoveMembers(ctx, values.SecretName, members); err != nil {
+			return errors.Wrapf(err, "failed to remove members from %q", values.SecretName)
+		}
+		services.Logger.Info("removed %q from %q", members, values.SecretName)
+		if err := progress.Complete(ctx, values.SecretName); err != nil {
+			log.Printf("failed to record progress: %q", err)
+		}
+	}
+	if !values.Rotate {
+		return nil
+	}
+	payload, err := generate(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate payload")
+	}
+	added, disabled, err := services.SecretManager.Rotate(ctx, values.SecretName, payload)
+	if err != nil {
+		if err := progress.Fail(ctx, rotation, err); err != nil {
+			log.Printf("failed to record progress: %q", err)
+		}
+		return progress.Err()
+	}
+	services.Logger.Info("added %q to %q and disabled %q", added, values.SecretName, disabled)
+	if err := progress.Complete(ctx, rotation); err != nil {
+		log.Printf("failed to record progress: %q", err)
+	}
+	return nil
+}
+
+// progressOf returns the progress of the remediation, kept for this attempt only if there is none.
+func progressOf(s *Services) *services.Progress {
+	if s.Progress == nil {
+		return &services.Progress{}
+	}
+	return s.Progress
+}
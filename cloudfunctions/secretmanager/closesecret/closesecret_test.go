@@ -0,0 +1,154 @@
+package closesecret
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+func TestCloseSecret(t *testing.T) {
+	ctx := context.Background()
+	const secret = "projects/test-project/secrets/api-key"
+	accessor := "roles/secretmanager.secretAccessor"
+	generate = func(n int) ([]byte, error) { return make([]byte, n), nil }
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		policy        *secretmanager.Policy
+		wantPolicy    *secretmanager.Policy
+		wantPayload   []byte
+		wantDisabled  []string
+		expectedError error
+	}{
+		{
+			name:       "public secret",
+			values:     &Values{ProjectID: "test-project", SecretName: secret},
+			policy:     &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"allUsers", "user:alice@example.com"}}}},
+			wantPolicy: &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"user:alice@example.com"}}}},
+		},
+		{
+			name:         "rotate",
+			values:       &Values{ProjectID: "test-project", SecretName: secret, Rotate: true, PayloadBytes: 16},
+			policy:       &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"allAuthenticatedUsers"}}}},
+			wantPolicy:   &secretmanager.Policy{},
+			wantPayload:  make([]byte, 16),
+			wantDisabled: []string{secret + "/versions/1"},
+		},
+		{
+			name:       "external member",
+			values:     &Values{ProjectID: "test-project", SecretName: secret, AllowDomains: []string{"example.com"}},
+			policy:     &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"user:alice@example.com", "user:mallory@attacker.com"}}}},
+			wantPolicy: &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"user:alice@example.com"}}}},
+		},
+		{
+			name:          "not public",
+			values:        &Values{ProjectID: "test-project", SecretName: secret, Rotate: true},
+			policy:        &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"user:alice@example.com"}}}},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "test-project", SecretName: secret, Rotate: true, DryRun: true},
+			policy: &secretmanager.Policy{Bindings: []*secretmanager.Binding{{Role: accessor, Members: []string{"allUsers"}}}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.SecretManagerStub{
+				StubbedPolicies: map[string]*secretmanager.Policy{secret: tt.policy},
+				StubbedVersions: map[string][]*secretmanager.SecretVersion{secret: {
+					{Name: secret + "/versions/1", State: "ENABLED"},
+				}},
+			}
+			if err := Execute(ctx, tt.values, &Services{
+				SecretManager: services.NewSecretManager(stub),
+				Logger:        services.NewLogger(&stubs.LoggerStub{}),
+			}); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantPolicy, stub.SavedPolicy); diff != "" {
+				t.Errorf("%s policy (-want +got):\n%s", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.wantPayload, stub.AddedPayload); diff != "" {
+				t.Errorf("%s payload (-want +got):\n%s", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.wantDisabled, stub.DisabledVersions); diff != "" {
+				t.Errorf("%s disabled versions (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestCloseSecretRetriesRotation(t *testing.T) {
+	ctx := context.Background()
+	const secret = "projects/test-project/secrets/api-key"
+	generate = func(n int) ([]byte, error) { return make([]byte, n), nil }
+	stub := &stubs.SecretManagerStub{
+		StubbedPolicies: map[string]*secretmanager.Policy{secret: {Bindings: []*secretmanager.Binding{
+			{Role: "roles/secretmanager.secretAccessor", Members: []string{"allUsers"}},
+		}}},
+		StubbedVersions: map[string][]*secretmanager.SecretVersion{secret: {
+			{Name: secret + "/versions/1", State: "ENABLED"},
+		}},
+		StubbedAddErr: errors.New("quota exceeded"),
+	}
+	svcs := &Services{
+		SecretManager: services.NewSecretManager(stub),
+		Logger:        services.NewLogger(&stubs.LoggerStub{}),
+		Progress:      &services.Progress{},
+	}
+	values := &Values{ProjectID: "test-project", SecretName: secret, Rotate: true}
+	err := Execute(ctx, values, svcs)
+	partial, ok := err.(*services.PartialError)
+	if !ok {
+		t.Fatalf("first attempt got %q, want a PartialError", err)
+	}
+	if diff := cmp.Diff([]string{secret}, partial.Completed); diff != "" {
+		t.Errorf("completed (-want +got):\n%s", diff)
+	}
+	// The secret is no longer public, the retry must still rotate it.
+	stub.StubbedPolicies[secret] = stub.SavedPolicy
+	stub.StubbedAddErr = nil
+	if err := Execute(ctx, values, svcs); err != nil {
+		t.Fatalf("retry failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{secret + "/versions/1"}, stub.DisabledVersions); diff != "" {
+		t.Errorf("disabled versions (-want +got):\n%s", diff)
+	}
+	if err := Execute(ctx, values, svcs); err != registry.ErrAlreadyRemediated {
+		t.Errorf("second retry got %q, want %q", err, registry.ErrAlreadyRemediated)
+	}
+}
+
+func TestCloseSecretNegativePayload(t *testing.T) {
+	stub := &stubs.SecretManagerStub{}
+	err := Execute(context.Background(), &Values{SecretName: "projects/test-project/secrets/api-key", Rotate: true, PayloadBytes: -1}, &Services{
+		SecretManager: services.NewSecretManager(stub),
+		Logger:        services.NewLogger(&stubs.LoggerStub{}),
+	})
+	if err == nil {
+		t.Fatalf("negative payload_bytes got no error")
+	}
+	if stub.SavedPolicy != nil || stub.AddedPayload != nil {
+		t.Errorf("negative payload_bytes changed the secret")
+	}
+}
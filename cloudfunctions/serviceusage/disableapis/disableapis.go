@@ -0,0 +1,124 @@
+package disableapis
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "disable_apis",
+		EntryPoint:  "DisableAPIs",
+		Topic:       "threat-findings-disable-apis",
+		Services:    []string{"ServiceUsage"},
+		Roles:       []string{"roles/serviceusage.serviceUsageAdmin"},
+		Permissions: []string{"serviceusage.services.list", "serviceusage.services.disable"},
+	})
+}
+
+// protectedAPIs are never disabled: the project could no longer be managed, audited or remediated,
+// or data encrypted with its keys read, without them.
+var protectedAPIs = []string{
+	"cloudkms.googleapis.com",
+	"cloudresourcemanager.googleapis.com",
+	"iam.googleapis.com",
+	"iamcredentials.googleapis.com",
+	"logging.googleapis.com",
+	"monitoring.googleapis.com",
+	"securitycenter.googleapis.com",
+	"serviceusage.googleapis.com",
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// APIs are the APIs disabled, such as "compute.googleapis.com".
+	APIs []string
+	// Protected are APIs never disabled in addition to the built in ones.
+	Protected []string
+	// DisableDependents disables the APIs depending on those disabled first, and those depending
+	// on them in turn, unless any of them is protected. Disabling an API others depend on fails
+	// otherwise.
+	DisableDependents bool
+	DryRun            bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	ServiceUsage *services.ServiceUsage
+	Logger       *services.Logger
+}
+
+// Execute disables the APIs enabled in the project, except the protected ones and those protected
+// APIs depend on. The APIs disabled, dependents included, are logged as changes, so they can be
+// enabled again.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	enabled, err := services.ServiceUsage.EnabledServices(ctx, values.ProjectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the enabled APIs of %q", values.ProjectID)
+	}
+	protected := append(append([]string{}, protectedAPIs...), values.Protected...)
+	var apis []string
+	for _, api := range values.APIs {
+		if contains(protected, api) {
+			services.Logger.Warning("not disabling protected API %q in %q", api, values.ProjectID)
+			continue
+		}
+		if contains(enabled, api) {
+			apis = append(apis, api)
+		}
+	}
+	if len(apis) == 0 {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have disabled %q in %q", apis, values.ProjectID)
+		return nil
+	}
+	var disabled []string
+	for _, api := range apis {
+		if contains(disabled, api) {
+			continue
+		}
+		dependents, err := services.ServiceUsage.DisableService(ctx, values.ProjectID, api, values.DisableDependents, protected)
+		if len(dependents) > 0 && err != nil {
+			services.Logger.Warning("disabled %q in %q depending on %q before failing", dependents, values.ProjectID, api)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to disable %q in %q", api, values.ProjectID)
+		}
+		disabled = append(disabled, dependents...)
+		if len(dependents) > 0 {
+			services.Logger.Info("disabled %q in %q along with %q depending on it", api, values.ProjectID, dependents)
+			continue
+		}
+		services.Logger.Info("disabled %q in %q", api, values.ProjectID)
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, s := range list {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
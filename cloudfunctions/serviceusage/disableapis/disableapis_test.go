@@ -0,0 +1,103 @@
+package disableapis
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestDisableAPIs(t *testing.T) {
+	ctx := context.Background()
+	enabled := []string{"compute.googleapis.com", "container.googleapis.com", "iam.googleapis.com", "bigquery.googleapis.com"}
+	for _, tt := range []struct {
+		name          string
+		values        *Values
+		want          []string
+		expectedError error
+		wantError     bool
+	}{
+		{
+			name:   "disable",
+			values: &Values{ProjectID: "test-project", APIs: []string{"compute.googleapis.com", "container.googleapis.com"}},
+			want:   []string{"test-project/compute.googleapis.com", "test-project/container.googleapis.com"},
+		},
+		{
+			name:   "skip protected",
+			values: &Values{ProjectID: "test-project", APIs: []string{"compute.googleapis.com", "iam.googleapis.com", "bigquery.googleapis.com"}, Protected: []string{"bigquery.googleapis.com"}},
+			want:   []string{"test-project/compute.googleapis.com"},
+		},
+		{
+			name:          "already disabled",
+			values:        &Values{ProjectID: "test-project", APIs: []string{"sqladmin.googleapis.com"}},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:          "only protected",
+			values:        &Values{ProjectID: "test-project", APIs: []string{"iam.googleapis.com"}},
+			expectedError: registry.ErrAlreadyRemediated,
+		},
+		{
+			name:      "dependents not disabled",
+			values:    &Values{ProjectID: "test-project", APIs: []string{"bigquery.googleapis.com"}},
+			wantError: true,
+		},
+		{
+			name:   "dependents",
+			values: &Values{ProjectID: "test-project", APIs: []string{"bigquery.googleapis.com"}, DisableDependents: true},
+			want:   []string{"test-project/container.googleapis.com", "test-project/bigquery.googleapis.com"},
+		},
+		{
+			name:   "dependent listed too",
+			values: &Values{ProjectID: "test-project", APIs: []string{"bigquery.googleapis.com", "container.googleapis.com"}, DisableDependents: true},
+			want:   []string{"test-project/container.googleapis.com", "test-project/bigquery.googleapis.com"},
+		},
+		{
+			name:      "protected dependent",
+			values:    &Values{ProjectID: "test-project", APIs: []string{"bigquery.googleapis.com"}, DisableDependents: true, Protected: []string{"container.googleapis.com"}},
+			wantError: true,
+		},
+		{
+			name:   "dry run",
+			values: &Values{ProjectID: "test-project", APIs: []string{"compute.googleapis.com"}, DryRun: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.ServiceUsageStub{
+				StubbedEnabledServices: map[string][]string{"test-project": enabled},
+				StubbedDependents:      map[string][]string{"bigquery.googleapis.com": {"container.googleapis.com"}},
+			}
+			err := Execute(ctx, tt.values, &Services{
+				ServiceUsage: services.NewServiceUsage(stub),
+				Logger:       services.NewLogger(&stubs.LoggerStub{}),
+			})
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("%s got no error", tt.name)
+				}
+			} else if err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.want, stub.DisabledServices); diff != "" {
+				t.Errorf("%s disabled (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
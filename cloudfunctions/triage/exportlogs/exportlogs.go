@@ -0,0 +1,154 @@
+package exportlogs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "export_evidence_logs",
+		EntryPoint:  "ExportEvidenceLogs",
+		Topic:       "threat-findings-export-evidence-logs",
+		Services:    []string{"Logs"},
+		Roles:       []string{"roles/viewer", "roles/logging.privateLogViewer"},
+		Permissions: []string{"logging.logEntries.list", "logging.privateLogEntries.list"},
+	})
+}
+
+const (
+	// defaultWindow is how long before and after the finding entries are exported for by default.
+	defaultWindow = time.Hour
+	// defaultMaxEntries bounds the entries exported of each log by default.
+	defaultMaxEntries = 10000
+)
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// ProjectID is the project of the resource, whose logs are exported.
+	ProjectID string
+	// ResourceName is the full name of the resource the finding is about.
+	ResourceName string
+	// FindingID is the ID of the finding the logs are exported for.
+	FindingID string
+	// FindingTime is when the event reported by the finding occurred, the exported window is
+	// centered on it.
+	FindingTime time.Time
+	// WindowMinutes is how long before and after the finding time entries are exported for, an hour
+	// if 0.
+	WindowMinutes int
+	// MaxEntries bounds the entries exported of each log, 10000 if 0.
+	MaxEntries int
+	DryRun     bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Logs *services.Logs
+	// Archive stores the exported entries in the evidence bucket.
+	Archive *services.Archive
+	Logger  *services.Logger
+}
+
+// export is a log exported for a finding.
+type export struct {
+	name   string
+	filter string
+}
+
+// Execute exports the audit and VPC flow log entries of the finding's resource in the window
+// around the finding time to the evidence bucket, one object of JSON lines per log.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.FindingTime.IsZero() {
+		return fmt.Errorf("finding time is required")
+	}
+	window := defaultWindow
+	if values.WindowMinutes > 0 {
+		window = time.Duration(values.WindowMinutes) * time.Minute
+	}
+	max := values.MaxEntries
+	if max <= 0 {
+		max = defaultMaxEntries
+	}
+	start, end := values.FindingTime.Add(-window), values.FindingTime.Add(window)
+	exports := exportsOf(values.ResourceName, start, end)
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have exported %d logs of %q in project %q from %s to %s", len(exports), values.ResourceName, values.ProjectID, start.Format(time.RFC3339), end.Format(time.RFC3339))
+		return nil
+	}
+	for _, e := range exports {
+		entries, truncated, err := services.Logs.Entries(ctx, values.ProjectID, e.filter, max)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		name := fmt.Sprintf("%s/%s/%s.json", values.ProjectID, values.FindingID, e.name)
+		uri, err := services.Archive.Store(ctx, name, b.Bytes())
+		if err != nil {
+			return err
+		}
+		services.Logger.Info("exported %d %s log entries of %q from %s to %s to %s", len(entries), e.name, values.ResourceName, start.Format(time.RFC3339), end.Format(time.RFC3339), uri)
+		if truncated {
+			services.Logger.Warning("more than %d %s log entries of %q matched, the rest were not exported", max, e.name, values.ResourceName)
+		}
+	}
+	return nil
+}
+
+// exportsOf returns the logs exported for the resource between start and end: audit logs naming
+// it, all of the project's if the resource is a project, and the VPC flows of instances and
+// projects.
+func exportsOf(resourceName string, start, end time.Time) []export {
+	window := fmt.Sprintf("timestamp>=%q AND timestamp<=%q", start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	audit := `logName:"cloudaudit.googleapis.com" AND ` + window
+	flows := `logName:"compute.googleapis.com%2Fvpc_flows" AND ` + window
+	parts := strings.Split(strings.TrimPrefix(resourceName, "//"), "/")
+	var kind, name string
+	switch {
+	case len(parts) == 2 && parts[0] == "storage.googleapis.com":
+		// Buckets are named without a collection, their audit logs name them buckets/<name>.
+		kind, name = "buckets", parts[1]
+	case len(parts) >= 3:
+		kind, name = parts[len(parts)-2], parts[len(parts)-1]
+	default:
+		return []export{{"audit", audit}}
+	}
+	switch kind {
+	case "projects":
+		return []export{{"audit", audit}, {"vpc_flows", flows}}
+	case "instances":
+		return []export{
+			{"audit", fmt.Sprintf("%s AND protoPayload.resourceName:%q", audit, kind+"/"+name)},
+			{"vpc_flows", fmt.Sprintf("%s AND (jsonPayload.src_instance.vm_name=%q OR jsonPayload.dest_instance.vm_name=%q)", flows, name, name)},
+		}
+	default:
+		return []export{{"audit", fmt.Sprintf("%s AND protoPayload.resourceName:%q", audit, kind+"/"+name)}}
+	}
+}
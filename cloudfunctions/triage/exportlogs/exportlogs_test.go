@@ -0,0 +1,95 @@
+package exportlogs
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	cloudlogging "google.golang.org/api/logging/v2"
+)
+
+func TestExportEvidenceLogs(t *testing.T) {
+	ctx := context.Background()
+	findingTime := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	window := `timestamp>="2020-05-01T11:00:00Z" AND timestamp<="2020-05-01T13:00:00Z"`
+	auditFilter := `logName:"cloudaudit.googleapis.com" AND ` + window + ` AND protoPayload.resourceName:"instances/vm"`
+	flowsFilter := `logName:"compute.googleapis.com%2Fvpc_flows" AND ` + window + ` AND (jsonPayload.src_instance.vm_name="vm" OR jsonPayload.dest_instance.vm_name="vm")`
+	tests := []struct {
+		name            string
+		resourceName    string
+		maxEntries      int
+		expectedFilters []string
+		expectedObjects map[string]int
+	}{
+		{
+			name:            "instance",
+			resourceName:    "//compute.googleapis.com/projects/test-project/zones/test-zone/instances/vm",
+			expectedFilters: []string{auditFilter, flowsFilter},
+			expectedObjects: map[string]int{"test-project/abc123/audit.json": 3, "test-project/abc123/vpc_flows.json": 1},
+		},
+		{
+			name:            "entries bounded",
+			resourceName:    "//compute.googleapis.com/projects/test-project/zones/test-zone/instances/vm",
+			maxEntries:      2,
+			expectedFilters: []string{auditFilter, flowsFilter},
+			expectedObjects: map[string]int{"test-project/abc123/audit.json": 2, "test-project/abc123/vpc_flows.json": 1},
+		},
+		{
+			name:            "bucket",
+			resourceName:    "//storage.googleapis.com/bucket",
+			expectedFilters: []string{`logName:"cloudaudit.googleapis.com" AND ` + window + ` AND protoPayload.resourceName:"buckets/bucket"`},
+			expectedObjects: map[string]int{"test-project/abc123/audit.json": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logsStub := &stubs.LogEntriesStub{StubbedEntries: map[string][][]*cloudlogging.LogEntry{
+				auditFilter: {{{InsertId: "1"}, {InsertId: "2"}}, {{InsertId: "3"}}},
+				flowsFilter: {{{InsertId: "4"}}},
+			}}
+			storageStub := &stubs.StorageStub{}
+			values := &Values{
+				ProjectID:    "test-project",
+				ResourceName: tt.resourceName,
+				FindingID:    "abc123",
+				FindingTime:  findingTime,
+				MaxEntries:   tt.maxEntries,
+			}
+			if err := Execute(ctx, values, &Services{
+				Logs:    services.NewLogs(logsStub),
+				Archive: services.NewArchive(storageStub, "evidence"),
+				Logger:  services.NewLogger(&stubs.LoggerStub{}),
+			}); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expectedFilters, logsStub.Filters); diff != "" {
+				t.Errorf("%s filters (-want +got):\n%s", tt.name, diff)
+			}
+			got := make(map[string]int)
+			for name, b := range storageStub.StubbedObjects {
+				got[name] = strings.Count(string(b), "\n")
+			}
+			if diff := cmp.Diff(tt.expectedObjects, got); diff != "" {
+				t.Errorf("%s exported entries (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
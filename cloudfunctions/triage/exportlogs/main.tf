@@ -0,0 +1,82 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+resource "google_cloudfunctions_function" "export-evidence-logs" {
+  name                  = "ExportEvidenceLogs"
+  description           = "Exports the logs of the resource of a finding around the finding time."
+  runtime               = "go113"
+  available_memory_mb   = 256
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 300
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "ExportEvidenceLogs"
+  service_account_email = var.setup.automation-service-account
+
+  event_trigger {
+    event_type = "google.pubsub.topic.publish"
+    resource   = "threat-findings-export-evidence-logs"
+  }
+  environment_variables = {
+    GCP_PROJECT                 = var.setup.automation-project
+    EVIDENCE_BUCKET             = google_storage_bucket.evidence.name
+    IMPERSONATE_SERVICE_ACCOUNT = lookup(var.setup.action-service-accounts, "export_evidence_logs", "")
+    FOLDER_SERVICE_ACCOUNTS     = var.setup.folder-service-accounts
+  }
+  build_environment_variables = {
+    GOFLAGS = "-tags=sra_action,sra_export_evidence_logs"
+  }
+}
+
+# PubSub topic to trigger this automation.
+resource "google_pubsub_topic" "topic" {
+  name    = "threat-findings-export-evidence-logs"
+  project = var.setup.automation-project
+}
+
+# Bucket the exported logs are kept in, versioned so evidence overwritten by another export is kept.
+resource "google_storage_bucket" "evidence" {
+  name     = "${var.setup.automation-project}-evidence"
+  project  = var.setup.automation-project
+  location = var.setup.region
+
+  versioning {
+    enabled = true
+  }
+}
+
+# Required to store the exported logs.
+resource "google_storage_bucket_iam_member" "object-creator" {
+  bucket = google_storage_bucket.evidence.name
+  role   = "roles/storage.objectCreator"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Required to retrieve ancestry for projects within this folder.
+resource "google_folder_iam_member" "roles-viewer" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/viewer"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
+
+# Required to read the Data Access audit logs along with the other logs.
+resource "google_folder_iam_member" "roles-private-log-viewer" {
+  count = length(var.folder-ids)
+
+  folder = "folders/${var.folder-ids[count.index]}"
+  role   = "roles/logging.privateLogViewer"
+  member = "serviceAccount:${var.setup.automation-service-account}"
+}
@@ -0,0 +1,122 @@
+package labelresource
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "label_resource",
+		EntryPoint:  "LabelResource",
+		Topic:       "threat-findings-label-resource",
+		Services:    []string{"Host", "Resource"},
+		Roles:       []string{"roles/viewer", "roles/compute.instanceAdmin.v1", "roles/storage.admin", "roles/resourcemanager.projectMover"},
+		Permissions: []string{"compute.instances.get", "compute.instances.setLabels", "storage.buckets.get", "storage.buckets.update", "resourcemanager.projects.get", "resourcemanager.projects.update"},
+	})
+}
+
+const (
+	// IncidentLabel is the label naming the finding the resource is investigated for.
+	IncidentLabel = "security-incident"
+	// TriageLabel is the label tracking the triage of the resource.
+	TriageLabel = "triage"
+	// maxLabelLength is the longest value a label can have.
+	maxLabelLength = 63
+)
+
+var (
+	instanceRE = regexp.MustCompile(`^//compute\.googleapis\.com/projects/([^/]+)/zones/([^/]+)/instances/([^/]+)$`)
+	bucketRE   = regexp.MustCompile(`^//storage\.googleapis\.com/([^/]+)$`)
+	projectRE  = regexp.MustCompile(`^//cloudresourcemanager\.googleapis\.com/projects/[^/]+$`)
+	invalidRE  = regexp.MustCompile(`[^a-z0-9_-]`)
+)
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// ProjectID is the project of the resource.
+	ProjectID string
+	// ResourceName is the full name of the resource, an instance, bucket or project.
+	ResourceName string
+	// FindingID is the ID of the finding the resource is labeled for.
+	FindingID string
+	// Labels are added to the labels naming the finding and marking its triage as pending,
+	// overriding them if they share a key.
+	Labels map[string]string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Host     *services.Host
+	Resource *services.Resource
+	Logger   *services.Logger
+}
+
+// Execute labels the resource of a finding for investigation without remediating it.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	labels := map[string]string{
+		IncidentLabel: labelValue(values.FindingID),
+		TriageLabel:   "pending",
+	}
+	for k, v := range values.Labels {
+		labels[k] = v
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have labeled %q in project %q with %v", values.ResourceName, values.ProjectID, labels)
+		return nil
+	}
+	var (
+		changed bool
+		err     error
+	)
+	switch name := values.ResourceName; {
+	case instanceRE.MatchString(name):
+		m := instanceRE.FindStringSubmatch(name)
+		changed, err = services.Host.LabelInstance(ctx, m[1], m[2], m[3], labels)
+	case bucketRE.MatchString(name):
+		changed, err = services.Resource.LabelBucket(ctx, bucketRE.FindStringSubmatch(name)[1], labels)
+	case projectRE.MatchString(name):
+		// The name holds the project number, the project is labeled by ID.
+		changed, err = services.Resource.LabelProject(ctx, values.ProjectID, labels)
+	default:
+		return fmt.Errorf("labeling %q is not supported", name)
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("labeled %q in project %q with %v", values.ResourceName, values.ProjectID, labels)
+	return nil
+}
+
+// labelValue returns the ID as a valid label value: lower case letters, digits, underscores and
+// dashes, at most 63 characters.
+func labelValue(id string) string {
+	v := invalidRE.ReplaceAllString(strings.ToLower(id), "-")
+	if len(v) > maxLabelLength {
+		v = v[:maxLabelLength]
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+package labelresource
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestLabelResource(t *testing.T) {
+	ctx := context.Background()
+	want := map[string]string{"security-incident": "abc123", "triage": "pending", "team": "secops"}
+	tests := []struct {
+		name           string
+		resourceName   string
+		instanceLabels map[string]string
+		bucketLabels   map[string]string
+		projectLabels  map[string]string
+		expectedError  error
+	}{
+		{name: "instance", resourceName: "//compute.googleapis.com/projects/test-project/zones/test-zone/instances/vm"},
+		{name: "bucket", resourceName: "//storage.googleapis.com/bucket"},
+		{name: "project", resourceName: "//cloudresourcemanager.googleapis.com/projects/123"},
+		{
+			name:           "instance already labeled",
+			resourceName:   "//compute.googleapis.com/projects/test-project/zones/test-zone/instances/vm",
+			instanceLabels: want,
+			expectedError:  registry.ErrAlreadyRemediated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			computeStub := &stubs.ComputeStub{StubbedInstance: &compute.Instance{Labels: tt.instanceLabels}}
+			storageStub := &stubs.StorageStub{StubbedBucketLabels: tt.bucketLabels}
+			crmStub := &stubs.ResourceManagerStub{StubbedProjects: map[string]*crm.Project{
+				"test-project": {ProjectId: "test-project", Labels: tt.projectLabels},
+			}}
+			s := &Services{
+				Host:     services.NewHost(computeStub),
+				Resource: services.NewResource(crmStub, storageStub),
+				Logger:   services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{
+				ProjectID:    "test-project",
+				ResourceName: tt.resourceName,
+				FindingID:    "ABC123",
+				Labels:       map[string]string{"team": "secops"},
+			}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+			var got map[string]string
+			switch tt.name {
+			case "instance":
+				got = computeStub.SavedInstanceLabels.Labels
+			case "bucket":
+				got = storageStub.SavedBucketLabels
+			case "project":
+				got = crmStub.StubbedProjects["test-project"].Labels
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("%s labels difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	for _, tt := range []struct{ id, want string }{
+		{id: "782e52631d61da6117a3772137c270d8", want: "782e52631d61da6117a3772137c270d8"},
+		{id: "Bad.ID/With Spaces", want: "bad-id-with-spaces"},
+	} {
+		if got := labelValue(tt.id); got != tt.want {
+			t.Errorf("labelValue(%q) got %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package addtoperimeter
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:        "vpcsc_add_to_perimeter",
+		EntryPoint:  "AddToPerimeter",
+		Topic:       "threat-findings-add-to-perimeter",
+		Services:    []string{"Resource", "AccessContextManager"},
+		Roles:       []string{"roles/viewer"},
+		Permissions: []string{"resourcemanager.projects.get"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	ProjectID string
+	// Perimeter is the restricted perimeter, named "accessPolicies/<id>/servicePerimeters/<name>".
+	Perimeter string
+	DryRun    bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	AccessContextManager *services.AccessContextManager
+	Resource             *services.Resource
+	Logger               *services.Logger
+}
+
+// Execute adds the project to the restricted perimeter, cutting off API access to its data from
+// outside of the perimeter.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	project, err := services.Resource.Project(ctx, values.ProjectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get project %q", values.ProjectID)
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have added project %q to perimeter %q", values.ProjectID, values.Perimeter)
+		return nil
+	}
+	added, err := services.AccessContextManager.AddToPerimeter(ctx, values.Perimeter, strconv.FormatInt(project.ProjectNumber, 10))
+	if err != nil {
+		return err
+	}
+	if !added {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("added project %q to perimeter %q", values.ProjectID, values.Perimeter)
+	return nil
+}
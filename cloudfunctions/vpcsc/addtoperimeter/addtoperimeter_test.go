@@ -0,0 +1,65 @@
+package addtoperimeter
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	acm "google.golang.org/api/accesscontextmanager/v1"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+const perimeter = "accessPolicies/123/servicePerimeters/restricted"
+
+func TestAddToPerimeter(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		resources     []string
+		dryRun        bool
+		want          []string
+		expectedError error
+	}{
+		{name: "add", resources: []string{"projects/111"}, want: []string{"projects/111", "projects/222"}},
+		{name: "dry run", resources: []string{"projects/111"}, dryRun: true, want: []string{"projects/111"}},
+		{name: "already protected", resources: []string{"projects/222"}, want: []string{"projects/222"}, expectedError: registry.ErrAlreadyRemediated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			acmStub := &stubs.AccessContextManagerStub{Perimeters: map[string]*acm.ServicePerimeter{
+				perimeter: {Name: perimeter, Status: &acm.ServicePerimeterConfig{Resources: tt.resources}},
+			}}
+			crmStub := &stubs.ResourceManagerStub{StubbedProjects: map[string]*crm.Project{
+				"exfil-project": {ProjectId: "exfil-project", ProjectNumber: 222},
+			}}
+			s := &Services{
+				AccessContextManager: services.NewAccessContextManager(acmStub),
+				Resource:             services.NewResource(crmStub, &stubs.StorageStub{}),
+				Logger:               services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{ProjectID: "exfil-project", Perimeter: perimeter, DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.want, acmStub.Perimeters[perimeter].Status.Resources); diff != "" {
+				t.Errorf("%s perimeter resources (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
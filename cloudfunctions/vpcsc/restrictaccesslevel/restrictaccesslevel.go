@@ -0,0 +1,66 @@
+package restrictaccesslevel
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "vpcsc_restrict_access_level",
+		EntryPoint: "RestrictAccessLevel",
+		Topic:      "threat-findings-restrict-access-level",
+		Services:   []string{"AccessContextManager"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// AccessLevel is the access level, named "accessPolicies/<id>/accessLevels/<name>".
+	AccessLevel string
+	// Members are the principals to exclude, such as "user:tim@example.com".
+	Members []string
+	// IPSubnetworks are the IP ranges to exclude in CIDR notation.
+	IPSubnetworks []string
+	DryRun        bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	AccessContextManager *services.AccessContextManager
+	Logger               *services.Logger
+}
+
+// Execute excludes the principals and IP ranges from the access level, cutting off their access
+// to the services protected by the perimeters admitting it.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have excluded %q and %q from access level %q", values.Members, values.IPSubnetworks, values.AccessLevel)
+		return nil
+	}
+	excluded, err := services.AccessContextManager.ExcludeFromAccessLevel(ctx, values.AccessLevel, values.Members, values.IPSubnetworks)
+	if err != nil {
+		return err
+	}
+	if len(excluded) == 0 {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("excluded %q from access level %q", excluded, values.AccessLevel)
+	return nil
+}
@@ -0,0 +1,62 @@
+package restrictaccesslevel
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	acm "google.golang.org/api/accesscontextmanager/v1"
+)
+
+const accessLevel = "accessPolicies/123/accessLevels/corp"
+
+func TestRestrictAccessLevel(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name           string
+		members        []string
+		dryRun         bool
+		wantConditions int
+		expectedError  error
+	}{
+		{name: "restrict", members: []string{"user:alice@example.com"}, wantConditions: 3},
+		{name: "dry run", members: []string{"user:alice@example.com"}, dryRun: true, wantConditions: 2},
+		{name: "already excluded", members: []string{"user:eve@example.com"}, wantConditions: 2, expectedError: registry.ErrAlreadyRemediated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			acmStub := &stubs.AccessContextManagerStub{AccessLevels: map[string]*acm.AccessLevel{
+				accessLevel: {Name: accessLevel, Basic: &acm.BasicLevel{Conditions: []*acm.Condition{
+					{IpSubnetworks: []string{"198.51.100.0/24"}},
+					{Members: []string{"user:eve@example.com"}, Negate: true},
+				}}},
+			}}
+			s := &Services{
+				AccessContextManager: services.NewAccessContextManager(acmStub),
+				Logger:               services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{AccessLevel: accessLevel, Members: tt.members, DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if n := len(acmStub.AccessLevels[accessLevel].Basic.Conditions); n != tt.wantConditions {
+				t.Errorf("%s got %d conditions, want %d", tt.name, n, tt.wantConditions)
+			}
+		})
+	}
+}
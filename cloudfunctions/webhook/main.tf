@@ -0,0 +1,50 @@
+# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+# The webhook is only deployed when a secret is set.
+resource "google_cloudfunctions_function" "webhook" {
+  count = var.webhook-secret == "" ? 0 : 1
+
+  name                  = "Webhook"
+  description           = "Invokes the automations over HTTP with HMAC signed requests."
+  runtime               = "go113"
+  available_memory_mb   = 256
+  source_archive_bucket = var.setup.gcf-bucket-name
+  source_archive_object = var.setup.gcf-object-name
+  timeout               = 540
+  project               = var.setup.automation-project
+  region                = var.setup.region
+  entry_point           = "Webhook"
+  service_account_email = var.setup.automation-service-account
+  trigger_http          = true
+
+  environment_variables = {
+    GCP_PROJECT    = var.setup.automation-project
+    WEBHOOK_SECRET = var.webhook-secret
+  }
+}
+
+# Callers do not authenticate to Google, requests are verified by their HMAC signature instead.
+resource "google_cloudfunctions_function_iam_member" "invoker" {
+  count = var.webhook-secret == "" ? 0 : 1
+
+  project        = var.setup.automation-project
+  region         = var.setup.region
+  cloud_function = google_cloudfunctions_function.webhook[0].name
+  role           = "roles/cloudfunctions.invoker"
+  member         = "allUsers"
+}
+
+output "url" {
+  value = var.webhook-secret == "" ? "" : google_cloudfunctions_function.webhook[0].https_trigger_url
+}
@@ -0,0 +1,92 @@
+package webhook
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Paths of the endpoints served by Probes.
+const (
+	HealthPath  = "/healthz"
+	ReadyPath   = "/readyz"
+	VersionPath = "/version"
+)
+
+// Version describes the deployed build and the actions it runs, served at /version.
+type Version struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit"`
+	BuildTime string   `json:"buildTime"`
+	Actions   []string `json:"actions"`
+}
+
+// Probes serves the health, readiness and version endpoints of an HTTP deployment. They are not
+// signed so load balancers and uptime checks can call them.
+type Probes struct {
+	// Ready returns an error if the clients or configuration the entry points need failed to load.
+	Ready func(context.Context) error
+	// Version returns the version served at /version.
+	Version func(context.Context) (*Version, error)
+}
+
+// Handles reports whether the request is for one of the probes.
+func (p *Probes) Handles(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	switch r.URL.Path {
+	case HealthPath, ReadyPath, VersionPath:
+		return true
+	}
+	return false
+}
+
+// ServeHTTP serves the probe the request is for: /healthz answers as long as the function runs,
+// /readyz once it is ready and /version with its version.
+func (p *Probes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case HealthPath:
+		w.Write([]byte("ok\n"))
+	case ReadyPath:
+		if p.Ready != nil {
+			if err := p.Ready(r.Context()); err != nil {
+				log.Printf("not ready: %q", err)
+				http.Error(w, "not ready", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.Write([]byte("ok\n"))
+	case VersionPath:
+		v := &Version{}
+		if p.Version != nil {
+			var err error
+			if v, err = p.Version(r.Context()); err != nil {
+				log.Printf("failed to get version: %q", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			log.Printf("failed to write version: %q", err)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
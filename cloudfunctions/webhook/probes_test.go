@@ -0,0 +1,69 @@
+package webhook
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbes(t *testing.T) {
+	test := []struct {
+		name           string
+		method         string
+		path           string
+		ready          error
+		expectedHandle bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "health", method: http.MethodGet, path: HealthPath, expectedHandle: true, expectedStatus: http.StatusOK, expectedBody: "ok\n"},
+		{name: "health while not ready", method: http.MethodGet, path: HealthPath, ready: errors.New("no config"), expectedHandle: true, expectedStatus: http.StatusOK, expectedBody: "ok\n"},
+		{name: "ready", method: http.MethodGet, path: ReadyPath, expectedHandle: true, expectedStatus: http.StatusOK, expectedBody: "ok\n"},
+		{name: "not ready", method: http.MethodGet, path: ReadyPath, ready: errors.New("no config"), expectedHandle: true, expectedStatus: http.StatusServiceUnavailable, expectedBody: "not ready\n"},
+		{name: "version", method: http.MethodGet, path: VersionPath, expectedHandle: true, expectedStatus: http.StatusOK, expectedBody: `{"version":"v1.2.0","commit":"abc123","buildTime":"2021-06-01T00:00:00Z","actions":["close_bucket"]}` + "\n"},
+		{name: "entry point", method: http.MethodPost, path: "/Router"},
+		{name: "post to probe", method: http.MethodPost, path: HealthPath},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Probes{
+				Ready: func(context.Context) error { return tt.ready },
+				Version: func(context.Context) (*Version, error) {
+					return &Version{Version: "v1.2.0", Commit: "abc123", BuildTime: "2021-06-01T00:00:00Z", Actions: []string{"close_bucket"}}, nil
+				},
+			}
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(""))
+			if got := p.Handles(r); got != tt.expectedHandle {
+				t.Fatalf("%s got handles %v want %v", tt.name, got, tt.expectedHandle)
+			}
+			if !tt.expectedHandle {
+				return
+			}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, r)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("%s got status %d want %d", tt.name, w.Code, tt.expectedStatus)
+			}
+			if got := w.Body.String(); got != tt.expectedBody {
+				t.Errorf("%s got body %q want %q", tt.name, got, tt.expectedBody)
+			}
+		})
+	}
+}
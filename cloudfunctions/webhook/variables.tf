@@ -0,0 +1,6 @@
+variable "setup" {}
+
+variable "webhook-secret" {
+  type        = string
+  description = "Shared secret used to sign webhook requests. The webhook is not deployed if empty."
+}
@@ -0,0 +1,122 @@
+// Package webhook invokes the Pub/Sub entry points over HTTP.
+//
+// Callers POST the message body to /<EntryPoint>, e.g. /Router, and sign the request with a
+// shared secret. The X-SRA-Timestamp header holds the Unix time of the request and the
+// X-SRA-Signature header holds "sha256=" followed by the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>". Requests older than five minutes are rejected to limit replays.
+//
+// Probes serves the unsigned health, readiness and version endpoints alongside the entry points.
+package webhook
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+const (
+	// TimestampHeader holds the Unix time the request was signed at.
+	TimestampHeader = "X-SRA-Timestamp"
+	// SignatureHeader holds the HMAC signature of the request.
+	SignatureHeader = "X-SRA-Signature"
+	// maxAge is how long a signed request is accepted for.
+	maxAge = 5 * time.Minute
+	// maxBodySize is the largest accepted request body.
+	maxBodySize = 1 << 20
+)
+
+// Handler is a Pub/Sub entry point.
+type Handler func(context.Context, pubsub.Message) error
+
+// Webhook serves the entry points over HTTP.
+type Webhook struct {
+	secret   []byte
+	handlers map[string]Handler
+	now      func() time.Time
+}
+
+// New returns a Webhook verifying requests with the secret and dispatching them to the handlers
+// keyed by entry point name.
+func New(secret string, handlers map[string]Handler) (*Webhook, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("webhook secret is not set")
+	}
+	return &Webhook{secret: []byte(secret), handlers: handlers, now: time.Now}, nil
+}
+
+// ServeHTTP verifies the request and runs the entry point named by its path.
+func (h *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.Trim(r.URL.Path, "/")
+	handler, ok := h.handlers[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("entry point %q not found", name), http.StatusNotFound)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body); err != nil {
+		log.Printf("rejected request to %q: %q", name, err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if err := handler(r.Context(), pubsub.Message{Data: body}); err != nil {
+		log.Printf("entry point %q failed: %q", name, err)
+		http.Error(w, "entry point failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Sign returns the signature of the body at the given Unix time.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *Webhook) verify(timestamp, signature string, body []byte) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if age := h.now().Sub(time.Unix(sec, 0)); age > maxAge || age < -maxAge {
+		return fmt.Errorf("timestamp %q is outside the accepted window", timestamp)
+	}
+	want := Sign(string(h.secret), timestamp, body)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package webhook
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+)
+
+const secret = "s3cr3t"
+
+func TestWebhook(t *testing.T) {
+	now := time.Unix(1622559845, 0)
+	body := []byte(`{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	stale := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+	test := []struct {
+		name           string
+		method         string
+		path           string
+		timestamp      string
+		signature      string
+		expectedStatus int
+		expectedData   []byte
+	}{
+		{name: "valid request", method: http.MethodPost, path: "/Router", timestamp: ts, signature: Sign(secret, ts, body), expectedStatus: http.StatusNoContent, expectedData: body},
+		{name: "wrong secret", method: http.MethodPost, path: "/Router", timestamp: ts, signature: Sign("other", ts, body), expectedStatus: http.StatusUnauthorized},
+		{name: "missing signature", method: http.MethodPost, path: "/Router", timestamp: ts, expectedStatus: http.StatusUnauthorized},
+		{name: "stale timestamp", method: http.MethodPost, path: "/Router", timestamp: stale, signature: Sign(secret, stale, body), expectedStatus: http.StatusUnauthorized},
+		{name: "unknown entry point", method: http.MethodPost, path: "/Unknown", timestamp: ts, signature: Sign(secret, ts, body), expectedStatus: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodGet, path: "/Router", expectedStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			h, err := New(secret, map[string]Handler{
+				"Router": func(ctx context.Context, m pubsub.Message) error {
+					got = m.Data
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			h.now = func() time.Time { return now }
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body))
+			req.Header.Set(TimestampHeader, tt.timestamp)
+			req.Header.Set(SignatureHeader, tt.signature)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("%s failed, got status: %d want: %d", tt.name, rec.Code, tt.expectedStatus)
+			}
+			if diff := cmp.Diff(tt.expectedData, got); diff != "" {
+				t.Errorf("%s failed, difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package enforce2sv
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "workspace_enforce_2sv",
+		EntryPoint: "Enforce2SV",
+		Topic:      "threat-findings-enforce-2sv",
+		Services:   []string{"Directory"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// Email is the primary email address of the user.
+	Email string
+	// OrgUnit is the path of an organizational unit enforcing 2-Step Verification.
+	OrgUnit string
+	// Group is the email address of a group enforcing 2-Step Verification.
+	Group  string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Directory *services.Directory
+	Logger    *services.Logger
+}
+
+// Execute moves the user into the organizational unit, or adds them to the group, enforcing
+// 2-Step Verification. Users 2-Step Verification is already enforced for are left as they are.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	enforced, err := services.Directory.Enforced2SV(ctx, values.Email)
+	if err != nil {
+		return err
+	}
+	if enforced {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have enforced 2-Step Verification for user %q", values.Email)
+		return nil
+	}
+	if values.OrgUnit != "" {
+		moved, err := services.Directory.MoveToOrgUnit(ctx, values.Email, values.OrgUnit)
+		if err != nil {
+			return err
+		}
+		if moved {
+			services.Logger.Info("moved user %q to organizational unit %q", values.Email, values.OrgUnit)
+		}
+	}
+	if values.Group != "" {
+		if err := services.Directory.AddToGroup(ctx, values.Group, values.Email); err != nil {
+			return err
+		}
+		services.Logger.Info("added user %q to group %q", values.Email, values.Group)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package enforce2sv
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestEnforce2SV(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		enforced      bool
+		orgUnit       string
+		group         string
+		dryRun        bool
+		wantOrgUnit   string
+		wantInserted  map[string][]string
+		expectedError error
+	}{
+		{name: "org unit", orgUnit: "/2SV enforced", wantOrgUnit: "/2SV enforced"},
+		{
+			name:         "group",
+			group:        "2sv-enforced@example.com",
+			wantOrgUnit:  "/",
+			wantInserted: map[string][]string{"2sv-enforced@example.com": {"alice@example.com"}},
+		},
+		{name: "dry run", orgUnit: "/2SV enforced", dryRun: true, wantOrgUnit: "/"},
+		{name: "already enforced", enforced: true, orgUnit: "/2SV enforced", wantOrgUnit: "/", expectedError: registry.ErrAlreadyRemediated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			directoryStub := &stubs.DirectoryStub{Users: map[string]*admin.User{
+				"alice@example.com": {PrimaryEmail: "alice@example.com", OrgUnitPath: "/", IsEnforcedIn2Sv: tt.enforced},
+			}}
+			s := &Services{
+				Directory: services.NewDirectory(directoryStub),
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{Email: "alice@example.com", OrgUnit: tt.orgUnit, Group: tt.group, DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if got := directoryStub.Users["alice@example.com"].OrgUnitPath; got != tt.wantOrgUnit {
+				t.Errorf("%s org unit got %q, want %q", tt.name, got, tt.wantOrgUnit)
+			}
+			if diff := cmp.Diff(tt.wantInserted, directoryStub.InsertedMembers); diff != "" {
+				t.Errorf("%s inserted members (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
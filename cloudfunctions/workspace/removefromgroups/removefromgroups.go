@@ -0,0 +1,64 @@
+package removefromgroups
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "workspace_remove_from_groups",
+		EntryPoint: "RemoveFromGroups",
+		Topic:      "threat-findings-remove-from-groups",
+		Services:   []string{"Directory"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// Email is the primary email address of the user.
+	Email string
+	// Groups are the email addresses of the privileged groups to remove the user from.
+	Groups []string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Directory *services.Directory
+	Logger    *services.Logger
+}
+
+// Execute removes the user from the privileged groups they are a direct member of. Access granted
+// through these groups does not show in the IAM bindings of projects naming the user.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have removed user %q from groups %q", values.Email, values.Groups)
+		return nil
+	}
+	removed, err := services.Directory.RemoveFromPrivilegedGroups(ctx, values.Email, values.Groups)
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return registry.ErrAlreadyRemediated
+	}
+	services.Logger.Info("removed user %q from groups %q", values.Email, removed)
+	return nil
+}
@@ -0,0 +1,62 @@
+package removefromgroups
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestRemoveFromGroups(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		groups        []string
+		dryRun        bool
+		wantDeleted   map[string][]string
+		expectedError error
+	}{
+		{
+			name:        "remove",
+			groups:      []string{"gcp-organization-admins@example.com"},
+			wantDeleted: map[string][]string{"gcp-organization-admins@example.com": {"alice@example.com"}},
+		},
+		{name: "dry run", groups: []string{"gcp-organization-admins@example.com"}, dryRun: true},
+		{name: "not a member", groups: []string{"gcp-billing-admins@example.com"}, expectedError: registry.ErrAlreadyRemediated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			directoryStub := &stubs.DirectoryStub{Groups: map[string][]*admin.Group{
+				"alice@example.com": {{Email: "sre@example.com"}, {Email: "gcp-organization-admins@example.com"}},
+			}}
+			s := &Services{
+				Directory: services.NewDirectory(directoryStub),
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{Email: "alice@example.com", Groups: tt.groups, DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.wantDeleted, directoryStub.DeletedMembers); diff != "" {
+				t.Errorf("%s deleted members (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package revokesessions
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "workspace_revoke_sessions",
+		EntryPoint: "RevokeSessions",
+		Topic:      "threat-findings-revoke-sessions",
+		Services:   []string{"Directory"},
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// Email is the primary email address of the user.
+	Email  string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Directory *services.Directory
+	Logger    *services.Logger
+}
+
+// Execute signs the user out of every session and revokes the OAuth tokens they granted, the user
+// can sign in again.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have revoked the sessions and tokens of user %q", values.Email)
+		return nil
+	}
+	if err := services.Directory.SignOut(ctx, values.Email); err != nil {
+		return err
+	}
+	revoked, err := services.Directory.RevokeTokens(ctx, values.Email)
+	if err != nil {
+		return err
+	}
+	services.Logger.Info("signed out user %q and revoked tokens of %d applications: %q", values.Email, len(revoked), revoked)
+	return nil
+}
@@ -0,0 +1,58 @@
+package revokesessions
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestRevokeSessions(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		dryRun        bool
+		wantSignedOut []string
+		wantTokens    int
+	}{
+		{name: "revoke", wantSignedOut: []string{"alice@example.com"}},
+		{name: "dry run", dryRun: true, wantTokens: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			directoryStub := &stubs.DirectoryStub{Tokens: map[string][]*admin.Token{
+				"alice@example.com": {{ClientId: "mail-client"}, {ClientId: "drive-sync"}},
+			}}
+			s := &Services{
+				Directory: services.NewDirectory(directoryStub),
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{Email: "alice@example.com", DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.wantSignedOut, directoryStub.SignedOut); diff != "" {
+				t.Errorf("%s signed out (-want +got):\n%s", tt.name, diff)
+			}
+			if got := len(directoryStub.Tokens["alice@example.com"]); got != tt.wantTokens {
+				t.Errorf("%s got %d tokens left, want %d", tt.name, got, tt.wantTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package suspenduser
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func init() {
+	registry.Register(registry.Action{
+		Name:       "workspace_suspend_user",
+		EntryPoint: "SuspendUser",
+		Topic:      "threat-findings-suspend-user",
+		Services:   []string{"Directory"},
+		Approval:   true,
+	})
+}
+
+// Values contains the required values needed for this function.
+type Values struct {
+	// Email is the primary email address of the user.
+	Email  string
+	DryRun bool
+}
+
+// Services contains the services needed for this function.
+type Services struct {
+	Directory *services.Directory
+	Logger    *services.Logger
+}
+
+// Execute suspends the Google Workspace or Cloud Identity user.
+func Execute(ctx context.Context, values *Values, services *Services) error {
+	suspended, err := services.Directory.Suspended(ctx, values.Email)
+	if err != nil {
+		return err
+	}
+	if suspended {
+		return registry.ErrAlreadyRemediated
+	}
+	if values.DryRun {
+		services.Logger.Info("dry_run on, would have suspended user %q", values.Email)
+		return nil
+	}
+	if err := services.Directory.SuspendUser(ctx, values.Email); err != nil {
+		return err
+	}
+	services.Logger.Info("suspended user %q", values.Email)
+	return nil
+}
@@ -0,0 +1,57 @@
+package suspenduser
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestSuspendUser(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name          string
+		suspended     bool
+		dryRun        bool
+		wantSuspended bool
+		expectedError error
+	}{
+		{name: "suspend", wantSuspended: true},
+		{name: "dry run", dryRun: true},
+		{name: "already suspended", suspended: true, wantSuspended: true, expectedError: registry.ErrAlreadyRemediated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			directoryStub := &stubs.DirectoryStub{Users: map[string]*admin.User{
+				"alice@example.com": {PrimaryEmail: "alice@example.com", Suspended: tt.suspended},
+			}}
+			s := &Services{
+				Directory: services.NewDirectory(directoryStub),
+				Logger:    services.NewLogger(&stubs.LoggerStub{}),
+			}
+			values := &Values{Email: "alice@example.com", DryRun: tt.dryRun}
+			if err := Execute(ctx, values, s); err != tt.expectedError {
+				t.Fatalf("%s got %q, want %q", tt.name, err, tt.expectedError)
+			}
+			if got := directoryStub.Users["alice@example.com"].Suspended; got != tt.wantSuspended {
+				t.Errorf("%s suspended got %t, want %t", tt.name, got, tt.wantSuspended)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Command generate-roles writes least privileged custom roles for the actions of the router's
+// configuration, to grant the automation service account instead of broad predefined roles.
+//
+// A role holding exactly the permissions each enabled action uses is written to <action>.yaml,
+// to create with gcloud, and all of them to roles.tf with their grants on the targeted folders:
+//
+//	go run ./cmd/generate-roles -organization 456 -project my-automation-project -out roles
+//	gcloud iam roles create sraCloseBucket --organization 456 --file roles/close_bucket.yaml
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+)
+
+func main() {
+	config := flag.String("config", "config/sra.yaml", "path of the router's configuration")
+	organization := flag.String("organization", "", "ID of the organization the roles are created in")
+	project := flag.String("project", "", "project the automations are deployed to")
+	serviceAccount := flag.String("service-account", "", "email of the automation service account, defaults to the one created in the project")
+	out := flag.String("out", "roles", "directory the roles are written to")
+	flag.Parse()
+	if *organization == "" {
+		log.Fatalf("-organization is required")
+	}
+	if *serviceAccount == "" {
+		if *project == "" {
+			log.Fatalf("either -project or -service-account is required")
+		}
+		*serviceAccount = fmt.Sprintf("automation-service-account@%s.iam.gserviceaccount.com", *project)
+	}
+	conf, err := router.ReadConfig(*config)
+	if err != nil {
+		log.Fatalf("failed to read %s: %q", *config, err)
+	}
+	roles, skipped, err := customRoles(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range roles {
+		if err := write(filepath.Join(*out, r.Action+".yaml"), func(f *os.File) error { return writeRole(f, r) }); err != nil {
+			log.Fatal(err)
+		}
+	}
+	member := "serviceAccount:" + *serviceAccount
+	if err := write(filepath.Join(*out, "roles.tf"), func(f *os.File) error { return writeTerraform(f, roles, *organization, member) }); err != nil {
+		log.Fatal(err)
+	}
+	for _, a := range skipped {
+		fmt.Printf("%s: no Cloud IAM permissions needed on the targeted folders, skipped\n", a)
+	}
+	fmt.Printf("%d roles written to %s\n", len(roles), *out)
+}
+
+// write creates the file at path and writes it with fn.
+func write(path string, fn func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %q", path, err)
+	}
+	return f.Close()
+}
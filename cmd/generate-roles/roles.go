@@ -0,0 +1,163 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+)
+
+// customRole is the least privileged role of an action and where it is granted.
+type customRole struct {
+	// Action is the name of the action the role is for.
+	Action string
+	// ID is the ID of the role in the organization, such as "sraCloseBucket".
+	ID          string
+	Title       string
+	Description string
+	// Permissions are the permissions of the action, sorted.
+	Permissions []string
+	// Grants are the organizations, folders and projects the action targets, such as "folders/123".
+	Grants []string
+}
+
+// customRoles returns a role for each enabled action of the configuration, and the names of
+// those needing no Cloud IAM permissions on the resources they target, such as AWS actions.
+func customRoles(conf *router.Configuration) ([]*customRole, []string, error) {
+	enabled := make(map[string]bool)
+	for _, a := range conf.EnabledActions() {
+		enabled[a] = true
+	}
+	byAction := make(map[string]*customRole)
+	skipped := make(map[string]bool)
+	for _, as := range conf.Automations() {
+		for _, a := range as {
+			action, ok := registry.Lookup(a.Action)
+			if !ok {
+				return nil, nil, fmt.Errorf("%q is not a registered action", a.Action)
+			}
+			if !enabled[a.Action] {
+				continue
+			}
+			if len(action.Permissions) == 0 {
+				skipped[a.Action] = true
+				continue
+			}
+			r, ok := byAction[a.Action]
+			if !ok {
+				r = &customRole{
+					Action:      a.Action,
+					ID:          roleID(a.Action),
+					Title:       "SRA " + a.Action,
+					Description: fmt.Sprintf("Permissions the %s action of Security Response Automation uses.", a.Action),
+					Permissions: dedupe(action.Permissions),
+				}
+				byAction[a.Action] = r
+			}
+			r.Grants = dedupe(append(r.Grants, a.Resources()...))
+		}
+	}
+	roles := make([]*customRole, 0, len(byAction))
+	for _, r := range byAction {
+		roles = append(roles, r)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Action < roles[j].Action })
+	var names []string
+	for a := range skipped {
+		names = append(names, a)
+	}
+	sort.Strings(names)
+	return roles, names, nil
+}
+
+// roleID returns the ID of the action's role, "sra" followed by the action in camel case.
+func roleID(action string) string {
+	id := "sra"
+	for _, w := range strings.Split(action, "_") {
+		if w != "" {
+			id += strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return id
+}
+
+// dedupe returns the values sorted without duplicates.
+func dedupe(values []string) []string {
+	seen := make(map[string]bool)
+	var l []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			l = append(l, v)
+		}
+	}
+	sort.Strings(l)
+	return l
+}
+
+// writeRole writes the role in the format of `gcloud iam roles create --file`.
+func writeRole(w io.Writer, r *customRole) error {
+	if _, err := fmt.Fprintf(w, "title: %s\ndescription: %s\nstage: GA\nincludedPermissions:\n", r.Title, r.Description); err != nil {
+		return err
+	}
+	for _, p := range r.Permissions {
+		if _, err := fmt.Fprintf(w, "- %s\n", p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTerraform writes the roles as organization custom roles granted to the member on the
+// resources their actions target.
+func writeTerraform(w io.Writer, roles []*customRole, organization, member string) error {
+	var b strings.Builder
+	for i, r := range roles {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "resource \"google_organization_iam_custom_role\" %q {\n", r.Action)
+		fmt.Fprintf(&b, "  org_id      = %q\n", organization)
+		fmt.Fprintf(&b, "  role_id     = %q\n", r.ID)
+		fmt.Fprintf(&b, "  title       = %q\n", r.Title)
+		fmt.Fprintf(&b, "  description = %q\n", r.Description)
+		b.WriteString("  permissions = [\n")
+		for _, p := range r.Permissions {
+			fmt.Fprintf(&b, "    %q,\n", p)
+		}
+		b.WriteString("  ]\n}\n")
+		for _, g := range r.Grants {
+			kind, id := g[:strings.Index(g, "/")], g[strings.Index(g, "/")+1:]
+			name := fmt.Sprintf("%s_%s_%s", r.Action, kind, id)
+			role := fmt.Sprintf("google_organization_iam_custom_role.%s.name", r.Action)
+			b.WriteString("\n")
+			switch kind {
+			case "organizations":
+				fmt.Fprintf(&b, "resource \"google_organization_iam_member\" %q {\n  org_id = %q\n  role   = %s\n  member = %q\n}\n", name, id, role, member)
+			case "folders":
+				fmt.Fprintf(&b, "resource \"google_folder_iam_member\" %q {\n  folder = %q\n  role   = %s\n  member = %q\n}\n", name, g, role, member)
+			case "projects":
+				fmt.Fprintf(&b, "resource \"google_project_iam_member\" %q {\n  project = %q\n  role    = %s\n  member  = %q\n}\n", name, id, role, member)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
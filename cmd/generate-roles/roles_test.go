@@ -0,0 +1,100 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+)
+
+func TestCustomRoles(t *testing.T) {
+	conf := &router.Configuration{}
+	conf.Spec.Enabled = []string{"close_bucket", "aws_isolate_instance"}
+	conf.Spec.Parameters.SHA.PublicBucketACL = []router.Automation{
+		{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*", "organizations/456/folders/789/projects/my-project"}},
+		{Action: "close_bucket", FolderIDs: []string{"123"}},
+		{Action: "enable_bucket_only_policy", Target: []string{"organizations/456/*"}},
+	}
+	conf.Spec.Parameters.SHA.OpenFirewall = []router.Automation{
+		{Action: "aws_isolate_instance", Target: []string{"organizations/456/*"}},
+	}
+	roles, skipped, err := customRoles(conf)
+	if err != nil {
+		t.Fatalf("customRoles failed: %q", err)
+	}
+	expected := []*customRole{{
+		Action:      "close_bucket",
+		ID:          "sraCloseBucket",
+		Title:       "SRA close_bucket",
+		Description: "Permissions the close_bucket action of Security Response Automation uses.",
+		Permissions: []string{"storage.buckets.get", "storage.buckets.getIamPolicy", "storage.buckets.setIamPolicy"},
+		Grants:      []string{"folders/123", "projects/my-project"},
+	}}
+	if diff := cmp.Diff(expected, roles); diff != "" {
+		t.Errorf("roles difference:%+v", diff)
+	}
+	if diff := cmp.Diff([]string{"aws_isolate_instance"}, skipped); diff != "" {
+		t.Errorf("skipped difference:%+v", diff)
+	}
+}
+
+func TestCustomRolesUnknownAction(t *testing.T) {
+	conf := &router.Configuration{}
+	conf.Spec.Parameters.SHA.PublicBucketACL = []router.Automation{{Action: "close_everything"}}
+	if _, _, err := customRoles(conf); err == nil {
+		t.Errorf("unknown action should fail")
+	}
+}
+
+func TestWriteTerraform(t *testing.T) {
+	roles := []*customRole{{
+		Action:      "close_bucket",
+		ID:          "sraCloseBucket",
+		Title:       "SRA close_bucket",
+		Description: "Permissions the close_bucket action of Security Response Automation uses.",
+		Permissions: []string{"storage.buckets.get"},
+		Grants:      []string{"folders/123"},
+	}}
+	var b bytes.Buffer
+	if err := writeTerraform(&b, roles, "456", "serviceAccount:sa@p.iam.gserviceaccount.com"); err != nil {
+		t.Fatalf("writeTerraform failed: %q", err)
+	}
+	for _, want := range []string{
+		`resource "google_organization_iam_custom_role" "close_bucket" {`,
+		`  role_id     = "sraCloseBucket"`,
+		`    "storage.buckets.get",`,
+		`resource "google_folder_iam_member" "close_bucket_folders_123" {`,
+		`  role   = google_organization_iam_custom_role.close_bucket.name`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("terraform is missing %q:\n%s", want, b.String())
+		}
+	}
+}
+
+// TestPermissions checks every action granted roles on the targeted folders lists the permissions
+// its custom role is generated from.
+func TestPermissions(t *testing.T) {
+	for _, a := range registry.Actions() {
+		if len(a.Roles) > 0 && len(a.Permissions) == 0 {
+			t.Errorf("%s has roles but no permissions", a.Name)
+		}
+	}
+}
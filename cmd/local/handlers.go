@@ -0,0 +1,316 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/billing/detachbilling"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/removepublic"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/requiressl"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/updatepassword"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/requireoslogin"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/cordonnode"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/disabledashboard"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/isolatepod"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/removepublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/rotatekey"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/resourcemanager/shutdownproject"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/secretmanager/closesecret"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/serviceusage/disableapis"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/triage/labelresource"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// handlers runs the actions whose services can be built locally, keyed by action name.
+var handlers = map[string]func(context.Context, []byte, *services.Global) error{
+	"close_bucket": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values closebucket.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return closebucket.Execute(ctx, &values, &closebucket.Services{Resource: s.Resource, Logger: s.Logger})
+	},
+	"close_secret": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values closesecret.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return closesecret.Execute(ctx, &values, &closesecret.Services{SecretManager: s.SecretManager, Logger: s.Logger})
+	},
+	"detach_billing": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values detachbilling.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return detachbilling.Execute(ctx, &values, &detachbilling.Services{Billing: s.Billing, Logger: s.Logger})
+	},
+	"disable_apis": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values disableapis.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return disableapis.Execute(ctx, &values, &disableapis.Services{ServiceUsage: s.ServiceUsage, Logger: s.Logger})
+	},
+	"shutdown_project": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values shutdownproject.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return shutdownproject.Execute(ctx, &values, &shutdownproject.Services{Resource: s.Resource, Logger: s.Logger})
+	},
+	"enable_bucket_only_policy": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values enablebucketonlypolicy.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return enablebucketonlypolicy.Execute(ctx, &values, &enablebucketonlypolicy.Services{Resource: s.Resource, Logger: s.Logger})
+	},
+	"gce_quarantine_instance": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values quarantineinstance.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return quarantineinstance.Execute(ctx, &values, &quarantineinstance.Services{Host: s.Host, Firewall: s.Firewall, Resource: s.Resource, Logger: s.Logger})
+	},
+	"gce_block_ip": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values blockip.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return blockip.Execute(ctx, &values, &blockip.Services{Firewall: s.Firewall, Resource: s.Resource, Logger: s.Logger})
+	},
+	"remove_public_ip": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values removepublicip.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return removepublicip.Execute(ctx, &values, &removepublicip.Services{Host: s.Host, Resource: s.Resource, Logger: s.Logger})
+	},
+	"gce_require_os_login": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values requireoslogin.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return requireoslogin.Execute(ctx, &values, &requireoslogin.Services{OrgPolicy: s.OrgPolicy, Logger: s.Logger})
+	},
+	"kms_rotate_key": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values rotatekey.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return rotatekey.Execute(ctx, &values, &rotatekey.Services{KMS: s.KMS, Logger: s.Logger})
+	},
+	"kms_remove_public_access": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values removepublicaccess.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return removepublicaccess.Execute(ctx, &values, &removepublicaccess.Services{KMS: s.KMS, Logger: s.Logger})
+	},
+	"remediate_firewall": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values openfirewall.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return openfirewall.Execute(ctx, &values, &openfirewall.Services{Firewall: s.Firewall, Resource: s.Resource, Logger: s.Logger})
+	},
+	"gce_create_disk_snapshot": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values createsnapshot.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		_, err := createsnapshot.Execute(ctx, &values, &createsnapshot.Services{Host: s.Host, Resource: s.Resource, Logger: s.Logger})
+		return err
+	},
+	"enable_audit_logs": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values enableauditlogs.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return enableauditlogs.Execute(ctx, &values, &enableauditlogs.Services{Resource: s.Resource, Logger: s.Logger})
+	},
+	"close_cloud_sql": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values removepublic.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return removepublic.Execute(ctx, &values, &removepublic.Services{CloudSQL: s.CloudSQL, Resource: s.Resource, Logger: s.Logger})
+	},
+	"cloud_sql_require_ssl": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values requiressl.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return requiressl.Execute(ctx, &values, &requiressl.Services{CloudSQL: s.CloudSQL, Resource: s.Resource, Logger: s.Logger})
+	},
+	"cloud_sql_update_password": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values updatepassword.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return updatepassword.Execute(ctx, &values, &updatepassword.Services{CloudSQL: s.CloudSQL, Resource: s.Resource, Logger: s.Logger})
+	},
+	"label_resource": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values labelresource.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return labelresource.Execute(ctx, &values, &labelresource.Services{Host: s.Host, Resource: s.Resource, Logger: s.Logger})
+	},
+	"disable_dashboard": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values disabledashboard.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return disabledashboard.Execute(ctx, &values, &disabledashboard.Services{Container: s.Container, Resource: s.Resource, Logger: s.Logger})
+	},
+	"gke_isolate_pod": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values isolatepod.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return isolatepod.Execute(ctx, &values, &isolatepod.Services{Kubernetes: s.Kubernetes, Resource: s.Resource, Logger: s.Logger})
+	},
+	"gke_cordon_node": func(ctx context.Context, b []byte, s *services.Global) error {
+		var values cordonnode.Values
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		return cordonnode.Execute(ctx, &values, &cordonnode.Services{Kubernetes: s.Kubernetes, Resource: s.Resource, Logger: s.Logger})
+	},
+}
+
+// message is a message the router would have published.
+type message struct {
+	topic string
+	data  []byte
+}
+
+// runner runs handlers with the given services, recording what the router publishes instead of
+// publishing it.
+type runner struct {
+	services  *services.Global
+	dryRun    bool
+	topic     string
+	published []message
+}
+
+// Topic remembers the topic to record the next published message under.
+func (r *runner) Topic(id string) *pubsub.Topic {
+	r.topic = id
+	return nil
+}
+
+// Publish records the message instead of publishing it.
+func (r *runner) Publish(ctx context.Context, topic *pubsub.Topic, m *pubsub.Message) (string, error) {
+	r.published = append(r.published, message{topic: r.topic, data: m.Data})
+	return fmt.Sprintf("local-%d", len(r.published)), nil
+}
+
+// route runs the router on the finding then runs the actions it routed the finding to.
+func (r *runner) route(ctx context.Context, conf *router.Configuration, finding []byte) error {
+	if err := router.Execute(ctx, &router.Values{Finding: finding}, &router.Services{
+		PubSub:                services.NewPubSub(r),
+		Configuration:         conf,
+		Logger:                r.services.Logger,
+		Resource:              r.services.Resource,
+		SecurityCommandCenter: r.services.SecurityCommandCenter,
+	}); err != nil {
+		return err
+	}
+	for _, m := range r.published {
+		fmt.Printf("published to %s: %s\n", m.topic, m.data)
+		name := actionOf(m.topic)
+		if name == "" {
+			continue
+		}
+		if err := r.run(ctx, name, m.data); err != nil {
+			return fmt.Errorf("%s: %q", name, err)
+		}
+	}
+	return nil
+}
+
+// run runs the named action on its values, in dry run if the runner is.
+func (r *runner) run(ctx context.Context, name string, values []byte) error {
+	h, ok := handlers[name]
+	if !ok {
+		fmt.Printf("%s is not supported locally, values: %s\n", name, values)
+		return nil
+	}
+	if r.dryRun {
+		b, err := withDryRun(values)
+		if err != nil {
+			return err
+		}
+		values = b
+	}
+	return h(ctx, values, r.services)
+}
+
+// actionOf returns the name of the action triggered by the topic, empty if there is none.
+func actionOf(topic string) string {
+	for _, a := range registry.Actions() {
+		if a.Topic == topic {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// withDryRun returns the action's values with dry run set.
+func withDryRun(values []byte) ([]byte, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(values, &m); err != nil {
+		return nil, err
+	}
+	m["DryRun"] = true
+	return json.Marshal(m)
+}
+
+// stubbed returns services backed by stubs, the resource manager returning the given ancestry.
+func stubbed(ancestry []string) *services.Global {
+	compute := &stubs.ComputeStub{}
+	return &services.Global{
+		Logger:                services.NewLogger(&stubs.LoggerStub{}),
+		Resource:              services.NewResource(&stubs.ResourceManagerStub{GetAncestryResponse: services.CreateAncestors(ancestry)}, &stubs.StorageStub{}),
+		Host:                  services.NewHost(compute),
+		Firewall:              services.NewFirewall(compute),
+		Container:             services.NewContainer(&stubs.ContainerStub{}),
+		Kubernetes:            services.NewKubernetes(&stubs.KubernetesStub{}),
+		CloudSQL:              services.NewCloudSQL(&stubs.CloudSQL{}),
+		SecurityCommandCenter: services.NewCommandCenter(&stubs.SecurityCommandCenterStub{}),
+		OrgPolicy:             services.NewOrgPolicy(&stubs.OrgPolicyStub{}),
+		KMS:                   services.NewKMS(&stubs.KMSStub{}),
+		SecretManager:         services.NewSecretManager(&stubs.SecretManagerStub{}),
+		ServiceUsage:          services.NewServiceUsage(&stubs.ServiceUsageStub{}),
+		Billing:               services.NewBilling(&stubs.CloudBillingStub{}),
+	}
+}
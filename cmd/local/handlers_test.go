@@ -0,0 +1,73 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithDryRun(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		values   string
+		expected string
+		err      bool
+	}{
+		{name: "unset", values: `{"BucketName":"b"}`, expected: `{"BucketName":"b","DryRun":true}`},
+		{name: "disabled", values: `{"BucketName":"b","DryRun":false}`, expected: `{"BucketName":"b","DryRun":true}`},
+		{name: "invalid", values: `[]`, err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := withDryRun([]byte(tt.values))
+			if (err != nil) != tt.err {
+				t.Fatalf("%s got error %v, want error %v", tt.name, err, tt.err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("%s got %s want %s", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	r := &runner{services: stubbed([]string{"project/p", "folder/f", "organization/o"}), dryRun: true}
+	for _, tt := range []struct {
+		name   string
+		action string
+		values string
+		err    bool
+	}{
+		{name: "dry run", action: "close_bucket", values: `{"ProjectID":"p","BucketName":"b"}`},
+		{name: "not supported locally", action: "report", values: `{}`},
+		{name: "invalid values", action: "close_bucket", values: `[]`, err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.run(ctx, tt.action, []byte(tt.values)); (err != nil) != tt.err {
+				t.Errorf("%s got error %v, want error %v", tt.name, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestActionOf(t *testing.T) {
+	if got := actionOf("threat-findings-close-bucket"); got != "close_bucket" {
+		t.Errorf("got %q want %q", got, "close_bucket")
+	}
+	if got := actionOf("threat-findings-unsupported"); got != "" {
+		t.Errorf("got %q want no action", got)
+	}
+}
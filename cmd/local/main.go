@@ -0,0 +1,73 @@
+// Command local runs a Cloud Function handler on this machine against a finding read from disk.
+//
+// The router is run by default, printing the messages it would publish and running the action
+// each is routed to. Services are stubbed and actions run in dry run unless told otherwise:
+//
+//	go run ./cmd/local -finding finding.json
+//	go run ./cmd/local -finding values.json -handler close_bucket -mock=false -dry-run=false
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func main() {
+	finding := flag.String("finding", "", "path of the finding, or of the action's values when -handler is an action")
+	handler := flag.String("handler", "router", `handler to run, "router" or the name of an action such as "close_bucket"`)
+	mock := flag.Bool("mock", true, "use stubbed clients instead of calling the Google Cloud APIs")
+	dryRun := flag.Bool("dry-run", true, "run the actions in dry run")
+	config := flag.String("config", "config/sra.yaml", "path of the router's configuration")
+	ancestry := flag.String("ancestry", "project/project,folder/folder,organization/organization", "ancestry the stubbed resource manager returns, used with -mock")
+	flag.Parse()
+	if *finding == "" {
+		log.Fatalf("-finding is required")
+	}
+	b, err := ioutil.ReadFile(*finding)
+	if err != nil {
+		log.Fatalf("failed to read %s: %q", *finding, err)
+	}
+	ctx := context.Background()
+	var svcs *services.Global
+	if *mock {
+		svcs = stubbed(strings.Split(*ancestry, ","))
+	} else if svcs, err = services.New(ctx); err != nil {
+		log.Fatalf("failed to initialize: %q", err)
+	}
+	r := &runner{services: svcs, dryRun: *dryRun}
+	if *handler != "router" {
+		if err := r.run(ctx, *handler, b); err != nil {
+			log.Fatalf("failed to run %s: %q", *handler, err)
+		}
+		return
+	}
+	conf, err := router.ReadConfig(*config)
+	if err != nil {
+		log.Fatalf("failed to read %s: %q", *config, err)
+	}
+	if err := r.route(ctx, conf, b); err != nil {
+		log.Fatalf("failed to route %s: %q", *finding, err)
+	}
+	fmt.Printf("%s: %d messages routed\n", *finding, len(r.published))
+}
@@ -0,0 +1,72 @@
+// Command preflight checks a deployment can run its automations before a finding needs them.
+//
+// It impersonates the automation service account to test it holds the permissions of every
+// enabled action on the organizations, folders and projects they target, and lists the APIs they
+// call that are not enabled in the automation project:
+//
+//	go run ./cmd/preflight -project my-automation-project
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func main() {
+	config := flag.String("config", "config/sra.yaml", "path of the router's configuration")
+	project := flag.String("project", "", "project the automations are deployed to")
+	serviceAccount := flag.String("service-account", "", "email of the automation service account, defaults to the one created in the project")
+	asCaller := flag.Bool("as-caller", false, "test the permissions of your own credentials instead of impersonating the service account")
+	flag.Parse()
+	if *project == "" {
+		log.Fatalf("-project is required")
+	}
+	if *serviceAccount == "" {
+		*serviceAccount = fmt.Sprintf("automation-service-account@%s.iam.gserviceaccount.com", *project)
+	}
+	conf, err := router.ReadConfig(*config)
+	if err != nil {
+		log.Fatalf("failed to read %s: %q", *config, err)
+	}
+	ctx := context.Background()
+	if !*asCaller {
+		ctx = clients.WithProvider(ctx, clients.Impersonated{ServiceAccount: *serviceAccount})
+	}
+	p, err := services.InitPreflight(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize: %q", err)
+	}
+	gaps, err := router.Preflight(ctx, conf, *project, p)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, g := range gaps {
+		fmt.Println(g)
+	}
+	if len(gaps) > 0 {
+		fmt.Printf("%s: %d gaps found\n", *config, len(gaps))
+		os.Exit(1)
+	}
+	fmt.Printf("%s: ok\n", *config)
+}
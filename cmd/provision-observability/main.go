@@ -0,0 +1,87 @@
+// Command provision-observability creates the Cloud Monitoring dashboard and alerting policies of
+// SRA's metrics: failed executions, dead-lettered messages and their backlog, tripped circuits and
+// the mean time to remediate findings against the SLA of their category.
+//
+// Running it again updates the dashboard and policies it created, found by display name, rather
+// than duplicating them:
+//
+//	go run ./cmd/provision-observability -project my-automation-project -notification-channels projects/my-automation-project/notificationChannels/123
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients"
+)
+
+func main() {
+	project := flag.String("project", "", "project the automations are deployed to")
+	channels := flag.String("notification-channels", "", "comma separated notification channels alerted, named projects/<project>/notificationChannels/<id>")
+	failures := flag.Int("failure-threshold", 5, "failed executions of a function within 10 minutes raising an alert")
+	slas := flag.String("sla", "", "comma separated mean times to remediate by finding category raising an alert, such as public_bucket_acl=1h,*=24h with * for every other category")
+	flag.Parse()
+	if *project == "" {
+		log.Fatalf("-project is required")
+	}
+	sla, err := parseSLA(*slas)
+	if err != nil {
+		log.Fatalf("invalid -sla: %q", err)
+	}
+	var names []string
+	for _, c := range strings.Split(*channels, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			names = append(names, c)
+		}
+	}
+	ctx := context.Background()
+	m, err := clients.NewMonitoring(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize: %q", err)
+	}
+	done, err := provision(ctx, m, *project, names, *failures, sla)
+	for _, d := range done {
+		fmt.Println(d)
+	}
+	if err != nil {
+		log.Fatalf("failed to provision: %q", err)
+	}
+}
+
+// parseSLA parses the "category=duration" pairs of the -sla flag.
+func parseSLA(s string) (map[string]time.Duration, error) {
+	sla := make(map[string]time.Duration)
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("%q is not category=duration", p)
+		}
+		d, err := time.ParseDuration(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		sla[kv[0]] = d
+	}
+	return sla, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dashboard "google.golang.org/api/monitoring/v1"
+	monitoring "google.golang.org/api/monitoring/v3"
+)
+
+// displayName prefixes the display names of the dashboard and policies, which identify them
+// when provisioning again.
+const displayName = "Security Response Automation"
+
+const (
+	// failedExecutions filters the executions of SRA's functions that did not succeed.
+	failedExecutions = `metric.type="cloudfunctions.googleapis.com/function/execution_count" AND resource.type="cloud_function" AND metric.label.status!="ok"`
+	// deadLetters filters the dead-lettered messages the DeadLetter function archived.
+	deadLetters = `metric.type="logging.googleapis.com/user/security-response-automation/dead-letters"`
+	// deadLetterBacklog filters the messages awaiting the DeadLetter function.
+	deadLetterBacklog = `metric.type="pubsub.googleapis.com/subscription/num_undelivered_messages" AND resource.type="pubsub_subscription" AND resource.label.subscription_id=monitoring.regex.full_match("gcf-DeadLetter-.*")`
+	// circuitTrips filters the circuits tripped by actions failing repeatedly.
+	circuitTrips = `metric.type="logging.googleapis.com/user/security-response-automation/circuit-trips"`
+	// timeToRemediate filters the seconds from the creation of findings to their remediation.
+	timeToRemediate = `metric.type="logging.googleapis.com/user/security-response-automation/time-to-remediate"`
+)
+
+// allCategories keys the SLA of the categories without one of their own.
+const allCategories = "*"
+
+// monitoringClient holds the minimum interface required to provision.
+type monitoringClient interface {
+	ListDashboards(context.Context, string) ([]*dashboard.Dashboard, error)
+	CreateDashboard(context.Context, string, *dashboard.Dashboard) (*dashboard.Dashboard, error)
+	UpdateDashboard(context.Context, *dashboard.Dashboard) (*dashboard.Dashboard, error)
+	ListAlertPolicies(context.Context, string) ([]*monitoring.AlertPolicy, error)
+	CreateAlertPolicy(context.Context, string, *monitoring.AlertPolicy) (*monitoring.AlertPolicy, error)
+	UpdateAlertPolicy(context.Context, *monitoring.AlertPolicy) (*monitoring.AlertPolicy, error)
+}
+
+// provision creates or updates the dashboard and alerting policies in the project, returning what
+// it did. The policies notify the channels, failures is the number of failed executions of a
+// function within 10 minutes raising an alert and sla the mean time to remediate by finding
+// category above which an alert is raised.
+func provision(ctx context.Context, client monitoringClient, projectID string, channels []string, failures int, sla map[string]time.Duration) ([]string, error) {
+	var done []string
+	dashboards, err := client.ListDashboards(ctx, projectID)
+	if err != nil {
+		return done, fmt.Errorf("failed to list dashboards: %q", err)
+	}
+	d := newDashboard()
+	existing := false
+	for _, e := range dashboards {
+		if e.DisplayName == d.DisplayName {
+			d.Name, d.Etag, existing = e.Name, e.Etag, true
+			break
+		}
+	}
+	if existing {
+		if _, err := client.UpdateDashboard(ctx, d); err != nil {
+			return done, fmt.Errorf("failed to update dashboard %q: %q", d.Name, err)
+		}
+		done = append(done, fmt.Sprintf("updated dashboard %q", d.DisplayName))
+	} else {
+		if _, err := client.CreateDashboard(ctx, projectID, d); err != nil {
+			return done, fmt.Errorf("failed to create dashboard: %q", err)
+		}
+		done = append(done, fmt.Sprintf("created dashboard %q", d.DisplayName))
+	}
+	policies, err := client.ListAlertPolicies(ctx, projectID)
+	if err != nil {
+		return done, fmt.Errorf("failed to list alert policies: %q", err)
+	}
+	names := make(map[string]string)
+	for _, p := range policies {
+		names[p.DisplayName] = p.Name
+	}
+	for _, p := range newAlertPolicies(channels, failures, sla) {
+		if p.Name = names[p.DisplayName]; p.Name != "" {
+			if _, err := client.UpdateAlertPolicy(ctx, p); err != nil {
+				return done, fmt.Errorf("failed to update alert policy %q: %q", p.Name, err)
+			}
+			done = append(done, fmt.Sprintf("updated alert policy %q", p.DisplayName))
+			continue
+		}
+		if _, err := client.CreateAlertPolicy(ctx, projectID, p); err != nil {
+			return done, fmt.Errorf("failed to create alert policy %q: %q", p.DisplayName, err)
+		}
+		done = append(done, fmt.Sprintf("created alert policy %q", p.DisplayName))
+	}
+	return done, nil
+}
+
+// newDashboard returns the dashboard charting SRA's metrics.
+func newDashboard() *dashboard.Dashboard {
+	chart := func(title, filter, aligner, reducer string, groupBy ...string) *dashboard.Widget {
+		return &dashboard.Widget{
+			Title: title,
+			XyChart: &dashboard.XyChart{DataSets: []*dashboard.DataSet{{
+				PlotType: "LINE",
+				TimeSeriesQuery: &dashboard.TimeSeriesQuery{TimeSeriesFilter: &dashboard.TimeSeriesFilter{
+					Filter: filter,
+					Aggregation: &dashboard.Aggregation{
+						AlignmentPeriod:    "300s",
+						PerSeriesAligner:   aligner,
+						CrossSeriesReducer: reducer,
+						GroupByFields:      groupBy,
+					},
+				}},
+			}}},
+		}
+	}
+	return &dashboard.Dashboard{
+		DisplayName: displayName,
+		GridLayout: &dashboard.GridLayout{Columns: 2, Widgets: []*dashboard.Widget{
+			chart("Failed executions by function", failedExecutions, "ALIGN_DELTA", "REDUCE_SUM", "resource.label.function_name"),
+			chart("Dead-lettered messages by subscription", deadLetters, "ALIGN_DELTA", "REDUCE_SUM", "metric.label.subscription"),
+			chart("Dead-letter backlog", deadLetterBacklog, "ALIGN_MAX", "REDUCE_SUM"),
+			chart("Tripped circuits by action", circuitTrips, "ALIGN_DELTA", "REDUCE_SUM", "metric.label.action"),
+			chart("Mean time to remediate by category", timeToRemediate, "ALIGN_DELTA", "REDUCE_MEAN", "metric.label.category"),
+		}},
+	}
+}
+
+// newAlertPolicies returns the alerting policies on SRA's metrics notifying the channels.
+func newAlertPolicies(channels []string, failures int, sla map[string]time.Duration) []*monitoring.AlertPolicy {
+	policy := func(name, doc, filter, window, aligner, reducer string, threshold float64, groupBy ...string) *monitoring.AlertPolicy {
+		return &monitoring.AlertPolicy{
+			DisplayName:          displayName + ": " + name,
+			Combiner:             "OR",
+			NotificationChannels: channels,
+			Documentation:        &monitoring.Documentation{Content: doc, MimeType: "text/markdown"},
+			Conditions: []*monitoring.Condition{{
+				DisplayName: name,
+				ConditionThreshold: &monitoring.MetricThreshold{
+					Filter:         filter,
+					Comparison:     "COMPARISON_GT",
+					ThresholdValue: threshold,
+					Duration:       "0s",
+					Aggregations: []*monitoring.Aggregation{{
+						AlignmentPeriod:    window,
+						PerSeriesAligner:   aligner,
+						CrossSeriesReducer: reducer,
+						GroupByFields:      groupBy,
+					}},
+				},
+			}},
+		}
+	}
+	policies := []*monitoring.AlertPolicy{
+		policy("failed executions",
+			"A function of Security Response Automation keeps failing, see its logs for the errors.",
+			failedExecutions, "600s", "ALIGN_DELTA", "REDUCE_SUM", float64(failures-1), "resource.label.function_name"),
+		policy("dead-lettered messages",
+			"Messages were dead-lettered after failing every delivery attempt, they are archived to the dead-letters bucket.",
+			deadLetters, "300s", "ALIGN_DELTA", "REDUCE_SUM", 0, "metric.label.subscription"),
+		policy("dead-letter backlog",
+			"Dead-lettered messages are not being archived, check the DeadLetter function.",
+			deadLetterBacklog, "1800s", "ALIGN_MIN", "REDUCE_SUM", 0),
+		policy("tripped circuits",
+			"The circuit of an action tripped after consecutive failures, its findings are not remediated until it closes.",
+			circuitTrips, "300s", "ALIGN_DELTA", "REDUCE_SUM", 0, "metric.label.action"),
+	}
+	categories := make([]string, 0, len(sla))
+	for c := range sla {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	var others []string
+	for _, c := range categories {
+		if c == allCategories {
+			continue
+		}
+		others = append(others, fmt.Sprintf(`metric.label.category!="%s"`, c))
+		policies = append(policies, policy("SLA "+c,
+			fmt.Sprintf("The mean time to remediate %s findings over the last hour exceeds the SLA of %s, automation is falling behind.", c, sla[c]),
+			fmt.Sprintf(`%s AND metric.label.category="%s"`, timeToRemediate, c), "3600s", "ALIGN_DELTA", "REDUCE_MEAN", sla[c].Seconds()))
+	}
+	if d, ok := sla[allCategories]; ok {
+		filter := strings.Join(append([]string{timeToRemediate}, others...), " AND ")
+		policies = append(policies, policy("SLA",
+			fmt.Sprintf("The mean time to remediate findings of a category over the last hour exceeds the SLA of %s, automation is falling behind.", d),
+			filter, "3600s", "ALIGN_DELTA", "REDUCE_MEAN", d.Seconds(), "metric.label.category"))
+	}
+	return policies
+}
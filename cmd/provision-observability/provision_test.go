@@ -0,0 +1,72 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	monitoring "google.golang.org/api/monitoring/v3"
+)
+
+func TestProvision(t *testing.T) {
+	const channel = "projects/test-project/notificationChannels/123"
+	ctx := context.Background()
+	stub := &stubs.MonitoringStub{AlertPolicies: []*monitoring.AlertPolicy{{Name: "projects/test-project/alertPolicies/other", DisplayName: "other"}}}
+	sla := map[string]time.Duration{"public_bucket_acl": time.Hour, allCategories: 24 * time.Hour}
+	done, err := provision(ctx, stub, "test-project", []string{channel}, 5, sla)
+	if err != nil {
+		t.Fatalf("got error %q", err)
+	}
+	if len(done) != 7 || len(stub.Dashboards) != 1 || len(stub.AlertPolicies) != 7 {
+		t.Fatalf("got %q, %d dashboards and %d policies, want 1 dashboard and 6 new policies", done, len(stub.Dashboards), len(stub.AlertPolicies))
+	}
+	if diff := cmp.Diff([]string{channel}, stub.AlertPolicies[1].NotificationChannels); diff != "" {
+		t.Errorf("notification channels (-want +got):\n%s", diff)
+	}
+	if got := stub.AlertPolicies[1].Conditions[0].ConditionThreshold.ThresholdValue; got != 4 {
+		t.Errorf("got failure threshold %v, want more than 4", got)
+	}
+	c := stub.AlertPolicies[5].Conditions[0].ConditionThreshold
+	if want := timeToRemediate + ` AND metric.label.category="public_bucket_acl"`; c.Filter != want || c.ThresholdValue != 3600 {
+		t.Errorf("got SLA filter %q and threshold %v, want %q and 3600", c.Filter, c.ThresholdValue, want)
+	}
+	c = stub.AlertPolicies[6].Conditions[0].ConditionThreshold
+	if want := timeToRemediate + ` AND metric.label.category!="public_bucket_acl"`; c.Filter != want || c.ThresholdValue != 86400 {
+		t.Errorf("got default SLA filter %q and threshold %v, want %q and 86400", c.Filter, c.ThresholdValue, want)
+	}
+	done, err = provision(ctx, stub, "test-project", nil, 5, sla)
+	if err != nil {
+		t.Fatalf("got error %q provisioning again", err)
+	}
+	want := []string{
+		`updated dashboard "Security Response Automation"`,
+		`updated alert policy "Security Response Automation: failed executions"`,
+		`updated alert policy "Security Response Automation: dead-lettered messages"`,
+		`updated alert policy "Security Response Automation: dead-letter backlog"`,
+		`updated alert policy "Security Response Automation: tripped circuits"`,
+		`updated alert policy "Security Response Automation: SLA public_bucket_acl"`,
+		`updated alert policy "Security Response Automation: SLA"`,
+	}
+	if diff := cmp.Diff(want, done); diff != "" {
+		t.Errorf("provisioning again (-want +got):\n%s", diff)
+	}
+	if len(stub.Dashboards) != 1 || len(stub.AlertPolicies) != 7 {
+		t.Errorf("got %d dashboards and %d policies provisioning again, want 1 and 7", len(stub.Dashboards), len(stub.AlertPolicies))
+	}
+}
@@ -0,0 +1,83 @@
+// Command simulate publishes synthetic findings to a deployment of Security Response Automation.
+//
+// Each supported Event Threat Detection and Security Health Analytics category has a sample
+// payload in ./cmd/simulate/findings, rendered with the sandbox project and resource given and
+// published to the router's topic tagged as simulated, to run game days against a deployment:
+//
+//	go run ./cmd/simulate -project my-automation-project -target my-sandbox -target-number 123456789012 -organization 1234567890 -category public_bucket_acl -resource my-public-bucket
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func main() {
+	project := flag.String("project", "", "project the automations are deployed to")
+	topic := flag.String("topic", "threat-findings-router", "topic the findings are published to")
+	corpus := flag.String("corpus", "cmd/simulate/findings", "directory of the sample payloads")
+	category := flag.String("category", "", "comma separated categories to simulate, all of them if empty")
+	dryRun := flag.Bool("dry-run", false, "print the findings instead of publishing them")
+	var t target
+	flag.StringVar(&t.Project, "target", "", "ID of the sandbox project the findings are about")
+	flag.StringVar(&t.ProjectNumber, "target-number", "", "number of the sandbox project")
+	flag.StringVar(&t.Organization, "organization", "", "organization of the sandbox project")
+	flag.StringVar(&t.Folder, "folder", "", "folder of the sandbox project, if any")
+	flag.StringVar(&t.Resource, "resource", "sra-simulated", "name of the bucket, instance, firewall rule, cluster, Cloud SQL instance or dataset the findings are about")
+	flag.StringVar(&t.User, "user", "sra-simulated@example.com", "email of the user the IAM and account findings are about")
+	flag.Parse()
+	if t.Project == "" || t.ProjectNumber == "" || t.Organization == "" {
+		log.Fatalf("-target, -target-number and -organization are required")
+	}
+	if !*dryRun && *project == "" {
+		log.Fatalf("-project is required to publish")
+	}
+	categories, err := categoriesOf(*corpus)
+	if err != nil {
+		log.Fatalf("failed to read %s: %q", *corpus, err)
+	}
+	if *category != "" {
+		categories = strings.Split(*category, ",")
+	}
+	ctx := context.Background()
+	var ps *services.PubSub
+	if !*dryRun {
+		if ps, err = services.InitPubSub(ctx, *project); err != nil {
+			log.Fatalf("failed to initialize: %q", err)
+		}
+	}
+	for _, c := range categories {
+		f, err := render(*corpus, c, t, time.Now())
+		if err != nil {
+			log.Fatalf("failed to render %s: %q", c, err)
+		}
+		if *dryRun {
+			fmt.Printf("%s:\n%s\n", c, f.payload)
+			continue
+		}
+		if err := f.publish(ctx, ps, *topic); err != nil {
+			log.Fatalf("failed to publish %s: %q", c, err)
+		}
+		fmt.Printf("%s: published with correlation ID %s\n", c, f.correlationID())
+	}
+}
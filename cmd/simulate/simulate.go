@@ -0,0 +1,119 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// target names the sandbox the simulated findings are about.
+type target struct {
+	Project       string
+	ProjectNumber string
+	Organization  string
+	Folder        string
+	Resource      string
+	User          string
+}
+
+// finding is a rendered simulated finding.
+type finding struct {
+	target
+	// ID is the random ID of the finding, unique to each simulation.
+	ID string
+	// Time is the event and create time of the finding.
+	Time    string
+	payload []byte
+}
+
+// funcs are the functions available to the sample payloads.
+var funcs = template.FuncMap{
+	// dataset returns the resource name as a BigQuery dataset name, which cannot hold hyphens.
+	"dataset": func(name string) string { return strings.Replace(name, "-", "_", -1) },
+}
+
+// categoriesOf returns the categories with a sample payload in the corpus.
+func categoriesOf(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]string, 0, len(paths))
+	for _, p := range paths {
+		categories = append(categories, strings.TrimSuffix(filepath.Base(p), ".json"))
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// render renders the sample payload of the category for the target.
+func render(dir, category string, t target, now time.Time) (*finding, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, category+".json"))
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(category).Funcs(funcs).Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", category)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	f := &finding{target: t, ID: hex.EncodeToString(id), Time: now.UTC().Format(time.RFC3339Nano)}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f); err != nil {
+		return nil, errors.Wrapf(err, "failed to render %s", category)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.Errorf("%s is not valid JSON once rendered", category)
+	}
+	f.payload = buf.Bytes()
+	return f, nil
+}
+
+// correlationID returns the correlation ID the finding is published with, telling simulated
+// findings apart in the logs and the remediation history.
+func (f *finding) correlationID() string {
+	return "simulated-" + f.ID[:16]
+}
+
+// publish publishes the finding to the topic tagged as simulated.
+func (f *finding) publish(ctx context.Context, ps *services.PubSub, topic string) error {
+	if _, err := ps.Publish(ctx, topic, &pubsub.Message{
+		Data: f.payload,
+		Attributes: map[string]string{
+			services.CorrelationAttribute: f.correlationID(),
+			services.SimulatedAttribute:   "true",
+		},
+	}); err != nil {
+		return errors.Wrapf(err, "failed to publish to %q", topic)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	categories, err := categoriesOf("findings")
+	if err != nil {
+		t.Fatalf("categoriesOf failed: %q", err)
+	}
+	if len(categories) == 0 {
+		t.Fatal("no sample payloads found")
+	}
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	tgt := target{Project: "sandbox", ProjectNumber: "123456789012", Organization: "1234567890", Folder: "987654321", Resource: "sra-simulated", User: "sra-simulated@example.com"}
+	for _, c := range categories {
+		t.Run(c, func(t *testing.T) {
+			f, err := render("findings", c, tgt, now)
+			if err != nil {
+				t.Fatalf("render failed: %q", err)
+			}
+			if strings.Contains(string(f.payload), "{{") || strings.Contains(string(f.payload), "test-project") {
+				t.Errorf("%s was not rendered for the target: %s", c, f.payload)
+			}
+			var v struct {
+				Finding struct {
+					Name      string `json:"name"`
+					EventTime string `json:"eventTime"`
+				} `json:"finding"`
+			}
+			if err := json.Unmarshal(f.payload, &v); err != nil {
+				t.Fatalf("failed to read %s: %q", c, err)
+			}
+			if !strings.HasPrefix(v.Finding.Name, "organizations/1234567890/") || !strings.HasSuffix(v.Finding.Name, "/findings/"+f.ID) {
+				t.Errorf("%s got name %q", c, v.Finding.Name)
+			}
+			if v.Finding.EventTime != "2020-06-01T18:00:00Z" {
+				t.Errorf("%s got event time %q", c, v.Finding.EventTime)
+			}
+		})
+	}
+}
+
+func TestRenderUnknownCategory(t *testing.T) {
+	if _, err := render("findings", "not_a_category", target{}, time.Now()); err == nil {
+		t.Error("render of an unknown category succeeded")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// circuitCollection is the Firestore collection the circuits and pauses of the actions are kept in.
+const circuitCollection = "circuits"
+
+// actions lists the automations of the configuration with their scope, and whether their action
+// is paused if the project is given.
+func actions(ctx context.Context, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("actions", flag.ContinueOnError)
+	config := fs.String("config", "config/sra.yaml", "path of the router's configuration")
+	project := fs.String("project", "", "project the automations are deployed to, to tell which actions are paused")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	conf, err := router.ReadConfig(*config)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %q", *config, err)
+	}
+	var paused map[string]bool
+	if *project != "" {
+		breaker, err := services.InitBreaker(ctx, *project, circuitCollection, 0, 0)
+		if err != nil {
+			return err
+		}
+		paused = make(map[string]bool)
+		for _, as := range conf.Automations() {
+			for _, a := range as {
+				if _, ok := paused[a.Action]; ok {
+					continue
+				}
+				c, err := breaker.Circuit(ctx, a.Action)
+				if err != nil {
+					return err
+				}
+				paused[a.Action] = c.Paused
+			}
+		}
+	}
+	return printActions(w, conf, paused)
+}
+
+// printActions prints the automations of the configuration to w as a table, with a PAUSED column
+// if paused is not nil.
+func printActions(w io.Writer, conf *router.Configuration, paused map[string]bool) error {
+	automations := conf.Automations()
+	findings := make([]string, 0, len(automations))
+	for f := range automations {
+		findings = append(findings, f)
+	}
+	sort.Strings(findings)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := "FINDING\tACTION\tENABLED\tDRY RUN\tSCOPE\tEXCLUDED"
+	if paused != nil {
+		header += "\tPAUSED"
+	}
+	fmt.Fprintln(tw, header)
+	for _, f := range findings {
+		for _, a := range automations[f] {
+			line := fmt.Sprintf("%s\t%s\t%t\t%t\t%s\t%s", f, a.Action, enabled(conf, a.Action), a.Properties.DryRun, orNone(scope(a)), orNone(excluded(a)))
+			if paused != nil {
+				line += fmt.Sprintf("\t%t", paused[a.Action])
+			}
+			fmt.Fprintln(tw, line)
+		}
+	}
+	return tw.Flush()
+}
+
+// enabled returns whether the configuration enables the action.
+func enabled(conf *router.Configuration, action string) bool {
+	for _, a := range conf.EnabledActions() {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// scope returns the targets of the automation, label selectors last.
+func scope(a router.Automation) []string {
+	s := append([]string{}, a.Target...)
+	if a.OrganizationID != "" {
+		s = append(s, "organizations/"+a.OrganizationID)
+	}
+	for _, id := range a.FolderIDs {
+		s = append(s, "folders/"+id)
+	}
+	for _, id := range a.ProjectIDs {
+		s = append(s, "projects/"+id)
+	}
+	for _, l := range a.Labels {
+		s = append(s, "labels:"+l)
+	}
+	return s
+}
+
+// excluded returns the exclusions of the automation.
+func excluded(a router.Automation) []string {
+	s := append([]string{}, a.Exclude...)
+	for _, id := range a.ExcludeProjectIDs {
+		s = append(s, "projects/"+id)
+	}
+	return s
+}
+
+// orNone joins the list, or returns "-" if it is empty.
+func orNone(l []string) string {
+	if len(l) == 0 {
+		return "-"
+	}
+	return strings.Join(l, ",")
+}
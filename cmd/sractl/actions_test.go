@@ -0,0 +1,73 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+)
+
+func TestPrintActions(t *testing.T) {
+	conf := &router.Configuration{}
+	conf.Spec.Enabled = []string{"close_bucket"}
+	conf.Spec.Parameters.SHA.PublicBucketACL = []router.Automation{
+		{Action: "close_bucket", FolderIDs: []string{"123"}, ExcludeProjectIDs: []string{"prod"}},
+	}
+	conf.Spec.Parameters.SHA.OpenFirewall = []router.Automation{
+		{Action: "remediate_firewall    ", Target: []string{"organizations/456/projects/*"}, Labels: []string{"env!=dev"}},
+	}
+	for _, tt := range []struct {
+		name     string
+		paused   map[string]bool
+		expected []string
+	}{
+		{
+			name: "without pauses",
+			expected: []string{
+				"FINDING                ACTION                  ENABLED  DRY RUN  SCOPE                                             EXCLUDED",
+				"sha/open_firewall      remediate_firewall      false    false    organizations/456/projects/*,labels:env!=dev      -",
+				"sha/public_bucket_acl  close_bucket            true     false    folders/123                                       projects/prod",
+			},
+		},
+		{
+			name:   "with pauses",
+			paused: map[string]bool{"close_bucket": true},
+			expected: []string{
+				"FINDING                ACTION                  ENABLED  DRY RUN  SCOPE                                             EXCLUDED       PAUSED",
+				"sha/open_firewall      remediate_firewall      false    false    organizations/456/projects/*,labels:env!=dev      -              false",
+				"sha/public_bucket_acl  close_bucket            true     false    folders/123                                       projects/prod  true",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := printActions(&b, conf, tt.paused); err != nil {
+				t.Fatalf("%s printActions failed: %q", tt.name, err)
+			}
+			lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("%s got %d lines want %d:\n%s", tt.name, len(lines), len(tt.expected), b.String())
+			}
+			for i, l := range lines {
+				if got := strings.Fields(l); strings.Join(got, " ") != strings.Join(strings.Fields(tt.expected[i]), " ") {
+					t.Errorf("%s line %d got %q want %q", tt.name, i, l, tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// historyCollection is the Firestore collection the automations record remediations in.
+const historyCollection = "remediations"
+
+// followInterval is how often the history is polled with -follow.
+const followInterval = 10 * time.Second
+
+// history lists the remediations matching the flags in args to w.
+func history(ctx context.Context, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	project := fs.String("project", "", "project the automations are deployed to")
+	finding := fs.String("finding", "", "name of the finding remediated, organizations/<org>/sources/<source>/findings/<finding>")
+	correlationID := fs.String("correlation-id", "", "correlation ID of the finding, shared by everything done for it")
+	target := fs.String("target", "", "ID of the project whose resources were remediated")
+	action := fs.String("action", "", "name of the action, such as close_bucket")
+	since := fs.String("since", "", "start of the time range, an RFC 3339 time or a duration before now such as 12h")
+	until := fs.String("until", "", "end of the time range, an RFC 3339 time or a duration before now")
+	asJSON := fs.Bool("json", false, "print the remediations as JSON, one per line")
+	follow := fs.Bool("follow", false, "keep printing the remediations as they start")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" {
+		return fmt.Errorf("-project is required")
+	}
+	if *follow && *until != "" {
+		return fmt.Errorf("-follow and -until are exclusive")
+	}
+	now := time.Now()
+	q := services.HistoryQuery{Finding: *finding, CorrelationID: *correlationID, ProjectID: *target, Action: *action}
+	var err error
+	if q.Since, err = parseTime(*since, now); err != nil {
+		return fmt.Errorf("invalid -since: %q", err)
+	}
+	if q.Until, err = parseTime(*until, now); err != nil {
+		return fmt.Errorf("invalid -until: %q", err)
+	}
+	state, err := services.InitState(ctx, *project, historyCollection)
+	if err != nil {
+		return err
+	}
+	remediations, err := state.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	if err := printHistory(w, remediations, *asJSON); err != nil {
+		return err
+	}
+	if !*follow {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, r := range remediations {
+		seen[r.ID] = true
+	}
+	for {
+		// Remediations are recorded when they start, the overlap catches those recorded late.
+		q.Since = time.Now().Add(-2 * followInterval)
+		time.Sleep(followInterval)
+		remediations, err := state.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		var fresh []*services.Remediation
+		for _, r := range remediations {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				fresh = append(fresh, r)
+			}
+		}
+		if err := printRows(w, fresh, *asJSON); err != nil {
+			return err
+		}
+	}
+}
+
+// parseTime parses an RFC 3339 time or a duration before now, the zero time if s is empty.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// printHistory prints the remediations to w as a table, or as JSON lines.
+func printHistory(w io.Writer, remediations []*services.Remediation, asJSON bool) error {
+	return printRemediations(w, remediations, asJSON, true)
+}
+
+// printRows prints the remediations to w as table rows without a header, or as JSON lines.
+func printRows(w io.Writer, remediations []*services.Remediation, asJSON bool) error {
+	return printRemediations(w, remediations, asJSON, false)
+}
+
+func printRemediations(w io.Writer, remediations []*services.Remediation, asJSON, header bool) error {
+	if asJSON {
+		e := json.NewEncoder(w)
+		for _, r := range remediations {
+			if err := e.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(tw, "STARTED\tACTION\tOUTCOME\tRESOURCE\tFINDING\tERROR")
+	}
+	for _, r := range remediations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Started.UTC().Format(time.RFC3339), r.Action, r.Outcome, r.Resource, r.Finding, r.Error)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,72 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2020, 6, 2, 6, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		value    string
+		expected time.Time
+		err      bool
+	}{
+		{name: "empty"},
+		{name: "duration", value: "12h", expected: now.Add(-12 * time.Hour)},
+		{name: "time", value: "2020-06-01T18:00:00Z", expected: time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)},
+		{name: "invalid", value: "last night", err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime(tt.value, now)
+			if (err != nil) != tt.err {
+				t.Fatalf("%s got error %v, want error %v", tt.name, err, tt.err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("%s got %v want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintHistory(t *testing.T) {
+	started := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	remediations := []*services.Remediation{
+		{ID: "1", Finding: "finding/1", Action: "close_bucket", Resource: "projects/p1/buckets/b", Outcome: services.RemediationSucceeded, Started: started},
+		{ID: "2", Finding: "finding/2", Action: "open_firewall", Resource: "projects/p1/firewalls/f", Outcome: services.RemediationFailed, Error: "denied", Started: started.Add(time.Hour)},
+	}
+	var table bytes.Buffer
+	if err := printHistory(&table, remediations, false); err != nil {
+		t.Fatalf("printHistory failed: %q", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "STARTED") || !strings.Contains(lines[2], "2020-06-01T19:00:00Z  open_firewall") || !strings.HasSuffix(lines[2], "denied") {
+		t.Errorf("got table:\n%s", table.String())
+	}
+	var jsonLines bytes.Buffer
+	if err := printHistory(&jsonLines, remediations, true); err != nil {
+		t.Fatalf("printHistory failed: %q", err)
+	}
+	if got := strings.Count(jsonLines.String(), "\n"); got != 2 || !strings.Contains(jsonLines.String(), `"Action":"close_bucket"`) {
+		t.Errorf("got JSON:\n%s", jsonLines.String())
+	}
+}
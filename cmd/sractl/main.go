@@ -0,0 +1,69 @@
+// Command sractl inspects a deployment of Security Response Automation.
+//
+// The history command lists the remediations recorded in the history in the order they started,
+// selected by finding, project, action and time range, to answer what the automations did:
+//
+//	go run ./cmd/sractl history -project my-automation-project -target my-project -since 12h
+//
+// The actions, trigger, pause and resume commands list the configured automations, run an action
+// by hand and stop or restart an action without redeploying:
+//
+//	go run ./cmd/sractl pause -project my-automation-project -action close_bucket
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+)
+
+const usage = `usage: sractl <command> [flags]
+
+commands:
+	actions	list the configured automations, their scope and whether they are enabled or paused
+	trigger	run an action manually against a resource
+	pause	stop an action from running
+	resume	resume a paused action
+	history	list the remediations recorded in the history`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	var err error
+	switch os.Args[1] {
+	case "actions":
+		err = actions(ctx, os.Args[2:], os.Stdout)
+	case "trigger":
+		err = trigger(ctx, os.Args[2:], os.Stdout)
+	case "pause":
+		err = pause(ctx, os.Args[2:], os.Stdout, true)
+	case "resume":
+		err = pause(ctx, os.Args[2:], os.Stdout, false)
+	case "history":
+		err = history(ctx, os.Args[2:], os.Stdout)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s failed: %q", os.Args[1], err)
+	}
+}
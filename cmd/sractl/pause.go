@@ -0,0 +1,55 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// pause stops the action named in args from running, or resumes it if paused is false. Findings
+// routed to a paused action are skipped, not queued.
+func pause(ctx context.Context, args []string, w io.Writer, paused bool) error {
+	name := "pause"
+	if !paused {
+		name = "resume"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	project := fs.String("project", "", "project the automations are deployed to")
+	action := fs.String("action", "", "name of the action, such as close_bucket")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" || *action == "" {
+		return fmt.Errorf("-project and -action are required")
+	}
+	if _, ok := registry.Lookup(*action); !ok {
+		return fmt.Errorf("%q is not a registered action", *action)
+	}
+	breaker, err := services.InitBreaker(ctx, *project, circuitCollection, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err := breaker.Pause(ctx, *action, paused); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s %sd\n", *action, name)
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// trigger runs an action manually by publishing its values to the action's topic, as the router
+// does for the findings routed to it.
+func trigger(ctx context.Context, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("trigger", flag.ContinueOnError)
+	project := fs.String("project", "", "project the automations are deployed to")
+	action := fs.String("action", "", "name of the action, such as close_bucket")
+	values := fs.String("values", "", `values of the action as JSON, such as {"ProjectID": "my-project", "BucketName": "my-bucket"}`)
+	valuesFile := fs.String("values-file", "", "file holding the values of the action as JSON")
+	finding := fs.String("finding", "", "name of the finding the action is run for, recorded in the history")
+	dryRun := fs.Bool("dry-run", false, "run the action in dry run mode, logging the changes instead of making them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" || *action == "" {
+		return fmt.Errorf("-project and -action are required")
+	}
+	b := []byte(*values)
+	if *valuesFile != "" {
+		var err error
+		if b, err = ioutil.ReadFile(*valuesFile); err != nil {
+			return err
+		}
+	}
+	m, err := message(*action, b, *finding, *dryRun)
+	if err != nil {
+		return err
+	}
+	a, _ := registry.Lookup(*action)
+	ps, err := services.InitPubSub(ctx, *project)
+	if err != nil {
+		return err
+	}
+	if _, err := ps.Publish(ctx, a.Topic, m); err != nil {
+		return fmt.Errorf("failed to publish to %q: %q", a.Topic, err)
+	}
+	fmt.Fprintf(w, "published %s to %s with correlation ID %s\n", *action, a.Topic, m.Attributes[services.CorrelationAttribute])
+	return nil
+}
+
+// message returns the message running the action with the values, tagged with a manual
+// correlation ID.
+func message(action string, values []byte, finding string, dryRun bool) (*pubsub.Message, error) {
+	if _, ok := registry.Lookup(action); !ok {
+		return nil, fmt.Errorf("%q is not a registered action", action)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("-values or -values-file is required")
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(values, &v); err != nil {
+		return nil, fmt.Errorf("invalid values: %q", err)
+	}
+	if dryRun {
+		v["DryRun"] = true
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	attributes := map[string]string{
+		services.CorrelationAttribute: "manual-" + hex.EncodeToString(id),
+		"category":                    "manual",
+	}
+	if finding != "" {
+		attributes["finding"] = finding
+	}
+	return &pubsub.Message{Data: b, Attributes: attributes}, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func TestMessage(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		action  string
+		values  string
+		finding string
+		dryRun  bool
+		err     bool
+	}{
+		{name: "values", action: "close_bucket", values: `{"ProjectID": "p1", "BucketName": "b"}`},
+		{name: "dry run", action: "close_bucket", values: `{"ProjectID": "p1", "BucketName": "b"}`, dryRun: true},
+		{name: "finding", action: "close_bucket", values: `{"ProjectID": "p1", "BucketName": "b"}`, finding: "organizations/1/sources/2/findings/3"},
+		{name: "unknown action", action: "drop_database", values: `{"ProjectID": "p1"}`, err: true},
+		{name: "missing values", action: "close_bucket", err: true},
+		{name: "invalid values", action: "close_bucket", values: `["p1"]`, err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := message(tt.action, []byte(tt.values), tt.finding, tt.dryRun)
+			if (err != nil) != tt.err {
+				t.Fatalf("%s got error %v, want error %v", tt.name, err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			var v map[string]interface{}
+			if err := json.Unmarshal(m.Data, &v); err != nil {
+				t.Fatalf("%s failed to unmarshal values: %q", tt.name, err)
+			}
+			if v["ProjectID"] != "p1" {
+				t.Errorf("%s got values %v", tt.name, v)
+			}
+			if dryRun, _ := v["DryRun"].(bool); dryRun != tt.dryRun {
+				t.Errorf("%s got DryRun %v want %v", tt.name, dryRun, tt.dryRun)
+			}
+			if id := m.Attributes[services.CorrelationAttribute]; !strings.HasPrefix(id, "manual-") {
+				t.Errorf("%s got correlation ID %q want a manual one", tt.name, id)
+			}
+			if got := m.Attributes["finding"]; got != tt.finding {
+				t.Errorf("%s got finding %q want %q", tt.name, got, tt.finding)
+			}
+		})
+	}
+}
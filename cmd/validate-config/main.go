@@ -0,0 +1,63 @@
+// Command validate-config checks the router's configuration before it is deployed.
+//
+// It reports unknown or disabled actions, organizations and folders that do not exist and roles
+// the automation service account lacks, with the command to grant each missing role:
+//
+//	go run ./cmd/validate-config -project my-automation-project
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+func main() {
+	config := flag.String("config", "config/sra.yaml", "path of the router's configuration")
+	project := flag.String("project", "", "project the automations are deployed to")
+	serviceAccount := flag.String("service-account", "", "email of the automation service account, defaults to the one created in the project")
+	flag.Parse()
+	if *serviceAccount == "" {
+		if *project == "" {
+			log.Fatalf("either -project or -service-account is required")
+		}
+		*serviceAccount = fmt.Sprintf("automation-service-account@%s.iam.gserviceaccount.com", *project)
+	}
+	conf, err := router.ReadConfig(*config)
+	if err != nil {
+		log.Fatalf("failed to read %s: %q", *config, err)
+	}
+	ctx := context.Background()
+	r, err := services.InitResource(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize: %q", err)
+	}
+	errs := validate(ctx, conf, r, *serviceAccount)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("%s: %d problems found\n", *config, len(errs))
+		os.Exit(1)
+	}
+	fmt.Printf("%s: ok\n", *config)
+}
@@ -0,0 +1,187 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// ancestorPattern matches the organizations and folders of a target or exclude pattern.
+var ancestorPattern = regexp.MustCompile(`(organizations|folders)/(\d+)`)
+
+// validate checks the automations of the configuration, returning an error for each problem found.
+//
+// Every action must be registered and enabled, every organization and folder referenced by a
+// target or exclude must exist and the service account must hold the roles the action needs on
+// the folders and organizations it targets, directly or inherited from the ancestors named in
+// the target.
+func validate(ctx context.Context, conf *router.Configuration, r *services.Resource, serviceAccount string) []error {
+	var errs []error
+	member := "serviceAccount:" + serviceAccount
+	enabled := make(map[string]bool)
+	for _, a := range conf.Spec.Enabled {
+		if _, ok := registry.Lookup(a); !ok {
+			errs = append(errs, fmt.Errorf("enabled_actions: %q is not a registered action", a))
+		}
+		enabled[a] = true
+	}
+	exists := make(map[string]error)
+	roles := make(map[string][]string)
+	automations := conf.Automations()
+	for _, finding := range sortedKeys(automations) {
+		for _, a := range automations[finding] {
+			where := fmt.Sprintf("%s: %s", finding, a.Action)
+			action, ok := registry.Lookup(a.Action)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown action, see automations.md for the supported actions", where))
+				continue
+			}
+			if len(enabled) > 0 && !enabled[a.Action] {
+				errs = append(errs, fmt.Errorf("%s: action is configured but not listed in enabled_actions, it will never run", where))
+			}
+			for _, selector := range a.Labels {
+				if _, err := services.ParseLabelSelector(selector); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %v", where, err))
+				}
+			}
+			patterns := append(append([]string{}, a.Target...), a.Exclude...)
+			if a.OrganizationID != "" {
+				patterns = append(patterns, "organizations/"+a.OrganizationID)
+			}
+			for _, id := range a.FolderIDs {
+				patterns = append(patterns, "folders/"+id)
+			}
+			for _, pattern := range patterns {
+				for _, name := range ancestors(pattern) {
+					if _, ok := exists[name]; !ok {
+						exists[name] = exist(ctx, r, name)
+					}
+					if err := exists[name]; err != nil {
+						errs = append(errs, fmt.Errorf("%s: %q references %s which does not exist or is not visible to %s: %v", where, pattern, name, serviceAccount, err))
+					}
+				}
+			}
+			for _, id := range append(append([]string{}, a.ProjectIDs...), a.ExcludeProjectIDs...) {
+				name := "projects/" + id
+				if _, ok := exists[name]; !ok {
+					exists[name] = exist(ctx, r, name)
+				}
+				if err := exists[name]; err != nil {
+					errs = append(errs, fmt.Errorf("%s: project %q does not exist or is not visible to %s: %v", where, id, serviceAccount, err))
+				}
+			}
+			targets := a.Target
+			if a.OrganizationID != "" {
+				targets = append([]string{"organizations/" + a.OrganizationID}, targets...)
+			}
+			for _, id := range a.FolderIDs {
+				targets = append(targets, "folders/"+id)
+			}
+			for _, pattern := range targets {
+				errs = append(errs, missingRoles(ctx, r, where, pattern, member, action.Roles, roles)...)
+			}
+		}
+	}
+	return errs
+}
+
+// missingRoles returns an error for each role the member lacks on the resources the pattern targets.
+func missingRoles(ctx context.Context, r *services.Resource, where, pattern, member string, required []string, cache map[string][]string) []error {
+	names := ancestors(pattern)
+	if len(names) == 0 || len(required) == 0 {
+		return nil
+	}
+	granted := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := cache[name]; !ok {
+			roles, err := memberRoles(ctx, r, name, member)
+			if err != nil {
+				return []error{fmt.Errorf("%s: failed to read the IAM policy of %s to check roles: %v", where, name, err)}
+			}
+			cache[name] = roles
+		}
+		for _, role := range cache[name] {
+			granted[role] = true
+		}
+	}
+	var errs []error
+	deepest := names[len(names)-1]
+	for _, role := range required {
+		if granted[role] {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s lacks %s on %s, grant it with: %s", where, member, role, deepest, grantCommand(deepest, member, role)))
+	}
+	return errs
+}
+
+// ancestors returns the organizations and folders named in the pattern, from the top down.
+func ancestors(pattern string) []string {
+	var names []string
+	for _, m := range ancestorPattern.FindAllStringSubmatch(pattern, -1) {
+		names = append(names, m[1]+"/"+m[2])
+	}
+	return names
+}
+
+func exist(ctx context.Context, r *services.Resource, name string) error {
+	id := name[strings.Index(name, "/")+1:]
+	if strings.HasPrefix(name, "projects/") {
+		_, err := r.Project(ctx, id)
+		return err
+	}
+	if strings.HasPrefix(name, "folders/") {
+		_, err := r.Folder(ctx, id)
+		return err
+	}
+	o, err := r.Organization(ctx, id)
+	if err == nil && o == nil {
+		return fmt.Errorf("organization %q not found", id)
+	}
+	return err
+}
+
+func memberRoles(ctx context.Context, r *services.Resource, name, member string) ([]string, error) {
+	id := name[strings.Index(name, "/")+1:]
+	if strings.HasPrefix(name, "folders/") {
+		return r.FolderRoles(ctx, id, member)
+	}
+	return r.OrganizationRoles(ctx, id, member)
+}
+
+func grantCommand(name, member, role string) string {
+	id := name[strings.Index(name, "/")+1:]
+	if strings.HasPrefix(name, "organizations/") {
+		return fmt.Sprintf("gcloud organizations add-iam-policy-binding %s --member=%s --role=%s", id, member, role)
+	}
+	return fmt.Sprintf("gcloud resource-manager folders add-iam-policy-binding %s --member=%s --role=%s", id, member, role)
+}
+
+func sortedKeys(m map[string][]router.Automation) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,126 @@
+package main
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	crmv2 "google.golang.org/api/cloudresourcemanager/v2"
+)
+
+func TestValidate(t *testing.T) {
+	const sa = "automation-service-account@test-project.iam.gserviceaccount.com"
+	member := "serviceAccount:" + sa
+	for _, tt := range []struct {
+		name        string
+		automation  router.Automation
+		enabled     []string
+		folderRoles []string
+		expected    []string
+	}{
+		{
+			name:        "valid",
+			automation:  router.Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}},
+			folderRoles: []string{"roles/storage.admin"},
+		},
+		{
+			name:       "missing role",
+			automation: router.Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}},
+			expected:   []string{"lacks roles/storage.admin on folders/123, grant it with: gcloud resource-manager folders add-iam-policy-binding 123"},
+		},
+		{
+			name:        "unknown folder",
+			automation:  router.Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}, Exclude: []string{"organizations/456/folders/789/*"}},
+			folderRoles: []string{"roles/storage.admin"},
+			expected:    []string{"references folders/789 which does not exist"},
+		},
+		{
+			name:       "unknown action",
+			automation: router.Automation{Action: "close_everything", Target: []string{"organizations/456/folders/123/*"}},
+			expected:   []string{"close_everything: unknown action"},
+		},
+		{
+			name:        "not enabled",
+			automation:  router.Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}},
+			enabled:     []string{"iam_revoke"},
+			folderRoles: []string{"roles/storage.admin"},
+			expected:    []string{"not listed in enabled_actions"},
+		},
+		{
+			name:        "invalid label selector",
+			automation:  router.Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}, Labels: []string{"sra-enforce=true", "=prod"}},
+			folderRoles: []string{"roles/storage.admin"},
+			expected:    []string{`invalid label selector "=prod"`},
+		},
+		{
+			name:        "unknown project",
+			automation:  router.Automation{Action: "close_bucket", Target: []string{"organizations/456/folders/123/*"}, ProjectIDs: []string{"test-project", "missing-project"}},
+			folderRoles: []string{"roles/storage.admin"},
+			expected:    []string{`project "missing-project" does not exist`},
+		},
+		{
+			name:       "organization without roles",
+			automation: router.Automation{Action: "close_bucket", OrganizationID: "456"},
+			expected:   []string{"lacks roles/storage.admin on organizations/456, grant it with: gcloud organizations add-iam-policy-binding 456"},
+		},
+		{
+			name:        "folder ids",
+			automation:  router.Automation{Action: "close_bucket", FolderIDs: []string{"123", "789"}},
+			folderRoles: []string{"roles/storage.admin"},
+			expected:    []string{"references folders/789 which does not exist", "lacks roles/storage.admin on folders/789"},
+		},
+		{
+			name:       "folder ids without roles",
+			automation: router.Automation{Action: "close_bucket", FolderIDs: []string{"123"}},
+			expected:   []string{"lacks roles/storage.admin on folders/123, grant it with: gcloud resource-manager folders add-iam-policy-binding 123"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crmStub := &stubs.ResourceManagerStub{
+				GetOrganizationResponse: &crm.Organization{Name: "organizations/456"},
+				GetPolicyResponse: &crm.Policy{Bindings: []*crm.Binding{
+					{Role: "roles/viewer", Members: []string{member}},
+				}},
+				StubbedFolders:  map[string]*crmv2.Folder{"folders/123": {Name: "folders/123"}},
+				StubbedProjects: map[string]*crm.Project{"test-project": {ProjectId: "test-project"}},
+				StubbedFolderPolicies: map[string]*crmv2.Policy{"folders/123": {Bindings: []*crmv2.Binding{
+					{Role: "roles/editor", Members: []string{"user:someone@example.com"}},
+				}}},
+			}
+			for _, role := range tt.folderRoles {
+				p := crmStub.StubbedFolderPolicies["folders/123"]
+				p.Bindings = append(p.Bindings, &crmv2.Binding{Role: role, Members: []string{member}})
+			}
+			conf := &router.Configuration{}
+			conf.Spec.Enabled = tt.enabled
+			conf.Spec.Parameters.SHA.PublicBucketACL = []router.Automation{tt.automation}
+			errs := validate(context.Background(), conf, services.NewResource(crmStub, &stubs.StorageStub{}), sa)
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("%q failed, got errors %v want %v", tt.name, errs, tt.expected)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tt.expected[i]) {
+					t.Errorf("%q failed, got %q want it to contain %q", tt.name, err, tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -1830,6 +1830,226 @@ func (m *LoggingScanner_Finding) GetName() string {
 	return ""
 }
 
+type KmsScanner struct {
+	NotificationConfigName string              `protobuf:"bytes,1,opt,name=notificationConfigName,proto3" json:"notificationConfigName,omitempty"`
+	Finding                *KmsScanner_Finding `protobuf:"bytes,2,opt,name=finding,proto3" json:"finding,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}            `json:"-"`
+	XXX_unrecognized       []byte              `json:"-"`
+	XXX_sizecache          int32               `json:"-"`
+}
+
+func (m *KmsScanner) Reset()         { *m = KmsScanner{} }
+func (m *KmsScanner) String() string { return proto.CompactTextString(m) }
+func (*KmsScanner) ProtoMessage()    {}
+func (*KmsScanner) Descriptor() ([]byte, []int) {
+	return fileDescriptor_42ce1b275ac7c5c9, []int{8}
+}
+
+func (m *KmsScanner) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KmsScanner.Unmarshal(m, b)
+}
+func (m *KmsScanner) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KmsScanner.Marshal(b, m, deterministic)
+}
+func (m *KmsScanner) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KmsScanner.Merge(m, src)
+}
+func (m *KmsScanner) XXX_Size() int {
+	return xxx_messageInfo_KmsScanner.Size(m)
+}
+func (m *KmsScanner) XXX_DiscardUnknown() {
+	xxx_messageInfo_KmsScanner.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KmsScanner proto.InternalMessageInfo
+
+func (m *KmsScanner) GetNotificationConfigName() string {
+	if m != nil {
+		return m.NotificationConfigName
+	}
+	return ""
+}
+
+func (m *KmsScanner) GetFinding() *KmsScanner_Finding {
+	if m != nil {
+		return m.Finding
+	}
+	return nil
+}
+
+type KmsScanner_SecurityMarks struct {
+	Marks                map[string]string `protobuf:"bytes,1,rep,name=marks,proto3" json:"marks,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *KmsScanner_SecurityMarks) Reset()         { *m = KmsScanner_SecurityMarks{} }
+func (m *KmsScanner_SecurityMarks) String() string { return proto.CompactTextString(m) }
+func (*KmsScanner_SecurityMarks) ProtoMessage()    {}
+func (*KmsScanner_SecurityMarks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_42ce1b275ac7c5c9, []int{8, 0}
+}
+
+func (m *KmsScanner_SecurityMarks) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KmsScanner_SecurityMarks.Unmarshal(m, b)
+}
+func (m *KmsScanner_SecurityMarks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KmsScanner_SecurityMarks.Marshal(b, m, deterministic)
+}
+func (m *KmsScanner_SecurityMarks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KmsScanner_SecurityMarks.Merge(m, src)
+}
+func (m *KmsScanner_SecurityMarks) XXX_Size() int {
+	return xxx_messageInfo_KmsScanner_SecurityMarks.Size(m)
+}
+func (m *KmsScanner_SecurityMarks) XXX_DiscardUnknown() {
+	xxx_messageInfo_KmsScanner_SecurityMarks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KmsScanner_SecurityMarks proto.InternalMessageInfo
+
+func (m *KmsScanner_SecurityMarks) GetMarks() map[string]string {
+	if m != nil {
+		return m.Marks
+	}
+	return nil
+}
+
+type KmsScanner_SourceProperties struct {
+	ProjectID            string   `protobuf:"bytes,1,opt,name=projectID,proto3" json:"projectID,omitempty"`
+	ScannerName          string   `protobuf:"bytes,2,opt,name=ScannerName,proto3" json:"ScannerName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KmsScanner_SourceProperties) Reset()         { *m = KmsScanner_SourceProperties{} }
+func (m *KmsScanner_SourceProperties) String() string { return proto.CompactTextString(m) }
+func (*KmsScanner_SourceProperties) ProtoMessage()    {}
+func (*KmsScanner_SourceProperties) Descriptor() ([]byte, []int) {
+	return fileDescriptor_42ce1b275ac7c5c9, []int{8, 1}
+}
+
+func (m *KmsScanner_SourceProperties) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KmsScanner_SourceProperties.Unmarshal(m, b)
+}
+func (m *KmsScanner_SourceProperties) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KmsScanner_SourceProperties.Marshal(b, m, deterministic)
+}
+func (m *KmsScanner_SourceProperties) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KmsScanner_SourceProperties.Merge(m, src)
+}
+func (m *KmsScanner_SourceProperties) XXX_Size() int {
+	return xxx_messageInfo_KmsScanner_SourceProperties.Size(m)
+}
+func (m *KmsScanner_SourceProperties) XXX_DiscardUnknown() {
+	xxx_messageInfo_KmsScanner_SourceProperties.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KmsScanner_SourceProperties proto.InternalMessageInfo
+
+func (m *KmsScanner_SourceProperties) GetProjectID() string {
+	if m != nil {
+		return m.ProjectID
+	}
+	return ""
+}
+
+func (m *KmsScanner_SourceProperties) GetScannerName() string {
+	if m != nil {
+		return m.ScannerName
+	}
+	return ""
+}
+
+type KmsScanner_Finding struct {
+	SourceProperties     *KmsScanner_SourceProperties `protobuf:"bytes,1,opt,name=sourceProperties,proto3" json:"sourceProperties,omitempty"`
+	Category             string                       `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	ResourceName         string                       `protobuf:"bytes,3,opt,name=resourceName,proto3" json:"resourceName,omitempty"`
+	State                string                       `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	SecurityMarks        *KmsScanner_SecurityMarks    `protobuf:"bytes,5,opt,name=securityMarks,proto3" json:"securityMarks,omitempty"`
+	EventTime            string                       `protobuf:"bytes,6,opt,name=eventTime,proto3" json:"eventTime,omitempty"`
+	Name                 string                       `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *KmsScanner_Finding) Reset()         { *m = KmsScanner_Finding{} }
+func (m *KmsScanner_Finding) String() string { return proto.CompactTextString(m) }
+func (*KmsScanner_Finding) ProtoMessage()    {}
+func (*KmsScanner_Finding) Descriptor() ([]byte, []int) {
+	return fileDescriptor_42ce1b275ac7c5c9, []int{8, 2}
+}
+
+func (m *KmsScanner_Finding) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KmsScanner_Finding.Unmarshal(m, b)
+}
+func (m *KmsScanner_Finding) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KmsScanner_Finding.Marshal(b, m, deterministic)
+}
+func (m *KmsScanner_Finding) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KmsScanner_Finding.Merge(m, src)
+}
+func (m *KmsScanner_Finding) XXX_Size() int {
+	return xxx_messageInfo_KmsScanner_Finding.Size(m)
+}
+func (m *KmsScanner_Finding) XXX_DiscardUnknown() {
+	xxx_messageInfo_KmsScanner_Finding.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KmsScanner_Finding proto.InternalMessageInfo
+
+func (m *KmsScanner_Finding) GetSourceProperties() *KmsScanner_SourceProperties {
+	if m != nil {
+		return m.SourceProperties
+	}
+	return nil
+}
+
+func (m *KmsScanner_Finding) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *KmsScanner_Finding) GetResourceName() string {
+	if m != nil {
+		return m.ResourceName
+	}
+	return ""
+}
+
+func (m *KmsScanner_Finding) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *KmsScanner_Finding) GetSecurityMarks() *KmsScanner_SecurityMarks {
+	if m != nil {
+		return m.SecurityMarks
+	}
+	return nil
+}
+
+func (m *KmsScanner_Finding) GetEventTime() string {
+	if m != nil {
+		return m.EventTime
+	}
+	return ""
+}
+
+func (m *KmsScanner_Finding) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*StorageScanner)(nil), "StorageScanner")
 	proto.RegisterType((*StorageScanner_SecurityMarks)(nil), "StorageScanner.SecurityMarks")
@@ -1871,65 +2091,71 @@ func init() {
 	proto.RegisterMapType((map[string]string)(nil), "LoggingScanner.SecurityMarks.MarksEntry")
 	proto.RegisterType((*LoggingScanner_SourceProperties)(nil), "LoggingScanner.SourceProperties")
 	proto.RegisterType((*LoggingScanner_Finding)(nil), "LoggingScanner.Finding")
+	proto.RegisterType((*KmsScanner)(nil), "KmsScanner")
+	proto.RegisterType((*KmsScanner_SecurityMarks)(nil), "KmsScanner.SecurityMarks")
+	proto.RegisterMapType((map[string]string)(nil), "KmsScanner.SecurityMarks.MarksEntry")
+	proto.RegisterType((*KmsScanner_SourceProperties)(nil), "KmsScanner.SourceProperties")
+	proto.RegisterType((*KmsScanner_Finding)(nil), "KmsScanner.Finding")
 }
 
 func init() { proto.RegisterFile("sha/protos/sha.proto", fileDescriptor_42ce1b275ac7c5c9) }
 
 var fileDescriptor_42ce1b275ac7c5c9 = []byte{
-	// 869 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x98, 0xcf, 0x4f, 0xe3, 0x46,
-	0x14, 0xc7, 0xe5, 0x04, 0x27, 0xf0, 0x52, 0x68, 0x70, 0x11, 0x35, 0x11, 0x2d, 0x69, 0xd4, 0x56,
-	0x69, 0x45, 0x4d, 0x1b, 0xa4, 0x8a, 0x72, 0x28, 0x95, 0x12, 0x50, 0xa3, 0x02, 0xaa, 0x1c, 0xfe,
-	0x81, 0xa9, 0x99, 0x18, 0x97, 0x64, 0x26, 0x1d, 0x4f, 0x40, 0xb9, 0x55, 0x95, 0x7a, 0x68, 0x77,
-	0x2f, 0x7b, 0x5d, 0xed, 0x71, 0xb5, 0x5a, 0xed, 0x79, 0xf7, 0x3f, 0xda, 0xf3, 0xfe, 0x0d, 0xab,
-	0xd8, 0x86, 0xd8, 0x33, 0x76, 0x08, 0x78, 0xa3, 0x2c, 0x7b, 0x41, 0xf3, 0xc3, 0xf3, 0xcd, 0xbc,
-	0x37, 0x9f, 0xef, 0xd3, 0x13, 0xb0, 0xe2, 0x9e, 0xa1, 0xad, 0x1e, 0xa3, 0x9c, 0xba, 0x5b, 0xee,
-	0x19, 0x32, 0xbc, 0x61, 0xe5, 0x1f, 0x15, 0x96, 0x5a, 0x9c, 0x32, 0x64, 0xe3, 0x96, 0x85, 0x08,
-	0xc1, 0x4c, 0xfb, 0x11, 0x56, 0x09, 0xe5, 0x4e, 0xdb, 0xb1, 0x10, 0x77, 0x28, 0xa9, 0x53, 0xd2,
-	0x76, 0xec, 0x63, 0xd4, 0xc5, 0xba, 0x52, 0x56, 0xaa, 0x0b, 0x66, 0xc2, 0xae, 0xf6, 0x03, 0xe4,
-	0xdb, 0x0e, 0x39, 0x75, 0x88, 0xad, 0x67, 0xca, 0x4a, 0xb5, 0x50, 0xfb, 0xd4, 0x88, 0x2a, 0x1b,
-	0x07, 0xfe, 0xb6, 0x79, 0xf5, 0x5d, 0xe9, 0x3f, 0x05, 0x16, 0x5b, 0xd8, 0xea, 0x33, 0x87, 0x0f,
-	0x8e, 0x10, 0x3b, 0x77, 0xb5, 0x9f, 0x41, 0xed, 0x0e, 0x07, 0xba, 0x52, 0xce, 0x56, 0x0b, 0xb5,
-	0xaa, 0x28, 0x11, 0xf9, 0xda, 0xf0, 0xfe, 0xee, 0x13, 0xce, 0x06, 0xa6, 0x7f, 0xac, 0xb4, 0x03,
-	0x30, 0x5a, 0xd4, 0x8a, 0x90, 0x3d, 0xc7, 0x83, 0xe0, 0xde, 0xc3, 0xa1, 0xb6, 0x02, 0xea, 0x05,
-	0xea, 0xf4, 0xb1, 0x77, 0xc5, 0x05, 0xd3, 0x9f, 0xec, 0x66, 0x76, 0x94, 0x92, 0x09, 0xc5, 0x16,
-	0xed, 0x33, 0x0b, 0xff, 0xce, 0x68, 0x0f, 0x33, 0xee, 0x60, 0x57, 0x5b, 0x87, 0x85, 0x1e, 0xa3,
-	0x7f, 0x62, 0x8b, 0x37, 0x4f, 0x03, 0x95, 0xd1, 0x82, 0x56, 0x86, 0x42, 0x70, 0x2d, 0x2f, 0x3b,
-	0xbe, 0x62, 0x78, 0xa9, 0xf4, 0x34, 0x03, 0xf9, 0x20, 0x68, 0xed, 0x10, 0x8a, 0xae, 0xa0, 0xef,
-	0x49, 0x16, 0x6a, 0x65, 0x29, 0x48, 0xe1, 0x3b, 0x53, 0x3a, 0xa9, 0x55, 0xe0, 0x23, 0x86, 0xfd,
-	0xd5, 0xd0, 0x8f, 0x47, 0xd6, 0xb4, 0x12, 0xcc, 0x5b, 0x88, 0x63, 0x9b, 0xb2, 0x81, 0x9e, 0xf5,
-	0xf6, 0xaf, 0xe7, 0xc3, 0x3c, 0xb8, 0x1c, 0x71, 0xac, 0xcf, 0xf9, 0x79, 0xf0, 0x26, 0x5a, 0x1d,
-	0x16, 0xdd, 0x70, 0x82, 0x75, 0xd5, 0xbb, 0xe0, 0x67, 0x63, 0x5f, 0xc1, 0x8c, 0x9e, 0x19, 0x26,
-	0x0d, 0x5f, 0x60, 0xc2, 0x4f, 0x9c, 0x2e, 0xd6, 0x73, 0x7e, 0xd2, 0xae, 0x17, 0x34, 0x0d, 0xe6,
-	0xc8, 0xf0, 0xc2, 0x79, 0x6f, 0xc3, 0x1b, 0x57, 0x1e, 0xe7, 0xe0, 0xe3, 0x03, 0x87, 0xe1, 0x4b,
-	0xd4, 0xe9, 0xa4, 0xa5, 0xb0, 0x26, 0x52, 0xa8, 0x1b, 0x82, 0xb4, 0x8c, 0xe1, 0xff, 0x12, 0x86,
-	0x7b, 0x51, 0x0c, 0xbf, 0x91, 0x34, 0xa6, 0xc7, 0xe1, 0x6b, 0xe5, 0xd6, 0x20, 0xea, 0x90, 0x47,
-	0x9d, 0x0e, 0xbd, 0xc4, 0xa7, 0x81, 0xdc, 0xd5, 0x54, 0xfb, 0x1a, 0x96, 0x82, 0x61, 0xb3, 0x67,
-	0x22, 0x62, 0xe3, 0x00, 0x04, 0x61, 0x55, 0xdb, 0x84, 0x65, 0x64, 0x71, 0xe7, 0xc2, 0xcb, 0xe6,
-	0x09, 0x73, 0x6c, 0x1b, 0xb3, 0x00, 0x0d, 0x79, 0x63, 0x08, 0xbe, 0x8f, 0x99, 0x2f, 0xa9, 0xfa,
-	0xe0, 0x87, 0x96, 0x44, 0x6b, 0xe4, 0x64, 0x6b, 0x3c, 0x0b, 0x59, 0xe3, 0x28, 0xd1, 0x1a, 0x5f,
-	0xc8, 0x89, 0xbf, 0xd9, 0x1b, 0x61, 0xee, 0x33, 0x02, 0xf7, 0xa2, 0x6f, 0xb2, 0x31, 0xbe, 0x89,
-	0xf7, 0x46, 0x23, 0xde, 0x1b, 0x9f, 0x8f, 0x47, 0x23, 0xbd, 0x39, 0x5e, 0xa8, 0xb0, 0x5a, 0xa7,
-	0xdd, 0x5e, 0x9f, 0xe3, 0x26, 0x71, 0x39, 0x22, 0x56, 0xea, 0x4a, 0xfd, 0x93, 0xe8, 0x91, 0x0d,
-	0x23, 0xfe, 0x17, 0x64, 0xab, 0x3c, 0x92, 0xac, 0x72, 0x10, 0xb5, 0xca, 0xf7, 0x49, 0x52, 0x33,
-	0xab, 0xdc, 0x0d, 0xd1, 0x30, 0x8d, 0x09, 0x2a, 0xf7, 0xcb, 0x10, 0x9e, 0x27, 0x89, 0x78, 0x56,
-	0x13, 0x83, 0x9d, 0x15, 0xa5, 0xbf, 0xc5, 0x53, 0xfa, 0xd5, 0x44, 0xaf, 0x92, 0x1e, 0xd6, 0x61,
-	0x3b, 0xd1, 0x40, 0x1c, 0xb9, 0x98, 0x4f, 0xa1, 0x9d, 0x88, 0x2a, 0xdf, 0xa9, 0x9d, 0x10, 0x24,
-	0xee, 0x17, 0x94, 0x93, 0xb6, 0x13, 0x62, 0x90, 0xb3, 0x82, 0x31, 0xb1, 0x9d, 0x18, 0xf7, 0x0a,
-	0xe9, 0x21, 0x7c, 0xa5, 0x02, 0x34, 0x51, 0x37, 0x2d, 0x80, 0xdf, 0x89, 0x00, 0x7e, 0x62, 0x8c,
-	0x54, 0x65, 0xf8, 0xfe, 0x95, 0xe0, 0xdb, 0x8d, 0xc2, 0xf7, 0x65, 0xf8, 0xf8, 0xf4, 0xc0, 0xfb,
-	0x5b, 0xb9, 0x35, 0x79, 0x9b, 0xb0, 0x4c, 0xdb, 0x6d, 0xec, 0xc5, 0xd1, 0x44, 0x5d, 0x93, 0x76,
-	0xb0, 0x1b, 0x08, 0xcb, 0x1b, 0x22, 0xa7, 0xd9, 0xf1, 0xc5, 0xf3, 0xd7, 0x44, 0x4e, 0xd7, 0x23,
-	0xf9, 0x48, 0xc7, 0xe8, 0x2a, 0xe4, 0x7a, 0x88, 0x61, 0xc2, 0x83, 0xeb, 0x04, 0xb3, 0x04, 0x2e,
-	0x45, 0xa2, 0xd5, 0x18, 0xa2, 0xf7, 0x44, 0x76, 0x73, 0xde, 0xa5, 0xd7, 0x12, 0x1f, 0x71, 0x2c,
-	0xb7, 0xf9, 0x24, 0x6e, 0xe7, 0x43, 0xdc, 0xbe, 0x99, 0x03, 0x68, 0xfd, 0xd5, 0x99, 0x02, 0xb7,
-	0x23, 0xd5, 0x3b, 0x71, 0x1b, 0x3a, 0x7e, 0xbf, 0x0a, 0xe6, 0x93, 0x09, 0x41, 0x0c, 0x07, 0x38,
-	0xab, 0x62, 0xb9, 0x17, 0x5f, 0x2c, 0xd7, 0x12, 0xb3, 0x9f, 0xbe, 0x50, 0x3e, 0x54, 0xa1, 0x58,
-	0xa7, 0x84, 0x23, 0x87, 0x60, 0x96, 0x16, 0xbb, 0x6d, 0x11, 0xbb, 0x35, 0x43, 0xd4, 0x96, 0xe1,
-	0x7b, 0x20, 0xc1, 0xf7, 0x4b, 0x14, 0xbe, 0x6f, 0x65, 0x91, 0xfb, 0x85, 0xe0, 0xf3, 0x10, 0x82,
-	0xc7, 0x89, 0x08, 0x56, 0x62, 0xc2, 0x9c, 0x15, 0x88, 0xfb, 0xf1, 0x20, 0x6e, 0xdc, 0xf0, 0x12,
-	0xef, 0xa6, 0x79, 0x3c, 0xa4, 0xb6, 0xed, 0x10, 0x7b, 0x0a, 0xcd, 0x63, 0x54, 0xf9, 0x4e, 0xcd,
-	0xa3, 0x20, 0xf1, 0x61, 0x36, 0x8f, 0x62, 0x90, 0xef, 0x5d, 0xf3, 0x38, 0xee, 0x15, 0x52, 0x43,
-	0xf8, 0x47, 0xce, 0xfb, 0xbf, 0xe8, 0xf6, 0xdb, 0x00, 0x00, 0x00, 0xff, 0xff, 0xfe, 0xe1, 0x33,
-	0xb4, 0x2f, 0x15, 0x00, 0x00,
+	// 885 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdd, 0x98, 0x5b, 0x4f, 0x13, 0x41,
+	0x14, 0xc7, 0xd3, 0x96, 0x6d, 0xe1, 0x20, 0x58, 0x57, 0x82, 0xa5, 0x41, 0xc1, 0x46, 0x0d, 0x1a,
+	0x5d, 0x14, 0x12, 0x83, 0x3c, 0x88, 0x09, 0x85, 0xd8, 0x70, 0x09, 0xd9, 0xf2, 0x05, 0xc6, 0x32,
+	0x5d, 0x57, 0xda, 0xdd, 0x3a, 0x3b, 0x85, 0xf4, 0xcd, 0x98, 0xf8, 0xe0, 0xe5, 0xc5, 0x57, 0xe3,
+	0xa3, 0x31, 0xc6, 0x67, 0xfd, 0x46, 0x3e, 0xfb, 0x19, 0x9c, 0xbd, 0x40, 0x77, 0x67, 0x76, 0x4b,
+	0xe9, 0xda, 0x14, 0x78, 0x69, 0xe6, 0xfa, 0xdf, 0x73, 0xce, 0xfc, 0xce, 0xcc, 0x49, 0x61, 0xc2,
+	0x7a, 0x89, 0xe6, 0x1b, 0xc4, 0xa4, 0xa6, 0x35, 0xcf, 0x9a, 0x8a, 0xd3, 0x2c, 0xbc, 0x95, 0x60,
+	0xbc, 0x4c, 0x4d, 0x82, 0x34, 0x5c, 0xae, 0x20, 0xc3, 0xc0, 0x44, 0x7e, 0x0c, 0x93, 0x86, 0x49,
+	0xf5, 0xaa, 0x5e, 0x41, 0x54, 0x37, 0x8d, 0x55, 0xd3, 0xa8, 0xea, 0xda, 0x36, 0xaa, 0xe3, 0x5c,
+	0x62, 0x36, 0x31, 0x37, 0xa2, 0x46, 0xcc, 0xca, 0x8f, 0x20, 0x53, 0xd5, 0x8d, 0x3d, 0xdd, 0xd0,
+	0x72, 0x49, 0xb6, 0x70, 0x74, 0xe1, 0x9a, 0x12, 0x54, 0x56, 0xd6, 0xdd, 0x69, 0xf5, 0x68, 0x5d,
+	0xfe, 0x7d, 0x02, 0xc6, 0xca, 0xb8, 0xd2, 0x24, 0x3a, 0x6d, 0x6d, 0x21, 0xb2, 0x6f, 0xc9, 0x4f,
+	0x41, 0xaa, 0xdb, 0x0d, 0xf6, 0xad, 0x14, 0x93, 0x98, 0xe3, 0x25, 0x02, 0xab, 0x15, 0xe7, 0x77,
+	0xcd, 0xa0, 0xa4, 0xa5, 0xba, 0xdb, 0xf2, 0x4b, 0x00, 0xed, 0x41, 0x39, 0x0b, 0xa9, 0x7d, 0xdc,
+	0xf2, 0xec, 0xb6, 0x9b, 0xf2, 0x04, 0x48, 0x07, 0xa8, 0xd6, 0xc4, 0x8e, 0x89, 0x23, 0xaa, 0xdb,
+	0x59, 0x4e, 0x2e, 0x25, 0xf2, 0x2a, 0x64, 0xcb, 0x66, 0x93, 0x54, 0xf0, 0x0e, 0x31, 0x1b, 0x98,
+	0x50, 0x1d, 0x5b, 0xf2, 0x34, 0x8c, 0xb0, 0x30, 0xbd, 0xc2, 0x15, 0x5a, 0xda, 0xf3, 0x54, 0xda,
+	0x03, 0xf2, 0x2c, 0x8c, 0x7a, 0x66, 0x39, 0xd1, 0x71, 0x15, 0xfd, 0x43, 0xf9, 0x6f, 0x49, 0xc8,
+	0x78, 0x4e, 0xcb, 0x9b, 0x90, 0xb5, 0x38, 0x7d, 0x47, 0x72, 0x74, 0x61, 0x56, 0x70, 0x92, 0x5b,
+	0xa7, 0x0a, 0x3b, 0xe5, 0x02, 0x5c, 0x22, 0xd8, 0x1d, 0xf5, 0x7d, 0x3c, 0x30, 0x26, 0xe7, 0x61,
+	0x98, 0x1d, 0x12, 0xd6, 0x4c, 0xd2, 0xca, 0xa5, 0x9c, 0xf9, 0xe3, 0xbe, 0x1d, 0x07, 0x8b, 0xb2,
+	0x4e, 0x6e, 0xc8, 0x8d, 0x83, 0xd3, 0x91, 0x57, 0x61, 0xcc, 0xf2, 0x07, 0x38, 0x27, 0x39, 0x06,
+	0x5e, 0xef, 0x78, 0x0a, 0x6a, 0x70, 0x8f, 0x1d, 0x34, 0x7c, 0x80, 0x0d, 0xba, 0xab, 0x33, 0xbb,
+	0xd2, 0x6e, 0xd0, 0x8e, 0x07, 0x64, 0x19, 0x86, 0x0c, 0xdb, 0xe0, 0x8c, 0x33, 0xe1, 0xb4, 0x0b,
+	0x5f, 0xd2, 0x70, 0x79, 0x5d, 0x27, 0xf8, 0x10, 0xd5, 0x6a, 0x71, 0x29, 0x5c, 0xe0, 0x29, 0xcc,
+	0x29, 0x9c, 0xb4, 0x88, 0xe1, 0x07, 0x01, 0xc3, 0x95, 0x20, 0x86, 0x77, 0x05, 0x8d, 0xfe, 0x71,
+	0xf8, 0x27, 0x71, 0x6a, 0x10, 0x73, 0x90, 0x61, 0xa6, 0x99, 0x87, 0x78, 0xcf, 0x93, 0x3b, 0xea,
+	0xca, 0x77, 0x60, 0xdc, 0x6b, 0x96, 0x1a, 0x2a, 0x32, 0x34, 0xec, 0x81, 0xc0, 0x8d, 0xca, 0xf7,
+	0xe1, 0x0a, 0xaa, 0x50, 0xfd, 0xc0, 0x89, 0xe6, 0x2e, 0xd1, 0x35, 0x0d, 0x13, 0x0f, 0x0d, 0x71,
+	0xc2, 0x06, 0xdf, 0xc5, 0xcc, 0x95, 0x94, 0x5c, 0xf0, 0x7d, 0x43, 0x7c, 0x6a, 0xa4, 0xc5, 0xd4,
+	0xf8, 0xee, 0x4b, 0x8d, 0xad, 0xc8, 0xd4, 0xb8, 0x29, 0x06, 0xfe, 0xe4, 0xdc, 0xf0, 0x73, 0x9f,
+	0xe4, 0xb8, 0xe7, 0xf3, 0x26, 0x15, 0x92, 0x37, 0xe1, 0xb9, 0x51, 0x0c, 0xcf, 0x8d, 0x1b, 0x9d,
+	0xd1, 0x88, 0x9f, 0x1c, 0x3f, 0x25, 0x98, 0x5c, 0x35, 0xeb, 0x8d, 0x26, 0xc5, 0x25, 0x83, 0x99,
+	0x62, 0x54, 0x62, 0xdf, 0xd4, 0x4f, 0xf8, 0x1c, 0x99, 0x51, 0xc2, 0xbf, 0x20, 0xa6, 0xca, 0x67,
+	0x21, 0x55, 0xd6, 0x83, 0xa9, 0xf2, 0x30, 0x4a, 0x6a, 0x60, 0x37, 0x77, 0x91, 0x4f, 0x98, 0x62,
+	0x17, 0x37, 0xf7, 0x2f, 0x1f, 0x9e, 0xbb, 0x91, 0x78, 0xce, 0x45, 0x3a, 0x3b, 0x28, 0x4a, 0x37,
+	0xc2, 0x29, 0xbd, 0xdd, 0xd5, 0xa9, 0xc4, 0x87, 0xd5, 0x2e, 0x27, 0x8a, 0x88, 0x22, 0x0b, 0xd3,
+	0x3e, 0x94, 0x13, 0x41, 0xe5, 0x9e, 0xca, 0x09, 0x4e, 0xe2, 0x7c, 0x41, 0xd9, 0x6d, 0x39, 0xc1,
+	0x3b, 0x39, 0x28, 0x18, 0x23, 0xcb, 0x89, 0x4e, 0xa7, 0x10, 0x1f, 0xc2, 0xdf, 0x12, 0x40, 0x09,
+	0xd5, 0xe3, 0x02, 0xf8, 0x80, 0x07, 0xf0, 0xaa, 0xd2, 0x56, 0x15, 0xe1, 0x7b, 0x27, 0xc0, 0xb7,
+	0x1c, 0x84, 0xef, 0x96, 0x7f, 0x7b, 0xff, 0xc0, 0x7b, 0x93, 0x38, 0x35, 0x79, 0xec, 0xf5, 0x37,
+	0xab, 0x55, 0xec, 0xf8, 0xc1, 0x6c, 0x54, 0xcd, 0x1a, 0x83, 0xc9, 0x15, 0x16, 0x27, 0x78, 0x4e,
+	0x53, 0x9d, 0x2f, 0xcf, 0xe7, 0x91, 0x9c, 0x4e, 0x07, 0xe2, 0x11, 0x8f, 0xd1, 0x49, 0x48, 0x37,
+	0x10, 0x61, 0x50, 0x78, 0xe6, 0x78, 0xbd, 0x08, 0x2e, 0x79, 0xa2, 0xa5, 0x10, 0xa2, 0x57, 0x78,
+	0x76, 0xd3, 0x8e, 0xd1, 0x53, 0x91, 0x87, 0xd8, 0x91, 0xdb, 0x4c, 0x14, 0xb7, 0xc3, 0x3e, 0x6e,
+	0xff, 0x0e, 0x01, 0x94, 0x5f, 0xd7, 0xfa, 0xc0, 0x6d, 0x5b, 0xb5, 0x27, 0x6e, 0x7d, 0xdb, 0xcf,
+	0xd7, 0x85, 0xf9, 0xb5, 0x4b, 0x10, 0xfd, 0x0e, 0x0e, 0xea, 0xb2, 0x5c, 0x09, 0xbf, 0x2c, 0xa7,
+	0x22, 0xa3, 0x1f, 0xff, 0xa2, 0xfc, 0x24, 0x41, 0x96, 0x81, 0x43, 0x91, 0xce, 0xc4, 0xe3, 0x62,
+	0xb7, 0xc8, 0x63, 0x37, 0xa5, 0xf0, 0xda, 0x22, 0x7c, 0x1f, 0x05, 0xf8, 0x9e, 0x05, 0xe1, 0xbb,
+	0x27, 0x8a, 0x9c, 0x2f, 0x04, 0x7f, 0xf8, 0x10, 0xdc, 0x8e, 0x44, 0xb0, 0x10, 0xe2, 0xe6, 0xa0,
+	0x40, 0x5c, 0x0b, 0x07, 0x71, 0xe6, 0x84, 0x93, 0xf8, 0x3f, 0xc5, 0xe3, 0xa6, 0xa9, 0x69, 0x2c,
+	0x54, 0x7d, 0x28, 0x1e, 0x83, 0xca, 0x3d, 0x15, 0x8f, 0x9c, 0xc4, 0xc5, 0x2c, 0x1e, 0x79, 0x27,
+	0xcf, 0x5c, 0xf1, 0xd8, 0xe9, 0x14, 0xe2, 0x43, 0x68, 0x3f, 0xc2, 0x1b, 0x75, 0xab, 0x0f, 0x8f,
+	0x70, 0x5b, 0xb5, 0xa7, 0x47, 0xd8, 0xb7, 0xfd, 0x62, 0x3e, 0xc2, 0x7e, 0x07, 0xcf, 0xdc, 0x23,
+	0x1c, 0x15, 0xfd, 0xd8, 0xc0, 0xbd, 0x48, 0x3b, 0x7f, 0xc4, 0x2f, 0xfe, 0x03, 0x6c, 0x3b, 0x59,
+	0x40, 0xa0, 0x17, 0x00, 0x00,
 }
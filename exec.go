@@ -19,302 +19,229 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"net/http"
 	"os"
+	"reflect"
 
 	"cloud.google.com/go/pubsub"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/bigquery/closepublicdataset"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/removepublic"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/requiressl"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloud-sql/updatepassword"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/filter"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/disabledashboard"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/removenonorgmembers"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
-	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloudevent"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/playbook"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/webhook"
 	"github.com/googlecloudplatform/security-response-automation/services"
 )
 
 var (
-	svcs      *services.Global
+	svcs     *services.Global
+	secrets  *services.Secrets
+	history  *services.State
+	breaker  *services.Breaker
+	throttle *services.Throttle
+	// checkpoints keeps the runs of multi-step remediations.
+	checkpoints *services.Checkpoints
+	// errorReporting reports the errors of actions, nil if ERROR_REPORTING is "false".
+	errorReporting *services.ErrorReporting
+	// tasks defers work through the queue named by TASKS_QUEUE, nil if it is not set.
+	tasks *services.Tasks
+	// redactor scrubs sensitive material from logs and notifications, nil if REDACTION_PATTERNS is
+	// "none".
+	redactor  *services.Redactor
 	projectID = os.Getenv("GCP_PROJECT")
 )
 
+// webhookHandlers maps the entry points that can be invoked over HTTP by the Webhook function,
+// the entry points of the actions are added on init.
+var webhookHandlers = map[string]webhook.Handler{
+	"Playbook": Playbook,
+}
+
+// eventHandlers maps the entry points CloudEvent can run to their functions, those the Webhook
+// function dispatches are added on init.
+var eventHandlers = map[string]cloudevent.Handler{}
+
+// action is the entry point of an action and the services it runs with.
+type action struct {
+	handler  func(context.Context, pubsub.Message) error
+	services []services.Service
+}
+
+// actionHandlers maps the entry points of the actions built in to their functions. Each is added by
+// the file of its entry point, built unless the sra_action tag is set without the action's own tag,
+// such as sra_close_bucket, so an action function only builds in its own action and the services
+// it runs with.
+var actionHandlers = map[string]action{}
+
+// addAction adds the entry point of an action and the services it runs with to actionHandlers. It
+// is called from package variables so the entry points are added before init runs.
+func addAction(entryPoint string, h func(context.Context, pubsub.Message) error, with ...services.Service) bool {
+	actionHandlers[entryPoint] = action{handler: h, services: with}
+	return true
+}
+
+// entryPoint returns the entry point of the deployed function, named by EVENT_HANDLER for
+// CloudEvent or by FUNCTION_TARGET (ENTRY_POINT on older runtimes).
+func entryPoint() string {
+	if target := os.Getenv("EVENT_HANDLER"); target != "" {
+		return target
+	}
+	if target := os.Getenv("FUNCTION_TARGET"); target != "" {
+		return target
+	}
+	return os.Getenv("ENTRY_POINT")
+}
+
+// requiredServices returns the services the deployed function needs: those of its entry point if
+// it runs an action, or those of every action built in and of the routing functions otherwise, such
+// as for the Router or Webhook. Resource is added when findings are routed to folders as it looks
+// up the ancestry of their projects.
+func requiredServices() []services.Service {
+	var with []services.Service
+	if a, ok := actionHandlers[entryPoint()]; ok {
+		with = append(with, a.services...)
+	} else {
+		for _, a := range actionHandlers {
+			with = append(with, a.services...)
+		}
+		with = append(with, routingServices...)
+	}
+	if os.Getenv("FOLDER_SERVICE_ACCOUNTS") != "" {
+		with = append(with, services.WithResource)
+	}
+	return with
+}
+
+// missingServices returns the services the action declares in its registration, among those of
+// Global, that are not initialized.
+func missingServices(g *services.Global, a registry.Action) []string {
+	v := reflect.ValueOf(g).Elem()
+	var missing []string
+	for _, name := range a.Services {
+		if f := v.FieldByName(name); f.IsValid() && f.IsNil() {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// playbookHandlers maps the names of the registered actions to their entry points.
+func playbookHandlers() map[string]playbook.Handler {
+	m := make(map[string]playbook.Handler)
+	for _, a := range registry.Actions() {
+		m[a.Name] = actionHandlers[a.EntryPoint].handler
+	}
+	return m
+}
+
 func init() {
 	log.SetFlags(log.LstdFlags | log.Llongfile)
+	for _, a := range registry.Actions() {
+		h, ok := actionHandlers[a.EntryPoint]
+		if !ok {
+			log.Fatalf("action %q has no entry point %q", a.Name, a.EntryPoint)
+		}
+		webhookHandlers[a.EntryPoint] = h.handler
+	}
+	for name, h := range routingHandlers {
+		webhookHandlers[name] = h
+	}
+	for name, h := range webhookHandlers {
+		eventHandlers[name] = cloudevent.Handler(h)
+	}
+	for name, h := range routingEventHandlers {
+		eventHandlers[name] = h
+	}
 	ctx := context.Background()
 	var err error
 	if projectID == "" {
 		log.Fatalf("GCP_PROJECT environment variable not set")
 	}
-	svcs, err = services.New(ctx)
+	svcs, err = services.NewFor(ctx, requiredServices()...)
 	if err != nil {
 		log.Fatalf("failed to initialize services: %q", err)
 	}
-}
-
-// Filter is the entry point for the Filter Cloud function.
-// This function will receive all findings and filter them against
-// any user-defined Rego policies before forwarding along to the
-// Router function.
-func Filter(ctx context.Context, m pubsub.Message) error {
-	ps, err := services.InitPubSub(ctx, projectID)
+	for _, a := range registry.Actions() {
+		if a.EntryPoint != entryPoint() {
+			continue
+		}
+		if missing := missingServices(svcs, a); len(missing) > 0 {
+			log.Fatalf("entry point %q does not initialize the services %q of action %q", a.EntryPoint, missing, a.Name)
+		}
+	}
+	if redactor, err = redactionConfig(); err != nil {
+		log.Fatalf("failed to initialize redaction: %q", err)
+	}
+	svcs.Logger = svcs.Logger.WithRedactor(redactor)
+	delegates, err = initDelegates(ctx)
 	if err != nil {
-		return err
+		log.Fatalf("failed to initialize folder service accounts: %q", err)
 	}
-	return filter.Execute(ctx, m, &filter.Services{
-		PubSub:                ps,
-		Logger:                svcs.Logger,
-		SecurityCommandCenter: svcs.SecurityCommandCenter,
-	})
-}
-
-// Router is the entry point for the router Cloud Function.
-//
-// This Cloud Function will receive all findings and route them to configured automation.
-func Router(ctx context.Context, m pubsub.Message) error {
-	ps, err := services.InitPubSub(ctx, projectID)
+	secrets, err = services.InitSecrets(ctx)
 	if err != nil {
-		return err
+		log.Fatalf("failed to initialize secrets: %q", err)
 	}
-	conf, err := router.Config()
+	history, err = services.InitState(ctx, projectID, historyCollection)
 	if err != nil {
-		return err
+		log.Fatalf("failed to initialize history: %q", err)
 	}
-	return router.Execute(ctx, &router.Values{
-		Finding: m.Data,
-	}, &router.Services{
-		PubSub:                ps,
-		Configuration:         conf,
-		Logger:                svcs.Logger,
-		Resource:              svcs.Resource,
-		SecurityCommandCenter: svcs.SecurityCommandCenter,
-	})
-}
-
-// IAMRevoke is the entry point for the IAM revoker Cloud Function.
-//
-// This function will attempt to revoke the external members added to the policy if they
-// match the provided list of disallowed domains. Additionally this method will only remove
-// members if the project they were added to is within the specified folders. This
-// configuration allows you to take a remediation action only on specific members and folders.
-// For example, you may have a folder "development" where users can experiment without strict
-// policies. However in your "production" folder you may want to revoke any grants that ETD
-// finds as long as they match the domains you specify.
-//
-// Permissions required
-// 	- roles/resourcemanager.folderAdmin to revoke IAM grants.
-//	- roles/viewer to verify the affected project is within the enforced folder.
-//
-func IAMRevoke(ctx context.Context, m pubsub.Message) error {
-	var values revoke.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return revoke.Execute(ctx, &values, &revoke.Services{
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
+	threshold, cooldown := breakerConfig()
+	breaker, err = services.InitBreaker(ctx, projectID, circuitCollection, threshold, cooldown)
+	if err != nil {
+		log.Fatalf("failed to initialize circuit breaker: %q", err)
 	}
-}
-
-// SnapshotDisk is the entry point for the auto creation of GCE snapshots Cloud Function.
-//
-// Once a supported finding is received this Cloud Function will look for any existing disk snapshots
-// for the affected instance. If there are recent snapshots then no action is taken. This is so we
-// do not overwrite a recent snapshot. If we have not taken a snapshot recently, take a new snapshot
-// for each disk within the instance.
-//
-// Permissions required
-//	- roles/compute.instanceAdmin.v1 to manage disk snapshots.
-//
-func SnapshotDisk(ctx context.Context, m pubsub.Message) error {
-	var values createsnapshot.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		output, err := createsnapshot.Execute(ctx, &values, &createsnapshot.Services{
-			Host:   svcs.Host,
-			Logger: svcs.Logger,
-		})
-		if err != nil {
-			return err
-		}
-		for _, dest := range values.Output {
-			switch dest {
-			case "turbinia":
-				log.Println("turbinia output is enabled, sending each copied disk to turbinia")
-				turbiniaProjectID := values.Turbinia.ProjectID
-				turbiniaTopicName := values.Turbinia.Topic
-				turbiniaZone := values.Turbinia.Zone
-				diskNames := output.DiskNames
-				if err := services.SendTurbinia(ctx, turbiniaProjectID, turbiniaTopicName, turbiniaZone, diskNames); err != nil {
-					return err
-				}
-				svcs.Logger.Info("sent %d disks to turbinia", len(diskNames))
-			}
-		}
-		return nil
-	default:
-		return err
+	checkpoints, err = services.InitCheckpoints(ctx, projectID, runsCollection)
+	if err != nil {
+		log.Fatalf("failed to initialize checkpoints: %q", err)
 	}
-}
-
-// CloseBucket will remove any public users from buckets found within the provided folders.
-//
-// Permissions required
-//	- roles/viewer to retrieve ancestry.
-//	- roles/storeage.admin to modify buckets.
-//
-func CloseBucket(ctx context.Context, m pubsub.Message) error {
-	var values closebucket.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return closebucket.Execute(ctx, &values, &closebucket.Services{
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
+	if os.Getenv("ERROR_REPORTING") != "false" {
+		if errorReporting, err = services.InitErrorReporting(ctx, projectID); err != nil {
+			log.Fatalf("failed to initialize error reporting: %q", err)
+		}
 	}
-}
-
-// OpenFirewall will remediate an open firewall.
-//
-// Permissions required
-//	- roles/viewer to retrieve ancestry.
-//	- roles/compute.securityAdmin to modify firewall rules.
-//
-func OpenFirewall(ctx context.Context, m pubsub.Message) error {
-	var values openfirewall.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		err := openfirewall.Execute(ctx, &values, &openfirewall.Services{
-			Firewall: svcs.Firewall,
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-		if err != nil {
-			return err
+	if queue := os.Getenv("TASKS_QUEUE"); queue != "" {
+		if tasks, err = services.InitTasks(ctx, queue, projectID, os.Getenv("TASKS_SERVICE_ACCOUNT")); err != nil {
+			log.Fatalf("failed to initialize tasks: %q", err)
+		}
+		if throttle, err = services.InitThrottle(ctx, projectID, quotaCollection); err != nil {
+			log.Fatalf("failed to initialize throttle: %q", err)
 		}
-		return nil
-	default:
-		return err
 	}
-}
-
-// RemoveNonOrganizationMembers removes all members that do not match the organization domain.
-//
-// This Cloud Function will respond to Security Health Analytics **NON_ORG_IAM_MEMBER** findings from **IAM Scanner**.
-// All user member types (user:) that do not correspond to the organization will be removed from policy binding.
-//
-// Permissions required
-//	- roles/resourcemanager.organizationAdmin to get org info and policies and set policies.
-//
-func RemoveNonOrganizationMembers(ctx context.Context, m pubsub.Message) error {
-	var values removenonorgmembers.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return removenonorgmembers.Execute(ctx, &values, &removenonorgmembers.Services{
-			Logger:   svcs.Logger,
-			Resource: svcs.Resource,
-		})
-	default:
-		return err
+	if ttl, shared := ancestryConfig(); svcs.Resource != nil && ttl > 0 {
+		var store *services.Checkpoints
+		if shared {
+			if store, err = services.InitCheckpoints(ctx, projectID, ancestryCollection); err != nil {
+				log.Fatalf("failed to initialize ancestry cache: %q", err)
+			}
+		}
+		svcs.Resource.CacheAncestry(ttl, store)
 	}
 }
 
-// RemovePublicIP removes all the external IP addresses of a GCE instance.
-//
-// This Cloud Function will respond to Security Health Analytics **Public IP Address** findings
-// from **Compute Instance Scanner**. All public IP addresses of the affected instance will be
-// deleted when this function is activated.
-//
-// Permissions required
-//	- roles/compute.instanceAdmin.v1 to get instance data and delete access config.
-//
-func RemovePublicIP(ctx context.Context, m pubsub.Message) error {
-	var values removepublicip.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return removepublicip.Execute(ctx, &values, &removepublicip.Services{
-			Host:     svcs.Host,
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
-	}
+// secret returns the environment variable, or the Secret Manager secret it references.
+func secret(ctx context.Context, env string) (string, error) {
+	return secrets.Resolve(ctx, os.Getenv(env))
 }
 
-// ClosePublicDataset removes public access of a BigQuery dataset.
+// Playbook runs the automations of a playbook in order for one finding.
 //
-// This Cloud Function will respond to Security Health Analytics **Public Dataset** findings
-// from **Dataset Scanner**. All public access of the affected dataset will be
-// removed when this function is activated.
+// The router sends the automations configured with the same playbook to this function, which runs
+// each step and publishes the outcome of every step to the threat-findings-playbook-results topic.
 //
 // Permissions required
-//	- roles/bigquery.dataOwner to get and update dataset metadata.
+//	- roles/viewer to look up the instances referenced by conditions.
+//	- The permissions of every automation run in a playbook.
 //
-func ClosePublicDataset(ctx context.Context, m pubsub.Message) error {
-	var values closepublicdataset.Values
+func Playbook(ctx context.Context, m pubsub.Message) error {
+	var values playbook.Values
 	switch err := json.Unmarshal(m.Data, &values); err {
 	case nil:
-		bigquery, err := services.InitBigQuery(ctx, values.ProjectID)
+		ps, err := pubSub(ctx)
 		if err != nil {
 			return err
 		}
-		return closepublicdataset.Execute(ctx, &values, &closepublicdataset.Services{
-			BigQuery: bigquery,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
-	}
-}
-
-// EnableBucketOnlyPolicy Enable bucket only policy on a GCS bucket.
-//
-// This Cloud Function will respond to Security Health Analytics **BUCKET_POLICY_ONLY_DISABLED** findings
-// from **STORAGE_SCANNER**. Bucket only IAM policy will be enforced on the bucket.
-//
-// Permissions required
-//	- roles/storage.admin to change the Bucket policy mode.
-//
-func EnableBucketOnlyPolicy(ctx context.Context, m pubsub.Message) error {
-	var values enablebucketonlypolicy.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return enablebucketonlypolicy.Execute(ctx, &values, &enablebucketonlypolicy.Services{
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
-	}
-}
-
-// CloseCloudSQL removes public IP for a Cloud SQL instance.
-//
-// This Cloud Function will respond to Security Health Analytics **Public SQL Instance** findings
-// from **SQL Scanner**. All public IP addresses of the affected instance will be
-// deleted when this function is activated.
-//
-// Permissions required
-//	- roles/cloudsql.editor to get instance data and delete access config.
-//
-func CloseCloudSQL(ctx context.Context, m pubsub.Message) error {
-	var values removepublic.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return removepublic.Execute(ctx, &values, &removepublic.Services{
-			CloudSQL: svcs.CloudSQL,
-			Resource: svcs.Resource,
+		return playbook.Execute(ctx, &values, &playbook.Services{
+			Handlers: playbookHandlers(),
+			Host:     svcs.Host,
+			PubSub:   ps,
 			Logger:   svcs.Logger,
 		})
 	default:
@@ -322,93 +249,53 @@ func CloseCloudSQL(ctx context.Context, m pubsub.Message) error {
 	}
 }
 
-// CloudSQLRequireSSL enables the SSL requirement for a Cloud SQL instance.
+// Webhook invokes the Pub/Sub entry points over HTTP.
 //
-// This Cloud Function will respond to Security Health Analytics **Public SQL Instance** findings
-// from **SQL Scanner**. All public IP addresses of the affected instance will be
-// deleted when this function is activated.
+// SOAR platforms and external detectors POST the finding or automation values to
+// /<EntryPoint>, e.g. /Router, signed with the secret held in the WEBHOOK_SECRET environment
+// variable, or in the Secret Manager secret it references. See the webhook package for the
+// signature format. GET /healthz, /readyz and /version are served without a signature.
 //
 // Permissions required
-//	- roles/cloudsql.editor to get instance data and delete access config.
+//	- The permissions of every entry point invoked through the webhook.
 //
-func CloudSQLRequireSSL(ctx context.Context, m pubsub.Message) error {
-	var values requiressl.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return requiressl.Execute(ctx, &values, &requiressl.Services{
-			CloudSQL: svcs.CloudSQL,
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
+func Webhook(w http.ResponseWriter, r *http.Request) {
+	if probes.Handles(r) {
+		probes.ServeHTTP(w, r)
+		return
 	}
-}
-
-// DisableDashboard will disable the Kubernetes dashboard addon.
-//
-// This Cloud Function will respond to Security Health Analytics **Web UI Enabled** findings
-// from **Container Scanner**. The Kubernetes dashboard addon will be disabled when this
-// function is activated.
-//
-// Permissions required
-//	- roles/container.clusterAdmin update cluster addon.
-//
-func DisableDashboard(ctx context.Context, m pubsub.Message) error {
-	var values disabledashboard.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return disabledashboard.Execute(ctx, &values, &disabledashboard.Services{
-			Container: svcs.Container,
-			Resource:  svcs.Resource,
-			Logger:    svcs.Logger,
-		})
-	default:
-		return err
+	key, err := secret(r.Context(), "WEBHOOK_SECRET")
+	if err != nil {
+		log.Printf("failed to read webhook secret: %q", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
 	}
-}
-
-// EnableAuditLogs enables the Audit Logs to specific project
-//
-// This Cloud Function will respond to Security Health Analytics **AUDIT_LOGGING_DISABLED** findings
-// from **LOGGING_SCANNER**.
-//
-// Permissions required
-//	- roles/resourcemanager.folderAdmin to get/update resource policy from projects in folder.
-//	- roles/editor to get/update resource policy to specific project.
-//
-func EnableAuditLogs(ctx context.Context, m pubsub.Message) error {
-	var values enableauditlogs.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return enableauditlogs.Execute(ctx, &values, &enableauditlogs.Services{
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
+	h, err := webhook.New(key, webhookHandlers)
+	if err != nil {
+		log.Printf("failed to initialize webhook: %q", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
 	}
+	h.ServeHTTP(w, r)
 }
 
-// UpdatePassword updates the root password for a Cloud SQL instance.
+// CloudEvent is the entry point of the functions deployed on 2nd gen Cloud Functions or Cloud Run.
 //
-// This Cloud Function will respond to Security Health Analytics **SQL No Root Password** findings
-// from **SQL Scanner**. The root user of the affected instance will be updated with
-// a new password when this function is activated.
+// It runs the Pub/Sub entry point named by the EVENT_HANDLER environment variable, such as
+// "Router" or "CloseBucket", for the messages Eventarc delivers as CloudEvents. The functions
+// behave as their 1st gen counterparts, with the longer timeouts and the concurrent requests per
+// instance the newer platforms allow.
 //
 // Permissions required
-//	- roles/cloudsql.admin to update a user password.
-//
-func UpdatePassword(ctx context.Context, m pubsub.Message) error {
-	var values updatepassword.Values
-	switch err := json.Unmarshal(m.Data, &values); err {
-	case nil:
-		return updatepassword.Execute(ctx, &values, &updatepassword.Services{
-			CloudSQL: svcs.CloudSQL,
-			Resource: svcs.Resource,
-			Logger:   svcs.Logger,
-		})
-	default:
-		return err
+//	- The permissions of the entry point named by EVENT_HANDLER.
+//
+func CloudEvent(w http.ResponseWriter, r *http.Request) {
+	name := os.Getenv("EVENT_HANDLER")
+	h, ok := eventHandlers[name]
+	if !ok {
+		log.Printf("EVENT_HANDLER %q is not an entry point", name)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
 	}
+	cloudevent.Serve(name, h).ServeHTTP(w, r)
 }
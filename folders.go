@@ -0,0 +1,103 @@
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// delegate holds the services of a folder remediated as its own service account.
+type delegate struct {
+	provider clients.CredentialsProvider
+	svcs     *services.Global
+}
+
+// delegates maps folder IDs to their delegates, as configured by FOLDER_SERVICE_ACCOUNTS.
+var delegates map[string]*delegate
+
+// initDelegates creates the services of each folder listed in FOLDER_SERVICE_ACCOUNTS, a comma
+// separated list of folder IDs and the service account impersonated to remediate their projects,
+// such as "123=sra@bu-a.iam.gserviceaccount.com,456=sra@bu-b.iam.gserviceaccount.com".
+//
+// Logs are still written as the function's service account.
+func initDelegates(ctx context.Context) (map[string]*delegate, error) {
+	env := os.Getenv("FOLDER_SERVICE_ACCOUNTS")
+	if env == "" {
+		return nil, nil
+	}
+	byFolder := make(map[string]*delegate)
+	for _, entry := range strings.Split(env, ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid FOLDER_SERVICE_ACCOUNTS entry %q, want folder=service account", entry)
+		}
+		p := clients.Impersonated{ServiceAccount: kv[1]}
+		s, err := services.NewFor(clients.WithProvider(ctx, p), requiredServices()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize services of folder %q: %q", kv[0], err)
+		}
+		s.Logger = svcs.Logger
+		byFolder[kv[0]] = &delegate{provider: p, svcs: s}
+	}
+	return byFolder, nil
+}
+
+// route returns the context and services to remediate the finding with: the delegate of the
+// nearest folder of the finding's project that has one, the function's own otherwise. Their logger
+// appends the correlation ID of the message to its entries.
+//
+// Clients created with the returned context authenticate as the delegate too, and the changes the
+// services make to policies and firewall rules with it are logged with the returned logger.
+func route(ctx context.Context, m pubsub.Message) (context.Context, *services.Global) {
+	ctx, g := delegateOf(ctx, m)
+	if id := m.Attributes[services.CorrelationAttribute]; id != "" {
+		correlated := *g
+		correlated.Logger = g.Logger.WithCorrelation(id)
+		g = &correlated
+	}
+	return services.WithChangeLogger(ctx, g.Logger), g
+}
+
+// delegateOf returns the context and services of the delegate of the finding's project, the
+// function's own if it has none.
+func delegateOf(ctx context.Context, m pubsub.Message) (context.Context, *services.Global) {
+	if len(delegates) == 0 {
+		return ctx, svcs
+	}
+	var v struct{ ProjectID string }
+	if json.Unmarshal(m.Data, &v) != nil || v.ProjectID == "" {
+		return ctx, svcs
+	}
+	folders, err := svcs.Resource.Folders(ctx, v.ProjectID)
+	if err != nil {
+		log.Printf("failed to get folders of %q, remediating as the function's service account: %q", v.ProjectID, err)
+		return ctx, svcs
+	}
+	for _, f := range folders {
+		if d, ok := delegates[f]; ok {
+			return clients.WithProvider(ctx, d.provider), d.svcs
+		}
+	}
+	return ctx, svcs
+}
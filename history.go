@@ -0,0 +1,312 @@
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/machine"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+const (
+	// historyCollection is the Firestore collection remediations are recorded in.
+	historyCollection = "remediations"
+	// circuitCollection is the Firestore collection the circuits of the actions are kept in.
+	circuitCollection = "circuits"
+	// quotaCollection is the Firestore collection the token buckets of rate limited APIs are kept in.
+	quotaCollection = "quotas"
+	// runsCollection is the Firestore collection the runs of multi-step remediations are kept in.
+	runsCollection = "runs"
+	// ancestryCollection is the Firestore collection the ancestry of projects is cached in.
+	ancestryCollection = "ancestry"
+	// reservedAttribute marks the messages deferred by the throttle, their token already taken.
+	reservedAttribute = "quotaReserved"
+)
+
+// resourceKeys maps the fields of action values to the resource path segments they name.
+var resourceKeys = []struct{ key, kind string }{
+	{"AccountID", "accounts"},
+	{"ProjectID", "projects"},
+	{"Zone", "zones"},
+	{"InstanceZone", "zones"},
+	{"Instance", "instances"},
+	{"InstanceID", "instances"},
+	{"BucketName", "buckets"},
+	{"DatasetID", "datasets"},
+	{"FirewallID", "firewalls"},
+	{"ImageURI", "images"},
+}
+
+// breakerConfig returns the consecutive failures tripping the circuit of an action and how long it
+// stays open, from CIRCUIT_BREAKER_THRESHOLD and CIRCUIT_BREAKER_COOLDOWN, by default 5 and 15m.
+func breakerConfig() (int, time.Duration) {
+	threshold, cooldown := 5, 15*time.Minute
+	if n, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_THRESHOLD")); err == nil && n >= 0 {
+		threshold = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("CIRCUIT_BREAKER_COOLDOWN")); err == nil {
+		cooldown = d
+	}
+	return threshold, cooldown
+}
+
+// ancestryConfig returns how long the ancestry of projects is cached for, five minutes unless
+// ANCESTRY_CACHE_TTL is set with 0 disabling the cache, and whether the cache is shared by the
+// instances of the function through Firestore, as when ANCESTRY_CACHE_SHARED is "true".
+func ancestryConfig() (time.Duration, bool) {
+	ttl := 5 * time.Minute
+	if d, err := time.ParseDuration(os.Getenv("ANCESTRY_CACHE_TTL")); err == nil {
+		ttl = d
+	}
+	return ttl, os.Getenv("ANCESTRY_CACHE_SHARED") == "true"
+}
+
+// configTTL returns how long the router's configuration and policies are cached for, a minute
+// unless CONFIG_CACHE_TTL is set with 0 reading them for every finding.
+func configTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("CONFIG_CACHE_TTL")); err == nil {
+		return d
+	}
+	return time.Minute
+}
+
+// redactionConfig returns the redactor of the newline separated built-in pattern names and regular
+// expressions of REDACTION_PATTERNS, every built-in pattern if it is not set and none if "none".
+func redactionConfig() (*services.Redactor, error) {
+	patterns := strings.TrimSpace(os.Getenv("REDACTION_PATTERNS"))
+	switch patterns {
+	case "":
+		return services.DefaultRedactor(), nil
+	case "none":
+		return nil, nil
+	}
+	var names []string
+	for _, p := range strings.Split(patterns, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			names = append(names, p)
+		}
+	}
+	return services.NewRedactor(names)
+}
+
+// deferred takes a token for the rate limited API the action is throttled by, returning true if
+// there was none left and the message was deferred through Cloud Tasks until its token is
+// available. QUOTA_PER_MINUTE overrides the action's rate.
+//
+// Failing to throttle is logged and the action runs.
+func deferred(ctx context.Context, action string, m pubsub.Message) bool {
+	a, ok := registry.Lookup(action)
+	if !ok || a.Quota == "" || throttle == nil || m.Attributes[reservedAttribute] != "" {
+		return false
+	}
+	perMinute := a.QuotaPerMinute
+	if n, err := strconv.Atoi(os.Getenv("QUOTA_PER_MINUTE")); err == nil {
+		perMinute = n
+	}
+	wait, err := throttle.Take(ctx, a.Quota, perMinute)
+	if err != nil {
+		log.Printf("failed to throttle %s: %q", action, err)
+		return false
+	}
+	if wait == 0 {
+		return false
+	}
+	attributes := map[string]string{reservedAttribute: "true"}
+	for k, v := range m.Attributes {
+		attributes[k] = v
+	}
+	at := time.Now().Add(wait)
+	if err := tasks.PublishAt(ctx, a.Topic, m.Data, attributes, at); err != nil {
+		log.Printf("failed to defer %s: %q", action, err)
+		return false
+	}
+	svcs.Logger.Info("%s quota of %s exhausted, deferred finding %q to %s", action, a.Quota, m.Attributes["finding"], at.Format(time.RFC3339))
+	return true
+}
+
+// claim claims the remediation of the message's finding by the action in the remediation
+// history, returning false if it was already applied and the action must not run again. Messages
+// without a finding can't be told apart and are always run.
+//
+// Failing to claim is logged and the action runs, a remediation applied twice is better than one
+// never applied.
+func claim(ctx context.Context, action string, m pubsub.Message) (*services.Remediation, bool) {
+	if breaker != nil {
+		ok, err := breaker.Allow(ctx, action)
+		if err != nil {
+			log.Printf("failed to check circuit: %q", err)
+		}
+		if err == nil && !ok {
+			svcs.Logger.Error("%s is paused or its circuit is open, skipping finding %q", action, m.Attributes["finding"])
+			return nil, false
+		}
+	}
+	// The later steps of a multi-step remediation run under the claim of its first step.
+	if m.Attributes[machine.RunAttribute] != "" {
+		return nil, true
+	}
+	if deferred(ctx, action, m) {
+		return nil, false
+	}
+	if history == nil {
+		return nil, true
+	}
+	r := &services.Remediation{
+		Finding:       m.Attributes["finding"],
+		CorrelationID: m.Attributes[services.CorrelationAttribute],
+		Category:      m.Attributes["category"],
+		Action:        action,
+		Resource:      resourceOf(m.Data),
+		Started:       time.Now(),
+	}
+	if t, err := time.Parse(time.RFC3339Nano, m.Attributes["created"]); err == nil {
+		r.FindingCreated = t
+	}
+	if r.Finding == "" {
+		sum := sha256.Sum256([]byte(action + "|" + r.Started.Format(time.RFC3339Nano)))
+		r.ID = hex.EncodeToString(sum[:])
+		return r, true
+	}
+	r.ID = services.IdempotencyKey(r.Finding, claimedAction(action, m.Data))
+	ok, err := history.Claim(ctx, r)
+	if err != nil {
+		log.Printf("failed to claim remediation: %q", err)
+		return r, true
+	}
+	if !ok {
+		log.Printf("%s already applied to finding %q, skipping", action, r.Finding)
+	}
+	return r, ok
+}
+
+// record saves the outcome of the claimed remediation in the remediation history and counts it in
+// the action's circuit, called deferred from the action's entry point with the error it returns.
+//
+// Actions returning registry.ErrAlreadyRemediated are recorded as no-ops and those returning
+// registry.ErrPending as pending, both succeed. Other errors are reported to Error Reporting.
+// Failing to record is logged and does not fail the action.
+func record(ctx context.Context, action string, r *services.Remediation, m pubsub.Message, err *error) {
+	noop := *err != nil && errors.Cause(*err) == registry.ErrAlreadyRemediated
+	if noop {
+		log.Printf("%s found the resource already remediated", action)
+		*err = nil
+	}
+	pending := *err != nil && errors.Cause(*err) == registry.ErrPending
+	if pending {
+		log.Printf("%s deferred its change", action)
+		*err = nil
+	}
+	if *err != nil && errorReporting != nil {
+		if rerr := errorReporting.Report(ctx, action, *err); rerr != nil {
+			log.Printf("failed to report error: %q", rerr)
+		}
+	}
+	if breaker != nil {
+		tripped, berr := breaker.Record(ctx, action, *err != nil)
+		if berr != nil {
+			log.Printf("failed to record circuit: %q", berr)
+		}
+		if tripped {
+			svcs.Logger.Error("circuit of %s tripped: %q", action, *err)
+		}
+	}
+	if r == nil {
+		return
+	}
+	r.Outcome = services.RemediationSucceeded
+	r.Finished = time.Now()
+	var v struct{ DryRun bool }
+	if json.Unmarshal(m.Data, &v) == nil && v.DryRun {
+		r.Outcome = services.RemediationDryRun
+	}
+	if noop {
+		r.Outcome = services.RemediationNoOp
+	}
+	if pending {
+		r.Outcome = services.RemediationPending
+	}
+	if *err != nil {
+		r.Outcome = services.RemediationFailed
+		r.Error = (*err).Error()
+	}
+	if p, ok := errors.Cause(*err).(*services.PartialError); ok {
+		r.Completed = p.Completed
+		for resource := range p.Failed {
+			r.Failed = append(r.Failed, resource)
+		}
+		sort.Strings(r.Failed)
+	}
+	// The time-to-remediate metric reads these entries, keep the message in sync with its filter.
+	if d, ok := r.TimeToRemediate(); ok {
+		svcs.Logger.Info("remediated %s finding %q with %s in %d seconds", r.Category, r.Finding, action, int64(d.Seconds()))
+	}
+	if err := history.Save(ctx, r); err != nil {
+		log.Printf("failed to record remediation: %q", err)
+	}
+}
+
+// resourceOf returns the resource the values of an action name, as precisely as they name it.
+func resourceOf(data []byte) string {
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, k := range resourceKeys {
+		if s, ok := v[k.key].(string); ok && s != "" {
+			parts = append(parts, k.kind, s)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// claimedAction returns what the remediation of a finding is claimed for: the action, along with
+// its mode for actions running in one such as "shutdown_project/lien". A finding remediated in one
+// mode is remediated again when routed to the action in another, such as a project held by a lien
+// then shut down.
+func claimedAction(action string, data []byte) string {
+	var v struct{ Mode string }
+	if json.Unmarshal(data, &v) != nil || v.Mode == "" {
+		return action
+	}
+	return action + "/" + v.Mode
+}
+
+// progress returns the progress of the claimed remediation, resuming what an earlier attempt left.
+// Progress is not kept across attempts when the remediation is not recorded.
+func progress(ctx context.Context, r *services.Remediation) *services.Progress {
+	if r == nil || checkpoints == nil {
+		return &services.Progress{}
+	}
+	p, err := services.NewProgress(ctx, checkpoints, "progress-"+r.ID)
+	if err != nil {
+		log.Printf("failed to load progress: %q", err)
+		return &services.Progress{}
+	}
+	return p
+}
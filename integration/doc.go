@@ -0,0 +1,23 @@
+// Package integration holds an opt-in test suite running the automations of a deployment against
+// disposable resources in a sandbox project.
+//
+// The tests are built with the integration build tag and skipped unless SRA_INTEGRATION_PROJECT
+// names the sandbox project:
+//
+//	SRA_INTEGRATION_PROJECT=my-sandbox SRA_INTEGRATION_AUTOMATION_PROJECT=my-automation-project \
+//	  SRA_INTEGRATION_ORGANIZATION=1234567890 go test -tags integration -v ./integration
+package integration
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
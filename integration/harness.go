@@ -0,0 +1,297 @@
+//go:build integration
+// +build integration
+
+package integration
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"cloud.google.com/go/iam"
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/storage"
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// timeout is how long a remediation is waited for once its finding is published.
+const timeout = 5 * time.Minute
+
+// corpus is the directory of the simulator's sample payloads.
+var corpus = filepath.Join("..", "cmd", "simulate", "findings")
+
+// Harness provisions disposable resources in the sandbox project, publishes findings about them
+// and deletes them once the test is done.
+type Harness struct {
+	Project       string
+	ProjectNumber string
+	Organization  string
+	Folder        string
+	Zone          string
+	// Topic is the router's topic in the automation project.
+	Topic string
+	// ID is unique to the run, naming the resources it creates.
+	ID string
+
+	storage    *storage.Client
+	compute    *compute.Service
+	Storage    *clients.Storage
+	Compute    *clients.Compute
+	CRM        *clients.CloudResourceManager
+	pubsub     *services.PubSub
+	cleanups   []func()
+	publishing map[string]string
+}
+
+// getenv returns the environment variable or the fallback if it is not set.
+func getenv(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// New returns a harness for the sandbox project named by SRA_INTEGRATION_PROJECT, skipping the
+// test if it is not set. Call Close when done to delete the resources created.
+func New(t *testing.T) *Harness {
+	project := os.Getenv("SRA_INTEGRATION_PROJECT")
+	if project == "" {
+		t.Skip("SRA_INTEGRATION_PROJECT is not set")
+	}
+	ctx := context.Background()
+	h := &Harness{
+		Project:      project,
+		Organization: os.Getenv("SRA_INTEGRATION_ORGANIZATION"),
+		Folder:       os.Getenv("SRA_INTEGRATION_FOLDER"),
+		Zone:         getenv("SRA_INTEGRATION_ZONE", "us-central1-a"),
+		Topic:        getenv("SRA_INTEGRATION_TOPIC", "threat-findings-router"),
+		ID:           randomID(t)[:8],
+	}
+	automationProject := os.Getenv("SRA_INTEGRATION_AUTOMATION_PROJECT")
+	if h.Organization == "" || automationProject == "" {
+		t.Fatal("SRA_INTEGRATION_ORGANIZATION and SRA_INTEGRATION_AUTOMATION_PROJECT are required")
+	}
+	var err error
+	if h.storage, err = storage.NewClient(ctx); err != nil {
+		t.Fatalf("failed to init storage: %q", err)
+	}
+	if h.compute, err = compute.NewService(ctx); err != nil {
+		t.Fatalf("failed to init compute: %q", err)
+	}
+	if h.Storage, err = clients.NewStorage(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if h.Compute, err = clients.NewCompute(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if h.CRM, err = clients.NewCloudResourceManager(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if h.pubsub, err = services.InitPubSub(ctx, automationProject); err != nil {
+		t.Fatal(err)
+	}
+	p, err := h.CRM.GetProject(ctx, project)
+	if err != nil {
+		t.Fatalf("failed to get project %q: %q", project, err)
+	}
+	h.ProjectNumber = strconv.FormatInt(p.ProjectNumber, 10)
+	return h
+}
+
+// randomID returns a random hex ID.
+func randomID(t *testing.T) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Close deletes the resources created, most recent first.
+func (h *Harness) Close() {
+	for i := len(h.cleanups) - 1; i >= 0; i-- {
+		h.cleanups[i]()
+	}
+}
+
+// cleanup registers fn to be run on Close.
+func (h *Harness) cleanup(fn func()) {
+	h.cleanups = append(h.cleanups, fn)
+}
+
+// PublicBucket creates a bucket readable by allUsers and returns its name.
+func (h *Harness) PublicBucket(t *testing.T) string {
+	ctx := context.Background()
+	name := fmt.Sprintf("sra-integration-%s", h.ID)
+	b := h.storage.Bucket(name)
+	if err := b.Create(ctx, h.Project, nil); err != nil {
+		t.Fatalf("failed to create bucket %q: %q", name, err)
+	}
+	h.cleanup(func() {
+		if err := b.Delete(ctx); err != nil {
+			t.Logf("failed to delete bucket %q: %q", name, err)
+		}
+	})
+	p, err := h.Storage.BucketPolicy(ctx, name)
+	if err != nil {
+		t.Fatalf("failed to get policy of %q: %q", name, err)
+	}
+	p.Add("allUsers", iam.RoleName("roles/storage.objectViewer"))
+	if err := h.Storage.SetBucketPolicy(ctx, name, p); err != nil {
+		t.Fatalf("failed to make %q public: %q", name, err)
+	}
+	return name
+}
+
+// Instance creates a small instance with an external IP address and returns its name.
+func (h *Harness) Instance(t *testing.T) string {
+	ctx := context.Background()
+	name := fmt.Sprintf("sra-integration-%s", h.ID)
+	op, err := h.compute.Instances.Insert(h.Project, h.Zone, &compute.Instance{
+		Name:        name,
+		MachineType: fmt.Sprintf("zones/%s/machineTypes/e2-micro", h.Zone),
+		Disks: []*compute.AttachedDisk{{
+			Boot:             true,
+			AutoDelete:       true,
+			InitializeParams: &compute.AttachedDiskInitializeParams{SourceImage: "projects/debian-cloud/global/images/family/debian-11"},
+		}},
+		NetworkInterfaces: []*compute.NetworkInterface{{
+			Network:       "global/networks/default",
+			AccessConfigs: []*compute.AccessConfig{{Name: "External NAT", Type: "ONE_TO_ONE_NAT"}},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		t.Fatalf("failed to create instance %q: %q", name, err)
+	}
+	h.cleanup(func() {
+		if _, err := h.compute.Instances.Delete(h.Project, h.Zone, name).Context(ctx).Do(); err != nil {
+			t.Logf("failed to delete instance %q: %q", name, err)
+		}
+	})
+	if errs := h.Compute.WaitZone(h.Project, h.Zone, op); len(errs) > 0 {
+		t.Fatalf("failed to create instance %q: %q", name, errs[0])
+	}
+	return name
+}
+
+// Grant grants the role on the sandbox project to the member.
+func (h *Harness) Grant(t *testing.T, role, member string) {
+	ctx := context.Background()
+	update := func(fn func(*crm.Policy)) error {
+		p, err := h.CRM.GetPolicyProject(ctx, h.Project)
+		if err != nil {
+			return err
+		}
+		fn(p)
+		_, err = h.CRM.SetPolicyProject(ctx, h.Project, p)
+		return err
+	}
+	if err := update(func(p *crm.Policy) {
+		p.Bindings = append(p.Bindings, &crm.Binding{Role: role, Members: []string{member}})
+	}); err != nil {
+		t.Fatalf("failed to grant %q to %q: %q", role, member, err)
+	}
+	h.cleanup(func() {
+		if err := update(func(p *crm.Policy) {
+			for _, b := range p.Bindings {
+				if b.Role != role {
+					continue
+				}
+				var kept []string
+				for _, m := range b.Members {
+					if m != member {
+						kept = append(kept, m)
+					}
+				}
+				b.Members = kept
+			}
+		}); err != nil {
+			t.Logf("failed to revoke %q from %q: %q", role, member, err)
+		}
+	})
+}
+
+// Publish renders the simulator's sample payload of the category about the resource and user and
+// publishes it to the router's topic tagged as simulated.
+func (h *Harness) Publish(t *testing.T, category, resource, user string) {
+	b, err := ioutil.ReadFile(filepath.Join(corpus, category+".json"))
+	if err != nil {
+		t.Fatalf("failed to read the payload of %q: %q", category, err)
+	}
+	tmpl, err := template.New(category).Option("missingkey=error").Funcs(template.FuncMap{
+		"dataset": func(name string) string { return strings.Replace(name, "-", "_", -1) },
+	}).Parse(string(b))
+	if err != nil {
+		t.Fatalf("failed to parse the payload of %q: %q", category, err)
+	}
+	id := randomID(t)
+	var payload bytes.Buffer
+	if err := tmpl.Execute(&payload, map[string]string{
+		"Project":       h.Project,
+		"ProjectNumber": h.ProjectNumber,
+		"Organization":  h.Organization,
+		"Folder":        h.Folder,
+		"Resource":      resource,
+		"User":          user,
+		"ID":            id,
+		"Time":          time.Now().UTC().Format(time.RFC3339Nano),
+	}); err != nil {
+		t.Fatalf("failed to render the payload of %q: %q", category, err)
+	}
+	if _, err := h.pubsub.Publish(context.Background(), h.Topic, &pubsub.Message{
+		Data: payload.Bytes(),
+		Attributes: map[string]string{
+			services.CorrelationAttribute: "integration-" + id[:16],
+			services.SimulatedAttribute:   "true",
+		},
+	}); err != nil {
+		t.Fatalf("failed to publish %q: %q", category, err)
+	}
+	t.Logf("published %s about %q with correlation ID integration-%s", category, resource, id[:16])
+}
+
+// Eventually polls done until it returns true, failing the test if it does not within the timeout.
+func (h *Harness) Eventually(t *testing.T, what string, done func(context.Context) (bool, error)) {
+	ctx := context.Background()
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := done(ctx)
+		if err != nil {
+			t.Logf("%s: %q", what, err)
+		}
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s did not happen within %s", what, timeout)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
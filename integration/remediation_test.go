@@ -0,0 +1,94 @@
+//go:build integration
+// +build integration
+
+package integration
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// The deployment must route the categories below to their automations for the sandbox project,
+// in ./config/sra.yaml: close_bucket for public_bucket_acl, remove_public_ip for
+// public_ip_address and iam_revoke for iam_anomalous_grant, neither in dry run.
+
+func TestPublicBucketClosed(t *testing.T) {
+	h := New(t)
+	defer h.Close()
+	bucket := h.PublicBucket(t)
+	h.Publish(t, "public_bucket_acl", bucket, "")
+	h.Eventually(t, "removing allUsers from "+bucket, func(ctx context.Context) (bool, error) {
+		p, err := h.Storage.BucketPolicy(ctx, bucket)
+		if err != nil {
+			return false, err
+		}
+		for _, role := range p.Roles() {
+			for _, m := range p.Members(role) {
+				if m == "allUsers" || m == "allAuthenticatedUsers" {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+}
+
+func TestPublicIPRemoved(t *testing.T) {
+	h := New(t)
+	defer h.Close()
+	instance := h.Instance(t)
+	h.Publish(t, "public_ip_address", instance, "")
+	h.Eventually(t, "removing the external IP of "+instance, func(ctx context.Context) (bool, error) {
+		i, err := h.Compute.GetInstance(ctx, h.Project, h.Zone, instance)
+		if err != nil {
+			return false, err
+		}
+		for _, ni := range i.NetworkInterfaces {
+			if len(ni.AccessConfigs) > 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+func TestAnomalousGrantRevoked(t *testing.T) {
+	// The member must be a Google account outside of the organization's domains.
+	member := os.Getenv("SRA_INTEGRATION_EXTERNAL_USER")
+	if member == "" {
+		t.Skip("SRA_INTEGRATION_EXTERNAL_USER is not set")
+	}
+	h := New(t)
+	defer h.Close()
+	h.Grant(t, "roles/viewer", "user:"+member)
+	h.Publish(t, "iam_anomalous_grant", "", member)
+	h.Eventually(t, "revoking the grant to "+member, func(ctx context.Context) (bool, error) {
+		p, err := h.CRM.GetPolicyProject(ctx, h.Project)
+		if err != nil {
+			return false, err
+		}
+		for _, b := range p.Bindings {
+			for _, m := range b.Members {
+				if m == "user:"+member {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+}
@@ -32,6 +32,8 @@ module "google-setup" {
   cscc-notifications-topic-prefix = local.cscc-findings-topic
   findings-topic                  = local.findings-topic
   enable-scc-notification         = var.enable-scc-notification
+  action-service-accounts         = var.action-service-accounts
+  folder-service-accounts         = var.folder-service-accounts
 }
 
 module "filter" {
@@ -39,10 +41,17 @@ module "filter" {
   setup  = module.google-setup
 }
 
+module "webhook" {
+  source         = "./cloudfunctions/webhook"
+  setup          = module.google-setup
+  webhook-secret = var.webhook-secret
+}
+
 module "router" {
-  source     = "./cloudfunctions/router/"
-  setup      = module.google-setup
-  folder-ids = var.folder-ids
+  source        = "./cloudfunctions/router/"
+  setup         = module.google-setup
+  folder-ids    = var.folder-ids
+  policy-bucket = var.policy-bucket
 }
 
 module "close_public_bucket" {
@@ -52,9 +61,17 @@ module "close_public_bucket" {
 }
 
 module "revoke_iam_grants" {
-  source     = "./cloudfunctions/iam/revoke"
-  setup      = module.google-setup
-  folder-ids = var.folder-ids
+  source           = "./cloudfunctions/iam/revoke"
+  setup            = module.google-setup
+  folder-ids       = var.folder-ids
+  sendgrid-api-key = var.sendgrid-api-key
+}
+
+module "contain_service_account" {
+  source           = "./cloudfunctions/iam/containserviceaccount"
+  setup            = module.google-setup
+  folder-ids       = var.folder-ids
+  sendgrid-api-key = var.sendgrid-api-key
 }
 
 module "create_disk_snapshot" {
@@ -65,12 +82,54 @@ module "create_disk_snapshot" {
   turbinia-topic-name = ""
 }
 
+module "close_secret" {
+  source     = "./cloudfunctions/secretmanager/closesecret"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "detach_billing" {
+  source     = "./cloudfunctions/billing/detachbilling"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "disable_apis" {
+  source     = "./cloudfunctions/serviceusage/disableapis"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "shutdown_project" {
+  source     = "./cloudfunctions/resourcemanager/shutdownproject"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
 module "enable_bucket_only_policy" {
   source     = "./cloudfunctions/gcs/enablebucketonlypolicy"
   setup      = module.google-setup
   folder-ids = var.folder-ids
 }
 
+module "gcs_prevent_public_access" {
+  source     = "./cloudfunctions/gcs/preventpublicaccess"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "kms_remove_public_access" {
+  source     = "./cloudfunctions/kms/removepublicaccess"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "kms_rotate_key" {
+  source     = "./cloudfunctions/kms/rotatekey"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
 module "open_firewall" {
   source     = "./cloudfunctions/gce/openfirewall"
   setup      = module.google-setup
@@ -83,6 +142,29 @@ module "remove_public_ip" {
   folder-ids = var.folder-ids
 }
 
+module "gce_require_os_login" {
+  source = "./cloudfunctions/gce/requireoslogin"
+  setup  = module.google-setup
+}
+
+module "gce_restrict_external_ips" {
+  source     = "./cloudfunctions/gce/restrictexternalips"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "label_resource" {
+  source     = "./cloudfunctions/triage/labelresource"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "export_evidence_logs" {
+  source     = "./cloudfunctions/triage/exportlogs"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
 module "close_public_dataset" {
   source     = "./cloudfunctions/bigquery/closepublicdataset"
   setup      = module.google-setup
@@ -125,3 +207,131 @@ module "enable_audit_logs" {
 //  setup      = module.google-setup
 //  folder-ids = var.folder-ids
 //}
+
+module "isolate_pod" {
+  source     = "./cloudfunctions/gke/isolatepod"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "cordon_node" {
+  source     = "./cloudfunctions/gke/cordonnode"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "block_ip" {
+  source     = "./cloudfunctions/gce/blockip"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "quarantine_instance" {
+  source     = "./cloudfunctions/gce/quarantineinstance"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "guardduty_bridge" {
+  source         = "./cloudfunctions/aws/snsbridge"
+  setup          = module.google-setup
+  sns-topic-arns = var.aws-sns-topic-arns
+}
+
+module "disable_access_key" {
+  source                = "./cloudfunctions/aws/disableaccesskey"
+  setup                 = module.google-setup
+  aws-access-key-id     = var.aws-access-key-id
+  aws-secret-access-key = var.aws-secret-access-key
+}
+
+module "isolate_ec2_instance" {
+  source                = "./cloudfunctions/aws/isolateinstance"
+  setup                 = module.google-setup
+  aws-access-key-id     = var.aws-access-key-id
+  aws-secret-access-key = var.aws-secret-access-key
+}
+
+module "container_analysis_bridge" {
+  source     = "./cloudfunctions/containeranalysis/occurrencebridge"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "quarantine_image" {
+  source     = "./cloudfunctions/binauthz/quarantineimage"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "workspace_suspend_user" {
+  source = "./cloudfunctions/workspace/suspenduser"
+  setup  = module.google-setup
+}
+
+module "workspace_revoke_sessions" {
+  source = "./cloudfunctions/workspace/revokesessions"
+  setup  = module.google-setup
+}
+
+module "workspace_remove_from_groups" {
+  source = "./cloudfunctions/workspace/removefromgroups"
+  setup  = module.google-setup
+}
+
+module "workspace_enforce_2sv" {
+  source = "./cloudfunctions/workspace/enforce2sv"
+  setup  = module.google-setup
+}
+
+module "vpcsc_add_to_perimeter" {
+  source     = "./cloudfunctions/vpcsc/addtoperimeter"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "vpcsc_restrict_access_level" {
+  source = "./cloudfunctions/vpcsc/restrictaccesslevel"
+  setup  = module.google-setup
+}
+
+module "iam_downgrade_roles" {
+  source     = "./cloudfunctions/iam/downgraderoles"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "report" {
+  source              = "./cloudfunctions/report"
+  setup               = module.google-setup
+  sendgrid-api-key    = var.sendgrid-api-key
+  report-from         = var.report-from
+  report-to           = var.report-to
+  report-chat-webhook = var.report-chat-webhook
+}
+
+module "send_email" {
+  source           = "./cloudfunctions/notify/sendemail"
+  setup            = module.google-setup
+  sendgrid-api-key = var.sendgrid-api-key
+}
+
+module "playbook" {
+  source           = "./cloudfunctions/playbook"
+  setup            = module.google-setup
+  sendgrid-api-key = var.sendgrid-api-key
+}
+
+module "fan_out" {
+  source     = "./cloudfunctions/fanout"
+  setup      = module.google-setup
+  folder-ids = var.folder-ids
+}
+
+module "dead_letter" {
+  source               = "./cloudfunctions/deadletter"
+  setup                = module.google-setup
+  pagerduty-api-key    = var.pagerduty-api-key
+  pagerduty-service-id = var.pagerduty-service-id
+  pagerduty-from       = var.pagerduty-from
+}
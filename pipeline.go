@@ -0,0 +1,206 @@
+//go:build !sra_action
+// +build !sra_action
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/snsbridge"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/containeranalysis/occurrencebridge"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/deadletter"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/fanout"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/report"
+	"github.com/googlecloudplatform/security-response-automation/services"
+)
+
+// The bridges forwarding findings to the findings topic and the functions fanning out work items,
+// handling dead letters and reporting are left out of builds with the sra_action tag, as the
+// Router is.
+
+// GuardDutyBridge forwards AWS GuardDuty findings delivered by Amazon SNS to the findings topic.
+//
+// This HTTPS Cloud Function is subscribed to the SNS topic GuardDuty findings are published to.
+// Messages must be signed by Amazon SNS and come from one of the topics listed in the
+// SNS_TOPIC_ARNS environment variable. Subscription requests are confirmed automatically.
+//
+// Permissions required
+//	- roles/pubsub.publisher to publish findings to the findings topic.
+//
+func GuardDutyBridge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var m services.SNSMessage
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, "invalid sns message", http.StatusBadRequest)
+		return
+	}
+	ps, err := pubSub(ctx)
+	if err != nil {
+		log.Printf("failed to initialize pubsub: %q", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := snsbridge.Execute(ctx, &snsbridge.Values{
+		Topic:     os.Getenv("FINDINGS_TOPIC"),
+		TopicARNs: strings.Split(os.Getenv("SNS_TOPIC_ARNS"), ","),
+		Message:   &m,
+	}, &snsbridge.Services{
+		SNS:    services.InitSNS(),
+		PubSub: ps,
+		Logger: svcs.Logger,
+	}); err != nil {
+		log.Printf("failed to forward sns message: %q", err)
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}
+}
+
+// ContainerAnalysisBridge forwards Container Analysis vulnerability occurrences to the findings topic.
+//
+// This Cloud Function is subscribed to the container-analysis-occurrences-v1beta1 topic. Its
+// notifications only name the occurrence so the full occurrence is looked up and published to
+// the topic named by the FINDINGS_TOPIC environment variable.
+//
+// Permissions required
+//	- roles/containeranalysis.occurrences.viewer to get occurrences.
+//	- roles/pubsub.publisher to publish findings to the findings topic.
+//
+func ContainerAnalysisBridge(ctx context.Context, m pubsub.Message) error {
+	values := occurrencebridge.Values{Topic: os.Getenv("FINDINGS_TOPIC")}
+	switch err := json.Unmarshal(m.Data, &values); err {
+	case nil:
+		ca, err := containerAnalysis(ctx)
+		if err != nil {
+			return err
+		}
+		ps, err := pubSub(ctx)
+		if err != nil {
+			return err
+		}
+		return occurrencebridge.Execute(ctx, &values, &occurrencebridge.Services{
+			ContainerAnalysis: ca,
+			PubSub:            ps,
+			Logger:            svcs.Logger,
+		})
+	default:
+		return err
+	}
+}
+
+// FanOut is the entry point for the FanOut Cloud Function.
+//
+// Actions touching too many projects to remediate in one invocation publish a work item per project
+// to the threat-findings-fan-out topic. This function applies each to its project, with the
+// credentials of the project's folder delegate if any, and a work item failing is delivered again
+// on its own without repeating the others.
+//
+// Permissions required
+//	- roles/resourcemanager.projectIamAdmin to remove members from the IAM policies of projects.
+//
+func FanOut(ctx context.Context, m pubsub.Message) error {
+	var item services.WorkItem
+	switch err := json.Unmarshal(m.Data, &item); err {
+	case nil:
+		ctx, s := route(ctx, m)
+		return fanout.Execute(ctx, &item, &fanout.Services{
+			Resource: s.Resource,
+			Logger:   s.Logger,
+		})
+	default:
+		return err
+	}
+}
+
+// DeadLetter is the entry point for the DeadLetter Cloud Function.
+//
+// Subscriptions forward the messages automations failed to process after their maximum delivery
+// attempts to the threat-findings-dead-letter topic. This function archives each to the bucket
+// named by DEAD_LETTER_BUCKET, logs it for the dead-letter metric and alerts PagerDuty if
+// PAGERDUTY_SERVICE_ID is set.
+//
+// Permissions required
+//	- roles/storage.objectCreator on the dead-letter bucket.
+//
+func DeadLetter(ctx context.Context, m pubsub.Message) error {
+	archive, err := archiveOf(ctx, os.Getenv("DEAD_LETTER_BUCKET"))
+	if err != nil {
+		return err
+	}
+	values := &deadletter.Values{
+		PagerDutyServiceID: os.Getenv("PAGERDUTY_SERVICE_ID"),
+		PagerDutyFrom:      os.Getenv("PAGERDUTY_FROM"),
+	}
+	var pd *services.PagerDuty
+	if values.PagerDutyServiceID != "" {
+		key, err := secret(ctx, "PAGERDUTY_API_KEY")
+		if err != nil {
+			return err
+		}
+		pd = services.InitPagerDuty(key)
+	}
+	return deadletter.Execute(ctx, m, values, &deadletter.Services{
+		Archive:   archive,
+		PagerDuty: pd,
+		Logger:    svcs.Logger,
+		Redactor:  redactor,
+	})
+}
+
+// Report is the entry point for the Report Cloud Function.
+//
+// Cloud Scheduler publishes to the threat-findings-report topic weekly. This function summarizes
+// the remediations recorded in the history over the past week: the findings handled, the outcomes
+// of every action, what actions in dry run would have changed and the noisiest projects. The
+// summary is emailed to the comma separated REPORT_TO recipients from REPORT_FROM and posted to
+// the Google Chat incoming webhook held in REPORT_CHAT_WEBHOOK, or its Secret Manager secret.
+//
+// Permissions required
+//	- roles/datastore.user to read the history.
+//
+func Report(ctx context.Context, m pubsub.Message) error {
+	values := &report.Values{From: os.Getenv("REPORT_FROM")}
+	if to := os.Getenv("REPORT_TO"); to != "" {
+		values.To = strings.Split(to, ",")
+	}
+	var email *services.Email
+	if len(values.To) > 0 {
+		key, err := secret(ctx, "SENDGRID_API_KEY")
+		if err != nil {
+			return err
+		}
+		email = services.InitEmail(key)
+	}
+	var chat *services.Chat
+	webhook, err := secret(ctx, "REPORT_CHAT_WEBHOOK")
+	if err != nil {
+		return err
+	}
+	if webhook != "" {
+		chat = services.InitChat(webhook)
+	}
+	return report.Execute(ctx, m, values, &report.Services{
+		History: history,
+		Email:   email,
+		Chat:    chat,
+		Logger:  svcs.Logger,
+	})
+}
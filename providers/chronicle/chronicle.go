@@ -0,0 +1,193 @@
+// Package chronicle represents Chronicle rule detections delivered by webhook or Pub/Sub export.
+//
+// Detections carry no resource names of their own so the rule must surface the values needed by
+// an automation as outcome variables or match variables with these names:
+//
+//	project_id   project of the affected resource, required by every automation.
+//	ip_address   address blocked by gce_block_ip, optionally with network.
+//	instance_ip  internal address of the instance quarantined by gce_quarantine_instance.
+//	bucket_name  bucket used by close_bucket and enable_bucket_only_policy.
+//	member       member revoked by iam_revoke, may be repeated.
+package chronicle
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// Field is a match variable or outcome variable of a detection.
+type Field struct {
+	Key      string      `json:"key"`
+	Variable string      `json:"variable"`
+	Value    interface{} `json:"value"`
+}
+
+// Rule describes the rule that raised a detection.
+type Rule struct {
+	RuleName        string  `json:"ruleName"`
+	RuleID          string  `json:"ruleId"`
+	RuleVersion     string  `json:"ruleVersion"`
+	AlertState      string  `json:"alertState"`
+	DetectionFields []Field `json:"detectionFields"`
+	Outcomes        []Field `json:"outcomes"`
+}
+
+// Detection is a Chronicle rule detection.
+type Detection struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	CreatedTime string `json:"createdTime"`
+	Detection   []Rule `json:"detection"`
+}
+
+// Finding represents a Chronicle detection.
+type Finding struct {
+	Detection *Detection
+}
+
+// Name returns "chronicle_detection" if the bytes hold a Chronicle rule detection.
+func (f *Finding) Name(b []byte) string {
+	if _, err := New(b); err != nil {
+		return ""
+	}
+	return "chronicle_detection"
+}
+
+// Level returns the severity set by the rule's `severity` outcome variable, if any.
+func (f *Finding) Level(b []byte) severity.Level {
+	ff, err := New(b)
+	if err != nil {
+		return severity.Unknown
+	}
+	return severity.Parse(ff.field("severity"))
+}
+
+// New returns a new Chronicle finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.Detection); err != nil {
+		return nil, err
+	}
+	if f.Detection == nil || f.Detection.Type != "RULE_DETECTION" || len(f.Detection.Detection) == 0 {
+		return nil, fmt.Errorf("not a chronicle rule detection")
+	}
+	return &f, nil
+}
+
+// RuleID returns the ID of the rule that raised the detection.
+func (f *Finding) RuleID() string {
+	return f.Detection.Detection[0].RuleID
+}
+
+// RuleName returns the name of the rule that raised the detection.
+func (f *Finding) RuleName() string {
+	return f.Detection.Detection[0].RuleName
+}
+
+// ProjectID returns the project named by the rule.
+func (f *Finding) ProjectID() string {
+	return f.field("project_id")
+}
+
+// BlockIP returns values for the block IP automation.
+func (f *Finding) BlockIP() (*blockip.Values, error) {
+	ip, err := f.required("ip_address")
+	if err != nil {
+		return nil, err
+	}
+	return &blockip.Values{ProjectID: f.ProjectID(), Network: f.field("network"), IPAddresses: []string{ip}}, nil
+}
+
+// QuarantineInstance returns values for the quarantine instance automation.
+func (f *Finding) QuarantineInstance() (*quarantineinstance.Values, error) {
+	ip, err := f.required("instance_ip")
+	if err != nil {
+		return nil, err
+	}
+	return &quarantineinstance.Values{ProjectID: f.ProjectID(), IPAddress: ip}, nil
+}
+
+// CloseBucket returns values for the close bucket automation.
+func (f *Finding) CloseBucket() (*closebucket.Values, error) {
+	bucket, err := f.required("bucket_name")
+	if err != nil {
+		return nil, err
+	}
+	return &closebucket.Values{ProjectID: f.ProjectID(), BucketName: bucket}, nil
+}
+
+// EnableBucketOnlyPolicy returns values for the enable bucket only policy automation.
+func (f *Finding) EnableBucketOnlyPolicy() (*enablebucketonlypolicy.Values, error) {
+	bucket, err := f.required("bucket_name")
+	if err != nil {
+		return nil, err
+	}
+	return &enablebucketonlypolicy.Values{ProjectID: f.ProjectID(), BucketName: bucket}, nil
+}
+
+// IAMRevoke returns values for the IAM revoke automation.
+func (f *Finding) IAMRevoke() (*revoke.Values, error) {
+	members := f.fields("member")
+	if len(members) == 0 {
+		return nil, fmt.Errorf("rule %q does not set %q", f.RuleName(), "member")
+	}
+	if _, err := f.required("project_id"); err != nil {
+		return nil, err
+	}
+	return &revoke.Values{ProjectID: f.ProjectID(), ExternalMembers: members}, nil
+}
+
+// required returns the value of the field and ensures the project is also known.
+func (f *Finding) required(key string) (string, error) {
+	for _, k := range []string{"project_id", key} {
+		if f.field(k) == "" {
+			return "", fmt.Errorf("rule %q does not set %q", f.RuleName(), k)
+		}
+	}
+	return f.field(key), nil
+}
+
+// field returns the first value of the named field.
+func (f *Finding) field(key string) string {
+	if v := f.fields(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// fields returns all non empty values of the named field from the outcomes and match variables.
+func (f *Finding) fields(key string) []string {
+	var values []string
+	for _, d := range f.Detection.Detection {
+		for _, field := range append(d.Outcomes, d.DetectionFields...) {
+			if field.Key != key && field.Variable != key {
+				continue
+			}
+			if v := fmt.Sprint(field.Value); field.Value != nil && v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
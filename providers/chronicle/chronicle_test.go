@@ -0,0 +1,76 @@
+package chronicle
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
+)
+
+const detection = `{
+	"id": "de_50fd0957-0959-6410-0000-c6f8400006b1",
+	"type": "RULE_DETECTION",
+	"createdTime": "2021-06-01T15:04:05Z",
+	"detection": [{
+		"ruleName": "gcp_anomalous_grant",
+		"ruleId": "ru_e6abfcb5-1b85-41b0-b64c-695b3250436f",
+		"ruleVersion": "ru_e6abfcb5-1b85-41b0-b64c-695b3250436f@v_1605892822_687503000",
+		"alertState": "ALERTING",
+		"detectionFields": [{"key": "project_id", "value": "test-project"}],
+		"outcomes": [
+			{"variable": "member", "value": "user:bob@gmail.com"},
+			{"variable": "member", "value": "user:eve@gmail.com"},
+			{"variable": "ip_address", "value": "198.51.100.7"}
+		]
+	}]
+}`
+
+func TestReadFinding(t *testing.T) {
+	if got := (&Finding{}).Name([]byte(detection)); got != "chronicle_detection" {
+		t.Errorf("Name() got:%q want:%q", got, "chronicle_detection")
+	}
+	if got := (&Finding{}).Name([]byte(`{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`)); got != "" {
+		t.Errorf("Name() of scc finding got:%q want:%q", got, "")
+	}
+	f, err := New([]byte(detection))
+	if err != nil {
+		t.Fatalf("New() failed: %q", err)
+	}
+	if got, want := f.RuleID(), "ru_e6abfcb5-1b85-41b0-b64c-695b3250436f"; got != want {
+		t.Errorf("RuleID() got:%q want:%q", got, want)
+	}
+	iam, err := f.IAMRevoke()
+	if err != nil {
+		t.Fatalf("IAMRevoke() failed: %q", err)
+	}
+	wantIAM := &revoke.Values{ProjectID: "test-project", ExternalMembers: []string{"user:bob@gmail.com", "user:eve@gmail.com"}}
+	if diff := cmp.Diff(wantIAM, iam); diff != "" {
+		t.Errorf("IAMRevoke() difference: %+v", diff)
+	}
+	block, err := f.BlockIP()
+	if err != nil {
+		t.Fatalf("BlockIP() failed: %q", err)
+	}
+	wantBlock := &blockip.Values{ProjectID: "test-project", IPAddresses: []string{"198.51.100.7"}}
+	if diff := cmp.Diff(wantBlock, block); diff != "" {
+		t.Errorf("BlockIP() difference: %+v", diff)
+	}
+	if _, err := f.CloseBucket(); err == nil {
+		t.Errorf("CloseBucket() without bucket_name should fail")
+	}
+}
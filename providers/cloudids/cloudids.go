@@ -0,0 +1,139 @@
+// Package cloudids represents Cloud IDS threat logs exported to Pub/Sub by a log sink.
+//
+// The sink should match `logName:"ids.googleapis.com%2Fthreat"` so only threat logs are exported.
+package cloudids
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// resourceType is the monitored resource type of Cloud IDS endpoints.
+const resourceType = "ids.googleapis.com/Endpoint"
+
+// extractProject is a regex to extract the project ID from the log name.
+var extractProject = regexp.MustCompile(`^projects/([^/]+)/logs/`)
+
+// Threat is the payload of a Cloud IDS threat log.
+type Threat struct {
+	Name                 string   `json:"name"`
+	UniqueThreatID       string   `json:"unique_threat_id"`
+	AlertTime            string   `json:"alert_time"`
+	AlertSeverity        string   `json:"alert_severity"`
+	Type                 string   `json:"type"`
+	Category             string   `json:"category"`
+	ThreatID             string   `json:"threat_id"`
+	SourceIPAddress      string   `json:"source_ip_address"`
+	DestinationIPAddress string   `json:"destination_ip_address"`
+	SourcePort           int      `json:"source_port"`
+	DestinationPort      int      `json:"destination_port"`
+	IPProtocol           string   `json:"ip_protocol"`
+	Direction            string   `json:"direction"`
+	Application          string   `json:"application"`
+	CVEs                 []string `json:"cves"`
+	Network              string   `json:"network"`
+}
+
+// LogEntry is a Cloud IDS threat log entry.
+type LogEntry struct {
+	LogName  string `json:"logName"`
+	Resource struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+	} `json:"resource"`
+	JSONPayload *Threat `json:"jsonPayload"`
+}
+
+// Finding represents a Cloud IDS threat.
+type Finding struct {
+	Log *LogEntry
+}
+
+// Name returns "ids_threat" if the bytes hold a Cloud IDS threat log.
+func (f *Finding) Name(b []byte) string {
+	if _, err := New(b); err != nil {
+		return ""
+	}
+	return "ids_threat"
+}
+
+// Level returns the severity of the threat.
+func (f *Finding) Level(b []byte) severity.Level {
+	ff, err := New(b)
+	if err != nil {
+		return severity.Unknown
+	}
+	return severity.Parse(ff.Severity())
+}
+
+// New returns a new Cloud IDS finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.Log); err != nil {
+		return nil, err
+	}
+	if f.Log == nil || f.Log.Resource.Type != resourceType || f.Log.JSONPayload == nil {
+		return nil, fmt.Errorf("not a cloud ids threat")
+	}
+	return &f, nil
+}
+
+// ProjectID returns the project of the IDS endpoint.
+func (f *Finding) ProjectID() string {
+	i := extractProject.FindStringSubmatch(f.Log.LogName)
+	if len(i) != 2 {
+		return ""
+	}
+	return i[1]
+}
+
+// Severity returns the lower case severity of the threat.
+func (f *Finding) Severity() string {
+	return strings.ToLower(f.Log.JSONPayload.AlertSeverity)
+}
+
+// BlockIP returns values for the block IP automation. The source of the suspicious packet is blocked.
+func (f *Finding) BlockIP() (*blockip.Values, error) {
+	ip := f.Log.JSONPayload.SourceIPAddress
+	if ip == "" {
+		return nil, fmt.Errorf("threat %q has no source ip address", f.Log.JSONPayload.Name)
+	}
+	return &blockip.Values{
+		ProjectID:   f.ProjectID(),
+		Network:     f.Log.JSONPayload.Network,
+		IPAddresses: []string{ip},
+	}, nil
+}
+
+// QuarantineInstance returns values for the quarantine instance automation. The destination of the
+// suspicious packet is quarantined.
+func (f *Finding) QuarantineInstance() (*quarantineinstance.Values, error) {
+	ip := f.Log.JSONPayload.DestinationIPAddress
+	if ip == "" {
+		return nil, fmt.Errorf("threat %q has no destination ip address", f.Log.JSONPayload.Name)
+	}
+	return &quarantineinstance.Values{
+		ProjectID: f.ProjectID(),
+		IPAddress: ip,
+	}, nil
+}
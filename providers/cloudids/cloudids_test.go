@@ -0,0 +1,87 @@
+package cloudids
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/blockip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/quarantineinstance"
+)
+
+const threat = `{
+	"insertId": "1e5kofvf1nxq6b",
+	"jsonPayload": {
+		"name": "Microsoft Windows SMB Remote Code Execution Vulnerability",
+		"unique_threat_id": "7193178295416371459",
+		"alert_time": "2021-06-01T15:04:05Z",
+		"alert_severity": "CRITICAL",
+		"type": "vulnerability",
+		"category": "code-execution",
+		"threat_id": "33000",
+		"source_ip_address": "198.51.100.7",
+		"destination_ip_address": "10.128.0.2",
+		"source_port": 44382,
+		"destination_port": 445,
+		"ip_protocol": "tcp",
+		"direction": "client-to-server",
+		"application": "ms-ds-smb",
+		"cves": ["CVE-2017-0144"],
+		"network": "projects/test-project/global/networks/default"
+	},
+	"resource": {
+		"type": "ids.googleapis.com/Endpoint",
+		"labels": {"resource_container": "projects/test-project", "location": "us-central1-a", "id": "ids-endpoint"}
+	},
+	"logName": "projects/test-project/logs/ids.googleapis.com%2Fthreat",
+	"receiveTimestamp": "2021-06-01T15:04:08Z"
+}`
+
+func TestReadFinding(t *testing.T) {
+	if got := (&Finding{}).Name([]byte(threat)); got != "ids_threat" {
+		t.Errorf("Name() got:%q want:%q", got, "ids_threat")
+	}
+	if got := (&Finding{}).Name([]byte(`{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`)); got != "" {
+		t.Errorf("Name() of scc finding got:%q want:%q", got, "")
+	}
+	f, err := New([]byte(threat))
+	if err != nil {
+		t.Fatalf("New() failed: %q", err)
+	}
+	if f.Severity() != "critical" {
+		t.Errorf("Severity() got:%q want:%q", f.Severity(), "critical")
+	}
+	block, err := f.BlockIP()
+	if err != nil {
+		t.Fatalf("BlockIP() failed: %q", err)
+	}
+	wantBlock := &blockip.Values{
+		ProjectID:   "test-project",
+		Network:     "projects/test-project/global/networks/default",
+		IPAddresses: []string{"198.51.100.7"},
+	}
+	if diff := cmp.Diff(wantBlock, block); diff != "" {
+		t.Errorf("BlockIP() difference: %+v", diff)
+	}
+	quarantine, err := f.QuarantineInstance()
+	if err != nil {
+		t.Fatalf("QuarantineInstance() failed: %q", err)
+	}
+	wantQuarantine := &quarantineinstance.Values{ProjectID: "test-project", IPAddress: "10.128.0.2"}
+	if diff := cmp.Diff(wantQuarantine, quarantine); diff != "" {
+		t.Errorf("QuarantineInstance() difference: %+v", diff)
+	}
+}
@@ -0,0 +1,139 @@
+// Package containeranalysis represents Container Analysis vulnerability occurrences.
+//
+// Container Analysis notifications only name the occurrence so the OccurrenceBridge function
+// looks the occurrence up and publishes it in full to the findings topic. On-Demand Scanning
+// results can be published to the findings topic as is.
+package containeranalysis
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/binauthz/quarantineimage"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/notify/sendemail"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// extractProject is a regex to extract the project ID from the occurrence name.
+var extractProject = regexp.MustCompile(`^projects/([^/]+)/(?:locations/[^/]+/)?occurrences/`)
+
+// Occurrence is a Container Analysis vulnerability occurrence.
+type Occurrence struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	NoteName string `json:"noteName"`
+	Resource struct {
+		URI string `json:"uri"`
+	} `json:"resource"`
+	ResourceURI   string `json:"resourceUri"`
+	Vulnerability *struct {
+		Severity          string  `json:"severity"`
+		EffectiveSeverity string  `json:"effectiveSeverity"`
+		CVSSScore         float64 `json:"cvssScore"`
+		ShortDescription  string  `json:"shortDescription"`
+		FixAvailable      bool    `json:"fixAvailable"`
+	} `json:"vulnerability"`
+}
+
+// Finding represents a Container Analysis vulnerability.
+type Finding struct {
+	Occurrence *Occurrence
+}
+
+// Name returns "container_vulnerability" if the bytes hold a vulnerability occurrence.
+func (f *Finding) Name(b []byte) string {
+	if _, err := New(b); err != nil {
+		return ""
+	}
+	return "container_vulnerability"
+}
+
+// Level returns the effective severity of the vulnerability.
+func (f *Finding) Level(b []byte) severity.Level {
+	ff, err := New(b)
+	if err != nil {
+		return severity.Unknown
+	}
+	return severity.Parse(ff.Severity())
+}
+
+// New returns a new Container Analysis finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.Occurrence); err != nil {
+		return nil, err
+	}
+	if f.Occurrence == nil || f.Occurrence.Kind != "VULNERABILITY" || f.Occurrence.Vulnerability == nil || f.ImageURI() == "" {
+		return nil, fmt.Errorf("not a vulnerability occurrence")
+	}
+	return &f, nil
+}
+
+// ProjectID returns the project holding the occurrence.
+func (f *Finding) ProjectID() string {
+	i := extractProject.FindStringSubmatch(f.Occurrence.Name)
+	if len(i) != 2 {
+		return ""
+	}
+	return i[1]
+}
+
+// ImageURI returns the URI of the vulnerable image.
+//
+// Occurrences from the v1 API name the image in resourceUri, v1beta1 in resource.uri.
+func (f *Finding) ImageURI() string {
+	if f.Occurrence.ResourceURI != "" {
+		return f.Occurrence.ResourceURI
+	}
+	return f.Occurrence.Resource.URI
+}
+
+// CVE returns the identifier of the vulnerability, the last segment of the note name.
+func (f *Finding) CVE() string {
+	return path.Base(f.Occurrence.NoteName)
+}
+
+// Severity returns the lower case effective severity of the vulnerability.
+func (f *Finding) Severity() string {
+	v := f.Occurrence.Vulnerability
+	if v.EffectiveSeverity != "" && v.EffectiveSeverity != "SEVERITY_UNSPECIFIED" {
+		return strings.ToLower(v.EffectiveSeverity)
+	}
+	return strings.ToLower(v.Severity)
+}
+
+// QuarantineImage returns values for the quarantine image automation.
+func (f *Finding) QuarantineImage() *quarantineimage.Values {
+	return &quarantineimage.Values{
+		ProjectID: f.ProjectID(),
+		ImageURI:  f.ImageURI(),
+	}
+}
+
+// SendEmail returns values for the email notification automation.
+func (f *Finding) SendEmail() *sendemail.Values {
+	v := f.Occurrence.Vulnerability
+	return &sendemail.Values{
+		ProjectID: f.ProjectID(),
+		Subject:   fmt.Sprintf("[SRA] %s vulnerability %s in %s", strings.ToUpper(f.Severity()), f.CVE(), f.ImageURI()),
+		Body: fmt.Sprintf("Container Analysis found %s (CVSS %.1f, fix available: %t) in image %s of project %s.\n\n%s\n\nOccurrence: %s\n",
+			f.CVE(), v.CVSSScore, v.FixAvailable, f.ImageURI(), f.ProjectID(), v.ShortDescription, f.Occurrence.Name),
+	}
+}
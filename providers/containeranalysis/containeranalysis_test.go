@@ -0,0 +1,71 @@
+package containeranalysis
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/binauthz/quarantineimage"
+)
+
+const occurrence = `{
+	"name": "projects/test-project/occurrences/7a0e3cd5-97a2-4c4e-8b4d-3f0a1f8f2d11",
+	"resource": {"uri": "https://gcr.io/test-project/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"},
+	"noteName": "projects/goog-vulnz/notes/CVE-2021-3711",
+	"kind": "VULNERABILITY",
+	"vulnerability": {
+		"severity": "HIGH",
+		"effectiveSeverity": "CRITICAL",
+		"cvssScore": 9.8,
+		"shortDescription": "CVE-2021-3711",
+		"fixAvailable": true
+	}
+}`
+
+func TestReadFinding(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		finding  string
+		expected string
+	}{
+		{name: "vulnerability", finding: occurrence, expected: "container_vulnerability"},
+		{name: "discovery", finding: `{"name": "projects/p/occurrences/1", "kind": "DISCOVERY", "resource": {"uri": "https://gcr.io/p/i"}}`, expected: ""},
+		{name: "scc finding", finding: `{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`, expected: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Finding{}).Name([]byte(tt.finding)); got != tt.expected {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.expected)
+			}
+		})
+	}
+	f, err := New([]byte(occurrence))
+	if err != nil {
+		t.Fatalf("New() failed: %q", err)
+	}
+	if got := f.Severity(); got != "critical" {
+		t.Errorf("Severity() got:%q want:%q", got, "critical")
+	}
+	if got := f.CVE(); got != "CVE-2021-3711" {
+		t.Errorf("CVE() got:%q want:%q", got, "CVE-2021-3711")
+	}
+	want := &quarantineimage.Values{
+		ProjectID: "test-project",
+		ImageURI:  "https://gcr.io/test-project/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31",
+	}
+	if diff := cmp.Diff(want, f.QuarantineImage()); diff != "" {
+		t.Errorf("QuarantineImage() difference: %+v", diff)
+	}
+}
@@ -0,0 +1,270 @@
+// Package custom represents findings published by third-party detectors.
+//
+// Detectors outside of Security Command Center can trigger automations by publishing a
+// message to the findings topic that follows the schema below. Fields are matched
+// case-insensitively and unknown fields are ignored so the schema can grow without
+// breaking existing publishers.
+//
+//	{
+//	  "schemaVersion": "v1",
+//	  "customFinding": {
+//	    "source": "my-detector",
+//	    "category": "public_bucket",
+//	    "severity": "HIGH",
+//	    "eventTime": "2020-01-01T00:00:00Z",
+//	    "resource": {
+//	      "name": "//storage.googleapis.com/my-bucket",
+//	      "projectId": "my-project"
+//	    },
+//	    "indicators": {
+//	      "ipAddresses": ["10.0.0.1"],
+//	      "domains": ["example.com"],
+//	      "members": ["user:bob@example.com"]
+//	    },
+//	    "recommendedAction": "close_bucket"
+//	  }
+//	}
+//
+// The category selects the automations configured under `custom` in the router's
+// configuration. If a recommended action is set only the matching configured automation
+// is run, a detector can never request an automation that is not configured.
+package custom
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/billing/detachbilling"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/resourcemanager/shutdownproject"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/secretmanager/closesecret"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/serviceusage/disableapis"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha"
+)
+
+// SchemaVersion is the only schema version currently understood.
+const SchemaVersion = "v1"
+
+// Resource identifies the resource affected by the finding.
+type Resource struct {
+	// Name is the full resource name, i.e. //compute.googleapis.com/projects/p/zones/z/instances/i.
+	Name string `json:"name"`
+	// ProjectID is the project the resource belongs to. Derived from Name if omitted.
+	ProjectID string `json:"projectId"`
+}
+
+// Indicators are the observables reported by the detector.
+type Indicators struct {
+	IPAddresses []string `json:"ipAddresses"`
+	Domains     []string `json:"domains"`
+	Members     []string `json:"members"`
+}
+
+// Schema is the body of a custom finding.
+type Schema struct {
+	Source            string     `json:"source"`
+	Category          string     `json:"category"`
+	Severity          string     `json:"severity"`
+	EventTime         string     `json:"eventTime"`
+	Resource          Resource   `json:"resource"`
+	Indicators        Indicators `json:"indicators"`
+	RecommendedAction string     `json:"recommendedAction"`
+}
+
+// Envelope is the message published by third-party detectors.
+type Envelope struct {
+	SchemaVersion string  `json:"schemaVersion"`
+	CustomFinding *Schema `json:"customFinding"`
+}
+
+// Finding represents a custom finding.
+type Finding struct {
+	Custom *Envelope
+}
+
+// Name returns "custom" if the bytes hold a custom finding.
+func (f *Finding) Name(b []byte) string {
+	ff, err := New(b)
+	if err != nil {
+		return ""
+	}
+	if ff.Category() == "" {
+		return ""
+	}
+	return "custom"
+}
+
+// Level returns the severity reported by the detector.
+func (f *Finding) Level(b []byte) severity.Level {
+	ff, err := New(b)
+	if err != nil {
+		return severity.Unknown
+	}
+	return severity.Parse(ff.Custom.CustomFinding.Severity)
+}
+
+// New returns a new custom finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.Custom); err != nil {
+		return nil, err
+	}
+	if f.Custom == nil || f.Custom.CustomFinding == nil {
+		return nil, fmt.Errorf("not a custom finding")
+	}
+	if v := f.Custom.SchemaVersion; v != SchemaVersion {
+		return nil, fmt.Errorf("unsupported custom finding schema version %q", v)
+	}
+	return &f, nil
+}
+
+// Category returns the lower case category of the finding.
+func (f *Finding) Category() string {
+	return strings.ToLower(f.Custom.CustomFinding.Category)
+}
+
+// RecommendedAction returns the action suggested by the detector, if any.
+func (f *Finding) RecommendedAction() string {
+	return f.Custom.CustomFinding.RecommendedAction
+}
+
+// ProjectID returns the project of the affected resource.
+func (f *Finding) ProjectID() string {
+	if p := f.Custom.CustomFinding.Resource.ProjectID; p != "" {
+		return p
+	}
+	return sha.ProjectID(f.Custom.CustomFinding.Resource.Name)
+}
+
+// CloseBucket returns values for the close bucket automation.
+func (f *Finding) CloseBucket() (*closebucket.Values, error) {
+	name, err := f.bucketName()
+	if err != nil {
+		return nil, err
+	}
+	return &closebucket.Values{ProjectID: f.ProjectID(), BucketName: name}, nil
+}
+
+// EnableBucketOnlyPolicy returns values for the enable bucket only policy automation.
+func (f *Finding) EnableBucketOnlyPolicy() (*enablebucketonlypolicy.Values, error) {
+	name, err := f.bucketName()
+	if err != nil {
+		return nil, err
+	}
+	return &enablebucketonlypolicy.Values{ProjectID: f.ProjectID(), BucketName: name}, nil
+}
+
+// CreateSnapshot returns values for the create snapshot automation.
+func (f *Finding) CreateSnapshot() (*createsnapshot.Values, error) {
+	zone, instance, err := f.instance()
+	if err != nil {
+		return nil, err
+	}
+	return &createsnapshot.Values{
+		ProjectID: f.ProjectID(),
+		RuleName:  f.Category(),
+		Instance:  instance,
+		Zone:      zone,
+	}, nil
+}
+
+// RemovePublicIP returns values for the remove public IP automation.
+func (f *Finding) RemovePublicIP() (*removepublicip.Values, error) {
+	zone, instance, err := f.instance()
+	if err != nil {
+		return nil, err
+	}
+	return &removepublicip.Values{ProjectID: f.ProjectID(), InstanceZone: zone, InstanceID: instance}, nil
+}
+
+// OpenFirewall returns values for the remediate firewall automation.
+func (f *Finding) OpenFirewall() (*openfirewall.Values, error) {
+	id := sha.FirewallID(f.Custom.CustomFinding.Resource.Name)
+	if id == "" {
+		return nil, fmt.Errorf("resource %q is not a firewall", f.Custom.CustomFinding.Resource.Name)
+	}
+	return &openfirewall.Values{ProjectID: f.ProjectID(), FirewallID: id}, nil
+}
+
+// IAMRevoke returns values for the IAM revoke automation.
+func (f *Finding) IAMRevoke() (*revoke.Values, error) {
+	if len(f.Custom.CustomFinding.Indicators.Members) == 0 {
+		return nil, fmt.Errorf("no members in indicators")
+	}
+	return &revoke.Values{ProjectID: f.ProjectID(), ExternalMembers: f.Custom.CustomFinding.Indicators.Members}, nil
+}
+
+// CloseSecret returns values for the close secret automation.
+func (f *Finding) CloseSecret() (*closesecret.Values, error) {
+	name := sha.SecretName(f.Custom.CustomFinding.Resource.Name)
+	if name == "" {
+		return nil, fmt.Errorf("resource %q is not a secret", f.Custom.CustomFinding.Resource.Name)
+	}
+	return &closesecret.Values{ProjectID: f.ProjectID(), SecretName: name}, nil
+}
+
+// DisableAPIs returns values for the disable APIs automation.
+func (f *Finding) DisableAPIs() (*disableapis.Values, error) {
+	projectID := f.ProjectID()
+	if projectID == "" {
+		return nil, fmt.Errorf("resource %q is not within a project", f.Custom.CustomFinding.Resource.Name)
+	}
+	return &disableapis.Values{ProjectID: projectID}, nil
+}
+
+// ShutdownProject returns values for the shutdown project automation.
+func (f *Finding) ShutdownProject() (*shutdownproject.Values, error) {
+	projectID := f.ProjectID()
+	if projectID == "" {
+		return nil, fmt.Errorf("resource %q is not within a project", f.Custom.CustomFinding.Resource.Name)
+	}
+	reason := fmt.Sprintf("Held for investigation of %s finding %s", f.Custom.CustomFinding.Source, f.Category())
+	return &shutdownproject.Values{ProjectID: projectID, Reason: reason}, nil
+}
+
+// DetachBilling returns values for the detach billing automation.
+func (f *Finding) DetachBilling() (*detachbilling.Values, error) {
+	projectID := f.ProjectID()
+	if projectID == "" {
+		return nil, fmt.Errorf("resource %q is not within a project", f.Custom.CustomFinding.Resource.Name)
+	}
+	return &detachbilling.Values{ProjectID: projectID}, nil
+}
+
+func (f *Finding) bucketName() (string, error) {
+	name := sha.BucketName(f.Custom.CustomFinding.Resource.Name)
+	if name == "" {
+		return "", fmt.Errorf("resource %q is not a bucket", f.Custom.CustomFinding.Resource.Name)
+	}
+	return name, nil
+}
+
+func (f *Finding) instance() (string, string, error) {
+	name := f.Custom.CustomFinding.Resource.Name
+	zone, instance := sha.Zone(name), sha.Instance(name)
+	if zone == "" || instance == "" {
+		return "", "", fmt.Errorf("resource %q is not an instance", name)
+	}
+	return zone, instance, nil
+}
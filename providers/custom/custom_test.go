@@ -0,0 +1,164 @@
+package custom
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestName(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		finding string
+		want    string
+	}{
+		{name: "custom", finding: `{"schemaVersion": "v1", "customFinding": {"category": "PUBLIC_BUCKET"}}`, want: "custom"},
+		{name: "missing category", finding: `{"schemaVersion": "v1", "customFinding": {}}`, want: ""},
+		{name: "unknown version", finding: `{"schemaVersion": "v2", "customFinding": {"category": "public_bucket"}}`, want: ""},
+		{name: "scc finding", finding: `{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`, want: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Finding{}).Name([]byte(tt.finding)); got != tt.want {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFinding(t *testing.T) {
+	const (
+		bucket = `{
+			"schemaVersion": "v1",
+			"customFinding": {
+				"source": "my-detector",
+				"category": "public_bucket",
+				"resource": {"name": "//storage.googleapis.com/this-is-public", "projectId": "test-project"},
+				"recommendedAction": "close_bucket"
+			}
+		}`
+		instance = `{
+			"schemaVersion": "v1",
+			"customFinding": {
+				"category": "cryptomining",
+				"resource": {"name": "//compute.googleapis.com/projects/test-project/zones/us-central1-a/instances/miner"}
+			}
+		}`
+		grant = `{
+			"schemaVersion": "v1",
+			"customFinding": {
+				"category": "suspicious_grant",
+				"resource": {"name": "//cloudresourcemanager.googleapis.com/projects/test-project"},
+				"indicators": {"members": ["user:bob@evil.com"]}
+			}
+		}`
+		secret = `{
+			"schemaVersion": "v1",
+			"customFinding": {
+				"category": "public_secret",
+				"resource": {"name": "//secretmanager.googleapis.com/projects/test-project/secrets/api-key"}
+			}
+		}`
+	)
+	t.Run("bucket", func(t *testing.T) {
+		f, err := New([]byte(bucket))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.CloseBucket()
+		if err != nil {
+			t.Fatalf("CloseBucket() failed: %q", err)
+		}
+		if values.ProjectID != "test-project" || values.BucketName != "this-is-public" {
+			t.Errorf("CloseBucket() got:%+v", values)
+		}
+		if f.RecommendedAction() != "close_bucket" {
+			t.Errorf("RecommendedAction() got:%q want:%q", f.RecommendedAction(), "close_bucket")
+		}
+		if _, err := f.CreateSnapshot(); err == nil {
+			t.Errorf("CreateSnapshot() on a bucket should fail")
+		}
+	})
+	t.Run("instance", func(t *testing.T) {
+		f, err := New([]byte(instance))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.RemovePublicIP()
+		if err != nil {
+			t.Fatalf("RemovePublicIP() failed: %q", err)
+		}
+		if values.ProjectID != "test-project" || values.InstanceZone != "us-central1-a" || values.InstanceID != "miner" {
+			t.Errorf("RemovePublicIP() got:%+v", values)
+		}
+	})
+	t.Run("grant", func(t *testing.T) {
+		f, err := New([]byte(grant))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.IAMRevoke()
+		if err != nil {
+			t.Fatalf("IAMRevoke() failed: %q", err)
+		}
+		if diff := cmp.Diff([]string{"user:bob@evil.com"}, values.ExternalMembers); diff != "" {
+			t.Errorf("IAMRevoke() members diff: %s", diff)
+		}
+	})
+	t.Run("secret", func(t *testing.T) {
+		f, err := New([]byte(secret))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.CloseSecret()
+		if err != nil {
+			t.Fatalf("CloseSecret() failed: %q", err)
+		}
+		if values.ProjectID != "test-project" || values.SecretName != "projects/test-project/secrets/api-key" {
+			t.Errorf("CloseSecret() got:%+v", values)
+		}
+		if _, err := f.CloseBucket(); err == nil {
+			t.Errorf("CloseBucket() on a secret should fail")
+		}
+	})
+	t.Run("project", func(t *testing.T) {
+		f, err := New([]byte(instance))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.DisableAPIs()
+		if err != nil {
+			t.Fatalf("DisableAPIs() failed: %q", err)
+		}
+		if values.ProjectID != "test-project" {
+			t.Errorf("DisableAPIs() got:%+v", values)
+		}
+		shutdown, err := f.ShutdownProject()
+		if err != nil {
+			t.Fatalf("ShutdownProject() failed: %q", err)
+		}
+		if shutdown.ProjectID != "test-project" || shutdown.Reason == "" {
+			t.Errorf("ShutdownProject() got:%+v", shutdown)
+		}
+		billing, err := f.DetachBilling()
+		if err != nil {
+			t.Fatalf("DetachBilling() failed: %q", err)
+		}
+		if billing.ProjectID != "test-project" {
+			t.Errorf("DetachBilling() got:%+v", billing)
+		}
+	})
+}
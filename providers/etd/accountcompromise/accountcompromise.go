@@ -0,0 +1,181 @@
+// Package accountcompromise represents the Event Threat Detection findings about Google Workspace
+// or Cloud Identity accounts that may be compromised, such as leaked credentials or suspicious
+// logins.
+package accountcompromise
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/containserviceaccount"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/restrictaccesslevel"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/enforce2sv"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/removefromgroups"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/revokesessions"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/suspenduser"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// rules lists the Event Threat Detection rules reporting a compromised account.
+var rules = map[string]bool{
+	"account_has_leaked_credentials":   true,
+	"account_disabled_hijacked":        true,
+	"account_disabled_password_leak":   true,
+	"gov_attack_warning":               true,
+	"suspicious_login":                 true,
+	"suspicious_login_less_secure_app": true,
+	"suspicious_programmatic_login":    true,
+	// Service Account Self-Investigation, a service account reading its own IAM policy.
+	"iam_anomalous_behavior_service_account_gets_own_iam_policy": true,
+}
+
+// serviceAccountSuffix ends the email addresses of user-managed service accounts.
+const serviceAccountSuffix = ".iam.gserviceaccount.com"
+
+// detection holds the fields shared by findings notified by Security Command Center and those
+// read from Cloud Logging.
+type detection struct {
+	DetectionCategory struct {
+		RuleName string `json:"ruleName"`
+	} `json:"detectionCategory"`
+	Properties struct {
+		PrincipalEmail string `json:"principalEmail"`
+	} `json:"properties"`
+}
+
+// Finding represents an account compromise finding.
+type Finding struct {
+	// UseCSCC is whether the finding was notified by Security Command Center.
+	UseCSCC bool
+	name    string
+	d       detection
+	// projectID is the project the finding was reported in, if any.
+	projectID string
+}
+
+// Name returns "account_compromise" if the bytes hold an account compromise finding.
+func (f *Finding) Name(b []byte) string {
+	if _, err := New(b); err != nil {
+		return ""
+	}
+	return "account_compromise"
+}
+
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
+// New returns a new account compromise finding.
+func New(b []byte) (*Finding, error) {
+	var v struct {
+		Finding struct {
+			Name             string    `json:"name"`
+			SourceProperties detection `json:"sourceProperties"`
+		} `json:"finding"`
+		JSONPayload detection `json:"jsonPayload"`
+		InsertID    string    `json:"insertId"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	f := &Finding{name: v.InsertID, d: v.JSONPayload}
+	if r := v.Finding.SourceProperties.DetectionCategory.RuleName; r != "" {
+		f = &Finding{UseCSCC: true, name: v.Finding.Name, d: v.Finding.SourceProperties}
+	}
+	if !rules[f.RuleName()] {
+		return nil, fmt.Errorf("not an account compromise finding")
+	}
+	if r, err := scc.ResourceOf(b); err == nil {
+		f.projectID = r.ProjectID
+	}
+	return f, nil
+}
+
+// RuleName returns the Event Threat Detection rule that reported the finding.
+func (f *Finding) RuleName() string {
+	return f.d.DetectionCategory.RuleName
+}
+
+// User returns the email address of the account that may be compromised.
+func (f *Finding) User() string {
+	return strings.ToLower(f.d.Properties.PrincipalEmail)
+}
+
+// SuspendUser returns values for the suspend user automation.
+func (f *Finding) SuspendUser() (*suspenduser.Values, error) {
+	if f.User() == "" {
+		return nil, fmt.Errorf("finding %q does not name its user", f.name)
+	}
+	return &suspenduser.Values{Email: f.User()}, nil
+}
+
+// RevokeSessions returns values for the revoke sessions automation.
+func (f *Finding) RevokeSessions() (*revokesessions.Values, error) {
+	if f.User() == "" {
+		return nil, fmt.Errorf("finding %q does not name its user", f.name)
+	}
+	return &revokesessions.Values{Email: f.User()}, nil
+}
+
+// RemoveFromGroups returns values for the remove from groups automation.
+func (f *Finding) RemoveFromGroups() (*removefromgroups.Values, error) {
+	if f.User() == "" {
+		return nil, fmt.Errorf("finding %q does not name its user", f.name)
+	}
+	return &removefromgroups.Values{Email: f.User()}, nil
+}
+
+// Enforce2SV returns values for the enforce 2-Step Verification automation.
+func (f *Finding) Enforce2SV() (*enforce2sv.Values, error) {
+	if f.User() == "" {
+		return nil, fmt.Errorf("finding %q does not name its user", f.name)
+	}
+	return &enforce2sv.Values{Email: f.User()}, nil
+}
+
+// ContainServiceAccount returns values for the contain service account automation. The service
+// account is removed from the policies of its own project and of the project the finding was
+// reported in.
+func (f *Finding) ContainServiceAccount() (*containserviceaccount.Values, error) {
+	email := f.User()
+	at := strings.LastIndex(email, "@")
+	if at < 0 || !strings.HasSuffix(email, serviceAccountSuffix) {
+		return nil, fmt.Errorf("finding %q does not name a user-managed service account", f.name)
+	}
+	projectID := strings.TrimSuffix(email[at+1:], serviceAccountSuffix)
+	projectIDs := []string{projectID}
+	if f.projectID != "" && f.projectID != projectID {
+		projectIDs = append(projectIDs, f.projectID)
+	}
+	return &containserviceaccount.Values{ProjectID: projectID, ServiceAccount: email, ProjectIDs: projectIDs}, nil
+}
+
+// RestrictAccessLevel returns values for the restrict access level automation.
+func (f *Finding) RestrictAccessLevel() (*restrictaccesslevel.Values, error) {
+	email := f.User()
+	if email == "" {
+		return nil, fmt.Errorf("finding %q does not name its user", f.name)
+	}
+	member := "user:" + email
+	if strings.HasSuffix(email, ".gserviceaccount.com") {
+		member = "serviceAccount:" + email
+	}
+	return &restrictaccesslevel.Values{Members: []string{member}}, nil
+}
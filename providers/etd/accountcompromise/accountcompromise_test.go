@@ -0,0 +1,173 @@
+package accountcompromise
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/containserviceaccount"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/workspace/suspenduser"
+)
+
+const (
+	leakedCredentialsSCC = `{
+		"notificationConfigName": "organizations/154584661726/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/154584661726/sources/2673592633662526977/findings/1c9d0cf4b8d5c3a9f0e1",
+			"resourceName": "//cloudresourcemanager.googleapis.com/organizations/154584661726",
+			"state": "ACTIVE",
+			"category": "Account_Has_Leaked_Credentials",
+			"sourceProperties": {
+				"detectionCategory": {
+					"ruleName": "account_has_leaked_credentials"
+				},
+				"properties": {
+					"principalEmail": "Alice@example.com"
+				}
+			},
+			"severity": "HIGH"
+		}
+	}`
+	suspiciousLoginLog = `{
+		"insertId": "e8m2ad1f2",
+		"jsonPayload": {
+			"detectionCategory": {
+				"ruleName": "suspicious_login"
+			},
+			"properties": {
+				"principalEmail": "bob@example.com"
+			}
+		},
+		"logName": "organizations/154584661726/logs/threatdetection.googleapis.com%2Fdetection"
+	}`
+	selfInvestigationLog = `{
+		"insertId": "k2d9f0a1c",
+		"jsonPayload": {
+			"detectionCategory": {
+				"ruleName": "iam_anomalous_behavior_service_account_gets_own_iam_policy"
+			},
+			"properties": {
+				"principalEmail": "deployer@sa-project.iam.gserviceaccount.com"
+			}
+		},
+		"logName": "projects/app-project/logs/threatdetection.googleapis.com%2Fdetection",
+		"resource": {
+			"labels": {
+				"project_id": "app-project"
+			}
+		}
+	}`
+	badIPLog = `{
+		"jsonPayload": {
+			"detectionCategory": {
+				"ruleName": "bad_ip"
+			}
+		}
+	}`
+)
+
+func TestReadFinding(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		finding  string
+		wantName string
+		want     *suspenduser.Values
+		useCSCC  bool
+	}{
+		{name: "leaked credentials", finding: leakedCredentialsSCC, wantName: "account_compromise", want: &suspenduser.Values{Email: "alice@example.com"}, useCSCC: true},
+		{name: "suspicious login", finding: suspiciousLoginLog, wantName: "account_compromise", want: &suspenduser.Values{Email: "bob@example.com"}},
+		{name: "other rule", finding: badIPLog},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Finding{}).Name([]byte(tt.finding)); got != tt.wantName {
+				t.Fatalf("%s Name() got:%q want:%q", tt.name, got, tt.wantName)
+			}
+			if tt.want == nil {
+				return
+			}
+			f, err := New([]byte(tt.finding))
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if f.UseCSCC != tt.useCSCC {
+				t.Errorf("%s UseCSCC got:%t want:%t", tt.name, f.UseCSCC, tt.useCSCC)
+			}
+			got, err := f.SuspendUser()
+			if err != nil {
+				t.Fatalf("%s SuspendUser() failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s SuspendUser() difference: %+v", tt.name, diff)
+			}
+			revoke, err := f.RevokeSessions()
+			if err != nil {
+				t.Fatalf("%s RevokeSessions() failed: %q", tt.name, err)
+			}
+			if revoke.Email != tt.want.Email {
+				t.Errorf("%s RevokeSessions() email got:%q want:%q", tt.name, revoke.Email, tt.want.Email)
+			}
+		})
+	}
+}
+
+func TestContainServiceAccount(t *testing.T) {
+	f, err := New([]byte(selfInvestigationLog))
+	if err != nil {
+		t.Fatalf("failed to read finding: %q", err)
+	}
+	got, err := f.ContainServiceAccount()
+	if err != nil {
+		t.Fatalf("ContainServiceAccount() failed: %q", err)
+	}
+	want := &containserviceaccount.Values{
+		ProjectID:      "sa-project",
+		ServiceAccount: "deployer@sa-project.iam.gserviceaccount.com",
+		ProjectIDs:     []string{"sa-project", "app-project"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ContainServiceAccount() difference: %+v", diff)
+	}
+	user, err := New([]byte(suspiciousLoginLog))
+	if err != nil {
+		t.Fatalf("failed to read finding: %q", err)
+	}
+	if _, err := user.ContainServiceAccount(); err == nil {
+		t.Errorf("ContainServiceAccount() of a user succeeded, want error")
+	}
+}
+
+func TestRestrictAccessLevel(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		finding string
+		want    []string
+	}{
+		{name: "user", finding: suspiciousLoginLog, want: []string{"user:bob@example.com"}},
+		{name: "service account", finding: selfInvestigationLog, want: []string{"serviceAccount:deployer@sa-project.iam.gserviceaccount.com"}},
+	} {
+		f, err := New([]byte(tt.finding))
+		if err != nil {
+			t.Fatalf("%s failed to read finding: %q", tt.name, err)
+		}
+		got, err := f.RestrictAccessLevel()
+		if err != nil {
+			t.Fatalf("%s RestrictAccessLevel() failed: %q", tt.name, err)
+		}
+		if diff := cmp.Diff(tt.want, got.Members); diff != "" {
+			t.Errorf("%s RestrictAccessLevel() members difference: %+v", tt.name, diff)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/revoke"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/etd/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 )
 
 // Name verifies and returns the rule name of the finding.
@@ -26,19 +28,43 @@ func (f *Finding) Name(b []byte) string {
 	return name
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // New returns a new finding.
 func New(b []byte) (*Finding, error) {
 	var f Finding
 	if err := json.Unmarshal(b, &f.anomalousIAM); err != nil {
 		return nil, err
 	}
+	var principal struct {
+		Finding struct {
+			SourceProperties struct {
+				Properties struct {
+					PrincipalEmail string `json:"principalEmail"`
+				} `json:"properties"`
+			} `json:"sourceProperties"`
+		} `json:"finding"`
+		JSONPayload struct {
+			Properties struct {
+				PrincipalEmail string `json:"principalEmail"`
+			} `json:"properties"`
+		} `json:"jsonPayload"`
+	}
+	if err := json.Unmarshal(b, &principal); err != nil {
+		return nil, err
+	}
 	if f.anomalousIAM.GetJsonPayload().GetDetectionCategory().GetRuleName() != "" {
+		f.grantor = principal.JSONPayload.Properties.PrincipalEmail
 		return &f, nil
 	}
 	if err := json.Unmarshal(b, &f.anomalousIAMSCC); err != nil {
 		return nil, err
 	}
 	f.UseCSCC = true
+	f.grantor = principal.Finding.SourceProperties.Properties.PrincipalEmail
 	return &f, nil
 }
 
@@ -47,6 +73,8 @@ type Finding struct {
 	UseCSCC         bool
 	anomalousIAM    *pb.AnomalousIAMGrant
 	anomalousIAMSCC *pb.AnomalousIAMGrantSCC
+	// grantor is the email address of the principal who made the grant.
+	grantor string
 }
 
 // IAMRevoke returns values for the IAM revoke automation.
@@ -55,10 +83,12 @@ func (f *Finding) IAMRevoke() *revoke.Values {
 		return &revoke.Values{
 			ProjectID:       f.anomalousIAMSCC.GetFinding().GetSourceProperties().GetEvidence()[0].GetSourceLogId().GetProjectId(),
 			ExternalMembers: f.anomalousIAMSCC.GetFinding().GetSourceProperties().GetProperties().GetSensitiveRoleGrant().GetMembers(),
+			Grantor:         f.grantor,
 		}
 	}
 	return &revoke.Values{
 		ProjectID:       f.anomalousIAM.GetJsonPayload().GetEvidence()[0].GetSourceLogId().GetProjectId(),
 		ExternalMembers: f.anomalousIAM.GetJsonPayload().GetProperties().GetSensitiveRoleGrant().GetMembers(),
+		Grantor:         f.grantor,
 	}
 }
@@ -24,6 +24,7 @@ func TestReadFinding(t *testing.T) {
 					},
 					"evidence": [{"sourceLogId": {"projectId": "onboarding-project"}}],
 					"properties": {
+						"principalEmail": "admin@example.com",
 						"sensitiveRoleGrant": {
 							"members": ["user:john.doe@example.com", "user:jane.doe@example.com"]
 						}
@@ -37,6 +38,7 @@ func TestReadFinding(t *testing.T) {
 		etdAnomalousIAM = `{
 			"jsonPayload": {
 				"properties": {
+					"principalEmail": "admin@example.com",
 					"sensitiveRoleGrant": {
 						"members": ["user:john.doe@example.com", "user:jane.doe@example.com"]
 					}
@@ -78,6 +80,9 @@ func TestReadFinding(t *testing.T) {
 				if values.ProjectID != tt.projectID {
 					t.Errorf("%s failed: got:%q want:%q", tt.name, values.ProjectID, tt.projectID)
 				}
+				if values.Grantor != "admin@example.com" {
+					t.Errorf("%s failed: got grantor:%q want:%q", tt.name, values.Grantor, "admin@example.com")
+				}
 			}
 		})
 	}
@@ -21,6 +21,8 @@ import (
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/createsnapshot"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/etd/protos"
 	"github.com/googlecloudplatform/security-response-automation/providers/etd"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 )
 
 // Name returns the rule name of the finding.
@@ -41,6 +43,11 @@ func (f *Finding) Name(b []byte) string {
 	return name
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // Finding represents a bad IP finding.
 type Finding struct {
 	UseCSCC   bool
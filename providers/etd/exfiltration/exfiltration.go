@@ -0,0 +1,117 @@
+package exfiltration
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/addtoperimeter"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// rules lists the Event Threat Detection rules reporting data exfiltration.
+var rules = map[string]bool{
+	"big_query_exfil": true,
+	"cloudsql_exfil":  true,
+}
+
+// detection holds the fields shared by findings notified by Security Command Center and those
+// read from Cloud Logging.
+type detection struct {
+	DetectionCategory struct {
+		RuleName    string `json:"ruleName"`
+		SubRuleName string `json:"subRuleName"`
+	} `json:"detectionCategory"`
+	Evidence []struct {
+		SourceLogID struct {
+			ProjectID string `json:"projectId"`
+		} `json:"sourceLogId"`
+	} `json:"evidence"`
+}
+
+// Finding represents an exfiltration finding.
+type Finding struct {
+	// UseCSCC is whether the finding was notified by Security Command Center.
+	UseCSCC   bool
+	name      string
+	d         detection
+	projectID string
+}
+
+// Name returns "exfiltration" if the bytes hold an exfiltration finding.
+func (f *Finding) Name(b []byte) string {
+	if _, err := New(b); err != nil {
+		return ""
+	}
+	return "exfiltration"
+}
+
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
+// New returns a new exfiltration finding.
+func New(b []byte) (*Finding, error) {
+	var v struct {
+		Finding struct {
+			Name             string    `json:"name"`
+			SourceProperties detection `json:"sourceProperties"`
+		} `json:"finding"`
+		JSONPayload detection `json:"jsonPayload"`
+		InsertID    string    `json:"insertId"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	f := &Finding{name: v.InsertID, d: v.JSONPayload}
+	if r := v.Finding.SourceProperties.DetectionCategory.RuleName; r != "" {
+		f = &Finding{UseCSCC: true, name: v.Finding.Name, d: v.Finding.SourceProperties}
+	}
+	if !rules[f.RuleName()] {
+		return nil, fmt.Errorf("not an exfiltration finding")
+	}
+	// The evidence names the project the data was exfiltrated from, the finding's resource may
+	// name it by number only.
+	if len(f.d.Evidence) > 0 {
+		f.projectID = f.d.Evidence[0].SourceLogID.ProjectID
+	}
+	if f.projectID == "" {
+		if r, err := scc.ResourceOf(b); err == nil {
+			f.projectID = r.ProjectID
+		}
+	}
+	return f, nil
+}
+
+// RuleName returns the Event Threat Detection rule that reported the finding.
+func (f *Finding) RuleName() string {
+	return f.d.DetectionCategory.RuleName
+}
+
+// SubRuleName returns how the data was exfiltrated, such as "exfil_to_external_table".
+func (f *Finding) SubRuleName() string {
+	return f.d.DetectionCategory.SubRuleName
+}
+
+// AddToPerimeter returns values for the add to perimeter automation.
+func (f *Finding) AddToPerimeter() (*addtoperimeter.Values, error) {
+	if f.projectID == "" {
+		return nil, fmt.Errorf("finding %q does not name its project", f.name)
+	}
+	return &addtoperimeter.Values{ProjectID: f.projectID}, nil
+}
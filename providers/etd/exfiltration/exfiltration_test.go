@@ -0,0 +1,105 @@
+package exfiltration
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/vpcsc/addtoperimeter"
+)
+
+const (
+	bigQueryExfilSCC = `{
+		"notificationConfigName": "organizations/154584661726/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/154584661726/sources/2673592633662526977/findings/7f3a9c2e1b",
+			"resourceName": "//cloudresourcemanager.googleapis.com/projects/102030405060",
+			"state": "ACTIVE",
+			"category": "Exfiltration: BigQuery Data Exfiltration",
+			"sourceProperties": {
+				"detectionCategory": {
+					"ruleName": "big_query_exfil",
+					"subRuleName": "exfil_to_external_table"
+				},
+				"evidence": [{
+					"sourceLogId": {
+						"projectId": "sales-data"
+					}
+				}]
+			},
+			"severity": "HIGH"
+		}
+	}`
+	cloudSQLExfilLog = `{
+		"insertId": "3c7d1e9f0a",
+		"jsonPayload": {
+			"detectionCategory": {
+				"ruleName": "cloudsql_exfil",
+				"subRuleName": "export_to_public_gcs"
+			}
+		},
+		"logName": "projects/billing-db/logs/threatdetection.googleapis.com%2Fdetection",
+		"resource": {
+			"labels": {
+				"project_id": "billing-db"
+			}
+		}
+	}`
+	badIPLog = `{
+		"jsonPayload": {
+			"detectionCategory": {
+				"ruleName": "bad_ip"
+			}
+		}
+	}`
+)
+
+func TestReadFinding(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		finding  string
+		wantName string
+		want     *addtoperimeter.Values
+		useCSCC  bool
+	}{
+		{name: "bigquery", finding: bigQueryExfilSCC, wantName: "exfiltration", want: &addtoperimeter.Values{ProjectID: "sales-data"}, useCSCC: true},
+		{name: "cloud sql", finding: cloudSQLExfilLog, wantName: "exfiltration", want: &addtoperimeter.Values{ProjectID: "billing-db"}},
+		{name: "other rule", finding: badIPLog},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Finding{}).Name([]byte(tt.finding)); got != tt.wantName {
+				t.Fatalf("%s Name() got:%q want:%q", tt.name, got, tt.wantName)
+			}
+			if tt.want == nil {
+				return
+			}
+			f, err := New([]byte(tt.finding))
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if f.UseCSCC != tt.useCSCC {
+				t.Errorf("%s UseCSCC got:%t want:%t", tt.name, f.UseCSCC, tt.useCSCC)
+			}
+			got, err := f.AddToPerimeter()
+			if err != nil {
+				t.Fatalf("%s AddToPerimeter() failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s AddToPerimeter() difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/requireoslogin"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/etd/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 )
 
 // Finding represents this finding.
@@ -32,6 +35,11 @@ func (f *Finding) Name(b []byte) string {
 	return name
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // New returns a new finding.
 func New(b []byte) (*Finding, error) {
 	var f Finding
@@ -80,3 +88,11 @@ func (f *Finding) OpenFirewall() *openfirewall.Values {
 		SourceRanges: sourceIPRanges(f.sshBruteForce),
 	}
 }
+
+// RequireOSLogin returns values for the require OS Login automation.
+func (f *Finding) RequireOSLogin() *requireoslogin.Values {
+	if f.UseCSCC {
+		return &requireoslogin.Values{ProjectID: f.sshBruteForceSCC.GetFinding().GetSourceProperties().GetProperties().GetProjectId()}
+	}
+	return &requireoslogin.Values{ProjectID: f.sshBruteForce.GetJsonPayload().GetProperties().GetProjectId()}
+}
@@ -97,6 +97,9 @@ func TestReadFinding(t *testing.T) {
 				if values.ProjectID != tt.projectID {
 					t.Errorf("%s failed: got:%q want:%q", tt.name, values.ProjectID, tt.projectID)
 				}
+				if got := r.RequireOSLogin().ProjectID; got != tt.projectID {
+					t.Errorf("%s require OS Login failed: got:%q want:%q", tt.name, got, tt.projectID)
+				}
 			}
 		})
 	}
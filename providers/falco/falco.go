@@ -0,0 +1,147 @@
+// Package falco represents Falco runtime alerts forwarded to Pub/Sub by falcosidekick.
+//
+// Falco does not know which GKE cluster it runs in so falcosidekick must be configured to add
+// the custom fields `gcp.project`, `gke.location` and `gke.cluster` to every alert. Alerts used to
+// cordon nodes must also carry `k8s.node.name`, the node the Falco pod is scheduled on.
+package falco
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/cordonnode"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/isolatepod"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// Alert is a Falco alert as published by falcosidekick.
+type Alert struct {
+	Output       string                 `json:"output"`
+	Priority     string                 `json:"priority"`
+	Rule         string                 `json:"rule"`
+	Time         string                 `json:"time"`
+	Hostname     string                 `json:"hostname"`
+	Tags         []string               `json:"tags"`
+	OutputFields map[string]interface{} `json:"output_fields"`
+}
+
+// Finding represents a Falco alert.
+type Finding struct {
+	Alert *Alert
+}
+
+// Name returns "falco_alert" if the bytes hold a Falco alert.
+func (f *Finding) Name(b []byte) string {
+	if _, err := New(b); err != nil {
+		return ""
+	}
+	return "falco_alert"
+}
+
+// Level returns the severity of the alert derived from its priority.
+func (f *Finding) Level(b []byte) severity.Level {
+	ff, err := New(b)
+	if err != nil {
+		return severity.Unknown
+	}
+	switch ff.Priority() {
+	case "emergency", "alert", "critical":
+		return severity.Critical
+	case "error":
+		return severity.High
+	case "warning":
+		return severity.Medium
+	case "notice", "informational", "debug":
+		return severity.Low
+	}
+	return severity.Unknown
+}
+
+// New returns a new Falco finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.Alert); err != nil {
+		return nil, err
+	}
+	if f.Alert == nil || f.Alert.Rule == "" || f.Alert.Priority == "" || f.Alert.OutputFields == nil {
+		return nil, fmt.Errorf("not a falco alert")
+	}
+	return &f, nil
+}
+
+// Rule returns the name of the Falco rule that raised the alert.
+func (f *Finding) Rule() string {
+	return f.Alert.Rule
+}
+
+// Priority returns the lower case priority of the alert.
+func (f *Finding) Priority() string {
+	return strings.ToLower(f.Alert.Priority)
+}
+
+// IsolatePod returns values for the isolate pod automation.
+func (f *Finding) IsolatePod() (*isolatepod.Values, error) {
+	namespace, pod := f.field("k8s.ns.name"), f.field("k8s.pod.name")
+	if namespace == "" || pod == "" {
+		return nil, fmt.Errorf("alert %q is not for a kubernetes pod", f.Alert.Rule)
+	}
+	if err := f.validateCluster(); err != nil {
+		return nil, err
+	}
+	return &isolatepod.Values{
+		ProjectID: f.field("gcp.project"),
+		Location:  f.field("gke.location"),
+		ClusterID: f.field("gke.cluster"),
+		Namespace: namespace,
+		Pod:       pod,
+	}, nil
+}
+
+// CordonNode returns values for the cordon node automation.
+func (f *Finding) CordonNode() (*cordonnode.Values, error) {
+	node := f.field("k8s.node.name")
+	if node == "" {
+		return nil, fmt.Errorf("alert %q is missing custom field %q", f.Alert.Rule, "k8s.node.name")
+	}
+	if err := f.validateCluster(); err != nil {
+		return nil, err
+	}
+	return &cordonnode.Values{
+		ProjectID: f.field("gcp.project"),
+		Location:  f.field("gke.location"),
+		ClusterID: f.field("gke.cluster"),
+		Node:      node,
+	}, nil
+}
+
+func (f *Finding) validateCluster() error {
+	for _, k := range []string{"gcp.project", "gke.location", "gke.cluster"} {
+		if f.field(k) == "" {
+			return fmt.Errorf("alert is missing custom field %q", k)
+		}
+	}
+	return nil
+}
+
+func (f *Finding) field(key string) string {
+	v, ok := f.Alert.OutputFields[key].(string)
+	if !ok {
+		return ""
+	}
+	return v
+}
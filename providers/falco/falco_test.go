@@ -0,0 +1,103 @@
+package falco
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+func TestReadFinding(t *testing.T) {
+	const (
+		alert = `{
+			"output": "Notice A shell was spawned in a container with an attached terminal",
+			"priority": "Notice",
+			"rule": "Terminal shell in container",
+			"time": "2020-06-01T15:04:05.123456789Z",
+			"hostname": "gke-test-cluster-default-pool-1234",
+			"output_fields": {
+				"k8s.ns.name": "default",
+				"k8s.pod.name": "nginx",
+				"k8s.node.name": "gke-test-cluster-default-pool-5678",
+				"gcp.project": "test-project",
+				"gke.location": "us-central1-a",
+				"gke.cluster": "test-cluster"
+			}
+		}`
+		missingNode = `{
+			"priority": "Notice",
+			"rule": "Terminal shell in container",
+			"hostname": "gke-test-cluster-default-pool-1234",
+			"output_fields": {
+				"k8s.ns.name": "default",
+				"k8s.pod.name": "nginx",
+				"gcp.project": "test-project",
+				"gke.location": "us-central1-a",
+				"gke.cluster": "test-cluster"
+			}
+		}`
+		missingCluster = `{
+			"priority": "Notice",
+			"rule": "Terminal shell in container",
+			"hostname": "gke-test-cluster-default-pool-1234",
+			"output_fields": {"k8s.ns.name": "default", "k8s.pod.name": "nginx"}
+		}`
+	)
+	if got := (&Finding{}).Name([]byte(alert)); got != "falco_alert" {
+		t.Errorf("Name() got:%q want:%q", got, "falco_alert")
+	}
+	if got := (&Finding{}).Name([]byte(`{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`)); got != "" {
+		t.Errorf("Name() of scc finding got:%q want:%q", got, "")
+	}
+	f, err := New([]byte(alert))
+	if err != nil {
+		t.Fatalf("New() failed: %q", err)
+	}
+	if f.Priority() != "notice" {
+		t.Errorf("Priority() got:%q want:%q", f.Priority(), "notice")
+	}
+	if got := f.Level([]byte(alert)); got != severity.Low {
+		t.Errorf("Level() got:%s want:%s", got, severity.Low)
+	}
+	pod, err := f.IsolatePod()
+	if err != nil {
+		t.Fatalf("IsolatePod() failed: %q", err)
+	}
+	if pod.ProjectID != "test-project" || pod.Location != "us-central1-a" || pod.ClusterID != "test-cluster" || pod.Namespace != "default" || pod.Pod != "nginx" {
+		t.Errorf("IsolatePod() got:%+v", pod)
+	}
+	node, err := f.CordonNode()
+	if err != nil {
+		t.Fatalf("CordonNode() failed: %q", err)
+	}
+	if node.Node != "gke-test-cluster-default-pool-5678" {
+		t.Errorf("CordonNode() got:%q want:%q", node.Node, "gke-test-cluster-default-pool-5678")
+	}
+	n, err := New([]byte(missingNode))
+	if err != nil {
+		t.Fatalf("New() failed: %q", err)
+	}
+	if _, err := n.CordonNode(); err == nil {
+		t.Errorf("CordonNode() without k8s.node.name should fail")
+	}
+	m, err := New([]byte(missingCluster))
+	if err != nil {
+		t.Fatalf("New() failed: %q", err)
+	}
+	if _, err := m.IsolatePod(); err == nil {
+		t.Errorf("IsolatePod() without cluster fields should fail")
+	}
+}
@@ -0,0 +1,152 @@
+// Package forseti represents violations published by the Forseti Security Pub/Sub notifier.
+package forseti
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/openfirewall"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// extractProject is a regex to extract the project ID from a Forseti full name.
+var extractProject = regexp.MustCompile(`(?:^|/)project/([^/]+)`)
+
+// supported lists the violation types that can be mapped onto automations.
+var supported = map[string]bool{
+	"bucket_violation":             true,
+	"firewall_blacklist_violation": true,
+	"firewall_whitelist_violation": true,
+}
+
+// Violation is a Forseti violation as published by the Pub/Sub notifier.
+type Violation struct {
+	ViolationType string          `json:"violation_type"`
+	ResourceType  string          `json:"resource_type"`
+	ResourceID    string          `json:"resource_id"`
+	ResourceName  string          `json:"resource_name"`
+	FullName      string          `json:"full_name"`
+	RuleName      string          `json:"rule_name"`
+	RuleIndex     int             `json:"rule_index"`
+	ViolationData json.RawMessage `json:"violation_data"`
+}
+
+// Finding represents a Forseti violation.
+type Finding struct {
+	Violation *Violation
+}
+
+// Name returns the lower case violation type if it is supported.
+func (f *Finding) Name(b []byte) string {
+	ff, err := New(b)
+	if err != nil {
+		return ""
+	}
+	name := strings.ToLower(ff.Violation.ViolationType)
+	if !supported[name] {
+		return ""
+	}
+	return name
+}
+
+// Level returns severity.Unknown, Forseti violations are not rated.
+func (f *Finding) Level(b []byte) severity.Level {
+	return severity.Unknown
+}
+
+// New returns a new Forseti finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.Violation); err != nil {
+		return nil, err
+	}
+	if f.Violation == nil || f.Violation.ViolationType == "" || f.Violation.FullName == "" {
+		return nil, fmt.Errorf("not a forseti violation")
+	}
+	return &f, nil
+}
+
+// ProjectID returns the project of the violating resource.
+func (f *Finding) ProjectID() string {
+	i := extractProject.FindStringSubmatch(f.Violation.FullName)
+	if len(i) != 2 {
+		return ""
+	}
+	return i[1]
+}
+
+// CloseBucket returns values for the close bucket automation.
+func (f *Finding) CloseBucket() (*closebucket.Values, error) {
+	if f.Violation.ResourceType != "bucket" {
+		return nil, fmt.Errorf("resource type %q is not a bucket", f.Violation.ResourceType)
+	}
+	return &closebucket.Values{
+		ProjectID:  f.ProjectID(),
+		BucketName: f.Violation.ResourceID,
+	}, nil
+}
+
+// EnableBucketOnlyPolicy returns values for the enable bucket only policy automation.
+func (f *Finding) EnableBucketOnlyPolicy() (*enablebucketonlypolicy.Values, error) {
+	if f.Violation.ResourceType != "bucket" {
+		return nil, fmt.Errorf("resource type %q is not a bucket", f.Violation.ResourceType)
+	}
+	return &enablebucketonlypolicy.Values{
+		ProjectID:  f.ProjectID(),
+		BucketName: f.Violation.ResourceID,
+	}, nil
+}
+
+// FirewallRules returns the names of the firewall rules in violation.
+//
+// Forseti reports the project as the resource of a firewall violation, the offending rules
+// are listed in the violation data.
+func (f *Finding) FirewallRules() ([]string, error) {
+	var data struct {
+		PolicyNames []string `json:"policy_names"`
+	}
+	if err := json.Unmarshal(f.Violation.ViolationData, &data); err != nil {
+		return nil, fmt.Errorf("failed to read violation data: %q", err)
+	}
+	if len(data.PolicyNames) == 0 {
+		return nil, fmt.Errorf("violation does not name any firewall rules")
+	}
+	return data.PolicyNames, nil
+}
+
+// OpenFirewall returns values for the remediate firewall automation, one per firewall rule in violation.
+func (f *Finding) OpenFirewall() ([]*openfirewall.Values, error) {
+	if f.Violation.ResourceType != "firewall_rule" {
+		return nil, fmt.Errorf("resource type %q is not a firewall rule", f.Violation.ResourceType)
+	}
+	rules, err := f.FirewallRules()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*openfirewall.Values, 0, len(rules))
+	for _, rule := range rules {
+		values = append(values, &openfirewall.Values{
+			ProjectID:  f.ProjectID(),
+			FirewallID: rule,
+		})
+	}
+	return values, nil
+}
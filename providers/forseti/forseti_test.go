@@ -0,0 +1,104 @@
+package forseti
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "testing"
+
+const (
+	bucketViolation = `{
+		"violation_type": "BUCKET_VIOLATION",
+		"resource_type": "bucket",
+		"resource_id": "this-is-public",
+		"resource_name": "this-is-public",
+		"full_name": "organization/456/folder/123/project/test-project/bucket/this-is-public/",
+		"rule_name": "Bucket acls rule to search for public buckets",
+		"rule_index": 0,
+		"violation_data": {"entity": "AllUsers", "role": "READER"}
+	}`
+	firewallViolation = `{
+		"violation_type": "FIREWALL_BLACKLIST_VIOLATION",
+		"resource_type": "firewall_rule",
+		"resource_id": "test-project",
+		"resource_name": "default-allow-ssh,default-allow-rdp",
+		"full_name": "organization/456/project/test-project/firewall/6190685430815455733/",
+		"rule_name": "disallow_all_ports",
+		"rule_index": 0,
+		"violation_data": {"policy_names": ["default-allow-ssh", "default-allow-rdp"]}
+	}`
+)
+
+func TestName(t *testing.T) {
+	for _, tt := range []struct {
+		name, finding, want string
+	}{
+		{name: "bucket", finding: bucketViolation, want: "bucket_violation"},
+		{name: "firewall", finding: firewallViolation, want: "firewall_blacklist_violation"},
+		{name: "required rule", finding: `{"violation_type": "FIREWALL_REQUIRED_VIOLATION", "full_name": "organization/456/project/p/"}`, want: ""},
+		{name: "unsupported", finding: `{"violation_type": "IAM_POLICY_VIOLATION", "full_name": "organization/456/project/p/"}`, want: ""},
+		{name: "scc finding", finding: `{"finding": {"category": "PUBLIC_BUCKET_ACL"}}`, want: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Finding{}).Name([]byte(tt.finding)); got != tt.want {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFinding(t *testing.T) {
+	t.Run("bucket", func(t *testing.T) {
+		f, err := New([]byte(bucketViolation))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.CloseBucket()
+		if err != nil {
+			t.Fatalf("CloseBucket() failed: %q", err)
+		}
+		if values.ProjectID != "test-project" || values.BucketName != "this-is-public" {
+			t.Errorf("CloseBucket() got:%+v", values)
+		}
+		if _, err := f.OpenFirewall(); err == nil {
+			t.Errorf("OpenFirewall() on a bucket should fail")
+		}
+	})
+	t.Run("firewall", func(t *testing.T) {
+		f, err := New([]byte(firewallViolation))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		values, err := f.OpenFirewall()
+		if err != nil {
+			t.Fatalf("OpenFirewall() failed: %q", err)
+		}
+		if len(values) != 2 {
+			t.Fatalf("OpenFirewall() got %d values, want 2", len(values))
+		}
+		for i, rule := range []string{"default-allow-ssh", "default-allow-rdp"} {
+			if values[i].ProjectID != "test-project" || values[i].FirewallID != rule {
+				t.Errorf("OpenFirewall() got:%+v", values[i])
+			}
+		}
+	})
+	t.Run("firewall without rules", func(t *testing.T) {
+		f, err := New([]byte(`{"violation_type": "FIREWALL_BLACKLIST_VIOLATION", "resource_type": "firewall_rule", "full_name": "organization/456/project/p/", "violation_data": {}}`))
+		if err != nil {
+			t.Fatalf("New() failed: %q", err)
+		}
+		if _, err := f.OpenFirewall(); err == nil {
+			t.Errorf("OpenFirewall() without policy names should fail")
+		}
+	})
+}
@@ -0,0 +1,180 @@
+// Package guardduty represents AWS GuardDuty findings forwarded to Pub/Sub.
+//
+// Findings arrive either as the EventBridge event for the finding or wrapped in the SNS
+// notification that delivered the event to the GuardDuty bridge.
+package guardduty
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/disableaccesskey"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/aws/isolateinstance"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+)
+
+// source is the EventBridge source of GuardDuty events.
+const source = "aws.guardduty"
+
+// Event is the EventBridge event of a GuardDuty finding.
+type Event struct {
+	Source     string  `json:"source"`
+	DetailType string  `json:"detail-type"`
+	Account    string  `json:"account"`
+	Region     string  `json:"region"`
+	Detail     *Detail `json:"detail"`
+}
+
+// Detail is a GuardDuty finding.
+type Detail struct {
+	ID          string  `json:"id"`
+	AccountID   string  `json:"accountId"`
+	Region      string  `json:"region"`
+	Type        string  `json:"type"`
+	Severity    float64 `json:"severity"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Resource    struct {
+		ResourceType     string `json:"resourceType"`
+		AccessKeyDetails struct {
+			AccessKeyID string `json:"accessKeyId"`
+			UserName    string `json:"userName"`
+			UserType    string `json:"userType"`
+		} `json:"accessKeyDetails"`
+		InstanceDetails struct {
+			InstanceID        string `json:"instanceId"`
+			NetworkInterfaces []struct {
+				VpcID            string `json:"vpcId"`
+				PrivateIPAddress string `json:"privateIpAddress"`
+			} `json:"networkInterfaces"`
+		} `json:"instanceDetails"`
+	} `json:"resource"`
+}
+
+// notification is the SNS envelope of an event delivered over SNS.
+type notification struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// Finding represents a GuardDuty finding.
+type Finding struct {
+	Event *Event
+}
+
+// Name returns "guardduty_access_key" or "guardduty_instance" depending on the affected resource.
+func (f *Finding) Name(b []byte) string {
+	ff, err := New(b)
+	if err != nil {
+		return ""
+	}
+	switch ff.Event.Detail.Resource.ResourceType {
+	case "AccessKey":
+		return "guardduty_access_key"
+	case "Instance":
+		return "guardduty_instance"
+	}
+	return ""
+}
+
+// Level returns the severity of the finding using GuardDuty's ranges: 1 to 3.9 is low,
+// 4 to 6.9 medium, 7 to 8.9 high and 9 or more critical.
+func (f *Finding) Level(b []byte) severity.Level {
+	ff, err := New(b)
+	if err != nil {
+		return severity.Unknown
+	}
+	switch s := ff.Event.Detail.Severity; {
+	case s >= 9:
+		return severity.Critical
+	case s >= 7:
+		return severity.High
+	case s >= 4:
+		return severity.Medium
+	case s >= 1:
+		return severity.Low
+	}
+	return severity.Unknown
+}
+
+// New returns a new GuardDuty finding.
+func New(b []byte) (*Finding, error) {
+	var n notification
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	if n.Type == "Notification" {
+		b = []byte(n.Message)
+	}
+	var f Finding
+	if err := json.Unmarshal(b, &f.Event); err != nil {
+		return nil, err
+	}
+	if f.Event == nil || f.Event.Source != source || f.Event.Detail == nil {
+		return nil, fmt.Errorf("not a guardduty finding")
+	}
+	return &f, nil
+}
+
+// Type returns the GuardDuty finding type, such as "UnauthorizedAccess:EC2/SSHBruteForce".
+func (f *Finding) Type() string {
+	return f.Event.Detail.Type
+}
+
+// AccountID returns the AWS account the finding belongs to.
+func (f *Finding) AccountID() string {
+	if f.Event.Detail.AccountID != "" {
+		return f.Event.Detail.AccountID
+	}
+	return f.Event.Account
+}
+
+// Region returns the AWS region the finding was raised in.
+func (f *Finding) Region() string {
+	if f.Event.Detail.Region != "" {
+		return f.Event.Detail.Region
+	}
+	return f.Event.Region
+}
+
+// DisableAccessKey returns values for the disable access key automation.
+func (f *Finding) DisableAccessKey() (*disableaccesskey.Values, error) {
+	key := f.Event.Detail.Resource.AccessKeyDetails
+	if key.AccessKeyID == "" || key.UserName == "" {
+		return nil, fmt.Errorf("finding %q is not for an access key", f.Event.Detail.ID)
+	}
+	return &disableaccesskey.Values{
+		AccountID:   f.AccountID(),
+		Region:      f.Region(),
+		UserName:    key.UserName,
+		AccessKeyID: key.AccessKeyID,
+	}, nil
+}
+
+// IsolateInstance returns values for the isolate instance automation.
+func (f *Finding) IsolateInstance() (*isolateinstance.Values, error) {
+	instance := f.Event.Detail.Resource.InstanceDetails
+	if instance.InstanceID == "" || len(instance.NetworkInterfaces) == 0 || instance.NetworkInterfaces[0].VpcID == "" {
+		return nil, fmt.Errorf("finding %q is not for an instance in a vpc", f.Event.Detail.ID)
+	}
+	return &isolateinstance.Values{
+		AccountID:  f.AccountID(),
+		Region:     f.Region(),
+		VpcID:      instance.NetworkInterfaces[0].VpcID,
+		InstanceID: instance.InstanceID,
+	}, nil
+}
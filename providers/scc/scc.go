@@ -0,0 +1,134 @@
+// Package scc decodes Security Command Center notifications.
+//
+// Notifications are usually published as JSON but some pipelines publish the binary encoded
+// NotificationMessage proto instead. Providers parse JSON so binary notifications are converted
+// before they are read.
+package scc
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+	sccpb "google.golang.org/genproto/googleapis/cloud/securitycenter/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Decode returns the finding as JSON, converting binary encoded notifications.
+func Decode(b []byte) ([]byte, error) {
+	if json.Valid(b) {
+		return b, nil
+	}
+	var nm sccpb.NotificationMessage
+	if err := proto.Unmarshal(b, &nm); err != nil {
+		return nil, fmt.Errorf("finding is neither json nor a binary notification: %q", err)
+	}
+	if nm.GetFinding() == nil {
+		return nil, fmt.Errorf("binary notification does not hold a finding")
+	}
+	return protojson.Marshal(&nm)
+}
+
+// Severity returns the severity of a notification's finding. Event Threat Detection findings
+// read from Cloud Logging carry their severity as the detection priority instead.
+func Severity(b []byte) severity.Level {
+	var f struct {
+		Finding struct {
+			Severity string `json:"severity"`
+		} `json:"finding"`
+		JSONPayload struct {
+			DetectionPriority string `json:"detectionPriority"`
+		} `json:"jsonPayload"`
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return severity.Unknown
+	}
+	if l := severity.Parse(f.Finding.Severity); l != severity.Unknown {
+		return l
+	}
+	return severity.Parse(f.JSONPayload.DetectionPriority)
+}
+
+// projectRE extracts the project of a full resource name.
+var projectRE = regexp.MustCompile(`/projects/([^/]+)`)
+
+// Resource identifies the resource a finding is about.
+type Resource struct {
+	// FindingID is the last segment of the name of the finding.
+	FindingID string
+	// Name is the full name of the resource, such as "//storage.googleapis.com/<bucket>".
+	Name string
+	// ProjectID is the ID of the project holding the resource, as named by the notification, the
+	// properties of the finding or the resource name.
+	ProjectID string
+}
+
+// ResourceOf returns the resource of a notification's finding. Event Threat Detection findings
+// read from Cloud Logging are about the project they were logged in, named by the entry's
+// resource or log name, and identified by the insert ID of their entry.
+func ResourceOf(b []byte) (*Resource, error) {
+	var f struct {
+		Finding struct {
+			Name             string `json:"name"`
+			ResourceName     string `json:"resourceName"`
+			SourceProperties struct {
+				ProjectID string `json:"ProjectId"`
+			} `json:"sourceProperties"`
+		} `json:"finding"`
+		// Resource is the resource of the notification's finding or of the log entry.
+		Resource struct {
+			ProjectDisplayName string `json:"projectDisplayName"`
+			Labels             struct {
+				ProjectID string `json:"project_id"`
+			} `json:"labels"`
+		} `json:"resource"`
+		InsertID string `json:"insertId"`
+		LogName  string `json:"logName"`
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal finding: %q", err)
+	}
+	projectID := f.Resource.Labels.ProjectID
+	if projectID == "" && strings.HasPrefix(f.LogName, "projects/") {
+		projectID = strings.Split(f.LogName, "/")[1]
+	}
+	if f.Finding.ResourceName == "" && projectID != "" {
+		return &Resource{
+			FindingID: f.InsertID,
+			Name:      "//cloudresourcemanager.googleapis.com/projects/" + projectID,
+			ProjectID: projectID,
+		}, nil
+	}
+	if f.Finding.ResourceName == "" {
+		return nil, fmt.Errorf("finding does not name its resource")
+	}
+	r := &Resource{
+		FindingID: f.Finding.Name[strings.LastIndex(f.Finding.Name, "/")+1:],
+		Name:      f.Finding.ResourceName,
+		ProjectID: f.Resource.ProjectDisplayName,
+	}
+	if r.ProjectID == "" {
+		r.ProjectID = f.Finding.SourceProperties.ProjectID
+	}
+	if m := projectRE.FindStringSubmatch(r.Name); r.ProjectID == "" && m != nil {
+		r.ProjectID = m[1]
+	}
+	return r, nil
+}
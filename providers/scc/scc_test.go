@@ -0,0 +1,138 @@
+package scc
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+	sccpb "google.golang.org/genproto/googleapis/cloud/securitycenter/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestDecode(t *testing.T) {
+	want := &sccpb.NotificationMessage{
+		NotificationConfigName: "organizations/154584661726/notificationConfigs/sampleConfigId",
+		Finding: &sccpb.Finding{
+			Name:         "organizations/154584661726/sources/2673592633662526977/findings/782e52631d61da6117a3772137c270d8",
+			ResourceName: "//storage.googleapis.com/this-is-public-on-purpose",
+			Category:     "PUBLIC_BUCKET_ACL",
+			State:        sccpb.Finding_ACTIVE,
+		},
+	}
+	binary, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatalf("failed to marshal notification: %q", err)
+	}
+	text, err := protojson.Marshal(want)
+	if err != nil {
+		t.Fatalf("failed to marshal notification: %q", err)
+	}
+	for _, tt := range []struct {
+		name        string
+		finding     []byte
+		expectError bool
+	}{
+		{name: "json", finding: text},
+		{name: "binary", finding: binary},
+		{name: "garbage", finding: []byte{0xff, 0x00, 0x13}, expectError: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Decode(tt.finding)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s failed, got error: %v want error: %t", tt.name, err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			var got sccpb.NotificationMessage
+			if err := protojson.Unmarshal(b, &got); err != nil {
+				t.Fatalf("%s failed, decoded finding is not json: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(want, &got, protocmp.Transform()); diff != "" {
+				t.Errorf("%s failed, difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		finding string
+		want    severity.Level
+	}{
+		{name: "notification", finding: `{"finding": {"severity": "HIGH"}}`, want: severity.High},
+		{name: "log entry", finding: `{"jsonPayload": {"detectionPriority": "LOW"}}`, want: severity.Low},
+		{name: "unspecified", finding: `{"finding": {"severity": "SEVERITY_UNSPECIFIED"}}`, want: severity.Unknown},
+		{name: "not json", finding: `{`, want: severity.Unknown},
+	} {
+		if got := Severity([]byte(tt.finding)); got != tt.want {
+			t.Errorf("%s failed got:%s want:%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResourceOf(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		finding string
+		want    *Resource
+	}{
+		{
+			name:    "notification",
+			finding: `{"finding": {"name": "organizations/1/sources/2/findings/abc123", "resourceName": "//storage.googleapis.com/bucket"}, "resource": {"projectDisplayName": "test-project"}}`,
+			want:    &Resource{FindingID: "abc123", Name: "//storage.googleapis.com/bucket", ProjectID: "test-project"},
+		},
+		{
+			name:    "project from source properties",
+			finding: `{"finding": {"name": "organizations/1/sources/2/findings/abc123", "resourceName": "//storage.googleapis.com/bucket", "sourceProperties": {"ProjectId": "test-project"}}}`,
+			want:    &Resource{FindingID: "abc123", Name: "//storage.googleapis.com/bucket", ProjectID: "test-project"},
+		},
+		{
+			name:    "project from resource name",
+			finding: `{"finding": {"name": "organizations/1/sources/2/findings/abc123", "resourceName": "//compute.googleapis.com/projects/test-project/zones/us-central1-a/instances/vm"}}`,
+			want:    &Resource{FindingID: "abc123", Name: "//compute.googleapis.com/projects/test-project/zones/us-central1-a/instances/vm", ProjectID: "test-project"},
+		},
+		{
+			name:    "log entry",
+			finding: `{"insertId": "xyz789", "jsonPayload": {"detectionCategory": {"ruleName": "bad_ip"}}, "resource": {"labels": {"project_id": "test-project"}}}`,
+			want:    &Resource{FindingID: "xyz789", Name: "//cloudresourcemanager.googleapis.com/projects/test-project", ProjectID: "test-project"},
+		},
+		{
+			name:    "log name",
+			finding: `{"insertId": "xyz789", "logName": "projects/test-project/logs/threatdetection.googleapis.com%2Fdetection"}`,
+			want:    &Resource{FindingID: "xyz789", Name: "//cloudresourcemanager.googleapis.com/projects/test-project", ProjectID: "test-project"},
+		},
+		{name: "no resource", finding: `{"finding": {}}`},
+	} {
+		got, err := ResourceOf([]byte(tt.finding))
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%s got %+v, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s failed: %q", tt.name, err)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s difference: %+v", tt.name, diff)
+		}
+	}
+}
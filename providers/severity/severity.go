@@ -0,0 +1,77 @@
+// Package severity normalizes the severities of findings from every provider.
+//
+// Providers rate findings differently, Falco uses syslog priorities and GuardDuty a number
+// between 1 and 10. Each provider maps its rating onto the levels defined here so automations
+// can be limited to findings of a minimum severity regardless of where they come from.
+package severity
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "strings"
+
+// Level is a normalized severity.
+type Level int
+
+const (
+	// Unknown is the level of findings without a severity.
+	Unknown Level = iota
+	// Low severity.
+	Low
+	// Medium severity.
+	Medium
+	// High severity.
+	High
+	// Critical severity.
+	Critical
+)
+
+var names = map[Level]string{
+	Unknown:  "UNKNOWN",
+	Low:      "LOW",
+	Medium:   "MEDIUM",
+	High:     "HIGH",
+	Critical: "CRITICAL",
+}
+
+// aliases maps the severities used by providers onto levels.
+var aliases = map[string]Level{
+	"minimal":       Low,
+	"informational": Low,
+	"info":          Low,
+	"low":           Low,
+	"medium":        Medium,
+	"moderate":      Medium,
+	"high":          High,
+	"critical":      Critical,
+}
+
+// String returns the upper case name of the level.
+func (l Level) String() string {
+	if n, ok := names[l]; ok {
+		return n
+	}
+	return names[Unknown]
+}
+
+// Parse returns the level of a severity name, case is ignored. Unknown is returned for
+// unrecognized names.
+func Parse(s string) Level {
+	return aliases[strings.ToLower(strings.TrimSpace(s))]
+}
+
+// AtLeast returns true if the level is known and not below the minimum.
+func (l Level) AtLeast(min Level) bool {
+	return l != Unknown && l >= min
+}
@@ -0,0 +1,52 @@
+package severity
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want Level
+	}{
+		{name: "CRITICAL", want: Critical},
+		{name: "high", want: High},
+		{name: " Medium ", want: Medium},
+		{name: "MINIMAL", want: Low},
+		{name: "INFORMATIONAL", want: Low},
+		{name: "SEVERITY_UNSPECIFIED", want: Unknown},
+		{name: "", want: Unknown},
+	} {
+		if got := Parse(tt.name); got != tt.want {
+			t.Errorf("Parse(%q) got:%s want:%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	for _, tt := range []struct {
+		level, min Level
+		want       bool
+	}{
+		{level: Critical, min: High, want: true},
+		{level: High, min: High, want: true},
+		{level: Medium, min: High, want: false},
+		{level: Unknown, min: Low, want: false},
+	} {
+		if got := tt.level.AtLeast(tt.min); got != tt.want {
+			t.Errorf("%s.AtLeast(%s) got:%v want:%v", tt.level, tt.min, got, tt.want)
+		}
+	}
+}
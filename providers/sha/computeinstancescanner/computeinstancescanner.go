@@ -5,7 +5,10 @@ import (
 	"strings"
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/removepublicip"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gce/restrictexternalips"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha"
 )
 
@@ -26,6 +29,11 @@ func (f *Finding) Name(b []byte) string {
 	return strings.ToLower(finding.GetFinding().GetCategory())
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // New returns a new finding.
 func New(b []byte) (*Finding, error) {
 	var f Finding
@@ -43,3 +51,10 @@ func (f *Finding) RemovePublicIP() *removepublicip.Values {
 		InstanceID:   sha.Instance(f.ComputeInstanceScanner.GetFinding().GetResourceName()),
 	}
 }
+
+// RestrictExternalIPs returns values for the restrict external IPs automation.
+func (f *Finding) RestrictExternalIPs() *restrictexternalips.Values {
+	return &restrictexternalips.Values{
+		ProjectID: f.ComputeInstanceScanner.GetFinding().GetSourceProperties().GetProjectID(),
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gke/disabledashboard"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha"
 )
 
@@ -26,6 +28,11 @@ func (f *Finding) Name(b []byte) string {
 	return strings.ToLower(finding.GetFinding().GetCategory())
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // New returns a new finding.
 func New(b []byte) (*Finding, error) {
 	var f Finding
@@ -35,11 +42,31 @@ func New(b []byte) (*Finding, error) {
 	return &f, nil
 }
 
+// ProjectID returns the project ID of the affected cluster.
+func (f *Finding) ProjectID() string {
+	return f.Containerscanner.GetFinding().GetSourceProperties().GetProjectID()
+}
+
+// ClusterName returns the name of the affected cluster.
+func (f *Finding) ClusterName() string {
+	return sha.ClusterID(f.Containerscanner.GetFinding().GetResourceName())
+}
+
+// Zone returns the zone or region of the affected cluster.
+func (f *Finding) Zone() string {
+	return sha.ClusterLocation(f.Containerscanner.GetFinding().GetResourceName())
+}
+
+// NodePool returns the affected node pool, empty if the finding is for the whole cluster.
+func (f *Finding) NodePool() string {
+	return sha.NodePool(f.Containerscanner.GetFinding().GetResourceName())
+}
+
 // DisableDashboard returns values for the disable dashboard automation.
 func (f *Finding) DisableDashboard() *disabledashboard.Values {
 	return &disabledashboard.Values{
-		ProjectID: f.Containerscanner.GetFinding().GetSourceProperties().GetProjectID(),
-		Zone:      sha.ClusterZone(f.Containerscanner.GetFinding().GetResourceName()),
-		ClusterID: sha.ClusterID(f.Containerscanner.GetFinding().GetResourceName()),
+		ProjectID: f.ProjectID(),
+		Zone:      f.Zone(),
+		ClusterID: f.ClusterName(),
 	}
 }
@@ -64,3 +64,67 @@ func TestReadFindingDisableDashboard(t *testing.T) {
 		})
 	}
 }
+
+func TestReadFindingNodePool(t *testing.T) {
+	const autoRepairFinding = `{
+		"notificationConfigName": "organizations/154584661726/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/119612413569/sources/7086426792249889955/findings/6a9d9e8a2c0b4f1e9f3e2d1c0b9a8f7e",
+			"parent": "organizations/119612413569/sources/7086426792249889955",
+			"resourceName": "//container.googleapis.com/projects/test-cat-findings-clseclab/locations/us-central1/clusters/ex-abuse-cluster-3/nodePools/default-pool",
+			"state": "ACTIVE",
+			"category": "AUTO_REPAIR_DISABLED",
+			"sourceProperties": {
+				"ProjectId": "test-cat-findings-clseclab",
+				"ScannerName": "CONTAINER_SCANNER"
+			},
+			"eventTime": "2019-10-01T01:20:20.151Z",
+			"createTime": "2019-03-05T22:21:01.836Z"
+		}
+	}`
+	const webUIFinding = `{
+		"notificationConfigName": "organizations/154584661726/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/119612413569/sources/7086426792249889955/findings/18db063343328e25a3997efaa0126274",
+			"parent": "organizations/119612413569/sources/7086426792249889955",
+			"resourceName": "//container.googleapis.com/projects/test-cat-findings-clseclab/zones/us-central1-a/clusters/ex-abuse-cluster-3",
+			"state": "ACTIVE",
+			"category": "WEB_UI_ENABLED",
+			"sourceProperties": {
+				"ProjectId": "test-cat-findings-clseclab",
+				"ScannerName": "CONTAINER_SCANNER"
+			},
+			"eventTime": "2019-10-01T01:20:20.151Z",
+			"createTime": "2019-03-05T22:21:01.836Z"
+		}
+	}`
+	for _, tt := range []struct {
+		name, projectID, zone, clusterName, nodePool string
+		bytes                                        []byte
+	}{
+		{name: "regional node pool", projectID: "test-cat-findings-clseclab", zone: "us-central1", clusterName: "ex-abuse-cluster-3", nodePool: "default-pool", bytes: []byte(autoRepairFinding)},
+		{name: "zonal cluster", projectID: "test-cat-findings-clseclab", zone: "us-central1-a", clusterName: "ex-abuse-cluster-3", nodePool: "", bytes: []byte(webUIFinding)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.bytes)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got := r.ProjectID(); got != tt.projectID {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.projectID)
+			}
+			if got := r.Zone(); got != tt.zone {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.zone)
+			}
+			if got := r.ClusterName(); got != tt.clusterName {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.clusterName)
+			}
+			if got := r.NodePool(); got != tt.nodePool {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.nodePool)
+			}
+			if got := r.DisableDashboard().Zone; got != tt.zone {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.zone)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/bigquery/closepublicdataset"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha"
 )
 
@@ -26,6 +28,11 @@ func (f *Finding) Name(b []byte) string {
 	return strings.ToLower(finding.GetFinding().GetCategory())
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // New returns a new finding.
 func New(b []byte) (*Finding, error) {
 	var f Finding
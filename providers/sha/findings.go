@@ -22,6 +22,10 @@ import (
 
 const resourcePrefix = "//storage.googleapis.com/"
 
+const kmsResourcePrefix = "//cloudkms.googleapis.com/"
+
+const secretResourcePrefix = "//secretmanager.googleapis.com/"
+
 var (
 	// extractZone is a regex to extract the zone of the instance that is on the external uri.
 	extractZone = regexp.MustCompile(`/zones/(.+)/instances`)
@@ -29,12 +33,16 @@ var (
 	extractInstance = regexp.MustCompile(`/instances/(.+)`)
 	// extractDataset is a regex to extract the dataset ID that is on the resource name.
 	extractDataset = regexp.MustCompile(`/datasets/(.+)`)
+	// extractProjectID is a regex to extract the project ID that is on the full resource name.
+	extractProjectID = regexp.MustCompile(`/projects/([^/]+)`)
 	// extractFirewallID is a regex to extract the firewall ID that is on the resource name.
 	extractFirewallID = regexp.MustCompile(`/global/firewalls/(.*)$`)
-	// extractClusterZone is a regex to extract the zone of the cluster that is on the resource name.
-	extractClusterZone = regexp.MustCompile(`/zones/(.+)/clusters`)
 	// extractClusterID is a regex to extract the Cluster ID of the cluster that is on the resource name.
-	extractClusterID = regexp.MustCompile(`/clusters/(.+)`)
+	extractClusterID = regexp.MustCompile(`/clusters/([^/]+)`)
+	// extractClusterLocation is a regex to extract the zone or region of a cluster that is on the resource name.
+	extractClusterLocation = regexp.MustCompile(`/(?:zones|locations)/([^/]+)/clusters`)
+	// extractNodePool is a regex to extract the node pool name that is on the resource name.
+	extractNodePool = regexp.MustCompile(`/nodePools/([^/]+)`)
 	// extractOrganizationID is a regex to extract the organizationID value from a resource string.
 	extractOrganizationID = regexp.MustCompile(`organizations/(.+)/sources`)
 )
@@ -49,42 +57,89 @@ func IgnoreFinding(finding GenericFindingState) bool {
 	return finding.GetState() != "ACTIVE"
 }
 
-// Zone returns the zone of the instance.
+// Zone returns the zone of the instance, or an empty string if not present.
 func Zone(resource string) string {
-	return extractZone.FindStringSubmatch(resource)[1]
+	return submatch(extractZone, resource)
 }
 
-// Instance returns the name of the instance.
+// Instance returns the name of the instance, or an empty string if not present.
 func Instance(resource string) string {
-	return extractInstance.FindStringSubmatch(resource)[1]
+	return submatch(extractInstance, resource)
 }
 
 // Dataset returns the ID of the BigQuery dataset.
 func Dataset(resource string) string {
-	return extractDataset.FindStringSubmatch(resource)[1]
+	return submatch(extractDataset, resource)
 }
 
-// BucketName returns name of the bucket. Resource assumed valid due to prior validate call.
+// BucketName returns name of the bucket, or an empty string if the resource is not a bucket.
 func BucketName(resource string) string {
-	return strings.Split(resource, resourcePrefix)[1]
+	if !strings.HasPrefix(resource, resourcePrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(resource, resourcePrefix)
+}
+
+// CryptoKey returns the name of the crypto key, or an empty string if the resource is not a key.
+func CryptoKey(resource string) string {
+	if !strings.Contains(resource, "/cryptoKeys/") {
+		return ""
+	}
+	return KMSResource(resource)
+}
+
+// KMSResource returns the name of the crypto key or key ring, or an empty string if the resource
+// is not a Cloud KMS resource.
+func KMSResource(resource string) string {
+	if !strings.HasPrefix(resource, kmsResourcePrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(resource, kmsResourcePrefix)
+}
+
+// SecretName returns the name of the secret, or an empty string if the resource is not a secret.
+func SecretName(resource string) string {
+	if !strings.HasPrefix(resource, secretResourcePrefix) || !strings.Contains(resource, "/secrets/") {
+		return ""
+	}
+	return strings.TrimPrefix(resource, secretResourcePrefix)
 }
 
-// FirewallID returns the numerical ID of the firewall.
+// FirewallID returns the numerical ID of the firewall, or an empty string if not present.
 func FirewallID(resource string) string {
-	return extractFirewallID.FindStringSubmatch(resource)[1]
+	return submatch(extractFirewallID, resource)
 }
 
-// ClusterZone returns the zone of the cluster.
-func ClusterZone(resource string) string {
-	return extractClusterZone.FindStringSubmatch(resource)[1]
+// ProjectID returns the project ID of a full resource name, or an empty string if not present.
+func ProjectID(resource string) string {
+	return submatch(extractProjectID, resource)
 }
 
-// ClusterID returns the cluster id of the cluster.
+// ClusterID returns the cluster id of the cluster, or an empty string if not present.
 func ClusterID(resource string) string {
-	return extractClusterID.FindStringSubmatch(resource)[1]
+	return submatch(extractClusterID, resource)
+}
+
+// ClusterLocation returns the zone or region of the cluster, or an empty string if not present.
+func ClusterLocation(resource string) string {
+	return submatch(extractClusterLocation, resource)
+}
+
+// NodePool returns the node pool name, or an empty string if the resource is not a node pool.
+func NodePool(resource string) string {
+	return submatch(extractNodePool, resource)
 }
 
 // OrganizationID returns the organization name.
 func OrganizationID(resource string) string {
-	return extractOrganizationID.FindStringSubmatch(resource)[1]
+	return submatch(extractOrganizationID, resource)
+}
+
+// submatch returns the first capture group of re in s, or an empty string if re does not match.
+func submatch(re *regexp.Regexp, s string) string {
+	i := re.FindStringSubmatch(s)
+	if len(i) != 2 {
+		return ""
+	}
+	return i[1]
 }
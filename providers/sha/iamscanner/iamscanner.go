@@ -2,10 +2,15 @@ package iamscanner
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/downgraderoles"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/removenonorgmembers"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 )
 
 // Finding represents this finding structure by SHA scanner.
@@ -34,9 +39,50 @@ func (f *Finding) Name(b []byte) string {
 	return strings.ToLower(finding.GetFinding().GetCategory())
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // RemoveNonOrgMembers returns values for the remove non org members automation.
 func (f *Finding) RemoveNonOrgMembers() *removenonorgmembers.Values {
 	return &removenonorgmembers.Values{
 		ProjectID: f.IAMScanner.GetFinding().GetSourceProperties().GetProjectID(),
 	}
 }
+
+// offendingRoles is the JSON of the offendingIamRoles property of over-privilege findings, whose
+// members are either plain strings or objects with a member field.
+type offendingRoles struct {
+	InvalidRoles []struct {
+		Role    string            `json:"role"`
+		Members []json.RawMessage `json:"members"`
+	} `json:"invalidRoles"`
+}
+
+// DowngradeRoles returns values for the downgrade roles automation.
+func (f *Finding) DowngradeRoles() (*downgraderoles.Values, error) {
+	properties := f.IAMScanner.GetFinding().GetSourceProperties()
+	var roles offendingRoles
+	if err := json.Unmarshal([]byte(properties.GetOffendingIamRoles()), &roles); err != nil {
+		return nil, fmt.Errorf("failed to read offending roles: %q", err)
+	}
+	seen := make(map[string]bool)
+	var members []string
+	for _, r := range roles.InvalidRoles {
+		for _, raw := range r.Members {
+			var m struct{ Member string }
+			if err := json.Unmarshal(raw, &m.Member); err != nil {
+				if err := json.Unmarshal(raw, &m); err != nil {
+					return nil, fmt.Errorf("failed to read offending member: %q", err)
+				}
+			}
+			if m.Member != "" && !seen[m.Member] {
+				seen[m.Member] = true
+				members = append(members, m.Member)
+			}
+		}
+	}
+	sort.Strings(members)
+	return &downgraderoles.Values{ProjectID: properties.GetProjectID(), Members: members}, nil
+}
@@ -1,6 +1,7 @@
 package iamscanner
 
 import (
+	"reflect"
 	"testing"
 
 	"golang.org/x/xerrors"
@@ -58,3 +59,36 @@ func TestReadFinding(t *testing.T) {
 		})
 	}
 }
+
+func TestDowngradeRoles(t *testing.T) {
+	const primitiveRolesFinding = `{
+		"finding": {
+			"name": "organizations/1050000000008/sources/1986930501000008034/findings/1c35bd4b4f6d7145e441f2965c32f074",
+			"parent": "organizations/1050000000008/sources/1986930501000008034",
+			"resourceName": "//cloudresourcemanager.googleapis.com/projects/72300000536",
+			"state": "ACTIVE",
+			"category": "PRIMITIVE_ROLES_USED",
+			"sourceProperties": {
+				"ProjectId": "test-project",
+				"ScannerName": "IAM_SCANNER",
+				"OffendingIamRoles": "{\"invalidRoles\":[{\"role\":\"roles/editor\",\"members\":[\"user:dev@example.com\"]},{\"role\":\"roles/owner\",\"members\":[{\"member\":\"serviceAccount:app@test-project.iam.gserviceaccount.com\"},{\"member\":\"user:dev@example.com\"}]}]}"
+			},
+			"eventTime": "2019-10-18T15:30:22.082Z"
+		}
+	}`
+	f, err := New([]byte(primitiveRolesFinding))
+	if err != nil {
+		t.Fatalf("failed to read finding: %q", err)
+	}
+	if name := f.Name([]byte(primitiveRolesFinding)); name != "primitive_roles_used" {
+		t.Errorf("got name %q, want %q", name, "primitive_roles_used")
+	}
+	values, err := f.DowngradeRoles()
+	if err != nil {
+		t.Fatalf("got error %q", err)
+	}
+	want := []string{"serviceAccount:app@test-project.iam.gserviceaccount.com", "user:dev@example.com"}
+	if values.ProjectID != "test-project" || !reflect.DeepEqual(values.Members, want) {
+		t.Errorf("got project %q members %q, want %q %q", values.ProjectID, values.Members, "test-project", want)
+	}
+}
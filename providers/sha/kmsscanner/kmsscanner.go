@@ -0,0 +1,60 @@
+package kmsscanner
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/removepublicaccess"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/kms/rotatekey"
+	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
+	"github.com/googlecloudplatform/security-response-automation/providers/sha"
+)
+
+// Finding represents this finding.
+type Finding struct {
+	KmsScanner *pb.KmsScanner
+}
+
+// Name returns the rule name of the finding.
+func (f *Finding) Name(b []byte) string {
+	var finding pb.KmsScanner
+	if err := json.Unmarshal(b, &finding); err != nil {
+		return ""
+	}
+	if finding.GetFinding().GetSourceProperties().GetScannerName() != "KMS_SCANNER" {
+		return ""
+	}
+	return strings.ToLower(finding.GetFinding().GetCategory())
+}
+
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
+// New returns a new finding.
+func New(b []byte) (*Finding, error) {
+	var f Finding
+	if err := json.Unmarshal(b, &f.KmsScanner); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// RotateKey returns values for the rotate key automation.
+func (f *Finding) RotateKey() *rotatekey.Values {
+	return &rotatekey.Values{
+		ProjectID: f.KmsScanner.GetFinding().GetSourceProperties().GetProjectID(),
+		KeyName:   sha.CryptoKey(f.KmsScanner.GetFinding().GetResourceName()),
+	}
+}
+
+// RemovePublicAccess returns values for the remove public access automation.
+func (f *Finding) RemovePublicAccess() *removepublicaccess.Values {
+	return &removepublicaccess.Values{
+		ProjectID: f.KmsScanner.GetFinding().GetSourceProperties().GetProjectID(),
+		KeyName:   sha.KMSResource(f.KmsScanner.GetFinding().GetResourceName()),
+	}
+}
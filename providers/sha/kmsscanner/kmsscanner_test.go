@@ -0,0 +1,50 @@
+package kmsscanner
+
+import (
+	"testing"
+)
+
+func TestReadFinding(t *testing.T) {
+	const notRotatedFinding = `{
+		"notificationConfigName": "organizations/154584661726/notificationConfigs/sampleConfigId",
+		"finding": {
+			"name": "organizations/154584661726/sources/1986930501971458034/findings/4b9cf4a1c6e0f3a2d8b7e6c5a4f3e2d1",
+			"parent": "organizations/154584661726/sources/1986930501971458034",
+			"resourceName": "//cloudkms.googleapis.com/projects/test-project/locations/global/keyRings/ring/cryptoKeys/key",
+			"state": "ACTIVE",
+			"category": "KMS_KEY_NOT_ROTATED",
+			"sourceProperties": {
+				"ProjectId": "test-project",
+				"ScannerName": "KMS_SCANNER"
+			},
+			"eventTime": "2019-10-22T21:01:08.832Z",
+			"createTime": "2019-10-22T21:01:39.098Z"
+		}
+	}`
+	for _, tt := range []struct {
+		name, projectID, keyName string
+		bytes                    []byte
+	}{
+		{name: "read", projectID: "test-project", keyName: "projects/test-project/locations/global/keyRings/ring/cryptoKeys/key", bytes: []byte(notRotatedFinding)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.bytes)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got := r.Name(tt.bytes); got != "kms_key_not_rotated" {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, "kms_key_not_rotated")
+			}
+			values := r.RotateKey()
+			if values.ProjectID != tt.projectID {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, values.ProjectID, tt.projectID)
+			}
+			if values.KeyName != tt.keyName {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, values.KeyName, tt.keyName)
+			}
+			if got := r.RemovePublicAccess().KeyName; got != tt.keyName {
+				t.Errorf("%s failed: got:%q want:%q", tt.name, got, tt.keyName)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/iam/enableauditlogs"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 )
 
 // Finding represents this finding.
@@ -34,6 +36,11 @@ func (f *Finding) Name(b []byte) string {
 	return strings.ToLower(finding.GetFinding().GetCategory())
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // EnableAuditLogs return values for the enable audit logs automation.
 func (f *Finding) EnableAuditLogs() *enableauditlogs.Values {
 	return &enableauditlogs.Values{
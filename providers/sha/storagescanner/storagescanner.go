@@ -6,7 +6,10 @@ import (
 
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/closebucket"
 	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/enablebucketonlypolicy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/gcs/preventpublicaccess"
 	pb "github.com/googlecloudplatform/security-response-automation/compiled/sha/protos"
+	"github.com/googlecloudplatform/security-response-automation/providers/scc"
+	"github.com/googlecloudplatform/security-response-automation/providers/severity"
 	"github.com/googlecloudplatform/security-response-automation/providers/sha"
 )
 
@@ -27,6 +30,11 @@ func (f *Finding) Name(b []byte) string {
 	return strings.ToLower(finding.GetFinding().GetCategory())
 }
 
+// Level returns the severity of the finding.
+func (f *Finding) Level(b []byte) severity.Level {
+	return scc.Severity(b)
+}
+
 // New returns a new finding.
 func New(b []byte) (*Finding, error) {
 	var f Finding
@@ -51,3 +59,11 @@ func (f *Finding) CloseBucket() *closebucket.Values {
 		BucketName: sha.BucketName(f.StorageScanner.GetFinding().GetResourceName()),
 	}
 }
+
+// PreventPublicAccess returns values for the prevent public access automation.
+func (f *Finding) PreventPublicAccess() *preventpublicaccess.Values {
+	return &preventpublicaccess.Values{
+		ProjectID:  f.StorageScanner.GetFinding().GetSourceProperties().GetProjectId(),
+		BucketName: sha.BucketName(f.StorageScanner.GetFinding().GetResourceName()),
+	}
+}
@@ -0,0 +1,214 @@
+//go:build !sra_action
+// +build !sra_action
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloudevent"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/filter"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/router/policy"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/webhook"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// routingHandlers are the entry points routing findings the Webhook function dispatches, left out
+// of builds with the sra_action tag along with the router, its policies and the Filter.
+var routingHandlers = map[string]webhook.Handler{
+	"Router": Router,
+}
+
+// routingEventHandlers are the entry points routing findings CloudEvent can run, along with those
+// forwarding findings, fanning out work items, handling dead letters and reporting.
+var routingEventHandlers = map[string]cloudevent.Handler{
+	"Filter":                  Filter,
+	"DeadLetter":              DeadLetter,
+	"Report":                  Report,
+	"ContainerAnalysisBridge": ContainerAnalysisBridge,
+	"FanOut":                  FanOut,
+}
+
+// routingServices are the services the Router and Filter need besides those of the actions.
+var routingServices = []services.Service{services.WithResource, services.WithSecurityCommandCenter}
+
+// preflightOnce runs the preflight check on the first finding routed, if PREFLIGHT is "true".
+var preflightOnce sync.Once
+
+// Filter is the entry point for the Filter Cloud function.
+// This function will receive all findings and filter them against
+// any user-defined Rego policies before forwarding along to the
+// Router function.
+func Filter(ctx context.Context, m pubsub.Message) error {
+	ps, err := pubSub(ctx)
+	if err != nil {
+		return err
+	}
+	return filter.Execute(ctx, m, &filter.Services{
+		PubSub:                ps,
+		Logger:                svcs.Logger,
+		SecurityCommandCenter: svcs.SecurityCommandCenter,
+	})
+}
+
+// Router is the entry point for the router Cloud Function.
+//
+// This Cloud Function will receive all findings and route them to configured automation.
+func Router(ctx context.Context, m pubsub.Message) error {
+	ps, err := pubSub(ctx)
+	if err != nil {
+		return err
+	}
+	conf, err := config()
+	if err != nil {
+		return err
+	}
+	if os.Getenv("PREFLIGHT") == "true" {
+		preflightOnce.Do(func() { preflight(ctx, conf) })
+	}
+	engine, err := policyEngine(ctx)
+	if err != nil {
+		return err
+	}
+	return router.Execute(ctx, &router.Values{
+		Finding:       m.Data,
+		CorrelationID: m.Attributes[services.CorrelationAttribute],
+		Simulated:     m.Attributes[services.SimulatedAttribute] == "true",
+	}, &router.Services{
+		PubSub:                ps,
+		Configuration:         conf,
+		Logger:                svcs.Logger,
+		Resource:              svcs.Resource,
+		SecurityCommandCenter: svcs.SecurityCommandCenter,
+		Policy:                engine,
+		Tasks:                 tasks,
+	})
+}
+
+// preflight logs what the service account lacks to run the enabled automations, once per instance.
+func preflight(ctx context.Context, conf *router.Configuration) {
+	p, err := services.InitPreflight(ctx)
+	if err != nil {
+		svcs.Logger.Warning("preflight skipped: %q", err)
+		return
+	}
+	gaps, err := router.Preflight(ctx, conf, projectID, p)
+	if err != nil {
+		svcs.Logger.Warning("preflight failed: %q", err)
+		return
+	}
+	for _, g := range gaps {
+		svcs.Logger.Warning("preflight: %s", g)
+	}
+}
+
+// loadPolicies compiles the policies bundled in the deployment and those in the bucket named by
+// the POLICY_BUCKET environment variable. Returns nil if there are no policies.
+func loadPolicies(ctx context.Context) (*policy.Engine, error) {
+	modules := make(map[string][]byte)
+	for name, b := range policy.FileStore {
+		modules[name] = b
+	}
+	if bucket := os.Getenv("POLICY_BUCKET"); bucket != "" {
+		p, err := policyStore(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := p.Modules(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		for name, b := range stored {
+			modules["gs://"+bucket+"/"+name] = b
+		}
+	}
+	if len(modules) == 0 {
+		return nil, nil
+	}
+	return policy.New(modules)
+}
+
+var (
+	// configMemo holds the router's configuration, so bursts of findings do not read and parse it
+	// for each message.
+	configMemo memo
+	// policyMemo holds the compiled policies, so bursts of findings do not read the policy bucket
+	// and compile them for each message.
+	policyMemo memo
+)
+
+// config returns the router's configuration, read again once it was cached for CONFIG_CACHE_TTL.
+func config() (*router.Configuration, error) {
+	v, err := configMemo.get(configTTL(), func() (interface{}, error) {
+		return router.Config()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*router.Configuration), nil
+}
+
+// policyEngine returns the policies compiled by loadPolicies, compiled again once they were cached
+// for CONFIG_CACHE_TTL. Returns nil if there are no policies.
+func policyEngine(ctx context.Context) (*policy.Engine, error) {
+	v, err := policyMemo.get(configTTL(), func() (interface{}, error) {
+		return loadPolicies(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	e, _ := v.(*policy.Engine)
+	return e, nil
+}
+
+// probes serves the health, readiness and version endpoints of the Webhook function.
+var probes = &webhook.Probes{Ready: ready, Version: version}
+
+// ready returns an error if the configuration, policies or Pub/Sub client the Router needs fail to
+// load, the services having been initialized on start.
+func ready(ctx context.Context) error {
+	if _, err := config(); err != nil {
+		return errors.Wrap(err, "failed to load configuration")
+	}
+	if _, err := policyEngine(ctx); err != nil {
+		return errors.Wrap(err, "failed to load policies")
+	}
+	if _, err := pubSub(ctx); err != nil {
+		return errors.Wrap(err, "failed to initialize pubsub")
+	}
+	return nil
+}
+
+// version returns the version of the build and the actions its configuration enables.
+func version(ctx context.Context) (*webhook.Version, error) {
+	conf, err := config()
+	if err != nil {
+		return nil, err
+	}
+	return &webhook.Version{
+		Version:   clients.Version,
+		Commit:    clients.Commit,
+		BuildTime: clients.BuildTime,
+		Actions:   conf.EnabledActions(),
+	}, nil
+}
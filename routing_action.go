@@ -0,0 +1,65 @@
+//go:build sra_action
+// +build sra_action
+
+package exec
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/googlecloudplatform/security-response-automation/clients"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/cloudevent"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/registry"
+	"github.com/googlecloudplatform/security-response-automation/cloudfunctions/webhook"
+	"github.com/googlecloudplatform/security-response-automation/services"
+	"github.com/pkg/errors"
+)
+
+// Builds with the sra_action tag only deploy actions: the Router and Filter, the router's
+// configuration and the policy engine are left out so action functions are smaller and start
+// faster, as are the actions whose own tags, such as sra_close_bucket, are not set.
+
+var (
+	routingHandlers      = map[string]webhook.Handler{}
+	routingEventHandlers = map[string]cloudevent.Handler{}
+	routingServices      []services.Service
+)
+
+// probes serves the health, readiness and version endpoints of the Webhook function.
+var probes = &webhook.Probes{Ready: ready, Version: version}
+
+// ready returns an error if the Pub/Sub client fails to initialize, the services having been
+// initialized on start.
+func ready(ctx context.Context) error {
+	if _, err := pubSub(ctx); err != nil {
+		return errors.Wrap(err, "failed to initialize pubsub")
+	}
+	return nil
+}
+
+// version returns the version of the build and the actions it can run.
+func version(ctx context.Context) (*webhook.Version, error) {
+	var actions []string
+	for _, a := range registry.Actions() {
+		actions = append(actions, a.Name)
+	}
+	return &webhook.Version{
+		Version:   clients.Version,
+		Commit:    clients.Commit,
+		BuildTime: clients.BuildTime,
+		Actions:   actions,
+	}, nil
+}
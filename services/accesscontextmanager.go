@@ -0,0 +1,120 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	acm "google.golang.org/api/accesscontextmanager/v1"
+)
+
+// AccessContextManagerClient holds the minimum interface required by the AccessContextManager service.
+type AccessContextManagerClient interface {
+	GetServicePerimeter(context.Context, string) (*acm.ServicePerimeter, error)
+	PatchServicePerimeter(context.Context, string, *acm.ServicePerimeter, string) (*acm.Operation, error)
+	GetAccessLevel(context.Context, string) (*acm.AccessLevel, error)
+	PatchAccessLevel(context.Context, string, *acm.AccessLevel, string) (*acm.Operation, error)
+}
+
+// AccessContextManager service managing VPC Service Controls.
+type AccessContextManager struct {
+	client AccessContextManagerClient
+}
+
+// NewAccessContextManager returns an AccessContextManager service.
+func NewAccessContextManager(client AccessContextManagerClient) *AccessContextManager {
+	return &AccessContextManager{client: client}
+}
+
+// AddToPerimeter adds the project, by number, to the resources protected by the enforced
+// configuration of the perimeter and reports whether it was added. A project belongs to at most
+// one regular perimeter, adding a project protected by another perimeter fails.
+func (a *AccessContextManager) AddToPerimeter(ctx context.Context, perimeter, projectNumber string) (bool, error) {
+	p, err := a.client.GetServicePerimeter(ctx, perimeter)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get perimeter %q", perimeter)
+	}
+	resource := "projects/" + projectNumber
+	var resources []string
+	if p.Status != nil {
+		resources = p.Status.Resources
+	}
+	for _, r := range resources {
+		if r == resource {
+			return false, nil
+		}
+	}
+	patch := &acm.ServicePerimeter{Status: &acm.ServicePerimeterConfig{Resources: append(resources, resource)}}
+	if _, err := a.client.PatchServicePerimeter(ctx, perimeter, patch, "status.resources"); err != nil {
+		return false, errors.Wrapf(err, "failed to add %q to perimeter %q", resource, perimeter)
+	}
+	return true, nil
+}
+
+// ExcludeFromAccessLevel excludes the members, such as "user:tim@example.com", and IP ranges in
+// CIDR notation from the basic access level and returns those newly excluded. Each is excluded by
+// a negated condition, which only restricts access levels whose conditions must all be met: access
+// levels combining their conditions with OR are not changed.
+func (a *AccessContextManager) ExcludeFromAccessLevel(ctx context.Context, level string, members, ipSubnetworks []string) ([]string, error) {
+	l, err := a.client.GetAccessLevel(ctx, level)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get access level %q", level)
+	}
+	if l.Basic == nil {
+		return nil, fmt.Errorf("access level %q is not a basic access level", level)
+	}
+	if f := l.Basic.CombiningFunction; f != "" && f != "AND" {
+		return nil, fmt.Errorf("access level %q combines its conditions with %s", level, f)
+	}
+	var excluded []string
+	for _, m := range members {
+		if !excludes(l.Basic.Conditions, func(c *acm.Condition) []string { return c.Members }, m) {
+			l.Basic.Conditions = append(l.Basic.Conditions, &acm.Condition{Members: []string{m}, Negate: true})
+			excluded = append(excluded, m)
+		}
+	}
+	for _, ip := range ipSubnetworks {
+		if !excludes(l.Basic.Conditions, func(c *acm.Condition) []string { return c.IpSubnetworks }, ip) {
+			l.Basic.Conditions = append(l.Basic.Conditions, &acm.Condition{IpSubnetworks: []string{ip}, Negate: true})
+			excluded = append(excluded, ip)
+		}
+	}
+	if len(excluded) == 0 {
+		return nil, nil
+	}
+	patch := &acm.AccessLevel{Basic: l.Basic}
+	if _, err := a.client.PatchAccessLevel(ctx, level, patch, "basic"); err != nil {
+		return nil, errors.Wrapf(err, "failed to exclude %q from access level %q", excluded, level)
+	}
+	return excluded, nil
+}
+
+// excludes reports whether a negated condition excludes the value, a member or IP range.
+func excludes(conditions []*acm.Condition, field func(*acm.Condition) []string, value string) bool {
+	for _, c := range conditions {
+		if !c.Negate {
+			continue
+		}
+		for _, v := range field(c) {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	acm "google.golang.org/api/accesscontextmanager/v1"
+)
+
+func TestAddToPerimeter(t *testing.T) {
+	const perimeter = "accessPolicies/123/servicePerimeters/restricted"
+	ctx := context.Background()
+	stub := &stubs.AccessContextManagerStub{Perimeters: map[string]*acm.ServicePerimeter{
+		perimeter: {Name: perimeter, Status: &acm.ServicePerimeterConfig{Resources: []string{"projects/111"}}},
+	}}
+	a := NewAccessContextManager(stub)
+	if added, err := a.AddToPerimeter(ctx, perimeter, "222"); err != nil || !added {
+		t.Fatalf("got added %t, error %q", added, err)
+	}
+	if diff := cmp.Diff([]string{"projects/111", "projects/222"}, stub.Perimeters[perimeter].Status.Resources); diff != "" {
+		t.Errorf("perimeter resources (-want +got):\n%s", diff)
+	}
+	if added, err := a.AddToPerimeter(ctx, perimeter, "222"); err != nil || added {
+		t.Errorf("got added %t, error %q adding again", added, err)
+	}
+	if diff := cmp.Diff([]string{"status.resources"}, stub.SavedMasks); diff != "" {
+		t.Errorf("update masks (-want +got):\n%s", diff)
+	}
+}
+
+func TestExcludeFromAccessLevel(t *testing.T) {
+	const level = "accessPolicies/123/accessLevels/corp"
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name              string
+		combiningFunction string
+		members, ips      []string
+		want              []string
+		wantConditions    int
+		expectError       bool
+	}{
+		{name: "member and ip", members: []string{"user:tim@example.com"}, ips: []string{"203.0.113.0/24"}, want: []string{"user:tim@example.com", "203.0.113.0/24"}, wantConditions: 4},
+		{name: "already excluded", members: []string{"user:eve@example.com"}, wantConditions: 2},
+		{name: "or", combiningFunction: "OR", members: []string{"user:tim@example.com"}, wantConditions: 2, expectError: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.AccessContextManagerStub{AccessLevels: map[string]*acm.AccessLevel{
+				level: {Name: level, Basic: &acm.BasicLevel{
+					CombiningFunction: tt.combiningFunction,
+					Conditions: []*acm.Condition{
+						{IpSubnetworks: []string{"198.51.100.0/24"}},
+						{Members: []string{"user:eve@example.com"}, Negate: true},
+					},
+				}},
+			}}
+			got, err := NewAccessContextManager(stub).ExcludeFromAccessLevel(ctx, level, tt.members, tt.ips)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s got error %v, want error %t", tt.name, err, tt.expectError)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s excluded (-want +got):\n%s", tt.name, diff)
+			}
+			if n := len(stub.AccessLevels[level].Basic.Conditions); n != tt.wantConditions {
+				t.Errorf("%s got %d conditions, want %d", tt.name, n, tt.wantConditions)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// ancestryCache holds the ancestry of projects, folder scoped actions resolving the same ancestry
+// for each finding of a burst in one project.
+type ancestryCache struct {
+	ttl   time.Duration
+	store *Checkpoints
+	mu    sync.Mutex
+	cache map[string]cachedAncestry
+}
+
+type cachedAncestry struct {
+	Ancestry *crm.GetAncestryResponse
+	Fetched  time.Time
+}
+
+// CacheAncestry caches the ancestry of projects for ttl in memory and, if store is not nil, in its
+// Firestore collection so the instances of a function share it.
+//
+// Moving a project takes up to ttl to be seen by the filters and routing relying on its ancestry.
+func (r *Resource) CacheAncestry(ttl time.Duration, store *Checkpoints) {
+	r.ancestryCache = &ancestryCache{ttl: ttl, store: store, cache: make(map[string]cachedAncestry)}
+}
+
+// ancestry returns the ancestry of the project, cached if CacheAncestry was called.
+func (r *Resource) ancestry(ctx context.Context, projectID string) (*crm.GetAncestryResponse, error) {
+	c := r.ancestryCache
+	if c == nil {
+		return r.crm.GetAncestry(ctx, projectID)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if a, ok := c.cache[projectID]; ok && time.Since(a.Fetched) < c.ttl {
+		return a.Ancestry, nil
+	}
+	if c.store != nil {
+		var a cachedAncestry
+		found, err := c.store.Load(ctx, projectID, &a)
+		if err != nil {
+			log.Printf("failed to load cached ancestry of %q: %q", projectID, err)
+		}
+		if found && time.Since(a.Fetched) < c.ttl {
+			c.cache[projectID] = a
+			return a.Ancestry, nil
+		}
+	}
+	resp, err := r.crm.GetAncestry(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	a := cachedAncestry{Ancestry: resp, Fetched: time.Now()}
+	c.cache[projectID] = a
+	if c.store != nil {
+		if err := c.store.Save(ctx, projectID, a); err != nil {
+			log.Printf("failed to cache ancestry of %q: %q", projectID, err)
+		}
+	}
+	return resp, nil
+}
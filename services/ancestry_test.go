@@ -0,0 +1,120 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestCacheAncestry(t *testing.T) {
+	ancestors := CreateAncestors([]string{"project/projectID", "folder/team", "organization/organizationID"})
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		ttl           time.Duration
+		shared        bool
+		expectedCalls int
+	}{
+		{name: "not cached", ttl: -1, expectedCalls: 4},
+		{name: "cached in memory", ttl: time.Minute, expectedCalls: 2},
+		{name: "shared through firestore", ttl: time.Minute, shared: true, expectedCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crmStub := &stubs.ResourceManagerStub{GetAncestryResponse: ancestors}
+			var store *Checkpoints
+			if tt.shared {
+				store = NewCheckpoints(&stubs.FirestoreStub{}, "automation-project", "ancestry")
+			}
+			// Two instances of a function each looking up the ancestry twice.
+			for i := 0; i < 2; i++ {
+				r := NewResource(crmStub, &stubs.StorageStub{})
+				if tt.ttl >= 0 {
+					r.CacheAncestry(tt.ttl, store)
+				}
+				for j := 0; j < 2; j++ {
+					if _, err := r.Folders(ctx, "projectID"); err != nil {
+						t.Fatalf("%s Folders failed: %q", tt.name, err)
+					}
+				}
+			}
+			if crmStub.AncestryCalls != tt.expectedCalls {
+				t.Errorf("%s got %d ancestry lookups, want %d", tt.name, crmStub.AncestryCalls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+func TestOrganizationCached(t *testing.T) {
+	ctx := context.Background()
+	crmStub := &stubs.ResourceManagerStub{GetOrganizationResponse: &crm.Organization{Name: "organizations/456", DisplayName: "example.com"}}
+	// Two services of the same instance, sharing the package's cache.
+	for i := 0; i < 2; i++ {
+		r := NewResource(crmStub, &stubs.StorageStub{})
+		o, err := r.Organization(ctx, "456")
+		if err != nil {
+			t.Fatalf("Organization failed: %q", err)
+		}
+		if o.DisplayName != "example.com" {
+			t.Errorf("got organization %q, want example.com", o.DisplayName)
+		}
+	}
+	if crmStub.OrganizationCalls != 1 {
+		t.Errorf("got %d organization lookups, want 1", crmStub.OrganizationCalls)
+	}
+}
+
+// hungOrganizations blocks the lookups of organization 1 until release is closed, signaling
+// started when one begins.
+type hungOrganizations struct {
+	*stubs.ResourceManagerStub
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *hungOrganizations) GetOrganization(ctx context.Context, name string) (*crm.Organization, error) {
+	if name == "organizations/1" {
+		close(h.started)
+		<-h.release
+	}
+	return &crm.Organization{Name: name}, nil
+}
+
+func TestOrganizationNotBlockedByOthers(t *testing.T) {
+	ctx := context.Background()
+	h := &hungOrganizations{ResourceManagerStub: &stubs.ResourceManagerStub{}, started: make(chan struct{}), release: make(chan struct{})}
+	defer close(h.release)
+	r := NewResource(h, &stubs.StorageStub{})
+	go r.Organization(ctx, "1")
+	<-h.started
+	done := make(chan error)
+	go func() {
+		_, err := r.Organization(ctx, "2")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Organization failed: %q", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lookup of organization 2 waited for the hung lookup of organization 1")
+	}
+}
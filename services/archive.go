@@ -0,0 +1,46 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveClient holds the minimum interface required by the archive service.
+type ArchiveClient interface {
+	WriteObject(context.Context, string, string, []byte) error
+}
+
+// Archive service stores payloads in a Cloud Storage bucket.
+type Archive struct {
+	client ArchiveClient
+	bucket string
+}
+
+// NewArchive returns a new archive service storing payloads in the bucket.
+func NewArchive(client ArchiveClient, bucket string) *Archive {
+	return &Archive{client: client, bucket: bucket}
+}
+
+// Store writes the payload to the named object and returns its URI.
+func (a *Archive) Store(ctx context.Context, name string, b []byte) (string, error) {
+	if err := a.client.WriteObject(ctx, a.bucket, name, b); err != nil {
+		return "", errors.Wrapf(err, "failed to archive %q", name)
+	}
+	return fmt.Sprintf("gs://%s/%s", a.bucket, name), nil
+}
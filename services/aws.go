@@ -0,0 +1,87 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// isolationGroup is the name of the security group without rules used to isolate instances.
+	isolationGroup = "sra-isolation"
+)
+
+// AWSClient holds the minimum interface required by the AWS service.
+type AWSClient interface {
+	UpdateAccessKey(context.Context, string, string, string) error
+	SecurityGroupID(context.Context, string, string) (string, error)
+	CreateSecurityGroup(context.Context, string, string, string) (string, error)
+	RevokeSecurityGroupEgress(context.Context, string, string) error
+	SetInstanceSecurityGroups(context.Context, string, []string) error
+}
+
+// AWS service.
+type AWS struct {
+	client AWSClient
+}
+
+// NewAWS returns a new AWS service.
+func NewAWS(client AWSClient) *AWS {
+	return &AWS{client: client}
+}
+
+// DisableAccessKey marks the IAM user's access key as inactive.
+func (a *AWS) DisableAccessKey(ctx context.Context, userName, accessKeyID string) error {
+	if err := a.client.UpdateAccessKey(ctx, userName, accessKeyID, "Inactive"); err != nil {
+		return errors.Wrapf(err, "failed to disable access key %q of %q", accessKeyID, userName)
+	}
+	return nil
+}
+
+// IsolateInstance replaces the security groups of the instance with one allowing no traffic.
+//
+// Connections already tracked by the instance may persist until they are closed.
+func (a *AWS) IsolateInstance(ctx context.Context, vpcID, instanceID string) error {
+	groupID, err := a.isolationGroup(ctx, vpcID)
+	if err != nil {
+		return err
+	}
+	if err := a.client.SetInstanceSecurityGroups(ctx, instanceID, []string{groupID}); err != nil {
+		return errors.Wrapf(err, "failed to set security groups of %q", instanceID)
+	}
+	return nil
+}
+
+// isolationGroup returns the ID of the isolation security group of the VPC, creating it if needed.
+func (a *AWS) isolationGroup(ctx context.Context, vpcID string) (string, error) {
+	id, err := a.client.SecurityGroupID(ctx, vpcID, isolationGroup)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find security group %q", isolationGroup)
+	}
+	if id != "" {
+		return id, nil
+	}
+	id, err = a.client.CreateSecurityGroup(ctx, vpcID, isolationGroup, "Isolates instances by Security Response Automation")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create security group %q", isolationGroup)
+	}
+	// New security groups allow all egress traffic by default.
+	if err := a.client.RevokeSecurityGroupEgress(ctx, id, "0.0.0.0/0"); err != nil {
+		return "", errors.Wrapf(err, "failed to revoke egress of %q", id)
+	}
+	return id, nil
+}
@@ -0,0 +1,232 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// maxConcurrentPolicies bounds the projects whose policies UpdateProjectPolicies updates at once.
+const maxConcurrentPolicies = 8
+
+// PolicyTransform changes an IAM policy in place and reports whether it changed anything.
+type PolicyTransform func(*crm.Policy) bool
+
+// RemoveMember returns a transform removing the member from every binding of a policy, dropping
+// the bindings left without members.
+func RemoveMember(member string) PolicyTransform {
+	return func(policy *crm.Policy) bool {
+		changed := false
+		bindings := []*crm.Binding{}
+		for _, b := range policy.Bindings {
+			members := []string{}
+			for _, m := range b.Members {
+				if strings.EqualFold(m, member) {
+					changed = true
+					continue
+				}
+				members = append(members, m)
+			}
+			b.Members = members
+			if len(members) > 0 {
+				bindings = append(bindings, b)
+			}
+		}
+		policy.Bindings = bindings
+		return changed
+	}
+}
+
+// ReplaceRole returns a transform removing the member from the binding of the role and adding it
+// to the bindings of the replacement roles, creating the bindings missing from the policy.
+func ReplaceRole(member, role string, replacements []string) PolicyTransform {
+	return func(policy *crm.Policy) bool {
+		changed := false
+		bindings := []*crm.Binding{}
+		for _, b := range policy.Bindings {
+			if b.Role == role && b.Condition == nil {
+				members := []string{}
+				for _, m := range b.Members {
+					if strings.EqualFold(m, member) {
+						changed = true
+						continue
+					}
+					members = append(members, m)
+				}
+				b.Members = members
+				if len(members) == 0 {
+					continue
+				}
+			}
+			bindings = append(bindings, b)
+		}
+		policy.Bindings = bindings
+		for _, replacement := range replacements {
+			if addMember(policy, replacement, member) {
+				changed = true
+			}
+		}
+		return changed
+	}
+}
+
+// addMember adds the member to the unconditional binding of the role, reporting whether it was
+// missing.
+func addMember(policy *crm.Policy, role, member string) bool {
+	for _, b := range policy.Bindings {
+		if b.Role != role || b.Condition != nil {
+			continue
+		}
+		for _, m := range b.Members {
+			if strings.EqualFold(m, member) {
+				return false
+			}
+		}
+		b.Members = append(b.Members, member)
+		return true
+	}
+	policy.Bindings = append(policy.Bindings, &crm.Binding{Role: role, Members: []string{member}})
+	return true
+}
+
+// expiringGrantTitle is the title of the conditions of the grants ExpireMembersProject replaces.
+const expiringGrantTitle = "sra-expiring-grant"
+
+// ExpireMembers returns a transform replacing the unconditional grants of the members by grants
+// conditioned to expire at the given time, recording the roles replaced by member in expired.
+func ExpireMembers(members []string, expiry time.Time, expired map[string][]string) PolicyTransform {
+	condition := &crm.Expr{
+		Title:       expiringGrantTitle,
+		Description: "Temporary grant set by Security Response Automation in place of a revoked grant.",
+		Expression:  fmt.Sprintf("request.time < timestamp(%q)", expiry.UTC().Format(time.RFC3339)),
+	}
+	return func(policy *crm.Policy) bool {
+		var added []*crm.Binding
+		for _, b := range policy.Bindings {
+			if b.Condition != nil {
+				continue
+			}
+			keep := []string{}
+			for _, m := range b.Members {
+				if !containsFold(members, m) {
+					keep = append(keep, m)
+					continue
+				}
+				expired[m] = append(expired[m], b.Role)
+				added = append(added, &crm.Binding{Role: b.Role, Members: []string{m}, Condition: condition})
+			}
+			b.Members = keep
+		}
+		if len(added) == 0 {
+			return false
+		}
+		bindings := []*crm.Binding{}
+		for _, b := range policy.Bindings {
+			if len(b.Members) > 0 {
+				bindings = append(bindings, b)
+			}
+		}
+		policy.Bindings = append(bindings, added...)
+		// Conditional bindings require version 3 policies.
+		policy.Version = 3
+		return true
+	}
+}
+
+// containsFold reports whether the values contain s, compared regardless of case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateProjectPolicies applies the transform to the policies of the projects concurrently and
+// returns the projects whose policy changed. Projects failing do not stop the others, they are
+// reported together in a *PartialError.
+func (r *Resource) UpdateProjectPolicies(ctx context.Context, projectIDs []string, transform PolicyTransform) ([]string, error) {
+	var (
+		mu      sync.Mutex
+		changed []string
+	)
+	err := ForEach(ctx, projectIDs, maxConcurrentPolicies, func(ctx context.Context, projectID string) error {
+		updated, err := r.updateProjectPolicy(ctx, projectID, transform)
+		if err != nil || !updated {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		changed = append(changed, projectID)
+		return nil
+	})
+	sort.Strings(changed)
+	return changed, err
+}
+
+// RemoveMemberProjects removes the member from the policies of the projects, returning the
+// projects it was removed from.
+func (r *Resource) RemoveMemberProjects(ctx context.Context, projectIDs []string, member string) ([]string, error) {
+	return r.UpdateProjectPolicies(ctx, projectIDs, RemoveMember(member))
+}
+
+// ReplaceRoleProject replaces the role of the member in the policy of the project by the
+// replacement roles, reporting whether the policy changed.
+func (r *Resource) ReplaceRoleProject(ctx context.Context, projectID, member, role string, replacements []string) (bool, error) {
+	return r.updateProjectPolicy(ctx, projectID, ReplaceRole(member, role, replacements))
+}
+
+// ExpireMembersProject replaces the unconditional grants of the members on the project by grants
+// expiring at the given time, returning the roles replaced by member.
+func (r *Resource) ExpireMembersProject(ctx context.Context, projectID string, members []string, expiry time.Time) (map[string][]string, error) {
+	var expired map[string][]string
+	_, err := r.updateProjectPolicy(ctx, projectID, func(policy *crm.Policy) bool {
+		// The policy is read again when its update conflicts, start over.
+		expired = make(map[string][]string)
+		return ExpireMembers(members, expiry, expired)(policy)
+	})
+	return expired, err
+}
+
+// updateProjectPolicy applies the transform to the policy of the project, writing it only if it
+// changed.
+func (r *Resource) updateProjectPolicy(ctx context.Context, projectID string, transform PolicyTransform) (bool, error) {
+	changed := false
+	err := updatePolicy(func() error {
+		policy, err := r.crm.GetPolicyProject(ctx, projectID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get project policy")
+		}
+		before := policyGrants(policy)
+		if changed = transform(policy); !changed {
+			return nil
+		}
+		if _, err = r.crm.SetPolicyProject(ctx, projectID, policy); err != nil {
+			return errors.Wrap(err, "failed to set project policy")
+		}
+		logChange(ctx, policyChange(projectPolicyKind, "projects/"+projectID, before, policyGrants(policy)))
+		return nil
+	})
+	return changed, err
+}
@@ -0,0 +1,118 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestRemoveMemberProjects(t *testing.T) {
+	const member = "user:bad@example.com"
+	crmStub := &stubs.ResourceManagerStub{ProjectPolicies: map[string]*crm.Policy{
+		"project-a": {Bindings: []*crm.Binding{
+			{Role: "roles/owner", Members: []string{member}},
+			{Role: "roles/viewer", Members: []string{"user:good@example.com", member}},
+		}},
+		"project-b": {Bindings: []*crm.Binding{
+			{Role: "roles/viewer", Members: []string{"user:good@example.com"}},
+		}},
+		"project-c": {Bindings: []*crm.Binding{
+			{Role: "roles/editor", Members: []string{"User:Bad@example.com"}},
+		}},
+	}}
+	r := NewResource(crmStub, &stubs.StorageStub{})
+
+	changed, err := r.RemoveMemberProjects(context.Background(), []string{"project-a", "project-b", "project-c", "missing"}, member)
+	partial, ok := err.(*PartialError)
+	if !ok {
+		t.Fatalf("got %q, want a partial failure", err)
+	}
+	if diff := cmp.Diff([]string{"project-a", "project-c"}, changed); diff != "" {
+		t.Errorf("changed difference: %+v", diff)
+	}
+	if diff := cmp.Diff([]string{"project-a", "project-b", "project-c"}, partial.Completed); diff != "" {
+		t.Errorf("completed difference: %+v", diff)
+	}
+	if _, ok := partial.Failed["missing"]; !ok || len(partial.Failed) != 1 {
+		t.Errorf("failed got %+v, want missing", partial.Failed)
+	}
+	want := []*crm.Binding{{Role: "roles/viewer", Members: []string{"user:good@example.com"}}}
+	if diff := cmp.Diff(want, crmStub.ProjectPolicies["project-a"].Bindings); diff != "" {
+		t.Errorf("project-a bindings difference: %+v", diff)
+	}
+	if n := len(crmStub.ProjectPolicies["project-c"].Bindings); n != 0 {
+		t.Errorf("project-c has %d bindings left, want 0", n)
+	}
+}
+
+func TestReplaceRole(t *testing.T) {
+	const member = "user:dev@example.com"
+	policy := &crm.Policy{Bindings: []*crm.Binding{
+		{Role: "roles/editor", Members: []string{member, "user:ops@example.com"}},
+		{Role: "roles/storage.objectViewer", Members: []string{"user:ops@example.com"}},
+	}}
+	if !ReplaceRole(member, "roles/editor", []string{"roles/storage.objectViewer", "roles/pubsub.publisher"})(policy) {
+		t.Fatal("policy did not change")
+	}
+	want := []*crm.Binding{
+		{Role: "roles/editor", Members: []string{"user:ops@example.com"}},
+		{Role: "roles/storage.objectViewer", Members: []string{"user:ops@example.com", member}},
+		{Role: "roles/pubsub.publisher", Members: []string{member}},
+	}
+	if diff := cmp.Diff(want, policy.Bindings); diff != "" {
+		t.Errorf("bindings (-want +got):\n%s", diff)
+	}
+	if ReplaceRole(member, "roles/editor", []string{"roles/storage.objectViewer", "roles/pubsub.publisher"})(policy) {
+		t.Error("policy changed replacing again")
+	}
+}
+
+func TestExpireMembers(t *testing.T) {
+	const member = "user:tom@gmail.com"
+	expiry := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	policy := &crm.Policy{Bindings: []*crm.Binding{
+		{Role: "roles/editor", Members: []string{"user:test@test.com", member}},
+		{Role: "roles/owner", Members: []string{"user:Tom@gmail.com"}},
+	}}
+	expired := make(map[string][]string)
+	if !ExpireMembers([]string{member}, expiry, expired)(policy) {
+		t.Fatal("policy did not change")
+	}
+	condition := &crm.Expr{
+		Title:       expiringGrantTitle,
+		Description: "Temporary grant set by Security Response Automation in place of a revoked grant.",
+		Expression:  `request.time < timestamp("2020-01-02T15:04:05Z")`,
+	}
+	want := &crm.Policy{Version: 3, Bindings: []*crm.Binding{
+		{Role: "roles/editor", Members: []string{"user:test@test.com"}},
+		{Role: "roles/editor", Members: []string{member}, Condition: condition},
+		{Role: "roles/owner", Members: []string{"user:Tom@gmail.com"}, Condition: condition},
+	}}
+	if diff := cmp.Diff(want, policy); diff != "" {
+		t.Errorf("policy (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string][]string{member: {"roles/editor"}, "user:Tom@gmail.com": {"roles/owner"}}, expired); diff != "" {
+		t.Errorf("expired roles (-want +got):\n%s", diff)
+	}
+	if ExpireMembers([]string{member}, expiry, make(map[string][]string))(policy) {
+		t.Error("policy changed expiring again")
+	}
+}
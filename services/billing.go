@@ -0,0 +1,69 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+)
+
+// CloudBillingClient holds the minimum interface required by the Billing service.
+type CloudBillingClient interface {
+	GetBillingInfo(context.Context, string) (*cloudbilling.ProjectBillingInfo, error)
+	UpdateBillingInfo(context.Context, string, *cloudbilling.ProjectBillingInfo) (*cloudbilling.ProjectBillingInfo, error)
+}
+
+// Billing service manages the billing of projects.
+type Billing struct {
+	client CloudBillingClient
+}
+
+// NewBilling returns a new Billing service.
+func NewBilling(client CloudBillingClient) *Billing {
+	return &Billing{client: client}
+}
+
+// BillingAccount returns the name of the billing account of the project, such as
+// "billingAccounts/012345-567890-ABCDEF", or an empty string if its billing is disabled.
+func (b *Billing) BillingAccount(ctx context.Context, projectID string) (string, error) {
+	info, err := b.client.GetBillingInfo(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	if !info.BillingEnabled {
+		return "", nil
+	}
+	return info.BillingAccountName, nil
+}
+
+// DetachBilling disables the billing of the project by removing its billing account, returning the
+// account removed or an empty string if its billing was already disabled. The account removed is
+// logged as a change, so it can be attached again.
+func (b *Billing) DetachBilling(ctx context.Context, projectID string) (string, error) {
+	account, err := b.BillingAccount(ctx, projectID)
+	if err != nil || account == "" {
+		return "", err
+	}
+	if _, err := b.client.UpdateBillingInfo(ctx, projectID, &cloudbilling.ProjectBillingInfo{}); err != nil {
+		return "", err
+	}
+	logChange(ctx, &Change{
+		Kind:     billingKind,
+		Resource: "projects/" + projectID + "/billingInfo",
+		Fields:   map[string]FieldChange{"billingAccountName": {Before: account, After: ""}},
+	})
+	return account, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+)
+
+func TestDetachBilling(t *testing.T) {
+	const account = "billingAccounts/012345-567890-ABCDEF"
+	for _, tt := range []struct {
+		name     string
+		info     *cloudbilling.ProjectBillingInfo
+		detached string
+	}{
+		{name: "billing enabled", info: &cloudbilling.ProjectBillingInfo{BillingAccountName: account, BillingEnabled: true}, detached: account},
+		{name: "billing disabled", info: &cloudbilling.ProjectBillingInfo{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cbStub := &stubs.CloudBillingStub{StubbedBillingInfo: map[string]*cloudbilling.ProjectBillingInfo{"test-project": tt.info}}
+			got, err := NewBilling(cbStub).DetachBilling(context.Background(), "test-project")
+			if err != nil {
+				t.Fatalf("%q DetachBilling failed: %q", tt.name, err)
+			}
+			if got != tt.detached {
+				t.Errorf("%q DetachBilling detached %q want %q", tt.name, got, tt.detached)
+			}
+			updated, ok := cbStub.UpdatedBillingInfo["test-project"]
+			if ok != (tt.detached != "") {
+				t.Fatalf("%q DetachBilling updated:%t", tt.name, ok)
+			}
+			if ok && updated.BillingAccountName != "" {
+				t.Errorf("%q DetachBilling set account %q", tt.name, updated.BillingAccountName)
+			}
+		})
+	}
+}
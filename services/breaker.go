@@ -0,0 +1,201 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	firestore "google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// BreakerClient holds the minimum interface required by the breaker service.
+type BreakerClient interface {
+	GetDocument(context.Context, string) (*firestore.Document, error)
+	PatchDocument(context.Context, string, *firestore.Document) (*firestore.Document, error)
+	PatchDocumentIf(context.Context, string, *firestore.Document, string) (*firestore.Document, error)
+}
+
+// Circuit is the state of an action's circuit breaker.
+type Circuit struct {
+	Action string
+	// Failures is the number of consecutive failures of the action.
+	Failures int
+	// Opened is when the circuit last tripped, zero if it never did.
+	Opened time.Time
+	// Paused stops the action whatever its failures until it is resumed, as a kill switch.
+	Paused bool
+	// Probing is when a run was let through the open circuit, zero if none was since it tripped.
+	Probing time.Time
+
+	updateTime string
+}
+
+// Breaker service trips the circuit of an action after consecutive failures so a broken action
+// stops calling APIs, keeping the state of every circuit in a Firestore collection.
+//
+// An open circuit lets a single run through once the cool-down has passed, marking the circuit as
+// probing with a conditional write so concurrent runs do not all hit the broken API: the circuit
+// closes if the probe succeeds and opens again if it fails. A probe that never records is replaced
+// by another after a further cool-down. Concurrent runs may lose an update to the count, which only
+// delays tripping.
+type Breaker struct {
+	client     BreakerClient
+	parent     string
+	collection string
+	threshold  int
+	cooldown   time.Duration
+	now        func() time.Time
+}
+
+// NewBreaker returns a new breaker service tripping after threshold consecutive failures and
+// letting a run through after the cool-down, a zero threshold never trips.
+func NewBreaker(client BreakerClient, projectID, collection string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		client:     client,
+		parent:     fmt.Sprintf("projects/%s/databases/(default)/documents", projectID),
+		collection: collection,
+		threshold:  threshold,
+		cooldown:   cooldown,
+		now:        time.Now,
+	}
+}
+
+// Circuit returns the circuit of the action.
+func (b *Breaker) Circuit(ctx context.Context, action string) (*Circuit, error) {
+	d, err := b.client.GetDocument(ctx, b.name(action))
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+		return &Circuit{Action: action}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get circuit of %q", action)
+	}
+	opened, _ := time.Parse(time.RFC3339Nano, d.Fields["opened"].TimestampValue)
+	probing, _ := time.Parse(time.RFC3339Nano, d.Fields["probing"].TimestampValue)
+	return &Circuit{
+		Action:     action,
+		Failures:   int(d.Fields["failures"].IntegerValue),
+		Opened:     opened,
+		Paused:     d.Fields["paused"].BooleanValue,
+		Probing:    probing,
+		updateTime: d.UpdateTime,
+	}, nil
+}
+
+// Allow returns whether the action may run, false while it is paused or its circuit is open.
+// Paused actions are stopped even if the breaker never trips. Once the cool-down has passed, only
+// the run winning the probe of the open circuit is allowed.
+func (b *Breaker) Allow(ctx context.Context, action string) (bool, error) {
+	c, err := b.Circuit(ctx, action)
+	if err != nil {
+		return false, err
+	}
+	if c.Paused {
+		return false, nil
+	}
+	if b.threshold == 0 {
+		return true, nil
+	}
+	now := b.now()
+	switch {
+	case c.Failures < b.threshold:
+		return true, nil
+	case now.Sub(c.Opened) < b.cooldown:
+		return false, nil
+	case !c.Probing.IsZero() && now.Sub(c.Probing) < b.cooldown:
+		return false, nil
+	}
+	c.Probing = now
+	_, err = b.client.PatchDocumentIf(ctx, b.name(c.Action), b.document(c), c.updateTime)
+	if contended(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to probe circuit of %q", action)
+	}
+	return true, nil
+}
+
+// Pause stops the action until it is resumed, or resumes it if paused is false.
+func (b *Breaker) Pause(ctx context.Context, action string, paused bool) error {
+	c, err := b.Circuit(ctx, action)
+	if err != nil {
+		return err
+	}
+	c.Paused = paused
+	return b.save(ctx, c)
+}
+
+// Record counts the outcome of a run of the action, returning true if the failure tripped the
+// circuit.
+func (b *Breaker) Record(ctx context.Context, action string, failed bool) (bool, error) {
+	if b.threshold == 0 {
+		return false, nil
+	}
+	c, err := b.Circuit(ctx, action)
+	if err != nil {
+		return false, err
+	}
+	if !failed && c.Failures == 0 {
+		return false, nil
+	}
+	tripped := false
+	c.Probing = time.Time{}
+	switch {
+	case !failed:
+		c.Failures = 0
+	case c.Failures+1 >= b.threshold:
+		c.Failures++
+		c.Opened = b.now()
+		tripped = true
+	default:
+		c.Failures++
+	}
+	if err := b.save(ctx, c); err != nil {
+		return false, err
+	}
+	return tripped, nil
+}
+
+// save replaces the circuit's document.
+func (b *Breaker) save(ctx context.Context, c *Circuit) error {
+	if _, err := b.client.PatchDocument(ctx, b.name(c.Action), b.document(c)); err != nil {
+		return errors.Wrapf(err, "failed to save circuit of %q", c.Action)
+	}
+	return nil
+}
+
+func (b *Breaker) name(action string) string {
+	return b.parent + "/" + b.collection + "/" + action
+}
+
+func (b *Breaker) document(c *Circuit) *firestore.Document {
+	ts := func(t time.Time) firestore.Value {
+		if t.IsZero() {
+			return firestore.Value{NullValue: "NULL_VALUE"}
+		}
+		return firestore.Value{TimestampValue: t.UTC().Format(time.RFC3339Nano)}
+	}
+	return &firestore.Document{Fields: map[string]firestore.Value{
+		"failures": {IntegerValue: int64(c.Failures), ForceSendFields: []string{"IntegerValue"}},
+		"opened":   ts(c.Opened),
+		"paused":   {BooleanValue: c.Paused, ForceSendFields: []string{"BooleanValue"}},
+		"probing":  ts(c.Probing),
+	}}
+}
@@ -0,0 +1,139 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	b := NewBreaker(&stubs.FirestoreStub{}, "automation-project", "circuits", 3, 15*time.Minute)
+	b.now = func() time.Time { return now }
+	steps := []struct {
+		name            string
+		failed          bool
+		after           time.Duration
+		expectedTripped bool
+		expectedAllow   bool
+	}{
+		{name: "first failure", failed: true, expectedAllow: true},
+		{name: "success resets", failed: false, expectedAllow: true},
+		{name: "failure 1", failed: true, expectedAllow: true},
+		{name: "failure 2", failed: true, expectedAllow: true},
+		{name: "failure 3 trips", failed: true, expectedTripped: true, expectedAllow: false},
+		{name: "half open after cool-down fails again", failed: true, after: 20 * time.Minute, expectedTripped: true, expectedAllow: false},
+		{name: "half open after cool-down succeeds", failed: false, after: 20 * time.Minute, expectedAllow: true},
+	}
+	for _, s := range steps {
+		now = now.Add(s.after)
+		tripped, err := b.Record(ctx, "gce_create_disk_snapshot", s.failed)
+		if err != nil {
+			t.Fatalf("%s: Record failed: %q", s.name, err)
+		}
+		if tripped != s.expectedTripped {
+			t.Errorf("%s: tripped got:%v want:%v", s.name, tripped, s.expectedTripped)
+		}
+		allow, err := b.Allow(ctx, "gce_create_disk_snapshot")
+		if err != nil {
+			t.Fatalf("%s: Allow failed: %q", s.name, err)
+		}
+		if allow != s.expectedAllow {
+			t.Errorf("%s: allow got:%v want:%v", s.name, allow, s.expectedAllow)
+		}
+	}
+	if allow, err := b.Allow(ctx, "close_bucket"); err != nil || !allow {
+		t.Errorf("other actions got:%v, %v want allowed", allow, err)
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	b := NewBreaker(&stubs.FirestoreStub{}, "automation-project", "circuits", 1, 15*time.Minute)
+	b.now = func() time.Time { return now }
+	if _, err := b.Record(ctx, "close_bucket", true); err != nil {
+		t.Fatalf("Record failed: %q", err)
+	}
+	now = now.Add(20 * time.Minute)
+	steps := []struct {
+		name          string
+		after         time.Duration
+		expectedAllow bool
+	}{
+		{name: "probe after cool-down", expectedAllow: true},
+		{name: "second run while probing", expectedAllow: false},
+		{name: "probe never recorded", after: 20 * time.Minute, expectedAllow: true},
+		{name: "run while probing again", expectedAllow: false},
+	}
+	for _, s := range steps {
+		now = now.Add(s.after)
+		allow, err := b.Allow(ctx, "close_bucket")
+		if err != nil {
+			t.Fatalf("%s: Allow failed: %q", s.name, err)
+		}
+		if allow != s.expectedAllow {
+			t.Errorf("%s: allow got:%v want:%v", s.name, allow, s.expectedAllow)
+		}
+	}
+	if _, err := b.Record(ctx, "close_bucket", false); err != nil {
+		t.Fatalf("Record failed: %q", err)
+	}
+	for i := 0; i < 2; i++ {
+		if allow, err := b.Allow(ctx, "close_bucket"); err != nil || !allow {
+			t.Errorf("closed circuit got:%v, %v want allowed", allow, err)
+		}
+	}
+}
+
+func TestBreakerPause(t *testing.T) {
+	ctx := context.Background()
+	for _, threshold := range []int{0, 3} {
+		b := NewBreaker(&stubs.FirestoreStub{}, "automation-project", "circuits", threshold, 15*time.Minute)
+		if _, err := b.Record(ctx, "close_bucket", true); err != nil {
+			t.Fatalf("Record failed: %q", err)
+		}
+		if err := b.Pause(ctx, "close_bucket", true); err != nil {
+			t.Fatalf("Pause failed: %q", err)
+		}
+		if allow, err := b.Allow(ctx, "close_bucket"); err != nil || allow {
+			t.Errorf("threshold %d: paused action got:%v, %v want not allowed", threshold, allow, err)
+		}
+		if _, err := b.Record(ctx, "close_bucket", true); err != nil {
+			t.Fatalf("Record failed: %q", err)
+		}
+		c, err := b.Circuit(ctx, "close_bucket")
+		if err != nil {
+			t.Fatalf("Circuit failed: %q", err)
+		}
+		if !c.Paused {
+			t.Errorf("threshold %d: recording a failure resumed the action", threshold)
+		}
+		if threshold > 0 && c.Failures != 2 {
+			t.Errorf("threshold %d: failures got:%d want:2", threshold, c.Failures)
+		}
+		if err := b.Pause(ctx, "close_bucket", false); err != nil {
+			t.Fatalf("Pause failed: %q", err)
+		}
+		if allow, err := b.Allow(ctx, "close_bucket"); err != nil || !allow {
+			t.Errorf("threshold %d: resumed action got:%v, %v want allowed", threshold, allow, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ChatClient holds the minimum interface required by the Chat service.
+type ChatClient interface {
+	Post(context.Context, string, string) error
+}
+
+// Chat service posting messages to a Google Chat space.
+type Chat struct {
+	client  ChatClient
+	webhook string
+}
+
+// NewChat returns a Chat service posting to the space of the incoming webhook URL.
+func NewChat(client ChatClient, webhook string) *Chat {
+	return &Chat{client: client, webhook: webhook}
+}
+
+// Send posts the text to the space.
+func (c *Chat) Send(ctx context.Context, text string) error {
+	return errors.Wrap(c.client.Post(ctx, c.webhook, text), "failed to post to chat")
+}
@@ -0,0 +1,77 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	firestore "google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// CheckpointClient holds the minimum interface required by the checkpoints service.
+type CheckpointClient interface {
+	GetDocument(context.Context, string) (*firestore.Document, error)
+	PatchDocument(context.Context, string, *firestore.Document) (*firestore.Document, error)
+}
+
+// Checkpoints service persists the state of long running work as JSON in a Firestore collection.
+type Checkpoints struct {
+	client     CheckpointClient
+	parent     string
+	collection string
+}
+
+// NewCheckpoints returns a new checkpoints service storing state in the collection of the
+// project's default Firestore database.
+func NewCheckpoints(client CheckpointClient, projectID, collection string) *Checkpoints {
+	return &Checkpoints{
+		client:     client,
+		parent:     fmt.Sprintf("projects/%s/databases/(default)/documents", projectID),
+		collection: collection,
+	}
+}
+
+// Save stores the state under the ID, replacing any previous state.
+func (c *Checkpoints) Save(ctx context.Context, id string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	doc := &firestore.Document{Fields: map[string]firestore.Value{"state": {StringValue: string(b)}}}
+	if _, err := c.client.PatchDocument(ctx, c.parent+"/"+c.collection+"/"+id, doc); err != nil {
+		return errors.Wrapf(err, "failed to save checkpoint %q", id)
+	}
+	return nil
+}
+
+// Load reads the state stored under the ID into v, returning false if there is none.
+func (c *Checkpoints) Load(ctx context.Context, id string, v interface{}) (bool, error) {
+	d, err := c.client.GetDocument(ctx, c.parent+"/"+c.collection+"/"+id)
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to load checkpoint %q", id)
+	}
+	if err := json.Unmarshal([]byte(d.Fields["state"].StringValue), v); err != nil {
+		return false, errors.Wrapf(err, "failed to decode checkpoint %q", id)
+	}
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// CloudIdentityClient holds the minimum interface required by the CloudIdentity service.
+type CloudIdentityClient interface {
+	LookupGroup(context.Context, string) (string, error)
+	ListMemberships(context.Context, string) ([]*cloudidentity.Membership, error)
+}
+
+// CloudIdentity service reading the members of Google Workspace or Cloud Identity groups.
+type CloudIdentity struct {
+	client CloudIdentityClient
+}
+
+// NewCloudIdentity returns a CloudIdentity service.
+func NewCloudIdentity(client CloudIdentityClient) *CloudIdentity {
+	return &CloudIdentity{client: client}
+}
+
+// ExternalMembers returns the identities of the group, expanding nested groups, whose email
+// address is not from one of the allowed domains. Groups outside of the account, which cannot be
+// expanded, are external themselves unless they are from an allowed domain.
+func (c *CloudIdentity) ExternalMembers(ctx context.Context, group string, allowDomains []string) ([]string, error) {
+	if len(allowDomains) == 0 {
+		return nil, errors.New("must provide at least one domain to allow")
+	}
+	seen := map[string]bool{strings.ToLower(group): true}
+	external := []string{}
+	for queue := []string{group}; len(queue) > 0; queue = queue[1:] {
+		name, err := c.client.LookupGroup(ctx, queue[0])
+		if groupUnavailable(err) {
+			if !fromDomains(queue[0], allowDomains) {
+				external = append(external, queue[0])
+			}
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to look up group %q", queue[0])
+		}
+		memberships, err := c.client.ListMemberships(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list members of %q", queue[0])
+		}
+		for _, m := range memberships {
+			if m.PreferredMemberKey == nil {
+				continue
+			}
+			id := m.PreferredMemberKey.Id
+			if id == "" || seen[strings.ToLower(id)] {
+				continue
+			}
+			seen[strings.ToLower(id)] = true
+			switch {
+			case m.Type == "GROUP":
+				queue = append(queue, id)
+			case !fromDomains(id, allowDomains):
+				external = append(external, id)
+			}
+		}
+	}
+	sort.Strings(external)
+	return external, nil
+}
+
+// fromDomains reports whether the email address is from one of the domains.
+func fromDomains(email string, domains []string) bool {
+	for _, d := range domains {
+		if strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupUnavailable reports whether the group lookup failed as the group is missing or outside
+// of the account.
+func groupUnavailable(err error) bool {
+	e, ok := errors.Cause(err).(*googleapi.Error)
+	return ok && (e.Code == http.StatusNotFound || e.Code == http.StatusForbidden)
+}
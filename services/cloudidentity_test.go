@@ -0,0 +1,76 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+)
+
+func TestExternalMembers(t *testing.T) {
+	ctx := context.Background()
+	member := func(id, kind string) *cloudidentity.Membership {
+		return &cloudidentity.Membership{PreferredMemberKey: &cloudidentity.EntityKey{Id: id}, Type: kind}
+	}
+	stub := &stubs.CloudIdentityStub{
+		Groups: map[string]string{
+			"devs@example.com":        "groups/devs",
+			"contractors@example.com": "groups/contractors",
+			"admins@example.com":      "groups/admins",
+		},
+		Memberships: map[string][]*cloudidentity.Membership{
+			"groups/devs": {
+				member("dev@example.com", "USER"),
+				member("contractors@example.com", "GROUP"),
+				member("admins@example.com", "GROUP"),
+			},
+			"groups/contractors": {
+				member("tim@gmail.com", "USER"),
+				member("partners@partner.com", "GROUP"),
+				member("devs@example.com", "GROUP"),
+			},
+			"groups/admins": {
+				member("ops@example.com", "USER"),
+				member("app@project.iam.gserviceaccount.com", "SERVICE_ACCOUNT"),
+			},
+		},
+	}
+	for _, tt := range []struct {
+		name          string
+		group         string
+		allow         []string
+		want          []string
+		expectedError bool
+	}{
+		{name: "nested", group: "devs@example.com", allow: []string{"example.com"}, want: []string{"app@project.iam.gserviceaccount.com", "partners@partner.com", "tim@gmail.com"}},
+		{name: "allowed", group: "admins@example.com", allow: []string{"example.com", "project.iam.gserviceaccount.com"}, want: []string{}},
+		{name: "outside account", group: "partners@partner.com", allow: []string{"example.com"}, want: []string{"partners@partner.com"}},
+		{name: "no domains", group: "devs@example.com", expectedError: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCloudIdentity(stub).ExternalMembers(ctx, tt.group, tt.allow)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("%s got error %v, want error %t", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s external members (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
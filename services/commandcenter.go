@@ -16,6 +16,7 @@ package services
 
 import (
 	"context"
+	"sort"
 
 	crm "google.golang.org/genproto/googleapis/cloud/securitycenter/v1beta1"
 	"google.golang.org/genproto/protobuf/field_mask"
@@ -44,6 +45,7 @@ func (r *CommandCenter) AddSecurityMarks(ctx context.Context, serviceID string,
 	for k := range securityMarks {
 		paths = append(paths, "marks."+k)
 	}
+	sort.Strings(paths)
 
 	return r.client.AddSecurityMarks(ctx, &crm.UpdateSecurityMarksRequest{
 		UpdateMask: &field_mask.FieldMask{
@@ -0,0 +1,74 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	containeranalysis "google.golang.org/api/containeranalysis/v1beta1"
+)
+
+// ContainerAnalysisClient holds the minimum interface required by the Container Analysis service.
+type ContainerAnalysisClient interface {
+	GetOccurrence(context.Context, string) (*containeranalysis.Occurrence, error)
+	ListOccurrences(context.Context, string, string) ([]*containeranalysis.Occurrence, error)
+	DeleteOccurrence(context.Context, string) error
+}
+
+// ContainerAnalysis service.
+type ContainerAnalysis struct {
+	client ContainerAnalysisClient
+}
+
+// NewContainerAnalysis returns a Container Analysis service.
+func NewContainerAnalysis(client ContainerAnalysisClient) *ContainerAnalysis {
+	return &ContainerAnalysis{client: client}
+}
+
+// Occurrence returns the occurrence with the given name.
+func (c *ContainerAnalysis) Occurrence(ctx context.Context, name string) (*containeranalysis.Occurrence, error) {
+	o, err := c.client.GetOccurrence(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get occurrence %q", name)
+	}
+	return o, nil
+}
+
+// RevokeAttestations deletes the attestations of the image made by the attestor's note and
+// returns the names of the deleted attestations.
+//
+// Binary Authorization policies requiring the attestor then refuse to deploy the image.
+func (c *ContainerAnalysis) RevokeAttestations(ctx context.Context, projectID, noteName, imageURI string) ([]string, error) {
+	filter := fmt.Sprintf("kind=%q AND resourceUrl=%q", "ATTESTATION", imageURI)
+	occurrences, err := c.client.ListOccurrences(ctx, projectID, filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list attestations of %q", imageURI)
+	}
+	var revoked []string
+	for _, o := range occurrences {
+		if o.NoteName != noteName || o.Resource == nil || o.Resource.Uri != imageURI {
+			continue
+		}
+		if err := c.client.DeleteOccurrence(ctx, o.Name); err != nil {
+			return revoked, errors.Wrapf(err, "failed to delete attestation %q", o.Name)
+		}
+		revoked = append(revoked, o.Name)
+	}
+	sort.Strings(revoked)
+	return revoked, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+
+	"cloud.google.com/go/iam"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// Kinds of the resources whose changes are logged.
+const (
+	projectPolicyKind      = "project_iam_policy"
+	organizationPolicyKind = "organization_iam_policy"
+	bucketPolicyKind       = "bucket_iam_policy"
+	objectACLKind          = "object_acl"
+	firewallRuleKind       = "firewall_rule"
+	cryptoKeyPolicyKind    = "crypto_key_iam_policy"
+	keyRingPolicyKind      = "key_ring_iam_policy"
+	secretPolicyKind       = "secret_iam_policy"
+	secretVersionKind      = "secret_version"
+	serviceKind            = "service"
+	projectKind            = "project"
+	lienKind               = "lien"
+	billingKind            = "project_billing_info"
+)
+
+// Change is the structured diff of an IAM policy, object ACL, firewall rule, secret version, API,
+// project, lien or project billing changed by a remediation, logged so audits can tell exactly what was changed and
+// rollbacks can revert it.
+type Change struct {
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+	// Added and Removed are the grants of an IAM policy or ACL the change added and removed.
+	Added   []Grant `json:"added,omitempty"`
+	Removed []Grant `json:"removed,omitempty"`
+	// Fields holds the values of the other fields changed, by field name.
+	Fields map[string]FieldChange `json:"fields,omitempty"`
+}
+
+// Grant is a role granted to a member by an IAM policy, under a condition if any.
+type Grant struct {
+	Role      string `json:"role"`
+	Member    string `json:"member"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// FieldChange holds the value of a field before and after a change, null for a resource created or
+// deleted.
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// changeLoggerKey is the key of the logger in the contexts returned by WithChangeLogger.
+type changeLoggerKey struct{}
+
+// WithChangeLogger returns a context the changes made by the services with it are logged to with the
+// logger, so they are logged along with the other messages of the remediation.
+func WithChangeLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, changeLoggerKey{}, l)
+}
+
+// logChange logs the change as JSON to the logger of the context, or to the standard logger if it
+// has none. Changes leaving the resource as it was are not logged.
+func logChange(ctx context.Context, c *Change) {
+	if len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Fields) == 0 {
+		return
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		log.Printf("failed to encode change of %s %s: %q", c.Kind, c.Resource, err)
+		return
+	}
+	if l, ok := ctx.Value(changeLoggerKey{}).(*Logger); ok && l != nil {
+		// The members changed are what an audit or rollback needs, they are not redacted.
+		l.WithRedactor(nil).Info("changed %s %s: %s", c.Kind, c.Resource, b)
+		return
+	}
+	log.Printf("changed %s %s: %s", c.Kind, c.Resource, b)
+}
+
+// policyGrants returns the grants of a Cloud Resource Manager policy, read before the policy is
+// modified in place.
+func policyGrants(policy *crm.Policy) []Grant {
+	var grants []Grant
+	for _, b := range policy.Bindings {
+		condition := ""
+		if b.Condition != nil {
+			condition = b.Condition.Expression
+		}
+		for _, m := range b.Members {
+			grants = append(grants, Grant{Role: b.Role, Member: m, Condition: condition})
+		}
+	}
+	return grants
+}
+
+// bucketGrants returns the grants of a bucket's policy.
+func bucketGrants(policy *iam.Policy) []Grant {
+	var grants []Grant
+	for _, role := range policy.Roles() {
+		for _, m := range policy.Members(role) {
+			grants = append(grants, Grant{Role: string(role), Member: m})
+		}
+	}
+	return grants
+}
+
+// policyChange returns the change of the resource's policy from the grants before to those after.
+func policyChange(kind, resource string, before, after []Grant) *Change {
+	c := &Change{Kind: kind, Resource: resource}
+	c.Added = subtractGrants(after, before)
+	c.Removed = subtractGrants(before, after)
+	return c
+}
+
+// subtractGrants returns the grants of a not in b, sorted by role and member.
+func subtractGrants(a, b []Grant) []Grant {
+	in := make(map[Grant]bool)
+	for _, g := range b {
+		in[g] = true
+	}
+	var diff []Grant
+	for _, g := range a {
+		if !in[g] {
+			in[g] = true
+			diff = append(diff, g)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].Role != diff[j].Role {
+			return diff[i].Role < diff[j].Role
+		}
+		return diff[i].Member < diff[j].Member
+	})
+	return diff
+}
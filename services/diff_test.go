@@ -0,0 +1,71 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestPolicyChange(t *testing.T) {
+	before := []Grant{
+		{Role: "roles/owner", Member: "user:alice@example.com"},
+		{Role: "roles/viewer", Member: "user:bob@example.com"},
+	}
+	after := []Grant{
+		{Role: "roles/viewer", Member: "user:bob@example.com"},
+		{Role: "roles/viewer", Member: "user:alice@example.com", Condition: "request.time < timestamp('2020-01-01T00:00:00Z')"},
+	}
+	c := policyChange(projectPolicyKind, "projects/test-project", before, after)
+	if diff := cmp.Diff([]Grant{after[1]}, c.Added); diff != "" {
+		t.Errorf("unexpected added grants (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]Grant{before[0]}, c.Removed); diff != "" {
+		t.Errorf("unexpected removed grants (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemoveUsersProjectLogsChange(t *testing.T) {
+	client := &recordingLogger{}
+	ctx := WithChangeLogger(context.Background(), NewLogger(client).WithRedactor(DefaultRedactor()))
+	crmStub := &stubs.ResourceManagerStub{GetPolicyResponse: &crm.Policy{Bindings: []*crm.Binding{
+		{Role: "roles/editor", Members: []string{"user:alice@example.com", "user:bob@example.com"}},
+	}}}
+	r := NewResource(crmStub, &stubs.StorageStub{})
+	if err := r.RemoveUsersProject(ctx, "test-project", []string{"user:alice@example.com"}); err != nil {
+		t.Fatalf("RemoveUsersProject failed: %q", err)
+	}
+	if len(client.messages) != 1 {
+		t.Fatalf("got %d messages want 1", len(client.messages))
+	}
+	prefix := "changed project_iam_policy projects/test-project: "
+	if !strings.HasPrefix(client.messages[0], prefix) {
+		t.Fatalf("got message %q", client.messages[0])
+	}
+	var c Change
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(client.messages[0], prefix)), &c); err != nil {
+		t.Fatalf("failed to decode change: %q", err)
+	}
+	want := Change{Kind: projectPolicyKind, Resource: "projects/test-project", Removed: []Grant{{Role: "roles/editor", Member: "user:alice@example.com"}}}
+	if diff := cmp.Diff(want, c); diff != "" {
+		t.Errorf("unexpected change (-want +got):\n%s", diff)
+	}
+}
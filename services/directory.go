@@ -0,0 +1,159 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// DirectoryClient holds the minimum interface required by the Directory service.
+type DirectoryClient interface {
+	GetUser(context.Context, string) (*admin.User, error)
+	PatchUser(context.Context, string, *admin.User) (*admin.User, error)
+	SignOut(context.Context, string) error
+	ListTokens(context.Context, string) ([]*admin.Token, error)
+	DeleteToken(context.Context, string, string) error
+	ListGroups(context.Context, string) ([]*admin.Group, error)
+	InsertMember(context.Context, string, *admin.Member) (*admin.Member, error)
+	DeleteMember(context.Context, string, string) error
+}
+
+// Directory service managing the Google Workspace or Cloud Identity users implicated in findings.
+type Directory struct {
+	client DirectoryClient
+}
+
+// NewDirectory returns a Directory service.
+func NewDirectory(client DirectoryClient) *Directory {
+	return &Directory{client: client}
+}
+
+// Suspended reports whether the user is suspended.
+func (d *Directory) Suspended(ctx context.Context, email string) (bool, error) {
+	u, err := d.client.GetUser(ctx, email)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get user %q", email)
+	}
+	return u.Suspended, nil
+}
+
+// SuspendUser suspends the user, who can no longer sign in until an administrator restores them.
+func (d *Directory) SuspendUser(ctx context.Context, email string) error {
+	patch := &admin.User{Suspended: true, ForceSendFields: []string{"Suspended"}}
+	if _, err := d.client.PatchUser(ctx, email, patch); err != nil {
+		return errors.Wrapf(err, "failed to suspend user %q", email)
+	}
+	return nil
+}
+
+// Enforced2SV reports whether 2-Step Verification is enforced for the user.
+func (d *Directory) Enforced2SV(ctx context.Context, email string) (bool, error) {
+	u, err := d.client.GetUser(ctx, email)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get user %q", email)
+	}
+	return u.IsEnforcedIn2Sv, nil
+}
+
+// MoveToOrgUnit moves the user into the organizational unit of the path, such as "/2SV enforced",
+// and reports whether the user was moved.
+func (d *Directory) MoveToOrgUnit(ctx context.Context, email, orgUnitPath string) (bool, error) {
+	u, err := d.client.GetUser(ctx, email)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get user %q", email)
+	}
+	if u.OrgUnitPath == orgUnitPath {
+		return false, nil
+	}
+	if _, err := d.client.PatchUser(ctx, email, &admin.User{OrgUnitPath: orgUnitPath}); err != nil {
+		return false, errors.Wrapf(err, "failed to move user %q to %q", email, orgUnitPath)
+	}
+	return true, nil
+}
+
+// SignOut signs the user out of every session and resets their sign-in cookies.
+func (d *Directory) SignOut(ctx context.Context, email string) error {
+	if err := d.client.SignOut(ctx, email); err != nil {
+		return errors.Wrapf(err, "failed to sign out user %q", email)
+	}
+	return nil
+}
+
+// RevokeTokens revokes the OAuth tokens the user granted to third-party applications and returns
+// the client IDs of the applications whose access was revoked.
+func (d *Directory) RevokeTokens(ctx context.Context, email string) ([]string, error) {
+	tokens, err := d.client.ListTokens(ctx, email)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list tokens of %q", email)
+	}
+	var revoked []string
+	for _, t := range tokens {
+		if err := d.client.DeleteToken(ctx, email, t.ClientId); err != nil {
+			return revoked, errors.Wrapf(err, "failed to revoke token of %q for %q", email, t.ClientId)
+		}
+		revoked = append(revoked, t.ClientId)
+	}
+	sort.Strings(revoked)
+	return revoked, nil
+}
+
+// AddToGroup adds the user to the group as a member.
+func (d *Directory) AddToGroup(ctx context.Context, group, email string) error {
+	if _, err := d.client.InsertMember(ctx, group, &admin.Member{Email: email, Role: "MEMBER"}); err != nil {
+		return errors.Wrapf(err, "failed to add %q to group %q", email, group)
+	}
+	return nil
+}
+
+// RemoveFromGroups removes the user from every group they are a direct member of and returns the
+// email addresses of the groups it was removed from.
+func (d *Directory) RemoveFromGroups(ctx context.Context, email string) ([]string, error) {
+	return d.removeFromGroups(ctx, email, func(string) bool { return true })
+}
+
+// RemoveFromPrivilegedGroups removes the user from those of the groups they are a direct member of
+// and returns the email addresses of the groups it was removed from. Groups are matched regardless
+// of case.
+func (d *Directory) RemoveFromPrivilegedGroups(ctx context.Context, email string, groups []string) ([]string, error) {
+	privileged := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		privileged[strings.ToLower(g)] = true
+	}
+	return d.removeFromGroups(ctx, email, func(group string) bool { return privileged[strings.ToLower(group)] })
+}
+
+func (d *Directory) removeFromGroups(ctx context.Context, email string, remove func(string) bool) ([]string, error) {
+	groups, err := d.client.ListGroups(ctx, email)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list groups of %q", email)
+	}
+	var removed []string
+	for _, g := range groups {
+		if !remove(g.Email) {
+			continue
+		}
+		if err := d.client.DeleteMember(ctx, g.Email, email); err != nil {
+			return removed, errors.Wrapf(err, "failed to remove %q from group %q", email, g.Email)
+		}
+		removed = append(removed, g.Email)
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
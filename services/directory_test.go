@@ -0,0 +1,105 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestSuspendUser(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubs.DirectoryStub{Users: map[string]*admin.User{"tim@example.com": {PrimaryEmail: "tim@example.com"}}}
+	d := NewDirectory(stub)
+	if suspended, err := d.Suspended(ctx, "tim@example.com"); err != nil || suspended {
+		t.Fatalf("got suspended %t, error %q before suspending", suspended, err)
+	}
+	if err := d.SuspendUser(ctx, "tim@example.com"); err != nil {
+		t.Fatalf("failed to suspend user: %q", err)
+	}
+	if suspended, err := d.Suspended(ctx, "tim@example.com"); err != nil || !suspended {
+		t.Errorf("got suspended %t, error %q after suspending", suspended, err)
+	}
+}
+
+func TestRemoveFromGroups(t *testing.T) {
+	stub := &stubs.DirectoryStub{Groups: map[string][]*admin.Group{
+		"tim@example.com": {{Email: "sre@example.com"}, {Email: "admins@example.com"}},
+	}}
+	removed, err := NewDirectory(stub).RemoveFromGroups(context.Background(), "tim@example.com")
+	if err != nil {
+		t.Fatalf("failed to remove from groups: %q", err)
+	}
+	if diff := cmp.Diff([]string{"admins@example.com", "sre@example.com"}, removed); diff != "" {
+		t.Errorf("removed groups (-want +got):\n%s", diff)
+	}
+	expected := map[string][]string{"sre@example.com": {"tim@example.com"}, "admins@example.com": {"tim@example.com"}}
+	if diff := cmp.Diff(expected, stub.DeletedMembers); diff != "" {
+		t.Errorf("deleted members (-want +got):\n%s", diff)
+	}
+}
+
+func TestRevokeTokens(t *testing.T) {
+	stub := &stubs.DirectoryStub{Tokens: map[string][]*admin.Token{
+		"tim@example.com": {{ClientId: "mail-client"}, {ClientId: "drive-sync"}},
+	}}
+	revoked, err := NewDirectory(stub).RevokeTokens(context.Background(), "tim@example.com")
+	if err != nil {
+		t.Fatalf("failed to revoke tokens: %q", err)
+	}
+	if diff := cmp.Diff([]string{"drive-sync", "mail-client"}, revoked); diff != "" {
+		t.Errorf("revoked tokens (-want +got):\n%s", diff)
+	}
+	if left := stub.Tokens["tim@example.com"]; len(left) != 0 {
+		t.Errorf("got %d tokens left, want 0", len(left))
+	}
+}
+
+func TestRemoveFromPrivilegedGroups(t *testing.T) {
+	stub := &stubs.DirectoryStub{Groups: map[string][]*admin.Group{
+		"tim@example.com": {{Email: "sre@example.com"}, {Email: "gcp-organization-admins@example.com"}},
+	}}
+	privileged := []string{"GCP-Organization-Admins@example.com", "gcp-billing-admins@example.com"}
+	removed, err := NewDirectory(stub).RemoveFromPrivilegedGroups(context.Background(), "tim@example.com", privileged)
+	if err != nil {
+		t.Fatalf("failed to remove from groups: %q", err)
+	}
+	if diff := cmp.Diff([]string{"gcp-organization-admins@example.com"}, removed); diff != "" {
+		t.Errorf("removed groups (-want +got):\n%s", diff)
+	}
+	expected := map[string][]string{"gcp-organization-admins@example.com": {"tim@example.com"}}
+	if diff := cmp.Diff(expected, stub.DeletedMembers); diff != "" {
+		t.Errorf("deleted members (-want +got):\n%s", diff)
+	}
+}
+
+func TestMoveToOrgUnit(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubs.DirectoryStub{Users: map[string]*admin.User{"tim@example.com": {PrimaryEmail: "tim@example.com", OrgUnitPath: "/"}}}
+	d := NewDirectory(stub)
+	if moved, err := d.MoveToOrgUnit(ctx, "tim@example.com", "/2SV enforced"); err != nil || !moved {
+		t.Fatalf("got moved %t, error %q", moved, err)
+	}
+	if got := stub.Users["tim@example.com"].OrgUnitPath; got != "/2SV enforced" {
+		t.Errorf("got org unit %q, want %q", got, "/2SV enforced")
+	}
+	if moved, err := d.MoveToOrgUnit(ctx, "tim@example.com", "/2SV enforced"); err != nil || moved {
+		t.Errorf("got moved %t, error %q moving again", moved, err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorReportingClient holds the minimum interface required by the error reporting service.
+type ErrorReportingClient interface {
+	ReportErrorEvent(context.Context, string, *clouderrorreporting.ReportedErrorEvent) error
+}
+
+// ErrorReporting service reports the errors of actions to Cloud Error Reporting.
+type ErrorReporting struct {
+	client    ErrorReportingClient
+	projectID string
+}
+
+// NewErrorReporting returns an error reporting service reporting to the project.
+func NewErrorReporting(client ErrorReportingClient, projectID string) *ErrorReporting {
+	return &ErrorReporting{client: client, projectID: projectID}
+}
+
+// variableRegex matches the parts of error messages naming resources or counting things, which
+// differ between occurrences of the same error.
+var variableRegex = regexp.MustCompile(`"[^"]*"|'[^']*'|\S+@\S+|\d+`)
+
+// Report reports the error returned by the action.
+//
+// Error Reporting groups errors by where they are reported from, which is set to the action and
+// the fingerprint of the error so occurrences of the same failure group together whichever
+// resource they hit.
+func (e *ErrorReporting) Report(ctx context.Context, action string, err error) error {
+	class, fp := ErrorClass(err), Fingerprint(action, err)
+	event := &clouderrorreporting.ReportedErrorEvent{
+		Message: fmt.Sprintf("%s failed with %s [%s]: %s", action, class, fp, err),
+		ServiceContext: &clouderrorreporting.ServiceContext{
+			Service:      action,
+			ResourceType: "cloud_function",
+		},
+		Context: &clouderrorreporting.ErrorContext{
+			ReportLocation: &clouderrorreporting.SourceLocation{
+				FilePath:     action,
+				FunctionName: class + "/" + fp,
+				LineNumber:   1,
+			},
+		},
+	}
+	return errors.Wrapf(e.client.ReportErrorEvent(ctx, e.projectID, event), "failed to report error of %s", action)
+}
+
+// ErrorClass returns the class of the error: the status of API errors, "partial_failure" for
+// actions that failed on some of their resources, "deadline_exceeded" and "canceled" for context
+// errors or the type of the error otherwise.
+func ErrorClass(err error) string {
+	class, _ := classify(err)
+	return class
+}
+
+// Fingerprint returns a stable fingerprint of the error returned by the action.
+//
+// API, partial and context errors are fingerprinted by their class alone. Other errors share
+// their type so their message is included, stripped of quoted names, email addresses and numbers.
+func Fingerprint(action string, err error) string {
+	class, generic := classify(err)
+	key := action + "|" + class
+	if generic {
+		key += "|" + variableRegex.ReplaceAllString(errors.Cause(err).Error(), "_")
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// classify returns the class of the error and whether it is only known by its type.
+func classify(err error) (string, bool) {
+	cause := errors.Cause(err)
+	switch e := cause.(type) {
+	case *googleapi.Error:
+		if len(e.Errors) > 0 && e.Errors[0].Reason != "" {
+			return fmt.Sprintf("googleapi_%d_%s", e.Code, e.Errors[0].Reason), false
+		}
+		return fmt.Sprintf("googleapi_%d", e.Code), false
+	case *PartialError:
+		return "partial_failure", false
+	}
+	if s, ok := status.FromError(cause); ok {
+		return "grpc_" + s.Code().String(), false
+	}
+	switch cause {
+	case context.DeadlineExceeded:
+		return "deadline_exceeded", false
+	case context.Canceled:
+		return "canceled", false
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", cause), "*"), true
+}
@@ -0,0 +1,81 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	"github.com/pkg/errors"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFingerprint(t *testing.T) {
+	denied := func(bucket string) error {
+		return errors.Wrapf(&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}}, "failed to close bucket %q", bucket)
+	}
+	test := []struct {
+		name          string
+		a, b          error
+		expectedClass string
+		expectedSame  bool
+	}{
+		{name: "api errors on different resources", a: denied("a"), b: denied("b"), expectedClass: "googleapi_403_forbidden", expectedSame: true},
+		{name: "api errors with different status", a: denied("a"), b: &googleapi.Error{Code: 404}, expectedClass: "googleapi_403_forbidden"},
+		{name: "grpc errors on different resources", a: status.Errorf(codes.PermissionDenied, "denied on %q", "a"), b: errors.Wrap(status.Errorf(codes.PermissionDenied, "denied on %q", "b"), "failed"), expectedClass: "grpc_PermissionDenied", expectedSame: true},
+		{name: "messages naming different resources", a: fmt.Errorf("bucket %q has 3 owners", "a"), b: fmt.Errorf("bucket %q has 4 owners", "b"), expectedClass: "errors.errorString", expectedSame: true},
+		{name: "different messages", a: errors.New("no project"), b: errors.New("no bucket"), expectedClass: "errors.fundamental"},
+		{name: "partial failures", a: &PartialError{Failed: map[string]string{"a": "denied"}}, b: errors.Wrap(&PartialError{Failed: map[string]string{"b": "denied"}}, "failed"), expectedClass: "partial_failure", expectedSame: true},
+		{name: "deadline", a: context.DeadlineExceeded, b: errors.Wrap(context.DeadlineExceeded, "failed"), expectedClass: "deadline_exceeded", expectedSame: true},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorClass(tt.a); got != tt.expectedClass {
+				t.Errorf("%s got class %q want %q", tt.name, got, tt.expectedClass)
+			}
+			if got := Fingerprint("close_bucket", tt.a) == Fingerprint("close_bucket", tt.b); got != tt.expectedSame {
+				t.Errorf("%s got same fingerprint %v want %v", tt.name, got, tt.expectedSame)
+			}
+			if Fingerprint("close_bucket", tt.a) == Fingerprint("open_firewall", tt.a) {
+				t.Errorf("%s got same fingerprint for different actions", tt.name)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubs.ErrorReportingStub{}
+	r := NewErrorReporting(stub, "automation-project")
+	err := errors.Wrap(&googleapi.Error{Code: 403}, "failed to close bucket")
+	if err := r.Report(ctx, "close_bucket", err); err != nil {
+		t.Fatalf("Report failed: %q", err)
+	}
+	if len(stub.Events) != 1 {
+		t.Fatalf("got %d events want 1", len(stub.Events))
+	}
+	e := stub.Events[0]
+	if e.ServiceContext.Service != "close_bucket" || e.Context.ReportLocation.FunctionName != "googleapi_403/"+Fingerprint("close_bucket", err) {
+		t.Errorf("got service %q and location %+v", e.ServiceContext.Service, e.Context.ReportLocation)
+	}
+	if !strings.Contains(e.Message, "failed to close bucket") {
+		t.Errorf("got message %q, missing the error", e.Message)
+	}
+}
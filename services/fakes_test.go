@@ -0,0 +1,159 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+	compute "google.golang.org/api/compute/v1"
+	sccpb "google.golang.org/genproto/googleapis/cloud/securitycenter/v1beta1"
+)
+
+// The fakes implement the same interfaces as the clients.
+var (
+	_ crmClient           = &stubs.ResourceManagerFake{}
+	_ storageClient       = &stubs.StorageFake{}
+	_ ArchiveClient       = &stubs.StorageFake{}
+	_ ComputeClient       = &stubs.ComputeFake{}
+	_ FirewallClient      = &stubs.ComputeFake{}
+	_ CommandCenterClient = &stubs.SecurityCommandCenterFake{}
+)
+
+func TestResourceFakes(t *testing.T) {
+	ctx := context.Background()
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddOrganization("456")
+	crmFake.AddFolder("123", "organizations/456")
+	crmFake.AddProject("test-project", 1, "folders/123")
+	if _, err := crmFake.SetPolicyProject(ctx, "test-project", &crm.Policy{Bindings: []*crm.Binding{
+		{Role: "roles/owner", Members: []string{"user:tom@gmail.com", "user:anne@example.com"}},
+	}}); err != nil {
+		t.Fatalf("failed to seed the project policy: %q", err)
+	}
+	storageFake := stubs.NewStorageFake()
+	storageFake.AddBucket("public-bucket", map[string][]string{"roles/storage.objectViewer": {"allUsers", "user:anne@example.com"}})
+	r := NewResource(crmFake, storageFake)
+
+	folders, err := r.Folders(ctx, "test-project")
+	if err != nil {
+		t.Fatalf("Folders failed: %q", err)
+	}
+	if !reflect.DeepEqual(folders, []string{"123"}) {
+		t.Errorf("got folders %v want [123]", folders)
+	}
+	projects, err := r.Projects(ctx, "123")
+	if err != nil {
+		t.Fatalf("Projects failed: %q", err)
+	}
+	if !reflect.DeepEqual(projects, []string{"test-project"}) {
+		t.Errorf("got projects %v want [test-project]", projects)
+	}
+	if err := r.RemoveUsersProject(ctx, "test-project", []string{"user:tom@gmail.com"}); err != nil {
+		t.Fatalf("RemoveUsersProject failed: %q", err)
+	}
+	if got := crmFake.ProjectPolicy("test-project").Bindings[0].Members; !reflect.DeepEqual(got, []string{"user:anne@example.com"}) {
+		t.Errorf("got owners %v want [user:anne@example.com]", got)
+	}
+	if err := r.RemoveMembersFromBucket(ctx, "public-bucket", []string{"allUsers"}); err != nil {
+		t.Fatalf("RemoveMembersFromBucket failed: %q", err)
+	}
+	if got := storageFake.Members("public-bucket", "roles/storage.objectViewer"); !reflect.DeepEqual(got, []string{"user:anne@example.com"}) {
+		t.Errorf("got viewers %v want [user:anne@example.com]", got)
+	}
+	if err := r.EnableBucketOnlyPolicy(ctx, "public-bucket"); err != nil {
+		t.Fatalf("EnableBucketOnlyPolicy failed: %q", err)
+	}
+	if !storageFake.BucketPolicyOnlyEnabled("public-bucket") {
+		t.Error("bucket only policy not enabled")
+	}
+	if err := r.RemoveMembersFromBucket(ctx, "missing-bucket", []string{"allUsers"}); err == nil {
+		t.Error("RemoveMembersFromBucket of a missing bucket succeeded")
+	}
+}
+
+func TestPolicyFakeRejectsStaleETag(t *testing.T) {
+	ctx := context.Background()
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddProject("test-project", 1, "organizations/456")
+	stale, err := crmFake.GetPolicyProject(ctx, "test-project")
+	if err != nil {
+		t.Fatalf("GetPolicyProject failed: %q", err)
+	}
+	if _, err := crmFake.SetPolicyProject(ctx, "test-project", stale); err != nil {
+		t.Fatalf("SetPolicyProject failed: %q", err)
+	}
+	stale.Etag = "etag-0"
+	if _, err := crmFake.SetPolicyProject(ctx, "test-project", stale); err == nil {
+		t.Error("SetPolicyProject with a stale ETag succeeded")
+	}
+}
+
+func TestComputeFake(t *testing.T) {
+	ctx := context.Background()
+	computeFake := stubs.NewComputeFake()
+	computeFake.AddInstance("test-project", "us-central1-a", &compute.Instance{
+		Name: "instance-1",
+		NetworkInterfaces: []*compute.NetworkInterface{
+			{Name: "nic0", AccessConfigs: []*compute.AccessConfig{{Name: "External NAT", Type: "ONE_TO_ONE_NAT", NatIP: "198.51.100.1"}}},
+		},
+	})
+	computeFake.AddFirewall("test-project", &compute.Firewall{Name: "open-ssh", SourceRanges: []string{"0.0.0.0/0"}})
+	h := NewHost(computeFake)
+	if err := h.RemoveExternalIPs(ctx, "test-project", "us-central1-a", "instance-1"); err != nil {
+		t.Fatalf("RemoveExternalIPs failed: %q", err)
+	}
+	if got := computeFake.Instance("test-project", "us-central1-a", "instance-1").NetworkInterfaces[0].AccessConfigs; len(got) != 0 {
+		t.Errorf("got access configs %v want none", got)
+	}
+	fw := NewFirewall(computeFake)
+	if _, err := fw.DisableFirewallRule(ctx, "test-project", "open-ssh", "open-ssh"); err != nil {
+		t.Fatalf("DisableFirewallRule failed: %q", err)
+	}
+	if !computeFake.Firewall("test-project", "open-ssh").Disabled {
+		t.Error("firewall rule not disabled")
+	}
+	if err := h.RemoveExternalIPs(ctx, "test-project", "us-central1-a", "missing"); err == nil {
+		t.Error("RemoveExternalIPs of a missing instance succeeded")
+	}
+}
+
+func TestCommandCenterFake(t *testing.T) {
+	ctx := context.Background()
+	const name = "organizations/456/sources/789/findings/abc"
+	sccFake := stubs.NewSecurityCommandCenterFake()
+	sccFake.AddFinding(&sccpb.Finding{Name: name, State: sccpb.Finding_ACTIVE, SecurityMarks: &sccpb.SecurityMarks{Marks: map[string]string{"kept": "true"}}})
+	cc := NewCommandCenter(sccFake)
+	if _, err := cc.AddSecurityMarks(ctx, name, map[string]string{"sra-remediated-event-time": "2020-06-01T18:00:00Z"}); err != nil {
+		t.Fatalf("AddSecurityMarks failed: %q", err)
+	}
+	if _, err := cc.SetInactive(ctx, name); err != nil {
+		t.Fatalf("SetInactive failed: %q", err)
+	}
+	f := sccFake.Finding(name)
+	want := map[string]string{"kept": "true", "sra-remediated-event-time": "2020-06-01T18:00:00Z"}
+	if !reflect.DeepEqual(f.SecurityMarks.Marks, want) {
+		t.Errorf("got marks %v want %v", f.SecurityMarks.Marks, want)
+	}
+	if f.State != sccpb.Finding_INACTIVE {
+		t.Errorf("got state %v want INACTIVE", f.State)
+	}
+	if _, err := cc.AddSecurityMarks(ctx, "organizations/456/sources/789/findings/missing", map[string]string{"a": "b"}); err == nil {
+		t.Error("AddSecurityMarks of a missing finding succeeded")
+	}
+}
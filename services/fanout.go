@@ -0,0 +1,88 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/pkg/errors"
+)
+
+// FanOutTopic receives the work items of actions touching too many projects to remediate in one
+// invocation, processed one project at a time by the FanOut function.
+const FanOutTopic = "threat-findings-fan-out"
+
+// OperationRemoveMember removes the work item's member from the IAM policy of its project.
+const OperationRemoveMember = "remove_member"
+
+// maxConcurrentPublishes bounds the work items published at once.
+const maxConcurrentPublishes = 20
+
+// WorkItem is the part of an action's remediation applied to a single project.
+type WorkItem struct {
+	// Action is the action the work item was published by.
+	Action string
+	// Operation is what is applied to the project, such as OperationRemoveMember.
+	Operation string
+	ProjectID string
+	// Member is the IAM member the operation applies to, such as "serviceAccount:<email>".
+	Member string
+	DryRun bool
+}
+
+// FanOut publishes the work items of an action, one per project.
+type FanOut struct {
+	pubsub        *PubSub
+	correlationID string
+}
+
+// NewFanOut returns a FanOut service publishing work items correlated with the finding.
+func NewFanOut(pubsub *PubSub, correlationID string) *FanOut {
+	return &FanOut{pubsub: pubsub, correlationID: correlationID}
+}
+
+// Publish publishes a copy of the work item for each of the projects, returning the projects
+// queued. Projects failing to publish are reported together in a *PartialError.
+func (f *FanOut) Publish(ctx context.Context, item WorkItem, projectIDs []string) ([]string, error) {
+	var (
+		mu     sync.Mutex
+		queued []string
+	)
+	err := ForEach(ctx, projectIDs, maxConcurrentPublishes, func(ctx context.Context, projectID string) error {
+		item := item
+		item.ProjectID = projectID
+		b, err := json.Marshal(item)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal work item")
+		}
+		m := &pubsub.Message{Data: b}
+		if f.correlationID != "" {
+			m.Attributes = map[string]string{CorrelationAttribute: f.correlationID}
+		}
+		if _, err := f.pubsub.Publish(ctx, FanOutTopic, m); err != nil {
+			return errors.Wrapf(err, "failed to publish work item of %q", projectID)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		queued = append(queued, projectID)
+		return nil
+	})
+	sort.Strings(queued)
+	return queued, err
+}
@@ -0,0 +1,57 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestFanOutPublish(t *testing.T) {
+	stub := &stubs.PubSubStub{StubbedTopic: &pubsub.Topic{}}
+	f := NewFanOut(NewPubSub(stub), "correlation-1")
+	item := WorkItem{Action: "iam_contain_service_account", Operation: OperationRemoveMember, Member: "serviceAccount:sa@p.iam.gserviceaccount.com"}
+	queued, err := f.Publish(context.Background(), item, []string{"project-b", "project-a", "project-c"})
+	if err != nil {
+		t.Fatalf("publish failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"project-a", "project-b", "project-c"}, queued); diff != "" {
+		t.Errorf("queued projects (-want +got):\n%s", diff)
+	}
+	var projects []string
+	for _, m := range stub.Published {
+		var got WorkItem
+		if err := json.Unmarshal(m.Data, &got); err != nil {
+			t.Fatalf("failed to unmarshal work item: %q", err)
+		}
+		if got.Member != item.Member || got.Operation != item.Operation || got.Action != item.Action {
+			t.Errorf("work item %+v does not match %+v", got, item)
+		}
+		if id := m.Attributes[CorrelationAttribute]; id != "correlation-1" {
+			t.Errorf("work item of %q has correlation ID %q, want %q", got.ProjectID, id, "correlation-1")
+		}
+		projects = append(projects, got.ProjectID)
+	}
+	sort.Strings(projects)
+	if diff := cmp.Diff(queued, projects); diff != "" {
+		t.Errorf("published projects (-want +got):\n%s", diff)
+	}
+}
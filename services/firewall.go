@@ -18,14 +18,22 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 	compute "google.golang.org/api/compute/v1"
 	"google.golang.org/api/googleapi"
 )
 
-// sshBlockName is the firewall rule name created when blocking SSH.
-const sshBlockName = "automatic-ssh-block"
+const (
+	// sshBlockName is the firewall rule name created when blocking SSH.
+	sshBlockName = "automatic-ssh-block"
+	// ipBlockName is the firewall rule name created when blocking IP addresses.
+	ipBlockName = "automatic-ip-block"
+	// quarantineName is the prefix of the firewall rules isolating quarantined instances.
+	quarantineName = "automatic-quarantine"
+)
 
 // FirewallClient holds the minimum interface required by the firewall service.
 type FirewallClient interface {
@@ -74,13 +82,81 @@ func (f *Firewall) BlockSSH(ctx context.Context, projectID string, sourceRanges
 	// Consider deduping. Currently this is done by the API.
 	sourceRanges = append(sourceRanges, fw.SourceRanges...)
 	ruleID := fmt.Sprintf("%d", fw.Id)
-	if err := f.UpdateFirewallRuleSourceRange(ctx, projectID, ruleID, fw.Name, sourceRanges); err != nil {
+	if err := f.updateSourceRanges(ctx, projectID, ruleID, fw, sourceRanges); err != nil {
 		return errors.Wrapf(err, "failed to update source ranges for: %q %q %q", projectID, ruleID, fw.Name)
 	}
 	log.Printf("firewall rule %q updated in %q", fw.Name, projectID)
 	return nil
 }
 
+// BlockIPs will add a firewall rule denying all ingress traffic from the given IP addresses to the network.
+func (f *Firewall) BlockIPs(ctx context.Context, projectID, network string, ips []string) error {
+	name := networkRuleName(ipBlockName, network)
+	sourceRanges := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		sourceRanges = append(sourceRanges, hostRange(ip))
+	}
+	fw, err := f.FirewallRule(ctx, projectID, name)
+	if err != nil {
+		if !notFound(err) {
+			return errors.Wrapf(err, "failed getting firewall rule: %q", name)
+		}
+		log.Printf("adding a new firewall rule to block %q", ips)
+		return f.addFirewallRule(ctx, projectID, &compute.Firewall{
+			Denied:       []*compute.FirewallDenied{{IPProtocol: "all"}},
+			Description:  "Block IP addresses by Security Response Automation",
+			Direction:    "INGRESS",
+			Name:         name,
+			Network:      network,
+			Priority:     0,
+			SourceRanges: sourceRanges,
+			// Priority 0 is the zero value and would otherwise be dropped from the request.
+			ForceSendFields: []string{"Priority"},
+		})
+	}
+	sourceRanges = append(sourceRanges, fw.SourceRanges...)
+	ruleID := fmt.Sprintf("%d", fw.Id)
+	if err := f.updateSourceRanges(ctx, projectID, ruleID, fw, sourceRanges); err != nil {
+		return errors.Wrapf(err, "failed to update source ranges for: %q %q %q", projectID, ruleID, fw.Name)
+	}
+	return nil
+}
+
+// QuarantineRules ensures firewall rules denying all ingress and egress traffic of instances with the
+// network tag exist in the network.
+func (f *Firewall) QuarantineRules(ctx context.Context, projectID, network, tag string) error {
+	for _, direction := range []string{"INGRESS", "EGRESS"} {
+		name := networkRuleName(quarantineName+"-"+strings.ToLower(direction), network)
+		_, err := f.FirewallRule(ctx, projectID, name)
+		if err == nil {
+			continue
+		}
+		if !notFound(err) {
+			return errors.Wrapf(err, "failed getting firewall rule: %q", name)
+		}
+		rule := &compute.Firewall{
+			Denied:      []*compute.FirewallDenied{{IPProtocol: "all"}},
+			Description: "Isolate quarantined instances by Security Response Automation",
+			Direction:   direction,
+			Name:        name,
+			Network:     network,
+			Priority:    0,
+			TargetTags:  []string{tag},
+			// Priority 0 is the zero value and would otherwise be dropped from the request.
+			ForceSendFields: []string{"Priority"},
+		}
+		if direction == "EGRESS" {
+			rule.DestinationRanges = []string{"0.0.0.0/0"}
+		} else {
+			rule.SourceRanges = []string{"0.0.0.0/0"}
+		}
+		if err := f.addFirewallRule(ctx, projectID, rule); err != nil {
+			return errors.Wrapf(err, "failed to add firewall rule: %q", name)
+		}
+	}
+	return nil
+}
+
 // addFirewallRule will add a firewall rule.
 func (f *Firewall) addFirewallRule(ctx context.Context, projectID string, fw *compute.Firewall) error {
 	op, err := f.client.InsertFirewallRule(ctx, projectID, fw)
@@ -90,34 +166,78 @@ func (f *Firewall) addFirewallRule(ctx context.Context, projectID string, fw *co
 	if errs := f.WaitGlobal(projectID, op); len(errs) > 0 {
 		return errs[0]
 	}
+	logChange(ctx, firewallChange(projectID, fw.Name, "rule", nil, fw))
 	return nil
 }
 
 // EnableFirewallRule sets the firewall rule to enabled.
 func (f *Firewall) EnableFirewallRule(ctx context.Context, projectID string, ruleID string, name string) (*compute.Operation, error) {
-	return f.client.PatchFirewallRule(ctx, projectID, ruleID, &compute.Firewall{Name: name, Disabled: false})
+	return f.setDisabled(ctx, projectID, ruleID, name, false)
 }
 
 // DisableFirewallRule sets the firewall rule to disabled.
 func (f *Firewall) DisableFirewallRule(ctx context.Context, projectID string, ruleID string, name string) (*compute.Operation, error) {
-	return f.client.PatchFirewallRule(ctx, projectID, ruleID, &compute.Firewall{Name: name, Disabled: true})
+	return f.setDisabled(ctx, projectID, ruleID, name, true)
+}
+
+func (f *Firewall) setDisabled(ctx context.Context, projectID, ruleID, name string, disabled bool) (*compute.Operation, error) {
+	before := f.current(ctx, projectID, ruleID)
+	op, err := f.client.PatchFirewallRule(ctx, projectID, ruleID, &compute.Firewall{Name: name, Disabled: disabled})
+	if err != nil {
+		return nil, err
+	}
+	var was interface{}
+	if before != nil {
+		was = before.Disabled
+	}
+	logChange(ctx, firewallChange(projectID, name, "disabled", was, disabled))
+	return op, nil
 }
 
 // UpdateFirewallRuleSourceRange updates the firewall source ranges
 func (f *Firewall) UpdateFirewallRuleSourceRange(ctx context.Context, projectID string, ruleID string, name string, sourceRanges []string) error {
-	op, err := f.client.PatchFirewallRule(ctx, projectID, ruleID, &compute.Firewall{Name: name, SourceRanges: sourceRanges})
+	before := f.current(ctx, projectID, ruleID)
+	if before == nil {
+		before = &compute.Firewall{Name: name}
+	}
+	return f.updateSourceRanges(ctx, projectID, ruleID, before, sourceRanges)
+}
+
+// updateSourceRanges replaces the source ranges of the rule as it was before the update.
+func (f *Firewall) updateSourceRanges(ctx context.Context, projectID, ruleID string, before *compute.Firewall, sourceRanges []string) error {
+	op, err := f.client.PatchFirewallRule(ctx, projectID, ruleID, &compute.Firewall{Name: before.Name, SourceRanges: sourceRanges})
 	if err != nil {
 		return err
 	}
 	if errs := f.WaitGlobal(projectID, op); len(errs) > 0 {
 		return errs[0]
 	}
+	logChange(ctx, firewallChange(projectID, before.Name, "sourceRanges", before.SourceRanges, sourceRanges))
 	return nil
 }
 
 // DeleteFirewallRule delete the firewall rule.
 func (f *Firewall) DeleteFirewallRule(ctx context.Context, projectID string, ruleID string) (*compute.Operation, error) {
-	return f.client.DeleteFirewallRule(ctx, projectID, ruleID)
+	before := f.current(ctx, projectID, ruleID)
+	op, err := f.client.DeleteFirewallRule(ctx, projectID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	name := ruleID
+	if before != nil {
+		name = before.Name
+	}
+	logChange(ctx, firewallChange(projectID, name, "rule", before, nil))
+	return op, nil
+}
+
+// current returns the rule as it is before a change, nil if it cannot be read.
+func (f *Firewall) current(ctx context.Context, projectID, ruleID string) *compute.Firewall {
+	r, err := f.client.FirewallRule(ctx, projectID, ruleID)
+	if err != nil {
+		return nil
+	}
+	return r
 }
 
 // FirewallRule get a firewall rule
@@ -129,3 +249,38 @@ func (f *Firewall) FirewallRule(ctx context.Context, projectID string, ruleID st
 func (f *Firewall) WaitGlobal(project string, op *compute.Operation) []error {
 	return f.client.WaitGlobal(project, op)
 }
+
+// firewallChange returns the change of a field of the rule from before to after.
+func firewallChange(projectID, name, field string, before, after interface{}) *Change {
+	return &Change{
+		Kind:     firewallRuleKind,
+		Resource: fmt.Sprintf("projects/%s/global/firewalls/%s", projectID, name),
+		Fields:   map[string]FieldChange{field: {Before: before, After: after}},
+	}
+}
+
+// networkRuleName returns the rule name suffixed with the short name of a non default network.
+func networkRuleName(name, network string) string {
+	n := network[strings.LastIndex(network, "/")+1:]
+	if n == "" || n == "default" {
+		return name
+	}
+	return name + "-" + n
+}
+
+// hostRange returns the IP address as a single host CIDR range.
+func hostRange(ip string) string {
+	if strings.Contains(ip, "/") {
+		return ip
+	}
+	if strings.Contains(ip, ":") {
+		return ip + "/128"
+	}
+	return ip + "/32"
+}
+
+// notFound returns true if the error is a not found API error.
+func notFound(err error) bool {
+	e, ok := err.(*googleapi.Error)
+	return ok && e.Code == http.StatusNotFound
+}
@@ -28,6 +28,8 @@ import (
 
 // ComputeClient contains minimum interface required by the host service.
 type ComputeClient interface {
+	AttachDisk(context.Context, string, string, string, *compute.AttachedDisk) (*compute.Operation, error)
+	DetachDisk(context.Context, string, string, string, string) (*compute.Operation, error)
 	DiskInsert(context.Context, string, string, *compute.Disk) (*compute.Operation, error)
 	CreateSnapshot(context.Context, string, string, string, *compute.Snapshot) (*compute.Operation, error)
 	DeleteAccessConfig(ctx context.Context, project, zone, instance, accessConfig, networkInterface string) (*compute.Operation, error)
@@ -35,7 +37,10 @@ type ComputeClient interface {
 	DeleteInstance(context.Context, string, string, string) (*compute.Operation, error)
 	GetInstance(ctx context.Context, project, zone, instance string) (*compute.Instance, error)
 	ListDisks(context.Context, string, string) (*compute.DiskList, error)
+	ListInstances(context.Context, string) ([]*compute.Instance, error)
 	ListProjectSnapshots(context.Context, string) (*compute.SnapshotList, error)
+	SetInstanceLabels(context.Context, string, string, string, *compute.InstancesSetLabelsRequest) (*compute.Operation, error)
+	SetInstanceTags(context.Context, string, string, string, *compute.Tags) (*compute.Operation, error)
 	SetLabels(context.Context, string, string, *compute.GlobalSetLabelsRequest) (*compute.Operation, error)
 	StartInstance(context.Context, string, string, string) (*compute.Operation, error)
 	StopInstance(context.Context, string, string, string) (*compute.Operation, error)
@@ -65,6 +70,22 @@ func (h *Host) DeleteDiskSnapshot(ctx context.Context, projectID, snapshot strin
 	return nil
 }
 
+// HasExternalIPs reports whether the instance has an external IP address RemoveExternalIPs would remove.
+func (h *Host) HasExternalIPs(ctx context.Context, project, zone, instance string) (bool, error) {
+	i, err := h.client.GetInstance(ctx, project, zone, instance)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get instance %q", instance)
+	}
+	for _, ni := range i.NetworkInterfaces {
+		for _, ac := range ni.AccessConfigs {
+			if ac.Type == "ONE_TO_ONE_NAT" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 // RemoveExternalIPs iterates on all network interfaces of an instance and deletes its accessConfigs, actually removing the external IP addresses of the instance.
 func (h *Host) RemoveExternalIPs(ctx context.Context, project, zone, instance string) error {
 	i, err := h.client.GetInstance(ctx, project, zone, instance)
@@ -136,6 +157,67 @@ func (h *Host) CopyDiskSnapshot(ctx context.Context, srcProjectID, dstProjectID,
 	return nil
 }
 
+// AttachDisk attaches a disk to an instance in read only mode, so evidence can be examined without being altered.
+func (h *Host) AttachDisk(ctx context.Context, projectID, zone, instance, disk string) error {
+	op, err := h.client.AttachDisk(ctx, projectID, zone, instance, &compute.AttachedDisk{
+		Source: fmt.Sprintf("projects/%s/zones/%s/disks/%s", projectID, zone, disk),
+		Mode:   "READ_ONLY",
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to attach disk %q to %q", disk, instance)
+	}
+	if errs := h.WaitZone(projectID, zone, op); len(errs) > 0 {
+		return errors.Wrap(errs[0], "failed waiting: first error")
+	}
+	return nil
+}
+
+// AttachSnapshot creates a disk from a snapshot in the project of the instance and attaches it to
+// the instance in read only mode. The name of the created disk is returned.
+func (h *Host) AttachSnapshot(ctx context.Context, srcProjectID, snapshot, projectID, zone, instance string) (string, error) {
+	disk := fmt.Sprintf("%s-%d", snapshot, time.Now().Unix())
+	op, err := h.client.DiskInsert(ctx, projectID, zone, &compute.Disk{
+		Name:           disk,
+		SourceSnapshot: fmt.Sprintf("projects/%s/global/snapshots/%s", srcProjectID, snapshot),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create disk from snapshot %q", snapshot)
+	}
+	if errs := h.WaitZone(projectID, zone, op); len(errs) > 0 {
+		return "", errors.Wrap(errs[0], "failed waiting: first error")
+	}
+	if err := h.AttachDisk(ctx, projectID, zone, instance, disk); err != nil {
+		return "", err
+	}
+	return disk, nil
+}
+
+// DetachDisk detaches a disk from an instance, looking up the device name it is attached under.
+func (h *Host) DetachDisk(ctx context.Context, projectID, zone, instance, disk string) error {
+	i, err := h.client.GetInstance(ctx, projectID, zone, instance)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get instance %q", instance)
+	}
+	deviceName := ""
+	for _, d := range i.Disks {
+		if strings.HasSuffix(d.Source, "/disks/"+disk) {
+			deviceName = d.DeviceName
+			break
+		}
+	}
+	if deviceName == "" {
+		return fmt.Errorf("disk %q is not attached to %q", disk, instance)
+	}
+	op, err := h.client.DetachDisk(ctx, projectID, zone, instance, deviceName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to detach disk %q from %q", disk, instance)
+	}
+	if errs := h.WaitZone(projectID, zone, op); len(errs) > 0 {
+		return errors.Wrap(errs[0], "failed waiting: first error")
+	}
+	return nil
+}
+
 // ListProjectSnapshots returns a list of snapshots.
 func (h *Host) ListProjectSnapshots(ctx context.Context, projectID string) (*compute.SnapshotList, error) {
 	return h.client.ListProjectSnapshots(ctx, projectID)
@@ -199,6 +281,30 @@ func (h *Host) diskBelongsToInstance(disks *compute.Disk, instance string) bool
 	return false
 }
 
+// LabelInstance adds the labels to the instance, keeping its other labels. It reports whether the
+// instance was missing any of them.
+func (h *Host) LabelInstance(ctx context.Context, projectID, zone, instance string, labels map[string]string) (bool, error) {
+	i, err := h.client.GetInstance(ctx, projectID, zone, instance)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get instance %q", instance)
+	}
+	merged, changed := mergeLabels(i.Labels, labels)
+	if !changed {
+		return false, nil
+	}
+	op, err := h.client.SetInstanceLabels(ctx, projectID, zone, instance, &compute.InstancesSetLabelsRequest{
+		Labels:           merged,
+		LabelFingerprint: i.LabelFingerprint,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to set labels of instance %q", instance)
+	}
+	if errs := h.WaitZone(projectID, zone, op); len(errs) > 0 {
+		return false, errors.Wrap(errs[0], "failed waiting: first error")
+	}
+	return true, nil
+}
+
 // StopInstance stops the provided instance.
 func (h *Host) StopInstance(ctx context.Context, projectID, zone, instance string) error {
 	op, err := h.client.StopInstance(ctx, projectID, zone, instance)
@@ -227,3 +333,54 @@ func (h *Host) StartInstance(ctx context.Context, projectID, zone, instance stri
 func (h *Host) DeleteInstance(ctx context.Context, projectID, zone, instance string) (*compute.Operation, error) {
 	return h.client.DeleteInstance(ctx, projectID, zone, instance)
 }
+
+// Instance returns the instance.
+func (h *Host) Instance(ctx context.Context, projectID, zone, instance string) (*compute.Instance, error) {
+	i, err := h.client.GetInstance(ctx, projectID, zone, instance)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get instance %q", instance)
+	}
+	return i, nil
+}
+
+// InstanceByInternalIP returns the instance of the project with a network interface using the given internal IP.
+func (h *Host) InstanceByInternalIP(ctx context.Context, projectID, ip string) (*compute.Instance, error) {
+	instances, err := h.client.ListInstances(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list instances in %q", projectID)
+	}
+	for _, instance := range instances {
+		for _, nic := range instance.NetworkInterfaces {
+			if nic.NetworkIP == ip {
+				return instance, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no instance with internal ip %q in %q", ip, projectID)
+}
+
+// AddInstanceTag adds the network tag to the instance if not already present.
+func (h *Host) AddInstanceTag(ctx context.Context, projectID, zone, instance, tag string) error {
+	i, err := h.Instance(ctx, projectID, zone, instance)
+	if err != nil {
+		return err
+	}
+	tags := i.Tags
+	if tags == nil {
+		tags = &compute.Tags{}
+	}
+	for _, t := range tags.Items {
+		if t == tag {
+			return nil
+		}
+	}
+	tags.Items = append(tags.Items, tag)
+	op, err := h.client.SetInstanceTags(ctx, projectID, zone, instance, tags)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set tags on instance %q", instance)
+	}
+	if errs := h.WaitZone(projectID, zone, op); len(errs) > 0 {
+		return fmt.Errorf("failed waiting to tag instance. Errors[0]: %s", errs[0])
+	}
+	return nil
+}
@@ -217,3 +217,61 @@ func TestRemoveExternalIPFromInstanceNetworkInterfacesFailing(t *testing.T) {
 		})
 	}
 }
+
+func TestDetachDisk(t *testing.T) {
+	const (
+		project  = "test-project"
+		zone     = "test-zone"
+		instance = "test-instance"
+	)
+	tests := []struct {
+		name            string
+		disk            string
+		expectedDevices []string
+		expectedError   bool
+	}{
+		{name: "detach attached disk", disk: "data-disk", expectedDevices: []string{"persistent-disk-1"}},
+		{name: "disk not attached", disk: "other-disk", expectedError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			computeStub := &stubs.ComputeStub{
+				StubbedInstance: &compute.Instance{Disks: []*compute.AttachedDisk{
+					{DeviceName: "persistent-disk-0", Source: "projects/test-project/zones/test-zone/disks/boot-disk"},
+					{DeviceName: "persistent-disk-1", Source: "projects/test-project/zones/test-zone/disks/data-disk"},
+				}},
+			}
+			host := NewHost(computeStub)
+			if err := host.DetachDisk(ctx, project, zone, instance, tt.disk); (err != nil) != tt.expectedError {
+				t.Errorf("%v failed, err: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expectedDevices, computeStub.DetachedDevices); diff != "" {
+				t.Errorf("%v failed, difference: %+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestAttachSnapshot(t *testing.T) {
+	ctx := context.Background()
+	computeStub := &stubs.ComputeStub{}
+	host := NewHost(computeStub)
+	disk, err := host.AttachSnapshot(ctx, "source-project", "evidence", "forensics-project", "test-zone", "forensics-instance")
+	if err != nil {
+		t.Fatalf("failed to attach snapshot: %q", err)
+	}
+	if len(computeStub.InsertedDisks) != 1 || computeStub.InsertedDisks[0].Name != disk {
+		t.Fatalf("created disks %+v, want %q", computeStub.InsertedDisks, disk)
+	}
+	if got, want := computeStub.InsertedDisks[0].SourceSnapshot, "projects/source-project/global/snapshots/evidence"; got != want {
+		t.Errorf("source snapshot got %q, want %q", got, want)
+	}
+	want := []*compute.AttachedDisk{{
+		Source: "projects/forensics-project/zones/test-zone/disks/" + disk,
+		Mode:   "READ_ONLY",
+	}}
+	if diff := cmp.Diff(want, computeStub.AttachedDisks); diff != "" {
+		t.Errorf("attached disks difference: %+v", diff)
+	}
+}
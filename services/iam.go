@@ -0,0 +1,57 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	iam "google.golang.org/api/iam/v1"
+)
+
+// IAMClient holds the minimum interface required by the IAM service.
+type IAMClient interface {
+	ListServiceAccountKeys(context.Context, string) ([]*iam.ServiceAccountKey, error)
+	DisableServiceAccountKey(context.Context, string) error
+}
+
+// IAM service managing service accounts.
+type IAM struct {
+	client IAMClient
+}
+
+// NewIAM returns an IAM service.
+func NewIAM(client IAMClient) *IAM {
+	return &IAM{client: client}
+}
+
+// DisableKeys disables the user-managed keys of the service account and returns the names of the
+// keys disabled. Disabled keys can be enabled again.
+func (i *IAM) DisableKeys(ctx context.Context, email string) ([]string, error) {
+	keys, err := i.client.ListServiceAccountKeys(ctx, "projects/-/serviceAccounts/"+email)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list keys of %q", email)
+	}
+	var disabled []string
+	for _, k := range keys {
+		if err := i.client.DisableServiceAccountKey(ctx, k.Name); err != nil {
+			return disabled, errors.Wrapf(err, "failed to disable key %q", k.Name)
+		}
+		disabled = append(disabled, k.Name)
+	}
+	sort.Strings(disabled)
+	return disabled, nil
+}
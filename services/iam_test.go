@@ -0,0 +1,42 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	iam "google.golang.org/api/iam/v1"
+)
+
+func TestDisableKeys(t *testing.T) {
+	const name = "projects/-/serviceAccounts/sa@test-project.iam.gserviceaccount.com"
+	stub := &stubs.IAMStub{Keys: map[string][]*iam.ServiceAccountKey{
+		name: {{Name: name + "/keys/2"}, {Name: name + "/keys/1"}},
+	}}
+	disabled, err := NewIAM(stub).DisableKeys(context.Background(), "sa@test-project.iam.gserviceaccount.com")
+	if err != nil {
+		t.Fatalf("failed to disable keys: %q", err)
+	}
+	want := []string{name + "/keys/1", name + "/keys/2"}
+	if diff := cmp.Diff(want, disabled); diff != "" {
+		t.Errorf("disabled keys (-want +got):\n%s", diff)
+	}
+	if len(stub.DisabledKeys) != 2 {
+		t.Errorf("got %d keys disabled, want 2", len(stub.DisabledKeys))
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/googlecloudplatform/security-response-automation/clients"
 )
@@ -14,56 +15,136 @@ type Global struct {
 	Host                  *Host
 	Firewall              *Firewall
 	Container             *Container
+	Kubernetes            *Kubernetes
 	CloudSQL              *CloudSQL
 	SecurityCommandCenter *CommandCenter
+	OrgPolicy             *OrgPolicy
+	KMS                   *KMS
+	SecretManager         *SecretManager
+	ServiceUsage          *ServiceUsage
+	Billing               *Billing
 }
 
+// Service initializes one of the services of a Global, such as WithHost.
+type Service func(context.Context, *Global) error
+
 // New returns an initialized Global struct.
 func New(ctx context.Context) (*Global, error) {
-	host, err := initHost(ctx)
-	if err != nil {
+	return NewFor(ctx, WithHost, WithResource, WithFirewall, WithContainer, WithKubernetes, WithCloudSQL,
+		WithSecurityCommandCenter, WithOrgPolicy, WithKMS, WithSecretManager, WithServiceUsage, WithBilling)
+}
+
+// NewFor returns a Global struct with the Logger and the given services initialized, such as those
+// an action runs with. Binaries that do not call New only link the clients of the services they
+// name.
+func NewFor(ctx context.Context, with ...Service) (*Global, error) {
+	g := &Global{}
+	var err error
+	if g.Logger, err = initLog(ctx); err != nil {
 		return nil, err
 	}
+	for _, w := range with {
+		if err := w(ctx, g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
 
-	log, err := initLog(ctx)
-	if err != nil {
-		return nil, err
+// WithHost initializes the Host service, unless it already is.
+func WithHost(ctx context.Context, g *Global) (err error) {
+	if g.Host == nil {
+		g.Host, err = initHost(ctx)
 	}
+	return err
+}
 
-	res, err := initResource(ctx)
-	if err != nil {
-		return nil, err
+// WithResource initializes the Resource service, unless it already is.
+func WithResource(ctx context.Context, g *Global) (err error) {
+	if g.Resource == nil {
+		g.Resource, err = InitResource(ctx)
 	}
+	return err
+}
 
-	fw, err := initFirewall(ctx)
-	if err != nil {
-		return nil, err
+// WithFirewall initializes the Firewall service, unless it already is.
+func WithFirewall(ctx context.Context, g *Global) (err error) {
+	if g.Firewall == nil {
+		g.Firewall, err = initFirewall(ctx)
 	}
+	return err
+}
 
-	cont, err := initContainer(ctx)
-	if err != nil {
-		return nil, err
+// WithContainer initializes the Container service, unless it already is.
+func WithContainer(ctx context.Context, g *Global) (err error) {
+	if g.Container == nil {
+		g.Container, err = initContainer(ctx)
 	}
+	return err
+}
 
-	sql, err := initCloudSQL(ctx)
-	if err != nil {
-		return nil, err
+// WithKubernetes initializes the Kubernetes service, unless it already is.
+func WithKubernetes(ctx context.Context, g *Global) (err error) {
+	if g.Kubernetes == nil {
+		g.Kubernetes, err = initKubernetes(ctx)
 	}
+	return err
+}
 
-	scc, err := initSecurityCommandCenter(ctx)
-	if err != nil {
-		return nil, err
+// WithCloudSQL initializes the CloudSQL service, unless it already is.
+func WithCloudSQL(ctx context.Context, g *Global) (err error) {
+	if g.CloudSQL == nil {
+		g.CloudSQL, err = initCloudSQL(ctx)
+	}
+	return err
+}
+
+// WithSecurityCommandCenter initializes the SecurityCommandCenter service, unless it already is.
+func WithSecurityCommandCenter(ctx context.Context, g *Global) (err error) {
+	if g.SecurityCommandCenter == nil {
+		g.SecurityCommandCenter, err = initSecurityCommandCenter(ctx)
+	}
+	return err
+}
+
+// WithOrgPolicy initializes the OrgPolicy service, unless it already is.
+func WithOrgPolicy(ctx context.Context, g *Global) (err error) {
+	if g.OrgPolicy == nil {
+		g.OrgPolicy, err = initOrgPolicy(ctx)
+	}
+	return err
+}
+
+// WithKMS initializes the KMS service, unless it already is.
+func WithKMS(ctx context.Context, g *Global) (err error) {
+	if g.KMS == nil {
+		g.KMS, err = initKMS(ctx)
+	}
+	return err
+}
+
+// WithSecretManager initializes the SecretManager service, unless it already is.
+func WithSecretManager(ctx context.Context, g *Global) (err error) {
+	if g.SecretManager == nil {
+		g.SecretManager, err = initSecretManager(ctx)
+	}
+	return err
+}
+
+// WithServiceUsage initializes the ServiceUsage service, unless it already is.
+func WithServiceUsage(ctx context.Context, g *Global) (err error) {
+	if g.ServiceUsage == nil {
+		g.ServiceUsage, err = initServiceUsage(ctx)
 	}
+	return err
+}
 
-	return &Global{
-		Host:                  host,
-		Logger:                log,
-		Resource:              res,
-		Firewall:              fw,
-		Container:             cont,
-		CloudSQL:              sql,
-		SecurityCommandCenter: scc,
-	}, nil
+// WithBilling initializes the Billing service, unless it already is.
+func WithBilling(ctx context.Context, g *Global) (err error) {
+	if g.Billing == nil {
+		g.Billing, err = initBilling(ctx)
+	}
+	return err
 }
 
 // InitPagerDuty creates and initializes a new instance of PagerDuty.
@@ -81,6 +162,174 @@ func InitBigQuery(ctx context.Context, projectID string) (*BigQuery, error) {
 	return NewBigQuery(bq), nil
 }
 
+// InitContainerAnalysis creates and initializes a new instance of ContainerAnalysis.
+func InitContainerAnalysis(ctx context.Context) (*ContainerAnalysis, error) {
+	ca, err := clients.NewContainerAnalysis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize container analysis client: %q", err)
+	}
+	return NewContainerAnalysis(ca), nil
+}
+
+// InitDirectory creates and initializes a new instance of Directory.
+func InitDirectory(ctx context.Context) (*Directory, error) {
+	d, err := clients.NewDirectory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize directory client: %q", err)
+	}
+	return NewDirectory(d), nil
+}
+
+// InitCloudIdentity creates and initializes a new instance of CloudIdentity.
+func InitCloudIdentity(ctx context.Context) (*CloudIdentity, error) {
+	c, err := clients.NewCloudIdentity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloud identity client: %q", err)
+	}
+	return NewCloudIdentity(c), nil
+}
+
+// InitIAM creates and initializes a new instance of IAM.
+func InitIAM(ctx context.Context) (*IAM, error) {
+	i, err := clients.NewIAM(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize iam client: %q", err)
+	}
+	return NewIAM(i), nil
+}
+
+// InitAccessContextManager creates and initializes a new instance of AccessContextManager.
+func InitAccessContextManager(ctx context.Context) (*AccessContextManager, error) {
+	a, err := clients.NewAccessContextManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize access context manager client: %q", err)
+	}
+	return NewAccessContextManager(a), nil
+}
+
+// InitRecommender creates and initializes a new instance of Recommender.
+func InitRecommender(ctx context.Context) (*Recommender, error) {
+	r, err := clients.NewRecommender(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize recommender client: %q", err)
+	}
+	return NewRecommender(r), nil
+}
+
+// InitEmail creates and initializes a new instance of Email sending through SendGrid.
+func InitEmail(apiKey string) *Email {
+	return NewEmail(clients.NewSendGridClient(apiKey))
+}
+
+// InitChat creates and initializes a new instance of Chat posting to the incoming webhook URL.
+func InitChat(webhook string) *Chat {
+	return NewChat(clients.NewChat(), webhook)
+}
+
+// InitAWS creates and initializes a new instance of AWS for the region.
+func InitAWS(region string) (*AWS, error) {
+	aws, err := clients.NewAWS(region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize aws client: %q", err)
+	}
+	return NewAWS(aws), nil
+}
+
+// InitSNS creates and initializes a new instance of SNS.
+func InitSNS() *SNS {
+	return NewSNS(clients.NewSNS())
+}
+
+// InitPolicy creates and initializes a new instance of Policy.
+func InitPolicy(ctx context.Context) (*Policy, error) {
+	s, err := clients.NewStorage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage client: %q", err)
+	}
+	return NewPolicy(s), nil
+}
+
+// InitArchive creates and initializes a new instance of Archive storing payloads in the bucket.
+func InitArchive(ctx context.Context, bucket string) (*Archive, error) {
+	s, err := clients.NewStorage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage client: %q", err)
+	}
+	return NewArchive(s, bucket), nil
+}
+
+// InitSecrets creates and initializes a new instance of Secrets, secrets are cached for five minutes.
+func InitSecrets(ctx context.Context) (*Secrets, error) {
+	sm, err := clients.NewSecretManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize secret manager client: %q", err)
+	}
+	return NewSecrets(sm, 5*time.Minute), nil
+}
+
+// InitState creates and initializes a new instance of State using the collection of the project.
+func InitState(ctx context.Context, projectID, collection string) (*State, error) {
+	fs, err := clients.NewFirestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize firestore client: %q", err)
+	}
+	return NewState(fs, projectID, collection), nil
+}
+
+// InitErrorReporting creates and initializes a new instance of ErrorReporting reporting to the project.
+func InitErrorReporting(ctx context.Context, projectID string) (*ErrorReporting, error) {
+	er, err := clients.NewErrorReporting(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize error reporting client: %q", err)
+	}
+	return NewErrorReporting(er, projectID), nil
+}
+
+// InitLogs creates and initializes a new instance of Logs.
+func InitLogs(ctx context.Context) (*Logs, error) {
+	l, err := clients.NewLogEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logging client: %q", err)
+	}
+	return NewLogs(l), nil
+}
+
+// InitBreaker creates and initializes a new instance of Breaker using the collection of the project.
+func InitBreaker(ctx context.Context, projectID, collection string, threshold int, cooldown time.Duration) (*Breaker, error) {
+	fs, err := clients.NewFirestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize firestore client: %q", err)
+	}
+	return NewBreaker(fs, projectID, collection, threshold, cooldown), nil
+}
+
+// InitThrottle creates and initializes a new instance of Throttle using the collection of the project.
+func InitThrottle(ctx context.Context, projectID, collection string) (*Throttle, error) {
+	fs, err := clients.NewFirestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize firestore client: %q", err)
+	}
+	return NewThrottle(fs, projectID, collection), nil
+}
+
+// InitCheckpoints creates and initializes a new instance of Checkpoints using the collection of the project.
+func InitCheckpoints(ctx context.Context, projectID, collection string) (*Checkpoints, error) {
+	fs, err := clients.NewFirestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize firestore client: %q", err)
+	}
+	return NewCheckpoints(fs, projectID, collection), nil
+}
+
+// InitTasks creates and initializes a new instance of Tasks using the queue.
+func InitTasks(ctx context.Context, queue, projectID, serviceAccount string) (*Tasks, error) {
+	ct, err := clients.NewCloudTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloud tasks client: %q", err)
+	}
+	return NewTasks(ct, queue, projectID, serviceAccount), nil
+}
+
 // InitPubSub creates and initializes a new instance of PubSub.
 func InitPubSub(ctx context.Context, projectID string) (*PubSub, error) {
 	pubsub, err := clients.NewPubSub(ctx, projectID)
@@ -106,7 +355,8 @@ func initLog(ctx context.Context) (*Logger, error) {
 	return NewLogger(logClient), nil
 }
 
-func initResource(ctx context.Context) (*Resource, error) {
+// InitResource creates and initializes a new instance of Resource.
+func InitResource(ctx context.Context) (*Resource, error) {
 	crm, err := clients.NewCloudResourceManager(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize cloud resource manager client: %q", err)
@@ -134,6 +384,14 @@ func initContainer(ctx context.Context) (*Container, error) {
 	return NewContainer(cc), nil
 }
 
+func initKubernetes(ctx context.Context) (*Kubernetes, error) {
+	kc, err := clients.NewKubernetes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kubernetes client: %q", err)
+	}
+	return NewKubernetes(kc), nil
+}
+
 func initCloudSQL(ctx context.Context) (*CloudSQL, error) {
 	cs, err := clients.NewCloudSQL(ctx)
 	if err != nil {
@@ -149,3 +407,56 @@ func initSecurityCommandCenter(ctx context.Context) (*CommandCenter, error) {
 	}
 	return NewCommandCenter(scc), nil
 }
+
+func initOrgPolicy(ctx context.Context) (*OrgPolicy, error) {
+	op, err := clients.NewOrgPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize org policy client: %q", err)
+	}
+	return NewOrgPolicy(op), nil
+}
+
+func initKMS(ctx context.Context) (*KMS, error) {
+	k, err := clients.NewKMS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kms client: %q", err)
+	}
+	return NewKMS(k), nil
+}
+
+func initSecretManager(ctx context.Context) (*SecretManager, error) {
+	sm, err := clients.NewSecretManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize secret manager client: %q", err)
+	}
+	return NewSecretManager(sm), nil
+}
+
+func initServiceUsage(ctx context.Context) (*ServiceUsage, error) {
+	su, err := clients.NewServiceUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize service usage client: %q", err)
+	}
+	return NewServiceUsage(su), nil
+}
+
+func initBilling(ctx context.Context) (*Billing, error) {
+	cb, err := clients.NewCloudBilling(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloud billing client: %q", err)
+	}
+	return NewBilling(cb), nil
+}
+
+// InitPreflight creates and initializes a new instance of Preflight.
+func InitPreflight(ctx context.Context) (*Preflight, error) {
+	crm, err := clients.NewCloudResourceManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloud resource manager client: %q", err)
+	}
+	su, err := clients.NewServiceUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize service usage client: %q", err)
+	}
+	return NewPreflight(crm, su), nil
+}
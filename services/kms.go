@@ -0,0 +1,186 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+// KMSClient holds the minimum interface required by the KMS service.
+type KMSClient interface {
+	GetCryptoKey(context.Context, string) (*cloudkms.CryptoKey, error)
+	PatchCryptoKey(context.Context, string, string, *cloudkms.CryptoKey) (*cloudkms.CryptoKey, error)
+	GetIamPolicy(context.Context, string) (*cloudkms.Policy, error)
+	SetIamPolicy(context.Context, string, *cloudkms.Policy) (*cloudkms.Policy, error)
+}
+
+// KMS Service.
+type KMS struct {
+	client KMSClient
+}
+
+// NewKMS returns a new KMS service.
+func NewKMS(client KMSClient) *KMS {
+	return &KMS{client: client}
+}
+
+// RotationPeriod returns how often the crypto key, named
+// "projects/<id>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>", is rotated, zero if it is
+// not rotated automatically.
+func (k *KMS) RotationPeriod(ctx context.Context, name string) (time.Duration, error) {
+	key, err := k.client.GetCryptoKey(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	if key.RotationPeriod == "" {
+		return 0, nil
+	}
+	// Durations are returned in seconds, such as "7776000s".
+	d, err := time.ParseDuration(key.RotationPeriod)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse rotation period %q: %q", key.RotationPeriod, err)
+	}
+	return d, nil
+}
+
+// SetRotation rotates the crypto key every period from the next rotation on. Only symmetric keys
+// can be rotated automatically.
+func (k *KMS) SetRotation(ctx context.Context, name string, period time.Duration, next time.Time) error {
+	_, err := k.client.PatchCryptoKey(ctx, name, "rotationPeriod,nextRotationTime", &cloudkms.CryptoKey{
+		RotationPeriod:   fmt.Sprintf("%ds", int64(period/time.Second)),
+		NextRotationTime: next.UTC().Format(time.RFC3339),
+	})
+	return err
+}
+
+// ExternalMembers returns the members of the IAM policy of the crypto key or key ring, named
+// "projects/<id>/locations/<location>/keyRings/<ring>", granting access to anyone: allUsers and
+// allAuthenticatedUsers. Users, groups and domains outside the allowed domains are returned too
+// if there are any, service accounts are not.
+func (k *KMS) ExternalMembers(ctx context.Context, resource string, allowDomains []string) ([]string, error) {
+	p, err := k.client.GetIamPolicy(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	return externalMembers(kmsBindings(p), allowDomains), nil
+}
+
+// RemoveMembers removes the members from every binding of the IAM policy of the crypto key or key
+// ring. The grants removed are logged as a change, so they can be granted again.
+func (k *KMS) RemoveMembers(ctx context.Context, resource string, members []string) error {
+	kind := keyRingPolicyKind
+	if strings.Contains(resource, "/cryptoKeys/") {
+		kind = cryptoKeyPolicyKind
+	}
+	return updatePolicy(func() error {
+		p, err := k.client.GetIamPolicy(ctx, resource)
+		if err != nil {
+			return err
+		}
+		before := bindingGrants(kmsBindings(p))
+		var bindings []*cloudkms.Binding
+		for _, b := range p.Bindings {
+			if b.Members = withoutMembers(b.Members, members); len(b.Members) > 0 {
+				bindings = append(bindings, b)
+			}
+		}
+		p.Bindings = bindings
+		if _, err := k.client.SetIamPolicy(ctx, resource, p); err != nil {
+			return err
+		}
+		logChange(ctx, policyChange(kind, resource, before, bindingGrants(kmsBindings(p))))
+		return nil
+	})
+}
+
+// external reports whether the member grants public access or, with allowed domains, is a user,
+// group or domain outside them.
+func external(member string, allowDomains []string) bool {
+	switch {
+	case member == "allUsers", member == "allAuthenticatedUsers":
+		return true
+	case len(allowDomains) == 0:
+		return false
+	case strings.HasPrefix(member, "user:"), strings.HasPrefix(member, "group:"):
+		return !containsFold(allowDomains, member[strings.LastIndex(member, "@")+1:])
+	case strings.HasPrefix(member, "domain:"):
+		return !containsFold(allowDomains, strings.TrimPrefix(member, "domain:"))
+	}
+	return false
+}
+
+// binding is a binding of an IAM policy, whichever API the policy is from, so members are filtered
+// and grants read the same way for all of them.
+type binding struct {
+	role      string
+	condition string
+	members   []string
+}
+
+// externalMembers returns the members of the bindings granting access to anyone or, with allowed
+// domains, outside them. Members are returned once however many roles they are granted.
+func externalMembers(bindings []binding, allowDomains []string) []string {
+	var members []string
+	seen := make(map[string]bool)
+	for _, b := range bindings {
+		for _, m := range b.members {
+			if !seen[m] && external(m, allowDomains) {
+				members = append(members, m)
+			}
+			seen[m] = true
+		}
+	}
+	return members
+}
+
+// withoutMembers returns the members of a binding kept once the members removed are taken out.
+func withoutMembers(members, removed []string) []string {
+	var kept []string
+	for _, m := range members {
+		if !containsFold(removed, m) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// bindingGrants returns the grants of the bindings, read before the policy is modified in place.
+func bindingGrants(bindings []binding) []Grant {
+	var grants []Grant
+	for _, b := range bindings {
+		for _, m := range b.members {
+			grants = append(grants, Grant{Role: b.role, Member: m, Condition: b.condition})
+		}
+	}
+	return grants
+}
+
+// kmsBindings returns the bindings of a Cloud KMS policy.
+func kmsBindings(policy *cloudkms.Policy) []binding {
+	var bindings []binding
+	for _, b := range policy.Bindings {
+		condition := ""
+		if b.Condition != nil {
+			condition = b.Condition.Expression
+		}
+		bindings = append(bindings, binding{role: b.Role, condition: condition, members: b.Members})
+	}
+	return bindings
+}
@@ -0,0 +1,121 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+const testKey = "projects/test-project/locations/global/keyRings/ring/cryptoKeys/key"
+
+func TestRotationPeriod(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name   string
+		period string
+		want   time.Duration
+	}{
+		{name: "not rotated", want: 0},
+		{name: "rotated", period: "7776000s", want: 90 * 24 * time.Hour},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			kmsStub := &stubs.KMSStub{StubbedKeys: map[string]*cloudkms.CryptoKey{
+				testKey: {Name: testKey, RotationPeriod: tt.period},
+			}}
+			got, err := NewKMS(kmsStub).RotationPeriod(ctx, testKey)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s got %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRotation(t *testing.T) {
+	ctx := context.Background()
+	kmsStub := &stubs.KMSStub{}
+	next := time.Date(2020, 6, 2, 18, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	if err := NewKMS(kmsStub).SetRotation(ctx, testKey, 30*24*time.Hour, next); err != nil {
+		t.Fatalf("SetRotation failed: %q", err)
+	}
+	want := &cloudkms.CryptoKey{RotationPeriod: "2592000s", NextRotationTime: "2020-06-02T16:00:00Z"}
+	if diff := cmp.Diff(want, kmsStub.SavedKey); diff != "" {
+		t.Errorf("SetRotation key (-want +got):\n%s", diff)
+	}
+	if kmsStub.SavedKeyName != testKey || kmsStub.SavedUpdateMask != "rotationPeriod,nextRotationTime" {
+		t.Errorf("SetRotation patched %q with %q", kmsStub.SavedKeyName, kmsStub.SavedUpdateMask)
+	}
+}
+
+func TestKMSExternalMembers(t *testing.T) {
+	ctx := context.Background()
+	policy := &cloudkms.Policy{Bindings: []*cloudkms.Binding{
+		{Role: "roles/cloudkms.cryptoKeyEncrypterDecrypter", Members: []string{"allUsers", "user:alice@example.com", "user:mallory@attacker.com"}},
+		{Role: "roles/cloudkms.viewer", Members: []string{"allAuthenticatedUsers", "domain:attacker.com", "serviceAccount:sa@test-project.iam.gserviceaccount.com"}},
+	}}
+	for _, tt := range []struct {
+		name         string
+		allowDomains []string
+		want         []string
+	}{
+		{name: "public", want: []string{"allUsers", "allAuthenticatedUsers"}},
+		{name: "external", allowDomains: []string{"example.com"}, want: []string{"allUsers", "user:mallory@attacker.com", "allAuthenticatedUsers", "domain:attacker.com"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			kmsStub := &stubs.KMSStub{StubbedPolicies: map[string]*cloudkms.Policy{testKey: policy}}
+			got, err := NewKMS(kmsStub).ExternalMembers(ctx, testKey, tt.allowDomains)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s members (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestKMSRemoveMembers(t *testing.T) {
+	ctx := context.Background()
+	kmsStub := &stubs.KMSStub{StubbedPolicies: map[string]*cloudkms.Policy{testKey: {
+		Etag: "etag-1",
+		Bindings: []*cloudkms.Binding{
+			{Role: "roles/cloudkms.cryptoKeyEncrypterDecrypter", Members: []string{"allUsers", "user:alice@example.com"}},
+			{Role: "roles/cloudkms.viewer", Members: []string{"allAuthenticatedUsers"}},
+		},
+	}}}
+	if err := NewKMS(kmsStub).RemoveMembers(ctx, testKey, []string{"allUsers", "allAuthenticatedUsers"}); err != nil {
+		t.Fatalf("RemoveMembers failed: %q", err)
+	}
+	want := &cloudkms.Policy{
+		Etag: "etag-1",
+		Bindings: []*cloudkms.Binding{
+			{Role: "roles/cloudkms.cryptoKeyEncrypterDecrypter", Members: []string{"user:alice@example.com"}},
+		},
+	}
+	if diff := cmp.Diff(want, kmsStub.SavedPolicy); diff != "" {
+		t.Errorf("RemoveMembers policy (-want +got):\n%s", diff)
+	}
+	if kmsStub.SavedResource != testKey {
+		t.Errorf("RemoveMembers set the policy of %q, want %q", kmsStub.SavedResource, testKey)
+	}
+}
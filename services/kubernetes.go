@@ -0,0 +1,120 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// quarantineLabel is the label added to isolated pods and selected by the quarantine network policy.
+	quarantineLabel = "sra-quarantine"
+	// quarantinePolicy is the name of the network policy that denies all traffic to and from isolated pods.
+	quarantinePolicy = "sra-quarantine"
+)
+
+// validName matches DNS-1123 subdomains, the format of node, namespace and pod names.
+var validName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// KubernetesClient holds the minimum interface required by the Kubernetes service.
+type KubernetesClient interface {
+	Patch(context.Context, string, string, string, string, []byte) error
+	Create(context.Context, string, string, string, string, []byte) error
+}
+
+// Kubernetes service.
+type Kubernetes struct {
+	client KubernetesClient
+}
+
+// NewKubernetes returns a new Kubernetes service.
+func NewKubernetes(client KubernetesClient) *Kubernetes {
+	return &Kubernetes{client: client}
+}
+
+// CordonNode marks the node as unschedulable so no new pods are placed on it.
+func (k *Kubernetes) CordonNode(ctx context.Context, projectID, location, clusterID, node string) error {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": true},
+	}
+	if err := validateNames(node); err != nil {
+		return err
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/v1/nodes/%s", node)
+	if err := k.client.Patch(ctx, projectID, location, clusterID, path, b); err != nil {
+		return errors.Wrapf(err, "failed to cordon node %q", node)
+	}
+	return nil
+}
+
+// IsolatePod labels the pod for quarantine and ensures a network policy denying all of its traffic exists.
+//
+// Network policy enforcement must be enabled on the cluster for the isolation to take effect.
+func (k *Kubernetes) IsolatePod(ctx context.Context, projectID, location, clusterID, namespace, pod string) error {
+	if err := validateNames(namespace, pod); err != nil {
+		return err
+	}
+	policy := map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "NetworkPolicy",
+		"metadata":   map[string]interface{}{"name": quarantinePolicy},
+		"spec": map[string]interface{}{
+			"podSelector": map[string]interface{}{
+				"matchLabels": map[string]string{quarantineLabel: "true"},
+			},
+			"policyTypes": []string{"Ingress", "Egress"},
+		},
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/apis/networking.k8s.io/v1/namespaces/%s/networkpolicies", namespace)
+	if err := k.client.Create(ctx, projectID, location, clusterID, path, b); err != nil {
+		return errors.Wrapf(err, "failed to create quarantine network policy in namespace %q", namespace)
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{quarantineLabel: "true"},
+		},
+	}
+	if b, err = json.Marshal(patch); err != nil {
+		return err
+	}
+	path = fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", namespace, pod)
+	if err := k.client.Patch(ctx, projectID, location, clusterID, path, b); err != nil {
+		return errors.Wrapf(err, "failed to label pod %q", pod)
+	}
+	return nil
+}
+
+// validateNames ensures names are safe to use as segments of an API path.
+func validateNames(names ...string) error {
+	for _, n := range names {
+		if len(n) > 253 || !validName.MatchString(n) {
+			return fmt.Errorf("invalid kubernetes object name %q", n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LabelSelector matches the labels of a resource. Selectors are written "key=value" or
+// "key!=value" to match the value of a label, "key" to require the label with any value and
+// "!key" to require its absence.
+type LabelSelector struct {
+	Key   string
+	Value string
+	// HasValue is true if the selector compares the label's value.
+	HasValue bool
+	// Negate inverts the selector.
+	Negate bool
+}
+
+// ParseLabelSelector parses a selector such as "sra-enforce=true".
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	var l LabelSelector
+	switch {
+	case strings.Contains(s, "!="):
+		kv := strings.SplitN(s, "!=", 2)
+		l = LabelSelector{Key: kv[0], Value: kv[1], HasValue: true, Negate: true}
+	case strings.Contains(s, "="):
+		kv := strings.SplitN(s, "=", 2)
+		l = LabelSelector{Key: kv[0], Value: kv[1], HasValue: true}
+	case strings.HasPrefix(s, "!"):
+		l = LabelSelector{Key: strings.TrimPrefix(s, "!"), Negate: true}
+	default:
+		l = LabelSelector{Key: s}
+	}
+	l.Key, l.Value = strings.TrimSpace(l.Key), strings.TrimSpace(l.Value)
+	if l.Key == "" || strings.ContainsAny(l.Key, "!= ") {
+		return LabelSelector{}, fmt.Errorf("invalid label selector %q", s)
+	}
+	return l, nil
+}
+
+// Matches reports whether the labels match the selector.
+func (l LabelSelector) Matches(labels map[string]string) bool {
+	v, ok := labels[l.Key]
+	match := ok
+	if l.HasValue {
+		match = ok && v == l.Value
+	}
+	return match != l.Negate
+}
+
+// ProjectLabels returns the labels of the project.
+func (r *Resource) ProjectLabels(ctx context.Context, projectID string) (map[string]string, error) {
+	p, err := r.Project(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get project %q", projectID)
+	}
+	return p.Labels, nil
+}
+
+// MatchesLabels reports whether the labels of the project match every selector.
+func (r *Resource) MatchesLabels(ctx context.Context, projectID string, selectors []string) (bool, error) {
+	if len(selectors) == 0 {
+		return true, nil
+	}
+	labels, err := r.ProjectLabels(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range selectors {
+		l, err := ParseLabelSelector(s)
+		if err != nil {
+			return false, err
+		}
+		if !l.Matches(labels) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// LabelProject adds the labels to the project, keeping its other labels. It reports whether the
+// project was missing any of them.
+func (r *Resource) LabelProject(ctx context.Context, projectID string, labels map[string]string) (bool, error) {
+	p, err := r.Project(ctx, projectID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get project %q", projectID)
+	}
+	merged, changed := mergeLabels(p.Labels, labels)
+	if !changed {
+		return false, nil
+	}
+	p.Labels = merged
+	if _, err := r.crm.UpdateProject(ctx, projectID, p); err != nil {
+		return false, errors.Wrapf(err, "failed to update project %q", projectID)
+	}
+	return true, nil
+}
+
+// LabelBucket adds the labels to the bucket, keeping its other labels. It reports whether the
+// bucket was missing any of them.
+func (r *Resource) LabelBucket(ctx context.Context, bucketName string, labels map[string]string) (bool, error) {
+	existing, err := r.storage.BucketLabels(ctx, bucketName)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get bucket %q", bucketName)
+	}
+	if _, changed := mergeLabels(existing, labels); !changed {
+		return false, nil
+	}
+	if err := r.storage.SetBucketLabels(ctx, bucketName, labels); err != nil {
+		return false, errors.Wrapf(err, "failed to set labels of bucket %q", bucketName)
+	}
+	return true, nil
+}
+
+// mergeLabels returns the existing labels with the given labels added and whether any of them was
+// missing or had another value.
+func mergeLabels(existing, labels map[string]string) (map[string]string, bool) {
+	merged := make(map[string]string, len(existing)+len(labels))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	changed := false
+	for k, v := range labels {
+		if old, ok := merged[k]; !ok || old != v {
+			changed = true
+		}
+		merged[k] = v
+	}
+	return merged, changed
+}
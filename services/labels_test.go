@@ -0,0 +1,94 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestMatchesLabels(t *testing.T) {
+	crmStub := &stubs.ResourceManagerStub{StubbedProjects: map[string]*crm.Project{
+		"test-project": {ProjectId: "test-project", Labels: map[string]string{"sra-enforce": "true", "env": "prod"}},
+	}}
+	r := NewResource(crmStub, &stubs.StorageStub{})
+	for _, tt := range []struct {
+		name        string
+		selectors   []string
+		expected    bool
+		expectError bool
+	}{
+		{name: "no selectors", expected: true},
+		{name: "value matches", selectors: []string{"sra-enforce=true"}, expected: true},
+		{name: "value differs", selectors: []string{"sra-enforce=false"}, expected: false},
+		{name: "not equal", selectors: []string{"env!=dev"}, expected: true},
+		{name: "exists", selectors: []string{"env"}, expected: true},
+		{name: "not exists", selectors: []string{"!legacy"}, expected: true},
+		{name: "all must match", selectors: []string{"sra-enforce=true", "env=dev"}, expected: false},
+		{name: "invalid selector", selectors: []string{"=true"}, expectError: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.MatchesLabels(context.Background(), "test-project", tt.selectors)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("%s got error %q, expectError:%t", tt.name, err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("%s got %t want %t", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLabelProject(t *testing.T) {
+	ctx := context.Background()
+	crmStub := &stubs.ResourceManagerStub{StubbedProjects: map[string]*crm.Project{
+		"test-project": {ProjectId: "test-project", Labels: map[string]string{"env": "prod"}},
+	}}
+	r := NewResource(crmStub, &stubs.StorageStub{})
+	labels := map[string]string{"security-incident": "abc123", "triage": "pending"}
+
+	changed, err := r.LabelProject(ctx, "test-project", labels)
+	if err != nil || !changed {
+		t.Fatalf("first labeling got changed:%t err:%q, want changed", changed, err)
+	}
+	want := map[string]string{"env": "prod", "security-incident": "abc123", "triage": "pending"}
+	if diff := cmp.Diff(want, crmStub.StubbedProjects["test-project"].Labels); diff != "" {
+		t.Errorf("labels difference: %+v", diff)
+	}
+	if changed, err := r.LabelProject(ctx, "test-project", labels); err != nil || changed {
+		t.Errorf("second labeling got changed:%t err:%q, want unchanged", changed, err)
+	}
+}
+
+func TestLabelBucket(t *testing.T) {
+	ctx := context.Background()
+	storageStub := &stubs.StorageStub{StubbedBucketLabels: map[string]string{"triage": "pending"}}
+	r := NewResource(&stubs.ResourceManagerStub{}, storageStub)
+
+	if changed, err := r.LabelBucket(ctx, "bucket", map[string]string{"triage": "pending"}); err != nil || changed {
+		t.Errorf("labeled bucket got changed:%t err:%q, want unchanged", changed, err)
+	}
+	labels := map[string]string{"security-incident": "abc123", "triage": "pending"}
+	if changed, err := r.LabelBucket(ctx, "bucket", labels); err != nil || !changed {
+		t.Errorf("unlabeled bucket got changed:%t err:%q, want changed", changed, err)
+	}
+	if diff := cmp.Diff(labels, storageStub.SavedBucketLabels); diff != "" {
+		t.Errorf("labels difference: %+v", diff)
+	}
+}
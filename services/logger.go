@@ -14,6 +14,11 @@ package services
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+import (
+	"fmt"
+	"strings"
+)
+
 // LoggerClient contains minimum interface required by the logger service.
 type LoggerClient interface {
 	Info(message string, a ...interface{})
@@ -23,9 +28,21 @@ type LoggerClient interface {
 	Close()
 }
 
+// CorrelationAttribute is the attribute of the messages sent for a finding holding its correlation
+// ID, which ties together the log entries, marks, records and events of everything done for it.
+const CorrelationAttribute = "correlationId"
+
+// SimulatedAttribute is the attribute of the messages of findings published by the simulator, set
+// to "true", which do not exist in Security Command Center.
+const SimulatedAttribute = "simulated"
+
 // Logger client.
 type Logger struct {
 	client LoggerClient
+	// correlationID is appended to the messages of a logger handling a finding.
+	correlationID string
+	// redactor scrubs sensitive material from the messages, none is if nil.
+	redactor *Redactor
 }
 
 // NewLogger initializes and returns a Logger struct.
@@ -33,26 +50,63 @@ func NewLogger(l LoggerClient) *Logger {
 	return &Logger{client: l}
 }
 
+// WithCorrelation returns a logger appending the correlation ID to its messages, which share the
+// client of l. The logger is returned as is if the ID is empty.
+func (l *Logger) WithCorrelation(id string) *Logger {
+	if id == "" {
+		return l
+	}
+	return &Logger{client: l.client, correlationID: id, redactor: l.redactor}
+}
+
+// WithRedactor returns a logger scrubbing the material matched by the redactor from its
+// messages, which shares the client and correlation ID of l.
+func (l *Logger) WithRedactor(r *Redactor) *Logger {
+	return &Logger{client: l.client, correlationID: l.correlationID, redactor: r}
+}
+
 // Info sends a message to the logger using info as the severity.
 func (l *Logger) Info(message string, a ...interface{}) {
+	message, a = l.format(message, a)
 	l.client.Info(message, a...)
 }
 
 // Warning sends a message to the logger using warning as the severity.
 func (l *Logger) Warning(message string, a ...interface{}) {
+	message, a = l.format(message, a)
 	l.client.Warning(message, a...)
 }
 
 // Error sends a message to the logger using error as the severity.
 func (l *Logger) Error(message string, a ...interface{}) {
+	message, a = l.format(message, a)
 	l.client.Error(message, a...)
 }
 
 // Debug sends a message to the logger using debug as the severity.
 func (l *Logger) Debug(message string, a ...interface{}) {
+	message, a = l.format(message, a)
 	l.client.Debug(message, a...)
 }
 
+// correlated appends the correlation ID to the message format, if the logger has one.
+func (l *Logger) correlated(message string) string {
+	if l.correlationID == "" {
+		return message
+	}
+	return message + " correlation_id=" + strings.ReplaceAll(l.correlationID, "%", "%%")
+}
+
+// format returns the message format and arguments sent to the client. Messages of a logger with a
+// redactor are formatted and redacted here so the arguments are scrubbed too.
+func (l *Logger) format(message string, a []interface{}) (string, []interface{}) {
+	message = l.correlated(message)
+	if l.redactor == nil {
+		return message, a
+	}
+	return "%s", []interface{}{l.redactor.Redact(fmt.Sprintf(message, a...))}
+}
+
 // Close buffer and send messages to stackdriver.
 func (l *Logger) Close() {
 	l.client.Close()
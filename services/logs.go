@@ -0,0 +1,61 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	cloudlogging "google.golang.org/api/logging/v2"
+)
+
+// LogsClient holds the minimum interface required by the logs service.
+type LogsClient interface {
+	ListLogEntries(context.Context, string, string, func([]*cloudlogging.LogEntry) error) error
+}
+
+// Logs service reads log entries from Cloud Logging.
+type Logs struct {
+	client LogsClient
+}
+
+// errEnoughEntries stops listing entries once enough were read.
+var errEnoughEntries = errors.New("enough entries")
+
+// NewLogs returns a new logs service.
+func NewLogs(client LogsClient) *Logs {
+	return &Logs{client: client}
+}
+
+// Entries returns at most max of the entries of the project matching the filter, oldest first, and
+// whether more entries matched.
+func (l *Logs) Entries(ctx context.Context, projectID, filter string, max int) ([]*cloudlogging.LogEntry, bool, error) {
+	var entries []*cloudlogging.LogEntry
+	truncated := false
+	err := l.client.ListLogEntries(ctx, projectID, filter, func(page []*cloudlogging.LogEntry) error {
+		for _, e := range page {
+			if len(entries) == max {
+				truncated = true
+				return errEnoughEntries
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil && err != errEnoughEntries {
+		return nil, false, errors.Wrapf(err, "failed to list log entries of %q", projectID)
+	}
+	return entries, truncated, nil
+}
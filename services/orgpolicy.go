@@ -0,0 +1,121 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// OrgPolicyClient holds the minimum interface required by the OrgPolicy service.
+type OrgPolicyClient interface {
+	GetOrgPolicy(context.Context, string, string) (*crm.OrgPolicy, error)
+	GetEffectiveOrgPolicy(context.Context, string, string) (*crm.OrgPolicy, error)
+	SetOrgPolicy(context.Context, string, *crm.OrgPolicy) (*crm.OrgPolicy, error)
+}
+
+// OrgPolicy Service.
+type OrgPolicy struct {
+	client OrgPolicyClient
+}
+
+// NewOrgPolicy returns a new OrgPolicy service.
+func NewOrgPolicy(client OrgPolicyClient) *OrgPolicy {
+	return &OrgPolicy{client: client}
+}
+
+// Enforced reports whether the boolean constraint, such as
+// "constraints/iam.disableServiceAccountKeyCreation", is enforced on the resource, one of
+// "projects/<id>", "folders/<id>" or "organizations/<id>", either directly or by an ancestor.
+func (o *OrgPolicy) Enforced(ctx context.Context, resource, constraint string) (bool, error) {
+	p, err := o.client.GetEffectiveOrgPolicy(ctx, resource, constraint)
+	if err != nil {
+		return false, err
+	}
+	return p.BooleanPolicy != nil && p.BooleanPolicy.Enforced, nil
+}
+
+// Restricted reports whether the list constraint in effect on the resource allows at most the
+// values, or denies every value if there are none, either directly or by an ancestor.
+func (o *OrgPolicy) Restricted(ctx context.Context, resource, constraint string, values []string) (bool, error) {
+	p, err := o.client.GetEffectiveOrgPolicy(ctx, resource, constraint)
+	if err != nil {
+		return false, err
+	}
+	l := p.ListPolicy
+	switch {
+	case l == nil, l.AllValues == "ALLOW":
+		return false, nil
+	case l.AllValues == "DENY":
+		return true, nil
+	case len(l.AllowedValues) == 0:
+		return false, nil
+	}
+	allowed := make(map[string]bool)
+	for _, v := range values {
+		allowed[v] = true
+	}
+	for _, v := range l.AllowedValues {
+		if !allowed[v] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// EnforceConstraint enforces the boolean constraint on the resource.
+func (o *OrgPolicy) EnforceConstraint(ctx context.Context, resource, constraint string) error {
+	p, err := o.client.GetOrgPolicy(ctx, resource, constraint)
+	if err != nil {
+		return err
+	}
+	_, err = o.client.SetOrgPolicy(ctx, resource, &crm.OrgPolicy{
+		Constraint:    constraint,
+		Etag:          p.Etag,
+		BooleanPolicy: &crm.BooleanPolicy{Enforced: true},
+	})
+	return err
+}
+
+// AllowValues restricts the list constraint on the resource to the values, such as the customer
+// IDs allowed by "constraints/iam.allowedPolicyMemberDomains".
+func (o *OrgPolicy) AllowValues(ctx context.Context, resource, constraint string, values []string) error {
+	p, err := o.client.GetOrgPolicy(ctx, resource, constraint)
+	if err != nil {
+		return err
+	}
+	_, err = o.client.SetOrgPolicy(ctx, resource, &crm.OrgPolicy{
+		Constraint: constraint,
+		Etag:       p.Etag,
+		ListPolicy: &crm.ListPolicy{AllowedValues: values},
+	})
+	return err
+}
+
+// DenyAll denies every value of the list constraint on the resource, such as every instance
+// allowed an external IP by "constraints/compute.vmExternalIpAccess".
+func (o *OrgPolicy) DenyAll(ctx context.Context, resource, constraint string) error {
+	p, err := o.client.GetOrgPolicy(ctx, resource, constraint)
+	if err != nil {
+		return err
+	}
+	_, err = o.client.SetOrgPolicy(ctx, resource, &crm.OrgPolicy{
+		Constraint: constraint,
+		Etag:       p.Etag,
+		ListPolicy: &crm.ListPolicy{AllValues: "DENY"},
+	})
+	return err
+}
@@ -0,0 +1,143 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestEnforced(t *testing.T) {
+	const constraint = "constraints/iam.disableServiceAccountKeyCreation"
+	tests := []struct {
+		name     string
+		policy   *crm.OrgPolicy
+		expected bool
+	}{
+		{name: "not set", policy: &crm.OrgPolicy{Constraint: constraint}, expected: false},
+		{name: "not enforced", policy: &crm.OrgPolicy{BooleanPolicy: &crm.BooleanPolicy{}}, expected: false},
+		{name: "enforced", policy: &crm.OrgPolicy{BooleanPolicy: &crm.BooleanPolicy{Enforced: true}}, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.OrgPolicyStub{EffectivePolicies: map[string]*crm.OrgPolicy{constraint: tt.policy}}
+			got, err := NewOrgPolicy(stub).Enforced(context.Background(), "projects/foo", constraint)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got != tt.expected {
+				t.Errorf("%s got enforced %t, want %t", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRestricted(t *testing.T) {
+	const constraint = "constraints/compute.vmExternalIpAccess"
+	const instance = "projects/foo/zones/us-central1-a/instances/bastion"
+	tests := []struct {
+		name     string
+		policy   *crm.OrgPolicy
+		values   []string
+		expected bool
+	}{
+		{name: "not set", policy: &crm.OrgPolicy{Constraint: constraint}, expected: false},
+		{name: "all allowed", policy: &crm.OrgPolicy{ListPolicy: &crm.ListPolicy{AllValues: "ALLOW"}}, expected: false},
+		{name: "all denied", policy: &crm.OrgPolicy{ListPolicy: &crm.ListPolicy{AllValues: "DENY"}}, values: []string{instance}, expected: true},
+		{name: "allowlisted", policy: &crm.OrgPolicy{ListPolicy: &crm.ListPolicy{AllowedValues: []string{instance}}}, values: []string{instance}, expected: true},
+		{name: "others allowed", policy: &crm.OrgPolicy{ListPolicy: &crm.ListPolicy{AllowedValues: []string{instance, "projects/foo/zones/us-central1-a/instances/web"}}}, values: []string{instance}, expected: false},
+		{name: "allowed when denying all", policy: &crm.OrgPolicy{ListPolicy: &crm.ListPolicy{AllowedValues: []string{instance}}}, expected: false},
+		{name: "only denied values", policy: &crm.OrgPolicy{ListPolicy: &crm.ListPolicy{DeniedValues: []string{instance}}}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.OrgPolicyStub{EffectivePolicies: map[string]*crm.OrgPolicy{constraint: tt.policy}}
+			got, err := NewOrgPolicy(stub).Restricted(context.Background(), "projects/foo", constraint, tt.values)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if got != tt.expected {
+				t.Errorf("%s got restricted %t, want %t", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetOrgPolicy(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		constraint string
+		set        func(*OrgPolicy, string) error
+		expected   *crm.OrgPolicy
+	}{
+		{
+			name:       "enforce constraint",
+			constraint: "constraints/iam.disableServiceAccountKeyCreation",
+			set: func(o *OrgPolicy, c string) error {
+				return o.EnforceConstraint(ctx, "folders/123", c)
+			},
+			expected: &crm.OrgPolicy{
+				Constraint:    "constraints/iam.disableServiceAccountKeyCreation",
+				Etag:          "BwWWja0YfJA=",
+				BooleanPolicy: &crm.BooleanPolicy{Enforced: true},
+			},
+		},
+		{
+			name:       "allow values",
+			constraint: "constraints/iam.allowedPolicyMemberDomains",
+			set: func(o *OrgPolicy, c string) error {
+				return o.AllowValues(ctx, "folders/123", c, []string{"C03xgje4y"})
+			},
+			expected: &crm.OrgPolicy{
+				Constraint: "constraints/iam.allowedPolicyMemberDomains",
+				Etag:       "BwWWja0YfJA=",
+				ListPolicy: &crm.ListPolicy{AllowedValues: []string{"C03xgje4y"}},
+			},
+		},
+		{
+			name:       "deny all",
+			constraint: "constraints/compute.vmExternalIpAccess",
+			set: func(o *OrgPolicy, c string) error {
+				return o.DenyAll(ctx, "folders/123", c)
+			},
+			expected: &crm.OrgPolicy{
+				Constraint: "constraints/compute.vmExternalIpAccess",
+				Etag:       "BwWWja0YfJA=",
+				ListPolicy: &crm.ListPolicy{AllValues: "DENY"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.OrgPolicyStub{Policies: map[string]*crm.OrgPolicy{
+				tt.constraint: {Constraint: tt.constraint, Etag: "BwWWja0YfJA="},
+			}}
+			if err := tt.set(NewOrgPolicy(stub), tt.constraint); err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if stub.SavedResource != "folders/123" {
+				t.Errorf("%s set policy on %q, want %q", tt.name, stub.SavedResource, "folders/123")
+			}
+			if diff := cmp.Diff(tt.expected, stub.SavedPolicy); diff != "" {
+				t.Errorf("%s (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PolicyClient holds the minimum interface required by the policy service.
+type PolicyClient interface {
+	Objects(context.Context, string, string) ([]string, error)
+	Object(context.Context, string, string) ([]byte, error)
+}
+
+// Policy service reads Rego policies stored in Cloud Storage.
+type Policy struct {
+	client PolicyClient
+}
+
+// NewPolicy returns a new policy service.
+func NewPolicy(client PolicyClient) *Policy {
+	return &Policy{client: client}
+}
+
+// Modules returns the Rego policies in the bucket keyed by object name, policy tests are skipped.
+func (p *Policy) Modules(ctx context.Context, bucket string) (map[string][]byte, error) {
+	names, err := p.client.Objects(ctx, bucket, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list policies in bucket %q", bucket)
+	}
+	modules := make(map[string][]byte)
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".rego") || strings.HasSuffix(name, "_test.rego") {
+			continue
+		}
+		b, err := p.client.Object(ctx, bucket, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read policy %q", name)
+		}
+		modules[name] = b
+	}
+	return modules, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestPolicyModules(t *testing.T) {
+	stub := &stubs.StorageStub{StubbedObjects: map[string][]byte{
+		"gate.rego":      []byte("package sra.policy"),
+		"gate_test.rego": []byte("package sra.policy"),
+		"README.md":      []byte("policies"),
+	}}
+	got, err := NewPolicy(stub).Modules(context.Background(), "policies")
+	if err != nil {
+		t.Fatalf("Modules failed: %q", err)
+	}
+	want := map[string][]byte{"gate.rego": []byte("package sra.policy")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Modules failed diff:%q", diff)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// ForEach calls fn with each of the items, on at most workers goroutines at once, and waits for
+// them all. Items failing do not stop the others, they are reported together in a *PartialError
+// along with the items completed. Items not started once the context is done fail with its error.
+func ForEach(ctx context.Context, items []string, workers int, fn func(context.Context, string) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		partial = &PartialError{Failed: map[string]string{}}
+		sem     = make(chan struct{}, workers)
+	)
+	for _, item := range items {
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+			var err error
+			select {
+			case sem <- struct{}{}:
+				err = fn(ctx, item)
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				partial.Failed[item] = err.Error()
+				return
+			}
+			partial.Completed = append(partial.Completed, item)
+		}(item)
+	}
+	wg.Wait()
+	if len(partial.Failed) > 0 {
+		sort.Strings(partial.Completed)
+		return partial
+	}
+	return nil
+}
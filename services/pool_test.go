@@ -0,0 +1,70 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestForEach(t *testing.T) {
+	var running, peak int32
+	err := ForEach(context.Background(), []string{"a", "b", "c", "d", "e", "f"}, 2, func(ctx context.Context, item string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		if item == "c" || item == "e" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	partial, ok := err.(*PartialError)
+	if !ok {
+		t.Fatalf("got %q, want a partial failure", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "d", "f"}, partial.Completed); diff != "" {
+		t.Errorf("completed (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"c": "boom", "e": "boom"}, partial.Failed); diff != "" {
+		t.Errorf("failed (-want +got):\n%s", diff)
+	}
+	if peak > 2 {
+		t.Errorf("ran %d at once, want at most 2", peak)
+	}
+}
+
+func TestForEachCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ForEach(ctx, []string{"a", "b"}, 1, func(ctx context.Context, item string) error {
+		return ctx.Err()
+	})
+	partial, ok := err.(*PartialError)
+	if !ok || len(partial.Failed) != 2 {
+		t.Errorf("got %v, want both items failed", err)
+	}
+	if err := ForEach(context.Background(), nil, 4, nil); err != nil {
+		t.Errorf("got %q for no items, want nil", err)
+	}
+}
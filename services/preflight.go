@@ -0,0 +1,98 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"sort"
+)
+
+// testPermissionsLimit is the most permissions testIamPermissions checks in one call.
+const testPermissionsLimit = 100
+
+// PermissionsClient holds the minimum interface required to test the caller's permissions.
+type PermissionsClient interface {
+	TestPermissions(context.Context, string, []string) ([]string, error)
+}
+
+// ServiceUsageClient holds the minimum interface required to list the enabled APIs.
+type ServiceUsageClient interface {
+	EnabledServices(context.Context, string) ([]string, error)
+}
+
+// Preflight service checks the caller holds the permissions and has enabled the APIs automations
+// need, before a remediation fails for lack of them.
+type Preflight struct {
+	permissions PermissionsClient
+	usage       ServiceUsageClient
+	enabled     map[string]map[string]bool
+}
+
+// NewPreflight returns a new preflight service.
+func NewPreflight(p PermissionsClient, u ServiceUsageClient) *Preflight {
+	return &Preflight{permissions: p, usage: u, enabled: make(map[string]map[string]bool)}
+}
+
+// MissingPermissions returns the permissions the caller lacks on the resource, one of
+// "projects/<id>", "folders/<id>" or "organizations/<id>", sorted.
+func (p *Preflight) MissingPermissions(ctx context.Context, resource string, permissions []string) ([]string, error) {
+	held := make(map[string]bool)
+	for i := 0; i < len(permissions); i += testPermissionsLimit {
+		end := i + testPermissionsLimit
+		if end > len(permissions) {
+			end = len(permissions)
+		}
+		granted, err := p.permissions.TestPermissions(ctx, resource, permissions[i:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range granted {
+			held[g] = true
+		}
+	}
+	var missing []string
+	for _, perm := range permissions {
+		if !held[perm] {
+			missing = append(missing, perm)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// DisabledAPIs returns the APIs not enabled in the project among those given, such as
+// "compute.googleapis.com", sorted. The enabled APIs of each project are listed once.
+func (p *Preflight) DisabledAPIs(ctx context.Context, projectID string, apis []string) ([]string, error) {
+	enabled, ok := p.enabled[projectID]
+	if !ok {
+		names, err := p.usage.EnabledServices(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		enabled = make(map[string]bool)
+		for _, n := range names {
+			enabled[n] = true
+		}
+		p.enabled[projectID] = enabled
+	}
+	var disabled []string
+	for _, api := range apis {
+		if !enabled[api] {
+			disabled = append(disabled, api)
+		}
+	}
+	sort.Strings(disabled)
+	return disabled, nil
+}
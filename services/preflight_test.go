@@ -0,0 +1,67 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	var many []string
+	for i := 0; i < 150; i++ {
+		many = append(many, fmt.Sprintf("compute.p%03d.get", i))
+	}
+	for _, tt := range []struct {
+		name        string
+		granted     []string
+		permissions []string
+		expected    []string
+	}{
+		{name: "all held", granted: []string{"storage.buckets.get", "storage.buckets.update"}, permissions: []string{"storage.buckets.get", "storage.buckets.update"}},
+		{name: "missing", granted: []string{"storage.buckets.get"}, permissions: []string{"storage.buckets.update", "storage.buckets.get"}, expected: []string{"storage.buckets.update"}},
+		{name: "over the limit", granted: many[:140], permissions: many, expected: many[140:]},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			crm := &stubs.ResourceManagerStub{GrantedPermissions: map[string][]string{"folders/123": tt.granted}}
+			p := NewPreflight(crm, &stubs.ServiceUsageStub{})
+			got, err := p.MissingPermissions(context.Background(), "folders/123", tt.permissions)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("%s missing permissions difference:%+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestDisabledAPIs(t *testing.T) {
+	su := &stubs.ServiceUsageStub{StubbedEnabledServices: map[string][]string{
+		"automation-project": {"pubsub.googleapis.com", "compute.googleapis.com"},
+	}}
+	p := NewPreflight(&stubs.ResourceManagerStub{}, su)
+	got, err := p.DisabledAPIs(context.Background(), "automation-project", []string{"compute.googleapis.com", "sqladmin.googleapis.com", "bigquery.googleapis.com"})
+	if err != nil {
+		t.Fatalf("DisabledAPIs failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"bigquery.googleapis.com", "sqladmin.googleapis.com"}, got); diff != "" {
+		t.Errorf("disabled APIs difference:%+v", diff)
+	}
+}
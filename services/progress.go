@@ -0,0 +1,121 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Progress records which of the resources an action touches were remediated and which failed, so a
+// retry of the remediation resumes with the failed ones instead of processing everything again.
+//
+// The zero value keeps progress for a single attempt only. Progress is safe for concurrent use.
+type Progress struct {
+	// Completed lists the resources remediated, by earlier attempts too.
+	Completed []string `json:"completed"`
+	// Failed holds the error of each resource that failed in the last attempt.
+	Failed map[string]string `json:"failed,omitempty"`
+
+	checkpoints *Checkpoints
+	id          string
+	mu          sync.Mutex
+}
+
+// PartialError is returned by actions that remediated some of the resources they touch and failed
+// on others.
+type PartialError struct {
+	Completed []string
+	Failed    map[string]string
+}
+
+// Error lists the resources that failed.
+func (e *PartialError) Error() string {
+	var l []string
+	for r, err := range e.Failed {
+		l = append(l, fmt.Sprintf("%s: %s", r, err))
+	}
+	sort.Strings(l)
+	return fmt.Sprintf("failed on %d of %d resources: %s", len(e.Failed), len(e.Failed)+len(e.Completed), strings.Join(l, ", "))
+}
+
+// NewProgress returns the progress of the remediation with the ID kept in checkpoints, loading what
+// earlier attempts recorded.
+func NewProgress(ctx context.Context, checkpoints *Checkpoints, id string) (*Progress, error) {
+	p := &Progress{checkpoints: checkpoints, id: id}
+	if _, err := checkpoints.Load(ctx, id, p); err != nil {
+		return nil, err
+	}
+	p.Failed = nil
+	return p, nil
+}
+
+// Pending reports whether the resource still has to be remediated.
+func (p *Progress) Pending(resource string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pending(resource)
+}
+
+func (p *Progress) pending(resource string) bool {
+	for _, r := range p.Completed {
+		if r == resource {
+			return false
+		}
+	}
+	return true
+}
+
+// Complete records the resource as remediated.
+func (p *Progress) Complete(ctx context.Context, resource string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending(resource) {
+		p.Completed = append(p.Completed, resource)
+	}
+	delete(p.Failed, resource)
+	return p.save(ctx)
+}
+
+// Fail records the resource as failed with the error.
+func (p *Progress) Fail(ctx context.Context, resource string, err error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Failed == nil {
+		p.Failed = make(map[string]string)
+	}
+	p.Failed[resource] = err.Error()
+	return p.save(ctx)
+}
+
+// Err returns a PartialError if any resource failed, nil otherwise.
+func (p *Progress) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.Failed) == 0 {
+		return nil
+	}
+	return &PartialError{Completed: p.Completed, Failed: p.Failed}
+}
+
+func (p *Progress) save(ctx context.Context) error {
+	if p.checkpoints == nil {
+		return nil
+	}
+	return p.checkpoints.Save(ctx, p.id, p)
+}
@@ -0,0 +1,60 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestProgress(t *testing.T) {
+	ctx := context.Background()
+	checkpoints := NewCheckpoints(&stubs.FirestoreStub{}, "automation-project", "runs")
+	p, err := NewProgress(ctx, checkpoints, "remediation-id")
+	if err != nil {
+		t.Fatalf("NewProgress failed: %q", err)
+	}
+	if err := p.Complete(ctx, "disk-1"); err != nil {
+		t.Fatalf("Complete failed: %q", err)
+	}
+	if err := p.Fail(ctx, "disk-2", errors.New("quota exceeded")); err != nil {
+		t.Fatalf("Fail failed: %q", err)
+	}
+	want := &PartialError{Completed: []string{"disk-1"}, Failed: map[string]string{"disk-2": "quota exceeded"}}
+	if diff := cmp.Diff(want, p.Err()); diff != "" {
+		t.Errorf("first attempt error difference: %+v", diff)
+	}
+
+	retry, err := NewProgress(ctx, checkpoints, "remediation-id")
+	if err != nil {
+		t.Fatalf("NewProgress failed: %q", err)
+	}
+	if retry.Pending("disk-1") || !retry.Pending("disk-2") {
+		t.Errorf("retry pending got disk-1:%v disk-2:%v", retry.Pending("disk-1"), retry.Pending("disk-2"))
+	}
+	if err := retry.Complete(ctx, "disk-2"); err != nil {
+		t.Fatalf("Complete failed: %q", err)
+	}
+	if err := retry.Err(); err != nil {
+		t.Errorf("retry failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"disk-1", "disk-2"}, retry.Completed); diff != "" {
+		t.Errorf("completed difference: %+v", diff)
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// lienOrigin identifies the liens placed by automations.
+const lienOrigin = "security-response-automation"
+
+// deleteRestriction is the restriction of the liens preventing the deletion of projects.
+const deleteRestriction = "resourcemanager.projects.delete"
+
+// HoldProject places a lien on the project preventing its deletion until the lien is removed, such
+// as while it is investigated. It reports whether the project was not already held by an
+// automation. The lien placed is logged as a change, so it can be removed.
+func (r *Resource) HoldProject(ctx context.Context, projectID, reason string) (bool, error) {
+	p, err := r.Project(ctx, projectID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get project %q", projectID)
+	}
+	parent := fmt.Sprintf("projects/%d", p.ProjectNumber)
+	own, _, err := r.projectLiens(ctx, parent)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list liens of %q", projectID)
+	}
+	if len(own) > 0 {
+		return false, nil
+	}
+	lien, err := r.crm.CreateLien(ctx, &crm.Lien{
+		Parent:       parent,
+		Restrictions: []string{deleteRestriction},
+		Origin:       lienOrigin,
+		Reason:       reason,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to place lien on %q", projectID)
+	}
+	logChange(ctx, &Change{Kind: lienKind, Resource: lien.Name, Fields: map[string]FieldChange{
+		"parent": {Before: nil, After: parent},
+		"reason": {Before: nil, After: reason},
+	}})
+	return true, nil
+}
+
+// ProjectHeld reports whether an automation placed a lien on the project.
+func (r *Resource) ProjectHeld(ctx context.Context, projectID string) (bool, error) {
+	p, err := r.Project(ctx, projectID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get project %q", projectID)
+	}
+	own, _, err := r.projectLiens(ctx, fmt.Sprintf("projects/%d", p.ProjectNumber))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list liens of %q", projectID)
+	}
+	return len(own) > 0, nil
+}
+
+// ShutDownProject requests the deletion of the project: its resources stop serving and it is
+// deleted after 30 days unless restored before. It reports whether the project was active. Liens
+// placed by an automation, such as while the project was held for investigation, are removed first
+// while projects holding other liens are not shut down until those are removed. The liens removed
+// and the shutdown are logged as changes, so the project can be restored.
+func (r *Resource) ShutDownProject(ctx context.Context, projectID string) (bool, error) {
+	p, err := r.Project(ctx, projectID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get project %q", projectID)
+	}
+	if p.LifecycleState != "ACTIVE" {
+		return false, nil
+	}
+	parent := fmt.Sprintf("projects/%d", p.ProjectNumber)
+	own, others, err := r.projectLiens(ctx, parent)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list liens of %q", projectID)
+	}
+	if len(others) > 0 {
+		var names []string
+		for _, l := range others {
+			names = append(names, fmt.Sprintf("%s (%s)", l.Name, l.Origin))
+		}
+		return false, fmt.Errorf("project %q holds liens %s", projectID, strings.Join(names, ", "))
+	}
+	for _, l := range own {
+		if err := r.crm.DeleteLien(ctx, l.Name); err != nil {
+			return false, errors.Wrapf(err, "failed to remove lien %q of %q", l.Name, projectID)
+		}
+		logChange(ctx, &Change{Kind: lienKind, Resource: l.Name, Fields: map[string]FieldChange{
+			"parent": {Before: parent, After: nil},
+			"reason": {Before: l.Reason, After: nil},
+		}})
+	}
+	if err := r.crm.DeleteProject(ctx, projectID); err != nil {
+		return false, errors.Wrapf(err, "failed to shut down project %q", projectID)
+	}
+	logChange(ctx, &Change{Kind: projectKind, Resource: "projects/" + projectID, Fields: map[string]FieldChange{
+		"lifecycleState": {Before: "ACTIVE", After: "DELETE_REQUESTED"},
+	}})
+	return true, nil
+}
+
+// projectLiens returns the liens of the project, named "projects/<number>", placed by automations
+// and those placed by others.
+func (r *Resource) projectLiens(ctx context.Context, parent string) ([]*crm.Lien, []*crm.Lien, error) {
+	liens, err := r.crm.ListLiens(ctx, parent)
+	if err != nil {
+		return nil, nil, err
+	}
+	var own, others []*crm.Lien
+	for _, l := range liens {
+		if l.Origin == lienOrigin {
+			own = append(own, l)
+		} else {
+			others = append(others, l)
+		}
+	}
+	return own, others, nil
+}
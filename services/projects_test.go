@@ -0,0 +1,95 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	crm "google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestHoldProject(t *testing.T) {
+	ctx := context.Background()
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddOrganization("456")
+	crmFake.AddProject("rogue", 123, "organizations/456")
+	r := NewResource(crmFake, &stubs.StorageStub{})
+
+	held, err := r.HoldProject(ctx, "rogue", "finding abc")
+	if err != nil || !held {
+		t.Fatalf("got %t and %v, want held and no error", held, err)
+	}
+	liens, err := crmFake.ListLiens(ctx, "projects/123")
+	if err != nil {
+		t.Fatalf("failed to list liens: %q", err)
+	}
+	if len(liens) != 1 || liens[0].Origin != lienOrigin || liens[0].Reason != "finding abc" || liens[0].Restrictions[0] != deleteRestriction {
+		t.Errorf("got liens %+v", liens)
+	}
+	if held, err := r.HoldProject(ctx, "rogue", "finding def"); err != nil || held {
+		t.Errorf("got %t and %v holding a held project, want not held and no error", held, err)
+	}
+	if held, err := r.ProjectHeld(ctx, "rogue"); err != nil || !held {
+		t.Errorf("got %t and %v checking a held project, want held and no error", held, err)
+	}
+	if shut, err := r.ShutDownProject(ctx, "rogue"); err != nil || !shut {
+		t.Fatalf("got %t and %v shutting down a project held by an automation, want shut down and no error", shut, err)
+	}
+	if liens, err := crmFake.ListLiens(ctx, "projects/123"); err != nil || len(liens) != 0 {
+		t.Errorf("got liens %+v and %v after shutting down, want the automation's lien removed", liens, err)
+	}
+}
+
+func TestShutDownProjectHeldByOthers(t *testing.T) {
+	ctx := context.Background()
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddOrganization("456")
+	crmFake.AddProject("rogue", 123, "organizations/456")
+	r := NewResource(crmFake, &stubs.StorageStub{})
+	if _, err := crmFake.CreateLien(ctx, &crm.Lien{Parent: "projects/123", Origin: "legal-hold", Restrictions: []string{deleteRestriction}}); err != nil {
+		t.Fatalf("failed to place lien: %q", err)
+	}
+	if held, err := r.ProjectHeld(ctx, "rogue"); err != nil || held {
+		t.Errorf("got %t and %v checking a project held by others, want not held and no error", held, err)
+	}
+	if _, err := r.ShutDownProject(ctx, "rogue"); err == nil {
+		t.Errorf("shutting down a project held by others should fail")
+	}
+}
+
+func TestShutDownProject(t *testing.T) {
+	ctx := context.Background()
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddOrganization("456")
+	crmFake.AddProject("rogue", 123, "organizations/456")
+	r := NewResource(crmFake, &stubs.StorageStub{})
+
+	shut, err := r.ShutDownProject(ctx, "rogue")
+	if err != nil || !shut {
+		t.Fatalf("got %t and %v, want shut down and no error", shut, err)
+	}
+	p, err := crmFake.GetProject(ctx, "rogue")
+	if err != nil {
+		t.Fatalf("failed to get project: %q", err)
+	}
+	if p.LifecycleState != "DELETE_REQUESTED" {
+		t.Errorf("got lifecycle state %q, want DELETE_REQUESTED", p.LifecycleState)
+	}
+	if shut, err := r.ShutDownProject(ctx, "rogue"); err != nil || shut {
+		t.Errorf("got %t and %v shutting down a project pending deletion, want not shut down and no error", shut, err)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+// iamPolicyRecommender names the recommender suggesting IAM role reductions.
+const iamPolicyRecommender = "google.iam.policy.Recommender"
+
+// RecommenderClient holds the minimum interface required by the Recommender service.
+type RecommenderClient interface {
+	ListRecommendations(context.Context, string, string) ([]*recommender.GoogleCloudRecommenderV1Recommendation, error)
+	MarkRecommendationSucceeded(context.Context, string, string) error
+}
+
+// Recommender service reading the IAM recommendations of projects.
+type Recommender struct {
+	client RecommenderClient
+}
+
+// NewRecommender returns a Recommender service.
+func NewRecommender(client RecommenderClient) *Recommender {
+	return &Recommender{client: client}
+}
+
+// RoleRecommendation is an IAM recommendation replacing a role of a member by smaller roles, or by
+// none when the member does not use the role.
+type RoleRecommendation struct {
+	// Name of the recommendation.
+	Name string
+	// Etag of the recommendation, required to mark it as succeeded.
+	Etag string
+	// Role to remove from the member.
+	Role string
+	// Replacements are the roles to grant the member instead.
+	Replacements []string
+}
+
+// operation is the subset of a recommendation operation read by the service, its path filters and
+// value are untyped in the API.
+type operation struct {
+	Action      string                 `json:"action"`
+	Path        string                 `json:"path"`
+	PathFilters map[string]interface{} `json:"pathFilters"`
+	Value       interface{}            `json:"value"`
+}
+
+// RoleRecommendations returns the active IAM recommendations of the project for the member.
+func (r *Recommender) RoleRecommendations(ctx context.Context, projectID, member string) ([]*RoleRecommendation, error) {
+	parent := fmt.Sprintf("projects/%s/locations/global/recommenders/%s", projectID, iamPolicyRecommender)
+	recommendations, err := r.client.ListRecommendations(ctx, parent, "stateInfo.state = ACTIVE")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list recommendations of %q", projectID)
+	}
+	var found []*RoleRecommendation
+	for _, rec := range recommendations {
+		if rec.Content == nil {
+			continue
+		}
+		role := &RoleRecommendation{Name: rec.Name, Etag: rec.Etag}
+		for _, g := range rec.Content.OperationGroups {
+			for _, o := range g.Operations {
+				op, err := decodeOperation(o)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to read recommendation %q", rec.Name)
+				}
+				switch op.Action {
+				case "remove":
+					if strings.EqualFold(op.filter("/iamPolicy/bindings/*/members/*"), member) {
+						role.Role = op.filter("/iamPolicy/bindings/*/role")
+					}
+				case "add":
+					if v, ok := op.Value.(string); ok && strings.EqualFold(v, member) {
+						role.Replacements = append(role.Replacements, op.filter("/iamPolicy/bindings/*/role"))
+					}
+				}
+			}
+		}
+		if role.Role == "" {
+			continue
+		}
+		sort.Strings(role.Replacements)
+		found = append(found, role)
+	}
+	return found, nil
+}
+
+// MarkSucceeded marks the recommendation as applied.
+func (r *Recommender) MarkSucceeded(ctx context.Context, rec *RoleRecommendation) error {
+	return errors.Wrapf(r.client.MarkRecommendationSucceeded(ctx, rec.Name, rec.Etag), "failed to mark %q as succeeded", rec.Name)
+}
+
+// decodeOperation reads the operation through its JSON form.
+func decodeOperation(o *recommender.GoogleCloudRecommenderV1Operation) (*operation, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	var op operation
+	if err := json.Unmarshal(b, &op); err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// filter returns the value the path filter matches, if any.
+func (o *operation) filter(path string) string {
+	v, _ := o.PathFilters[path].(string)
+	return v
+}
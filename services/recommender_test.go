@@ -0,0 +1,73 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+func TestRoleRecommendations(t *testing.T) {
+	const parent = "projects/foo/locations/global/recommenders/google.iam.policy.Recommender"
+	ctx := context.Background()
+	op := func(action, member, role string) *recommender.GoogleCloudRecommenderV1Operation {
+		o := &recommender.GoogleCloudRecommenderV1Operation{
+			Action:      action,
+			Path:        "/iamPolicy/bindings/*/members/*",
+			PathFilters: map[string]interface{}{"/iamPolicy/bindings/*/role": role},
+		}
+		if action == "add" {
+			o.Path = "/iamPolicy/bindings/*/members/-"
+			o.Value = member
+		} else {
+			o.PathFilters["/iamPolicy/bindings/*/members/*"] = member
+		}
+		return o
+	}
+	stub := &stubs.RecommenderStub{Recommendations: map[string][]*recommender.GoogleCloudRecommenderV1Recommendation{
+		parent: {
+			{Name: parent + "/recommendations/1", Etag: "\"a\"", Content: &recommender.GoogleCloudRecommenderV1RecommendationContent{
+				OperationGroups: []*recommender.GoogleCloudRecommenderV1OperationGroup{{Operations: []*recommender.GoogleCloudRecommenderV1Operation{
+					op("add", "user:dev@example.com", "roles/storage.objectViewer"),
+					op("remove", "user:dev@example.com", "roles/editor"),
+				}}},
+			}},
+			{Name: parent + "/recommendations/2", Etag: "\"b\"", Content: &recommender.GoogleCloudRecommenderV1RecommendationContent{
+				OperationGroups: []*recommender.GoogleCloudRecommenderV1OperationGroup{{Operations: []*recommender.GoogleCloudRecommenderV1Operation{
+					op("remove", "user:ops@example.com", "roles/owner"),
+				}}},
+			}},
+		},
+	}}
+	r := NewRecommender(stub)
+	got, err := r.RoleRecommendations(ctx, "foo", "user:dev@example.com")
+	if err != nil {
+		t.Fatalf("got error %q", err)
+	}
+	want := []*RoleRecommendation{{Name: parent + "/recommendations/1", Etag: "\"a\"", Role: "roles/editor", Replacements: []string{"roles/storage.objectViewer"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("recommendations (-want +got):\n%s", diff)
+	}
+	if err := r.MarkSucceeded(ctx, got[0]); err != nil {
+		t.Fatalf("got error %q", err)
+	}
+	if diff := cmp.Diff([]string{parent + "/recommendations/1"}, stub.Succeeded); diff != "" {
+		t.Errorf("succeeded (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// redacted replaces the sensitive material found by a redactor.
+const redacted = "[REDACTED]"
+
+// redactionPatterns are the built-in patterns of sensitive material, keys first so their contents
+// are not partly redacted by the other patterns.
+var redactionPatterns = []struct{ name, pattern string }{
+	{"private_key", `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{"google_api_key", `AIza[0-9A-Za-z_\-]{35}`},
+	{"aws_access_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{"oauth_token", `ya29\.[0-9A-Za-z_\-]+`},
+	{"jwt", `\beyJ[0-9A-Za-z_\-]+\.[0-9A-Za-z_\-]+\.[0-9A-Za-z_\-]+`},
+	{"bearer_token", `(?i)\bbearer\s+[0-9A-Za-z._~+/\-]+=*`},
+	{"secret", `(?i)\b(?:password|passwd|secret|token|api_?key)["']?\s*[:=]\s*["']?[^\s"',;&]+`},
+	{"email", `[0-9A-Za-z._%+\-]+@[0-9A-Za-z\-]+(?:\.[0-9A-Za-z\-]+)*\.[A-Za-z]{2,}`},
+}
+
+// Redactor scrubs sensitive material such as keys, tokens and email addresses from text.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a redactor of the patterns, each the name of a built-in pattern such as
+// "private_key" or "email" or a regular expression.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, p := range patterns {
+		for _, builtin := range redactionPatterns {
+			if builtin.name == p {
+				p = builtin.pattern
+			}
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %q", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// DefaultRedactor returns a redactor of every built-in pattern.
+func DefaultRedactor() *Redactor {
+	var names []string
+	for _, p := range redactionPatterns {
+		names = append(names, p.name)
+	}
+	r, _ := NewRedactor(names)
+	return r
+}
+
+// Redact returns the text with every match of the redactor's patterns replaced by [REDACTED].
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
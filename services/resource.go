@@ -16,16 +16,29 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/iam"
 	"github.com/pkg/errors"
 	crm "google.golang.org/api/cloudresourcemanager/v1"
+	crmv2 "google.golang.org/api/cloudresourcemanager/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// policyAttempts bounds the read-modify-write loops updating IAM policies.
+const policyAttempts = 5
+
 type crmClient interface {
 	GetAncestry(context.Context, string) (*crm.GetAncestryResponse, error)
 	SetPolicyProject(context.Context, string, *crm.Policy) (*crm.Policy, error)
@@ -34,18 +47,38 @@ type crmClient interface {
 	SetPolicyOrganization(context.Context, string, *crm.Policy) (*crm.Policy, error)
 	GetOrganization(context.Context, string) (*crm.Organization, error)
 	SetPolicyProjectWithMask(context.Context, string, *crm.Policy, ...string) (*crm.Policy, error)
+	GetFolder(context.Context, string) (*crmv2.Folder, error)
+	GetPolicyFolder(context.Context, string) (*crmv2.Policy, error)
+	ListProjects(context.Context, string, string) (*crm.ListProjectsResponse, error)
+	ListFolders(context.Context, string, string) (*crmv2.ListFoldersResponse, error)
+	GetProject(context.Context, string) (*crm.Project, error)
+	UpdateProject(context.Context, string, *crm.Project) (*crm.Project, error)
+	DeleteProject(context.Context, string) error
+	ListLiens(context.Context, string) ([]*crm.Lien, error)
+	CreateLien(context.Context, *crm.Lien) (*crm.Lien, error)
+	DeleteLien(context.Context, string) error
 }
 
 type storageClient interface {
 	SetBucketPolicy(context.Context, string, *iam.Policy) error
 	BucketPolicy(context.Context, string) (*iam.Policy, error)
 	EnableBucketOnlyPolicy(context.Context, string) error
+	BucketPolicyOnly(context.Context, string) (bool, error)
+	BucketLabels(context.Context, string) (map[string]string, error)
+	SetBucketLabels(context.Context, string, map[string]string) error
+	EachObjectACLPage(context.Context, string, func(map[string]map[string]string) error) error
+	DeleteObjectACL(context.Context, string, string, string) error
 }
 
+// maxConcurrentObjects bounds the objects whose ACL RemoveMembersFromObjectACLs patches at once.
+const maxConcurrentObjects = 8
+
 // Resource service.
 type Resource struct {
 	crm     crmClient
 	storage storageClient
+	// ancestryCache is nil unless CacheAncestry was called.
+	ancestryCache *ancestryCache
 }
 
 // NewResource returns a new resource service.
@@ -58,55 +91,104 @@ func NewResource(crm crmClient, s storageClient) *Resource {
 
 // ProjectOnlyKeepUsersFromDomains removes users from the policy if they do not match the domain. (Non-users are not affected.)
 func (r *Resource) ProjectOnlyKeepUsersFromDomains(ctx context.Context, projectID string, allowDomains []string) ([]string, error) {
-	existingPolicy, err := r.crm.GetPolicyProject(ctx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get project policy: %q", err)
-	}
-	removed, policy, err := r.keepUsersFromPolicy(existingPolicy, allowDomains)
+	var removed []string
+	err := updatePolicy(func() error {
+		existingPolicy, err := r.crm.GetPolicyProject(ctx, projectID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get project policy")
+		}
+		before := policyGrants(existingPolicy)
+		var policy *crm.Policy
+		removed, policy, err = r.keepUsersFromPolicy(existingPolicy, allowDomains)
+		if err != nil || len(removed) == 0 {
+			return err
+		}
+		if _, err = r.crm.SetPolicyProject(ctx, projectID, policy); err != nil {
+			return errors.Wrap(err, "failed to set project policy")
+		}
+		logChange(ctx, policyChange(projectPolicyKind, "projects/"+projectID, before, policyGrants(policy)))
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if _, err := r.crm.SetPolicyProject(ctx, projectID, policy); err != nil {
-		return nil, fmt.Errorf("failed to set project policy: %q", err)
-	}
 	return removed, nil
 }
 
 // OrganizationOnlyKeepUsersFromDomains removes all users from an organization except where the user matches allowed domains.
 func (r *Resource) OrganizationOnlyKeepUsersFromDomains(ctx context.Context, orgID string, allowDomains []string) ([]string, error) {
-	existingPolicy, err := r.crm.GetPolicyOrganization(ctx, orgID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get project policy: %q", err)
-	}
-	removed, policy, err := r.keepUsersFromPolicy(existingPolicy, allowDomains)
+	var removed []string
+	err := updatePolicy(func() error {
+		existingPolicy, err := r.crm.GetPolicyOrganization(ctx, orgID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get organization policy")
+		}
+		before := policyGrants(existingPolicy)
+		var policy *crm.Policy
+		removed, policy, err = r.keepUsersFromPolicy(existingPolicy, allowDomains)
+		if err != nil {
+			return err
+		}
+		if _, err = r.crm.SetPolicyOrganization(ctx, orgID, policy); err != nil {
+			return errors.Wrap(err, "failed to set organization policy")
+		}
+		logChange(ctx, policyChange(organizationPolicyKind, "organizations/"+orgID, before, policyGrants(policy)))
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if _, err := r.crm.SetPolicyOrganization(ctx, orgID, policy); err != nil {
-		return nil, fmt.Errorf("failed to set project policy: %q", err)
-	}
 	return removed, nil
 }
 
 // RemoveUsersProject removes a slice of users from a project.
 func (r *Resource) RemoveUsersProject(ctx context.Context, projectID string, remove []string) error {
-	existingPolicy, err := r.crm.GetPolicyProject(ctx, projectID)
+	return updatePolicy(func() error {
+		existingPolicy, err := r.crm.GetPolicyProject(ctx, projectID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get project policy")
+		}
+		before := policyGrants(existingPolicy)
+		policy := r.removeUsersFromPolicy(existingPolicy, remove)
+		if _, err = r.crm.SetPolicyProject(ctx, projectID, policy); err != nil {
+			return errors.Wrap(err, "failed to set project policy")
+		}
+		logChange(ctx, policyChange(projectPolicyKind, "projects/"+projectID, before, policyGrants(policy)))
+		return nil
+	})
+}
+
+// RemoveMembersFromBucket removes members from the bucket.
+func (r *Resource) RemoveMembersFromBucket(ctx context.Context, bucketName string, members []string) error {
+	return updatePolicy(func() error {
+		return r.removeMembersFromBucket(ctx, bucketName, members)
+	})
+}
+
+// BucketHasMembers reports whether any of the members is granted a role on the given bucket.
+func (r *Resource) BucketHasMembers(ctx context.Context, bucketName string, members []string) (bool, error) {
+	p, err := r.storage.BucketPolicy(ctx, bucketName)
 	if err != nil {
-		return fmt.Errorf("failed to get project policy: %q", err)
+		return false, err
 	}
-	policy := r.removeUsersFromPolicy(existingPolicy, remove)
-	if _, err := r.crm.SetPolicyProject(ctx, projectID, policy); err != nil {
-		return fmt.Errorf("failed to set project policy: %q", err)
+	for _, role := range p.Roles() {
+		for _, policyMember := range p.Members(role) {
+			for _, m := range members {
+				if policyMember == m {
+					return true, nil
+				}
+			}
+		}
 	}
-	return nil
+	return false, nil
 }
 
-// RemoveMembersFromBucket removes members from the bucket.
-func (r *Resource) RemoveMembersFromBucket(ctx context.Context, bucketName string, members []string) error {
+func (r *Resource) removeMembersFromBucket(ctx context.Context, bucketName string, members []string) error {
 	p, err := r.storage.BucketPolicy(ctx, bucketName)
 	if err != nil {
 		return err
 	}
+	before := bucketGrants(p)
 	// Save what we need to remove in a map so we don't mutate a slice while we iterate over it.
 	toRemove := make(map[iam.RoleName]map[string]bool)
 
@@ -129,41 +211,288 @@ func (r *Resource) RemoveMembersFromBucket(ctx context.Context, bucketName strin
 			p.Remove(kk, k)
 		}
 	}
-	return r.storage.SetBucketPolicy(ctx, bucketName, p)
+	if err := r.storage.SetBucketPolicy(ctx, bucketName, p); err != nil {
+		return err
+	}
+	logChange(ctx, policyChange(bucketPolicyKind, "buckets/"+bucketName, before, bucketGrants(p)))
+	return nil
+}
+
+// RemoveMembersFromObjectACLs removes the entries of the members, such as allUsers, from the ACLs
+// of the bucket's objects and returns the number of entries removed. Objects are listed and patched
+// a page at a time, so buckets holding millions of objects fit in the function's memory. Buckets
+// with uniform bucket-level access have no object ACLs and are skipped. Objects failing do not stop
+// the others, they are reported together in a *PartialError.
+func (r *Resource) RemoveMembersFromObjectACLs(ctx context.Context, bucketName string, members []string) (int, error) {
+	uniform, err := r.storage.BucketPolicyOnly(ctx, bucketName)
+	if err != nil || uniform {
+		return 0, err
+	}
+	var (
+		mu      sync.Mutex
+		removed int
+		partial = &PartialError{Failed: map[string]string{}}
+	)
+	err = r.storage.EachObjectACLPage(ctx, bucketName, func(page map[string]map[string]string) error {
+		var objects []string
+		for name, acl := range page {
+			for e := range acl {
+				if containsFold(members, e) {
+					objects = append(objects, name)
+					break
+				}
+			}
+		}
+		sort.Strings(objects)
+		err := ForEach(ctx, objects, maxConcurrentObjects, func(ctx context.Context, object string) error {
+			c := &Change{Kind: objectACLKind, Resource: "buckets/" + bucketName + "/objects/" + object}
+			defer logChange(ctx, c)
+			var entities []string
+			for e := range page[object] {
+				if containsFold(members, e) {
+					entities = append(entities, e)
+				}
+			}
+			sort.Strings(entities)
+			for _, e := range entities {
+				if err := r.storage.DeleteObjectACL(ctx, bucketName, object, e); err != nil {
+					return errors.Wrapf(err, "failed to remove %q", e)
+				}
+				c.Removed = append(c.Removed, Grant{Role: page[object][e], Member: e})
+				mu.Lock()
+				removed++
+				mu.Unlock()
+			}
+			return nil
+		})
+		if p, ok := err.(*PartialError); ok {
+			partial.Completed = append(partial.Completed, p.Completed...)
+			for k, v := range p.Failed {
+				partial.Failed[k] = v
+			}
+			return nil
+		}
+		partial.Completed = append(partial.Completed, objects...)
+		return err
+	})
+	if err != nil {
+		return removed, errors.Wrapf(err, "failed to list the objects of %q", bucketName)
+	}
+	if len(partial.Failed) > 0 {
+		sort.Strings(partial.Completed)
+		return removed, partial
+	}
+	return removed, nil
 }
 
-// EnableAuditLogs enable audit logs to all services and LogTypes.
-func (r *Resource) EnableAuditLogs(ctx context.Context, projectID string) (*crm.Policy, error) {
+// ProjectHasUsers reports whether any of the users is granted a role on the given project, compared
+// the same way RemoveUsersProject removes them.
+func (r *Resource) ProjectHasUsers(ctx context.Context, projectID string, users []string) (bool, error) {
+	policy, err := r.crm.GetPolicyProject(ctx, projectID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get project policy")
+	}
+	for _, b := range policy.Bindings {
+		for _, member := range b.Members {
+			if !strings.HasPrefix(member, "user:") {
+				continue
+			}
+			for _, user := range users {
+				if strings.EqualFold(user, member) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// ProjectOwners returns the email addresses of the users granted the owner role on the project.
+func (r *Resource) ProjectOwners(ctx context.Context, projectID string) ([]string, error) {
+	policy, err := r.crm.GetPolicyProject(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get project policy")
+	}
+	var owners []string
+	for _, b := range policy.Bindings {
+		if b.Role != "roles/owner" {
+			continue
+		}
+		for _, member := range b.Members {
+			if strings.HasPrefix(member, "user:") {
+				owners = append(owners, strings.TrimPrefix(member, "user:"))
+			}
+		}
+	}
+	return owners, nil
+}
+
+// ProjectGroups returns the email addresses of the groups granted a role on the project.
+func (r *Resource) ProjectGroups(ctx context.Context, projectID string) ([]string, error) {
+	policy, err := r.crm.GetPolicyProject(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get project policy")
+	}
+	seen := make(map[string]bool)
+	var groups []string
+	for _, b := range policy.Bindings {
+		for _, member := range b.Members {
+			if strings.HasPrefix(member, "group:") && !seen[member] {
+				seen[member] = true
+				groups = append(groups, strings.TrimPrefix(member, "group:"))
+			}
+		}
+	}
+	return groups, nil
+}
+
+// allServices is the audit config applying to every service of a project.
+const allServices = "allServices"
+
+// auditLogTypes are the log types enabled by EnableAuditLogs.
+var auditLogTypes = []string{"ADMIN_READ", "DATA_READ", "DATA_WRITE"}
+
+// AuditLogsEnabled reports whether every log type is already enabled for the services on the given
+// project, either by their own audit config or by the one of all services. No services stand for
+// all services.
+func (r *Resource) AuditLogsEnabled(ctx context.Context, projectID string, services []string) (bool, error) {
+	policy, err := r.crm.GetPolicyProject(ctx, projectID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get project policy")
+	}
+	enabled := make(map[string]bool)
+	for _, conf := range policy.AuditConfigs {
+		enabled[conf.Service] = logsEnabled(conf)
+	}
+	for _, service := range servicesOrAll(services) {
+		if !enabled[service] && !enabled[allServices] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// logsEnabled reports whether every log type is enabled by the audit config without exemptions.
+func logsEnabled(conf *crm.AuditConfig) bool {
+	enabled := make(map[string]bool)
+	for _, c := range conf.AuditLogConfigs {
+		if len(c.ExemptedMembers) == 0 {
+			enabled[c.LogType] = true
+		}
+	}
+	for _, t := range auditLogTypes {
+		if !enabled[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// servicesOrAll returns the services, or all services if there are none.
+func servicesOrAll(services []string) []string {
+	if len(services) == 0 {
+		return []string{allServices}
+	}
+	return services
+}
+
+// EnableAuditLogs enables every log type for the services on the given project, all services if
+// none are given. Services already logged through the audit config of all services and the audit
+// configs of other services are left as they are.
+func (r *Resource) EnableAuditLogs(ctx context.Context, projectID string, services []string) (*crm.Policy, error) {
+	var result *crm.Policy
+	err := updatePolicy(func() error {
+		var err error
+		result, err = r.enableAuditLogs(ctx, projectID, servicesOrAll(services))
+		return err
+	})
+	return result, err
+}
+
+func (r *Resource) enableAuditLogs(ctx context.Context, projectID string, services []string) (*crm.Policy, error) {
 	res, err := r.crm.GetPolicyProject(ctx, projectID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get project policy")
 	}
-	isDefault := false
-	enableAll := &crm.AuditConfig{
-		AuditLogConfigs: []*crm.AuditLogConfig{
-			{LogType: "ADMIN_READ"},
-			{LogType: "DATA_READ"},
-			{LogType: "DATA_WRITE"},
-		},
-		Service: "allServices",
+	before, err := json.Marshal(res.AuditConfigs)
+	if err != nil {
+		return nil, err
 	}
+	covered := false
 	for _, conf := range res.AuditConfigs {
-		if conf.Service == "allServices" {
-			conf.AuditLogConfigs = enableAll.AuditLogConfigs
-			isDefault = true
+		if conf.Service == allServices && logsEnabled(conf) {
+			covered = true
 		}
 	}
-	if !isDefault {
-		res.AuditConfigs = append(res.AuditConfigs, enableAll)
+	for _, service := range services {
+		if covered && service != allServices {
+			continue
+		}
+		var conf *crm.AuditConfig
+		for _, c := range res.AuditConfigs {
+			if c.Service == service {
+				conf = c
+			}
+		}
+		if conf == nil {
+			conf = &crm.AuditConfig{Service: service}
+			res.AuditConfigs = append(res.AuditConfigs, conf)
+		}
+		if !logsEnabled(conf) {
+			enableLogTypes(conf)
+		}
 	}
 
 	result, err := r.crm.SetPolicyProjectWithMask(ctx, projectID, res, "auditConfigs")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to update project policy")
 	}
+	logChange(ctx, &Change{Kind: projectPolicyKind, Resource: "projects/" + projectID, Fields: map[string]FieldChange{
+		"auditConfigs": {Before: json.RawMessage(before), After: res.AuditConfigs},
+	}})
 	return result, nil
 }
 
+// enableLogTypes enables every log type in the audit config, removing their exemptions. Other log
+// types configured are left as they are.
+func enableLogTypes(conf *crm.AuditConfig) {
+	for _, t := range auditLogTypes {
+		found := false
+		for _, c := range conf.AuditLogConfigs {
+			if c.LogType == t {
+				c.ExemptedMembers = nil
+				found = true
+			}
+		}
+		if !found {
+			conf.AuditLogConfigs = append(conf.AuditLogConfigs, &crm.AuditLogConfig{LogType: t})
+		}
+	}
+}
+
+// updatePolicy runs the read-modify-write of a policy again while the write is rejected because the
+// policy's ETag no longer matches, the policy having changed since it was read, at most
+// policyAttempts times.
+func updatePolicy(update func() error) error {
+	var err error
+	for i := 0; i < policyAttempts; i++ {
+		if err = update(); !policyChanged(err) {
+			return err
+		}
+	}
+	return errors.Wrapf(err, "policy kept changing after %d attempts", policyAttempts)
+}
+
+// policyChanged reports whether a policy write was rejected because of a stale ETag, with ABORTED
+// by Cloud Resource Manager over gRPC, 409 over REST and 412 by Cloud Storage.
+func policyChanged(err error) bool {
+	if status.Code(errors.Cause(err)) == codes.Aborted {
+		return true
+	}
+	e, ok := errors.Cause(err).(*googleapi.Error)
+	return ok && (e.Code == http.StatusConflict || e.Code == http.StatusPreconditionFailed)
+}
+
 // keepUsersFromPolicy keeps users if they match the given domain.
 func (r *Resource) keepUsersFromPolicy(policy *crm.Policy, allowedDomains []string) ([]string, *crm.Policy, error) {
 	// Throw an error if no allowed domains are passed. Otherwise all users would be removed.
@@ -175,49 +504,87 @@ func (r *Resource) keepUsersFromPolicy(policy *crm.Policy, allowedDomains []stri
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to compile regex: %q", err)
 	}
-	removed := []string{}
-	for _, b := range policy.Bindings {
-		members := []string{}
-		for _, member := range b.Members {
-			isUser := strings.HasPrefix(member, "user:")
-			found := false
-			if allowedRegExp.MatchString(member) {
-				found = true
-			}
-			if !isUser || found {
-				members = append(members, member)
-				continue
-			}
-			if isUser && !found {
-				removed = append(removed, member)
-			}
-		}
-		b.Members = members
-	}
+	removed := filterBindings(policy.Bindings, func(member string) bool {
+		return !strings.HasPrefix(member, "user:") || allowedRegExp.MatchString(member)
+	})
 	return removed, policy, nil
 }
 
 // removeUsersFromPolicy removes a slice of users from a policy
 func (r *Resource) removeUsersFromPolicy(policy *crm.Policy, users []string) *crm.Policy {
-	for _, b := range policy.Bindings {
-		members := []string{}
-		for _, member := range b.Members {
-			isUser := strings.HasPrefix(member, "user:")
-			found := false
-			for _, user := range users {
-				if strings.EqualFold(user, member) {
-					found = true
-					break
-				}
+	remove := make(map[string]bool, len(users))
+	for _, u := range users {
+		remove[strings.ToLower(u)] = true
+	}
+	filterBindings(policy.Bindings, func(member string) bool {
+		return !strings.HasPrefix(member, "user:") || !remove[strings.ToLower(member)]
+	})
+	return policy
+}
+
+// memberChunk is the number of members of a binding filterBindings checks in one goroutine.
+const memberChunk = 1000
+
+// filterBindings keeps the members of the bindings keep reports true for and returns the others,
+// in the order of the bindings and of their members. Policies with thousands of members are
+// checked concurrently, a chunk of a binding's members per goroutine and at most a goroutine per
+// CPU, so they are filtered before the single write of the policy.
+func filterBindings(bindings []*crm.Binding, keep func(string) bool) []string {
+	type chunk struct {
+		binding, start, end int
+		kept, dropped       []string
+	}
+	var chunks []*chunk
+	for i, b := range bindings {
+		for start := 0; start < len(b.Members); start += memberChunk {
+			end := start + memberChunk
+			if end > len(b.Members) {
+				end = len(b.Members)
 			}
-			if !isUser || !found {
-				members = append(members, member)
+			chunks = append(chunks, &chunk{binding: i, start: start, end: end})
+		}
+	}
+	filter := func(c *chunk) {
+		c.kept = []string{}
+		for _, m := range bindings[c.binding].Members[c.start:c.end] {
+			if keep(m) {
+				c.kept = append(c.kept, m)
 				continue
 			}
+			c.dropped = append(c.dropped, m)
 		}
-		b.Members = members
 	}
-	return policy
+	if len(chunks) <= 1 {
+		for _, c := range chunks {
+			filter(c)
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+		for _, c := range chunks {
+			wg.Add(1)
+			go func(c *chunk) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				filter(c)
+			}(c)
+		}
+		wg.Wait()
+	}
+	dropped := []string{}
+	members := make([][]string, len(bindings))
+	for i := range members {
+		members[i] = []string{}
+	}
+	for _, c := range chunks {
+		members[c.binding] = append(members[c.binding], c.kept...)
+		dropped = append(dropped, c.dropped...)
+	}
+	for i, b := range bindings {
+		b.Members = members[i]
+	}
+	return dropped
 }
 
 // PolicyOrganization returns the IAM policy for the given resource name.
@@ -225,9 +592,102 @@ func (r *Resource) PolicyOrganization(ctx context.Context, name string) (*crm.Po
 	return r.crm.GetPolicyOrganization(ctx, name)
 }
 
-// Organization returns the organization name for the given organization resource.
+// organizationTTL is how long Organization reuses the organizations it looked up.
+const organizationTTL = 10 * time.Minute
+
+// organizationKey identifies an organization looked up with a client.
+type organizationKey struct {
+	crm  crmClient
+	name string
+}
+
+// cachedOrganization is locked while the organization is looked up, so lookups of the same
+// organization wait for the first one while those of others go ahead.
+type cachedOrganization struct {
+	mu           sync.Mutex
+	organization *crm.Organization
+	fetched      time.Time
+}
+
+// organizations holds the organizations looked up by every Resource of the function instance, so
+// bursts of findings do not look up the same organization for each message. Its lock only guards
+// the map, each entry has its own.
+var organizations = struct {
+	sync.Mutex
+	cache map[organizationKey]*cachedOrganization
+}{cache: make(map[organizationKey]*cachedOrganization)}
+
+// Organization returns the organization name for the given organization resource, cached for
+// organizationTTL.
 func (r *Resource) Organization(ctx context.Context, orgID string) (*crm.Organization, error) {
-	return r.crm.GetOrganization(ctx, "organizations/"+orgID)
+	k := organizationKey{crm: r.crm, name: "organizations/" + orgID}
+	organizations.Lock()
+	o, ok := organizations.cache[k]
+	if !ok {
+		o = &cachedOrganization{}
+		organizations.cache[k] = o
+	}
+	organizations.Unlock()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.fetched.IsZero() && time.Since(o.fetched) < organizationTTL {
+		return o.organization, nil
+	}
+	org, err := r.crm.GetOrganization(ctx, k.name)
+	if err != nil {
+		return nil, err
+	}
+	o.organization, o.fetched = org, time.Now()
+	return org, nil
+}
+
+// Folder returns the folder with the given ID.
+func (r *Resource) Folder(ctx context.Context, folderID string) (*crmv2.Folder, error) {
+	return r.crm.GetFolder(ctx, "folders/"+folderID)
+}
+
+// Project returns the project.
+func (r *Resource) Project(ctx context.Context, projectID string) (*crm.Project, error) {
+	return r.crm.GetProject(ctx, projectID)
+}
+
+// FolderRoles returns the roles granted to the member on the folder.
+func (r *Resource) FolderRoles(ctx context.Context, folderID, member string) ([]string, error) {
+	p, err := r.crm.GetPolicyFolder(ctx, "folders/"+folderID)
+	if err != nil {
+		return nil, err
+	}
+	var roles []string
+	for _, b := range p.Bindings {
+		for _, m := range b.Members {
+			if m == member {
+				roles = append(roles, b.Role)
+			}
+		}
+	}
+	return roles, nil
+}
+
+// OrganizationRoles returns the roles granted to the member on the organization.
+func (r *Resource) OrganizationRoles(ctx context.Context, orgID, member string) ([]string, error) {
+	p, err := r.crm.GetPolicyOrganization(ctx, "organizations/"+orgID)
+	if err != nil {
+		return nil, err
+	}
+	var roles []string
+	for _, b := range p.Bindings {
+		for _, m := range b.Members {
+			if m == member {
+				roles = append(roles, b.Role)
+			}
+		}
+	}
+	return roles, nil
+}
+
+// BucketPolicyOnlyEnabled reports whether bucket only policy is already enabled on the given bucket.
+func (r *Resource) BucketPolicyOnlyEnabled(ctx context.Context, bucketName string) (bool, error) {
+	return r.storage.BucketPolicyOnly(ctx, bucketName)
 }
 
 // EnableBucketOnlyPolicy enable bucket only policy for the given bucket
@@ -235,8 +695,144 @@ func (r *Resource) EnableBucketOnlyPolicy(ctx context.Context, bucketName string
 	return r.storage.EnableBucketOnlyPolicy(ctx, bucketName)
 }
 
+// Folders returns the IDs of the folders the project is in, from its parent up.
+func (r *Resource) Folders(ctx context.Context, projectID string) ([]string, error) {
+	resp, err := r.ancestry(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get project ancestry")
+	}
+	var folders []string
+	for _, a := range resp.Ancestor {
+		if a.ResourceId.Type == "folder" {
+			folders = append(folders, a.ResourceId.Id)
+		}
+	}
+	return folders, nil
+}
+
+// EachProject calls fn with each active project directly under the folder, following every page
+// of results, and stops at the first error fn returns. Projects pending deletion are skipped, they
+// can no longer be changed.
+func (r *Resource) EachProject(ctx context.Context, folderID string, fn func(*crm.Project) error) error {
+	filter := fmt.Sprintf("parent.type:folder parent.id:%s lifecycleState:ACTIVE", folderID)
+	for token := ""; ; {
+		resp, err := r.crm.ListProjects(ctx, filter, token)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list projects of folder %q", folderID)
+		}
+		for _, p := range resp.Projects {
+			if p.LifecycleState != "ACTIVE" {
+				continue
+			}
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if token = resp.NextPageToken; token == "" {
+			return nil
+		}
+	}
+}
+
+// EachFolder calls fn with each folder directly under the parent, "folders/<id>" or
+// "organizations/<id>", following every page of results, and stops at the first error fn returns.
+func (r *Resource) EachFolder(ctx context.Context, parent string, fn func(*crmv2.Folder) error) error {
+	for token := ""; ; {
+		resp, err := r.crm.ListFolders(ctx, parent, token)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list folders of %q", parent)
+		}
+		for _, f := range resp.Folders {
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+		if token = resp.NextPageToken; token == "" {
+			return nil
+		}
+	}
+}
+
+// Projects returns the IDs of the active projects directly under the folder.
+func (r *Resource) Projects(ctx context.Context, folderID string) ([]string, error) {
+	var ids []string
+	err := r.EachProject(ctx, folderID, func(p *crm.Project) error {
+		ids = append(ids, p.ProjectId)
+		return nil
+	})
+	return ids, err
+}
+
+// maxConcurrentFolders bounds the folders ProjectsUnder lists at once.
+const maxConcurrentFolders = 8
+
+// ProjectsUnder returns the IDs of the active projects of the folders and of their sub-folders at
+// any depth. The folders of each level are listed concurrently, so that organizations with hundreds
+// of projects are walked within the function's timeout. Folders failing to list do not stop the
+// others, they are reported in a *PartialError along with the projects of the others and the
+// folders listed.
+func (r *Resource) ProjectsUnder(ctx context.Context, folderIDs []string) ([]string, error) {
+	var (
+		mu        sync.Mutex
+		projects  []string
+		completed []string
+		failed    = map[string]string{}
+		seen      = map[string]bool{}
+	)
+	for level := folderIDs; len(level) > 0; {
+		var next []string
+		err := ForEach(ctx, level, maxConcurrentFolders, func(ctx context.Context, folderID string) error {
+			var ids, subfolders []string
+			if err := r.EachProject(ctx, folderID, func(p *crm.Project) error {
+				ids = append(ids, p.ProjectId)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if err := r.EachFolder(ctx, "folders/"+folderID, func(f *crmv2.Folder) error {
+				subfolders = append(subfolders, strings.TrimPrefix(f.Name, "folders/"))
+				return nil
+			}); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			projects = append(projects, ids...)
+			next = append(next, subfolders...)
+			return nil
+		})
+		switch partial, ok := err.(*PartialError); {
+		case ok:
+			completed = append(completed, partial.Completed...)
+			for k, v := range partial.Failed {
+				failed[k] = v
+			}
+		case err != nil:
+			return nil, err
+		default:
+			completed = append(completed, level...)
+		}
+		for _, id := range level {
+			seen[id] = true
+		}
+		level = nil
+		for _, id := range next {
+			if !seen[id] {
+				seen[id] = true
+				level = append(level, id)
+			}
+		}
+	}
+	sort.Strings(projects)
+	if len(failed) > 0 {
+		sort.Strings(completed)
+		return projects, &PartialError{Completed: completed, Failed: failed}
+	}
+	return projects, nil
+}
+
 func (r *Resource) getProjectAncestryPath(ctx context.Context, projectID string) (string, error) {
-	resp, err := r.crm.GetAncestry(ctx, projectID)
+	resp, err := r.ancestry(ctx, projectID)
 	if err != nil {
 		return "", err
 	}
@@ -249,7 +845,8 @@ func (r *Resource) getProjectAncestryPath(ctx context.Context, projectID string)
 
 func (r *Resource) ancestryMatches(patterns []string, ancestorPath string) (bool, error) {
 	for _, pattern := range patterns {
-		match, err := regexp.MatchString("^"+strings.Replace(pattern, "*", ".*", -1), ancestorPath)
+		// Patterns match whole path segments, organizations/45 does not match organizations/456.
+		match, err := regexp.MatchString("^"+strings.Replace(pattern, "*", ".*", -1)+"(/|$)", ancestorPath)
 		if err != nil {
 			return false, errors.Wrapf(err, "failed to parse: %s", pattern)
 		}
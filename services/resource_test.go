@@ -16,12 +16,14 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"cloud.google.com/go/iam"
 	"github.com/google/go-cmp/cmp"
 	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
 	crm "google.golang.org/api/cloudresourcemanager/v1"
+	crmv2 "google.golang.org/api/cloudresourcemanager/v2"
 )
 
 // TestRemoveUsersProject tests the removal of members from a policy.
@@ -197,6 +199,7 @@ func TestEnableAuditLogsOnProject(t *testing.T) {
 	tests := []struct {
 		name           string
 		existingConfig *crm.AuditConfig
+		services       []string
 		expectedConfig []*crm.AuditConfig
 	}{
 		{
@@ -221,10 +224,41 @@ func TestEnableAuditLogsOnProject(t *testing.T) {
 			existingConfig: &crm.AuditConfig{
 				AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}}, Service: "allServices",
 			},
+			expectedConfig: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}, {LogType: "ADMIN_READ"}}, Service: "allServices"},
+			},
+		},
+		{
+			name: "services covered by all services",
+			existingConfig: &crm.AuditConfig{
+				AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}, {LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}}, Service: "allServices",
+			},
+			services: []string{"cloudsql.googleapis.com"},
 			expectedConfig: []*crm.AuditConfig{
 				{AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}, {LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}}, Service: "allServices"},
 			},
 		},
+		{
+			name: "remove exemptions only",
+			existingConfig: &crm.AuditConfig{
+				AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}, {LogType: "DATA_READ", ExemptedMembers: []string{"user:alice@example.com"}}}, Service: "storage.googleapis.com",
+			},
+			services: []string{"storage.googleapis.com"},
+			expectedConfig: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}, {LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}}, Service: "storage.googleapis.com"},
+			},
+		},
+		{
+			name: "enable log types of services",
+			existingConfig: &crm.AuditConfig{
+				AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}}, Service: "cloudsql.googleapis.com",
+			},
+			services: []string{"cloudsql.googleapis.com", "storage.googleapis.com"},
+			expectedConfig: []*crm.AuditConfig{
+				{AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}, {LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}}, Service: "cloudsql.googleapis.com"},
+				{AuditLogConfigs: []*crm.AuditLogConfig{{LogType: "ADMIN_READ"}, {LogType: "DATA_READ"}, {LogType: "DATA_WRITE"}}, Service: "storage.googleapis.com"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		ctx := context.Background()
@@ -232,7 +266,7 @@ func TestEnableAuditLogsOnProject(t *testing.T) {
 
 		r := NewResource(crmStub, nil)
 		t.Run(tt.name, func(t *testing.T) {
-			res, err := r.EnableAuditLogs(ctx, "test-project-sra")
+			res, err := r.EnableAuditLogs(ctx, "test-project-sra", tt.services)
 			if err != nil {
 				t.Errorf("%s failed exp:%v got:%q", tt.name, nil, err)
 			}
@@ -277,6 +311,9 @@ func TestCheckMatches(t *testing.T) {
 		{name: "project not in target and in ignore", mustMatch: false, target: "organizations/456/folders/123/projects/yet-other-project", ignore: "organizations/456/folders/123/projects/" + projectID},
 		{name: "org not in target and not in ignore", mustMatch: false, target: "", ignore: ""},
 		{name: "specify project in any folder", mustMatch: true, target: "organizations/456/*/projects/test-project", ignore: "organizations/456/folders/12/*"},
+		{name: "whole org in target", mustMatch: true, target: "organizations/456", ignore: ""},
+		{name: "org prefix of another org", mustMatch: false, target: "organizations/45", ignore: ""},
+		{name: "project prefix of another project", mustMatch: false, target: "organizations/456/folders/123/projects/test", ignore: ""},
 	}
 
 	for _, tt := range tests {
@@ -297,3 +334,240 @@ func TestCheckMatches(t *testing.T) {
 	}
 
 }
+
+func TestPolicyConflicts(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		conflicts   int
+		expectError bool
+	}{
+		{name: "no conflict", conflicts: 0},
+		{name: "retries stale etags", conflicts: policyAttempts - 1},
+		{name: "gives up after the attempts", conflicts: policyAttempts, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crmStub := &stubs.ResourceManagerStub{
+				PolicyConflicts: tt.conflicts,
+				GetPolicyResponse: &crm.Policy{Bindings: []*crm.Binding{
+					{Role: "roles/editor", Members: []string{"user:bob@gmail.com", "user:ddgo@cloudorg.com"}},
+				}},
+			}
+			storageStub := &stubs.StorageStub{PolicyConflicts: tt.conflicts, BucketPolicyResponse: &iam.Policy{}}
+			storageStub.BucketPolicyResponse.Add("allUsers", "roles/storage.objectViewer")
+			r := NewResource(crmStub, storageStub)
+			if err := r.RemoveUsersProject(ctx, "project", []string{"user:bob@gmail.com"}); (err != nil) != tt.expectError {
+				t.Errorf("%s RemoveUsersProject got:%v want error:%v", tt.name, err, tt.expectError)
+			}
+			if err := r.RemoveMembersFromBucket(ctx, "bucket", []string{"allUsers"}); (err != nil) != tt.expectError {
+				t.Errorf("%s RemoveMembersFromBucket got:%v want error:%v", tt.name, err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if diff := cmp.Diff([]string{"user:ddgo@cloudorg.com"}, crmStub.SavedSetPolicy.Bindings[0].Members); diff != "" {
+				t.Errorf("%s project policy difference:%+v", tt.name, diff)
+			}
+			if m := storageStub.BucketPolicyResponse.Members("roles/storage.objectViewer"); len(m) != 0 {
+				t.Errorf("%s bucket members got:%v want none", tt.name, m)
+			}
+		})
+	}
+}
+
+func TestFolders(t *testing.T) {
+	crmStub := &stubs.ResourceManagerStub{
+		GetAncestryResponse: CreateAncestors([]string{"project/projectID", "folder/team", "folder/business-unit", "organization/organizationID"}),
+	}
+	r := NewResource(crmStub, &stubs.StorageStub{})
+	folders, err := r.Folders(context.Background(), "projectID")
+	if err != nil {
+		t.Fatalf("Folders failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"team", "business-unit"}, folders); diff != "" {
+		t.Errorf("folders difference: %+v", diff)
+	}
+}
+
+func TestProjectsPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		pages    [][]*crm.Project
+		expected []string
+	}{
+		{name: "no projects", pages: nil, expected: nil},
+		{name: "one page", pages: [][]*crm.Project{{active("a"), active("b")}}, expected: []string{"a", "b"}},
+		{
+			name:     "three pages",
+			pages:    [][]*crm.Project{{active("a")}, {active("b"), active("c")}, {active("d")}},
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "skips deleted projects",
+			pages:    [][]*crm.Project{{active("a"), {ProjectId: "b", LifecycleState: "DELETE_REQUESTED"}}, {active("c")}},
+			expected: []string{"a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewResource(&stubs.ResourceManagerStub{ProjectPages: tt.pages}, &stubs.StorageStub{})
+			got, err := r.Projects(context.Background(), "123")
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("%s (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+// active returns an active project with the ID.
+func active(id string) *crm.Project {
+	return &crm.Project{ProjectId: id, LifecycleState: "ACTIVE"}
+}
+
+func TestEachFolderPages(t *testing.T) {
+	crmStub := &stubs.ResourceManagerStub{FolderPages: map[string][][]*crmv2.Folder{
+		"organizations/456": {{{Name: "folders/1"}}, {{Name: "folders/2"}, {Name: "folders/3"}}},
+	}}
+	r := NewResource(crmStub, &stubs.StorageStub{})
+	var got []string
+	err := r.EachFolder(context.Background(), "organizations/456", func(f *crmv2.Folder) error {
+		got = append(got, f.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachFolder failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"folders/1", "folders/2", "folders/3"}, got); diff != "" {
+		t.Errorf("folders (-want +got):\n%s", diff)
+	}
+}
+
+func TestProjectsUnder(t *testing.T) {
+	crmFake := stubs.NewResourceManagerFake()
+	crmFake.AddOrganization("456")
+	crmFake.AddFolder("1", "organizations/456")
+	crmFake.AddFolder("2", "folders/1")
+	crmFake.AddFolder("3", "folders/2")
+	crmFake.AddFolder("4", "organizations/456")
+	crmFake.AddProject("top", 1, "folders/1")
+	crmFake.AddProject("middle", 2, "folders/2")
+	crmFake.AddProject("bottom", 3, "folders/3")
+	crmFake.AddProject("sibling", 4, "folders/4")
+	crmFake.AddProject("org", 5, "organizations/456")
+	r := NewResource(crmFake, &stubs.StorageStub{})
+
+	got, err := r.ProjectsUnder(context.Background(), []string{"1"})
+	if err != nil {
+		t.Fatalf("ProjectsUnder failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"bottom", "middle", "top"}, got); diff != "" {
+		t.Errorf("projects (-want +got):\n%s", diff)
+	}
+	got, err = r.ProjectsUnder(context.Background(), []string{"2", "4", "3"})
+	if err != nil {
+		t.Fatalf("ProjectsUnder failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"bottom", "middle", "sibling"}, got); diff != "" {
+		t.Errorf("projects (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemoveMembersFromObjectACLs(t *testing.T) {
+	ctx := context.Background()
+	storageFake := stubs.NewStorageFake()
+	storageFake.AddBucket("fine-grained", nil)
+	storageFake.SetObjectACL("fine-grained", "a.txt", map[string]string{"allUsers": "READER", "user-tom@example.com": "OWNER"})
+	storageFake.SetObjectACL("fine-grained", "b.txt", map[string]string{"user-tom@example.com": "OWNER"})
+	storageFake.AddBucket("uniform", nil)
+	if err := storageFake.EnableBucketOnlyPolicy(ctx, "uniform"); err != nil {
+		t.Fatalf("failed to enable uniform access: %q", err)
+	}
+	r := NewResource(stubs.NewResourceManagerFake(), storageFake)
+
+	removed, err := r.RemoveMembersFromObjectACLs(ctx, "fine-grained", []string{"allUsers", "allAuthenticatedUsers"})
+	if err != nil || removed != 1 {
+		t.Fatalf("got %d removed and %v, want 1 and no error", removed, err)
+	}
+	if diff := cmp.Diff(map[string]string{"user-tom@example.com": "OWNER"}, storageFake.ObjectACL("fine-grained", "a.txt")); diff != "" {
+		t.Errorf("a.txt ACL (-want +got):\n%s", diff)
+	}
+	if removed, err := r.RemoveMembersFromObjectACLs(ctx, "uniform", []string{"allUsers"}); err != nil || removed != 0 {
+		t.Errorf("got %d removed and %v for a uniform bucket, want none", removed, err)
+	}
+}
+
+func TestFilterBindings(t *testing.T) {
+	var members, want []string
+	for i := 0; i < 2*memberChunk+10; i++ {
+		m := fmt.Sprintf("user:%d@example.com", i)
+		members = append(members, m)
+		if i%3 != 0 {
+			want = append(want, m)
+		}
+	}
+	bindings := []*crm.Binding{
+		{Role: "roles/viewer", Members: members},
+		{Role: "roles/owner", Members: []string{"user:0@example.com"}},
+	}
+	dropped := filterBindings(bindings, func(m string) bool {
+		var i int
+		fmt.Sscanf(m, "user:%d@", &i)
+		return i%3 != 0
+	})
+	if diff := cmp.Diff(want, bindings[0].Members); diff != "" {
+		t.Errorf("viewers (-want +got):\n%s", diff)
+	}
+	if n := len(bindings[1].Members); n != 0 {
+		t.Errorf("got %d owners, want 0", n)
+	}
+	if n := len(dropped); n != len(members)-len(want)+1 {
+		t.Errorf("got %d dropped, want %d", n, len(members)-len(want)+1)
+	}
+}
+
+// largePolicy returns a policy of 50 bindings of 2000 users each, half of them outside example.com.
+func largePolicy() *crm.Policy {
+	p := &crm.Policy{}
+	for i := 0; i < 50; i++ {
+		b := &crm.Binding{Role: fmt.Sprintf("roles/custom%d", i)}
+		for j := 0; j < 2000; j++ {
+			domain := "example.com"
+			if j%2 == 0 {
+				domain = "gmail.com"
+			}
+			b.Members = append(b.Members, fmt.Sprintf("user:user%d@%s", j, domain))
+		}
+		p.Bindings = append(p.Bindings, b)
+	}
+	return p
+}
+
+func BenchmarkKeepUsersFromPolicy(b *testing.B) {
+	r := NewResource(&stubs.ResourceManagerStub{}, &stubs.StorageStub{})
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		p := largePolicy()
+		b.StartTimer()
+		if _, _, err := r.keepUsersFromPolicy(p, []string{"example.com"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRemoveUsersFromPolicy(b *testing.B) {
+	r := NewResource(&stubs.ResourceManagerStub{}, &stubs.StorageStub{})
+	var users []string
+	for j := 0; j < 2000; j += 2 {
+		users = append(users, fmt.Sprintf("user:user%d@gmail.com", j))
+	}
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		p := largePolicy()
+		b.StartTimer()
+		r.removeUsersFromPolicy(p, users)
+	}
+}
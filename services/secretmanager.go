@@ -0,0 +1,116 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// SecretManagerClient holds the minimum interface required by the Secret Manager service.
+type SecretManagerClient interface {
+	GetIamPolicy(context.Context, string) (*secretmanager.Policy, error)
+	SetIamPolicy(context.Context, string, *secretmanager.Policy) (*secretmanager.Policy, error)
+	ListSecretVersions(context.Context, string) ([]*secretmanager.SecretVersion, error)
+	AddSecretVersion(context.Context, string, []byte) (*secretmanager.SecretVersion, error)
+	DisableSecretVersion(context.Context, string) (*secretmanager.SecretVersion, error)
+}
+
+// SecretManager service remediates secrets, Secrets reads those holding configuration values.
+type SecretManager struct {
+	client SecretManagerClient
+}
+
+// NewSecretManager returns a new Secret Manager service.
+func NewSecretManager(client SecretManagerClient) *SecretManager {
+	return &SecretManager{client: client}
+}
+
+// ExternalMembers returns the members of the IAM policy of the secret, named
+// "projects/<id>/secrets/<secret>", granting access to anyone: allUsers and allAuthenticatedUsers.
+// Users, groups and domains outside the allowed domains are returned too if there are any.
+func (s *SecretManager) ExternalMembers(ctx context.Context, name string, allowDomains []string) ([]string, error) {
+	p, err := s.client.GetIamPolicy(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return externalMembers(secretBindings(p), allowDomains), nil
+}
+
+// RemoveMembers removes the members from every binding of the IAM policy of the secret. The grants
+// removed are logged as a change, so they can be granted again.
+func (s *SecretManager) RemoveMembers(ctx context.Context, name string, members []string) error {
+	return updatePolicy(func() error {
+		p, err := s.client.GetIamPolicy(ctx, name)
+		if err != nil {
+			return err
+		}
+		before := bindingGrants(secretBindings(p))
+		var bindings []*secretmanager.Binding
+		for _, b := range p.Bindings {
+			if b.Members = withoutMembers(b.Members, members); len(b.Members) > 0 {
+				bindings = append(bindings, b)
+			}
+		}
+		p.Bindings = bindings
+		if _, err := s.client.SetIamPolicy(ctx, name, p); err != nil {
+			return err
+		}
+		logChange(ctx, policyChange(secretPolicyKind, name, before, bindingGrants(secretBindings(p))))
+		return nil
+	})
+}
+
+// Rotate adds a version holding the payload to the secret then disables the versions enabled
+// before, returning the name of the version added and those disabled. Each version disabled is
+// logged as a change, so it can be enabled again.
+func (s *SecretManager) Rotate(ctx context.Context, name string, payload []byte) (string, []string, error) {
+	versions, err := s.client.ListSecretVersions(ctx, name)
+	if err != nil {
+		return "", nil, err
+	}
+	added, err := s.client.AddSecretVersion(ctx, name, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	var disabled []string
+	for _, v := range versions {
+		if v.State != "ENABLED" {
+			continue
+		}
+		if _, err := s.client.DisableSecretVersion(ctx, v.Name); err != nil {
+			return added.Name, disabled, err
+		}
+		disabled = append(disabled, v.Name)
+		logChange(ctx, &Change{Kind: secretVersionKind, Resource: v.Name, Fields: map[string]FieldChange{
+			"state": {Before: "ENABLED", After: "DISABLED"},
+		}})
+	}
+	return added.Name, disabled, nil
+}
+
+// secretBindings returns the bindings of a Secret Manager policy.
+func secretBindings(policy *secretmanager.Policy) []binding {
+	var bindings []binding
+	for _, b := range policy.Bindings {
+		condition := ""
+		if b.Condition != nil {
+			condition = b.Condition.Expression
+		}
+		bindings = append(bindings, binding{role: b.Role, condition: condition, members: b.Members})
+	}
+	return bindings
+}
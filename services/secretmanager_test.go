@@ -0,0 +1,105 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+const testSecret = "projects/test-project/secrets/api-key"
+
+func TestSecretExternalMembers(t *testing.T) {
+	ctx := context.Background()
+	policy := &secretmanager.Policy{Bindings: []*secretmanager.Binding{
+		{Role: "roles/secretmanager.secretAccessor", Members: []string{"allUsers", "group:ops@example.com", "group:ops@attacker.com"}},
+		{Role: "roles/secretmanager.viewer", Members: []string{"allAuthenticatedUsers", "serviceAccount:sa@test-project.iam.gserviceaccount.com"}},
+	}}
+	for _, tt := range []struct {
+		name         string
+		allowDomains []string
+		want         []string
+	}{
+		{name: "public", want: []string{"allUsers", "allAuthenticatedUsers"}},
+		{name: "external", allowDomains: []string{"example.com"}, want: []string{"allUsers", "group:ops@attacker.com", "allAuthenticatedUsers"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			smStub := &stubs.SecretManagerStub{StubbedPolicies: map[string]*secretmanager.Policy{testSecret: policy}}
+			got, err := NewSecretManager(smStub).ExternalMembers(ctx, testSecret, tt.allowDomains)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s members (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestSecretRemoveMembers(t *testing.T) {
+	ctx := context.Background()
+	smStub := &stubs.SecretManagerStub{StubbedPolicies: map[string]*secretmanager.Policy{testSecret: {
+		Etag: "etag-1",
+		Bindings: []*secretmanager.Binding{
+			{Role: "roles/secretmanager.secretAccessor", Members: []string{"allUsers", "group:ops@example.com"}},
+			{Role: "roles/secretmanager.viewer", Members: []string{"allAuthenticatedUsers"}},
+		},
+	}}}
+	if err := NewSecretManager(smStub).RemoveMembers(ctx, testSecret, []string{"allUsers", "allAuthenticatedUsers"}); err != nil {
+		t.Fatalf("RemoveMembers failed: %q", err)
+	}
+	want := &secretmanager.Policy{
+		Etag: "etag-1",
+		Bindings: []*secretmanager.Binding{
+			{Role: "roles/secretmanager.secretAccessor", Members: []string{"group:ops@example.com"}},
+		},
+	}
+	if diff := cmp.Diff(want, smStub.SavedPolicy); diff != "" {
+		t.Errorf("RemoveMembers policy (-want +got):\n%s", diff)
+	}
+	if smStub.SavedSecretName != testSecret {
+		t.Errorf("RemoveMembers set the policy of %q, want %q", smStub.SavedSecretName, testSecret)
+	}
+}
+
+func TestSecretRotate(t *testing.T) {
+	ctx := context.Background()
+	smStub := &stubs.SecretManagerStub{StubbedVersions: map[string][]*secretmanager.SecretVersion{testSecret: {
+		{Name: testSecret + "/versions/1", State: "DESTROYED"},
+		{Name: testSecret + "/versions/2", State: "DISABLED"},
+		{Name: testSecret + "/versions/3", State: "ENABLED"},
+	}}}
+	added, disabled, err := NewSecretManager(smStub).Rotate(ctx, testSecret, []byte("new-value"))
+	if err != nil {
+		t.Fatalf("Rotate failed: %q", err)
+	}
+	if added != testSecret+"/versions/new" {
+		t.Errorf("Rotate added %q", added)
+	}
+	if string(smStub.AddedPayload) != "new-value" {
+		t.Errorf("Rotate added payload %q, want %q", smStub.AddedPayload, "new-value")
+	}
+	want := []string{testSecret + "/versions/3"}
+	if diff := cmp.Diff(want, disabled); diff != "" {
+		t.Errorf("Rotate disabled (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, smStub.DisabledVersions); diff != "" {
+		t.Errorf("Rotate disabled versions (-want +got):\n%s", diff)
+	}
+}
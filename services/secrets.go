@@ -0,0 +1,82 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SecretPrefix marks configuration values that reference a Secret Manager secret, such as
+// "secretmanager://projects/my-project/secrets/sendgrid/versions/latest".
+const SecretPrefix = "secretmanager://"
+
+// SecretsClient holds the minimum interface required by the secrets service.
+type SecretsClient interface {
+	AccessSecretVersion(context.Context, string) ([]byte, error)
+}
+
+// Secrets service reads secrets from Secret Manager, caching them in memory.
+type Secrets struct {
+	client SecretsClient
+	ttl    time.Duration
+	mu     sync.Mutex
+	cache  map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value   string
+	fetched time.Time
+}
+
+// NewSecrets returns a new secrets service, secrets are read again once they are older than ttl.
+func NewSecrets(client SecretsClient, ttl time.Duration) *Secrets {
+	return &Secrets{client: client, ttl: ttl, cache: make(map[string]cachedSecret)}
+}
+
+// Resolve returns the value, or the secret it references if it starts with SecretPrefix.
+//
+// Secrets are refreshed once older than the service's ttl. If the refresh fails the cached value
+// is returned so a Secret Manager outage does not stop automations already holding the secret.
+func (s *Secrets) Resolve(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, SecretPrefix) {
+		return value, nil
+	}
+	name := strings.TrimPrefix(value, SecretPrefix)
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cache[name]
+	if ok && time.Since(c.fetched) < s.ttl {
+		return c.value, nil
+	}
+	b, err := s.client.AccessSecretVersion(ctx, name)
+	if err != nil {
+		if ok {
+			log.Printf("failed to refresh secret %q, using cached value: %q", name, err)
+			return c.value, nil
+		}
+		return "", errors.Wrapf(err, "failed to access secret %q", name)
+	}
+	s.cache[name] = cachedSecret{value: string(b), fetched: time.Now()}
+	return string(b), nil
+}
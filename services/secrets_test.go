@@ -0,0 +1,66 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	const name = "projects/p/secrets/sendgrid/versions/latest"
+	for _, tt := range []struct {
+		name     string
+		value    string
+		ttl      time.Duration
+		remove   bool
+		expected string
+		accessed int
+		fail     bool
+	}{
+		{name: "plain value", value: "key", ttl: time.Hour, expected: "key", accessed: 0},
+		{name: "cached", value: SecretPrefix + name, ttl: time.Hour, expected: "secret", accessed: 1},
+		{name: "latest by default", value: SecretPrefix + "projects/p/secrets/sendgrid", ttl: time.Hour, expected: "secret", accessed: 1},
+		{name: "refreshed", value: SecretPrefix + name, ttl: 0, expected: "secret", accessed: 2},
+		{name: "stale on failure", value: SecretPrefix + name, ttl: 0, remove: true, expected: "secret", accessed: 2},
+		{name: "not found", value: SecretPrefix + "projects/p/secrets/unknown", ttl: time.Hour, fail: true, accessed: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubs.SecretManagerStub{StubbedSecrets: map[string][]byte{name: []byte("secret")}}
+			s := NewSecrets(stub, tt.ttl)
+			var got string
+			var err error
+			for i := 0; i < 2; i++ {
+				got, err = s.Resolve(ctx, tt.value)
+				if tt.remove {
+					delete(stub.StubbedSecrets, name)
+				}
+			}
+			if (err != nil) != tt.fail {
+				t.Fatalf("%q unexpected error: %v", tt.name, err)
+			}
+			if got != tt.expected {
+				t.Errorf("%q got:%q want:%q", tt.name, got, tt.expected)
+			}
+			if stub.Accessed != tt.accessed {
+				t.Errorf("%q accessed %d times, want %d", tt.name, stub.Accessed, tt.accessed)
+			}
+		})
+	}
+}
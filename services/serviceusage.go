@@ -0,0 +1,167 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/api/googleapi"
+	serviceusage "google.golang.org/api/serviceusage/v1"
+)
+
+// ServiceUsageAdminClient holds the minimum interface required by the Service Usage service.
+type ServiceUsageAdminClient interface {
+	EnabledServices(context.Context, string) ([]string, error)
+	DisableService(context.Context, string, string, bool) (*serviceusage.Operation, error)
+	WaitServiceUsage(context.Context, *serviceusage.Operation) error
+}
+
+// ServiceUsage service enables and disables the APIs of projects.
+type ServiceUsage struct {
+	client ServiceUsageAdminClient
+}
+
+// NewServiceUsage returns a new Service Usage service.
+func NewServiceUsage(client ServiceUsageAdminClient) *ServiceUsage {
+	return &ServiceUsage{client: client}
+}
+
+// EnabledServices returns the APIs enabled in the project, such as "compute.googleapis.com".
+func (s *ServiceUsage) EnabledServices(ctx context.Context, projectID string) ([]string, error) {
+	return s.client.EnabledServices(ctx, projectID)
+}
+
+// dependentsRegex matches the APIs listed in the error of disabling an API other enabled APIs
+// depend on, such as "is depended on by the following active service(s): a,b; Please specify ...".
+var dependentsRegex = regexp.MustCompile(`following active service\(s\): ([^;]+);`)
+
+// DisableService disables the API in the project, returning the enabled APIs depending on it that
+// were disabled before it, deepest first.
+//
+// APIs are disabled one at a time and never with DisableDependentServices, so no API is disabled
+// without being checked: if other enabled APIs depend on the API, they are disabled first only if
+// dependents is set and none of them, nor those depending on them in turn, is protected. Each API
+// disabled is logged as a change, so it can be enabled again. A protected API found deeper than
+// the API's own dependents stops the walk, the APIs disabled until then are returned with the
+// error.
+func (s *ServiceUsage) DisableService(ctx context.Context, projectID, api string, dependents bool, protected []string) ([]string, error) {
+	enabled, err := s.client.EnabledServices(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the enabled APIs of %q", projectID)
+	}
+	w := &dependentsWalk{
+		s:          s,
+		projectID:  projectID,
+		dependents: dependents,
+		protected:  protected,
+		enabled:    make(map[string]bool),
+		walking:    make(map[string]bool),
+	}
+	for _, e := range enabled {
+		w.enabled[e] = true
+	}
+	if !w.enabled[api] {
+		return nil, nil
+	}
+	if err := w.disable(ctx, api); err != nil {
+		return w.disabled, err
+	}
+	return w.disabled[:len(w.disabled)-1], nil
+}
+
+// dependentsWalk disables an API after the enabled APIs depending on it.
+type dependentsWalk struct {
+	s          *ServiceUsage
+	projectID  string
+	dependents bool
+	protected  []string
+	// enabled holds the APIs enabled in the project, those disabled by the walk are removed.
+	enabled map[string]bool
+	// walking holds the APIs whose dependents are being disabled, to stop on cycles.
+	walking  map[string]bool
+	disabled []string
+}
+
+// disable disables the API, the enabled APIs its error reports depending on it first. Dependents
+// the project does not have enabled are not trusted, the error is returned as it is.
+func (w *dependentsWalk) disable(ctx context.Context, api string) error {
+	w.walking[api] = true
+	defer delete(w.walking, api)
+	for {
+		op, err := w.s.client.DisableService(ctx, w.projectID, api, false)
+		if err == nil {
+			if err := w.s.client.WaitServiceUsage(ctx, op); err != nil {
+				return err
+			}
+			delete(w.enabled, api)
+			w.disabled = append(w.disabled, api)
+			logChange(ctx, &Change{
+				Kind:     serviceKind,
+				Resource: fmt.Sprintf("projects/%s/services/%s", w.projectID, api),
+				Fields:   map[string]FieldChange{"state": {Before: "ENABLED", After: "DISABLED"}},
+			})
+			return nil
+		}
+		var l []string
+		for _, d := range dependentServices(err) {
+			if w.enabled[d] {
+				l = append(l, d)
+			}
+		}
+		if len(l) == 0 {
+			return err
+		}
+		if !w.dependents {
+			return errors.Wrapf(err, "%q is depended on by %q", api, l)
+		}
+		for _, d := range l {
+			if containsFold(w.protected, d) {
+				return fmt.Errorf("%q is depended on by protected API %q", api, d)
+			}
+			if w.walking[d] {
+				return fmt.Errorf("%q and %q depend on each other", api, d)
+			}
+		}
+		for _, d := range l {
+			if err := w.disable(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// dependentServices returns the APIs the error of disabling an API reports depending on it, nil if
+// the error is not about dependent APIs.
+func dependentServices(err error) []string {
+	e, ok := err.(*googleapi.Error)
+	if !ok {
+		return nil
+	}
+	m := dependentsRegex.FindStringSubmatch(e.Message)
+	if m == nil {
+		return nil
+	}
+	var l []string
+	for _, d := range strings.Split(m[1], ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			l = append(l, d)
+		}
+	}
+	return l
+}
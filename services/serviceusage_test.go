@@ -0,0 +1,92 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestDisableService(t *testing.T) {
+	ctx := context.Background()
+	enabled := []string{
+		"bigquery.googleapis.com",
+		"compute.googleapis.com",
+		"container.googleapis.com",
+		"containerregistry.googleapis.com",
+		"gkehub.googleapis.com",
+	}
+	dependents := map[string][]string{
+		"compute.googleapis.com":   {"container.googleapis.com", "containerregistry.googleapis.com"},
+		"container.googleapis.com": {"gkehub.googleapis.com"},
+		"bigquery.googleapis.com":  {"bigquerystorage.googleapis.com"},
+	}
+	for _, tt := range []struct {
+		name          string
+		api           string
+		dependents    bool
+		protected     []string
+		want          []string
+		wantDisabled  []string
+		expectedError bool
+	}{
+		{name: "no dependents", api: "gkehub.googleapis.com", wantDisabled: []string{"test-project/gkehub.googleapis.com"}},
+		{name: "not enabled", api: "sqladmin.googleapis.com"},
+		{name: "dependents not allowed", api: "compute.googleapis.com", expectedError: true},
+		{
+			name:       "dependents",
+			api:        "compute.googleapis.com",
+			dependents: true,
+			want:       []string{"gkehub.googleapis.com", "container.googleapis.com", "containerregistry.googleapis.com"},
+			wantDisabled: []string{
+				"test-project/gkehub.googleapis.com",
+				"test-project/container.googleapis.com",
+				"test-project/containerregistry.googleapis.com",
+				"test-project/compute.googleapis.com",
+			},
+		},
+		{name: "protected dependent", api: "compute.googleapis.com", dependents: true, protected: []string{"containerregistry.googleapis.com"}, expectedError: true},
+		{name: "protected indirect dependent", api: "compute.googleapis.com", dependents: true, protected: []string{"gkehub.googleapis.com"}, expectedError: true},
+		{name: "dependents not enabled", api: "bigquery.googleapis.com", dependents: true, expectedError: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &recordingLogger{}
+			ctx := WithChangeLogger(ctx, NewLogger(client))
+			suStub := &stubs.ServiceUsageStub{
+				StubbedEnabledServices: map[string][]string{"test-project": enabled},
+				StubbedDependents:      dependents,
+			}
+			got, err := NewServiceUsage(suStub).DisableService(ctx, "test-project", tt.api, tt.dependents, tt.protected)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("%s got error %v, want error %t", tt.name, err, tt.expectedError)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s dependents (-want +got):\n%s", tt.name, diff)
+			}
+			if diff := cmp.Diff(tt.wantDisabled, suStub.DisabledServices); diff != "" {
+				t.Errorf("%s disabled (-want +got):\n%s", tt.name, diff)
+			}
+			if suStub.DisableDependents {
+				t.Errorf("%s disabled an API along with its dependents", tt.name)
+			}
+			if len(client.messages) != len(tt.wantDisabled) {
+				t.Errorf("%s logged %d changes, want %d: %q", tt.name, len(client.messages), len(tt.wantDisabled), client.messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// snsHost matches the hosts Amazon SNS serves signing certificates and subscription URLs from.
+var snsHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSClient holds the minimum interface required by the SNS service.
+type SNSClient interface {
+	Get(context.Context, string) ([]byte, error)
+}
+
+// SNSMessage is a message delivered by Amazon SNS to a HTTPS subscription.
+type SNSMessage struct {
+	Type             string
+	MessageID        string `json:"MessageId"`
+	Token            string
+	TopicArn         string
+	Subject          string
+	Message          string
+	Timestamp        string
+	SignatureVersion string
+	Signature        string
+	SigningCertURL   string
+	SubscribeURL     string
+}
+
+// SNS service.
+type SNS struct {
+	client SNSClient
+}
+
+// NewSNS returns a SNS service.
+func NewSNS(client SNSClient) *SNS {
+	return &SNS{client: client}
+}
+
+// Verify checks the message was signed by Amazon SNS.
+func (s *SNS) Verify(ctx context.Context, m *SNSMessage) error {
+	if err := validateSNSURL(m.SigningCertURL); err != nil {
+		return err
+	}
+	var hash crypto.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported signature version %q", m.SignatureVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode signature")
+	}
+	b, err := s.client.Get(ctx, m.SigningCertURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to get signing certificate")
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return fmt.Errorf("signing certificate is not pem encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse signing certificate")
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signing certificate does not hold a rsa key")
+	}
+	digest, err := snsDigest(hash, m)
+	if err != nil {
+		return err
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	return nil
+}
+
+// ConfirmSubscription confirms the subscription the message asks for.
+func (s *SNS) ConfirmSubscription(ctx context.Context, m *SNSMessage) error {
+	if m.Type != "SubscriptionConfirmation" {
+		return fmt.Errorf("message type %q is not a subscription confirmation", m.Type)
+	}
+	if err := validateSNSURL(m.SubscribeURL); err != nil {
+		return err
+	}
+	if _, err := s.client.Get(ctx, m.SubscribeURL); err != nil {
+		return errors.Wrapf(err, "failed to confirm subscription to %q", m.TopicArn)
+	}
+	return nil
+}
+
+// snsDigest returns the hash of the canonical string Amazon SNS signs for the message.
+func snsDigest(hash crypto.Hash, m *SNSMessage) ([]byte, error) {
+	var fields [][2]string
+	switch m.Type {
+	case "Notification":
+		fields = [][2]string{{"Message", m.Message}, {"MessageId", m.MessageID}}
+		if m.Subject != "" {
+			fields = append(fields, [2]string{"Subject", m.Subject})
+		}
+		fields = append(fields, [][2]string{{"Timestamp", m.Timestamp}, {"TopicArn", m.TopicArn}, {"Type", m.Type}}...)
+	case "SubscriptionConfirmation", "UnsubscribeConfirmation":
+		fields = [][2]string{
+			{"Message", m.Message}, {"MessageId", m.MessageID}, {"SubscribeURL", m.SubscribeURL},
+			{"Timestamp", m.Timestamp}, {"Token", m.Token}, {"TopicArn", m.TopicArn}, {"Type", m.Type},
+		}
+	default:
+		return nil, fmt.Errorf("unknown message type %q", m.Type)
+	}
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(sb.String()))
+		return sum[:], nil
+	}
+	sum := sha256.Sum256([]byte(sb.String()))
+	return sum[:], nil
+}
+
+// validateSNSURL ensures the URL points to Amazon SNS over HTTPS.
+func validateSNSURL(u string) error {
+	p, err := url.Parse(u)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse url %q", u)
+	}
+	if p.Scheme != "https" || !snsHost.MatchString(p.Host) {
+		return fmt.Errorf("url %q is not served by amazon sns", u)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+const certURL = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-0123456789.pem"
+
+// signSNS signs the message with a new key and returns the PEM encoded certificate of the key.
+func signSNS(t *testing.T, m *SNSMessage) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %q", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %q", err)
+	}
+	digest, err := snsDigest(crypto.SHA256, m)
+	if err != nil {
+		t.Fatalf("failed to digest message: %q", err)
+	}
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+	if err != nil {
+		t.Fatalf("failed to sign message: %q", err)
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(sig)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestSNSVerify(t *testing.T) {
+	ctx := context.Background()
+	test := []struct {
+		name        string
+		certURL     string
+		tamper      func(*SNSMessage)
+		expectError bool
+	}{
+		{name: "valid signature", certURL: certURL},
+		{name: "tampered message", certURL: certURL, tamper: func(m *SNSMessage) { m.Message = "{}" }, expectError: true},
+		{name: "certificate not on sns", certURL: "https://sns.us-east-1.amazonaws.com.example.com/cert.pem", expectError: true},
+		{name: "certificate over http", certURL: "http://sns.us-east-1.amazonaws.com/cert.pem", expectError: true},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &SNSMessage{
+				Type:             "Notification",
+				MessageID:        "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+				TopicArn:         "arn:aws:sns:us-east-1:123456789012:guardduty",
+				Message:          `{"source": "aws.guardduty"}`,
+				Timestamp:        "2021-06-01T15:04:05.000Z",
+				SignatureVersion: "2",
+				SigningCertURL:   tt.certURL,
+			}
+			cert := signSNS(t, m)
+			if tt.tamper != nil {
+				tt.tamper(m)
+			}
+			s := NewSNS(&stubs.SNSStub{Responses: map[string][]byte{tt.certURL: cert}})
+			if err := s.Verify(ctx, m); (err != nil) != tt.expectError {
+				t.Errorf("%s failed, got error: %v want error: %t", tt.name, err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	firestore "google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// RemediationRunning is the outcome of remediations claimed and not yet finished.
+	RemediationRunning = "RUNNING"
+	// RemediationSucceeded is the outcome of remediations that ran without error.
+	RemediationSucceeded = "SUCCEEDED"
+	// RemediationFailed is the outcome of remediations that returned an error.
+	RemediationFailed = "FAILED"
+	// RemediationDryRun is the outcome of remediations that only logged what they would have done.
+	RemediationDryRun = "DRY_RUN"
+	// RemediationNoOp is the outcome of remediations that found the resource already remediated.
+	RemediationNoOp = "NO_OP"
+	// RemediationPending is the outcome of remediations that changed nothing yet, waiting for more
+	// findings.
+	RemediationPending = "PENDING"
+)
+
+// StateClient holds the minimum interface required by the state service.
+type StateClient interface {
+	GetDocument(context.Context, string) (*firestore.Document, error)
+	CreateDocument(context.Context, string, string, string, *firestore.Document) (*firestore.Document, error)
+	PatchDocument(context.Context, string, *firestore.Document) (*firestore.Document, error)
+	PatchDocumentIf(context.Context, string, *firestore.Document, string) (*firestore.Document, error)
+	RunQuery(context.Context, string, *firestore.StructuredQuery) ([]*firestore.Document, error)
+}
+
+// Remediation records an action taken on a finding.
+type Remediation struct {
+	// ID identifies the remediation within the collection.
+	ID      string
+	Finding string
+	// CorrelationID is the correlation ID of the finding, shared by everything done for it.
+	CorrelationID string
+	// Category is the category of the finding, such as "public_bucket_acl".
+	Category string
+	// FindingCreated is when the finding was created, zero if it did not tell.
+	FindingCreated time.Time
+	Action         string
+	// Resource is the resource the action changed, such as "projects/p/zones/z/instances/i".
+	Resource string
+	// PreviousState is the state of the resource before the action, as reported by the action.
+	PreviousState string
+	// Outcome is RemediationRunning, RemediationSucceeded, RemediationFailed, RemediationDryRun,
+	// RemediationNoOp or RemediationPending.
+	Outcome string
+	Error   string
+	// Completed and Failed list the resources remediated and failed by actions touching several.
+	Completed []string
+	Failed    []string
+	Started   time.Time
+	Finished  time.Time
+}
+
+// ProjectID returns the ID of the project of the remediated resource, empty if it is not in one.
+func (r *Remediation) ProjectID() string {
+	if m := projectRegex.FindStringSubmatch(r.Resource); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// TimeToRemediate returns how long after the finding was created the remediation finished, false
+// if it did not succeed or the finding did not tell when it was created.
+func (r *Remediation) TimeToRemediate() (time.Duration, bool) {
+	if r.Outcome != RemediationSucceeded || r.FindingCreated.IsZero() || r.Finished.IsZero() {
+		return 0, false
+	}
+	return r.Finished.Sub(r.FindingCreated), true
+}
+
+// projectRegex matches the project of resources such as "projects/p/zones/z/instances/i".
+var projectRegex = regexp.MustCompile(`(?:^|/)projects/([^/]+)`)
+
+// claimLease is how long a running remediation holds its claim, longer than the timeout of the
+// Cloud Functions so a claim left by a function that crashed can be taken over on redelivery.
+const claimLease = 10 * time.Minute
+
+// State service records remediations in a Firestore collection.
+type State struct {
+	client     StateClient
+	parent     string
+	collection string
+}
+
+// NewState returns a new state service storing remediations in the collection of the project's
+// default Firestore database.
+func NewState(client StateClient, projectID, collection string) *State {
+	return &State{
+		client:     client,
+		parent:     fmt.Sprintf("projects/%s/databases/(default)/documents", projectID),
+		collection: collection,
+	}
+}
+
+// Save creates or replaces the remediation.
+func (s *State) Save(ctx context.Context, r *Remediation) error {
+	if r.ID == "" {
+		return errors.New("remediation has no ID")
+	}
+	if _, err := s.client.PatchDocument(ctx, s.name(r.ID), toDocument(r)); err != nil {
+		return errors.Wrapf(err, "failed to save remediation %q", r.ID)
+	}
+	return nil
+}
+
+// IdempotencyKey returns the ID of the remediation of the finding by the action, the same each
+// time the finding is delivered or routed to the action.
+func IdempotencyKey(finding, action string) string {
+	sum := sha256.Sum256([]byte(finding + "|" + action))
+	return hex.EncodeToString(sum[:])
+}
+
+// Claim records the remediation as running, returning false if it was already claimed so the
+// action is not applied twice. Remediations that failed, only ran dry or held their claim longer
+// than the lease can be claimed again, by a single one of the deliveries racing for them.
+func (s *State) Claim(ctx context.Context, r *Remediation) (bool, error) {
+	if r.ID == "" {
+		return false, errors.New("remediation has no ID")
+	}
+	r.Outcome = RemediationRunning
+	_, err := s.client.CreateDocument(ctx, s.parent, s.collection, r.ID, toDocument(r))
+	if err == nil {
+		return true, nil
+	}
+	if e, ok := err.(*googleapi.Error); !ok || e.Code != http.StatusConflict {
+		return false, errors.Wrapf(err, "failed to claim remediation %q", r.ID)
+	}
+	d, err := s.client.GetDocument(ctx, s.name(r.ID))
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+		d = nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get remediation %q", r.ID)
+	}
+	updateTime := ""
+	if d != nil {
+		prev := fromDocument(r.ID, d)
+		switch {
+		case prev.Outcome == RemediationFailed, prev.Outcome == RemediationDryRun:
+		case prev.Outcome == RemediationRunning && r.Started.Sub(prev.Started) > claimLease:
+		default:
+			return false, nil
+		}
+		updateTime = d.UpdateTime
+	}
+	// The claim is only taken if nobody else took it since the record was read, so two
+	// redeliveries of a failed remediation cannot both apply the action again.
+	_, err = s.client.PatchDocumentIf(ctx, s.name(r.ID), toDocument(r), updateTime)
+	if contended(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to claim remediation %q", r.ID)
+	}
+	return true, nil
+}
+
+// Get returns the remediation with the given ID, nil if there is none.
+func (s *State) Get(ctx context.Context, id string) (*Remediation, error) {
+	d, err := s.client.GetDocument(ctx, s.name(id))
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get remediation %q", id)
+	}
+	return fromDocument(id, d), nil
+}
+
+// ByFinding returns the remediations of the finding.
+func (s *State) ByFinding(ctx context.Context, finding string) ([]*Remediation, error) {
+	return s.query(ctx, "finding", "EQUAL", &firestore.Value{StringValue: finding})
+}
+
+// ByResource returns the remediations of the resource.
+func (s *State) ByResource(ctx context.Context, resource string) ([]*Remediation, error) {
+	return s.query(ctx, "resource", "EQUAL", &firestore.Value{StringValue: resource})
+}
+
+// Since returns the remediations started at or after the given time.
+func (s *State) Since(ctx context.Context, t time.Time) ([]*Remediation, error) {
+	return s.query(ctx, "started", "GREATER_THAN_OR_EQUAL", &firestore.Value{TimestampValue: t.UTC().Format(time.RFC3339Nano)})
+}
+
+// HistoryQuery selects remediations, its zero fields match every remediation.
+type HistoryQuery struct {
+	Finding       string
+	CorrelationID string
+	// ProjectID matches the remediations of the resources in the project.
+	ProjectID string
+	Action    string
+	// Since and Until bound when the remediations started, Until excluded.
+	Since time.Time
+	Until time.Time
+}
+
+// Query returns the remediations matching the query in the order they started.
+//
+// Firestore filters on the most selective field of the query and the others are matched in memory,
+// so no composite index is needed. Queries bounded in time filter on when remediations started
+// rather than by action, whose history grows forever.
+func (s *State) Query(ctx context.Context, q HistoryQuery) ([]*Remediation, error) {
+	var l []*Remediation
+	var err error
+	switch {
+	case q.Finding != "":
+		l, err = s.ByFinding(ctx, q.Finding)
+	case q.CorrelationID != "":
+		l, err = s.query(ctx, "correlationId", "EQUAL", &firestore.Value{StringValue: q.CorrelationID})
+	case !q.Since.IsZero():
+		l, err = s.Since(ctx, q.Since)
+	case q.Action != "":
+		l, err = s.query(ctx, "action", "EQUAL", &firestore.Value{StringValue: q.Action})
+	default:
+		l, err = s.query(ctx, "", "", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var matched []*Remediation
+	for _, r := range l {
+		switch {
+		case q.Finding != "" && r.Finding != q.Finding,
+			q.CorrelationID != "" && r.CorrelationID != q.CorrelationID,
+			q.ProjectID != "" && r.ProjectID() != q.ProjectID,
+			q.Action != "" && r.Action != q.Action,
+			!q.Since.IsZero() && r.Started.Before(q.Since),
+			!q.Until.IsZero() && !r.Started.Before(q.Until):
+			continue
+		}
+		matched = append(matched, r)
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Started.Before(matched[j].Started) })
+	return matched, nil
+}
+
+// query returns the remediations whose field compares to the value, every remediation if field is
+// empty.
+func (s *State) query(ctx context.Context, field, op string, value *firestore.Value) ([]*Remediation, error) {
+	q := &firestore.StructuredQuery{From: []*firestore.CollectionSelector{{CollectionId: s.collection}}}
+	if field != "" {
+		q.Where = &firestore.Filter{FieldFilter: &firestore.FieldFilter{
+			Field: &firestore.FieldReference{FieldPath: field},
+			Op:    op,
+			Value: value,
+		}}
+	}
+	docs, err := s.client.RunQuery(ctx, s.parent, q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query remediations by %s", field)
+	}
+	var l []*Remediation
+	for _, d := range docs {
+		l = append(l, fromDocument(d.Name[len(s.parent)+len(s.collection)+2:], d))
+	}
+	return l, nil
+}
+
+func (s *State) name(id string) string {
+	return s.parent + "/" + s.collection + "/" + id
+}
+
+func toDocument(r *Remediation) *firestore.Document {
+	str := func(v string) firestore.Value {
+		if v == "" {
+			return firestore.Value{NullValue: "NULL_VALUE"}
+		}
+		return firestore.Value{StringValue: v}
+	}
+	ts := func(t time.Time) firestore.Value {
+		if t.IsZero() {
+			return firestore.Value{NullValue: "NULL_VALUE"}
+		}
+		return firestore.Value{TimestampValue: t.UTC().Format(time.RFC3339Nano)}
+	}
+	strs := func(l []string) firestore.Value {
+		if len(l) == 0 {
+			return firestore.Value{NullValue: "NULL_VALUE"}
+		}
+		a := &firestore.ArrayValue{}
+		for _, v := range l {
+			a.Values = append(a.Values, &firestore.Value{StringValue: v})
+		}
+		return firestore.Value{ArrayValue: a}
+	}
+	return &firestore.Document{Fields: map[string]firestore.Value{
+		"finding":        str(r.Finding),
+		"correlationId":  str(r.CorrelationID),
+		"category":       str(r.Category),
+		"findingCreated": ts(r.FindingCreated),
+		"action":         str(r.Action),
+		"resource":       str(r.Resource),
+		"previousState":  str(r.PreviousState),
+		"outcome":        str(r.Outcome),
+		"error":          str(r.Error),
+		"completed":      strs(r.Completed),
+		"failed":         strs(r.Failed),
+		"started":        ts(r.Started),
+		"finished":       ts(r.Finished),
+	}}
+}
+
+func fromDocument(id string, d *firestore.Document) *Remediation {
+	ts := func(v firestore.Value) time.Time {
+		t, _ := time.Parse(time.RFC3339Nano, v.TimestampValue)
+		return t
+	}
+	strs := func(v firestore.Value) []string {
+		if v.ArrayValue == nil {
+			return nil
+		}
+		var l []string
+		for _, s := range v.ArrayValue.Values {
+			l = append(l, s.StringValue)
+		}
+		return l
+	}
+	f := d.Fields
+	return &Remediation{
+		ID:             id,
+		Finding:        f["finding"].StringValue,
+		CorrelationID:  f["correlationId"].StringValue,
+		Category:       f["category"].StringValue,
+		FindingCreated: ts(f["findingCreated"]),
+		Action:         f["action"].StringValue,
+		Resource:       f["resource"].StringValue,
+		PreviousState:  f["previousState"].StringValue,
+		Outcome:        f["outcome"].StringValue,
+		Error:          f["error"].StringValue,
+		Completed:      strs(f["completed"]),
+		Failed:         strs(f["failed"]),
+		Started:        ts(f["started"]),
+		Finished:       ts(f["finished"]),
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestState(t *testing.T) {
+	ctx := context.Background()
+	started := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	remediations := []*Remediation{
+		{ID: "1", Finding: "finding/1", Category: "public_bucket_acl", FindingCreated: started.Add(-time.Hour), Action: "close_bucket", Resource: "buckets/b", Outcome: RemediationSucceeded, Started: started, Finished: started.Add(time.Second)},
+		{ID: "2", Finding: "finding/1", Action: "enable_bucket_only_policy", Resource: "buckets/b", Outcome: RemediationFailed, Error: "denied", Started: started},
+		{ID: "3", Finding: "finding/2", Action: "close_bucket", Resource: "buckets/c", Outcome: RemediationDryRun, Started: started.Add(time.Hour)},
+	}
+	s := NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+	for _, r := range remediations {
+		if err := s.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %q", err)
+		}
+	}
+	got, err := s.Get(ctx, "2")
+	if err != nil {
+		t.Fatalf("Get failed: %q", err)
+	}
+	if diff := cmp.Diff(remediations[1], got); diff != "" {
+		t.Errorf("Get difference:%+v", diff)
+	}
+	if got, err := s.Get(ctx, "4"); err != nil || got != nil {
+		t.Errorf("Get of missing remediation got:%+v, %v want nil", got, err)
+	}
+	byFinding, err := s.ByFinding(ctx, "finding/1")
+	if err != nil {
+		t.Fatalf("ByFinding failed: %q", err)
+	}
+	if diff := cmp.Diff(remediations[:2], byFinding); diff != "" {
+		t.Errorf("ByFinding difference:%+v", diff)
+	}
+	byResource, err := s.ByResource(ctx, "buckets/c")
+	if err != nil {
+		t.Fatalf("ByResource failed: %q", err)
+	}
+	if diff := cmp.Diff(remediations[2:], byResource); diff != "" {
+		t.Errorf("ByResource difference:%+v", diff)
+	}
+	since, err := s.Since(ctx, started.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Since failed: %q", err)
+	}
+	if diff := cmp.Diff(remediations[2:], since); diff != "" {
+		t.Errorf("Since difference:%+v", diff)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	started := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	remediations := []*Remediation{
+		{ID: "1", Finding: "finding/1", Action: "close_bucket", Resource: "projects/p1/buckets/b", Outcome: RemediationSucceeded, Started: started.Add(2 * time.Hour)},
+		{ID: "2", Finding: "finding/1", Action: "enable_bucket_only_policy", Resource: "projects/p1/buckets/b", Outcome: RemediationSucceeded, Started: started},
+		{ID: "3", Finding: "finding/2", Action: "close_bucket", Resource: "projects/p2/buckets/c", Outcome: RemediationDryRun, Started: started.Add(time.Hour)},
+		{ID: "4", Finding: "finding/3", Action: "open_firewall", Resource: "projects/p1/firewalls/f", Outcome: RemediationFailed, Started: started.Add(3 * time.Hour)},
+	}
+	s := NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+	for _, r := range remediations {
+		if err := s.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %q", err)
+		}
+	}
+	test := []struct {
+		name     string
+		query    HistoryQuery
+		expected []string
+	}{
+		{name: "all", expected: []string{"2", "3", "1", "4"}},
+		{name: "by finding", query: HistoryQuery{Finding: "finding/1"}, expected: []string{"2", "1"}},
+		{name: "by project", query: HistoryQuery{ProjectID: "p1"}, expected: []string{"2", "1", "4"}},
+		{name: "by action and project", query: HistoryQuery{Action: "close_bucket", ProjectID: "p1"}, expected: []string{"1"}},
+		{name: "by time range", query: HistoryQuery{Since: started.Add(time.Hour), Until: started.Add(3 * time.Hour)}, expected: []string{"3", "1"}},
+		{name: "by action since", query: HistoryQuery{Action: "close_bucket", Since: started.Add(90 * time.Minute)}, expected: []string{"1"}},
+		{name: "no match", query: HistoryQuery{ProjectID: "p3"}},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("%s failed: %q", tt.name, err)
+			}
+			var ids []string
+			for _, r := range got {
+				ids = append(ids, r.ID)
+			}
+			if diff := cmp.Diff(tt.expected, ids); diff != "" {
+				t.Errorf("%s difference:%+v", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestQuerySinceReadsWindow(t *testing.T) {
+	ctx := context.Background()
+	started := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	fs := &stubs.FirestoreStub{}
+	s := NewState(fs, "automation-project", "remediations")
+	for i := 0; i < 10; i++ {
+		r := &Remediation{ID: fmt.Sprint(i), Action: "close_bucket", Outcome: RemediationSucceeded, Started: started.Add(time.Duration(i) * 24 * time.Hour)}
+		if err := s.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %q", err)
+		}
+	}
+	got, err := s.Query(ctx, HistoryQuery{Action: "close_bucket", Since: started.Add(8 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Query failed: %q", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d remediations, want 2", len(got))
+	}
+	if fs.ReadDocuments != 2 {
+		t.Errorf("read %d remediations, want only the 2 of the window", fs.ReadDocuments)
+	}
+}
+
+func TestTimeToRemediate(t *testing.T) {
+	created := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	test := []struct {
+		name        string
+		remediation *Remediation
+		expected    time.Duration
+		expectedOK  bool
+	}{
+		{name: "succeeded", remediation: &Remediation{Outcome: RemediationSucceeded, FindingCreated: created, Finished: created.Add(time.Minute)}, expected: time.Minute, expectedOK: true},
+		{name: "failed", remediation: &Remediation{Outcome: RemediationFailed, FindingCreated: created, Finished: created.Add(time.Minute)}},
+		{name: "creation unknown", remediation: &Remediation{Outcome: RemediationSucceeded, Finished: created.Add(time.Minute)}},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.remediation.TimeToRemediate()
+			if got != tt.expected || ok != tt.expectedOK {
+				t.Errorf("%s got %v, %v want %v, %v", tt.name, got, ok, tt.expected, tt.expectedOK)
+			}
+		})
+	}
+}
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+	started := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	test := []struct {
+		name     string
+		previous *Remediation
+		started  time.Time
+		expected bool
+	}{
+		{name: "first delivery", started: started, expected: true},
+		{name: "redelivered while running", previous: &Remediation{Outcome: RemediationRunning, Started: started}, started: started.Add(time.Minute), expected: false},
+		{name: "running past the lease", previous: &Remediation{Outcome: RemediationRunning, Started: started}, started: started.Add(time.Hour), expected: true},
+		{name: "already succeeded", previous: &Remediation{Outcome: RemediationSucceeded, Started: started}, started: started.Add(time.Hour), expected: false},
+		{name: "previously failed", previous: &Remediation{Outcome: RemediationFailed, Started: started}, started: started.Add(time.Minute), expected: true},
+		{name: "previously dry run", previous: &Remediation{Outcome: RemediationDryRun, Started: started}, started: started.Add(time.Minute), expected: true},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			id := IdempotencyKey("finding/1", "close_bucket")
+			s := NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+			if tt.previous != nil {
+				tt.previous.ID = id
+				if err := s.Save(ctx, tt.previous); err != nil {
+					t.Fatalf("Save failed: %q", err)
+				}
+			}
+			ok, err := s.Claim(ctx, &Remediation{ID: id, Finding: "finding/1", Action: "close_bucket", Started: tt.started})
+			if err != nil {
+				t.Fatalf("Claim failed: %q", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("Claim got:%v want:%v", ok, tt.expected)
+			}
+			got, err := s.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Get failed: %q", err)
+			}
+			if ok && got.Outcome != RemediationRunning {
+				t.Errorf("claimed remediation outcome got:%q want:%q", got.Outcome, RemediationRunning)
+			}
+		})
+	}
+	if IdempotencyKey("finding/1", "close_bucket") == IdempotencyKey("finding/1", "enable_bucket_only_policy") {
+		t.Errorf("IdempotencyKey is the same for different actions")
+	}
+}
+
+func TestClaimConcurrently(t *testing.T) {
+	ctx := context.Background()
+	started := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	id := IdempotencyKey("finding/1", "close_bucket")
+	s := NewState(&stubs.FirestoreStub{}, "automation-project", "remediations")
+	if err := s.Save(ctx, &Remediation{ID: id, Outcome: RemediationFailed, Started: started}); err != nil {
+		t.Fatalf("Save failed: %q", err)
+	}
+	const deliveries = 2
+	claimed := make([]bool, deliveries)
+	var wg sync.WaitGroup
+	for i := 0; i < deliveries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := s.Claim(ctx, &Remediation{ID: id, Finding: "finding/1", Action: "close_bucket", Started: started.Add(time.Minute)})
+			if err != nil {
+				t.Errorf("Claim failed: %q", err)
+			}
+			claimed[i] = ok
+		}(i)
+	}
+	wg.Wait()
+	n := 0
+	for _, ok := range claimed {
+		if ok {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("concurrent claims of a failed remediation got %d claimed want 1", n)
+	}
+}
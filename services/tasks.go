@@ -0,0 +1,117 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	cloudtasks "google.golang.org/api/cloudtasks/v2"
+)
+
+// TasksClient holds the minimum interface required by the tasks service.
+type TasksClient interface {
+	CreateTask(context.Context, string, *cloudtasks.Task) (*cloudtasks.Task, error)
+	DeleteTask(context.Context, string) error
+}
+
+// Tasks service schedules work through a Cloud Tasks queue.
+type Tasks struct {
+	client         TasksClient
+	queue          string
+	projectID      string
+	serviceAccount string
+}
+
+// NewTasks returns a new tasks service.
+//
+// The queue is the full name of the queue: "projects/<project>/locations/<location>/queues/<queue>".
+// Tasks authenticate as the service account, topics are looked up in the project.
+func NewTasks(client TasksClient, queue, projectID, serviceAccount string) *Tasks {
+	return &Tasks{client: client, queue: queue, projectID: projectID, serviceAccount: serviceAccount}
+}
+
+// message is a Pub/Sub message as published through the REST API.
+type message struct {
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Data       string            `json:"data"`
+}
+
+// PublishAt publishes the data with the attributes to the Pub/Sub topic at the given time.
+//
+// Cloud Tasks calls the Pub/Sub API directly so nothing needs to be deployed to receive the task.
+func (t *Tasks) PublishAt(ctx context.Context, topic string, data []byte, attributes map[string]string, at time.Time) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"messages": []message{{Attributes: attributes, Data: base64.StdEncoding.EncodeToString(data)}},
+	})
+	if err != nil {
+		return err
+	}
+	task := &cloudtasks.Task{
+		ScheduleTime: at.UTC().Format(time.RFC3339),
+		HttpRequest: &cloudtasks.HttpRequest{
+			HttpMethod: "POST",
+			Url:        fmt.Sprintf("https://pubsub.googleapis.com/v1/projects/%s/topics/%s:publish", t.projectID, topic),
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       base64.StdEncoding.EncodeToString(body),
+			OauthToken: &cloudtasks.OAuthToken{
+				ServiceAccountEmail: t.serviceAccount,
+				Scope:               "https://www.googleapis.com/auth/pubsub",
+			},
+		},
+	}
+	if _, err := t.client.CreateTask(ctx, t.queue, task); err != nil {
+		return errors.Wrapf(err, "failed to schedule publishing to %q", topic)
+	}
+	return nil
+}
+
+// InvokeAt posts the body to the HTTP function at the URL at the given time and returns the name
+// of the task, which can be canceled until then.
+//
+// The request carries an identity token of the service account with the URL as its audience, so
+// the function can require roles/cloudfunctions.invoker.
+func (t *Tasks) InvokeAt(ctx context.Context, url string, body []byte, at time.Time) (string, error) {
+	task := &cloudtasks.Task{
+		ScheduleTime: at.UTC().Format(time.RFC3339),
+		HttpRequest: &cloudtasks.HttpRequest{
+			HttpMethod: "POST",
+			Url:        url,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       base64.StdEncoding.EncodeToString(body),
+			OidcToken: &cloudtasks.OidcToken{
+				ServiceAccountEmail: t.serviceAccount,
+				Audience:            url,
+			},
+		},
+	}
+	created, err := t.client.CreateTask(ctx, t.queue, task)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to schedule invoking %q", url)
+	}
+	return created.Name, nil
+}
+
+// Cancel deletes the scheduled task, doing nothing if it already ran or was canceled.
+func (t *Tasks) Cancel(ctx context.Context, name string) error {
+	if err := t.client.DeleteTask(ctx, name); err != nil && !notFound(err) {
+		return errors.Wrapf(err, "failed to cancel task %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestPublishAt(t *testing.T) {
+	stub := &stubs.CloudTasksStub{}
+	tasks := NewTasks(stub, "projects/p/locations/us-central1/queues/q", "automation-project", "sa@automation-project.iam.gserviceaccount.com")
+	at := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	if err := tasks.PublishAt(context.Background(), "threat-findings-close-bucket", []byte(`{"ProjectID":"p"}`), nil, at); err != nil {
+		t.Fatalf("PublishAt failed: %q", err)
+	}
+	if len(stub.CreatedTasks) != 1 {
+		t.Fatalf("PublishAt created %d tasks, want 1", len(stub.CreatedTasks))
+	}
+	task := stub.CreatedTasks[0]
+	if task.ScheduleTime != "2020-06-01T18:00:00Z" {
+		t.Errorf("ScheduleTime got:%q want:%q", task.ScheduleTime, "2020-06-01T18:00:00Z")
+	}
+	if want := "https://pubsub.googleapis.com/v1/projects/automation-project/topics/threat-findings-close-bucket:publish"; task.HttpRequest.Url != want {
+		t.Errorf("Url got:%q want:%q", task.HttpRequest.Url, want)
+	}
+	body, err := base64.StdEncoding.DecodeString(task.HttpRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to decode body: %q", err)
+	}
+	want := `{"messages":[{"data":"eyJQcm9qZWN0SUQiOiJwIn0="}]}`
+	if diff := cmp.Diff(want, string(body)); diff != "" {
+		t.Errorf("body difference:%+v", diff)
+	}
+}
+
+func TestInvokeAt(t *testing.T) {
+	const url = "https://us-central1-automation-project.cloudfunctions.net/Webhook/CloseBucket"
+	stub := &stubs.CloudTasksStub{}
+	tasks := NewTasks(stub, "projects/p/locations/us-central1/queues/q", "automation-project", "sa@automation-project.iam.gserviceaccount.com")
+	at := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	name, err := tasks.InvokeAt(context.Background(), url, []byte(`{"ProjectID":"p"}`), at)
+	if err != nil {
+		t.Fatalf("InvokeAt failed: %q", err)
+	}
+	if len(stub.CreatedTasks) != 1 {
+		t.Fatalf("InvokeAt created %d tasks, want 1", len(stub.CreatedTasks))
+	}
+	task := stub.CreatedTasks[0]
+	if task.HttpRequest.Url != url || task.HttpRequest.OidcToken.Audience != url {
+		t.Errorf("got url %q and audience %q, want %q", task.HttpRequest.Url, task.HttpRequest.OidcToken.Audience, url)
+	}
+	if err := tasks.Cancel(context.Background(), name); err != nil {
+		t.Fatalf("Cancel failed: %q", err)
+	}
+	if diff := cmp.Diff([]string{"projects/p/locations/us-central1/queues/q/tasks/1"}, stub.DeletedTasks); diff != "" {
+		t.Errorf("deleted tasks difference:%+v", diff)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	firestore "google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// throttleAttempts bounds the attempts to take a token while other instances update the bucket.
+const throttleAttempts = 10
+
+// ThrottleClient holds the minimum interface required by the throttle service.
+type ThrottleClient interface {
+	GetDocument(context.Context, string) (*firestore.Document, error)
+	PatchDocumentIf(context.Context, string, *firestore.Document, string) (*firestore.Document, error)
+}
+
+// Throttle service caps the calls made to rate limited APIs across every instance of the
+// functions with token buckets kept in a Firestore collection, one per API.
+//
+// Buckets hold up to a minute of calls and refill continuously. Callers finding a bucket empty
+// reserve the next token anyway and are told how long to wait for it, so deferred calls are spread
+// out instead of all retrying at once.
+type Throttle struct {
+	client     ThrottleClient
+	parent     string
+	collection string
+	now        func() time.Time
+}
+
+// NewThrottle returns a new throttle service keeping its buckets in the collection of the
+// project's default Firestore database.
+func NewThrottle(client ThrottleClient, projectID, collection string) *Throttle {
+	return &Throttle{
+		client:     client,
+		parent:     fmt.Sprintf("projects/%s/databases/(default)/documents", projectID),
+		collection: collection,
+		now:        time.Now,
+	}
+}
+
+// Take takes a token from the bucket of the API allowing perMinute calls a minute, returning how
+// long to wait before making the call, zero if it can be made now.
+func (t *Throttle) Take(ctx context.Context, api string, perMinute int) (time.Duration, error) {
+	if perMinute <= 0 {
+		return 0, nil
+	}
+	name := t.parent + "/" + t.collection + "/" + api
+	rate := float64(perMinute) / time.Minute.Seconds()
+	for i := 0; i < throttleAttempts; i++ {
+		now := t.now()
+		tokens, updateTime := float64(perMinute), ""
+		d, err := t.client.GetDocument(ctx, name)
+		if e, ok := err.(*googleapi.Error); err != nil && (!ok || e.Code != http.StatusNotFound) {
+			return 0, errors.Wrapf(err, "failed to get bucket of %q", api)
+		}
+		if err == nil {
+			updated, _ := time.Parse(time.RFC3339Nano, d.Fields["updated"].TimestampValue)
+			tokens = d.Fields["tokens"].DoubleValue + now.Sub(updated).Seconds()*rate
+			if tokens > float64(perMinute) {
+				tokens = float64(perMinute)
+			}
+			updateTime = d.UpdateTime
+		}
+		tokens--
+		_, err = t.client.PatchDocumentIf(ctx, name, &firestore.Document{Fields: map[string]firestore.Value{
+			"tokens":  {DoubleValue: tokens, ForceSendFields: []string{"DoubleValue"}},
+			"updated": {TimestampValue: now.UTC().Format(time.RFC3339Nano)},
+		}}, updateTime)
+		if contended(err) {
+			continue
+		}
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to take a token from the bucket of %q", api)
+		}
+		if tokens >= 0 {
+			return 0, nil
+		}
+		return time.Duration(-tokens * float64(time.Minute) / float64(perMinute)), nil
+	}
+	return 0, fmt.Errorf("bucket of %q kept changing after %d attempts", api, throttleAttempts)
+}
+
+// contended reports whether a conditional write failed because another instance wrote first.
+func contended(err error) bool {
+	e, ok := err.(*googleapi.Error)
+	return ok && (e.Code == http.StatusConflict || strings.Contains(e.Body, "FAILED_PRECONDITION"))
+}
@@ -0,0 +1,56 @@
+package services
+
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/googlecloudplatform/security-response-automation/clients/stubs"
+)
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 6, 1, 18, 0, 0, 0, time.UTC)
+	th := NewThrottle(&stubs.FirestoreStub{}, "automation-project", "quotas")
+	th.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		if wait, err := th.Take(ctx, "compute.snapshots.insert", 3); err != nil || wait != 0 {
+			t.Fatalf("take %d got:%v, %v want no wait", i, wait, err)
+		}
+	}
+	wait, err := th.Take(ctx, "compute.snapshots.insert", 3)
+	if err != nil {
+		t.Fatalf("Take failed: %q", err)
+	}
+	if wait != 20*time.Second {
+		t.Errorf("empty bucket wait got:%v want:20s", wait)
+	}
+	wait, err = th.Take(ctx, "compute.snapshots.insert", 3)
+	if err != nil {
+		t.Fatalf("Take failed: %q", err)
+	}
+	if wait != 40*time.Second {
+		t.Errorf("reserved token wait got:%v want:40s", wait)
+	}
+	now = now.Add(time.Minute)
+	if wait, err := th.Take(ctx, "compute.snapshots.insert", 3); err != nil || wait != 0 {
+		t.Errorf("refilled bucket got:%v, %v want no wait", wait, err)
+	}
+	if wait, err := th.Take(ctx, "compute.disks.insert", 3); err != nil || wait != 0 {
+		t.Errorf("other api got:%v, %v want no wait", wait, err)
+	}
+}
@@ -37,6 +37,15 @@ resource "google_service_account" "automation-service-account" {
   project      = var.automation-project
 }
 
+# Allows the automation service account to impersonate the service accounts of the actions.
+resource "google_service_account_iam_member" "action-token-creator" {
+  count = length(var.action-service-accounts)
+
+  service_account_id = "projects/-/serviceAccounts/${values(var.action-service-accounts)[count.index]}"
+  role               = "roles/iam.serviceAccountTokenCreator"
+  member             = "serviceAccount:${google_service_account.automation-service-account.email}"
+}
+
 // sinks
 resource "google_logging_project_sink" "sink" {
   count                  = var.enable-scc-notification ? 0 : 1
@@ -117,6 +126,149 @@ resource "google_project_iam_member" "stackdriver-writer" {
   member  = "serviceAccount:${google_service_account.automation-service-account.email}"
 }
 
+# Required to read the Secret Manager secrets referenced by the configuration.
+resource "google_project_iam_member" "secret-accessor" {
+  project = var.automation-project
+  role    = "roles/secretmanager.secretAccessor"
+  member  = "serviceAccount:${google_service_account.automation-service-account.email}"
+}
+
+resource "google_project_iam_member" "datastore-user" {
+  project = var.automation-project
+  role    = "roles/datastore.user"
+  member  = "serviceAccount:${google_service_account.automation-service-account.email}"
+}
+
+resource "google_project_iam_member" "error-reporting-writer" {
+  project = var.automation-project
+  role    = "roles/errorreporting.writer"
+  member  = "serviceAccount:${google_service_account.automation-service-account.email}"
+}
+
+# Counts the circuits tripped by action, alert on it to learn of actions that keep failing.
+resource "google_logging_metric" "circuit-trips" {
+  project = var.automation-project
+  name    = "security-response-automation/circuit-trips"
+  filter  = "logName=\"projects/${var.automation-project}/logs/security-response-automation\" AND textPayload:\"tripped\" AND textPayload:\"circuit of\""
+
+  metric_descriptor {
+    metric_kind = "DELTA"
+    value_type  = "INT64"
+    labels {
+      key        = "action"
+      value_type = "STRING"
+    }
+  }
+  label_extractors = {
+    "action" = "REGEXP_EXTRACT(textPayload, \"circuit of (\\\\S+) tripped\")"
+  }
+}
+
+# Distribution of the seconds from the creation of findings to their remediation by category, the
+# mean time to remediate and the SLA alerts are computed from it.
+resource "google_logging_metric" "time-to-remediate" {
+  project         = var.automation-project
+  name            = "security-response-automation/time-to-remediate"
+  filter          = "logName=\"projects/${var.automation-project}/logs/security-response-automation\" AND textPayload:\"remediated\" AND textPayload:\" seconds\""
+  value_extractor = "REGEXP_EXTRACT(textPayload, \"in (\\\\d+) seconds\")"
+
+  metric_descriptor {
+    metric_kind = "DELTA"
+    value_type  = "DISTRIBUTION"
+    unit        = "s"
+    labels {
+      key        = "category"
+      value_type = "STRING"
+    }
+    labels {
+      key        = "action"
+      value_type = "STRING"
+    }
+  }
+  label_extractors = {
+    "category" = "REGEXP_EXTRACT(textPayload, \"remediated (\\\\S+) finding\")"
+    "action"   = "REGEXP_EXTRACT(textPayload, \"with (\\\\S+) in \\\\d+ seconds\")"
+  }
+  bucket_options {
+    exponential_buckets {
+      num_finite_buckets = 32
+      growth_factor      = 2
+      scale              = 1
+    }
+  }
+}
+
+resource "google_project_service" "iamcredentials_api" {
+  count                      = length(var.action-service-accounts) + length(var.folder-service-accounts) > 0 ? 1 : 0
+  project                    = var.automation-project
+  service                    = "iamcredentials.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "firestore_api" {
+  project                    = var.automation-project
+  service                    = "firestore.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "secretmanager_api" {
+  project                    = var.automation-project
+  service                    = "secretmanager.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "admin_api" {
+  project                    = var.automation-project
+  service                    = "admin.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "iam_api" {
+  project                    = var.automation-project
+  service                    = "iam.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "accesscontextmanager_api" {
+  project                    = var.automation-project
+  service                    = "accesscontextmanager.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "recommender_api" {
+  project                    = var.automation-project
+  service                    = "recommender.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "cloudidentity_api" {
+  project                    = var.automation-project
+  service                    = "cloudidentity.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "cloudscheduler_api" {
+  project                    = var.automation-project
+  service                    = "cloudscheduler.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
+resource "google_project_service" "clouderrorreporting_api" {
+  project                    = var.automation-project
+  service                    = "clouderrorreporting.googleapis.com"
+  disable_dependent_services = false
+  disable_on_destroy         = false
+}
+
 resource "google_project_service" "cloudresourcemanager_api" {
   project                    = var.automation-project
   service                    = "cloudresourcemanager.googleapis.com"
@@ -26,6 +26,10 @@ output "findings-topic-id" {
   value = google_pubsub_topic.topic.id
 }
 
+output "findings-topic-name" {
+  value = google_pubsub_topic.topic.name
+}
+
 output "router-topic-name" {
   value = google_pubsub_topic.router-topic.name
 }
@@ -37,3 +41,11 @@ output "router-topic-id" {
 output "organization-id" {
   value = var.organization-id
 }
+
+output "action-service-accounts" {
+  value = var.action-service-accounts
+}
+
+output "folder-service-accounts" {
+  value = join(",", [for folder, sa in var.folder-service-accounts : "${folder}=${sa}"])
+}
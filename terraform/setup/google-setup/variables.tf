@@ -36,3 +36,15 @@ variable "notification-name" {
   type    = string
   default = "sra-notifications"
 }
+
+variable "action-service-accounts" {
+  type        = map(string)
+  default     = {}
+  description = "Service accounts the actions impersonate, keyed by action name."
+}
+
+variable "folder-service-accounts" {
+  type        = map(string)
+  default     = {}
+  description = "Service accounts the projects of each folder are remediated as, keyed by folder ID."
+}
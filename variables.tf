@@ -24,3 +24,87 @@ variable "enable-scc-notification" {
   default     = true
   description = "If true, create the notification config from SCC instead of Cloud Logging"
 }
+
+variable "aws-sns-topic-arns" {
+  type        = list(string)
+  default     = []
+  description = "ARNs of the Amazon SNS topics AWS GuardDuty findings are published to."
+}
+
+variable "aws-access-key-id" {
+  type        = string
+  default     = ""
+  description = "Access key ID of the AWS IAM user AWS automations act as."
+}
+
+variable "aws-secret-access-key" {
+  type        = string
+  default     = ""
+  description = "Secret access key of the AWS IAM user AWS automations act as."
+}
+
+variable "policy-bucket" {
+  type        = string
+  default     = ""
+  description = "Cloud Storage bucket holding Rego policies that gate automations. Only bundled policies are used if empty."
+}
+
+variable "webhook-secret" {
+  type        = string
+  default     = ""
+  description = "Shared secret used to sign requests to the Webhook function. The webhook is not deployed if empty."
+}
+
+variable "sendgrid-api-key" {
+  type        = string
+  default     = ""
+  description = "SendGrid API key used by the SendEmail automation."
+}
+
+variable "pagerduty-api-key" {
+  type        = string
+  default     = ""
+  description = "PagerDuty API key used to alert on dead-lettered messages."
+}
+
+variable "pagerduty-service-id" {
+  type        = string
+  default     = ""
+  description = "PagerDuty service alerted on dead-lettered messages, none is if empty."
+}
+
+variable "pagerduty-from" {
+  type        = string
+  default     = ""
+  description = "Email address of the PagerDuty user incidents are created as."
+}
+
+variable "action-service-accounts" {
+  type        = map(string)
+  default     = {}
+  description = "Service accounts actions impersonate instead of running as the automation service account, keyed by action name such as close_bucket."
+}
+
+variable "folder-service-accounts" {
+  type        = map(string)
+  default     = {}
+  description = "Service accounts the projects of a folder are remediated as, keyed by folder ID, each granting roles/iam.serviceAccountTokenCreator to the automation service account."
+}
+
+variable "report-from" {
+  type        = string
+  default     = ""
+  description = "Email address the weekly remediation report is sent from."
+}
+
+variable "report-to" {
+  type        = list(string)
+  default     = []
+  description = "Email addresses the weekly remediation report is sent to, none is emailed if empty."
+}
+
+variable "report-chat-webhook" {
+  type        = string
+  default     = ""
+  description = "Google Chat incoming webhook URL the weekly remediation report is posted to."
+}